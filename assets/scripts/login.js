@@ -13,6 +13,7 @@
   const trustedTokenInput = d.getElementById('trusted-device-token')
   const emailVerifiedCodeInput = d.getElementById('email_verified_code')
   const magicCodeInput = d.getElementById('magic_code')
+  const passkeyButton = d.getElementById('passkey-submit')
 
   // Set the trusted device token from the localstorage in the form if it exists
   try {
@@ -93,7 +94,52 @@
       .catch((err) => w.showError(loginField, err))
   }
 
+  const onSubmitPasskey = function (event) {
+    event.preventDefault()
+    passkeyButton.setAttribute('disabled', true)
+
+    const longRun = longRunCheckbox && longRunCheckbox.checked ? '1' : '0'
+    const redirect = redirectInput && redirectInput.value + w.location.hash
+
+    w.passkey
+      .begin()
+      .then((credential) => {
+        const data = new URLSearchParams()
+        data.append('webauthn-credential', credential)
+        data.append('long-run-session', longRun)
+        data.append('redirect', redirect)
+
+        const headers = new Headers()
+        headers.append('Content-Type', 'application/x-www-form-urlencoded')
+        headers.append('Accept', 'application/json')
+        return fetch('/auth/webauthn/login/finish', {
+          method: 'POST',
+          headers: headers,
+          body: data,
+          credentials: 'same-origin',
+        })
+      })
+      .then((response) => {
+        return response.json().then((body) => {
+          if (response.status < 400) {
+            w.location = body.redirect
+          } else {
+            passkeyButton.removeAttribute('disabled')
+            w.showError(loginField, body.error)
+          }
+        })
+      })
+      .catch((err) => {
+        passkeyButton.removeAttribute('disabled')
+        w.showError(loginField, err)
+      })
+  }
+
   loginForm.addEventListener('submit', onSubmitPassphrase)
+  if (passkeyButton && w.passkey && w.passkey.isSupported()) {
+    passkeyButton.classList.remove('d-none')
+    passkeyButton.addEventListener('click', onSubmitPasskey)
+  }
   passphraseInput.focus()
   submitButton.removeAttribute('disabled')
 })(window, document)
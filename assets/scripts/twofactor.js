@@ -9,6 +9,7 @@
   const clientIdInput = d.getElementById('client_id')
   const submitButton = d.getElementById('two-factor-submit')
   const passcodeInput = d.getElementById('two-factor-passcode')
+  const credentialInput = d.getElementById('webauthn-credential')
   const tokenInput = d.getElementById('two-factor-token')
   const trustCheckbox = d.getElementById('two-factor-trust-device')
   const longRunCheckbox = d.getElementById('long-run-session')
@@ -17,17 +18,35 @@
 
   const onSubmitTwoFactorCode = function (event) {
     event.preventDefault()
-    passcodeInput.setAttribute('disabled', true)
     submitButton.setAttribute('disabled', true)
 
+    // When the second factor is a passkey, the browser is asked for an
+    // assertion before sending the form
+    if (credentialInput) {
+      return w.passkey
+        .begin(tokenInput.value)
+        .then((credential) => {
+          credentialInput.value = credential
+          return sendTwoFactor()
+        })
+        .catch((err) => w.showError(twofaField, err))
+    }
+    passcodeInput.setAttribute('disabled', true)
+    return sendTwoFactor()
+  }
+
+  const sendTwoFactor = function () {
     const longRun = longRunCheckbox && longRunCheckbox.checked ? '1' : '0'
-    const passcode = passcodeInput.value
     const token = tokenInput.value
     const trustDevice = trustCheckbox && trustCheckbox.checked ? '1' : '0'
     const redirect = redirectInput.value + w.location.hash
 
     const data = new URLSearchParams()
-    data.append('two-factor-passcode', passcode)
+    if (credentialInput) {
+      data.append('webauthn-credential', credentialInput.value)
+    } else {
+      data.append('two-factor-passcode', passcodeInput.value)
+    }
     data.append('long-run-session', longRun)
     data.append('two-factor-token', token)
     data.append('two-factor-generate-trusted-device-token', trustDevice)
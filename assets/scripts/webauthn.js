@@ -0,0 +1,86 @@
+;(function (w) {
+  // The binary fields of the WebAuthn API are sent and received by the stack
+  // in base64url.
+  const toBuffer = function (str) {
+    const base64 = str.replace(/-/g, '+').replace(/_/g, '/')
+    const binary = w.atob(base64 + '='.repeat((4 - (base64.length % 4)) % 4))
+    const bytes = new Uint8Array(binary.length)
+    for (let i = 0; i < binary.length; i++) {
+      bytes[i] = binary.charCodeAt(i)
+    }
+    return bytes.buffer
+  }
+
+  const toBase64URL = function (buffer) {
+    const bytes = new Uint8Array(buffer)
+    let binary = ''
+    for (let i = 0; i < bytes.length; i++) {
+      binary += String.fromCharCode(bytes[i])
+    }
+    return w
+      .btoa(binary)
+      .replace(/\+/g, '-')
+      .replace(/\//g, '_')
+      .replace(/=+$/, '')
+  }
+
+  const isSupported = function () {
+    return !!(w.PublicKeyCredential && w.navigator.credentials)
+  }
+
+  // get asks the browser for an assertion with the options sent by the stack,
+  // and returns the credential serialized in JSON.
+  const get = function (options) {
+    const publicKey = Object.assign({}, options, {
+      challenge: toBuffer(options.challenge),
+      allowCredentials: (options.allowCredentials || []).map((cred) =>
+        Object.assign({}, cred, { id: toBuffer(cred.id) }),
+      ),
+    })
+    return w.navigator.credentials
+      .get({ publicKey: publicKey })
+      .then((cred) => {
+        const res = cred.response
+        return JSON.stringify({
+          id: cred.id,
+          type: cred.type,
+          response: {
+            clientDataJSON: toBase64URL(res.clientDataJSON),
+            authenticatorData: toBase64URL(res.authenticatorData),
+            signature: toBase64URL(res.signature),
+            userHandle: res.userHandle ? toBase64URL(res.userHandle) : '',
+          },
+        })
+      })
+  }
+
+  // begin fetches the options for an assertion. The two-factor token is given
+  // when the passkey is used as a second factor.
+  const begin = function (twoFactorToken) {
+    const data = new URLSearchParams()
+    if (twoFactorToken) {
+      data.append('two-factor-token', twoFactorToken)
+    }
+    const headers = new Headers()
+    headers.append('Content-Type', 'application/x-www-form-urlencoded')
+    headers.append('Accept', 'application/json')
+    return fetch('/auth/webauthn/login/begin', {
+      method: 'POST',
+      headers: headers,
+      body: data,
+      credentials: 'same-origin',
+    }).then((response) => {
+      return response.json().then((body) => {
+        if (response.status >= 400) {
+          throw body.error
+        }
+        return get(body.publicKey)
+      })
+    })
+  }
+
+  w.passkey = {
+    isSupported: isSupported,
+    begin: begin,
+  }
+})(window)
@@ -336,6 +336,20 @@ func (ac *AdminClient) CleanSessions(domain string) error {
 	return err
 }
 
+// RotateStorageKey generates a new storage key for the encryption at rest of
+// the files of an instance.
+func (ac *AdminClient) RotateStorageKey(domain string) error {
+	if !validDomain(domain) {
+		return fmt.Errorf("Invalid domain: %s", domain)
+	}
+	_, err := ac.Req(&request.Options{
+		Method:     "POST",
+		Path:       "/instances/" + domain + "/rotate-storage-key",
+		NoResponse: true,
+	})
+	return err
+}
+
 // DisableDebug disables the debug mode for the logger of an instance.
 func (ac *AdminClient) DisableDebug(domain string) error {
 	if !validDomain(domain) {
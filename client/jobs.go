@@ -51,6 +51,11 @@ type Job struct {
 		StartedAt time.Time   `json:"started_at"`
 		State     string      `json:"state"`
 		Worker    string      `json:"worker"`
+		Error     string      `json:"error,omitempty"`
+		Errors    []struct {
+			Error string    `json:"error"`
+			At    time.Time `json:"at"`
+		} `json:"errors,omitempty"`
 	} `json:"attributes"`
 }
 
@@ -172,6 +177,55 @@ func (c *Client) JobPush(r *JobOptions) (*Job, error) {
 	return j, nil
 }
 
+// GetDeadLetters returns the jobs that are in the dead-letter state, for the
+// given worker type (or all the workers if empty).
+func (c *Client) GetDeadLetters(worker string) ([]*Job, error) {
+	var queries url.Values
+	if worker != "" {
+		queries = url.Values{"worker": {worker}}
+	}
+	res, err := c.Req(&request.Options{
+		Method:  "GET",
+		Path:    "/jobs/dead-letters",
+		Queries: queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list []*Job
+	if err := readJSONAPI(res.Body, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RequeueDeadLetter puts the dead letter with the specified ID back in the
+// queue of its worker.
+func (c *Client) RequeueDeadLetter(jobID string) (*Job, error) {
+	res, err := c.Req(&request.Options{
+		Method: "POST",
+		Path:   fmt.Sprintf("/jobs/dead-letters/%s/requeue", url.PathEscape(jobID)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var j *Job
+	if err := readJSONAPI(res.Body, &j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// DiscardDeadLetter deletes the dead letter with the specified ID.
+func (c *Client) DiscardDeadLetter(jobID string) error {
+	_, err := c.Req(&request.Options{
+		Method:     "DELETE",
+		Path:       fmt.Sprintf("/jobs/dead-letters/%s", url.PathEscape(jobID)),
+		NoResponse: true,
+	})
+	return err
+}
+
 // GetTrigger return the trigger with the specified ID.
 func (c *Client) GetTrigger(triggerID string) (*Trigger, error) {
 	res, err := c.Req(&request.Options{
@@ -236,3 +290,33 @@ func (c *Client) ListTriggers() ([]*Trigger, error) {
 	}
 	return list, nil
 }
+
+// JobsQueue is the statistics about the queue of a worker type.
+type JobsQueue struct {
+	Worker     string         `json:"worker"`
+	Len        int            `json:"len"`
+	ByPriority map[string]int `json:"by_priority"`
+	Instances  []struct {
+		Domain  string `json:"domain"`
+		Queued  int    `json:"queued"`
+		Running int    `json:"running,omitempty"`
+	} `json:"instances,omitempty"`
+	MaxConcurrencyPerInstance int `json:"max_concurrency_per_instance,omitempty"`
+}
+
+// ListJobsQueues returns the statistics about the queues of the workers.
+func (ac *AdminClient) ListJobsQueues() ([]*JobsQueue, error) {
+	res, err := ac.Req(&request.Options{
+		Method: "GET",
+		Path:   "/jobs/queues",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var queues []*JobsQueue
+	if err := json.NewDecoder(res.Body).Decode(&queues); err != nil {
+		return nil, err
+	}
+	return queues, nil
+}
@@ -150,6 +150,39 @@ keyfiles written in:
 	},
 }
 
+var genStorageKeyCmd = &cobra.Command{
+	Use:   "gen-storage-key <filepath>",
+	Short: "Generate a master key for the encryption at rest of the files",
+	Long: `
+cozy-stack config gen-storage-key generate a storage master key and save it in
+the specified path. This key is used to seal the storage keys of the instances,
+when the encryption at rest of the files is enabled.
+
+The file permissions are 0400.`,
+
+	Example: `$ cozy-stack config gen-storage-key ~/storage-key
+keyfile written in:
+	~/storage-key
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Usage()
+		}
+
+		filename := path.Clean(utils.AbsPath(args[0]))
+		marshaledKey, err := keyring.GenerateEncodedStorageKey()
+		if err != nil {
+			return err
+		}
+
+		if err = writeFile(filename, marshaledKey, 0400); err != nil {
+			return err
+		}
+		errPrintfln("keyfile written in:\n  %s", filename)
+		return nil
+	},
+}
+
 var encryptCredentialsDataCmd = &cobra.Command{
 	Use:   "encrypt-data <encoding keyfile> <text>",
 	Short: "Encrypt data with the specified encryption keyfile.",
@@ -406,6 +439,7 @@ var listContextsCmd = &cobra.Command{
 func init() {
 	configCmdGroup.AddCommand(adminPasswdCmd)
 	configCmdGroup.AddCommand(genKeysCmd)
+	configCmdGroup.AddCommand(genStorageKeyCmd)
 	configCmdGroup.AddCommand(encryptCredentialsDataCmd)
 	configCmdGroup.AddCommand(decryptCredentialsDataCmd)
 	configCmdGroup.AddCommand(encryptCredentialsCmd)
@@ -1054,6 +1054,25 @@ var cleanSessionsCmd = &cobra.Command{
 	},
 }
 
+var rotateStorageKeyCmd = &cobra.Command{
+	Use:   "rotate-storage-key <domain>",
+	Short: "Rotate the key used for the encryption at rest of the files",
+	Long: `
+cozy-stack instances rotate-storage-key generates a new storage key for the
+encryption at rest of the files of an instance. The data keys of the files are
+sealed again with this new key, but the content of the files is not rewritten.
+`,
+	Example: "$ cozy-stack instances rotate-storage-key cozy.localhost:8080",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+		domain := args[0]
+		ac := newAdminClient()
+		return ac.RotateStorageKey(domain)
+	},
+}
+
 func init() {
 	instanceCmdGroup.AddCommand(showInstanceCmd)
 	instanceCmdGroup.AddCommand(showDBPrefixInstanceCmd)
@@ -1079,6 +1098,7 @@ func init() {
 	instanceCmdGroup.AddCommand(updateInstancePassphraseCmd)
 	instanceCmdGroup.AddCommand(setAuthModeCmd)
 	instanceCmdGroup.AddCommand(cleanSessionsCmd)
+	instanceCmdGroup.AddCommand(rotateStorageKeyCmd)
 	addInstanceCmd.Flags().StringSliceVar(&flagDomainAliases, "domain-aliases", nil, "Specify one or more aliases domain for the instance (separated by ',')")
 	addInstanceCmd.Flags().StringVar(&flagLocale, "locale", consts.DefaultLocale, "Locale of the new cozy instance")
 	addInstanceCmd.Flags().StringVar(&flagUUID, "uuid", "", "The UUID of the instance")
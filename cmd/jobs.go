@@ -21,6 +21,7 @@ var flagJobPrintLogs bool
 var flagJobPrintLogsVerbose bool
 var flagJobWorkers []string
 var flagJobsPurgeDuration string
+var flagJobsDeadLettersWorker string
 
 var jobsCmdGroup = &cobra.Command{
 	Use:   "jobs <command>",
@@ -114,6 +115,98 @@ var jobsPurgeCmd = &cobra.Command{
 	},
 }
 
+var jobsDeadLettersCmd = &cobra.Command{
+	Use:   "dead-letters",
+	Short: "List the jobs that have failed after all their retries",
+	Long: `
+The jobs of the workers configured with dead_letter are kept in the dead_letter
+state when they have failed after all their retries. This command lists them,
+with the history of their errors.
+`,
+	Example: `$ cozy-stack jobs dead-letters --domain example.mycozy.cloud --worker share-upload`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagDomain == "" {
+			return errMissingDomain
+		}
+		c := newClient(flagDomain, "io.cozy.jobs")
+		list, err := c.GetDeadLetters(flagJobsDeadLettersWorker)
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
+var jobsDeadLettersRequeueCmd = &cobra.Command{
+	Use:     "requeue <job-id>",
+	Short:   "Put a dead letter back in the queue of its worker",
+	Example: `$ cozy-stack jobs dead-letters requeue --domain example.mycozy.cloud 4b9cb4b0b2c511ef9f2a0242ac120002`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		if flagDomain == "" {
+			return errMissingDomain
+		}
+		c := newClient(flagDomain, "io.cozy.jobs")
+		j, err := c.RequeueDeadLetter(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(j, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
+var jobsDeadLettersDiscardCmd = &cobra.Command{
+	Use:     "discard <job-id>",
+	Short:   "Delete a dead letter",
+	Example: `$ cozy-stack jobs dead-letters discard --domain example.mycozy.cloud 4b9cb4b0b2c511ef9f2a0242ac120002`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		if flagDomain == "" {
+			return errMissingDomain
+		}
+		c := newClient(flagDomain, "io.cozy.jobs")
+		return c.DiscardDeadLetter(args[0])
+	},
+}
+
+var jobsQueuesCmd = &cobra.Command{
+	Use:   "queues",
+	Short: "Show the queues of the workers",
+	Long: `
+Show, for each worker type, the number of queued jobs by priority, and the
+instances with the most queued jobs (and their running jobs when the number
+of concurrent jobs per instance is limited).
+`,
+	Example: `$ cozy-stack jobs queues`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ac := newAdminClient()
+		queues, err := ac.ListJobsQueues()
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(queues, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
 func init() {
 	jobsCmdGroup.PersistentFlags().StringVar(&flagDomain, "domain", cozyDomain(), "specify the domain name of the instance")
 
@@ -124,7 +217,14 @@ func init() {
 	jobsPurgeCmd.Flags().StringSliceVar(&flagJobWorkers, "workers", nil, "worker types to iterate over (all workers by default)")
 	jobsPurgeCmd.Flags().StringVar(&flagJobsPurgeDuration, "duration", "", "duration to look for (ie. 3D, 2M)")
 
+	jobsDeadLettersCmd.Flags().StringVar(&flagJobsDeadLettersWorker, "worker", "", "only list the dead letters of this worker type")
+
+	jobsDeadLettersCmd.AddCommand(jobsDeadLettersRequeueCmd)
+	jobsDeadLettersCmd.AddCommand(jobsDeadLettersDiscardCmd)
+
 	jobsCmdGroup.AddCommand(jobsRunCmd)
 	jobsCmdGroup.AddCommand(jobsPurgeCmd)
+	jobsCmdGroup.AddCommand(jobsDeadLettersCmd)
+	jobsCmdGroup.AddCommand(jobsQueuesCmd)
 	RootCmd.AddCommand(jobsCmdGroup)
 }
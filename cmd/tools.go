@@ -17,6 +17,7 @@ import (
 
 	"github.com/cozy/cozy-stack/client/request"
 	"github.com/cozy/cozy-stack/cmd/browser"
+	"github.com/cozy/cozy-stack/model/notification/webpush"
 	build "github.com/cozy/cozy-stack/pkg/config"
 	"github.com/spf13/cobra"
 )
@@ -122,6 +123,25 @@ key) as inputs (both encoded in base64), and print on stdout the encrypted data
 	},
 }
 
+var vapidKeysCmd = &cobra.Command{
+	Use:   "vapid-keys",
+	Short: "generate a pair of VAPID keys for Web Push",
+	Long: `
+This command generates a new pair of VAPID keys, to be used in the
+notifications section of the configuration file for sending Web Push
+notifications to the browsers.
+`,
+	Example: "$ cozy-stack tools vapid-keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pub, priv, err := webpush.GenerateKeys()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "vapid_public_key: %s\nvapid_private_key: %s\n", pub, priv)
+		return nil
+	},
+}
+
 func getEncryptKey(key []byte) (*rsa.PublicKey, error) {
 	pubKey, err := x509.ParsePKIXPublicKey(key)
 	if err == nil {
@@ -202,6 +222,7 @@ func init() {
 	toolsCmdGroup.AddCommand(heapCmd)
 	toolsCmdGroup.AddCommand(unxorDocumentID)
 	toolsCmdGroup.AddCommand(encryptRSACmd)
+	toolsCmdGroup.AddCommand(vapidKeysCmd)
 	toolsCmdGroup.AddCommand(bugCmd)
 	RootCmd.AddCommand(toolsCmdGroup)
 }
@@ -0,0 +1,224 @@
+package bitwarden
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/gofrs/uuid/v5"
+)
+
+// DirName is the path of the hidden directory where the encrypted files of
+// the vault are stored.
+const DirName = "/.cozy_bitwarden"
+
+// MaxAttachmentSize is the maximal size for the content of an attachment (the
+// same as on bitwarden.com).
+const MaxAttachmentSize = 500 * 1024 * 1024
+
+var (
+	// ErrAttachmentNotFound is used when the attachment is not in the cipher
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentSize is used when the size of an attachment is invalid
+	ErrAttachmentSize = errors.New("invalid size for the attachment")
+	// ErrAttachmentOrganization is used when an attachment is added to a
+	// cipher of an organization: the ciphers are replicated to the cozy
+	// instances of the other members, but not the content of the attachments.
+	ErrAttachmentOrganization = errors.New("attachments are not supported for the ciphers of an organization")
+)
+
+// Attachment is a file attached to a cipher. Its name and its content are
+// encrypted on the client side. The content is stored in the VFS, in a
+// hidden directory, as a file with the same identifier as the attachment.
+type Attachment struct {
+	FileName string `json:"fileName"`
+	Key      string `json:"key,omitempty"`
+	Size     int64  `json:"size"`
+	Uploaded bool   `json:"uploaded,omitempty"`
+}
+
+// AddAttachment adds an attachment to the cipher, with the metadata sent by
+// the client. Its content must be uploaded after that, and the disk quota of
+// the instance is checked early to avoid an upload that will fail.
+func (c *Cipher) AddAttachment(inst *instance.Instance, fileName, key string, size int64) (string, error) {
+	if c.OrganizationID != "" {
+		return "", ErrAttachmentOrganization
+	}
+	if size <= 0 || size > MaxAttachmentSize {
+		return "", ErrAttachmentSize
+	}
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(inst.VFS(), &vfs.FileDoc{ByteSize: size}); err != nil {
+		return "", err
+	}
+	if c.Attachments == nil {
+		c.Attachments = make(map[string]*Attachment)
+	}
+	id := uuid.Must(uuid.NewV7()).String()
+	c.Attachments[id] = &Attachment{
+		FileName: fileName,
+		Key:      key,
+		Size:     size,
+	}
+	return id, nil
+}
+
+// UploadAttachment writes the encrypted content of an attachment in the VFS.
+// The content must have the size declared when the attachment was added. A
+// new key can be given when the attachment is shared with the cozy
+// organization, as its content is encrypted again by the client (with the
+// same size).
+func (c *Cipher) UploadAttachment(inst *instance.Instance, id, key string, content io.Reader) error {
+	if c.OrganizationID != "" {
+		return ErrAttachmentOrganization
+	}
+	a, ok := c.Attachments[id]
+	if !ok {
+		return ErrAttachmentNotFound
+	}
+
+	if err := writeContent(inst, id, a.Size, content); err != nil {
+		return err
+	}
+	a.Uploaded = true
+	if key != "" {
+		a.Key = key
+	}
+	if c.Metadata != nil {
+		c.Metadata.ChangeUpdatedAt()
+	}
+	return couchdb.UpdateDoc(inst, c)
+}
+
+// AttachmentURL returns an URL that can be used to download the content of an
+// attachment for a few minutes, without a token.
+func (c *Cipher) AttachmentURL(inst *instance.Instance, id string) (string, error) {
+	if a, ok := c.Attachments[id]; !ok || !a.Uploaded {
+		return "", ErrAttachmentNotFound
+	}
+	return contentURL(inst, id)
+}
+
+// DeleteAttachment removes an attachment from the cipher, and destroys its
+// content.
+func (c *Cipher) DeleteAttachment(inst *instance.Instance, id string) error {
+	if _, ok := c.Attachments[id]; !ok {
+		return ErrAttachmentNotFound
+	}
+	if err := destroyContent(inst, id); err != nil {
+		return err
+	}
+	delete(c.Attachments, id)
+	if c.Metadata != nil {
+		c.Metadata.ChangeUpdatedAt()
+	}
+	return couchdb.UpdateDoc(inst, c)
+}
+
+// DeleteAttachmentsContent destroys the content of all the attachments of
+// the cipher. It is used when the cipher is deleted.
+func (c *Cipher) DeleteAttachmentsContent(inst *instance.Instance) error {
+	var errm error
+	for id := range c.Attachments {
+		if err := destroyContent(inst, id); err != nil {
+			errm = errors.Join(errm, err)
+		}
+	}
+	return errm
+}
+
+// writeContent writes an encrypted content in the VFS, in the hidden
+// directory, as a file with the given identifier. The file is replaced if it
+// already exists.
+func writeContent(inst *instance.Instance, fileID string, size int64, content io.Reader) error {
+	fs := inst.VFS()
+	dir, err := ensureDir(inst)
+	if err != nil {
+		return err
+	}
+	olddoc, err := fs.FileByID(fileID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	newdoc, err := vfs.NewFileDoc(fileID, dir.DocID, size, nil, "application/octet-stream",
+		"files", time.Now(), false, false, true, nil)
+	if err != nil {
+		return err
+	}
+	newdoc.DocID = fileID
+	if olddoc != nil {
+		newdoc.CreatedAt = olddoc.CreatedAt
+	}
+	file, err := fs.CreateFile(newdoc, olddoc)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, content)
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// contentURL returns an URL where an encrypted content can be downloaded for a
+// few minutes, without a token.
+func contentURL(inst *instance.Instance, fileID string) (string, error) {
+	fs := inst.VFS()
+	file, err := fs.FileByID(fileID)
+	if err != nil {
+		return "", err
+	}
+	fpath, err := file.Path(fs)
+	if err != nil {
+		return "", err
+	}
+	secret, err := vfs.GetStore().AddFile(inst, fpath)
+	if err != nil {
+		return "", err
+	}
+	return inst.PageURL("/files/downloads/"+secret+"/"+fileID, nil), nil
+}
+
+func destroyContent(inst *instance.Instance, fileID string) error {
+	fs := inst.VFS()
+	file, err := fs.FileByID(fileID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return fs.DestroyFile(file)
+}
+
+// ensureDir returns the hidden directory for the encrypted files of the
+// vault, and creates it if it doesn't exist.
+func ensureDir(inst *instance.Instance) (*vfs.DirDoc, error) {
+	fs := inst.VFS()
+	dir, err := fs.DirByID(consts.BitwardenDirID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if dir != nil {
+		return dir, nil
+	}
+
+	dir, err = vfs.NewDirDocWithPath(DirName[1:], consts.RootDirID, "/", nil)
+	if err != nil {
+		return nil, err
+	}
+	dir.DocID = consts.BitwardenDirID
+	dir.CozyMetadata = vfs.NewCozyMetadata(inst.PageURL("/", nil))
+	err = fs.CreateDir(dir)
+	if errors.Is(err, os.ErrExist) {
+		dir, err = fs.DirByPath(dir.Fullpath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
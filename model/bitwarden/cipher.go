@@ -83,6 +83,7 @@ type Cipher struct {
 	Login          *LoginData             `json:"login,omitempty"`
 	Data           *MapData               `json:"data,omitempty"`
 	Fields         []Field                `json:"fields"`
+	Attachments    map[string]*Attachment `json:"attachments,omitempty"`
 	Metadata       *metadata.CozyMetadata `json:"cozyMetadata,omitempty"`
 	DeletedDate    *time.Time             `json:"deletedDate,omitempty"`
 }
@@ -112,6 +113,13 @@ func (c *Cipher) Clone() couchdb.Doc {
 	}
 	cloned.Fields = make([]Field, len(c.Fields))
 	copy(cloned.Fields, c.Fields)
+	if c.Attachments != nil {
+		cloned.Attachments = make(map[string]*Attachment, len(c.Attachments))
+		for id, a := range c.Attachments {
+			attachment := *a
+			cloned.Attachments[id] = &attachment
+		}
+	}
 	if c.Metadata != nil {
 		cloned.Metadata = c.Metadata.Clone()
 	}
@@ -183,7 +191,13 @@ func DeleteUnrecoverableCiphers(inst *instance.Instance) error {
 		}
 		return err
 	}
-	return couchdb.BulkDeleteDocs(inst, consts.BitwardenCiphers, ciphers)
+	if err := couchdb.BulkDeleteDocs(inst, consts.BitwardenCiphers, ciphers); err != nil {
+		return err
+	}
+	for _, c := range ciphers {
+		_ = c.(*Cipher).DeleteAttachmentsContent(inst)
+	}
+	return nil
 }
 
 var _ couchdb.Doc = &Cipher{}
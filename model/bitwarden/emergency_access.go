@@ -0,0 +1,567 @@
+package bitwarden
+
+import (
+	stdcrypto "crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/model/session"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/mail"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+)
+
+// EmergencyAccessType is the kind of access given to the grantee when the
+// recovery is approved.
+type EmergencyAccessType int
+
+const (
+	// EmergencyAccessTakeover allows the grantee to change the passphrase
+	EmergencyAccessTakeover EmergencyAccessType = 0
+	// EmergencyAccessView allows the grantee to read the ciphers
+	EmergencyAccessView EmergencyAccessType = 1
+)
+
+// EmergencyAccessStatus is the status of an emergency access.
+type EmergencyAccessStatus int
+
+const (
+	// EmergencyAccessInvited is used when the grantee has been invited
+	EmergencyAccessInvited EmergencyAccessStatus = 0
+	// EmergencyAccessAccepted is used when the grantee has accepted the
+	// invitation, and is waiting for the confirmation by the grantor
+	EmergencyAccessAccepted EmergencyAccessStatus = 1
+	// EmergencyAccessConfirmed is used when the grantor has given the key
+	// encrypted for the grantee
+	EmergencyAccessConfirmed EmergencyAccessStatus = 2
+	// EmergencyAccessRecoveryInitiated is used when the grantee has asked for
+	// the access
+	EmergencyAccessRecoveryInitiated EmergencyAccessStatus = 3
+	// EmergencyAccessRecoveryApproved is used when the grantee can use the
+	// access
+	EmergencyAccessRecoveryApproved EmergencyAccessStatus = 4
+)
+
+// MaxEmergencyWaitTimeDays is the maximal number of days before the access
+// is granted automatically.
+const MaxEmergencyWaitTimeDays = 90
+
+// emergencyChallengeTTL is the duration for which the challenge given to the
+// grantee can be signed.
+const emergencyChallengeTTL = 5 * time.Minute
+
+var (
+	// ErrEmergencyAccessNotFound is used when the emergency access doesn't
+	// exist
+	ErrEmergencyAccessNotFound = errors.New("emergency access not found")
+	// ErrEmergencyAccessStatus is used when the action can't be done with the
+	// current status of the emergency access
+	ErrEmergencyAccessStatus = errors.New("invalid status for the emergency access")
+	// ErrEmergencyAccessToken is used when the token of the grantee is invalid
+	ErrEmergencyAccessToken = errors.New("invalid token")
+	// ErrEmergencyAccessProof is used when the grantee has not signed the
+	// challenge with the private key matching their public key
+	ErrEmergencyAccessProof = errors.New("invalid signature of the challenge")
+	// ErrEmergencyAccessInvalid is used when the email, the type or the wait
+	// time is invalid
+	ErrEmergencyAccessInvalid = errors.New("invalid emergency access")
+)
+
+// EmergencyAccess allows a trusted person, the grantee, to ask for an access
+// to the vault when the user, the grantor, is no longer able to give it. The
+// document lives on the instance of the grantor, and the grantee, who has
+// their own cozy, is identified by their email and the token sent to them in
+// the invitation. The access is granted when the grantor approves it, or
+// automatically when the wait time has elapsed.
+type EmergencyAccess struct {
+	CouchID             string                 `json:"_id,omitempty"`
+	CouchRev            string                 `json:"_rev,omitempty"`
+	Email               string                 `json:"email"`
+	GranteeID           string                 `json:"grantee_id,omitempty"`
+	GranteePublicKey    string                 `json:"grantee_public_key,omitempty"`
+	Type                EmergencyAccessType    `json:"type"`
+	Status              EmergencyAccessStatus  `json:"status"`
+	WaitTimeDays        int                    `json:"wait_time_days"`
+	KeyEncrypted        string                 `json:"key_encrypted,omitempty"`
+	TokenHash           string                 `json:"token_hash,omitempty"`
+	RecoveryInitiatedAt *time.Time             `json:"recovery_initiated_at,omitempty"`
+	Metadata            *metadata.CozyMetadata `json:"cozyMetadata,omitempty"`
+}
+
+// ID returns the emergency access qualified identifier
+func (e *EmergencyAccess) ID() string { return e.CouchID }
+
+// Rev returns the emergency access revision
+func (e *EmergencyAccess) Rev() string { return e.CouchRev }
+
+// DocType returns the emergency access document type
+func (e *EmergencyAccess) DocType() string { return consts.BitwardenEmergencyAccesses }
+
+// Clone implements couchdb.Doc
+func (e *EmergencyAccess) Clone() couchdb.Doc {
+	cloned := *e
+	if e.RecoveryInitiatedAt != nil {
+		at := *e.RecoveryInitiatedAt
+		cloned.RecoveryInitiatedAt = &at
+	}
+	if e.Metadata != nil {
+		cloned.Metadata = e.Metadata.Clone()
+	}
+	return &cloned
+}
+
+// SetID changes the emergency access qualified identifier
+func (e *EmergencyAccess) SetID(id string) { e.CouchID = id }
+
+// SetRev changes the emergency access revision
+func (e *EmergencyAccess) SetRev(rev string) { e.CouchRev = rev }
+
+// NewEmergencyAccess returns an emergency access for the given grantee, not
+// yet persisted.
+func NewEmergencyAccess(email string, typ EmergencyAccessType, waitTimeDays int) (*EmergencyAccess, error) {
+	e := &EmergencyAccess{Email: email, Status: EmergencyAccessInvited}
+	if email == "" {
+		return nil, ErrEmergencyAccessInvalid
+	}
+	if err := e.SetOptions(typ, waitTimeDays); err != nil {
+		return nil, err
+	}
+	md := metadata.New()
+	md.DocTypeVersion = DocTypeVersion
+	e.Metadata = md
+	return e, nil
+}
+
+// FindEmergencyAccess returns the emergency access with the given
+// identifier.
+func FindEmergencyAccess(inst *instance.Instance, id string) (*EmergencyAccess, error) {
+	e := &EmergencyAccess{}
+	if err := couchdb.GetDoc(inst, consts.BitwardenEmergencyAccesses, id, e); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil, ErrEmergencyAccessNotFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+// FindAllEmergencyAccesses returns the emergency accesses given by the user.
+func FindAllEmergencyAccesses(inst *instance.Instance) ([]*EmergencyAccess, error) {
+	var accesses []*EmergencyAccess
+	req := &couchdb.AllDocsRequest{}
+	err := couchdb.GetAllDocs(inst, consts.BitwardenEmergencyAccesses, req, &accesses)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return accesses, nil
+}
+
+// SetOptions changes the type and the wait time of the emergency access.
+func (e *EmergencyAccess) SetOptions(typ EmergencyAccessType, waitTimeDays int) error {
+	if typ != EmergencyAccessTakeover && typ != EmergencyAccessView {
+		return ErrEmergencyAccessInvalid
+	}
+	if waitTimeDays < 1 || waitTimeDays > MaxEmergencyWaitTimeDays {
+		return ErrEmergencyAccessInvalid
+	}
+	e.Type = typ
+	e.WaitTimeDays = waitTimeDays
+	return nil
+}
+
+// Update changes the options of the emergency access. A new key encrypted
+// for the grantee can also be given, when the key of the grantor has been
+// rotated.
+func (e *EmergencyAccess) Update(inst *instance.Instance, typ EmergencyAccessType, waitTimeDays int, keyEncrypted string) error {
+	if err := e.SetOptions(typ, waitTimeDays); err != nil {
+		return err
+	}
+	if keyEncrypted != "" && e.KeyEncrypted != "" {
+		e.KeyEncrypted = keyEncrypted
+	}
+	return e.save(inst)
+}
+
+// Invite persists the emergency access with a new token, and sends it by
+// mail to the grantee. It is also used to send again the invitation.
+func (e *EmergencyAccess) Invite(inst *instance.Instance) error {
+	if e.Status != EmergencyAccessInvited {
+		return ErrEmergencyAccessStatus
+	}
+	token, err := e.newToken()
+	if err != nil {
+		return err
+	}
+	if e.CouchID == "" {
+		err = couchdb.CreateDoc(inst, e)
+	} else {
+		err = e.save(inst)
+	}
+	if err != nil {
+		return err
+	}
+	return e.sendInvitation(inst, token)
+}
+
+func (e *EmergencyAccess) sendInvitation(inst *instance.Instance, token string) error {
+	publicName, _ := csettings.PublicName(inst)
+	email, _ := inst.SettingsEMail()
+	action := inst.Translate("Mail Emergency Access Action View")
+	if e.Type == EmergencyAccessTakeover {
+		action = inst.Translate("Mail Emergency Access Action Takeover")
+	}
+	link := inst.SubDomain(consts.PassSlug)
+	link.Fragment = "/accept-emergency?" + url.Values{
+		"id":    {e.CouchID},
+		"name":  {publicName},
+		"email": {e.Email},
+		"token": {token},
+		"cozy":  {inst.PageURL("", nil)},
+	}.Encode()
+	msg, err := job.NewMessage(mail.Options{
+		Mode:         mail.ModeFromUser,
+		To:           []*mail.Address{{Email: e.Email}},
+		TemplateName: "bitwarden_emergency_invite",
+		TemplateValues: map[string]interface{}{
+			"GrantorPublicName": publicName,
+			"GrantorEmail":      email,
+			"Action":            action,
+			"WaitTimeDays":      strconv.Itoa(e.WaitTimeDays),
+			"Link":              link.String(),
+		},
+		Layout: mail.CozyCloudLayout,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: "sendmail",
+		Message:    msg,
+	})
+	return err
+}
+
+// newToken generates a new token for the grantee, and keeps its hash.
+func (e *EmergencyAccess) newToken() (string, error) {
+	token := crypto.GenerateRandomString(32)
+	hash, err := crypto.GenerateFromPassphrase([]byte(token))
+	if err != nil {
+		return "", err
+	}
+	e.TokenHash = string(hash)
+	return token, nil
+}
+
+// CheckToken returns an error if the token is not the one sent to the
+// grantee.
+func (e *EmergencyAccess) CheckToken(token string) error {
+	if e.TokenHash == "" || token == "" {
+		return ErrEmergencyAccessToken
+	}
+	if _, err := crypto.CompareHashAndPassphrase([]byte(e.TokenHash), []byte(token)); err != nil {
+		return ErrEmergencyAccessToken
+	}
+	return nil
+}
+
+// Accept is called when the grantee accepts the invitation. They send the
+// identifier and the public key of their bitwarden account, which are
+// persisted on the emergency access (and not as a contact, as the user may
+// also be a member of an organization), so that the grantor can encrypt
+// their key for the grantee. The token of the invitation, which has been sent by mail, is
+// replaced by a new one that is returned to the grantee.
+func (e *EmergencyAccess) Accept(inst *instance.Instance, token, userID, publicKey string) (string, error) {
+	if e.Status != EmergencyAccessInvited {
+		return "", ErrEmergencyAccessStatus
+	}
+	if err := e.CheckToken(token); err != nil {
+		return "", err
+	}
+	if userID == "" || publicKey == "" {
+		return "", ErrEmergencyAccessInvalid
+	}
+	if _, err := parsePublicKey(publicKey); err != nil {
+		return "", err
+	}
+	newToken, err := e.newToken()
+	if err != nil {
+		return "", err
+	}
+	e.GranteeID = userID
+	e.GranteePublicKey = publicKey
+	e.Status = EmergencyAccessAccepted
+	if err := e.save(inst); err != nil {
+		return "", err
+	}
+	return newToken, nil
+}
+
+// NewChallenge returns a random challenge that the grantee must sign with
+// the private key of their bitwarden account before using the emergency
+// access, to prove that they own the public key given when they have
+// accepted the invitation. A challenge can be used only once.
+func (e *EmergencyAccess) NewChallenge(inst *instance.Instance, token string) (string, error) {
+	if e.GranteeID == "" {
+		return "", ErrEmergencyAccessStatus
+	}
+	if err := e.CheckToken(token); err != nil {
+		return "", err
+	}
+	challenge := crypto.GenerateRandomString(32)
+	cache := config.GetConfig().CacheStorage
+	cache.Set(e.challengeKey(inst), []byte(challenge), emergencyChallengeTTL)
+	return challenge, nil
+}
+
+func (e *EmergencyAccess) challengeKey(inst *instance.Instance) string {
+	return "bw-emergency-challenge:" + inst.Domain + ":" + e.CouchID
+}
+
+// CheckGrantee returns an error if the token is not the one of the grantee,
+// or if the signature of the last challenge can't be verified with their
+// public key. The signature is made with RSASSA-PKCS1-v1_5 and SHA-256, and
+// is encoded in base64.
+func (e *EmergencyAccess) CheckGrantee(inst *instance.Instance, token, signature string) error {
+	if err := e.CheckToken(token); err != nil {
+		return err
+	}
+	cache := config.GetConfig().CacheStorage
+	key := e.challengeKey(inst)
+	challenge, ok := cache.Get(key)
+	if !ok {
+		return ErrEmergencyAccessProof
+	}
+	cache.Clear(key)
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) == 0 {
+		return ErrEmergencyAccessProof
+	}
+	pub, err := parsePublicKey(e.GranteePublicKey)
+	if err != nil {
+		return err
+	}
+	hashed := sha256.Sum256(challenge)
+	if err := rsa.VerifyPKCS1v15(pub, stdcrypto.SHA256, hashed[:], sig); err != nil {
+		return ErrEmergencyAccessProof
+	}
+	return nil
+}
+
+// parsePublicKey decodes the public key of a bitwarden account, which is a
+// RSA key in the PKIX format, encoded in base64.
+func parsePublicKey(publicKey string) (*rsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, ErrEmergencyAccessInvalid
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, ErrEmergencyAccessInvalid
+	}
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrEmergencyAccessInvalid
+	}
+	return key, nil
+}
+
+// Confirm is called by the grantor with their key encrypted with the public
+// key of the grantee.
+func (e *EmergencyAccess) Confirm(inst *instance.Instance, keyEncrypted string) error {
+	if e.Status != EmergencyAccessAccepted {
+		return ErrEmergencyAccessStatus
+	}
+	if keyEncrypted == "" {
+		return ErrEmergencyAccessInvalid
+	}
+	e.KeyEncrypted = keyEncrypted
+	e.Status = EmergencyAccessConfirmed
+	return e.save(inst)
+}
+
+// Initiate is called when the grantee asks for the access. The grantor is
+// notified, and the access will be approved automatically after the wait
+// time if the grantor doesn't reject it.
+func (e *EmergencyAccess) Initiate(inst *instance.Instance, token, signature string) error {
+	if e.Status != EmergencyAccessConfirmed {
+		return ErrEmergencyAccessStatus
+	}
+	if err := e.CheckGrantee(inst, token, signature); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	e.Status = EmergencyAccessRecoveryInitiated
+	e.RecoveryInitiatedAt = &now
+	if err := e.save(inst); err != nil {
+		return err
+	}
+	if err := e.scheduleApproval(inst); err != nil {
+		return err
+	}
+	return e.notify(inst, "Notifications Emergency Access Initiated Message",
+		e.Email, e.ApprovalDate().Format("2006-01-02"))
+}
+
+// ApprovalDate returns the date when the access will be approved
+// automatically, if the recovery has been initiated.
+func (e *EmergencyAccess) ApprovalDate() time.Time {
+	if e.RecoveryInitiatedAt == nil {
+		return time.Time{}
+	}
+	return e.RecoveryInitiatedAt.Add(time.Duration(e.WaitTimeDays) * 24 * time.Hour)
+}
+
+// Approve is called by the grantor to give the access to the grantee before
+// the end of the wait time.
+func (e *EmergencyAccess) Approve(inst *instance.Instance) error {
+	if e.Status != EmergencyAccessRecoveryInitiated {
+		return ErrEmergencyAccessStatus
+	}
+	e.Status = EmergencyAccessRecoveryApproved
+	return e.save(inst)
+}
+
+// Reject is called by the grantor to refuse the access asked by the grantee
+// (or to revoke it after the approval).
+func (e *EmergencyAccess) Reject(inst *instance.Instance) error {
+	if e.Status != EmergencyAccessRecoveryInitiated && e.Status != EmergencyAccessRecoveryApproved {
+		return ErrEmergencyAccessStatus
+	}
+	e.Status = EmergencyAccessConfirmed
+	e.RecoveryInitiatedAt = nil
+	return e.save(inst)
+}
+
+// CheckGranted returns an error if the grantee can't use the access of the
+// given type.
+func (e *EmergencyAccess) CheckGranted(inst *instance.Instance, token, signature string, typ EmergencyAccessType) error {
+	if e.Status != EmergencyAccessRecoveryApproved || e.Type != typ {
+		return ErrEmergencyAccessStatus
+	}
+	return e.CheckGrantee(inst, token, signature)
+}
+
+// Takeover changes the passphrase of the grantor with the one chosen by the
+// grantee. The client of the grantee has decrypted the key of the vault with
+// their private key, and sends it encrypted with the new passphrase. The
+// sessions of the grantor are closed, and they are notified.
+func (e *EmergencyAccess) Takeover(inst *instance.Instance, token, signature string, pass []byte, key string) error {
+	if err := e.CheckGranted(inst, token, signature, EmergencyAccessTakeover); err != nil {
+		return err
+	}
+	if len(pass) == 0 || key == "" {
+		return ErrEmergencyAccessInvalid
+	}
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return err
+	}
+	params := lifecycle.PassParameters{
+		Pass:       pass,
+		Iterations: setting.PassphraseKdfIterations,
+		Key:        key,
+	}
+	if err := lifecycle.ForceUpdatePassphrase(inst, pass, params); err != nil {
+		return err
+	}
+	if err := session.DeleteOthers(inst, ""); err != nil {
+		return err
+	}
+	return e.notify(inst, "Notifications Emergency Access Takeover Message", e.Email)
+}
+
+// Delete removes the emergency access.
+func (e *EmergencyAccess) Delete(inst *instance.Instance) error {
+	return couchdb.DeleteDoc(inst, e)
+}
+
+func (e *EmergencyAccess) save(inst *instance.Instance) error {
+	if e.Metadata != nil {
+		e.Metadata.ChangeUpdatedAt()
+	}
+	return couchdb.UpdateDoc(inst, e)
+}
+
+// EmergencyAccessMessage is the message used by the trigger for approving
+// automatically an emergency access at the end of the wait time.
+type EmergencyAccessMessage struct {
+	EmergencyAccessID string    `json:"emergency_access_id"`
+	InitiatedAt       time.Time `json:"initiated_at"`
+}
+
+func (e *EmergencyAccess) scheduleApproval(inst *instance.Instance) error {
+	msg := &EmergencyAccessMessage{
+		EmergencyAccessID: e.CouchID,
+		InitiatedAt:       *e.RecoveryInitiatedAt,
+	}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: "bitwarden-emergency-access",
+		Arguments:  e.ApprovalDate().Format(time.RFC3339),
+	}, msg)
+	if err != nil {
+		return err
+	}
+	return job.System().AddTrigger(t)
+}
+
+// ApproveAfterWaitTime is called by the bitwarden-emergency-access worker:
+// it approves the access if the recovery initiated by the grantee has not
+// been rejected by the grantor during the wait time.
+func ApproveAfterWaitTime(inst *instance.Instance, msg *EmergencyAccessMessage) error {
+	e, err := FindEmergencyAccess(inst, msg.EmergencyAccessID)
+	if err != nil {
+		if errors.Is(err, ErrEmergencyAccessNotFound) {
+			return nil
+		}
+		return err
+	}
+	if e.Status != EmergencyAccessRecoveryInitiated || e.RecoveryInitiatedAt == nil ||
+		!e.RecoveryInitiatedAt.Equal(msg.InitiatedAt) {
+		return nil
+	}
+	if err := e.Approve(inst); err != nil {
+		return err
+	}
+	return e.notify(inst, "Notifications Emergency Access Approved Message", e.Email)
+}
+
+// notify sends a notification to the grantor about the emergency access.
+func (e *EmergencyAccess) notify(inst *instance.Instance, key string, args ...interface{}) error {
+	message := inst.Translate(key, args...)
+	link := inst.SubDomain(consts.PassSlug)
+	link.Fragment = "/settings/emergency-access"
+	n := &notification.Notification{
+		Title:   inst.Translate("Notifications Emergency Access Subject"),
+		Message: message,
+		Slug:    consts.PassSlug,
+		Data: map[string]interface{}{
+			// For email notification
+			"Message": message,
+			"Link":    link.String(),
+
+			// For mobile push notification
+			"appName":      "",
+			"redirectLink": consts.PassSlug + "/#/settings/emergency-access",
+		},
+	}
+	return center.PushStack(inst.DomainName(), center.NotificationEmergencyAccess, n)
+}
+
+var _ couchdb.Doc = &EmergencyAccess{}
@@ -0,0 +1,100 @@
+package bitwarden
+
+import (
+	"net/url"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+)
+
+// EmergencyGrant is the record, on the cozy of the grantee, of an emergency
+// access given to them by a grantor. The emergency access lives on the cozy
+// of the grantor, and the client of the grantee saves this record after
+// having accepted the invitation, with the token returned by the cozy of the
+// grantor, to list the accesses the user has been given.
+type EmergencyGrant struct {
+	CouchID      string                 `json:"_id,omitempty"`
+	CouchRev     string                 `json:"_rev,omitempty"`
+	Cozy         string                 `json:"cozy"`
+	AccessID     string                 `json:"access_id"`
+	Token        string                 `json:"token"`
+	GrantorName  string                 `json:"grantor_name,omitempty"`
+	GrantorEmail string                 `json:"grantor_email,omitempty"`
+	Type         EmergencyAccessType    `json:"type"`
+	Status       EmergencyAccessStatus  `json:"status"`
+	WaitTimeDays int                    `json:"wait_time_days"`
+	Metadata     *metadata.CozyMetadata `json:"cozyMetadata,omitempty"`
+}
+
+// ID returns the emergency grant qualified identifier
+func (g *EmergencyGrant) ID() string { return g.CouchID }
+
+// Rev returns the emergency grant revision
+func (g *EmergencyGrant) Rev() string { return g.CouchRev }
+
+// DocType returns the emergency grant document type
+func (g *EmergencyGrant) DocType() string { return consts.BitwardenEmergencyGrants }
+
+// Clone implements couchdb.Doc
+func (g *EmergencyGrant) Clone() couchdb.Doc {
+	cloned := *g
+	if g.Metadata != nil {
+		cloned.Metadata = g.Metadata.Clone()
+	}
+	return &cloned
+}
+
+// SetID changes the emergency grant qualified identifier
+func (g *EmergencyGrant) SetID(id string) { g.CouchID = id }
+
+// SetRev changes the emergency grant revision
+func (g *EmergencyGrant) SetRev(rev string) { g.CouchRev = rev }
+
+// FindAllEmergencyGrants returns the emergency accesses given to the user.
+func FindAllEmergencyGrants(inst *instance.Instance) ([]*EmergencyGrant, error) {
+	var grants []*EmergencyGrant
+	req := &couchdb.AllDocsRequest{}
+	err := couchdb.GetAllDocs(inst, consts.BitwardenEmergencyGrants, req, &grants)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// SaveEmergencyGrant persists the emergency access given to the user. If the
+// access is already known, its record is updated, for example with a new
+// status.
+func SaveEmergencyGrant(inst *instance.Instance, g *EmergencyGrant) error {
+	u, err := url.Parse(g.Cozy)
+	if err != nil || u.Host == "" || g.AccessID == "" || g.Token == "" {
+		return ErrEmergencyAccessInvalid
+	}
+	g.Cozy = u.Scheme + "://" + u.Host
+	if g.Type != EmergencyAccessTakeover && g.Type != EmergencyAccessView {
+		return ErrEmergencyAccessInvalid
+	}
+
+	grants, err := FindAllEmergencyGrants(inst)
+	if err != nil {
+		return err
+	}
+	for _, existing := range grants {
+		if existing.Cozy == g.Cozy && existing.AccessID == g.AccessID {
+			g.CouchID = existing.CouchID
+			g.CouchRev = existing.CouchRev
+			g.Metadata = existing.Metadata
+			if g.Metadata != nil {
+				g.Metadata.ChangeUpdatedAt()
+			}
+			return couchdb.UpdateDoc(inst, g)
+		}
+	}
+	md := metadata.New()
+	md.DocTypeVersion = DocTypeVersion
+	g.Metadata = md
+	return couchdb.CreateDoc(inst, g)
+}
+
+var _ couchdb.Doc = &EmergencyGrant{}
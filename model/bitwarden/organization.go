@@ -111,6 +111,9 @@ func (o *Organization) Delete(inst *instance.Instance) error {
 	if err := couchdb.BulkDeleteDocs(inst, consts.BitwardenCiphers, docs); err != nil {
 		return err
 	}
+	for _, cipher := range ciphers {
+		_ = cipher.DeleteAttachmentsContent(inst)
+	}
 
 	return couchdb.DeleteDoc(inst, o)
 }
@@ -0,0 +1,410 @@
+package bitwarden
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+	"github.com/gofrs/uuid/v5"
+)
+
+// SendType is the type of a Send: a text or a file
+type SendType int
+
+const (
+	// SendTypeText is used for sharing a text
+	SendTypeText SendType = 0
+	// SendTypeFile is used for sharing a file
+	SendTypeFile SendType = 1
+)
+
+// maxAccessCountRetries is the number of times the update of the access count
+// of a Send is retried when there is a conflict.
+const maxAccessCountRetries = 5
+
+// MaxSendDeletionDelay is the maximal delay between now and the deletion
+// date of a Send (the same as on bitwarden.com).
+const MaxSendDeletionDelay = 31 * 24 * time.Hour
+
+var (
+	// ErrSendNotFound is used when the Send doesn't exist, or can't be accessed
+	// anymore
+	ErrSendNotFound = errors.New("send not found")
+	// ErrSendDeletionDate is used when the deletion date is invalid
+	ErrSendDeletionDate = errors.New("invalid deletion date")
+	// ErrSendExpirationDate is used when the expiration date is invalid
+	ErrSendExpirationDate = errors.New("invalid expiration date")
+	// ErrSendPasswordRequired is used when a password is needed to access the
+	// Send, and it has not been given
+	ErrSendPasswordRequired = errors.New("password required")
+	// ErrSendInvalidPassword is used when the password for accessing the Send
+	// is not the good one
+	ErrSendInvalidPassword = errors.New("invalid password")
+)
+
+// SendText is the content of a Send of the text type. The text is encrypted
+// on the client side.
+type SendText struct {
+	Text   string `json:"text,omitempty"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+// SendFile is the metadata of the file of a Send. Its name and its content
+// are encrypted on the client side, and the content is stored in the VFS,
+// like the attachments of the ciphers.
+type SendFile struct {
+	ID       string `json:"id"`
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Uploaded bool   `json:"uploaded,omitempty"`
+}
+
+// Send is an ephemeral share of a text or a file with people that may not
+// have a cozy. It can be accessed anonymously with its access identifier
+// (and a password if there is one), until it expires, is disabled, or has
+// reached its maximal number of accesses. The Send is deleted after its
+// deletion date.
+type Send struct {
+	CouchID        string                 `json:"_id,omitempty"`
+	CouchRev       string                 `json:"_rev,omitempty"`
+	Type           SendType               `json:"type"`
+	Name           string                 `json:"name"`
+	Notes          string                 `json:"notes,omitempty"`
+	Key            string                 `json:"key"`
+	Text           *SendText              `json:"text,omitempty"`
+	File           *SendFile              `json:"file,omitempty"`
+	Password       string                 `json:"password,omitempty"`
+	MaxAccessCount *int                   `json:"max_access_count,omitempty"`
+	AccessCount    int                    `json:"access_count"`
+	Disabled       bool                   `json:"disabled,omitempty"`
+	HideEmail      bool                   `json:"hide_email,omitempty"`
+	ExpirationDate *time.Time             `json:"expiration_date,omitempty"`
+	DeletionDate   time.Time              `json:"deletion_date"`
+	Metadata       *metadata.CozyMetadata `json:"cozyMetadata,omitempty"`
+}
+
+// ID returns the send qualified identifier
+func (s *Send) ID() string { return s.CouchID }
+
+// Rev returns the send revision
+func (s *Send) Rev() string { return s.CouchRev }
+
+// DocType returns the send document type
+func (s *Send) DocType() string { return consts.BitwardenSends }
+
+// Clone implements couchdb.Doc
+func (s *Send) Clone() couchdb.Doc {
+	cloned := *s
+	if s.Text != nil {
+		text := *s.Text
+		cloned.Text = &text
+	}
+	if s.File != nil {
+		file := *s.File
+		cloned.File = &file
+	}
+	if s.MaxAccessCount != nil {
+		count := *s.MaxAccessCount
+		cloned.MaxAccessCount = &count
+	}
+	if s.ExpirationDate != nil {
+		date := *s.ExpirationDate
+		cloned.ExpirationDate = &date
+	}
+	if s.Metadata != nil {
+		cloned.Metadata = s.Metadata.Clone()
+	}
+	return &cloned
+}
+
+// SetID changes the send qualified identifier
+func (s *Send) SetID(id string) { s.CouchID = id }
+
+// SetRev changes the send revision
+func (s *Send) SetRev(rev string) { s.CouchRev = rev }
+
+// AccessID returns the identifier used in the URL for accessing the Send:
+// the bitwarden clients expect the bytes of the identifier encoded in
+// base64url.
+func (s *Send) AccessID() string {
+	raw, err := hex.DecodeString(s.CouchID)
+	if err != nil {
+		return s.CouchID
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// FindSend returns the Send with the given identifier.
+func FindSend(inst *instance.Instance, id string) (*Send, error) {
+	send := &Send{}
+	if err := couchdb.GetDoc(inst, consts.BitwardenSends, id, send); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil, ErrSendNotFound
+		}
+		return nil, err
+	}
+	return send, nil
+}
+
+// SendIDFromAccessID returns the identifier of the Send for the given access
+// identifier. The decoding is strict, so that a Send has only one valid
+// access identifier.
+func SendIDFromAccessID(accessID string) (string, error) {
+	raw, err := base64.RawURLEncoding.Strict().DecodeString(accessID)
+	if err != nil || len(raw) == 0 {
+		return "", ErrSendNotFound
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// FindSendByAccessID returns the Send with the given access identifier.
+func FindSendByAccessID(inst *instance.Instance, accessID string) (*Send, error) {
+	id, err := SendIDFromAccessID(accessID)
+	if err != nil {
+		return nil, err
+	}
+	return FindSend(inst, id)
+}
+
+// FindAllSends returns all the Sends of the instance.
+func FindAllSends(inst *instance.Instance) ([]*Send, error) {
+	var sends []*Send
+	req := &couchdb.AllDocsRequest{}
+	if err := couchdb.GetAllDocs(inst, consts.BitwardenSends, req, &sends); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return sends, nil
+		}
+		return nil, err
+	}
+	return sends, nil
+}
+
+// CheckDates returns an error if the expiration and deletion dates of the
+// Send are not valid.
+func (s *Send) CheckDates() error {
+	now := time.Now()
+	if s.DeletionDate.Before(now) || s.DeletionDate.After(now.Add(MaxSendDeletionDelay)) {
+		return ErrSendDeletionDate
+	}
+	if s.ExpirationDate != nil && s.ExpirationDate.Before(now) {
+		return ErrSendExpirationDate
+	}
+	return nil
+}
+
+// SetPassword changes the password for accessing the Send. The client sends
+// a hash of the password, and it is hashed again before being persisted. An
+// empty password removes the protection.
+func (s *Send) SetPassword(password string) error {
+	if password == "" {
+		s.Password = ""
+		return nil
+	}
+	hash, err := crypto.GenerateFromPassphrase([]byte(password))
+	if err != nil {
+		return err
+	}
+	s.Password = string(hash)
+	return nil
+}
+
+// CheckPassword returns an error if the Send is protected by a password and
+// the given password is not the good one.
+func (s *Send) CheckPassword(password string) error {
+	if s.Password == "" {
+		return nil
+	}
+	if password == "" {
+		return ErrSendPasswordRequired
+	}
+	if _, err := crypto.CompareHashAndPassphrase([]byte(s.Password), []byte(password)); err != nil {
+		return ErrSendInvalidPassword
+	}
+	return nil
+}
+
+// CanBeAccessed returns true if the Send can still be accessed anonymously.
+func (s *Send) CanBeAccessed() bool {
+	now := time.Now()
+	if s.Disabled || !s.DeletionDate.After(now) {
+		return false
+	}
+	if s.ExpirationDate != nil && !s.ExpirationDate.After(now) {
+		return false
+	}
+	if s.MaxAccessCount != nil && s.AccessCount >= *s.MaxAccessCount {
+		return false
+	}
+	if s.Type == SendTypeFile && (s.File == nil || !s.File.Uploaded) {
+		return false
+	}
+	return true
+}
+
+// Access checks that the Send can be accessed with the given password. The
+// access is counted for the texts, but not for the files, as only the
+// download of the file is counted.
+func (s *Send) Access(inst *instance.Instance, password string) error {
+	if !s.CanBeAccessed() {
+		return ErrSendNotFound
+	}
+	if err := s.CheckPassword(password); err != nil {
+		return err
+	}
+	if s.Type == SendTypeFile {
+		return nil
+	}
+	return s.countAccess(inst)
+}
+
+// AccessFile checks that the file of the Send can be downloaded with the
+// given password, counts the access, and returns an URL for downloading its
+// encrypted content.
+func (s *Send) AccessFile(inst *instance.Instance, fileID, password string) (string, error) {
+	if s.Type != SendTypeFile || !s.CanBeAccessed() || s.File.ID != fileID {
+		return "", ErrSendNotFound
+	}
+	if err := s.CheckPassword(password); err != nil {
+		return "", err
+	}
+	if err := s.countAccess(inst); err != nil {
+		return "", err
+	}
+	return contentURL(inst, fileID)
+}
+
+// countAccess increments the access count of the Send. The Send can be
+// accessed by several recipients at the same time, so the update is retried
+// on a conflict, with the last version of the document.
+func (s *Send) countAccess(inst *instance.Instance) error {
+	for i := 0; ; i++ {
+		s.AccessCount++
+		err := couchdb.UpdateDoc(inst, s)
+		if err == nil || !couchdb.IsConflictError(err) || i >= maxAccessCountRetries {
+			return err
+		}
+		last, err := FindSend(inst, s.ID())
+		if err != nil {
+			return err
+		}
+		*s = *last
+		if !s.CanBeAccessed() {
+			return ErrSendNotFound
+		}
+	}
+}
+
+// Create persists a new Send, and adds a trigger for deleting it at its
+// deletion date.
+func (s *Send) Create(inst *instance.Instance) error {
+	if err := couchdb.CreateDoc(inst, s); err != nil {
+		return err
+	}
+	return s.scheduleDeletion(inst)
+}
+
+// Update persists the changes of a Send. A new trigger is added if the
+// deletion date has changed.
+func (s *Send) Update(inst *instance.Instance, old *Send) error {
+	if s.Metadata != nil {
+		s.Metadata.ChangeUpdatedAt()
+	}
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return err
+	}
+	if s.DeletionDate.Equal(old.DeletionDate) {
+		return nil
+	}
+	return s.scheduleDeletion(inst)
+}
+
+// AddFile declares the file of a Send, with the metadata sent by the client.
+// Its content must be uploaded after that, and the disk quota is checked
+// early to avoid an upload that will fail.
+func (s *Send) AddFile(inst *instance.Instance, fileName string, size int64) error {
+	if size <= 0 || size > MaxAttachmentSize {
+		return ErrAttachmentSize
+	}
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(inst.VFS(), &vfs.FileDoc{ByteSize: size}); err != nil {
+		return err
+	}
+	s.Type = SendTypeFile
+	s.File = &SendFile{
+		ID:       uuid.Must(uuid.NewV7()).String(),
+		FileName: fileName,
+		Size:     size,
+	}
+	return nil
+}
+
+// UploadFile writes the encrypted content of the file of the Send in the VFS.
+func (s *Send) UploadFile(inst *instance.Instance, fileID string, content io.Reader) error {
+	if s.File == nil || s.File.ID != fileID {
+		return ErrSendNotFound
+	}
+	if err := writeContent(inst, fileID, s.File.Size, content); err != nil {
+		return err
+	}
+	s.File.Uploaded = true
+	if s.Metadata != nil {
+		s.Metadata.ChangeUpdatedAt()
+	}
+	return couchdb.UpdateDoc(inst, s)
+}
+
+// Delete removes the Send, with the content of its file.
+func (s *Send) Delete(inst *instance.Instance) error {
+	if s.File != nil {
+		if err := destroyContent(inst, s.File.ID); err != nil {
+			return err
+		}
+	}
+	return couchdb.DeleteDoc(inst, s)
+}
+
+// CleanSendMessage is the message used by the trigger for deleting a Send.
+type CleanSendMessage struct {
+	SendID string `json:"send_id"`
+}
+
+func (s *Send) scheduleDeletion(inst *instance.Instance) error {
+	msg := &CleanSendMessage{SendID: s.CouchID}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: "clean-bitwarden-sends",
+		Arguments:  s.DeletionDate.Format(time.RFC3339),
+	}, msg)
+	if err != nil {
+		return err
+	}
+	return job.System().AddTrigger(t)
+}
+
+// CleanSend is called by the clean-bitwarden-sends worker: it deletes the
+// Send if its deletion date has been reached. The deletion date may have
+// been postponed since the trigger was added, and the job does nothing in
+// that case.
+func CleanSend(inst *instance.Instance, msg *CleanSendMessage) error {
+	send, err := FindSend(inst, msg.SendID)
+	if err != nil {
+		if errors.Is(err, ErrSendNotFound) {
+			return nil
+		}
+		return err
+	}
+	if send.DeletionDate.After(time.Now()) {
+		return nil
+	}
+	return send.Delete(inst)
+}
+
+var _ couchdb.Doc = &Send{}
@@ -0,0 +1,24 @@
+package bitwarden
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendIDFromAccessID(t *testing.T) {
+	send := &Send{CouchID: "0fbb2d5a0b4bb0a40dd3b54a4b8c1a2e"}
+	id, err := SendIDFromAccessID(send.AccessID())
+	require.NoError(t, err)
+	assert.Equal(t, send.CouchID, id)
+
+	// The padding bits must be zero, so that there is only one access
+	// identifier for a Send
+	_, err = SendIDFromAccessID("AQJ")
+	assert.ErrorIs(t, err, ErrSendNotFound)
+	_, err = SendIDFromAccessID("")
+	assert.ErrorIs(t, err, ErrSendNotFound)
+	_, err = SendIDFromAccessID("AQI=")
+	assert.ErrorIs(t, err, ErrSendNotFound)
+}
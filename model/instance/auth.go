@@ -53,6 +53,12 @@ const (
 	Basic AuthMode = iota
 	// TwoFactorMail authentication mode, with passcode sent via email
 	TwoFactorMail
+	// Passkey authentication mode, with a WebAuthn credential as the second
+	// factor
+	Passkey
+	// TwoFactorTOTP authentication mode, with a code generated by an
+	// authenticator app (RFC 6238)
+	TwoFactorTOTP
 )
 
 // AuthModeToString encode authentication mode in a string
@@ -60,6 +66,10 @@ func AuthModeToString(authMode AuthMode) string {
 	switch authMode {
 	case TwoFactorMail:
 		return "two_factor_mail"
+	case Passkey:
+		return "passkey"
+	case TwoFactorTOTP:
+		return "two_factor_totp"
 	default:
 		return "basic"
 	}
@@ -71,6 +81,10 @@ func StringToAuthMode(authMode string) (AuthMode, error) {
 	switch authMode {
 	case "two_factor_mail":
 		return TwoFactorMail, nil
+	case "passkey":
+		return Passkey, nil
+	case "two_factor_totp":
+		return TwoFactorTOTP, nil
 	case "basic":
 		return Basic, nil
 	default:
@@ -84,6 +98,25 @@ func (i *Instance) HasAuthMode(authMode AuthMode) bool {
 	return i.AuthMode == authMode
 }
 
+// HasTwoFactor returns whether or not a second factor is required to log in.
+// When the second factor is a passkey, the login flows of the clients that
+// can't make a WebAuthn assertion are refused, as a passcode sent by mail
+// would be a weaker second factor.
+func (i *Instance) HasTwoFactor() bool {
+	return i.AuthMode == TwoFactorMail || i.AuthMode == Passkey ||
+		i.AuthMode == TwoFactorTOTP
+}
+
+// TwoFactorMethod returns how the user can get the passcode for the second
+// factor: "totp" when it is generated by an authenticator app, and "mail"
+// else.
+func (i *Instance) TwoFactorMethod() string {
+	if i.AuthMode == TwoFactorTOTP {
+		return "totp"
+	}
+	return "mail"
+}
+
 // GenerateTwoFactorSecrets generates a (token, passcode) pair that can be
 // used as a two factor authentication secret value. The token is used to allow
 // the two-factor form — meaning the user has correctly entered its passphrase
@@ -113,8 +146,13 @@ func (i *Instance) GenerateTwoFactorSecrets() (token []byte, passcode string, er
 }
 
 // ValidateTwoFactorPasscode validates the given (token, passcode) pair for two
-// factor authentication.
+// factor authentication. When an authenticator app is used, the passcode is
+// the code generated by this app or one of the recovery codes.
 func (i *Instance) ValidateTwoFactorPasscode(token []byte, passcode string) bool {
+	if i.AuthMode == TwoFactorTOTP {
+		return i.ValidateTwoFactorToken(token) && i.ValidateAuthenticatorCode(passcode)
+	}
+
 	salt, err := crypto.DecodeAuthMessage(totpMACConfig, i.SessionSecret(), token, nil)
 	if err != nil {
 		return false
@@ -131,6 +169,17 @@ func (i *Instance) ValidateTwoFactorPasscode(token []byte, passcode string) bool
 	return ok && err == nil
 }
 
+// ValidateTwoFactorToken checks that the given token has been generated by
+// GenerateTwoFactorSecrets, ie that the first step of the two factor
+// authentication has been done. It is used when the second factor is a
+// passkey, where there is no passcode to limit the validity of the token.
+func (i *Instance) ValidateTwoFactorToken(token []byte) bool {
+	cfg := totpMACConfig
+	cfg.MaxAge = 15 * time.Minute
+	_, err := crypto.DecodeAuthMessage(cfg, i.SessionSecret(), token, nil)
+	return err == nil
+}
+
 // GenerateTwoFactorTrustedDeviceSecret generates a token that can be kept by the
 // user on-demand to avoid having two-factor authentication on a specific
 // machine.
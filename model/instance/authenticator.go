@@ -0,0 +1,166 @@
+package instance
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+)
+
+// RecoveryCodesCount is the number of recovery codes generated when the
+// authenticator app is activated.
+const RecoveryCodesCount = 10
+
+// authenticatorPeriod is the time step of the codes of the authenticator
+// apps. Most of them only support 30 seconds, 6 digits and SHA1.
+const authenticatorPeriod = 30
+
+// authenticatorSkew is the number of time steps accepted before and after the
+// current one, for the clock drift.
+const authenticatorSkew = 1
+
+var authenticatorHOTPOptions = hotp.ValidateOpts{
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateAuthenticatorKey generates a new secret for an authenticator app,
+// and saves it as pending: the current secret is kept until the new one is
+// confirmed with ActivateAuthenticatorKey. The returned key can be used for
+// the provisioning of the app, with its URL or a QR code.
+func (i *Instance) GenerateAuthenticatorKey() (*otp.Key, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      i.TemplateTitle(),
+		AccountName: i.Domain,
+		Period:      authenticatorPeriod,
+		SecretSize:  20,
+		Digits:      authenticatorHOTPOptions.Digits,
+		Algorithm:   authenticatorHOTPOptions.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+	i.TOTPPendingSecret = key.Secret()
+	if err := Update(i); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ActivateAuthenticatorKey replaces the secret of the authenticator app by
+// the pending one, if the given code has been generated with it. The recovery
+// codes of the previous secret are removed.
+func (i *Instance) ActivateAuthenticatorKey(code string) bool {
+	if i.TOTPPendingSecret == "" {
+		return false
+	}
+	counter, ok := matchTOTPCode(i.TOTPPendingSecret, strings.TrimSpace(code), 0)
+	if !ok {
+		return false
+	}
+	i.TOTPSecret = i.TOTPPendingSecret
+	i.TOTPPendingSecret = ""
+	i.TOTPLastCounter = counter
+	i.TOTPRecoveryCodes = nil
+	if err := Update(i); err != nil {
+		i.Logger().WithNamespace("auth").Errorf("Cannot activate the TOTP secret: %s", err)
+		return false
+	}
+	return true
+}
+
+// ValidateAuthenticatorCode returns true if the given code has been generated
+// by the authenticator app, or is one of the recovery codes. A code can only
+// be used once.
+func (i *Instance) ValidateAuthenticatorCode(code string) bool {
+	if i.TOTPSecret == "" {
+		return false
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == int(authenticatorHOTPOptions.Digits) {
+		return i.validateTOTPCode(code)
+	}
+	return i.useRecoveryCode(code)
+}
+
+func (i *Instance) validateTOTPCode(code string) bool {
+	counter, ok := matchTOTPCode(i.TOTPSecret, code, i.TOTPLastCounter)
+	if !ok {
+		return false
+	}
+	i.TOTPLastCounter = counter
+	if err := Update(i); err != nil {
+		i.Logger().WithNamespace("auth").Errorf("Cannot save the TOTP counter: %s", err)
+		return false
+	}
+	return true
+}
+
+// matchTOTPCode returns the time step for which the code has been generated
+// with the secret, if it is after the last accepted one.
+func matchTOTPCode(secret, code string, last int64) (int64, bool) {
+	current := time.Now().UTC().Unix() / authenticatorPeriod
+	for counter := current - authenticatorSkew; counter <= current+authenticatorSkew; counter++ {
+		if counter <= last {
+			continue
+		}
+		ok, err := hotp.ValidateCustom(code, uint64(counter), secret, authenticatorHOTPOptions)
+		if err == nil && ok {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes generates new recovery codes for the authenticator
+// app, and saves their hashes. The previous codes can no longer be used.
+func (i *Instance) GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodesCount)
+	hashes := make([]string, RecoveryCodesCount)
+	for j := range codes {
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(crypto.GenerateRandomBytes(6)))
+		codes[j] = raw[:5] + "-" + raw[5:]
+		hashes[j] = hashRecoveryCode(raw)
+	}
+	i.TOTPRecoveryCodes = hashes
+	if err := Update(i); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (i *Instance) useRecoveryCode(code string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if normalized == "" {
+		return false
+	}
+	hash := []byte(hashRecoveryCode(normalized))
+	for j, candidate := range i.TOTPRecoveryCodes {
+		if subtle.ConstantTimeCompare(hash, []byte(candidate)) != 1 {
+			continue
+		}
+		i.TOTPRecoveryCodes = append(i.TOTPRecoveryCodes[:j:j], i.TOTPRecoveryCodes[j+1:]...)
+		if err := Update(i); err != nil {
+			i.Logger().WithNamespace("auth").Errorf("Cannot remove the recovery code: %s", err)
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// The recovery codes have enough entropy to be hashed with a simple hash
+// function.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/model/vfs/vfsafero"
+	"github.com/cozy/cozy-stack/model/vfs/vfscrypt"
 	"github.com/cozy/cozy-stack/model/vfs/vfsswift"
 	build "github.com/cozy/cozy-stack/pkg/config"
 	"github.com/cozy/cozy-stack/pkg/config/config"
@@ -104,6 +105,18 @@ type Instance struct {
 	OAuthSecret []byte `json:"oauth_secret,omitempty"`
 	// CLISecret is used to authenticate request from the CLI
 	CLISecret []byte `json:"cli_secret,omitempty"`
+	// TOTPSecret is the secret shared with the authenticator app of the user
+	// (base32 encoded)
+	TOTPSecret string `json:"totp_secret,omitempty"`
+	// TOTPPendingSecret is a new secret for the authenticator app, that will
+	// replace TOTPSecret when a code generated with it has been confirmed
+	TOTPPendingSecret string `json:"totp_pending_secret,omitempty"`
+	// TOTPLastCounter is the time step of the last code accepted from the
+	// authenticator app, to prevent replay
+	TOTPLastCounter int64 `json:"totp_last_counter,omitempty"`
+	// TOTPRecoveryCodes are the hashes of the recovery codes that can be used
+	// when the authenticator app is lost
+	TOTPRecoveryCodes []string `json:"totp_recovery_codes,omitempty"`
 
 	// FeatureFlags is the feature flags that are specific to this instance
 	FeatureFlags map[string]interface{} `json:"feature_flags,omitempty"`
@@ -114,6 +127,10 @@ type Instance struct {
 	// OAuth clients that have been deleted
 	LastActivityFromDeletedOAuthClients *time.Time `json:"last_activity_from_deleted_oauth_clients,omitempty"`
 
+	// StorageKeys are the keys used for the encryption at rest of the files,
+	// sealed by the storage master key. The last one is the current key.
+	StorageKeys []StorageKey `json:"storage_keys,omitempty"`
+
 	vfs              vfs.VFS
 	contextualDomain string
 }
@@ -162,6 +179,17 @@ func (i *Instance) Clone() couchdb.Doc {
 
 	cloned.CLISecret = make([]byte, len(i.CLISecret))
 	copy(cloned.CLISecret, i.CLISecret)
+
+	cloned.TOTPRecoveryCodes = make([]string, len(i.TOTPRecoveryCodes))
+	copy(cloned.TOTPRecoveryCodes, i.TOTPRecoveryCodes)
+
+	cloned.StorageKeys = make([]StorageKey, len(i.StorageKeys))
+	for j, key := range i.StorageKeys {
+		cloned.StorageKeys[j] = StorageKey{
+			Version: key.Version,
+			Sealed:  append([]byte(nil), key.Sealed...),
+		}
+	}
 	return &cloned
 }
 
@@ -237,7 +265,14 @@ func (i *Instance) MakeVFS() error {
 	var err error
 	switch fsURL.Scheme {
 	case config.SchemeFile, config.SchemeMem:
-		i.vfs, err = vfsafero.New(i, index, disk, mutex, fsURL, i.DirName())
+		if config.GetConfig().Fs.Dedup {
+			i.vfs, err = vfsafero.NewDedup(i, index, disk, mutex, fsURL, i.DirName())
+		} else {
+			i.vfs, err = vfsafero.New(i, index, disk, mutex, fsURL, i.DirName())
+		}
+		if err == nil && config.GetConfig().Fs.Encryption {
+			i.vfs, err = vfsafero.Encrypt(i.vfs, i)
+		}
 	case config.SchemeSwift, config.SchemeSwiftSecure:
 		switch i.SwiftLayout {
 		case 2:
@@ -282,9 +317,15 @@ func (i *Instance) ThumbsFS() vfs.Thumbser {
 	case config.SchemeFile:
 		baseFS := afero.NewBasePathFs(afero.NewOsFs(),
 			path.Join(fsURL.Path, i.DirName(), vfs.ThumbsDirName))
+		if config.GetConfig().Fs.Encryption {
+			return vfsafero.NewEncryptedThumbsFs(vfscrypt.NewFs(baseFS, i))
+		}
 		return vfsafero.NewThumbsFs(baseFS)
 	case config.SchemeMem:
 		baseFS := vfsafero.GetMemFS(i.DomainName() + "-thumbs")
+		if config.GetConfig().Fs.Encryption {
+			return vfsafero.NewEncryptedThumbsFs(vfscrypt.NewFs(baseFS, i))
+		}
 		return vfsafero.NewThumbsFs(baseFS)
 	case config.SchemeSwift, config.SchemeSwiftSecure:
 		switch i.SwiftLayout {
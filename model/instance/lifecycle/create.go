@@ -13,6 +13,7 @@ import (
 
 	"github.com/cozy/cozy-stack/model/contact"
 	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/search"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -253,6 +254,13 @@ func Create(opts *Options) (*instance.Instance, error) {
 		return nil, err
 	}
 
+	opts.trace("setup search index", func() {
+		if errs := search.SetupTrigger(i); errs != nil {
+			i.Logger().WithNamespace("lifecycle").
+				Warnf("Cannot setup the trigger for the search index: %s", errs)
+		}
+	})
+
 	opts.trace("install apps", func() {
 		done := make(chan struct{})
 		for _, app := range opts.Apps {
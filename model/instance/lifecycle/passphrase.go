@@ -10,6 +10,7 @@ import (
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/instance"
 	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/model/webauthn"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/crypto"
 	"github.com/cozy/cozy-stack/pkg/emailer"
@@ -194,8 +195,11 @@ func UpdatePassphrase(
 	}
 	// With two factor authentication, we do not check the validity of the
 	// current passphrase, but the validity of the pair passcode/token which has
-	// been exchanged against the current passphrase.
-	if inst.HasAuthMode(instance.TwoFactorMail) {
+	// been exchanged against the current passphrase. When the second factor is
+	// a passkey, UpdatePassphraseWithPasskey must be used instead.
+	if inst.HasAuthMode(instance.Passkey) {
+		return instance.ErrInvalidTwoFactor
+	} else if inst.HasTwoFactor() {
 		if !inst.ValidateTwoFactorPasscode(twoFactorToken, twoFactorPasscode) {
 			return instance.ErrInvalidTwoFactor
 		}
@@ -207,6 +211,36 @@ func UpdatePassphrase(
 			return instance.ErrInvalidPassphrase
 		}
 	}
+	return savePassphrase(inst, params)
+}
+
+// UpdatePassphraseWithPasskey replaces the passphrase when the second factor
+// is a passkey: the token has been exchanged against the current passphrase,
+// and the assertion must have been made by one of the passkeys of the user.
+func UpdatePassphraseWithPasskey(
+	inst *instance.Instance,
+	twoFactorToken []byte,
+	assertion *webauthn.AssertionResponse,
+	params PassParameters,
+) error {
+	if len(params.Pass) == 0 {
+		return instance.ErrMissingPassphrase
+	}
+	if !inst.HasAuthMode(instance.Passkey) || assertion == nil ||
+		!inst.ValidateTwoFactorToken(twoFactorToken) {
+		return instance.ErrInvalidTwoFactor
+	}
+	if _, err := webauthn.FinishLogin(inst, assertion, false); err != nil {
+		inst.Logger().WithNamespace("lifecycle").
+			Infof("Invalid passkey assertion: %s", err)
+		return instance.ErrInvalidTwoFactor
+	}
+	return savePassphrase(inst, params)
+}
+
+// savePassphrase saves the new passphrase, after the checks of the current
+// one or of the second factor.
+func savePassphrase(inst *instance.Instance, params PassParameters) error {
 	hash, err := crypto.GenerateFromPassphrase(params.Pass)
 	if err != nil {
 		return err
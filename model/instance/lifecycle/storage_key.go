@@ -0,0 +1,61 @@
+package lifecycle
+
+import (
+	"errors"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// ErrStorageNotEncrypted is used when rotating the storage key of an instance
+// whose files are not encrypted at rest.
+var ErrStorageNotEncrypted = errors.New("the encryption at rest is not enabled")
+
+// RotateStorageKey generates a new storage key for the instance, and seals
+// again the data keys of all the files with it. The content of the files is
+// not rewritten. The previous storage keys that are no longer used are
+// removed, except the one just before the new key, which is kept for the
+// files that were being written during the rotation.
+func RotateStorageKey(inst *instance.Instance) error {
+	if !config.GetConfig().Fs.Encryption {
+		return ErrStorageNotEncrypted
+	}
+	rewrappers := []vfs.StorageKeysRewrapper{}
+	for _, fs := range []interface{}{inst.VFS(), inst.ThumbsFS()} {
+		if r, ok := fs.(vfs.StorageKeysRewrapper); ok {
+			rewrappers = append(rewrappers, r)
+		}
+	}
+	if len(rewrappers) == 0 {
+		return ErrStorageNotEncrypted
+	}
+
+	var previous uint32
+	if len(inst.StorageKeys) > 0 {
+		previous = inst.StorageKeys[len(inst.StorageKeys)-1].Version
+	}
+	if err := inst.AddStorageKey(); err != nil {
+		return err
+	}
+	if err := update(inst); err != nil {
+		return err
+	}
+
+	inUse := make(map[uint32]bool)
+	for _, r := range rewrappers {
+		versions, err := r.RewrapStorageKeys()
+		if err != nil {
+			// The old keys are kept, and the rotation can be retried
+			return err
+		}
+		for version := range versions {
+			inUse[version] = true
+		}
+	}
+
+	inst.PurgeStorageKeys(func(version uint32) bool {
+		return version == previous || inUse[version]
+	})
+	return update(inst)
+}
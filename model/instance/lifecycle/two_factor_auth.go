@@ -6,12 +6,17 @@ import (
 )
 
 // SendTwoFactorPasscode sends by mail the two factor secret to the owner of
-// the instance. It returns the generated token.
+// the instance. It returns the generated token. When the user has an
+// authenticator app, the passcode is generated by this app, and no mail is
+// sent.
 func SendTwoFactorPasscode(inst *instance.Instance) ([]byte, error) {
 	token, passcode, err := inst.GenerateTwoFactorSecrets()
 	if err != nil {
 		return nil, err
 	}
+	if inst.HasAuthMode(instance.TwoFactorTOTP) {
+		return token, nil
+	}
 	err = emailer.SendEmail(inst, &emailer.TransactionalEmailCmd{
 		TemplateName:   "two_factor",
 		TemplateValues: map[string]interface{}{"TwoFactorPasscode": passcode},
@@ -22,6 +27,17 @@ func SendTwoFactorPasscode(inst *instance.Instance) ([]byte, error) {
 	return token, nil
 }
 
+// StartTwoFactor begins the second step of the two factor authentication in
+// a browser. When the second factor is a passkey, nothing is sent by mail: the
+// token is only used to prove that the passphrase has been checked.
+func StartTwoFactor(inst *instance.Instance) ([]byte, error) {
+	if inst.HasAuthMode(instance.Passkey) {
+		token, _, err := inst.GenerateTwoFactorSecrets()
+		return token, err
+	}
+	return SendTwoFactorPasscode(inst)
+}
+
 // SendMailConfirmationCode send a code to validate the email of the instance
 // in order to activate 2FA.
 func SendMailConfirmationCode(inst *instance.Instance) error {
@@ -0,0 +1,127 @@
+package instance
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/cozy/cozy-stack/model/vfs/vfscrypt"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/keyring"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// ErrNoStorageMasterKey is used when the storage master key is missing in the
+// configuration.
+var ErrNoStorageMasterKey = errors.New("The storage master key is not configured")
+
+// StorageKey is a key used for the encryption at rest of the files of an
+// instance. It is kept sealed by the storage master key from the keyring.
+type StorageKey struct {
+	Version uint32 `json:"version"`
+	Sealed  []byte `json:"sealed"`
+}
+
+// CurrentStorageKey returns the storage key that must be used for encrypting
+// the new files, with its version. The first storage key of the instance is
+// generated when it is needed.
+func (i *Instance) CurrentStorageKey() (uint32, *[32]byte, error) {
+	if len(i.StorageKeys) == 0 {
+		if err := i.initStorageKey(); err != nil {
+			return 0, nil, err
+		}
+	}
+	current := i.StorageKeys[len(i.StorageKeys)-1]
+	key, err := openStorageKey(current.Sealed)
+	if err != nil {
+		return 0, nil, err
+	}
+	return current.Version, key, nil
+}
+
+// StorageKey returns the storage key for the given version.
+func (i *Instance) StorageKey(version uint32) (*[32]byte, error) {
+	for _, key := range i.StorageKeys {
+		if key.Version == version {
+			return openStorageKey(key.Sealed)
+		}
+	}
+	return nil, vfscrypt.ErrUnknownKey
+}
+
+// AddStorageKey generates a new storage key, that will be used for the new
+// files. The instance must be saved after that.
+func (i *Instance) AddStorageKey() error {
+	master := config.GetKeyring().StorageMasterKey()
+	if master == nil {
+		return ErrNoStorageMasterKey
+	}
+	key, err := keyring.GenerateStorageKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	sealed, err := keyring.SealStorageKey(master, key)
+	if err != nil {
+		return err
+	}
+	var version uint32 = 1
+	if len(i.StorageKeys) > 0 {
+		version = i.StorageKeys[len(i.StorageKeys)-1].Version + 1
+	}
+	i.StorageKeys = append(i.StorageKeys, StorageKey{Version: version, Sealed: sealed})
+	return nil
+}
+
+// PurgeStorageKeys removes the storage keys that are not kept by the given
+// function. The current key is always kept.
+func (i *Instance) PurgeStorageKeys(keep func(version uint32) bool) {
+	if len(i.StorageKeys) == 0 {
+		return
+	}
+	current := i.StorageKeys[len(i.StorageKeys)-1]
+	keys := make([]StorageKey, 0, len(i.StorageKeys))
+	for _, key := range i.StorageKeys[:len(i.StorageKeys)-1] {
+		if keep(key.Version) {
+			keys = append(keys, key)
+		}
+	}
+	i.StorageKeys = append(keys, current)
+}
+
+// initStorageKey generates the first storage key of an instance created
+// before the encryption at rest was enabled, and saves it. If the instance
+// document has been modified in the meantime, the key is taken from the
+// saved document if another process has already generated it.
+func (i *Instance) initStorageKey() error {
+	if err := i.AddStorageKey(); err != nil {
+		return err
+	}
+	err := Update(i)
+	if err == nil || !couchdb.IsConflictError(err) {
+		return err
+	}
+
+	fresh := &Instance{}
+	if err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.Instances, i.ID(), fresh); err != nil {
+		return err
+	}
+	if len(fresh.StorageKeys) == 0 {
+		if err := fresh.AddStorageKey(); err != nil {
+			return err
+		}
+		if err := Update(fresh); err != nil {
+			return err
+		}
+	}
+	i.StorageKeys = fresh.StorageKeys
+	return nil
+}
+
+func openStorageKey(sealed []byte) (*[32]byte, error) {
+	master := config.GetKeyring().StorageMasterKey()
+	if master == nil {
+		return nil, ErrNoStorageMasterKey
+	}
+	return keyring.OpenStorageKey(master, sealed)
+}
@@ -26,15 +26,27 @@ const (
 	Done State = "done"
 	// Errored state
 	Errored State = "errored"
+	// Waiting state, for a job that waits for its dependencies to be done
+	// before being queued
+	Waiting State = "waiting"
+	// Skipped state, for a job that has not been executed because one of its
+	// dependencies has failed
+	Skipped State = "skipped"
+	// DeadLetter state, for a job that has failed after all its retries and
+	// is kept to be re-queued later
+	DeadLetter State = "dead_letter"
 )
 
 // defaultMaxLimits defines the maximum limit of how much jobs will be returned
 // for each job state
 var defaultMaxLimits map[State]int = map[State]int{
-	Queued:  50,
-	Running: 50,
-	Done:    50,
-	Errored: 50,
+	Queued:     50,
+	Running:    50,
+	Done:       50,
+	Errored:    50,
+	Waiting:    50,
+	Skipped:    50,
+	DeadLetter: 50,
 }
 
 type (
@@ -88,6 +100,7 @@ type (
 		Event       Event       `json:"event"`
 		Payload     Payload     `json:"payload,omitempty"`
 		Manual      bool        `json:"manual_execution,omitempty"`
+		JobPriority Priority    `json:"priority,omitempty"`
 		Debounced   bool        `json:"debounced,omitempty"`
 		Options     *JobOptions `json:"options,omitempty"`
 		State       State       `json:"state"`
@@ -96,6 +109,15 @@ type (
 		FinishedAt  time.Time   `json:"finished_at"`
 		Error       string      `json:"error,omitempty"`
 		ForwardLogs bool        `json:"forward_logs,omitempty"`
+		DependsOn   []string    `json:"depends_on,omitempty"`
+		WorkflowID  string      `json:"workflow_id,omitempty"`
+		Errors      []JobError  `json:"errors,omitempty"`
+	}
+
+	// JobError is an error that has happened during an execution of a job.
+	JobError struct {
+		Error string    `json:"error"`
+		At    time.Time `json:"at"`
 	}
 
 	// JobRequest struct is used to represent a new job request.
@@ -107,9 +129,14 @@ type (
 		Event       Event
 		Payload     Payload
 		Manual      bool
+		Priority    Priority
 		Debounced   bool
 		ForwardLogs bool
 		Options     *JobOptions
+		// DependsOn is the list of the identifiers of the jobs that must be
+		// done before this job can be queued.
+		DependsOn  []string
+		WorkflowID string
 	}
 
 	// JobOptions struct contains the execution properties of the jobs.
@@ -152,6 +179,14 @@ func (j *Job) Clone() couchdb.Doc {
 		tmp := *j.Options
 		cloned.Options = &tmp
 	}
+	if j.DependsOn != nil {
+		cloned.DependsOn = make([]string, len(j.DependsOn))
+		copy(cloned.DependsOn, j.DependsOn)
+	}
+	if j.Errors != nil {
+		cloned.Errors = make([]JobError, len(j.Errors))
+		copy(cloned.Errors, j.Errors)
+	}
 	if j.Message != nil {
 		tmp := j.Message
 		j.Message = make([]byte, len(tmp))
@@ -243,6 +278,16 @@ func (j *Job) Nack(errorMessage string) error {
 	return j.Update()
 }
 
+// MoveToDeadLetters sets the job infos state to DeadLetter, with the specified
+// error, for a job that has exhausted its retries.
+func (j *Job) MoveToDeadLetters(errorMessage string) error {
+	j.Logger().Debugf("dead letter %s", j.ID())
+	j.FinishedAt = time.Now()
+	j.State = DeadLetter
+	j.Error = errorMessage
+	return j.Update()
+}
+
 // Update updates the job in couchdb
 func (j *Job) Update() error {
 	err := couchdb.UpdateDoc(j, j)
@@ -284,7 +329,7 @@ func (j *Job) WaitUntilDone(db prefixer.Prefixer) error {
 			switch state {
 			case Done:
 				return nil
-			case Errored:
+			case Errored, DeadLetter:
 				return errors.New("The konnector failed on account deletion")
 			}
 		case <-timeout:
@@ -332,12 +377,15 @@ func NewJob(db prefixer.Prefixer, req *JobRequest) *Job {
 		WorkerType:  req.WorkerType,
 		TriggerID:   req.TriggerID,
 		Manual:      req.Manual,
+		JobPriority: req.Priority,
 		Message:     req.Message,
 		Debounced:   req.Debounced,
 		Event:       req.Event,
 		Payload:     req.Payload,
 		Options:     req.Options,
 		ForwardLogs: req.ForwardLogs,
+		DependsOn:   req.DependsOn,
+		WorkflowID:  req.WorkflowID,
 		State:       Queued,
 		QueuedAt:    time.Now(),
 	}
@@ -456,7 +504,7 @@ func GetLastsJobs(jobs []*Job, workerType string) ([]*Job, error) {
 	// Ordering by QueuedAt before filtering jobs
 	sort.Slice(jobs, func(i, j int) bool { return jobs[i].QueuedAt.Before(jobs[j].QueuedAt) })
 
-	for _, state := range []State{Queued, Running, Done, Errored} {
+	for _, state := range []State{Queued, Running, Done, Errored, Waiting, Skipped, DeadLetter} {
 		limit := defaultMaxLimits[state]
 
 		filtered := FilterByWorkerAndState(jobs, workerType, state, limit)
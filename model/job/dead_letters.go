@@ -0,0 +1,74 @@
+package job
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// GetDeadLetters returns the jobs in the dead-letter state for the given
+// worker type, or for all the workers if the worker type is empty.
+func GetDeadLetters(db prefixer.Prefixer, workerType string) ([]*Job, error) {
+	worker := mango.Exists("worker")
+	if workerType != "" {
+		worker = mango.Equal("worker", workerType)
+	}
+	var results []*Job
+	req := &couchdb.FindRequest{
+		UseIndex: "by-worker-and-state",
+		Selector: mango.And(worker, mango.Equal("state", DeadLetter)),
+		Limit:    1000,
+	}
+	err := couchdb.FindDocs(db, consts.Jobs, req, &results)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RequeueDeadLetter puts a dead letter back in the queue of its worker. The
+// history of its errors is kept.
+func RequeueDeadLetter(db prefixer.Prefixer, jobID string) (*Job, error) {
+	e, ok := systemEnqueuer()
+	if !ok {
+		return nil, ErrClosed
+	}
+	j, err := Get(db, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if j.State != DeadLetter {
+		return nil, ErrNotDeadLetter
+	}
+	j.State = Queued
+	j.QueuedAt = time.Now()
+	j.StartedAt = time.Time{}
+	j.FinishedAt = time.Time{}
+	j.Error = ""
+	if err := j.Update(); err != nil {
+		return nil, err
+	}
+	if err := e.enqueue(j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// DiscardDeadLetter deletes a dead letter. The jobs that were waiting for it
+// are skipped.
+func DiscardDeadLetter(db prefixer.Prefixer, jobID string) error {
+	j, err := Get(db, jobID)
+	if err != nil {
+		return err
+	}
+	if j.State != DeadLetter {
+		return ErrNotDeadLetter
+	}
+	if err := couchdb.DeleteDoc(db, j); err != nil {
+		return err
+	}
+	return ReleaseDependents(j)
+}
@@ -0,0 +1,185 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// enqueuer is implemented by the brokers, to put in the queue a job whose
+// dependencies are done.
+type enqueuer interface {
+	enqueue(job *Job) error
+}
+
+// dependencyViewDefined is the set of the instances (by their prefix) where the
+// view for the waiting jobs is known to exist. The view is created with the
+// other views for the new instances, but it may be missing for the instances
+// that have not been updated since.
+var dependencyViewDefined sync.Map
+
+// defineDependencyView ensures that the view for the waiting jobs exists. The
+// request to CouchDB is made only once per instance.
+func defineDependencyView(db prefixer.Prefixer) error {
+	if _, ok := dependencyViewDefined.Load(db.DBPrefix()); ok {
+		return nil
+	}
+	if err := couchdb.DefineView(db, couchdb.JobsWaitingByDependencyView); err != nil {
+		return err
+	}
+	dependencyViewDefined.Store(db.DBPrefix(), struct{}{})
+	return nil
+}
+
+// checkDependencies verifies that the jobs the given job depends on exist, and
+// puts the job in the waiting state. It must be called before the job is
+// created in CouchDB.
+func checkDependencies(db prefixer.Prefixer, job *Job) error {
+	if len(job.DependsOn) == 0 {
+		return nil
+	}
+	// The client jobs are not put in a queue, they can't wait
+	if job.WorkerType == "client" {
+		return ErrInvalidDependency
+	}
+	deps, err := getJobs(db, job.DependsOn)
+	if err != nil {
+		return err
+	}
+	for _, dep := range deps {
+		if dep == nil {
+			return ErrInvalidDependency
+		}
+	}
+	if err := defineDependencyView(db); err != nil {
+		return err
+	}
+	job.State = Waiting
+	return nil
+}
+
+// resolveDependencies looks at the dependencies of a waiting job: if they are
+// all done, the job is queued, and if one of them has failed, the job is
+// skipped. Else, the job keeps waiting.
+func resolveDependencies(e enqueuer, job *Job) error {
+	deps, err := getJobs(job, job.DependsOn)
+	if err != nil {
+		return err
+	}
+	for i, dep := range deps {
+		if dep == nil {
+			return skipJob(e, job, fmt.Sprintf("dependency %s has been deleted", job.DependsOn[i]))
+		}
+		switch dep.State {
+		case Done:
+			continue
+		case Errored, Skipped:
+			return skipJob(e, job, fmt.Sprintf("dependency %s has %s", dep.ID(), dep.State))
+		default:
+			// A dead letter can still be re-queued, so the job keeps waiting
+			return nil
+		}
+	}
+
+	job.State = Queued
+	job.QueuedAt = time.Now()
+	if err := couchdb.UpdateDoc(job, job); err != nil {
+		// The job has already been released by another process
+		if couchdb.IsConflictError(err) {
+			return nil
+		}
+		return err
+	}
+	return e.enqueue(job)
+}
+
+// skipJob puts a waiting job in the skipped state, and the jobs that are
+// waiting for it are skipped too.
+func skipJob(e enqueuer, job *Job, reason string) error {
+	job.Logger().Debugf("skip %s: %s", job.ID(), reason)
+	job.State = Skipped
+	job.Error = reason
+	job.FinishedAt = time.Now()
+	if err := couchdb.UpdateDoc(job, job); err != nil {
+		if couchdb.IsConflictError(err) {
+			return nil
+		}
+		return err
+	}
+	return releaseDependents(e, job)
+}
+
+// releaseDependents resolves the dependencies of the jobs that are waiting for
+// the given job, now that it is finished.
+func releaseDependents(e enqueuer, job *Job) error {
+	var res couchdb.ViewResponse
+	err := couchdb.ExecView(job, couchdb.JobsWaitingByDependencyView, &couchdb.ViewRequest{
+		Key:         job.ID(),
+		IncludeDocs: true,
+	}, &res)
+	if err != nil {
+		// No job with dependencies has ever been pushed on this instance
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return nil
+		}
+		return err
+	}
+
+	var errm error
+	for _, row := range res.Rows {
+		dependent := &Job{}
+		if err := json.Unmarshal(row.Doc, dependent); err != nil {
+			errm = err
+			continue
+		}
+		if dependent.State != Waiting {
+			continue
+		}
+		if err := resolveDependencies(e, dependent); err != nil {
+			dependent.Logger().Warnf("Cannot resolve the dependencies of %s: %s", dependent.ID(), err)
+			errm = err
+		}
+	}
+	return errm
+}
+
+// ReleaseDependents must be called when a job is finished (done or errored):
+// the jobs that are waiting for it are queued or skipped if needed.
+func ReleaseDependents(job *Job) error {
+	e, ok := systemEnqueuer()
+	if !ok {
+		return nil
+	}
+	return releaseDependents(e, job)
+}
+
+// systemEnqueuer returns the broker of the global job system, if it can put
+// an existing job in its queues.
+func systemEnqueuer() (enqueuer, bool) {
+	if globalJobSystem == nil {
+		return nil, false
+	}
+	js, ok := globalJobSystem.(jobSystem)
+	if !ok {
+		return nil, false
+	}
+	e, ok := js.Broker.(enqueuer)
+	return e, ok
+}
+
+func getJobs(db prefixer.Prefixer, ids []string) ([]*Job, error) {
+	var jobs []*Job
+	req := &couchdb.AllDocsRequest{Keys: ids}
+	if err := couchdb.GetAllDocs(db, consts.Jobs, req, &jobs); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return make([]*Job, len(ids)), nil
+		}
+		return nil, err
+	}
+	return jobs, nil
+}
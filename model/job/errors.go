@@ -20,6 +20,21 @@ var (
 	ErrMessageNil = errors.New("jobs: message is nil")
 	// ErrMessageUnmarshal is used when unmarshalling a message causes an error
 	ErrMessageUnmarshal = errors.New("jobs: message unmarshal")
+	// ErrInvalidDependency is used when a job depends on a job that does not
+	// exist, or when a client job has dependencies
+	ErrInvalidDependency = errors.New("jobs: invalid dependency")
+	// ErrNotFoundWorkflow is used when the workflow could not be found
+	ErrNotFoundWorkflow = errors.New("jobs: workflow not found")
+	// ErrInvalidWorkflow is used when the jobs of a workflow can't be ordered
+	ErrInvalidWorkflow = errors.New("jobs: invalid workflow")
+	// ErrInvalidPriority is used when the priority of a job is not known
+	ErrInvalidPriority = errors.New("jobs: invalid priority")
+	// ErrInvalidFilter is used when the filter of an @event trigger is not
+	// valid
+	ErrInvalidFilter = errors.New("jobs: invalid filter")
+	// ErrNotDeadLetter is used when a job is expected to be a dead letter, but
+	// it is not the case
+	ErrNotDeadLetter = errors.New("jobs: not a dead letter")
 	// ErrAbort can be used to abort the execution of the job without causing
 	// errors.
 	ErrAbort = errors.New("jobs: abort")
@@ -44,3 +59,43 @@ type BadTriggerError struct {
 func (e BadTriggerError) Error() string {
 	return e.Err.Error()
 }
+
+// PermanentError is an error returned by a worker for a failure that can't be
+// fixed by executing the job again: the job won't be retried.
+type PermanentError struct {
+	Err error
+}
+
+func (e PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps the given error in a PermanentError.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return PermanentError{err}
+}
+
+// IsRetryable returns false if the error returned by a worker can't be
+// recovered from by a retry.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	var bad BadTriggerError
+	var permanent PermanentError
+	if errors.As(err, &bad) || errors.As(err, &permanent) {
+		return false
+	}
+	switch err {
+	case ErrAbort, ErrMessageUnmarshal, ErrMessageNil:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package job
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+type (
+	// QueueStats gives some statistics about the queue of a worker type.
+	QueueStats struct {
+		WorkerType string           `json:"worker"`
+		Len        int              `json:"len"`
+		ByPriority map[Priority]int `json:"by_priority"`
+		Instances  []InstanceStats  `json:"instances,omitempty"`
+		// MaxConcurrencyPerInstance is the maximal number of jobs of a single
+		// instance that can be executed at the same time (0 for no limit).
+		MaxConcurrencyPerInstance int `json:"max_concurrency_per_instance,omitempty"`
+	}
+
+	// InstanceStats is the number of jobs of an instance in a queue.
+	InstanceStats struct {
+		Domain  string `json:"domain"`
+		Queued  int    `json:"queued"`
+		Running int    `json:"running,omitempty"`
+	}
+
+	// queueStatser is implemented by the brokers that can give statistics on
+	// their queues.
+	queueStatser interface {
+		workerQueueStats(workerType string) (*QueueStats, error)
+	}
+
+	// releaser is implemented by the brokers that need to know when the
+	// execution of a job is finished, to limit the number of concurrent jobs
+	// per instance.
+	releaser interface {
+		release(job *Job)
+	}
+)
+
+// GetQueuesStats returns the statistics of the queues of all the workers.
+func GetQueuesStats() ([]*QueueStats, error) {
+	js, ok := globalJobSystem.(jobSystem)
+	if !ok {
+		return nil, ErrClosed
+	}
+	statser, ok := js.Broker.(queueStatser)
+	if !ok {
+		return nil, ErrClosed
+	}
+	var all []*QueueStats
+	for _, workerType := range js.WorkersTypes() {
+		stats, err := statser.workerQueueStats(workerType)
+		if err == ErrUnknownWorker {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(stats.Instances, func(i, j int) bool {
+			return stats.Instances[i].Queued > stats.Instances[j].Queued
+		})
+		all = append(all, stats)
+	}
+	return all, nil
+}
+
+// instanceToken returns the identifier of an instance used by the brokers to
+// share the workers between the instances. It contains what is needed to
+// load the jobs from CouchDB.
+func instanceToken(db prefixer.Prefixer) string {
+	prefix := db.DBPrefix()
+	if cluster := db.DBCluster(); cluster > 0 {
+		prefix = fmt.Sprintf("%s%%%d", prefix, cluster)
+	}
+	return prefix
+}
+
+// parseInstanceToken is the reverse of instanceToken.
+func parseInstanceToken(token string) prefixer.Prefixer {
+	parts := strings.SplitN(token, "%", 2)
+	var cluster int
+	if len(parts) > 1 {
+		cluster, _ = strconv.Atoi(parts[1])
+	}
+	return prefixer.NewPrefixer(cluster, "", parts[0])
+}
+
+// fairList is a list of jobs where the instances are served in a round-robin
+// fashion: a single instance with a lot of jobs can't delay the jobs of the
+// other instances.
+type fairList struct {
+	ring *list.List            // tokens of the instances with queued jobs
+	jobs map[string]*list.List // queued jobs by instance token
+}
+
+func newFairList() *fairList {
+	return &fairList{
+		ring: list.New(),
+		jobs: make(map[string]*list.List),
+	}
+}
+
+func (f *fairList) push(token string, job *Job) {
+	l, ok := f.jobs[token]
+	if !ok {
+		l = list.New()
+		f.jobs[token] = l
+		f.ring.PushBack(token)
+	}
+	l.PushBack(job)
+}
+
+// pop returns the first job of the next instance which is not full, or nil.
+func (f *fairList) pop(full func(token string) bool) (string, *Job) {
+	for i, n := 0, f.ring.Len(); i < n; i++ {
+		e := f.ring.Front()
+		token := e.Value.(string)
+		if full(token) {
+			f.ring.MoveToBack(e)
+			continue
+		}
+		l := f.jobs[token]
+		job := l.Remove(l.Front()).(*Job)
+		if l.Len() == 0 {
+			f.ring.Remove(e)
+			delete(f.jobs, token)
+		} else {
+			f.ring.MoveToBack(e)
+		}
+		return token, job
+	}
+	return "", nil
+}
+
+func (f *fairList) Len() int {
+	total := 0
+	for _, l := range f.jobs {
+		total += l.Len()
+	}
+	return total
+}
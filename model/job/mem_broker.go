@@ -1,12 +1,13 @@
 package job
 
 import (
-	"container/list"
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/limits"
@@ -22,9 +23,17 @@ type (
 		Jobs        chan *Job
 		closed      chan struct{}
 
-		list *list.List
-		run  bool
-		jmu  sync.RWMutex
+		lists   map[Priority]*fairList
+		rng     *rand.Rand
+		run     bool
+		stopped bool
+		jmu     sync.RWMutex
+
+		// maxPerInstance is the maximal number of jobs of an instance that
+		// can be executed at the same time (0 for no limit)
+		maxPerInstance int
+		running        map[string]int    // number of jobs sent by instance token
+		domains        map[string]string // domain names by instance token
 	}
 
 	// memBroker is an in-memory broker implementation of the Broker interface.
@@ -37,11 +46,19 @@ type (
 )
 
 // newMemQueue creates and a new in-memory queue.
-func newMemQueue(workerType string) *memQueue {
+func newMemQueue(workerType string, maxPerInstance int) *memQueue {
+	lists := make(map[Priority]*fairList, len(priorities))
+	for _, p := range priorities {
+		lists[p] = newFairList()
+	}
 	return &memQueue{
-		list:   list.New(),
-		Jobs:   make(chan *Job),
-		closed: make(chan struct{}),
+		lists:          lists,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		Jobs:           make(chan *Job),
+		closed:         make(chan struct{}),
+		maxPerInstance: maxPerInstance,
+		running:        make(map[string]int),
+		domains:        make(map[string]string),
 	}
 }
 
@@ -49,36 +66,72 @@ func newMemQueue(workerType string) *memQueue {
 func (q *memQueue) Enqueue(job *Job) error {
 	q.jmu.Lock()
 	defer q.jmu.Unlock()
-	q.list.PushBack(job.Clone())
-	if !q.run {
+	token := instanceToken(job)
+	q.domains[token] = job.DomainName()
+	q.lists[job.Priority()].push(token, job.Clone().(*Job))
+	q.start()
+	return nil
+}
+
+// start launches the goroutine that sends the jobs to the workers, if it is
+// not already running. It must be called with the lock.
+func (q *memQueue) start() {
+	if !q.run && !q.stopped {
 		q.run = true
 		go q.send()
 	}
-	return nil
+}
+
+func (q *memQueue) full(token string) bool {
+	return q.maxPerInstance > 0 && q.running[token] >= q.maxPerInstance
 }
 
 func (q *memQueue) send() {
 	for {
 		q.jmu.Lock()
-		e := q.list.Front()
-		if e == nil || !q.run {
+		var job *Job
+		var token string
+		for _, p := range priorityOrder(q.rng) {
+			if token, job = q.lists[p].pop(q.full); job != nil {
+				break
+			}
+		}
+		// When all the instances with queued jobs have reached their limit,
+		// the goroutine stops, and it will be started again by release.
+		if job == nil || !q.run {
 			q.run = false
 			q.jmu.Unlock()
 			return
 		}
-		q.list.Remove(e)
+		q.running[token]++
 		q.jmu.Unlock()
 		select {
 		case <-q.closed:
 			return
-		case q.Jobs <- e.Value.(*Job):
+		case q.Jobs <- job:
 		}
 	}
 }
 
+// release must be called when the execution of a job is finished.
+func (q *memQueue) release(job *Job) {
+	q.jmu.Lock()
+	defer q.jmu.Unlock()
+	token := instanceToken(job)
+	if q.running[token] <= 1 {
+		delete(q.running, token)
+	} else {
+		q.running[token]--
+	}
+	if q.lenLocked() > 0 {
+		q.start()
+	}
+}
+
 func (q *memQueue) close() {
 	q.jmu.Lock()
 	defer q.jmu.Unlock()
+	q.stopped = true
 	if !q.run {
 		return
 	}
@@ -90,7 +143,58 @@ func (q *memQueue) close() {
 func (q *memQueue) Len() int {
 	q.jmu.RLock()
 	defer q.jmu.RUnlock()
-	return q.list.Len()
+	return q.lenLocked()
+}
+
+func (q *memQueue) lenLocked() int {
+	total := 0
+	for _, l := range q.lists {
+		total += l.Len()
+	}
+	return total
+}
+
+// lenByPriority returns the length of the queue for each priority
+func (q *memQueue) lenByPriority() map[Priority]int {
+	q.jmu.RLock()
+	defer q.jmu.RUnlock()
+	lens := make(map[Priority]int, len(q.lists))
+	for p, l := range q.lists {
+		lens[p] = l.Len()
+	}
+	return lens
+}
+
+func (q *memQueue) stats() *QueueStats {
+	q.jmu.RLock()
+	defer q.jmu.RUnlock()
+	stats := &QueueStats{
+		ByPriority:                make(map[Priority]int, len(q.lists)),
+		MaxConcurrencyPerInstance: q.maxPerInstance,
+	}
+	byInstance := make(map[string]*InstanceStats)
+	get := func(token string) *InstanceStats {
+		s, ok := byInstance[token]
+		if !ok {
+			s = &InstanceStats{Domain: q.domains[token]}
+			byInstance[token] = s
+		}
+		return s
+	}
+	for p, l := range q.lists {
+		stats.ByPriority[p] = l.Len()
+		stats.Len += l.Len()
+		for token, jobs := range l.jobs {
+			get(token).Queued += jobs.Len()
+		}
+	}
+	for token, n := range q.running {
+		get(token).Running = n
+	}
+	for _, s := range byInstance {
+		stats.Instances = append(stats.Instances, *s)
+	}
+	return stats
 }
 
 // NewMemBroker creates a new in-memory broker system.
@@ -113,8 +217,9 @@ func (b *memBroker) StartWorkers(ws WorkersList) error {
 		if conf.Concurrency <= 0 {
 			continue
 		}
-		q := newMemQueue(conf.WorkerType)
+		q := newMemQueue(conf.WorkerType, conf.MaxConcurrencyPerInstance)
 		w := NewWorker(conf)
+		w.broker = b
 		b.queues[conf.WorkerType] = q
 		b.workers = append(b.workers, w)
 		if err := w.Start(q.Jobs); err != nil {
@@ -175,6 +280,9 @@ func (b *memBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, error)
 	if atomic.LoadUint32(&b.running) == 0 {
 		return nil, ErrClosed
 	}
+	if !req.Priority.IsValid() {
+		return nil, ErrInvalidPriority
+	}
 
 	workerType := req.WorkerType
 	var worker *Worker
@@ -215,6 +323,9 @@ func (b *memBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, error)
 		}
 	}
 
+	if err := checkDependencies(db, job); err != nil {
+		return nil, err
+	}
 	if err := job.Create(); err != nil {
 		return nil, err
 	}
@@ -224,13 +335,28 @@ func (b *memBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, error)
 		return job, nil
 	}
 
-	q := b.queues[workerType]
-	if err := q.Enqueue(job); err != nil {
+	// The dependencies may have finished before the job was created.
+	if job.State == Waiting {
+		if err := resolveDependencies(b, job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	if err := b.enqueue(job); err != nil {
 		return nil, err
 	}
 	return job, nil
 }
 
+func (b *memBroker) enqueue(job *Job) error {
+	q, ok := b.queues[job.WorkerType]
+	if !ok {
+		return ErrUnknownWorker
+	}
+	return q.Enqueue(job)
+}
+
 // WorkerQueueLen returns the size of the number of elements in queue of the
 // specified worker type.
 func (b *memBroker) WorkerQueueLen(workerType string) (int, error) {
@@ -241,6 +367,30 @@ func (b *memBroker) WorkerQueueLen(workerType string) (int, error) {
 	return q.Len(), nil
 }
 
+func (b *memBroker) workerQueueStats(workerType string) (*QueueStats, error) {
+	q, ok := b.queues[workerType]
+	if !ok {
+		return nil, ErrUnknownWorker
+	}
+	stats := q.stats()
+	stats.WorkerType = workerType
+	return stats, nil
+}
+
+func (b *memBroker) release(job *Job) {
+	if q, ok := b.queues[job.WorkerType]; ok {
+		q.release(job)
+	}
+}
+
+func (b *memBroker) workerQueueLenByPriority(workerType string) (map[Priority]int, error) {
+	q, ok := b.queues[workerType]
+	if !ok {
+		return nil, ErrUnknownWorker
+	}
+	return q.lenByPriority(), nil
+}
+
 func (b *memBroker) WorkerIsReserved(workerType string) (bool, error) {
 	for _, w := range b.workers {
 		if w.Type == workerType {
@@ -2,6 +2,7 @@ package job_test
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
 	"sync"
@@ -153,6 +154,71 @@ func TestMemBroker(t *testing.T) {
 		assert.Equal(t, job.ErrUnknownWorker, err)
 	})
 
+	t.Run("Dependencies", func(t *testing.T) {
+		release := make(chan struct{})
+		executed := make(chan string, 10)
+
+		broker := job.NewMemBroker()
+		assert.NoError(t, broker.StartWorkers(job.WorkersList{
+			{
+				WorkerType:   "deps",
+				Concurrency:  2,
+				MaxExecCount: 1,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var msg string
+					if err := ctx.UnmarshalMessage(&msg); err != nil {
+						return err
+					}
+					if msg == "first" {
+						<-release
+					}
+					executed <- msg
+					if msg == "fail" {
+						return errors.New("fail")
+					}
+					return nil
+				},
+			},
+		}))
+
+		push := func(msg string, deps ...string) *job.Job {
+			m, _ := job.NewMessage(msg)
+			j, err := broker.PushJob(testInstance, &job.JobRequest{
+				WorkerType: "deps",
+				Message:    m,
+				DependsOn:  deps,
+			})
+			assert.NoError(t, err)
+			return j
+		}
+
+		first := push("first")
+		second := push("second", first.ID())
+		assert.Equal(t, job.Waiting, second.State)
+		close(release)
+		assert.Equal(t, "first", <-executed)
+		assert.Equal(t, "second", <-executed)
+
+		failed := push("fail")
+		skipped := push("skipped", failed.ID())
+		skippedToo := push("skipped-too", skipped.ID())
+		assert.Equal(t, "fail", <-executed)
+		assert.Eventually(t, func() bool {
+			j, err := job.Get(testInstance, skippedToo.ID())
+			return err == nil && j.State == job.Skipped
+		}, 5*time.Second, 50*time.Millisecond)
+		j, err := job.Get(testInstance, skipped.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, job.Skipped, j.State)
+		assert.Len(t, executed, 0)
+
+		_, err = broker.PushJob(testInstance, &job.JobRequest{
+			WorkerType: "deps",
+			DependsOn:  []string{"unknown-job-id"},
+		})
+		assert.ErrorIs(t, err, job.ErrInvalidDependency)
+	})
+
 	t.Run("UnknownMessageType", func(t *testing.T) {
 		var w sync.WaitGroup
 
@@ -246,6 +312,208 @@ func TestMemBroker(t *testing.T) {
 		w.Wait()
 	})
 
+	t.Run("DeadLetter", func(t *testing.T) {
+		broker := job.NewMemBroker()
+		assert.NoError(t, broker.StartWorkers(job.WorkersList{
+			{
+				WorkerType:    "dead-letter",
+				Concurrency:   1,
+				MaxExecCount:  3,
+				RetryDelay:    1 * time.Millisecond,
+				MaxRetryDelay: 2 * time.Millisecond,
+				DeadLetter:    true,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var permanent bool
+					if err := ctx.UnmarshalMessage(&permanent); err != nil {
+						return err
+					}
+					if permanent {
+						return job.Permanent(errors.New("permanent failure"))
+					}
+					return errors.New("temporary failure")
+				},
+			},
+		}))
+
+		waitFinished := func(j *job.Job) *job.Job {
+			var finished *job.Job
+			assert.Eventually(t, func() bool {
+				var err error
+				finished, err = job.Get(testInstance, j.ID())
+				return err == nil && (finished.State == job.Errored || finished.State == job.DeadLetter)
+			}, 5*time.Second, 20*time.Millisecond)
+			return finished
+		}
+
+		msg, _ := job.NewMessage(false)
+		j, err := broker.PushJob(testInstance, &job.JobRequest{WorkerType: "dead-letter", Message: msg})
+		assert.NoError(t, err)
+		j = waitFinished(j)
+		assert.Equal(t, job.DeadLetter, j.State)
+		assert.Equal(t, "temporary failure", j.Error)
+		assert.Len(t, j.Errors, 3)
+
+		letters, err := job.GetDeadLetters(testInstance, "dead-letter")
+		assert.NoError(t, err)
+		if assert.Len(t, letters, 1) {
+			assert.Equal(t, j.ID(), letters[0].ID())
+		}
+
+		// A permanent error is not retried, and the job is not a dead letter
+		msg, _ = job.NewMessage(true)
+		j, err = broker.PushJob(testInstance, &job.JobRequest{WorkerType: "dead-letter", Message: msg})
+		assert.NoError(t, err)
+		j = waitFinished(j)
+		assert.Equal(t, job.Errored, j.State)
+		assert.Len(t, j.Errors, 1)
+	})
+
+	t.Run("Priorities", func(t *testing.T) {
+		release := make(chan struct{})
+		executed := make(chan string, 41)
+
+		broker := job.NewMemBroker()
+		assert.NoError(t, broker.StartWorkers(job.WorkersList{
+			{
+				WorkerType:   "priorities",
+				Concurrency:  1,
+				MaxExecCount: 1,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var msg string
+					if err := ctx.UnmarshalMessage(&msg); err != nil {
+						return err
+					}
+					if msg == "block" {
+						<-release
+					}
+					executed <- msg
+					return nil
+				},
+			},
+		}))
+
+		push := func(msg string, priority job.Priority) {
+			m, _ := job.NewMessage(msg)
+			_, err := broker.PushJob(testInstance, &job.JobRequest{
+				WorkerType: "priorities",
+				Message:    m,
+				Priority:   priority,
+			})
+			assert.NoError(t, err)
+		}
+
+		push("block", job.PriorityNormal)
+		for i := 0; i < 20; i++ {
+			push("low", job.PriorityLow)
+		}
+		for i := 0; i < 20; i++ {
+			push("high", job.PriorityHigh)
+		}
+		close(release)
+		assert.Equal(t, "block", <-executed)
+
+		// The high priority jobs are taken first most of the time, but not
+		// always, to avoid the starvation of the low priority queue.
+		high := 0
+		for i := 0; i < 20; i++ {
+			if <-executed == "high" {
+				high++
+			}
+		}
+		assert.Greater(t, high, 12)
+		for i := 0; i < 20; i++ {
+			<-executed
+		}
+
+		_, err := broker.PushJob(testInstance, &job.JobRequest{
+			WorkerType: "priorities",
+			Priority:   "urgent",
+		})
+		assert.ErrorIs(t, err, job.ErrInvalidPriority)
+	})
+
+	t.Run("Fairness", func(t *testing.T) {
+		otherInstance := testutils.NewSetup(t, t.Name()).GetTestInstance()
+		release := make(chan struct{})
+		executed := make(chan string, 20)
+
+		broker := job.NewMemBroker()
+		assert.NoError(t, broker.StartWorkers(job.WorkersList{
+			{
+				WorkerType:   "fairness",
+				Concurrency:  1,
+				MaxExecCount: 1,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var msg string
+					if err := ctx.UnmarshalMessage(&msg); err != nil {
+						return err
+					}
+					if msg == "block" {
+						<-release
+					}
+					executed <- ctx.Instance.Domain
+					return nil
+				},
+			},
+			{
+				WorkerType:                "limited",
+				Concurrency:               2,
+				MaxExecCount:              1,
+				MaxConcurrencyPerInstance: 1,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var msg string
+					if err := ctx.UnmarshalMessage(&msg); err != nil {
+						return err
+					}
+					if msg == "block" {
+						<-release
+					}
+					executed <- ctx.Instance.Domain
+					return nil
+				},
+			},
+		}))
+
+		push := func(db prefixer.Prefixer, workerType, msg string) {
+			m, _ := job.NewMessage(msg)
+			_, err := broker.PushJob(db, &job.JobRequest{
+				WorkerType: workerType,
+				Message:    m,
+			})
+			assert.NoError(t, err)
+		}
+
+		// The jobs of the other instance don't wait for all the jobs of the
+		// first instance to be executed.
+		push(testInstance, "fairness", "block")
+		for i := 0; i < 10; i++ {
+			push(testInstance, "fairness", "bulk")
+		}
+		push(otherInstance, "fairness", "interactive")
+		release <- struct{}{}
+		assert.Equal(t, testInstance.Domain, <-executed)
+		found := false
+		for i := 0; i < 3; i++ {
+			if <-executed == otherInstance.Domain {
+				found = true
+			}
+		}
+		assert.True(t, found)
+		for i := 0; i < 8; i++ {
+			<-executed
+		}
+
+		// When an instance has reached its limit of concurrent jobs, the free
+		// workers take the jobs of the other instances.
+		push(testInstance, "limited", "block")
+		push(testInstance, "limited", "next")
+		push(otherInstance, "limited", "interactive")
+		assert.Equal(t, otherInstance.Domain, <-executed)
+		close(release)
+		assert.Equal(t, testInstance.Domain, <-executed)
+		assert.Equal(t, testInstance.Domain, <-executed)
+	})
+
 	t.Run("PanicRetried", func(t *testing.T) {
 		var w sync.WaitGroup
 
@@ -34,6 +34,73 @@ func (i *workersQueuesCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// priorityQueuesLener is implemented by the brokers that can give the length
+// of their queues for each priority.
+type priorityQueuesLener interface {
+	workerQueueLenByPriority(workerType string) (map[Priority]int, error)
+}
+
+type workersPriorityQueuesCollector struct {
+	prometheus.Desc
+}
+
+func newWorkersPriorityQueuesCollector() prometheus.Collector {
+	desc := prometheus.NewDesc(
+		prometheus.BuildFQName("workers", "queues", "len_by_priority"),
+		`Len of the workers queues by worker type and priority`,
+		[]string{"worker_type", "priority"},
+		prometheus.Labels{},
+	)
+	return &workersPriorityQueuesCollector{*desc}
+}
+
+func (i *workersPriorityQueuesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- &i.Desc
+}
+
+func (i *workersPriorityQueuesCollector) Collect(ch chan<- prometheus.Metric) {
+	js, ok := globalJobSystem.(jobSystem)
+	if !ok {
+		return
+	}
+	broker, ok := js.Broker.(priorityQueuesLener)
+	if !ok {
+		return
+	}
+	for _, workerType := range js.WorkersTypes() {
+		lens, err := broker.workerQueueLenByPriority(workerType)
+		if err != nil {
+			continue
+		}
+		for priority, count := range lens {
+			ch <- prometheus.MustNewConstMetric(
+				&i.Desc, prometheus.GaugeValue, float64(count),
+				workerType, string(priority),
+			)
+		}
+	}
+}
+
+// jobsWaitDurations is a histogram of the time spent by the jobs in the
+// queues, before being executed, labelled by worker type and priority.
+var jobsWaitDurations = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "workers",
+		Subsystem: "jobs",
+		Name:      "wait_durations",
+
+		Help: "Time in seconds spent by the jobs in the queues, labelled by worker type and priority.",
+
+		// From 10ms to ~45 minutes
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	},
+	[]string{"worker_type", "priority"},
+)
+
 func init() {
-	prometheus.MustRegister(newWorkersQueuesCollector())
+	prometheus.MustRegister(
+		newWorkersQueuesCollector(),
+		newWorkersPriorityQueuesCollector(),
+		jobsWaitDurations,
+	)
 }
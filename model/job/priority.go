@@ -0,0 +1,80 @@
+package job
+
+import "math/rand"
+
+// Priority is used to choose the queue of a job: for a worker type, the jobs
+// with a high priority are taken before the jobs with a lower priority, but
+// the low priority queues are still regularly served to avoid starvation.
+type Priority string
+
+const (
+	// PriorityHigh is for the interactive jobs, where the user is waiting for
+	// the result.
+	PriorityHigh Priority = "high"
+	// PriorityNormal is the default priority.
+	PriorityNormal Priority = "normal"
+	// PriorityLow is for the bulk jobs, like a backfill or a mass
+	// regeneration.
+	PriorityLow Priority = "low"
+)
+
+// priorities is the list of the priorities, from the highest to the lowest.
+var priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// priorityWeights is how often a queue is served first, relatively to the
+// other queues of the same worker.
+var priorityWeights = map[Priority]int{
+	PriorityHigh:   6,
+	PriorityNormal: 3,
+	PriorityLow:    1,
+}
+
+// IsValid returns true if the priority is known, or empty (for the default
+// priority).
+func (p Priority) IsValid() bool {
+	if p == "" {
+		return true
+	}
+	_, ok := priorityWeights[p]
+	return ok
+}
+
+// Priority returns the priority of the job. When the priority has not been
+// set explicitly, the manual jobs have a high priority, and the other jobs a
+// normal priority.
+func (j *Job) Priority() Priority {
+	if j.JobPriority != "" {
+		return j.JobPriority
+	}
+	if j.Manual {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// priorityOrder returns the priorities in the order their queues should be
+// polled. The first one is chosen randomly according to the weights, and the
+// others follow in decreasing priority.
+func priorityOrder(rng *rand.Rand) []Priority {
+	total := 0
+	for _, p := range priorities {
+		total += priorityWeights[p]
+	}
+	n := rng.Intn(total)
+	first := priorities[0]
+	for _, p := range priorities {
+		n -= priorityWeights[p]
+		if n < 0 {
+			first = p
+			break
+		}
+	}
+	order := make([]Priority, 0, len(priorities))
+	order = append(order, first)
+	for _, p := range priorities {
+		if p != first {
+			order = append(order, p)
+		}
+	}
+	return order
+}
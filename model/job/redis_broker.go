@@ -23,8 +23,129 @@ const (
 	redisPrefix = "j/"
 	// redisHighPrioritySuffix suffix is the suffix used for prioritized queue.
 	redisHighPrioritySuffix = "/p0"
+	// redisLowPrioritySuffix suffix is the suffix used for the queue of the
+	// jobs with a low priority.
+	redisLowPrioritySuffix = "/p2"
 )
 
+// For each worker type and priority, the queue is made of:
+//   - a list with the tokens of the instances that have queued jobs, used as
+//     a ring to serve the instances in a round-robin fashion
+//   - a hash with the number of queued jobs for each instance token
+//   - a list of job identifiers for each instance token.
+//
+// And, when the number of concurrent jobs per instance is limited, a sorted
+// set with the running jobs of each instance, scored by the time after which
+// they are considered as lost.
+//
+// The ring is only modified inside lua scripts, so that a token can't be lost
+// if a stack crashes. As a script can't block, the stacks wait on a wake list
+// of the worker type, where an element is pushed when a job is queued or
+// released.
+//
+// The scripts only use the keys given in KEYS, and all the keys of a worker
+// type have the same hash tag, so that they are in the same slot of a redis
+// cluster.
+func redisWorkerKey(workerType string) string { return redisPrefix + "{" + workerType + "}" }
+
+func redisLensKey(queueKey string) string { return queueKey + "/lens" }
+
+func redisJobsKey(queueKey, token string) string { return queueKey + "/d/" + token }
+
+func redisRunningKey(workerType, token string) string {
+	return redisWorkerKey(workerType) + "/running/" + token
+}
+
+func redisWakeKey(workerType string) string { return redisWorkerKey(workerType) + "/wake" }
+
+// redisInstancesKey is the key of the hash with the domain names of the
+// instance tokens.
+func redisInstancesKey(workerType string) string {
+	return redisWorkerKey(workerType) + "/instances"
+}
+
+// redisWakeMaxLen is the maximal length of the wake lists. When no stack is
+// waiting, the elements are just kept to avoid missing the jobs, and more
+// elements than that would be useless.
+const redisWakeMaxLen = 100
+
+// redisPopMaxTokens is the maximal number of instance tokens looked at by a
+// call to pop for each priority.
+const redisPopMaxTokens = 100
+
+// redisEnqueueScript adds a job identifier to the list of its instance, and
+// the instance token to the ring if it was not already here. An element is
+// also pushed to the wake list to notify the stacks waiting for a job.
+var redisEnqueueScript = redis.NewScript(`
+redis.call('LPUSH', KEYS[1], ARGV[2])
+redis.call('HSET', KEYS[4], ARGV[1], ARGV[3])
+if redis.call('HINCRBY', KEYS[2], ARGV[1], 1) == 1 then
+  redis.call('LPUSH', KEYS[3], ARGV[1])
+end
+redis.call('LPUSH', KEYS[5], 1)
+redis.call('LTRIM', KEYS[5], 0, ARGV[4] - 1)
+return 1
+`)
+
+// redisPopScript takes the next job identifier of the instance token given in
+// ARGV[1], if this token is still at the end of the ring. The token is popped
+// from the ring, and put back at its beginning if the instance has other jobs,
+// or if it has reached its limit of concurrent jobs. It returns "ok" and the
+// job identifier, "full" if the instance has reached its limit, or "retry" if
+// the ring has changed and the next token must be read again.
+var redisPopScript = redis.NewScript(`
+local token = ARGV[1]
+local max = tonumber(ARGV[2])
+if redis.call('LINDEX', KEYS[1], -1) ~= token then
+  return {'retry'}
+end
+redis.call('RPOP', KEYS[1])
+if max > 0 then
+  redis.call('ZREMRANGEBYSCORE', KEYS[4], '-inf', ARGV[3])
+  if redis.call('ZCARD', KEYS[4]) >= max then
+    redis.call('LPUSH', KEYS[1], token)
+    return {'full'}
+  end
+end
+local id = redis.call('RPOP', KEYS[3])
+if not id then
+  redis.call('HDEL', KEYS[2], token)
+  return {'retry'}
+end
+if redis.call('HINCRBY', KEYS[2], token, -1) > 0 then
+  redis.call('LPUSH', KEYS[1], token)
+else
+  redis.call('HDEL', KEYS[2], token)
+end
+if max > 0 then
+  redis.call('ZADD', KEYS[4], ARGV[4], id)
+end
+return {'ok', id}
+`)
+
+// redisQueueKey returns the key of the redis list used as a ring of instance
+// tokens for the given worker type and priority.
+func redisQueueKey(workerType string, priority Priority) string {
+	return redisWorkerKey(workerType) + redisPrioritySuffix(priority)
+}
+
+// redisLegacyQueueKey returns the key of the redis list where the jobs were
+// queued, with prefix/jobID values, before the instances were served in a
+// round-robin fashion. These lists are still emptied after an upgrade.
+func redisLegacyQueueKey(workerType string, priority Priority) string {
+	return redisPrefix + workerType + redisPrioritySuffix(priority)
+}
+
+func redisPrioritySuffix(priority Priority) string {
+	switch priority {
+	case PriorityHigh:
+		return redisHighPrioritySuffix
+	case PriorityLow:
+		return redisLowPrioritySuffix
+	}
+	return ""
+}
+
 type redisBroker struct {
 	client         redis.UniversalClient
 	ctx            context.Context
@@ -54,6 +175,7 @@ func (b *redisBroker) StartWorkers(ws WorkersList) error {
 	for _, conf := range ws {
 		b.workersTypes = append(b.workersTypes, conf.WorkerType)
 		w := NewWorker(conf)
+		w.broker = b
 		b.workers = append(b.workers, w)
 		if conf.Concurrency <= 0 {
 			continue
@@ -63,7 +185,7 @@ func (b *redisBroker) StartWorkers(ws WorkersList) error {
 		if err := w.Start(ch); err != nil {
 			return err
 		}
-		go b.pollLoop(redisPrefix+conf.WorkerType, ch)
+		go b.pollLoop(w, ch)
 	}
 
 	if len(b.workersRunning) > 0 {
@@ -132,7 +254,7 @@ func SetRedisTimeoutForTest() {
 	redisBRPopTimeout = 1 * time.Second
 }
 
-func (b *redisBroker) pollLoop(key string, ch chan<- *Job) {
+func (b *redisBroker) pollLoop(w *Worker, ch chan<- *Job) {
 	defer func() {
 		b.closed <- struct{}{}
 	}()
@@ -143,50 +265,133 @@ func (b *redisBroker) pollLoop(key string, ch chan<- *Job) {
 			return
 		}
 
-		// The brpop redis command will always take elements in priority from the
-		// first key containing elements at the call. By always priorizing the
-		// manual queue, this would cause a starvation for our main queue if too
-		// many "manual" jobs are pushed. By randomizing the order we make sure we
-		// avoid such starvation. For one in three call, the main queue is
-		// selected.
-		keyP0 := key + redisHighPrioritySuffix
-		keyP1 := key
-		if rng.Intn(3) == 0 {
-			keyP1, keyP0 = keyP0, keyP1
-		}
-		results, err := b.client.BRPop(b.ctx, redisBRPopTimeout, keyP0, keyP1).Result()
-		if err != nil || len(results) < 2 {
+		// By always priorizing the high priority queue, this would cause a
+		// starvation for the other queues if too many jobs are pushed with a
+		// high priority. By randomizing the order (with weights) we make sure
+		// we avoid such starvation.
+		order := priorityOrder(rng)
+		token, jobID, err := b.pop(w, order)
+		if err != nil {
+			joblog.Warnf("Cannot pop a job for %s: %s", w.Type, err)
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
+		if token == "" {
+			// No job can be run for now, wait until a job is queued or
+			// released (or the timeout, for the lost jobs).
+			err := b.client.BRPop(b.ctx, redisBRPopTimeout, redisWakeKey(w.Type)).Err()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				time.Sleep(100 * time.Millisecond)
+			}
+			continue
+		}
 
-		key, val := results[0], results[1]
-		if len(key) < len(redisPrefix) {
-			joblog.Warnf("Invalid key %s", key)
+		db := parseInstanceToken(token)
+		job, err := Get(db, jobID)
+		if err != nil {
+			joblog.Warnf("Cannot find job %s on domain %s (%d): %s",
+				jobID, db.DBPrefix(), db.DBCluster(), err)
+			b.release(&Job{JobID: jobID, WorkerType: w.Type, Prefix: db.DBPrefix(), Cluster: db.DBCluster()})
 			continue
 		}
 
-		parts := strings.SplitN(val, "/", 2)
-		if len(parts) != 2 {
-			joblog.Warnf("Invalid val %s", val)
+		ch <- job
+	}
+}
+
+// pop returns the instance token and the identifier of the next job that can
+// be run from the queues of the given priorities, or empty strings if there
+// are none.
+func (b *redisBroker) pop(w *Worker, order []Priority) (string, string, error) {
+	for _, p := range order {
+		token, jobID, err := b.popQueue(w, redisQueueKey(w.Type, p))
+		if err != nil || token != "" {
+			return token, jobID, err
+		}
+	}
+	for _, p := range order {
+		val, err := b.client.RPop(b.ctx, redisLegacyQueueKey(w.Type, p)).Result()
+		if errors.Is(err, redis.Nil) {
 			continue
 		}
+		if err != nil {
+			return "", "", err
+		}
+		token, jobID, _ := strings.Cut(val, "/")
+		return token, jobID, nil
+	}
+	return "", "", nil
+}
+
+// popQueue looks at the instance tokens of the ring, from its end, until it
+// finds one that has a job that can be run.
+func (b *redisBroker) popQueue(w *Worker, queueKey string) (string, string, error) {
+	conf := w.defaultedConf(nil)
+	max := conf.MaxConcurrencyPerInstance
+	// A job that has not been released after all its tries is considered as
+	// lost, to not block its instance forever.
+	lost := time.Duration(conf.MaxExecCount) * (conf.Timeout + conf.MaxRetryDelay)
 
-		jobID := parts[1]
-		parts = strings.SplitN(parts[0], "%", 2)
-		prefix := parts[0]
-		var cluster int
-		if len(parts) > 1 {
-			cluster, _ = strconv.Atoi(parts[1])
+	n, err := b.client.LLen(b.ctx, queueKey).Result()
+	if err != nil {
+		return "", "", err
+	}
+	if n > redisPopMaxTokens {
+		n = redisPopMaxTokens
+	}
+	for i := int64(0); i < n; i++ {
+		token, err := b.client.LIndex(b.ctx, queueKey, -1).Result()
+		if errors.Is(err, redis.Nil) {
+			return "", "", nil
 		}
-		job, err := Get(prefixer.NewPrefixer(cluster, "", prefix), jobID)
 		if err != nil {
-			joblog.Warnf("Cannot find job %s on domain %s (%d): %s",
-				jobID, prefix, cluster, err)
+			return "", "", err
+		}
+		keys := []string{
+			queueKey,
+			redisLensKey(queueKey),
+			redisJobsKey(queueKey, token),
+			redisRunningKey(w.Type, token),
+		}
+		now := time.Now()
+		res, err := redisPopScript.Run(b.ctx, b.client, keys, token, max,
+			now.Unix(), now.Add(lost).Unix()).StringSlice()
+		if err != nil {
+			return "", "", err
+		}
+		switch {
+		case len(res) == 2 && res[0] == "ok":
+			return token, res[1], nil
+		case len(res) == 1 && (res[0] == "full" || res[0] == "retry"):
 			continue
+		default:
+			return "", "", fmt.Errorf("unexpected result from redis: %v", res)
 		}
+	}
+	return "", "", nil
+}
 
-		ch <- job
+// release removes the job from the running jobs of its instance.
+func (b *redisBroker) release(job *Job) {
+	for _, w := range b.workers {
+		if w.Type != job.WorkerType {
+			continue
+		}
+		if w.Conf.MaxConcurrencyPerInstance > 0 {
+			key := redisRunningKey(w.Type, instanceToken(job))
+			wake := redisWakeKey(w.Type)
+			// Another job of the instance may have been waiting for this one
+			_, err := b.client.TxPipelined(b.ctx, func(pipe redis.Pipeliner) error {
+				pipe.ZRem(b.ctx, key, job.JobID)
+				pipe.LPush(b.ctx, wake, 1)
+				pipe.LTrim(b.ctx, wake, 0, redisWakeMaxLen-1)
+				return nil
+			})
+			if err != nil {
+				joblog.Warnf("Cannot release job %s: %s", job.JobID, err)
+			}
+		}
+		return
 	}
 }
 
@@ -196,6 +401,9 @@ func (b *redisBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, erro
 	if atomic.LoadUint32(&b.running) == 0 {
 		return nil, ErrClosed
 	}
+	if !req.Priority.IsValid() {
+		return nil, ErrInvalidPriority
+	}
 
 	var worker *Worker
 	for _, w := range b.workers {
@@ -235,6 +443,9 @@ func (b *redisBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, erro
 		}
 	}
 
+	if err := checkDependencies(db, job); err != nil {
+		return nil, err
+	}
 	if err := job.Create(); err != nil {
 		return nil, err
 	}
@@ -244,39 +455,143 @@ func (b *redisBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, erro
 		return job, nil
 	}
 
-	key := redisPrefix + job.WorkerType
-	prefix := job.DBPrefix()
-	if cluster := job.DBCluster(); cluster > 0 {
-		prefix = fmt.Sprintf("%s%%%d", prefix, cluster)
-	}
-	val := prefix + "/" + job.JobID
-
-	// When the job is manual, it is being pushed in a specific prioritized
-	// queue.
-	if job.Manual {
-		key += redisHighPrioritySuffix
+	// The dependencies may have finished before the job was created.
+	if job.State == Waiting {
+		if err := resolveDependencies(b, job); err != nil {
+			return nil, err
+		}
+		return job, nil
 	}
 
-	if err := b.client.LPush(b.ctx, key, val).Err(); err != nil {
+	if err := b.enqueue(job); err != nil {
 		return nil, err
 	}
-
 	return job, nil
 }
 
+func (b *redisBroker) enqueue(job *Job) error {
+	// The job is pushed in the queue of its priority (the manual jobs have a
+	// high priority by default).
+	key := redisQueueKey(job.WorkerType, job.Priority())
+	token := instanceToken(job)
+	keys := []string{
+		redisJobsKey(key, token),
+		redisLensKey(key),
+		key,
+		redisInstancesKey(job.WorkerType),
+		redisWakeKey(job.WorkerType),
+	}
+	return redisEnqueueScript.Run(b.ctx, b.client, keys,
+		token, job.JobID, job.DomainName(), redisWakeMaxLen).Err()
+}
+
 // QueueLen returns the size of the number of elements in queue of the
 // specified worker type.
 func (b *redisBroker) WorkerQueueLen(workerType string) (int, error) {
-	key := redisPrefix + workerType
-	l1, err := b.client.LLen(b.ctx, key).Result()
+	lens, err := b.workerQueueLenByPriority(workerType)
 	if err != nil {
 		return 0, err
 	}
-	l2, err := b.client.LLen(b.ctx, key+redisHighPrioritySuffix).Result()
+	total := 0
+	for _, l := range lens {
+		total += l
+	}
+	return total, nil
+}
+
+func (b *redisBroker) workerQueueLenByPriority(workerType string) (map[Priority]int, error) {
+	lens := make(map[Priority]int, len(priorities))
+	for _, p := range priorities {
+		byInstance, legacy, err := b.queueLens(workerType, p)
+		if err != nil {
+			return nil, err
+		}
+		lens[p] = legacy
+		for _, n := range byInstance {
+			lens[p] += n
+		}
+	}
+	return lens, nil
+}
+
+// queueLens returns the number of queued jobs for each instance token, and
+// the number of jobs still queued with the legacy format.
+func (b *redisBroker) queueLens(workerType string, priority Priority) (map[string]int, int, error) {
+	vals, err := b.client.HGetAll(b.ctx, redisLensKey(redisQueueKey(workerType, priority))).Result()
 	if err != nil {
-		return 0, err
+		return nil, 0, err
+	}
+	legacy, err := b.client.LLen(b.ctx, redisLegacyQueueKey(workerType, priority)).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	byInstance := make(map[string]int, len(vals))
+	for token, val := range vals {
+		n, _ := strconv.Atoi(val)
+		if n > 0 {
+			byInstance[token] = n
+		}
+	}
+	return byInstance, int(legacy), nil
+}
+
+func (b *redisBroker) workerQueueStats(workerType string) (*QueueStats, error) {
+	var worker *Worker
+	for _, w := range b.workers {
+		if w.Type == workerType {
+			worker = w
+			break
+		}
+	}
+	if worker == nil {
+		return nil, ErrUnknownWorker
+	}
+
+	stats := &QueueStats{
+		WorkerType:                workerType,
+		ByPriority:                make(map[Priority]int, len(priorities)),
+		MaxConcurrencyPerInstance: worker.Conf.MaxConcurrencyPerInstance,
+	}
+	queued := make(map[string]int)
+	for _, p := range priorities {
+		byInstance, legacy, err := b.queueLens(workerType, p)
+		if err != nil {
+			return nil, err
+		}
+		stats.ByPriority[p] = legacy
+		for token, n := range byInstance {
+			stats.ByPriority[p] += n
+			queued[token] += n
+		}
+		stats.Len += stats.ByPriority[p]
+	}
+	if len(queued) == 0 {
+		return stats, nil
+	}
+
+	tokens := make([]string, 0, len(queued))
+	for token := range queued {
+		tokens = append(tokens, token)
+	}
+	domains, err := b.client.HMGet(b.ctx, redisInstancesKey(workerType), tokens...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range tokens {
+		s := InstanceStats{Queued: queued[token]}
+		if domain, ok := domains[i].(string); ok {
+			s.Domain = domain
+		}
+		if stats.MaxConcurrencyPerInstance > 0 {
+			running, err := b.client.ZCard(b.ctx, redisRunningKey(workerType, token)).Result()
+			if err != nil {
+				return nil, err
+			}
+			s.Running = int(running)
+		}
+		stats.Instances = append(stats.Instances, s)
 	}
-	return int(l1 + l2), nil
+	return stats, nil
 }
 
 func (b *redisBroker) WorkerIsReserved(workerType string) (bool, error) {
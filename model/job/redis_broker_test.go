@@ -13,6 +13,7 @@ import (
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/cozy-stack/tests/testutils"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -120,6 +121,160 @@ func TestRedisBroker(t *testing.T) {
 		time.Sleep(1 * time.Second)
 	})
 
+	t.Run("RedisFairness", func(t *testing.T) {
+		job.SetRedisTimeoutForTest()
+		opts1, _ := redis.ParseURL(redisURL1)
+		client1 := redis.NewClient(opts1)
+		otherInstance := testutils.NewSetup(t, t.Name()).GetTestInstance()
+		release := make(chan struct{})
+		executed := make(chan string, 20)
+
+		// The worker types are unique to not use the queues of a previous run
+		suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+		fairness := "fairness-" + suffix
+		limited := "limited-" + suffix
+		workerFunc := func(ctx *job.TaskContext) error {
+			var msg string
+			if err := ctx.UnmarshalMessage(&msg); err != nil {
+				return err
+			}
+			if msg == "block" {
+				<-release
+			}
+			executed <- ctx.Instance.Domain
+			return nil
+		}
+
+		broker := job.NewRedisBroker(client1)
+		assert.NoError(t, broker.StartWorkers(job.WorkersList{
+			{
+				WorkerType:   fairness,
+				Concurrency:  1,
+				MaxExecCount: 1,
+				WorkerFunc:   workerFunc,
+			},
+			{
+				WorkerType:                limited,
+				Concurrency:               2,
+				MaxExecCount:              1,
+				MaxConcurrencyPerInstance: 1,
+				WorkerFunc:                workerFunc,
+			},
+		}))
+		defer func() {
+			assert.NoError(t, broker.ShutdownWorkers(context.Background()))
+		}()
+
+		push := func(db prefixer.Prefixer, workerType, msg string) {
+			m, _ := job.NewMessage(msg)
+			_, err := broker.PushJob(db, &job.JobRequest{
+				WorkerType: workerType,
+				Message:    m,
+			})
+			assert.NoError(t, err)
+		}
+
+		// The jobs of the other instance don't wait for all the jobs of the
+		// first instance to be executed.
+		push(testInstance, fairness, "block")
+		time.Sleep(100 * time.Millisecond)
+		for i := 0; i < 10; i++ {
+			push(testInstance, fairness, "bulk")
+		}
+		push(otherInstance, fairness, "interactive")
+		release <- struct{}{}
+		assert.Equal(t, testInstance.Domain, <-executed)
+		found := false
+		for i := 0; i < 3; i++ {
+			if <-executed == otherInstance.Domain {
+				found = true
+			}
+		}
+		assert.True(t, found)
+		for i := 0; i < 8; i++ {
+			<-executed
+		}
+
+		// When an instance has reached its limit of concurrent jobs, the free
+		// workers take the jobs of the other instances.
+		push(testInstance, limited, "block")
+		time.Sleep(100 * time.Millisecond)
+		push(testInstance, limited, "next")
+		push(otherInstance, limited, "interactive")
+		assert.Equal(t, otherInstance.Domain, <-executed)
+		close(release)
+		assert.Equal(t, testInstance.Domain, <-executed)
+		assert.Equal(t, testInstance.Domain, <-executed)
+	})
+
+	t.Run("RedisAtomicPop", func(t *testing.T) {
+		job.SetRedisTimeoutForTest()
+		opts1, _ := redis.ParseURL(redisURL1)
+		client1 := redis.NewClient(opts1)
+		client2 := redis.NewClient(opts1)
+
+		// Two brokers on the same redis database take the jobs from the same
+		// queues, and each job must be executed only once.
+		n := 50
+		workerType := "atomic-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		var mu sync.Mutex
+		counts := make(map[string]int)
+		var w sync.WaitGroup
+		w.Add(n)
+		workersTestList := job.WorkersList{
+			{
+				WorkerType:   workerType,
+				Concurrency:  4,
+				MaxExecCount: 1,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var msg string
+					if err := ctx.UnmarshalMessage(&msg); err != nil {
+						return err
+					}
+					mu.Lock()
+					counts[msg]++
+					first := counts[msg] == 1
+					mu.Unlock()
+					if first {
+						w.Done()
+					}
+					return nil
+				},
+			},
+		}
+
+		broker1 := job.NewRedisBroker(client1)
+		assert.NoError(t, broker1.StartWorkers(workersTestList))
+		broker2 := job.NewRedisBroker(client2)
+		assert.NoError(t, broker2.StartWorkers(workersTestList))
+
+		for i := 0; i < n; i++ {
+			broker := broker1
+			if i%2 == 1 {
+				broker = broker2
+			}
+			msg, _ := job.NewMessage("job-" + strconv.Itoa(i))
+			_, err := broker.PushJob(testInstance, &job.JobRequest{
+				WorkerType: workerType,
+				Message:    msg,
+			})
+			assert.NoError(t, err)
+		}
+		w.Wait()
+		// Let a job executed twice be seen
+		time.Sleep(200 * time.Millisecond)
+
+		assert.NoError(t, broker1.ShutdownWorkers(context.Background()))
+		assert.NoError(t, broker2.ShutdownWorkers(context.Background()))
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, counts, n)
+		for msg, count := range counts {
+			assert.Equal(t, 1, count, msg)
+		}
+	})
+
 	t.Run("RedisAddJobRateLimitExceeded", func(t *testing.T) {
 		opts1, _ := redis.ParseURL(redisURL1)
 		client1 := redis.NewClient(opts1)
@@ -183,6 +183,9 @@ func (s *redisScheduler) eventLoop(eventsCh <-chan *realtime.Event) {
 				continue
 			}
 			et := t.(*EventTrigger)
+			if !et.filter.match(event) {
+				continue
+			}
 			if et.Infos().Debounce != "" {
 				var d time.Duration
 				if d, err = time.ParseDuration(et.Infos().Debounce); err == nil {
@@ -69,6 +69,7 @@ type (
 		WorkerType   string                 `json:"worker"`
 		Arguments    string                 `json:"arguments"`
 		Debounce     string                 `json:"debounce"`
+		Filter       *EventFilter           `json:"filter,omitempty"`
 		Options      *JobOptions            `json:"options"`
 		Message      Message                `json:"message"`
 		CurrentState *TriggerState          `json:"current_state,omitempty"`
@@ -302,7 +303,7 @@ func GetTriggerState(db prefixer.Prefixer, triggerID string) (*TriggerState, err
 		}
 
 		switch j.State {
-		case Errored:
+		case Errored, DeadLetter:
 			state.LastFailure = startedAt
 			state.LastFailedJobID = j.ID()
 			state.LastError = j.Error
@@ -17,6 +17,7 @@ type EventTrigger struct {
 	*TriggerInfos
 	unscheduled chan struct{}
 	mask        []permission.Rule
+	filter      *eventMatcher
 }
 
 // NewEventTrigger returns a new instance of EventTrigger given the specified
@@ -31,10 +32,18 @@ func NewEventTrigger(infos *TriggerInfos) (*EventTrigger, error) {
 		}
 		rules[i] = rule
 	}
+	var filter *eventMatcher
+	if infos.Filter != nil {
+		var err error
+		if filter, err = infos.Filter.compile(); err != nil {
+			return nil, err
+		}
+	}
 	return &EventTrigger{
 		TriggerInfos: infos,
 		unscheduled:  make(chan struct{}),
 		mask:         rules,
+		filter:       filter,
 	}, nil
 }
 
@@ -65,7 +74,7 @@ func (t *EventTrigger) Schedule() <-chan *JobRequest {
 						break
 					}
 				}
-				if found {
+				if found && t.filter.match(e) {
 					if evt, err := t.Infos().JobRequestWithEvent(e); err == nil {
 						ch <- evt
 					}
@@ -9,6 +9,7 @@ import (
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/realtime"
 	"github.com/cozy/cozy-stack/tests/testutils"
 	"github.com/stretchr/testify/assert"
@@ -214,6 +215,85 @@ func TestTrigger(t *testing.T) {
 		err := sch.ShutdownScheduler(context.Background())
 		assert.NoError(t, err)
 	})
+
+	t.Run("TriggerEventFilter", func(t *testing.T) {
+		called := make(chan string, 10)
+
+		bro := job.NewMemBroker()
+		assert.NoError(t, bro.StartWorkers(job.WorkersList{
+			{
+				WorkerType:   "worker_event_filter",
+				Concurrency:  1,
+				MaxExecCount: 1,
+				WorkerFunc: func(ctx *job.TaskContext) error {
+					var msg string
+					if err := ctx.UnmarshalMessage(&msg); err != nil {
+						return err
+					}
+					called <- msg
+					return nil
+				},
+			},
+		}))
+		sch := job.NewMemScheduler()
+		assert.NoError(t, sch.StartScheduler(bro))
+
+		_, err := job.NewTrigger(testInstance, job.TriggerInfos{
+			Type:       "@event",
+			Arguments:  "io.cozy.testeventobject",
+			WorkerType: "worker_event_filter",
+			Filter: &job.EventFilter{
+				Doc: mango.Map{"size": mango.Map{"$unknown": 1}},
+			},
+		}, nil)
+		assert.ErrorIs(t, err, job.ErrInvalidFilter)
+
+		trigger, err := job.NewTrigger(testInstance, job.TriggerInfos{
+			Type:       "@event",
+			Arguments:  "io.cozy.testeventobject:CREATED,UPDATED",
+			WorkerType: "worker_event_filter",
+			Filter: &job.EventFilter{
+				Doc:     mango.Map{"class": "image", "size": mango.Map{"$gt": 1048576}},
+				Changed: []string{"metadata.datetime"},
+			},
+		}, "filtered")
+		require.NoError(t, err)
+		require.NoError(t, sch.AddTrigger(trigger))
+
+		publish := func(verb, rev, class, size, datetime string, old *couchdb.JSONDoc) *couchdb.JSONDoc {
+			doc := &couchdb.JSONDoc{
+				Type: "io.cozy.testeventobject",
+				M: map[string]interface{}{
+					"_id":      "test-filter-id",
+					"_rev":     rev,
+					"class":    class,
+					"size":     size,
+					"metadata": map[string]interface{}{"datetime": datetime},
+				},
+			}
+			realtime.GetHub().Publish(testInstance, verb, doc, old)
+			return doc
+		}
+
+		// A small image is created: no job
+		publish(realtime.EventCreate, "1-a", "image", "1024", "2024-01-01", nil)
+		// A big image is created: a job
+		big := publish(realtime.EventCreate, "1-b", "image", "2097152", "2024-01-01", nil)
+		assert.Equal(t, "filtered", <-called)
+		// The datetime has not changed: no job
+		same := publish(realtime.EventUpdate, "2-b", "image", "2097152", "2024-01-01", big)
+		// The datetime has changed: a job
+		changed := publish(realtime.EventUpdate, "3-b", "image", "2097152", "2024-07-14", same)
+		assert.Equal(t, "filtered", <-called)
+		// Not an image: no job
+		publish(realtime.EventUpdate, "4-b", "video", "2097152", "2024-08-15", changed)
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Len(t, called, 0)
+
+		assert.NoError(t, sch.DeleteTrigger(testInstance, trigger.ID()))
+		assert.NoError(t, sch.ShutdownScheduler(context.Background()))
+	})
 }
 
 func makeMessage(t *testing.T, msg string) job.Message {
@@ -0,0 +1,117 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// EventFilter is an optional filter for the @event triggers, to push a job
+// only for the changes of the documents that are relevant for the worker.
+// All the conditions must be satisfied:
+//   - Doc is a mango selector for the new version of the document
+//   - Old is a mango selector for the old version of the document (only
+//     available for the updates)
+//   - Changed is a list of fields (with a dotted notation for the
+//     sub-fields) where at least one must have a different value in the old
+//     and the new version of the document. The fields are considered as
+//     changed for a creation or a deletion.
+type EventFilter struct {
+	Doc     mango.Map `json:"doc,omitempty"`
+	Old     mango.Map `json:"old,omitempty"`
+	Changed []string  `json:"changed,omitempty"`
+}
+
+// eventMatcher is the compiled version of an EventFilter.
+type eventMatcher struct {
+	doc     *mango.Matcher
+	old     *mango.Matcher
+	changed [][]string
+}
+
+func (f *EventFilter) compile() (*eventMatcher, error) {
+	m := &eventMatcher{}
+	var err error
+	if len(f.Doc) > 0 {
+		if m.doc, err = mango.Compile(f.Doc); err != nil {
+			return nil, fmt.Errorf("%w: doc: %s", ErrInvalidFilter, err)
+		}
+	}
+	if len(f.Old) > 0 {
+		if m.old, err = mango.Compile(f.Old); err != nil {
+			return nil, fmt.Errorf("%w: old: %s", ErrInvalidFilter, err)
+		}
+	}
+	for _, field := range f.Changed {
+		if field == "" {
+			return nil, fmt.Errorf("%w: changed: empty field", ErrInvalidFilter)
+		}
+		m.changed = append(m.changed, strings.Split(field, "."))
+	}
+	return m, nil
+}
+
+func (m *eventMatcher) match(e *realtime.Event) bool {
+	if m == nil {
+		return true
+	}
+	doc, err := docToMap(e.Doc)
+	if err != nil {
+		return false
+	}
+	var old map[string]interface{}
+	if e.OldDoc != nil {
+		if old, err = docToMap(e.OldDoc); err != nil {
+			return false
+		}
+	}
+
+	if m.doc != nil && !m.doc.Match(doc) {
+		return false
+	}
+	if m.old != nil && (old == nil || !m.old.Match(old)) {
+		return false
+	}
+	if len(m.changed) == 0 || e.Verb != realtime.EventUpdate {
+		return true
+	}
+	if old == nil {
+		return false
+	}
+	for _, path := range m.changed {
+		if !reflect.DeepEqual(lookup(doc, path), lookup(old, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// docToMap returns the document as a map, with the same types as if it was
+// unmarshaled from JSON.
+func docToMap(doc realtime.Doc) (map[string]interface{}, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func lookup(doc map[string]interface{}, path []string) interface{} {
+	var v interface{} = doc
+	for _, key := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
@@ -21,10 +21,11 @@ import (
 )
 
 var (
-	defaultConcurrency  = runtime.NumCPU()
-	defaultMaxExecCount = 1
-	defaultRetryDelay   = 60 * time.Millisecond
-	defaultTimeout      = 10 * time.Second
+	defaultConcurrency   = runtime.NumCPU()
+	defaultMaxExecCount  = 1
+	defaultRetryDelay    = 60 * time.Millisecond
+	defaultMaxRetryDelay = 5 * time.Minute
+	defaultTimeout       = 10 * time.Second
 )
 
 type (
@@ -69,7 +70,16 @@ type (
 		MaxExecCount int
 		Reserved     bool // true when the clients must not push jobs for this worker
 		Timeout      time.Duration
-		RetryDelay   time.Duration
+		// RetryDelay is the delay before the first retry, and it is doubled
+		// for each next retry, up to MaxRetryDelay
+		RetryDelay    time.Duration
+		MaxRetryDelay time.Duration
+		// DeadLetter is true when the jobs that have failed after all their
+		// retries must be kept as dead letters, to be re-queued later
+		DeadLetter bool
+		// MaxConcurrencyPerInstance is the maximal number of jobs of a single
+		// instance that can be executed at the same time (0 for no limit)
+		MaxConcurrencyPerInstance int
 	}
 
 	// Worker is a unit of work that will consume from a queue and execute the do
@@ -81,6 +91,9 @@ type (
 		running uint32
 		closing chan struct{}
 		closed  chan struct{}
+		// broker is used to queue the jobs that were waiting for a job
+		// executed by this worker
+		broker enqueuer
 	}
 
 	// TaskContext is a context.Context passed to the worker for each task
@@ -270,36 +283,45 @@ func (w *Worker) Shutdown(ctx context.Context) error {
 
 func (w *Worker) work(workerID string) {
 	for job := range w.jobs {
-		domain := job.Domain
-		if domain == "" {
-			joblog.Errorf("%s: missing domain from job request", workerID)
-			continue
+		w.handle(workerID, job)
+	}
+	joblog.Debugf("%s: worker shut down", workerID)
+	w.closed <- struct{}{}
+}
+
+func (w *Worker) handle(workerID string, job *Job) {
+	// The broker may limit the number of jobs executed at the same time for
+	// an instance, and must be told when a job is finished.
+	if r, ok := w.broker.(releaser); ok {
+		defer r.release(job)
+	}
+	domain := job.Domain
+	if domain == "" {
+		joblog.Errorf("%s: missing domain from job request", workerID)
+		return
+	}
+	var inst *instance.Instance
+	if domain != prefixer.GlobalPrefixer.DomainName() {
+		var err error
+		inst, err = instance.Get(job.Domain)
+		if err != nil {
+			joblog.Errorf("Instance not found for %s: %s", job.Domain, err)
+			return
 		}
-		var inst *instance.Instance
-		if domain != prefixer.GlobalPrefixer.DomainName() {
-			var err error
-			inst, err = instance.Get(job.Domain)
-			if err != nil {
-				joblog.Errorf("Instance not found for %s: %s", job.Domain, err)
-				continue
-			}
-			// Do not execute jobs for instances with blocking not signed TOS,
-			// except for:
-			// - mails because the user may needs a mail to login and accept
-			//   the new TOS (2FA, password reset, etc.)
-			// - migrations because the old version may be no longer supported
-			//   when the user will sign the TOS
-			if w.Type != "sendmail" && w.Type != "migrations" {
-				notSigned, deadline := inst.CheckTOSNotSignedAndDeadline()
-				if notSigned && deadline == instance.TOSBlocked {
-					continue
-				}
+		// Do not execute jobs for instances with blocking not signed TOS,
+		// except for:
+		// - mails because the user may needs a mail to login and accept
+		//   the new TOS (2FA, password reset, etc.)
+		// - migrations because the old version may be no longer supported
+		//   when the user will sign the TOS
+		if w.Type != "sendmail" && w.Type != "migrations" {
+			notSigned, deadline := inst.CheckTOSNotSignedAndDeadline()
+			if notSigned && deadline == instance.TOSBlocked {
+				return
 			}
 		}
-		w.runTask(inst, workerID, job)
 	}
-	joblog.Debugf("%s: worker shut down", workerID)
-	w.closed <- struct{}{}
+	w.runTask(inst, workerID, job)
 }
 
 func (w *Worker) runTask(inst *instance.Instance, workerID string, job *Job) {
@@ -310,6 +332,8 @@ func (w *Worker) runTask(inst *instance.Instance, workerID string, job *Job) {
 			err.Error())
 		return
 	}
+	jobsWaitDurations.WithLabelValues(w.Type, string(job.Priority())).
+		Observe(job.StartedAt.Sub(job.QueuedAt).Seconds())
 	t := &task{
 		w:    w,
 		ctx:  taskCtx,
@@ -340,7 +364,11 @@ func (w *Worker) runTask(inst *instance.Instance, workerID string, job *Job) {
 		taskCtx.Logger().Errorf("error while performing job: %s",
 			errRun.Error())
 		runResultLabel = metrics.WorkerExecResultErrored
-		errAck = job.Nack(errRun.Error())
+		if t.exhausted && t.conf.DeadLetter {
+			errAck = job.MoveToDeadLetters(errRun.Error())
+		} else {
+			errAck = job.Nack(errRun.Error())
+		}
 	} else {
 		runResultLabel = metrics.WorkerExecResultSuccess
 		errAck = job.Ack()
@@ -364,6 +392,9 @@ func (w *Worker) runTask(inst *instance.Instance, workerID string, job *Job) {
 	if errAck != nil {
 		taskCtx.Logger().Errorf("error while acking job done: %s",
 			errAck.Error())
+	} else if err := w.releaseDependents(job); err != nil {
+		taskCtx.Logger().Errorf("error while releasing the dependent jobs: %s",
+			err.Error())
 	}
 
 	// Delete the trigger associated with the job (if any) when we receive a
@@ -375,6 +406,13 @@ func (w *Worker) runTask(inst *instance.Instance, workerID string, job *Job) {
 	}
 }
 
+func (w *Worker) releaseDependents(job *Job) error {
+	if w.broker == nil {
+		return ReleaseDependents(job)
+	}
+	return releaseDependents(w.broker, job)
+}
+
 func (w *Worker) defaultedConf(opts *JobOptions) *WorkerConfig {
 	c := w.Conf.Clone()
 	if c.Concurrency == 0 {
@@ -386,6 +424,9 @@ func (w *Worker) defaultedConf(opts *JobOptions) *WorkerConfig {
 	if c.RetryDelay == 0 {
 		c.RetryDelay = defaultRetryDelay
 	}
+	if c.MaxRetryDelay == 0 {
+		c.MaxRetryDelay = defaultMaxRetryDelay
+	}
 	if c.Timeout == 0 {
 		c.Timeout = defaultTimeout
 	}
@@ -410,6 +451,7 @@ type task struct {
 	startTime time.Time
 	endTime   time.Time
 	execCount int
+	exhausted bool // true if the job has failed after all its retries
 }
 
 func (t *task) run() (err error) {
@@ -434,12 +476,17 @@ func (t *task) run() (err error) {
 	for {
 		retry, delay, timeout := t.nextDelay(err)
 
+		exhausted := err != nil && t.execCount >= t.conf.MaxExecCount && IsRetryable(err)
+
 		// The optional ErrorHook function allows to prevent retries depending
 		// on the previous error
-		if retry && t.conf.ErrorHook != nil {
-			retry = t.conf.ErrorHook(err)
+		if (retry || exhausted) && t.conf.ErrorHook != nil {
+			allowed := t.conf.ErrorHook(err)
+			retry = retry && allowed
+			exhausted = exhausted && allowed
 		}
 		if !retry {
+			t.exhausted = exhausted
 			break
 		}
 		if err != nil {
@@ -448,7 +495,11 @@ func (t *task) run() (err error) {
 		}
 
 		if delay > 0 {
-			time.Sleep(delay)
+			select {
+			case <-time.After(delay):
+			case <-t.ctx.Done():
+				return
+			}
 		}
 
 		t.ctx.Logger().Debugf("Executing job (%d) (timeout set to %s)",
@@ -471,6 +522,7 @@ func (t *task) run() (err error) {
 		execResultLabel = metrics.WorkerExecResultErrored
 		timer.ObserveDuration()
 		t.endTime = time.Now()
+		t.job.Errors = append(t.job.Errors, JobError{Error: err.Error(), At: t.endTime})
 
 		// Incrementing timeouts counter
 		if t.job.Message != nil {
@@ -537,14 +589,8 @@ func (t *task) exec(ctx *TaskContext) (err error) {
 func (t *task) nextDelay(prevError error) (bool, time.Duration, time.Duration) {
 	// for certain kinds of errors, we do not have a retry since these error
 	// cannot be recovered from
-	{
-		if _, ok := prevError.(BadTriggerError); ok {
-			return false, 0, 0
-		}
-		switch prevError {
-		case ErrAbort, ErrMessageUnmarshal, ErrMessageNil:
-			return false, 0, 0
-		}
+	if !IsRetryable(prevError) {
+		return false, 0, 0
 	}
 
 	c := t.conf
@@ -562,11 +608,22 @@ func (t *task) nextDelay(prevError error) (bool, time.Duration, time.Duration) {
 		// on first execution, execute immediately
 		nextDelay = 0
 	} else {
-		nextDelay = c.RetryDelay << uint(t.execCount-1)
+		// exponential backoff, capped by the max delay (and protected
+		// against an overflow of the shift)
+		shift := t.execCount - 1
+		if shift > 30 {
+			shift = 30
+		}
+		nextDelay = c.RetryDelay << uint(shift)
+		if nextDelay <= 0 || nextDelay > c.MaxRetryDelay {
+			nextDelay = c.MaxRetryDelay
+		}
 
 		// fuzzDelay number between delay * (1 +/- 0.1)
-		fuzzDelay := int(0.1 * float64(nextDelay))
-		nextDelay += time.Duration((rand.Intn(2*fuzzDelay) - fuzzDelay))
+		fuzzDelay := int64(0.1 * float64(nextDelay))
+		if fuzzDelay > 0 {
+			nextDelay += time.Duration(rand.Int63n(2*fuzzDelay) - fuzzDelay)
+		}
 	}
 
 	return true, nextDelay, timeout
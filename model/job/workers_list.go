@@ -81,6 +81,18 @@ func applyWorkerConfig(w *WorkerConfig, c config.Worker) *WorkerConfig {
 	if c.Timeout != nil {
 		w.Timeout = *c.Timeout
 	}
+	if c.RetryDelay != nil {
+		w.RetryDelay = *c.RetryDelay
+	}
+	if c.MaxRetryDelay != nil {
+		w.MaxRetryDelay = *c.MaxRetryDelay
+	}
+	if c.DeadLetter != nil {
+		w.DeadLetter = *c.DeadLetter
+	}
+	if c.MaxConcurrencyPerInstance != nil {
+		w.MaxConcurrencyPerInstance = *c.MaxConcurrencyPerInstance
+	}
 	return w
 }
 
@@ -0,0 +1,219 @@
+package job
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// Workflow is a named group of jobs, with dependencies between them, that are
+// pushed together. It is persisted in CouchDB to follow its progress.
+type Workflow struct {
+	DocID     string            `json:"_id,omitempty"`
+	DocRev    string            `json:"_rev,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Jobs      map[string]string `json:"jobs"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// WorkflowStep is a job of a workflow. The key identifies the step inside the
+// workflow, and DependsOn is the list of the keys of the steps that must be
+// done before this one can be executed.
+type WorkflowStep struct {
+	Key       string
+	Request   *JobRequest
+	DependsOn []string
+}
+
+// ID implements the couchdb.Doc interface
+func (w *Workflow) ID() string { return w.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (w *Workflow) Rev() string { return w.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (w *Workflow) DocType() string { return consts.JobsWorkflows }
+
+// SetID implements the couchdb.Doc interface
+func (w *Workflow) SetID(id string) { w.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (w *Workflow) SetRev(rev string) { w.DocRev = rev }
+
+// Clone implements the couchdb.Doc interface
+func (w *Workflow) Clone() couchdb.Doc {
+	cloned := *w
+	cloned.Jobs = make(map[string]string, len(w.Jobs))
+	for k, v := range w.Jobs {
+		cloned.Jobs[k] = v
+	}
+	return &cloned
+}
+
+// PushWorkflow pushes the jobs of a workflow, each job being queued only when
+// the jobs it depends on are done.
+func PushWorkflow(db prefixer.Prefixer, name string, steps []WorkflowStep) (*Workflow, error) {
+	ordered, err := sortWorkflowSteps(steps)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Workflow{
+		Name:      name,
+		Jobs:      make(map[string]string, len(steps)),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := couchdb.CreateDoc(db, w); err != nil {
+		return nil, err
+	}
+
+	for _, step := range ordered {
+		req := *step.Request
+		req.WorkflowID = w.DocID
+		req.DependsOn = append([]string{}, step.Request.DependsOn...)
+		for _, key := range step.DependsOn {
+			req.DependsOn = append(req.DependsOn, w.Jobs[key])
+		}
+		j, errp := System().PushJob(db, &req)
+		if errp != nil {
+			err = errp
+			break
+		}
+		w.Jobs[step.Key] = j.ID()
+	}
+
+	// Even when a job can't be pushed, the workflow keeps the list of the jobs
+	// that have already been pushed.
+	if errw := couchdb.UpdateDoc(db, w); errw != nil && err == nil {
+		err = errw
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// sortWorkflowSteps returns the steps in an order where each step comes after
+// the steps it depends on. An error is returned if a key is used twice, if a
+// dependency is unknown, or if there is a cycle.
+func sortWorkflowSteps(steps []WorkflowStep) ([]WorkflowStep, error) {
+	if len(steps) == 0 {
+		return nil, ErrInvalidWorkflow
+	}
+	byKey := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		if step.Key == "" || step.Request == nil || byKey[step.Key] {
+			return nil, ErrInvalidWorkflow
+		}
+		byKey[step.Key] = true
+	}
+	for _, step := range steps {
+		for _, dep := range step.DependsOn {
+			if !byKey[dep] || dep == step.Key {
+				return nil, ErrInvalidWorkflow
+			}
+		}
+	}
+
+	ordered := make([]WorkflowStep, 0, len(steps))
+	pushed := make(map[string]bool, len(steps))
+	for len(ordered) < len(steps) {
+		progress := false
+		for _, step := range steps {
+			if pushed[step.Key] {
+				continue
+			}
+			ready := true
+			for _, dep := range step.DependsOn {
+				if !pushed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, step)
+				pushed[step.Key] = true
+				progress = true
+			}
+		}
+		if !progress {
+			return nil, ErrInvalidWorkflow
+		}
+	}
+	return ordered, nil
+}
+
+// GetWorkflow returns the workflow with the given ID.
+func GetWorkflow(db prefixer.Prefixer, id string) (*Workflow, error) {
+	var w Workflow
+	if err := couchdb.GetDoc(db, consts.JobsWorkflows, id, &w); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil, ErrNotFoundWorkflow
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+// FetchJobs returns the jobs of the workflow, indexed by their keys. The jobs
+// that have been deleted are not in the map.
+func (w *Workflow) FetchJobs(db prefixer.Prefixer) (map[string]*Job, error) {
+	keys := make([]string, 0, len(w.Jobs))
+	ids := make([]string, 0, len(w.Jobs))
+	for key, id := range w.Jobs {
+		keys = append(keys, key)
+		ids = append(ids, id)
+	}
+	jobs := make(map[string]*Job, len(ids))
+	if len(ids) == 0 {
+		return jobs, nil
+	}
+	list, err := getJobs(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range list {
+		if j != nil {
+			jobs[keys[i]] = j
+		}
+	}
+	return jobs, nil
+}
+
+// WorkflowState computes the state of a workflow from the states of its jobs:
+//   - queued if no job has started
+//   - running if some jobs are still to be executed
+//   - errored if a job has failed or has been skipped, and no job is queued
+//     or running (the jobs waiting for a dead letter can't go further)
+//   - done if all the jobs are done.
+func WorkflowState(jobs map[string]*Job) State {
+	started, active, waiting, failed := false, false, false, false
+	for _, j := range jobs {
+		switch j.State {
+		case Queued:
+			active = true
+		case Waiting:
+			waiting = true
+		case Running:
+			started, active = true, true
+		case Errored, Skipped, DeadLetter:
+			started, failed = true, true
+		default:
+			started = true
+		}
+	}
+	switch {
+	case !started && (active || waiting):
+		return Queued
+	case active || (waiting && !failed):
+		return Running
+	case failed:
+		return Errored
+	default:
+		return Done
+	}
+}
+
+var _ couchdb.Doc = &Workflow{}
@@ -425,6 +425,9 @@ func exportDocuments(in *instance.Instance, doc *ExportDoc, now time.Time, tw *t
 		case consts.Files, consts.FilesVersions:
 			// we have code specific to those doctypes
 			continue
+		case consts.FilesSearch:
+			// the search index is rebuilt on the new instance
+			continue
 		}
 		dir := url.PathEscape(doctype)
 		err := couchdb.ForeachDocs(in, doctype, func(id string, doc json.RawMessage) error {
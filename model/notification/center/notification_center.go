@@ -28,6 +28,15 @@ const (
 	// NotificationOAuthClients category for sending alert when exceeding the
 	// connected OAuth clients limit.
 	NotificationOAuthClients = "oauth-clients"
+	// NotificationSharingExpiring category for reminding the owner of a
+	// sharing that it will expire soon.
+	NotificationSharingExpiring = "sharing-expiring"
+	// NotificationSharingExpired category for warning the owner of a sharing
+	// that it has been revoked when reaching its expiration date.
+	NotificationSharingExpired = "sharing-expired"
+	// NotificationEmergencyAccess category for warning the user that a
+	// trusted contact has asked for an emergency access to their vault.
+	NotificationEmergencyAccess = "emergency-access"
 )
 
 var (
@@ -45,6 +54,24 @@ var (
 			Stateful:     false,
 			MailTemplate: "notifications_oauthclients",
 		},
+		NotificationSharingExpiring: {
+			Description:  "Remind that a sharing will expire soon",
+			Collapsible:  false,
+			Stateful:     false,
+			MailTemplate: "notifications_sharing_expiring",
+		},
+		NotificationSharingExpired: {
+			Description:  "Warn that a sharing has been revoked on its expiration date",
+			Collapsible:  false,
+			Stateful:     false,
+			MailTemplate: "notifications_sharing_expired",
+		},
+		NotificationEmergencyAccess: {
+			Description:  "Warn about an emergency access to the vault",
+			Collapsible:  false,
+			Stateful:     false,
+			MailTemplate: "notifications_emergency_access",
+		},
 	}
 )
 
@@ -267,8 +294,21 @@ func makePush(inst *instance.Instance, p *notification.Properties, n *notificati
 		return nil
 	}
 
-	var errm error
 	log := inst.Logger().WithNamespace("notifications")
+	prefs, err := notification.GetPreferences(inst)
+	if err != nil {
+		log.Warnf("Cannot load the notification preferences: %s", err)
+		prefs = &notification.Preferences{}
+	}
+	if cat := prefs.ForCategory(n); cat.Muted {
+		log.Debugf("Notification %s was not sent (muted by the user)", n.ID())
+		return nil
+	} else if len(cat.Channels) > 0 {
+		preferredChannels = cat.Channels
+	}
+	at = delayForQuietHours(prefs, n, at)
+
+	var errm error
 	for _, channel := range preferredChannels {
 		switch channel {
 		case "mobile":
@@ -281,8 +321,23 @@ func makePush(inst *instance.Instance, p *notification.Properties, n *notificati
 				log.Errorf("Error while sending push %#v: %v. Error: %v", p, n.State, err)
 				errm = multierror.Append(errm, err)
 			}
+		case "web":
+			if p != nil {
+				log.Infof("Sending web push %#v: %v", p, n.State)
+				err := sendWebPush(inst, p, n, at)
+				if err == nil {
+					return nil
+				}
+				log.Errorf("Error while sending web push %#v: %v. Error: %v", p, n.State, err)
+				errm = multierror.Append(errm, err)
+			}
 		case "mail":
-			err := sendMail(inst, p, n, at)
+			var err error
+			if prefs.UseDigest(n) {
+				err = addToDigest(inst, prefs, p, n)
+			} else {
+				err = sendMail(inst, p, n, at)
+			}
 			if err == nil {
 				return nil
 			}
@@ -332,6 +387,21 @@ func sendPush(inst *instance.Instance,
 	if !hasNotifiableDevice(inst) {
 		return errors.New("No device with push notification")
 	}
+	return pushJobOrTrigger(inst, buildPushMessage(p, n), "push", at)
+}
+
+func sendWebPush(inst *instance.Instance,
+	p *notification.Properties,
+	n *notification.Notification,
+	at string,
+) error {
+	if !hasWebPushSubscription(inst) {
+		return errors.New("No browser with web push subscription")
+	}
+	return pushJobOrTrigger(inst, buildPushMessage(p, n), "webpush", at)
+}
+
+func buildPushMessage(p *notification.Properties, n *notification.Notification) job.Message {
 	email := buildMailMessage(p, n)
 	push := PushMessage{
 		NotificationID: n.ID(),
@@ -344,11 +414,8 @@ func sendPush(inst *instance.Instance,
 		Collapsible:    p.Collapsible,
 		MailFallback:   email,
 	}
-	msg, err := job.NewMessage(&push)
-	if err != nil {
-		return err
-	}
-	return pushJobOrTrigger(inst, msg, "push", at)
+	msg, _ := job.NewMessage(&push)
+	return msg
 }
 
 func sendMail(inst *instance.Instance,
@@ -439,5 +506,26 @@ func ensureMailFallback(channels []string) []string {
 
 func hasNotifiableDevice(inst *instance.Instance) bool {
 	cs, err := oauth.GetNotifiables(inst)
-	return err == nil && len(cs) > 0
+	if err != nil {
+		return false
+	}
+	for _, c := range cs {
+		if c.NotificationPlatform != oauth.PlatformWebPush {
+			return true
+		}
+	}
+	return false
+}
+
+func hasWebPushSubscription(inst *instance.Instance) bool {
+	cs, err := oauth.GetNotifiables(inst)
+	if err != nil {
+		return false
+	}
+	for _, c := range cs {
+		if c.WebPushSubscription() != nil {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,159 @@
+package center
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/mail"
+)
+
+// maxDigestNotifications is the maximal number of notifications listed in a
+// daily digest, and the size of the pages for loading them.
+const maxDigestNotifications = 100
+
+// maxDigestRetries is the maximal number of retries for scheduling the digest
+// when the preferences have been modified at the same time.
+const maxDigestRetries = 5
+
+// delayForQuietHours returns the date when the notification should be sent,
+// which is the end of the quiet hours if it falls inside them. The
+// notifications with a high priority are not delayed.
+func delayForQuietHours(prefs *notification.Preferences, n *notification.Notification, at string) string {
+	if n.Priority == "high" {
+		return at
+	}
+	sendAt := time.Now()
+	if at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return at
+		}
+		sendAt = t
+	}
+	until, ok := prefs.QuietUntil(sendAt)
+	if !ok {
+		return at
+	}
+	return until.Format(time.RFC3339)
+}
+
+// addToDigest marks the notification as pending for the next daily digest,
+// and ensures that the digest has been scheduled.
+func addToDigest(
+	inst *instance.Instance,
+	prefs *notification.Preferences,
+	p *notification.Properties,
+	n *notification.Notification,
+) error {
+	if buildMailMessage(p, n) == nil {
+		return nil
+	}
+	n.Digest = notification.DigestPending
+	if err := couchdb.UpdateDoc(inst, n); err != nil {
+		return err
+	}
+
+	// The preferences can be updated at the same time by another notification
+	// or by the user, so the update is retried on a conflict, with the last
+	// version of the preferences.
+	var next time.Time
+	for i := 0; ; i++ {
+		now := time.Now()
+		if prefs.Digest != nil && prefs.Digest.NextAt != nil && prefs.Digest.NextAt.After(now) {
+			return nil
+		}
+		next = prefs.NextDigest(now)
+		if prefs.Digest == nil {
+			prefs.Digest = &notification.Digest{}
+		}
+		prefs.Digest.NextAt = &next
+		err := prefs.Save(inst)
+		if err == nil {
+			break
+		}
+		if !couchdb.IsConflictError(err) || i >= maxDigestRetries {
+			return err
+		}
+		last, err := notification.GetPreferences(inst)
+		if err != nil {
+			return err
+		}
+		*prefs = *last
+	}
+	msg, err := job.NewMessage(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	return pushJobOrTrigger(inst, msg, "notifications-digest", next.Format(time.RFC3339))
+}
+
+// SendDigest sends a mail with the notifications that are pending for the
+// daily digest. The notifications are marked as sent before the mail is
+// pushed, so that a failure can't lead to the same digest sent twice.
+func SendDigest(inst *instance.Instance) error {
+	var docs, olds []interface{}
+	var items []map[string]interface{}
+	req := &couchdb.FindRequest{
+		UseIndex: "by-digest",
+		Selector: mango.Equal("digest", notification.DigestPending),
+		Sort: mango.SortBy{
+			{Field: "digest", Direction: mango.Asc},
+			{Field: "created_at", Direction: mango.Asc},
+		},
+		Limit: maxDigestNotifications,
+	}
+	for {
+		var notifs []*notification.Notification
+		err := couchdb.FindDocs(inst, consts.Notifications, req, &notifs)
+		if err != nil && !couchdb.IsNoDatabaseError(err) {
+			return err
+		}
+		for _, n := range notifs {
+			// Only the first notifications are listed in the mail, the
+			// others are just counted.
+			if len(items) < maxDigestNotifications {
+				items = append(items, map[string]interface{}{
+					"Title":   n.Title,
+					"Message": n.Message,
+					"Slug":    n.Slug,
+				})
+			}
+			olds = append(olds, n.Clone())
+			n.Digest = notification.DigestSent
+			docs = append(docs, n)
+		}
+		if len(notifs) < maxDigestNotifications {
+			break
+		}
+		req.Skip += len(notifs)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	if err := couchdb.BulkUpdateDocs(inst, consts.Notifications, docs, olds); err != nil {
+		return err
+	}
+	email := mail.Options{
+		Mode:         mail.ModeFromStack,
+		TemplateName: "notifications_digest",
+		TemplateValues: map[string]interface{}{
+			"Count":         len(docs),
+			"Notifications": items,
+		},
+	}
+	msg, err := job.NewMessage(&email)
+	if err != nil {
+		return err
+	}
+	_, err = job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: "sendmail",
+		Message:    msg,
+	})
+	return err
+}
@@ -58,11 +58,26 @@ type Notification struct {
 	PreferredChannels []string `json:"preferred_channels,omitempty"`
 	At                string   `json:"at,omitempty"`
 
+	SMS *SMSDelivery `json:"sms,omitempty"`
+	// Digest is set when the mail for the notification is sent in the daily
+	// digest (pending or sent)
+	Digest string `json:"digest,omitempty"`
+
 	// XXX retro-compatible fields for sending rich mail
 	Content     string `json:"content,omitempty"`
 	ContentHTML string `json:"content_html,omitempty"`
 }
 
+// SMSDelivery is the delivery status of a notification sent by SMS, as
+// reported by the SMS provider.
+type SMSDelivery struct {
+	Provider  string    `json:"provider"`
+	MessageID string    `json:"message_id,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // ID is used to implement the couchdb.Doc interface
 func (n *Notification) ID() string { return n.NID }
 
@@ -81,6 +96,10 @@ func (n *Notification) Clone() couchdb.Doc {
 	}
 	cloned.PreferredChannels = make([]string, len(n.PreferredChannels))
 	copy(cloned.PreferredChannels, n.PreferredChannels)
+	if n.SMS != nil {
+		sms := *n.SMS
+		cloned.SMS = &sms
+	}
 	return &cloned
 }
 
@@ -0,0 +1,265 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// DefaultDigestTime is the time of the day when the daily digest is sent, if
+// the user has not chosen another one.
+const DefaultDigestTime = "08:00"
+
+// The values of the digest field of a notification.
+const (
+	// DigestPending is for a notification waiting for the next daily digest
+	DigestPending = "pending"
+	// DigestSent is for a notification that has been sent in a daily digest
+	DigestSent = "sent"
+)
+
+// ErrInvalidPreferences is used when the notification preferences are not
+// valid.
+var ErrInvalidPreferences = errors.New("Invalid notification preferences")
+
+// knownChannels is the list of the channels that can be chosen by the user.
+var knownChannels = map[string]bool{
+	"mobile": true,
+	"web":    true,
+	"mail":   true,
+	"sms":    true,
+}
+
+// Preferences are the choices of the user for the notifications. There is a
+// single document per instance.
+type Preferences struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+
+	// Timezone is the IANA name of the timezone used for the quiet hours and
+	// the digest (UTC by default)
+	Timezone string `json:"timezone,omitempty"`
+
+	// Categories are the preferences for a category of notifications, with
+	// <slug>/<category> as key (the originator is used instead of the slug
+	// when the notification is not sent by an application)
+	Categories map[string]CategoryPreferences `json:"categories,omitempty"`
+
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	Digest     *Digest     `json:"digest,omitempty"`
+}
+
+// CategoryPreferences are the preferences for a category of notifications.
+type CategoryPreferences struct {
+	// Muted notifications are still saved, but not sent
+	Muted bool `json:"muted,omitempty"`
+	// Channels replaces the preferred channels of the notifications
+	Channels []string `json:"channels,omitempty"`
+	// Digest can be used to enable or disable the daily digest for this
+	// category, instead of using the global setting
+	Digest *bool `json:"digest,omitempty"`
+}
+
+// QuietHours is a period of the day, with the HH:MM format, where the
+// notifications are delayed. The end can be before the start for a period
+// over midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Digest is the configuration for the daily digest, where the mails of the
+// notifications are batched in a single mail.
+type Digest struct {
+	Enabled bool   `json:"enabled"`
+	Time    string `json:"time,omitempty"`
+	// NextAt is the date of the next digest that has been scheduled
+	NextAt *time.Time `json:"next_at,omitempty"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (p *Preferences) ID() string { return p.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (p *Preferences) Rev() string { return p.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (p *Preferences) DocType() string { return consts.NotificationsPreferences }
+
+// SetID is used to implement the couchdb.Doc interface
+func (p *Preferences) SetID(id string) { p.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (p *Preferences) SetRev(rev string) { p.DocRev = rev }
+
+// Clone implements couchdb.Doc
+func (p *Preferences) Clone() couchdb.Doc {
+	cloned := *p
+	if p.Categories != nil {
+		cloned.Categories = make(map[string]CategoryPreferences, len(p.Categories))
+		for k, v := range p.Categories {
+			v.Channels = append([]string(nil), v.Channels...)
+			cloned.Categories[k] = v
+		}
+	}
+	if p.QuietHours != nil {
+		q := *p.QuietHours
+		cloned.QuietHours = &q
+	}
+	if p.Digest != nil {
+		d := *p.Digest
+		cloned.Digest = &d
+	}
+	return &cloned
+}
+
+// GetPreferences returns the notification preferences of the user. If the
+// user has not set them, the default preferences are returned.
+func GetPreferences(db prefixer.Prefixer) (*Preferences, error) {
+	prefs := &Preferences{}
+	err := couchdb.GetDoc(db, consts.NotificationsPreferences, consts.NotificationsPreferencesID, prefs)
+	if couchdb.IsNotFoundError(err) {
+		return &Preferences{DocID: consts.NotificationsPreferencesID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// Save persists the preferences in CouchDB.
+func (p *Preferences) Save(db prefixer.Prefixer) error {
+	p.DocID = consts.NotificationsPreferencesID
+	if p.DocRev == "" {
+		return couchdb.CreateNamedDocWithDB(db, p)
+	}
+	return couchdb.UpdateDoc(db, p)
+}
+
+// Validate checks that the preferences are valid.
+func (p *Preferences) Validate() error {
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidPreferences, p.Timezone)
+	}
+	for key, cat := range p.Categories {
+		for _, channel := range cat.Channels {
+			if !knownChannels[channel] {
+				return fmt.Errorf("%w: unknown channel %q for %s", ErrInvalidPreferences, channel, key)
+			}
+		}
+	}
+	if q := p.QuietHours; q != nil {
+		if _, err := parseClock(q.Start); err != nil {
+			return fmt.Errorf("%w: quiet_hours.start: %s", ErrInvalidPreferences, err)
+		}
+		if _, err := parseClock(q.End); err != nil {
+			return fmt.Errorf("%w: quiet_hours.end: %s", ErrInvalidPreferences, err)
+		}
+	}
+	if d := p.Digest; d != nil && d.Time != "" {
+		if _, err := parseClock(d.Time); err != nil {
+			return fmt.Errorf("%w: digest.time: %s", ErrInvalidPreferences, err)
+		}
+	}
+	return nil
+}
+
+// CategoryKey returns the key used in the categories of the preferences for
+// the given notification.
+func CategoryKey(n *Notification) string {
+	slug := n.Slug
+	if slug == "" {
+		slug = n.Originator
+	}
+	return slug + "/" + n.Category
+}
+
+// ForCategory returns the preferences for the category of the notification.
+func (p *Preferences) ForCategory(n *Notification) CategoryPreferences {
+	return p.Categories[CategoryKey(n)]
+}
+
+// UseDigest returns true if the mail for the notification should be sent in
+// the daily digest.
+func (p *Preferences) UseDigest(n *Notification) bool {
+	if digest := p.ForCategory(n).Digest; digest != nil {
+		return *digest
+	}
+	return p.Digest != nil && p.Digest.Enabled
+}
+
+// QuietUntil returns the end of the quiet hours, and true, if the given time
+// is in the quiet hours.
+func (p *Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	q := p.QuietHours
+	if q == nil {
+		return time.Time{}, false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(q.End)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(p.location())
+	now := local.Hour()*60 + local.Minute()
+	var quiet bool
+	if start < end {
+		quiet = start <= now && now < end
+	} else {
+		quiet = now >= start || now < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+	return nextClock(local, end), true
+}
+
+// NextDigest returns the date of the next daily digest after the given time.
+func (p *Preferences) NextDigest(t time.Time) time.Time {
+	clock := DefaultDigestTime
+	if p.Digest != nil && p.Digest.Time != "" {
+		clock = p.Digest.Time
+	}
+	minutes, err := parseClock(clock)
+	if err != nil {
+		minutes, _ = parseClock(DefaultDigestTime)
+	}
+	return nextClock(t.In(p.location()), minutes)
+}
+
+func (p *Preferences) location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseClock parses a time of the day with the HH:MM format, and returns the
+// number of minutes since midnight.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// nextClock returns the first date after t at the given time of the day.
+func nextClock(t time.Time, minutes int) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location())
+	if !next.After(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, minutes/60, minutes%60, 0, 0, t.Location())
+	}
+	return next
+}
+
+var _ couchdb.Doc = &Preferences{}
@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferences(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	t.Run("QuietHours", func(t *testing.T) {
+		prefs := &Preferences{
+			Timezone:   "Europe/Paris",
+			QuietHours: &QuietHours{Start: "22:00", End: "07:30"},
+		}
+
+		_, ok := prefs.QuietUntil(time.Date(2024, 3, 12, 18, 0, 0, 0, paris))
+		assert.False(t, ok)
+
+		until, ok := prefs.QuietUntil(time.Date(2024, 3, 12, 23, 15, 0, 0, paris))
+		assert.True(t, ok)
+		assert.True(t, until.Equal(time.Date(2024, 3, 13, 7, 30, 0, 0, paris)))
+
+		until, ok = prefs.QuietUntil(time.Date(2024, 3, 13, 6, 0, 0, 0, time.UTC))
+		assert.True(t, ok)
+		assert.True(t, until.Equal(time.Date(2024, 3, 13, 7, 30, 0, 0, paris)))
+
+		prefs.QuietHours = &QuietHours{Start: "12:00", End: "14:00"}
+		_, ok = prefs.QuietUntil(time.Date(2024, 3, 12, 14, 0, 0, 0, paris))
+		assert.False(t, ok)
+		until, ok = prefs.QuietUntil(time.Date(2024, 3, 12, 12, 0, 0, 0, paris))
+		assert.True(t, ok)
+		assert.True(t, until.Equal(time.Date(2024, 3, 12, 14, 0, 0, 0, paris)))
+	})
+
+	t.Run("Digest", func(t *testing.T) {
+		prefs := &Preferences{}
+		n := &Notification{Slug: "banks", Category: "balance-lower"}
+		assert.False(t, prefs.UseDigest(n))
+		next := prefs.NextDigest(time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC))
+		assert.True(t, next.Equal(time.Date(2024, 3, 13, 8, 0, 0, 0, time.UTC)))
+
+		prefs.Timezone = "Europe/Paris"
+		prefs.Digest = &Digest{Enabled: true, Time: "18:00"}
+		assert.True(t, prefs.UseDigest(n))
+		next = prefs.NextDigest(time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC))
+		assert.True(t, next.Equal(time.Date(2024, 3, 12, 18, 0, 0, 0, paris)))
+
+		disabled := false
+		prefs.Categories = map[string]CategoryPreferences{
+			"banks/balance-lower": {Digest: &disabled},
+		}
+		assert.False(t, prefs.UseDigest(n))
+		assert.True(t, prefs.UseDigest(&Notification{Originator: "stack", Category: "disk-quota"}))
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		prefs := &Preferences{
+			Timezone:   "Europe/Paris",
+			Categories: map[string]CategoryPreferences{"banks/balance-lower": {Channels: []string{"mobile", "mail"}}},
+			QuietHours: &QuietHours{Start: "22:00", End: "07:00"},
+			Digest:     &Digest{Enabled: true, Time: "08:30"},
+		}
+		assert.NoError(t, prefs.Validate())
+
+		prefs.Timezone = "Mars/Olympus"
+		assert.True(t, errors.Is(prefs.Validate(), ErrInvalidPreferences))
+		prefs.Timezone = ""
+		prefs.QuietHours.End = "25:00"
+		assert.True(t, errors.Is(prefs.Validate(), ErrInvalidPreferences))
+		prefs.QuietHours.End = "07:00"
+		prefs.Categories["banks/balance-lower"] = CategoryPreferences{Channels: []string{"pigeon"}}
+		assert.True(t, errors.Is(prefs.Validate(), ErrInvalidPreferences))
+	})
+}
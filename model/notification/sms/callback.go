@@ -0,0 +1,85 @@
+package sms
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+)
+
+var callbackMACConfig = crypto.MACConfig{
+	Name:   "sms-callback",
+	MaxAge: 7 * 24 * time.Hour,
+	MaxLen: 256,
+}
+
+// CallbackURL returns the URL that a provider can call to update the delivery
+// status of the SMS for the given notification. It contains a token, as the
+// provider can't be authenticated otherwise.
+func CallbackURL(inst *instance.Instance, notificationID string) (string, error) {
+	token, err := crypto.EncodeAuthMessage(callbackMACConfig, inst.SessionSecret(), nil, []byte(notificationID))
+	if err != nil {
+		return "", err
+	}
+	path := "/notifications/" + notificationID + "/sms/callback"
+	return inst.PageURL(path, url.Values{"token": {string(token)}}), nil
+}
+
+// CheckCallbackToken returns true if the token of a callback URL is valid for
+// the given notification.
+func CheckCallbackToken(inst *instance.Instance, notificationID, token string) bool {
+	_, err := crypto.DecodeAuthMessage(callbackMACConfig, inst.SessionSecret(), []byte(token), []byte(notificationID))
+	return err == nil
+}
+
+// RecordStatus saves the delivery status of the SMS in the notification. As
+// the updates can be received out of order, the status can only move forward
+// in its lifecycle: queued, sent, and then delivered or failed.
+func RecordStatus(inst *instance.Instance, notificationID, provider string, status *Status) error {
+	var n notification.Notification
+	if err := couchdb.GetDoc(inst, consts.Notifications, notificationID, &n); err != nil {
+		return err
+	}
+	if n.SMS != nil && !movesForward(n.SMS.Status, status.Status) {
+		return nil
+	}
+	delivery := &notification.SMSDelivery{
+		Provider:  provider,
+		MessageID: status.MessageID,
+		Status:    status.Status,
+		Error:     status.Error,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if delivery.MessageID == "" && n.SMS != nil {
+		delivery.MessageID = n.SMS.MessageID
+	}
+	n.SMS = delivery
+	return couchdb.UpdateDoc(inst, &n)
+}
+
+// movesForward returns true if the status of a SMS can go from old to status.
+// The same status can be recorded again, for example with more details on the
+// error.
+func movesForward(old, status string) bool {
+	if old == status {
+		return true
+	}
+	return statusStep(status) > statusStep(old)
+}
+
+// statusStep returns the position of the status in the lifecycle of a SMS.
+func statusStep(status string) int {
+	switch status {
+	case StatusQueued:
+		return 1
+	case StatusSent:
+		return 2
+	case StatusDelivered, StatusFailed:
+		return 3
+	}
+	return 0
+}
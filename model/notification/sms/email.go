@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/mail"
+)
+
+// emailProvider sends the SMS via an email-to-SMS gateway: the text is sent
+// by mail to <number>@<gateway>, where the number is in the international
+// format without the leading +.
+type emailProvider struct {
+	gateway string
+}
+
+func newEmailProvider(cfg config.SMS) (Provider, error) {
+	gateway := strings.TrimPrefix(cfg.Gateway, "@")
+	if gateway == "" {
+		return nil, errors.New("email: missing gateway")
+	}
+	return &emailProvider{gateway: gateway}, nil
+}
+
+func (p *emailProvider) Send(ctx context.Context, inst *instance.Instance, msg *Message) (string, error) {
+	number := normalizeNumber(msg.Number)
+	if number == "" {
+		return "", errors.New("email: invalid phone number")
+	}
+	opts := &mail.Options{
+		Mode:    mail.ModeFromUser,
+		Subject: "SMS",
+		To:      []*mail.Address{{Email: number + "@" + p.gateway}},
+		Parts:   []*mail.Part{{Type: "text/plain", Body: msg.Text}},
+	}
+	m, err := job.NewMessage(opts)
+	if err != nil {
+		return "", err
+	}
+	j, err := job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: "sendmail",
+		Message:    m,
+	})
+	if err != nil {
+		return "", err
+	}
+	return j.ID(), nil
+}
+
+// normalizeNumber keeps only the digits of a phone number.
+func normalizeNumber(number string) string {
+	var sb strings.Builder
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,166 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/labstack/echo/v4"
+)
+
+// httpProvider is a generic provider for the HTTP APIs. The {number}, {text}
+// and {callback_url} placeholders are replaced in the URL and the body, with
+// the escaping of the content type for the body.
+type httpProvider struct {
+	url         string
+	method      string
+	contentType string
+	headers     map[string]string
+	body        string
+	token       string
+}
+
+func newHTTPProvider(cfg config.SMS) (Provider, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("http: missing url")
+	}
+	method := strings.ToUpper(cfg.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	contentType := cfg.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSON
+	}
+	body := cfg.Body
+	if body == "" && method != http.MethodGet {
+		if strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+			body = `{"to":"{number}","text":"{text}","callback_url":"{callback_url}"}`
+		} else {
+			body = "to={number}&text={text}&callback_url={callback_url}"
+		}
+	}
+	return &httpProvider{
+		url:         cfg.URL,
+		method:      method,
+		contentType: contentType,
+		headers:     cfg.Headers,
+		body:        body,
+		token:       cfg.Token,
+	}, nil
+}
+
+func (p *httpProvider) Send(ctx context.Context, inst *instance.Instance, msg *Message) (string, error) {
+	u := replacePlaceholders(p.url, msg, url.QueryEscape)
+	var body io.Reader
+	if p.body != "" {
+		escape := url.QueryEscape
+		if strings.HasPrefix(p.contentType, echo.MIMEApplicationJSON) {
+			escape = escapeJSONString
+		}
+		body = strings.NewReader(replacePlaceholders(p.body, msg, escape))
+	}
+	req, err := http.NewRequestWithContext(ctx, p.method, u, body)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		req.Header.Set(echo.HeaderContentType, p.contentType)
+	}
+	if p.token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+p.token)
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return "", fmt.Errorf("Unexpected status code: %d", res.StatusCode)
+	}
+
+	// The identifier of the message is optional in the response
+	var resp struct {
+		ID        interface{} `json:"id"`
+		MessageID interface{} `json:"message_id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err == nil {
+		if resp.MessageID != nil {
+			return fmt.Sprintf("%v", resp.MessageID), nil
+		}
+		if resp.ID != nil {
+			return fmt.Sprintf("%v", resp.ID), nil
+		}
+	}
+	return "", nil
+}
+
+// ParseCallback accepts a JSON body or a form, with a status field
+// (queued, sent, delivered, undelivered or failed), and the optional id and
+// error fields.
+func (p *httpProvider) ParseCallback(r *http.Request) (*Status, error) {
+	var fields struct {
+		ID        string `json:"id"`
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+		Error     string `json:"error"`
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	if mediaType == echo.MIMEApplicationJSON {
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			return nil, ErrInvalidCallback
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return nil, ErrInvalidCallback
+		}
+		fields.ID = r.Form.Get("id")
+		fields.MessageID = r.Form.Get("message_id")
+		fields.Status = r.Form.Get("status")
+		fields.Error = r.Form.Get("error")
+	}
+
+	status := &Status{MessageID: fields.MessageID, Error: fields.Error}
+	if status.MessageID == "" {
+		status.MessageID = fields.ID
+	}
+	switch strings.ToLower(fields.Status) {
+	case StatusQueued:
+		status.Status = StatusQueued
+	case StatusSent:
+		status.Status = StatusSent
+	case StatusDelivered:
+		status.Status = StatusDelivered
+	case StatusFailed, "undelivered":
+		status.Status = StatusFailed
+	default:
+		return nil, ErrInvalidCallback
+	}
+	return status, nil
+}
+
+func replacePlaceholders(s string, msg *Message, escape func(string) string) string {
+	return strings.NewReplacer(
+		"{number}", escape(msg.Number),
+		"{text}", escape(msg.Text),
+		"{callback_url}", escape(msg.CallbackURL),
+	).Replace(s)
+}
+
+// escapeJSONString escapes a string to be put between double quotes in a
+// JSON template.
+func escapeJSONString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
@@ -0,0 +1,140 @@
+// Package sms is used to send the notifications by SMS, via a provider that
+// is configured for each context. The providers are registered by their name,
+// and the stack comes with some built-in providers:
+//   - api_sen
+//   - twilio, for the Twilio API (and the compatible APIs)
+//   - http, for a generic HTTP API, with a body template
+//   - email, for the email-to-SMS gateways.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// The delivery statuses of a SMS.
+const (
+	// StatusQueued is when the provider has accepted the SMS, but it has not
+	// been sent yet
+	StatusQueued = "queued"
+	// StatusSent is when the SMS has been sent to the operator
+	StatusSent = "sent"
+	// StatusDelivered is when the operator has confirmed the delivery
+	StatusDelivered = "delivered"
+	// StatusFailed is when the SMS can't be delivered
+	StatusFailed = "failed"
+)
+
+var (
+	// ErrNotConfigured is used when there is no SMS provider for the context
+	// of an instance
+	ErrNotConfigured = errors.New("SMS not configured on this context")
+	// ErrUnknownProvider is used when the provider of the configuration has
+	// not been registered
+	ErrUnknownProvider = errors.New("Unknown provider for sending SMS")
+	// ErrInvalidCallback is used when the request for a delivery status can't
+	// be understood
+	ErrInvalidCallback = errors.New("Invalid SMS delivery callback")
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Message is a SMS to send.
+type Message struct {
+	NotificationID string
+	Number         string
+	Text           string
+	// CallbackURL is the URL where the provider can send the updates of the
+	// delivery status (empty if there is no notification to update)
+	CallbackURL string
+}
+
+// Provider is the interface for sending a SMS.
+type Provider interface {
+	// Send sends the SMS, and returns the identifier of the message for the
+	// provider (it can be empty).
+	Send(ctx context.Context, inst *instance.Instance, msg *Message) (string, error)
+}
+
+// CallbackParser is an optional interface for the providers that can send
+// the updates of the delivery status of a SMS.
+type CallbackParser interface {
+	ParseCallback(r *http.Request) (*Status, error)
+}
+
+// Status is an update of the delivery status of a SMS.
+type Status struct {
+	MessageID string
+	Status    string
+	Error     string
+}
+
+// Factory creates a provider from its configuration.
+type Factory func(cfg config.SMS) (Provider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Factory)
+)
+
+// Register makes a SMS provider available with the given name, for the
+// provider field of the configuration. It panics if it is called twice with
+// the same name.
+func Register(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if factory == nil {
+		panic("sms: Register factory is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("sms: Register called twice for provider " + name)
+	}
+	providers[name] = factory
+}
+
+// Providers returns the sorted list of the names of the registered providers.
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the provider for the given configuration.
+func New(cfg config.SMS) (Provider, error) {
+	providersMu.RLock()
+	factory, ok := providers[cfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, cfg.Provider)
+	}
+	return factory(cfg)
+}
+
+// GetConfig returns the SMS configuration for the context of the instance.
+func GetConfig(inst *instance.Instance) (*config.SMS, error) {
+	cfg, ok := config.GetConfig().Notifications.Contexts[inst.ContextName]
+	if !ok {
+		return nil, ErrNotConfigured
+	}
+	return &cfg, nil
+}
+
+func init() {
+	Register("api_sen", newSenProvider)
+	Register("twilio", newTwilioProvider)
+	Register("http", newHTTPProvider)
+	Register("email", newEmailProvider)
+}
@@ -0,0 +1,65 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type senProvider struct {
+	url   string
+	token string
+}
+
+func newSenProvider(cfg config.SMS) (Provider, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("api_sen: missing url")
+	}
+	return &senProvider{url: cfg.URL, token: cfg.Token}, nil
+}
+
+func (p *senProvider) Send(ctx context.Context, inst *instance.Instance, msg *Message) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"content":  msg.Text,
+		"receiver": []interface{}{msg.Number},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	req.Header.Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Add(echo.HeaderAuthorization, "Bearer "+p.token)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 200 {
+		return "", nil
+	}
+
+	var log logger.Logger = inst.Logger().WithNamespace("sms")
+	var body map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err == nil {
+		if t, ok := body["type"].(string); ok {
+			log = log.WithField("type", t)
+		}
+		if detail, ok := body["detail"].(string); ok {
+			log = log.WithField("detail", detail)
+		}
+		log.WithField("status_code", res.StatusCode).Warnf("Cannot send SMS")
+	}
+	return "", fmt.Errorf("Unexpected status code: %d", res.StatusCode)
+}
@@ -0,0 +1,139 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviders(t *testing.T) {
+	inst := &instance.Instance{Domain: "alice.cozy.localhost"}
+	msg := &Message{
+		NotificationID: "notif-id",
+		Number:         "+33 6 12 34 56 78",
+		Text:           `Hello "Alice" & Bob`,
+		CallbackURL:    "https://alice.cozy.localhost/notifications/notif-id/sms/callback?token=abc",
+	}
+
+	t.Run("Registry", func(t *testing.T) {
+		assert.Equal(t, []string{"api_sen", "email", "http", "twilio"}, Providers())
+		_, err := New(config.SMS{Provider: "unknown"})
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+		_, err = New(config.SMS{Provider: "twilio"})
+		assert.Error(t, err)
+		assert.Panics(t, func() { Register("http", newHTTPProvider) })
+	})
+
+	t.Run("Twilio", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "AC123", user)
+			assert.Equal(t, "secret", pass)
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, msg.Number, r.PostForm.Get("To"))
+			assert.Equal(t, "+33600000000", r.PostForm.Get("From"))
+			assert.Equal(t, msg.Text, r.PostForm.Get("Body"))
+			assert.Equal(t, msg.CallbackURL, r.PostForm.Get("StatusCallback"))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"sid": "SM42", "status": "queued"}`))
+		}))
+		defer ts.Close()
+
+		p, err := New(config.SMS{
+			Provider:   "twilio",
+			URL:        ts.URL,
+			AccountSID: "AC123",
+			Token:      "secret",
+			From:       "+33600000000",
+		})
+		require.NoError(t, err)
+		id, err := p.Send(context.Background(), inst, msg)
+		require.NoError(t, err)
+		assert.Equal(t, "SM42", id)
+
+		form := url.Values{
+			"MessageSid":    {"SM42"},
+			"MessageStatus": {"undelivered"},
+			"ErrorCode":     {"30003"},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		status, err := p.(CallbackParser).ParseCallback(req)
+		require.NoError(t, err)
+		assert.Equal(t, "SM42", status.MessageID)
+		assert.Equal(t, StatusFailed, status.Status)
+		assert.Equal(t, "error code 30003", status.Error)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "/send/33612345678", r.URL.Path)
+			assert.Equal(t, "some-key", r.Header.Get("X-Api-Key"))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			var payload map[string]string
+			require.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, msg.Number, payload["to"])
+			assert.Equal(t, msg.Text, payload["text"])
+			assert.Equal(t, msg.CallbackURL, payload["callback"])
+			_, _ = w.Write([]byte(`{"message_id": 1234}`))
+		}))
+		defer ts.Close()
+
+		p, err := New(config.SMS{
+			Provider: "http",
+			URL:      ts.URL + "/send/33612345678",
+			Method:   "put",
+			Headers:  map[string]string{"x-api-key": "some-key"},
+			Body:     `{"to": "{number}", "text": "{text}", "callback": "{callback_url}"}`,
+		})
+		require.NoError(t, err)
+		id, err := p.Send(context.Background(), inst, msg)
+		require.NoError(t, err)
+		assert.Equal(t, "1234", id)
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id": "1234", "status": "DELIVERED"}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		status, err := p.(CallbackParser).ParseCallback(req)
+		require.NoError(t, err)
+		assert.Equal(t, "1234", status.MessageID)
+		assert.Equal(t, StatusDelivered, status.Status)
+
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("status=unknown"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err = p.(CallbackParser).ParseCallback(req)
+		assert.ErrorIs(t, err, ErrInvalidCallback)
+	})
+
+	t.Run("Email", func(t *testing.T) {
+		_, err := New(config.SMS{Provider: "email"})
+		assert.Error(t, err)
+		assert.Equal(t, "33612345678", normalizeNumber(msg.Number))
+	})
+}
+
+func TestStatusMovesForward(t *testing.T) {
+	assert.True(t, movesForward("", StatusQueued))
+	assert.True(t, movesForward(StatusQueued, StatusSent))
+	assert.True(t, movesForward(StatusQueued, StatusDelivered))
+	assert.True(t, movesForward(StatusSent, StatusFailed))
+	assert.True(t, movesForward(StatusFailed, StatusFailed))
+	assert.False(t, movesForward(StatusSent, StatusQueued))
+	assert.False(t, movesForward(StatusDelivered, StatusSent))
+	assert.False(t, movesForward(StatusDelivered, StatusFailed))
+	assert.False(t, movesForward(StatusFailed, StatusDelivered))
+	assert.False(t, movesForward(StatusSent, "unknown"))
+}
@@ -0,0 +1,109 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/labstack/echo/v4"
+)
+
+const twilioDefaultURL = "https://api.twilio.com"
+
+// twilioProvider sends the SMS with the Messages resource of the Twilio API.
+// The url can be changed for the providers with a compatible API.
+type twilioProvider struct {
+	url        string
+	accountSID string
+	token      string
+	from       string
+}
+
+func newTwilioProvider(cfg config.SMS) (Provider, error) {
+	if cfg.AccountSID == "" || cfg.Token == "" || cfg.From == "" {
+		return nil, errors.New("twilio: account_sid, token and from are mandatory")
+	}
+	u := cfg.URL
+	if u == "" {
+		u = twilioDefaultURL
+	}
+	return &twilioProvider{
+		url:        strings.TrimSuffix(u, "/"),
+		accountSID: cfg.AccountSID,
+		token:      cfg.Token,
+		from:       cfg.From,
+	}, nil
+}
+
+func (p *twilioProvider) Send(ctx context.Context, inst *instance.Instance, msg *Message) (string, error) {
+	form := url.Values{}
+	form.Set("To", msg.Number)
+	form.Set("Body", msg.Text)
+	// The sender can be a messaging service instead of a phone number
+	if strings.HasPrefix(p.from, "MG") {
+		form.Set("MessagingServiceSid", p.from)
+	} else {
+		form.Set("From", p.from)
+	}
+	if msg.CallbackURL != "" {
+		form.Set("StatusCallback", msg.CallbackURL)
+	}
+
+	u := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.url, url.PathEscape(p.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.accountSID, p.token)
+	req.Header.Add(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	req.Header.Add(echo.HeaderContentType, echo.MIMEApplicationForm)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		SID     string `json:"sid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	if res.StatusCode/100 != 2 {
+		if body.Message != "" {
+			return "", fmt.Errorf("twilio: %s (code %d)", body.Message, body.Code)
+		}
+		return "", fmt.Errorf("Unexpected status code: %d", res.StatusCode)
+	}
+	return body.SID, nil
+}
+
+// ParseCallback parses the requests sent to the StatusCallback URL.
+func (p *twilioProvider) ParseCallback(r *http.Request) (*Status, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, ErrInvalidCallback
+	}
+	status := &Status{MessageID: r.PostForm.Get("MessageSid")}
+	switch r.PostForm.Get("MessageStatus") {
+	case "accepted", "scheduled", "queued", "sending":
+		status.Status = StatusQueued
+	case "sent":
+		status.Status = StatusSent
+	case "delivered":
+		status.Status = StatusDelivered
+	case "undelivered", "failed", "canceled":
+		status.Status = StatusFailed
+		if code := r.PostForm.Get("ErrorCode"); code != "" {
+			status.Error = "error code " + code
+		}
+	default:
+		return nil, ErrInvalidCallback
+	}
+	return status, nil
+}
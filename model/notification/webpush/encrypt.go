@@ -0,0 +1,140 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// recordSize is the record size of the aes128gcm content coding (RFC 8188).
+// A push message is always sent in a single record.
+const recordSize = 4096
+
+// headerSize is the size of the aes128gcm header with a P-256 public key as
+// key id: salt (16) + record size (4) + key id length (1) + key id (65)
+const headerSize = 16 + 4 + 1 + 65
+
+// MaxPayloadSize is the maximal size of a payload that can be sent in a
+// push message.
+const MaxPayloadSize = recordSize - headerSize - aesGCMOverhead - 1
+
+const aesGCMOverhead = 16
+
+var (
+	// ErrPayloadTooLarge is used when the payload can't fit in a push message
+	ErrPayloadTooLarge = errors.New("webpush: payload too large")
+	// ErrInvalidKeys is used when the keys of a subscription are not valid
+	ErrInvalidKeys = errors.New("webpush: invalid subscription keys")
+	// ErrInvalidContent is used when an encrypted content can't be decrypted
+	ErrInvalidContent = errors.New("webpush: invalid encrypted content")
+)
+
+// encrypt encrypts the payload for the user agent with the given public key
+// and authentication secret, as described in RFC 8291.
+func encrypt(uaPublic *ecdh.PublicKey, authSecret, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, nonce, err := contentCipher(asPrivate, uaPublic, asPrivate.PublicKey(), uaPublic, authSecret, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	asPublic := asPrivate.PublicKey().Bytes()
+	header := make([]byte, 0, headerSize)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// The padding delimiter 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// decrypt is the reverse of encrypt, on the user agent side.
+func decrypt(uaPrivate *ecdh.PrivateKey, authSecret, body []byte) ([]byte, error) {
+	if len(body) < headerSize {
+		return nil, ErrInvalidContent
+	}
+	salt := body[:16]
+	keyLen := int(body[20])
+	if len(body) < 21+keyLen {
+		return nil, ErrInvalidContent
+	}
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+keyLen])
+	if err != nil {
+		return nil, ErrInvalidContent
+	}
+	gcm, nonce, err := contentCipher(uaPrivate, asPublic, asPublic, uaPrivate.PublicKey(), authSecret, salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, body[21+keyLen:], nil)
+	if err != nil {
+		return nil, ErrInvalidContent
+	}
+	plaintext = bytes.TrimRight(plaintext, "\x00")
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		return nil, ErrInvalidContent
+	}
+	return plaintext[:len(plaintext)-1], nil
+}
+
+// contentCipher derives the content encryption key and the nonce from the
+// ECDH shared secret, the authentication secret and the salt.
+func contentCipher(
+	priv *ecdh.PrivateKey,
+	peer *ecdh.PublicKey,
+	asPublic, uaPublic *ecdh.PublicKey,
+	authSecret, salt []byte,
+) (cipher.AEAD, []byte, error) {
+	ecdhSecret, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, nil, ErrInvalidKeys
+	}
+
+	keyInfo := []byte("WebPush: info\x00")
+	keyInfo = append(keyInfo, uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic.Bytes()...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ecdhSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, nil, err
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gcm, nonce, nil
+}
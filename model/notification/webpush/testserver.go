@@ -0,0 +1,133 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// TestMessage is a push message received by a TestPushService, after its
+// decryption.
+type TestMessage struct {
+	Endpoint string
+	Payload  []byte
+	Header   http.Header
+}
+
+// TestPushService is a local stand-in for a push service, that can be used in
+// the tests and in development. It acts as a browser too: it creates the
+// subscriptions and decrypts the messages it receives.
+type TestPushService struct {
+	Server   *httptest.Server
+	Messages chan TestMessage
+
+	mu   sync.Mutex
+	subs map[string]*testSubscription
+}
+
+type testSubscription struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+	gone bool
+}
+
+// NewTestPushService starts a new push service stand-in. It must be closed
+// after use.
+func NewTestPushService() *TestPushService {
+	s := &TestPushService{
+		Messages: make(chan TestMessage, 100),
+		subs:     make(map[string]*testSubscription),
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Close stops the push service.
+func (s *TestPushService) Close() {
+	s.Server.Close()
+}
+
+// Subscribe creates a new subscription, like a browser would do with the
+// PushManager.
+func (s *TestPushService) Subscribe() (*Subscription, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	auth := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, auth); err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return nil, err
+	}
+	enc := base64.RawURLEncoding
+	endpoint := s.Server.URL + "/push/" + enc.EncodeToString(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[endpoint] = &testSubscription{key: key, auth: auth}
+	return &Subscription{
+		Endpoint: endpoint,
+		Keys: Keys{
+			P256dh: enc.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   enc.EncodeToString(auth),
+		},
+	}, nil
+}
+
+// Unsubscribe removes the subscription: the push service will answer with a
+// 410 Gone for the next messages.
+func (s *TestPushService) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ts, ok := s.subs[sub.Endpoint]; ok {
+		ts.gone = true
+	}
+}
+
+func (s *TestPushService) handle(w http.ResponseWriter, r *http.Request) {
+	endpoint := s.Server.URL + r.URL.Path
+	s.mu.Lock()
+	sub, ok := s.subs[endpoint]
+	s.mu.Unlock()
+	switch {
+	case r.Method != http.MethodPost:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case sub.gone:
+		w.WriteHeader(http.StatusGone)
+		return
+	case !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t="),
+		r.Header.Get("Content-Encoding") != "aes128gcm",
+		r.Header.Get("TTL") == "":
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	payload, err := decrypt(sub.key, sub.auth, body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.Messages <- TestMessage{
+		Endpoint: endpoint,
+		Payload:  payload,
+		Header:   r.Header.Clone(),
+	}
+	w.WriteHeader(http.StatusCreated)
+}
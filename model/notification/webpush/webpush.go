@@ -0,0 +1,280 @@
+// Package webpush can be used to send notifications to the browsers with the
+// Web Push protocol (RFC 8030), with VAPID for the identification of the
+// server (RFC 8292), and the encryption of the payload (RFC 8291).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/safehttp"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// defaultTTL is how long a push service should retain a message when the
+// browser is not connected.
+const defaultTTL = 24 * time.Hour
+
+// vapidValidity is the validity of the JWT for VAPID (24 hours at most).
+const vapidValidity = 12 * time.Hour
+
+// ErrNotConfigured is used when the VAPID keys are missing in the
+// configuration
+var ErrNotConfigured = errors.New("webpush: VAPID keys are not configured")
+
+// ErrInvalidEndpoint is used when the endpoint of a subscription is not an
+// https URL.
+var ErrInvalidEndpoint = errors.New("webpush: invalid endpoint")
+
+// Keys are the keys of a push subscription, as given by the PushSubscription
+// object of the browser, encoded in base64url.
+type Keys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+// Subscription is a push subscription of a browser.
+type Subscription struct {
+	Endpoint string
+	Keys     Keys
+}
+
+// Check returns an error if the endpoint or the keys of the subscription are
+// not valid.
+func (s *Subscription) Check() error {
+	if err := s.checkEndpoint(); err != nil {
+		return err
+	}
+	_, _, err := s.decodeKeys()
+	return err
+}
+
+// checkEndpoint returns an error if the endpoint is not an https URL. The push
+// services always use https, and the endpoints are given by the clients.
+func (s *Subscription) checkEndpoint() error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Host == "" || u.Scheme != "https" {
+		return ErrInvalidEndpoint
+	}
+	return nil
+}
+
+func (s *Subscription) decodeKeys() (*ecdh.PublicKey, []byte, error) {
+	p256dh, err := decodeBase64(s.Keys.P256dh)
+	if err != nil {
+		return nil, nil, ErrInvalidKeys
+	}
+	pub, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, nil, ErrInvalidKeys
+	}
+	auth, err := decodeBase64(s.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, nil, ErrInvalidKeys
+	}
+	return pub, auth, nil
+}
+
+// Urgency is the urgency of a push message, used by the browsers to save the
+// battery.
+type Urgency string
+
+const (
+	// UrgencyLow is for the messages that can wait for the device to be
+	// charging or on wifi
+	UrgencyLow Urgency = "low"
+	// UrgencyNormal is the default urgency
+	UrgencyNormal Urgency = "normal"
+	// UrgencyHigh is for the time-sensitive messages
+	UrgencyHigh Urgency = "high"
+)
+
+// Options are the optional parameters of a push message.
+type Options struct {
+	TTL     time.Duration
+	Urgency Urgency
+	// Topic is used to replace a pending message with the same topic (at most
+	// 32 characters of the base64url alphabet)
+	Topic string
+}
+
+// Client can be used to send push messages to the browsers.
+type Client struct {
+	publicKey  string
+	privateKey *ecdsa.PrivateKey
+	subject    string
+	http       *http.Client
+}
+
+// NewClient creates a client for sending push messages with the VAPID keys
+// of the configuration.
+func NewClient(conf config.Notifications) (*Client, error) {
+	if conf.VAPIDPublicKey == "" || conf.VAPIDPrivateKey == "" {
+		return nil, ErrNotConfigured
+	}
+	priv, err := parsePrivateKey(conf.VAPIDPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse vapid_private_key: %s", err)
+	}
+	pub, err := decodeBase64(conf.VAPIDPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse vapid_public_key: %s", err)
+	}
+	expected, err := priv.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse vapid_private_key: %s", err)
+	}
+	if !bytes.Equal(pub, expected.PublicKey().Bytes()) {
+		return nil, errors.New("vapid_public_key does not match vapid_private_key")
+	}
+	subject := conf.VAPIDSubject
+	if subject == "" {
+		subject = "mailto:" + config.GetConfig().NoReplyAddr
+	}
+	return &Client{
+		publicKey:  conf.VAPIDPublicKey,
+		privateKey: priv,
+		subject:    subject,
+		http:       safehttp.DefaultClient,
+	}, nil
+}
+
+// PublicKey returns the VAPID public key, to be used by the browsers as the
+// applicationServerKey when they subscribe.
+func (c *Client) PublicKey() string {
+	return c.publicKey
+}
+
+// Send sends a push message to the given subscription. The returned boolean
+// is true when the push service has answered that the subscription has
+// expired or has been removed, and it can be deleted.
+func (c *Client) Send(ctx context.Context, sub *Subscription, payload []byte, opts *Options) (bool, error) {
+	if err := sub.checkEndpoint(); err != nil {
+		return false, err
+	}
+	pub, auth, err := sub.decodeKeys()
+	if err != nil {
+		return false, err
+	}
+	body, err := encrypt(pub, auth, payload)
+	if err != nil {
+		return false, err
+	}
+	authorization, err := c.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", string(opts.Urgency))
+	}
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return true, fmt.Errorf("webpush: subscription has expired (%d)", res.StatusCode)
+	case res.StatusCode >= 300:
+		return false, fmt.Errorf("webpush: unexpected status code %d", res.StatusCode)
+	}
+	return false, nil
+}
+
+// vapidAuthorization returns the value of the Authorization header for the
+// given endpoint, with a JWT signed by the VAPID private key.
+func (c *Client) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(vapidValidity).Unix(),
+		"sub": c.subject,
+	})
+	signed, err := token.SignedString(c.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, c.publicKey), nil
+}
+
+// GenerateKeys returns a new pair of VAPID keys, encoded in base64url.
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(priv.PublicKey().Bytes()), enc.EncodeToString(priv.Bytes()), nil
+}
+
+func parsePrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	d, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, err
+	}
+	// The public key is in the uncompressed form: 0x04 || X || Y
+	pub := key.PublicKey().Bytes()
+	priv := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	priv.Curve = elliptic.P256()
+	priv.X = new(big.Int).SetBytes(pub[1:33])
+	priv.Y = new(big.Int).SetBytes(pub[33:])
+	return priv, nil
+}
+
+// decodeBase64 decodes the keys that can be encoded in base64url (the usual
+// format), with or without padding, or in standard base64.
+func decodeBase64(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{
+		base64.RawURLEncoding,
+		base64.URLEncoding,
+		base64.RawStdEncoding,
+		base64.StdEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("invalid base64")
+}
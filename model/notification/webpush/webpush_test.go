@@ -0,0 +1,112 @@
+package webpush
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebPush(t *testing.T) {
+	pub, priv, err := GenerateKeys()
+	require.NoError(t, err)
+	client, err := NewClient(config.Notifications{
+		VAPIDPublicKey:  pub,
+		VAPIDPrivateKey: priv,
+		VAPIDSubject:    "mailto:admin@example.org",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pub, client.PublicKey())
+
+	_, err = NewClient(config.Notifications{})
+	assert.ErrorIs(t, err, ErrNotConfigured)
+	other, _, err := GenerateKeys()
+	require.NoError(t, err)
+	_, err = NewClient(config.Notifications{
+		VAPIDPublicKey:  other,
+		VAPIDPrivateKey: priv,
+	})
+	assert.Error(t, err)
+
+	service := NewTestPushService()
+	defer service.Close()
+	sub, err := service.Subscribe()
+	require.NoError(t, err)
+	require.NoError(t, sub.Check())
+	// The certificate of the test server is self-signed
+	client.http = service.Server.Client()
+
+	t.Run("Send", func(t *testing.T) {
+		payload := []byte(`{"title":"Hello","body":"World"}`)
+		gone, err := client.Send(context.Background(), sub, payload, &Options{
+			Urgency: UrgencyHigh,
+			Topic:   "greetings",
+		})
+		require.NoError(t, err)
+		assert.False(t, gone)
+
+		msg := <-service.Messages
+		assert.Equal(t, sub.Endpoint, msg.Endpoint)
+		assert.Equal(t, payload, msg.Payload)
+		assert.Equal(t, "high", msg.Header.Get("Urgency"))
+		assert.Equal(t, "greetings", msg.Header.Get("Topic"))
+		assert.Equal(t, "86400", msg.Header.Get("TTL"))
+
+		// The JWT is signed with the VAPID key, for the origin of the push
+		// service
+		auth := strings.TrimPrefix(msg.Header.Get("Authorization"), "vapid t=")
+		parts := strings.SplitN(auth, ", k=", 2)
+		require.Len(t, parts, 2)
+		assert.Equal(t, pub, parts[1])
+		key, err := parsePrivateKey(priv)
+		require.NoError(t, err)
+		token, err := jwt.Parse(parts[0], func(token *jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"ES256"}))
+		require.NoError(t, err)
+		claims := token.Claims.(jwt.MapClaims)
+		assert.Equal(t, service.Server.URL, claims["aud"])
+		assert.Equal(t, "mailto:admin@example.org", claims["sub"])
+	})
+
+	t.Run("PayloadTooLarge", func(t *testing.T) {
+		payload := make([]byte, MaxPayloadSize+1)
+		_, err := client.Send(context.Background(), sub, payload, nil)
+		assert.ErrorIs(t, err, ErrPayloadTooLarge)
+
+		payload = payload[:MaxPayloadSize]
+		_, err = client.Send(context.Background(), sub, payload, nil)
+		assert.NoError(t, err)
+		msg := <-service.Messages
+		assert.Len(t, msg.Payload, MaxPayloadSize)
+	})
+
+	t.Run("Gone", func(t *testing.T) {
+		service.Unsubscribe(sub)
+		gone, err := client.Send(context.Background(), sub, []byte("bye"), nil)
+		assert.Error(t, err)
+		assert.True(t, gone)
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		invalid := &Subscription{
+			Endpoint: sub.Endpoint,
+			Keys:     Keys{P256dh: "foo", Auth: sub.Keys.Auth},
+		}
+		assert.ErrorIs(t, invalid.Check(), ErrInvalidKeys)
+	})
+
+	t.Run("InvalidEndpoint", func(t *testing.T) {
+		invalid := &Subscription{
+			Endpoint: strings.Replace(sub.Endpoint, "https://", "http://", 1),
+			Keys:     sub.Keys,
+		}
+		assert.ErrorIs(t, invalid.Check(), ErrInvalidEndpoint)
+		_, err := client.Send(context.Background(), invalid, []byte("foo"), nil)
+		assert.ErrorIs(t, err, ErrInvalidEndpoint)
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/webpush"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -38,6 +39,9 @@ const (
 	PlatformAPNS = "apns"
 	// PlatformHuawei platform using Huawei Push Kit
 	PlatformHuawei = "huawei"
+	// PlatformWebPush platform using Web Push in the browsers. The device
+	// token is the endpoint of the push subscription.
+	PlatformWebPush = "webpush"
 )
 
 // DocTypeVersion represents the doctype version. Each time this document
@@ -96,6 +100,8 @@ type Client struct {
 
 	NotificationPlatform    string `json:"notification_platform,omitempty"`     // Declared by the client (optional)
 	NotificationDeviceToken string `json:"notification_device_token,omitempty"` // Declared by the client (optional)
+	// Declared by the client for the webpush platform
+	NotificationWebPushKeys *webpush.Keys `json:"notification_web_push_keys,omitempty"`
 
 	// XXX omitempty does not work for time.Time, thus the interface{} type
 	SynchronizedAt  interface{} `json:"synchronized_at,omitempty"`   // Date of the last synchronization, updated by /settings/synchronized
@@ -134,6 +140,10 @@ func (c *Client) Clone() couchdb.Doc {
 		props := (&v).Clone()
 		cloned.Notifications[k] = *props
 	}
+	if c.NotificationWebPushKeys != nil {
+		keys := *c.NotificationWebPushKeys
+		cloned.NotificationWebPushKeys = &keys
+	}
 	if c.Metadata != nil {
 		cloned.Metadata = c.Metadata.Clone()
 	}
@@ -345,6 +355,14 @@ func (c *Client) checkMandatoryFields(i *instance.Instance) *ClientRegistrationE
 	switch c.NotificationPlatform {
 	case "", PlatformFirebase, PlatformAPNS, PlatformHuawei:
 	case "ios", "android": // retro-compatibility
+	case PlatformWebPush:
+		if sub := c.WebPushSubscription(); sub == nil || sub.Check() != nil {
+			return &ClientRegistrationError{
+				Code:        http.StatusBadRequest,
+				Error:       "invalid_client_metadata",
+				Description: "notification_web_push_keys and notification_device_token must be a valid push subscription",
+			}
+		}
 	default:
 		return &ClientRegistrationError{
 			Code:  http.StatusBadRequest,
@@ -354,6 +372,18 @@ func (c *Client) checkMandatoryFields(i *instance.Instance) *ClientRegistrationE
 	return nil
 }
 
+// WebPushSubscription returns the push subscription of a client with the
+// webpush platform, or nil.
+func (c *Client) WebPushSubscription() *webpush.Subscription {
+	if c.NotificationPlatform != PlatformWebPush || c.NotificationWebPushKeys == nil {
+		return nil
+	}
+	return &webpush.Subscription{
+		Endpoint: c.NotificationDeviceToken,
+		Keys:     *c.NotificationWebPushKeys,
+	}
+}
+
 // CheckSoftwareID checks if a SoftwareID is valid
 func (c *Client) CheckSoftwareID(instance *instance.Instance) *ClientRegistrationError {
 	if strings.HasPrefix(c.SoftwareID, "registry://") {
@@ -26,6 +26,7 @@ var blockList = map[string]bool{
 	consts.Intents:             none,
 	consts.OAuthClients:        none,
 	consts.OAuthAccessCodes:    none,
+	consts.WebAuthnCredentials: none,
 	consts.Archives:            none,
 	consts.Sharings:            none,
 	consts.Shared:              none,
@@ -54,18 +55,23 @@ var blockList = map[string]bool{
 	consts.AppLogs:             none,
 
 	// Only stack can write them
-	consts.Jobs:              readable,
-	consts.Triggers:          readable,
-	consts.Apps:              readable,
-	consts.Konnectors:        readable,
-	consts.Files:             readable,
-	consts.FilesVersions:     readable,
-	consts.Notifications:     readable,
-	consts.RemoteRequests:    readable,
-	consts.SessionsLogins:    readable,
-	consts.NotesSteps:        readable,
-	consts.NotesImages:       readable,
-	consts.BitwardenContacts: readable,
+	consts.Jobs:                       readable,
+	consts.Triggers:                   readable,
+	consts.Apps:                       readable,
+	consts.Konnectors:                 readable,
+	consts.Files:                      readable,
+	consts.FilesVersions:              readable,
+	consts.Notifications:              readable,
+	consts.RemoteRequests:             readable,
+	consts.SessionsLogins:             readable,
+	consts.NotesSteps:                 readable,
+	consts.NotesImages:                readable,
+	consts.BitwardenContacts:          readable,
+	consts.BitwardenSends:             readable,
+	consts.BitwardenEmergencyAccesses: readable,
+	consts.BitwardenEmergencyGrants:   readable,
+	consts.SharingsActivity:           readable,
+	consts.SharingsPlaceholders:       readable,
 }
 
 // CheckReadable will abort the context and returns false if the doctype
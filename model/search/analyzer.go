@@ -0,0 +1,57 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinTermLength is the minimal number of characters for a term to be
+	// indexed or searched.
+	MinTermLength = 2
+	// MaxTermLength is the maximal number of characters of a term. Longer
+	// words are truncated.
+	MaxTermLength = 40
+)
+
+// Tokenize splits a text in a list of terms. The terms are lowercased, and the
+// diacritics are removed, so that "Été" and "ete" gives the same term.
+func Tokenize(text string) []string {
+	folded, _, err := transform.String(folder(), text)
+	if err != nil {
+		folded = text
+	}
+	words := strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := words[:0]
+	for _, word := range words {
+		word = strings.ToLower(word)
+		chars := []rune(word)
+		if len(chars) < MinTermLength {
+			continue
+		}
+		if len(chars) > MaxTermLength {
+			word = string(chars[:MaxTermLength])
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// Frequencies returns the number of occurrences of each term in the text.
+func Frequencies(text string) map[string]int {
+	freqs := make(map[string]int)
+	for _, term := range Tokenize(text) {
+		freqs[term]++
+	}
+	return freqs
+}
+
+func folder() transform.Transformer {
+	return transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+}
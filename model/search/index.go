@@ -0,0 +1,293 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/note"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/revision"
+	"github.com/cozy/cozy-stack/pkg/logger"
+)
+
+// BatchSize is the maximal number of changes indexed by a single job.
+const BatchSize = 100
+
+// MaxContentSize is the maximal size of a file for its content to be indexed.
+// For larger files, only the name and the tags are indexed.
+const MaxContentSize = 20 << 20 // 20MB
+
+// Entry is the document in the search index for a file. The terms of the name
+// and tags are kept separated from the terms of the content, so that a rename
+// doesn't require to extract again the text of the file.
+type Entry struct {
+	DocID        string         `json:"_id,omitempty"`
+	DocRev       string         `json:"_rev,omitempty"`
+	DirID        string         `json:"dir_id"`
+	MD5Sum       []byte         `json:"md5sum,omitempty"`
+	NameTerms    map[string]int `json:"name_terms"`
+	ContentTerms map[string]int `json:"content_terms,omitempty"`
+}
+
+// ID returns the entry qualified identifier, the same as the file
+func (e *Entry) ID() string { return e.DocID }
+
+// Rev returns the entry revision
+func (e *Entry) Rev() string { return e.DocRev }
+
+// DocType returns the entry document type
+func (e *Entry) DocType() string { return consts.FilesSearch }
+
+// Clone implements couchdb.Doc
+func (e *Entry) Clone() couchdb.Doc {
+	cloned := *e
+	cloned.MD5Sum = make([]byte, len(e.MD5Sum))
+	copy(cloned.MD5Sum, e.MD5Sum)
+	cloned.NameTerms = make(map[string]int, len(e.NameTerms))
+	for k, v := range e.NameTerms {
+		cloned.NameTerms[k] = v
+	}
+	cloned.ContentTerms = make(map[string]int, len(e.ContentTerms))
+	for k, v := range e.ContentTerms {
+		cloned.ContentTerms[k] = v
+	}
+	return &cloned
+}
+
+// SetID changes the entry qualified identifier
+func (e *Entry) SetID(id string) { e.DocID = id }
+
+// SetRev changes the entry revision
+func (e *Entry) SetRev(rev string) { e.DocRev = rev }
+
+// IndexMessage is the message used by the search-index worker.
+type IndexMessage struct{}
+
+// Index reads the changes feed of the io.cozy.files database since the last
+// indexation, and updates the search index with them.
+//
+// The lock is refreshed while it is held, as extracting the text of the files
+// of a batch can take longer than the lock timeout.
+func Index(inst *instance.Instance, log logger.Logger) error {
+	mu := config.Lock().LongOperation(inst, "search-index/"+consts.Files)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	lastSeq, err := getLastSeqNumber(inst)
+	if err != nil {
+		return err
+	}
+	feed, err := couchdb.GetChanges(inst, &couchdb.ChangesRequest{
+		DocType:     consts.Files,
+		IncludeDocs: true,
+		Since:       lastSeq,
+		Limit:       BatchSize,
+	})
+	if err != nil {
+		return err
+	}
+	if feed.LastSeq == lastSeq {
+		return nil
+	}
+
+	var errj error
+	for _, change := range feed.Results {
+		if err := indexChange(inst, change); err != nil {
+			log.Warnf("Index error for %s: %s", change.DocID, err)
+			errj = errors.Join(errj, err)
+		}
+	}
+	_ = updateLastSequenceNumber(inst, feed.LastSeq)
+
+	if feed.Pending > 0 {
+		_ = PushJob(inst)
+	}
+
+	return errj
+}
+
+func indexChange(inst *instance.Instance, change couchdb.Change) error {
+	if strings.HasPrefix(change.DocID, "_design/") {
+		return nil
+	}
+
+	var entry Entry
+	err := couchdb.GetDoc(inst, consts.FilesSearch, change.DocID, &entry)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return err
+	}
+	exists := err == nil
+
+	if change.Deleted || change.Doc.Get("type") != consts.FileType ||
+		change.Doc.Get("trashed") == true {
+		if !exists {
+			return nil
+		}
+		return couchdb.DeleteDoc(inst, &entry)
+	}
+
+	raw, err := json.Marshal(&change.Doc)
+	if err != nil {
+		return err
+	}
+	doc := &vfs.FileDoc{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return err
+	}
+	entry.DocID = doc.ID()
+	entry.DirID = doc.DirID
+	entry.NameTerms = nameTerms(doc)
+	if !exists || !bytes.Equal(entry.MD5Sum, doc.MD5Sum) {
+		entry.MD5Sum = doc.MD5Sum
+		entry.ContentTerms = nil
+		text, err := extractText(inst, doc)
+		if err != nil {
+			inst.Logger().WithNamespace("search").
+				Infof("Cannot extract the text of %s: %s", doc.ID(), err)
+		} else if text != "" {
+			entry.ContentTerms = Frequencies(text)
+		}
+	}
+
+	if exists {
+		return couchdb.UpdateDoc(inst, &entry)
+	}
+	return couchdb.CreateNamedDocWithDB(inst, &entry)
+}
+
+func nameTerms(doc *vfs.FileDoc) map[string]int {
+	text := doc.DocName
+	if len(doc.Tags) > 0 {
+		text += " " + strings.Join(doc.Tags, " ")
+	}
+	return Frequencies(text)
+}
+
+// extractText returns the text of the file, or an empty string if the file
+// type is not supported.
+func extractText(inst *instance.Instance, doc *vfs.FileDoc) (string, error) {
+	if doc.Encrypted || doc.ByteSize > MaxContentSize {
+		return "", nil
+	}
+
+	if doc.Mime == consts.NoteMimeType {
+		schema, _ := doc.Metadata["schema"].(map[string]interface{})
+		raw, _ := doc.Metadata["content"].(map[string]interface{})
+		noteDoc := &note.Document{
+			DocID:      doc.ID(),
+			SchemaSpec: schema,
+			RawContent: raw,
+		}
+		return noteDoc.Text()
+	}
+
+	isPDF := doc.Mime == "application/pdf"
+	if !isPDF && !strings.HasPrefix(doc.Mime, "text/") {
+		return "", nil
+	}
+
+	f, err := inst.VFS().OpenFile(doc)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if isPDF {
+		buf, err := config.PDF().ExtractText(f)
+		if err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	content, err := io.ReadAll(io.LimitReader(f, MaxContentSize))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// getLastSeqNumber returns the last sequence number of the previous
+// indexation.
+func getLastSeqNumber(inst *instance.Instance) (string, error) {
+	result, err := couchdb.GetLocal(inst, consts.Files, "search-index")
+	if couchdb.IsNotFoundError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	seq, _ := result["last_seq"].(string)
+	return seq, nil
+}
+
+// updateLastSequenceNumber updates the last sequence number for the
+// indexation if it's superior to the number in CouchDB.
+func updateLastSequenceNumber(inst *instance.Instance, seq string) error {
+	result, err := couchdb.GetLocal(inst, consts.Files, "search-index")
+	if err != nil {
+		if !couchdb.IsNotFoundError(err) {
+			return err
+		}
+		result = make(map[string]interface{})
+	} else {
+		if prev, ok := result["last_seq"].(string); ok {
+			if revision.Generation(seq) <= revision.Generation(prev) {
+				return nil
+			}
+		}
+	}
+	result["last_seq"] = seq
+	return couchdb.PutLocal(inst, consts.Files, "search-index", result)
+}
+
+// PushJob adds a job to index the changes on the files.
+func PushJob(inst *instance.Instance) error {
+	msg, err := job.NewMessage(&IndexMessage{})
+	if err != nil {
+		return err
+	}
+	_, err = job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: "search-index",
+		Message:    msg,
+	})
+	return err
+}
+
+// SetupTrigger adds the trigger that keeps the search index up-to-date with
+// the changes on the files, if it doesn't exist yet. It also pushes a job for
+// the initial indexation of the files when the trigger is created.
+func SetupTrigger(inst *instance.Instance) error {
+	sched := job.System()
+	infos := job.TriggerInfos{
+		Type:       "@event",
+		WorkerType: "search-index",
+		Arguments:  consts.Files,
+		Debounce:   "1m",
+	}
+	if sched.HasTrigger(inst, infos) {
+		return nil
+	}
+
+	msg, err := job.NewMessage(&IndexMessage{})
+	if err != nil {
+		return err
+	}
+	t, err := job.NewTrigger(inst, infos, msg)
+	if err != nil {
+		return err
+	}
+	if err := sched.AddTrigger(t); err != nil {
+		return err
+	}
+	return PushJob(inst)
+}
@@ -0,0 +1,139 @@
+// Package search is an embedded full-text search engine for the files of an
+// instance. The index is fed from the changes feed of io.cozy.files by the
+// search-index worker, and is stored in CouchDB: there is a document for each
+// file with the frequencies of its terms, and a view to invert it.
+package search
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// PostingsPerPage is the number of rows fetched from the index in a single
+// request, when listing the files that contain a term of the query.
+const PostingsPerPage = 1000
+
+// Hit is a file that matches a query, with its relevance.
+type Hit struct {
+	FileID string
+	Score  float64
+}
+
+// Search returns the files that contains all the terms of the query, ordered
+// by relevance. The last term of the query is used as a prefix, to allow
+// search-as-you-type.
+func Search(inst *instance.Instance, query string) ([]Hit, error) {
+	terms := uniq(Tokenize(query))
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	total, err := couchdb.CountNormalDocs(inst, consts.FilesSearch)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	postings := make([]map[string]int, len(terms))
+	for i, term := range terms {
+		prefix := i == len(terms)-1
+		postings[i], err = fetchPostings(inst, term, prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rank(postings, total), nil
+}
+
+// fetchPostings returns the frequency of the term for each file that contains
+// it. The rows of the view are fetched page by page, so that no file is left
+// out for the frequent terms.
+func fetchPostings(inst *instance.Instance, term string, prefix bool) (map[string]int, error) {
+	postings := make(map[string]int)
+	cursor := couchdb.NewKeyCursor(PostingsPerPage, nil, "")
+	for cursor.HasMore() {
+		req := &couchdb.ViewRequest{}
+		if prefix {
+			req.StartKey = term
+			req.EndKey = term + "\ufff0"
+		} else {
+			req.Key = term
+		}
+		cursor.ApplyTo(req)
+		var res couchdb.ViewResponse
+		err := couchdb.ExecView(inst, couchdb.FilesSearchTermsView, req, &res)
+		if couchdb.IsNotFoundError(err) {
+			// The view may be missing on instances created before the search
+			err = couchdb.DefineView(inst, couchdb.FilesSearchTermsView)
+			if err == nil {
+				err = couchdb.ExecView(inst, couchdb.FilesSearchTermsView, req, &res)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		cursor.UpdateFrom(&res)
+
+		for _, row := range res.Rows {
+			if freq, ok := row.Value.(float64); ok {
+				postings[row.ID] += int(freq)
+			}
+		}
+	}
+	return postings, nil
+}
+
+// rank keeps the files that are in all the postings lists, and sorts them with
+// a TF-IDF score. total is the number of files in the index.
+func rank(postings []map[string]int, total int) []Hit {
+	if len(postings) == 0 {
+		return nil
+	}
+
+	scores := make(map[string]float64)
+	for id := range postings[0] {
+		scores[id] = 0
+	}
+	for _, list := range postings {
+		idf := math.Log(1 + float64(total)/float64(len(list)+1))
+		for id, score := range scores {
+			freq, ok := list[id]
+			if !ok || freq <= 0 {
+				delete(scores, id)
+				continue
+			}
+			scores[id] = score + (1+math.Log(float64(freq)))*idf
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{FileID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].FileID < hits[j].FileID
+	})
+	return hits
+}
+
+func uniq(terms []string) []string {
+	seen := make(map[string]struct{}, len(terms))
+	list := terms[:0]
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		list = append(list, term)
+	}
+	return list
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	terms := Tokenize("Été 2023: Compte-rendu de la réunion (v2).pdf")
+	assert.Equal(t, []string{"ete", "2023", "compte", "rendu", "de", "la", "reunion", "v2", "pdf"}, terms)
+
+	assert.Empty(t, Tokenize("a - b"))
+	assert.Empty(t, Tokenize(""))
+
+	long := strings.Repeat("x", 100)
+	terms = Tokenize(long)
+	require.Len(t, terms, 1)
+	assert.Len(t, terms[0], MaxTermLength)
+}
+
+func TestFrequencies(t *testing.T) {
+	freqs := Frequencies("The cat and the other cat. THE END")
+	assert.Equal(t, 3, freqs["the"])
+	assert.Equal(t, 2, freqs["cat"])
+	assert.Equal(t, 1, freqs["end"])
+}
+
+func TestRank(t *testing.T) {
+	postings := []map[string]int{
+		{"file1": 1, "file2": 5, "file3": 2},
+		{"file1": 1, "file2": 1},
+	}
+	hits := rank(postings, 10)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "file2", hits[0].FileID)
+	assert.Equal(t, "file1", hits[1].FileID)
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	hits = rank([]map[string]int{{"file1": 1}, {}}, 10)
+	assert.Empty(t, hits)
+
+	assert.Empty(t, rank(nil, 10))
+}
@@ -207,7 +207,7 @@ func sendLoginNotification(i *instance.Instance, l *LoginEntry) error {
 		changePassphraseLink = i.ChangePasswordURL()
 	}
 	var activateTwoFALink string
-	if !i.HasAuthMode(instance.TwoFactorMail) {
+	if !i.HasTwoFactor() {
 		settingsURL := i.SubDomain(consts.SettingsSlug)
 		settingsURL.Fragment = "/profile"
 		activateTwoFALink = settingsURL.String()
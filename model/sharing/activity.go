@@ -0,0 +1,273 @@
+package sharing
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+)
+
+const (
+	// ActivityCreate is for a file or directory added to the sharing
+	ActivityCreate = "create"
+	// ActivityUpdate is for a change of the content or the metadata of a file
+	ActivityUpdate = "update"
+	// ActivityRename is for a file or directory that has a new name
+	ActivityRename = "rename"
+	// ActivityMove is for a file or directory moved to another directory
+	ActivityMove = "move"
+	// ActivityTrash is for a file or directory put in the trash
+	ActivityTrash = "trash"
+)
+
+// Activity is an entry of the journal of a sharing: it tells which member has
+// made an operation on a shared file or directory, and when. The journal is
+// kept on the cozy of the sharer, as all the changes go through it.
+type Activity struct {
+	DocID       string    `json:"_id,omitempty"`
+	DocRev      string    `json:"_rev,omitempty"`
+	SharingID   string    `json:"sharing_id"`
+	MemberIndex int       `json:"member_index"`
+	MemberName  string    `json:"member_name,omitempty"`
+	Operation   string    `json:"operation"`
+	FileID      string    `json:"file_id"`
+	FileType    string    `json:"file_type"`
+	Name        string    `json:"name"`
+	OldName     string    `json:"old_name,omitempty"`
+	DirID       string    `json:"dir_id,omitempty"`
+	OldDirID    string    `json:"old_dir_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ID returns the activity qualified identifier
+func (a *Activity) ID() string { return a.DocID }
+
+// Rev returns the activity revision
+func (a *Activity) Rev() string { return a.DocRev }
+
+// DocType returns the activity document type
+func (a *Activity) DocType() string { return consts.SharingsActivity }
+
+// Clone implements couchdb.Doc
+func (a *Activity) Clone() couchdb.Doc {
+	cloned := *a
+	return &cloned
+}
+
+// SetID changes the activity qualified identifier
+func (a *Activity) SetID(id string) { a.DocID = id }
+
+// SetRev changes the activity revision
+func (a *Activity) SetRev(rev string) { a.DocRev = rev }
+
+// ListActivity returns the entries of the journal of the sharing, from the
+// most recent, with a bookmark for the next page.
+func (s *Sharing) ListActivity(inst *instance.Instance, limit int, bookmark string) ([]*Activity, string, error) {
+	var activities []*Activity
+	req := &couchdb.FindRequest{
+		UseIndex: "by-sharing-id",
+		Selector: mango.Equal("sharing_id", s.SID),
+		Sort: mango.SortBy{
+			{Field: "sharing_id", Direction: mango.Desc},
+			{Field: "created_at", Direction: mango.Desc},
+		},
+		Bookmark: bookmark,
+		Limit:    limit,
+	}
+	res, err := couchdb.FindDocsRaw(inst, consts.SharingsActivity, req, &activities)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return activities, res.Bookmark, nil
+}
+
+// RemoveActivity deletes the journal of the sharing, when it is revoked.
+func RemoveActivity(inst *instance.Instance, sharingID string) error {
+	for {
+		var activities []*Activity
+		req := &couchdb.FindRequest{
+			UseIndex: "by-sharing-id",
+			Selector: mango.Equal("sharing_id", sharingID),
+			Fields:   []string{"_id", "_rev"},
+			Limit:    consts.MaxItemsPerPageForMango,
+		}
+		err := couchdb.FindDocs(inst, consts.SharingsActivity, req, &activities)
+		if err != nil {
+			if couchdb.IsNoDatabaseError(err) {
+				return nil
+			}
+			return err
+		}
+		if len(activities) == 0 {
+			return nil
+		}
+		docs := make([]couchdb.Doc, len(activities))
+		for i, a := range activities {
+			docs[i] = a
+		}
+		if err := couchdb.BulkDeleteDocs(inst, consts.SharingsActivity, docs); err != nil {
+			return err
+		}
+		if len(activities) < consts.MaxItemsPerPageForMango {
+			return nil
+		}
+	}
+}
+
+// removeActivity deletes the journal of a revoked sharing. A failure is only
+// logged, as it must not block the revocation.
+func (s *Sharing) removeActivity(inst *instance.Instance) {
+	if err := RemoveActivity(inst, s.SID); err != nil {
+		inst.Logger().WithNamespace("sharing").
+			Warnf("Cannot remove the activity of %s: %s", s.SID, err)
+	}
+}
+
+// activityOperation returns the operation made on a file or directory by
+// comparing its new name and parent with the previous ones. An empty dirID
+// means that the parent directory has not been sent.
+func activityOperation(exists, trashed bool, name, dirID, oldName, oldDirID string) string {
+	switch {
+	case !exists:
+		return ActivityCreate
+	case trashed:
+		return ActivityTrash
+	case name != oldName:
+		return ActivityRename
+	case dirID != "" && dirID != oldDirID:
+		return ActivityMove
+	default:
+		return ActivityUpdate
+	}
+}
+
+// recordActivity adds an entry to the journal for an operation made by the
+// given member. A failure is only logged, as the journal must not block the
+// synchronization.
+func (s *Sharing) recordActivity(inst *instance.Instance, m *Member, a *Activity) {
+	if !s.Owner {
+		return
+	}
+	a.SharingID = s.SID
+	a.MemberIndex = s.memberIndex(m)
+	a.MemberName = m.PrimaryName()
+	a.CreatedAt = time.Now().UTC()
+	if a.Operation != ActivityRename {
+		a.OldName = ""
+	}
+	if a.Operation != ActivityMove {
+		a.OldDirID = ""
+	}
+	if err := couchdb.CreateDoc(inst, a); err != nil {
+		inst.Logger().WithNamespace("sharing").
+			Warnf("Cannot record the activity for %s: %s", s.SID, err)
+	}
+}
+
+// memberIndex returns the index of the given member in the members list, or
+// -1 if it is not a member of this sharing.
+func (s *Sharing) memberIndex(m *Member) int {
+	for i := range s.Members {
+		if &s.Members[i] == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// fileActivity returns the entry for the journal of a change on a file sent
+// by a member. The current file is nil for a new file.
+func fileActivity(target *FileDocWithRevisions, current *vfs.FileDoc) *Activity {
+	a := &Activity{
+		FileID:   target.DocID,
+		FileType: consts.FileType,
+		Name:     target.DocName,
+		DirID:    target.DirID,
+	}
+	if current != nil {
+		a.OldName, a.OldDirID = current.DocName, current.DirID
+	}
+	a.Operation = activityOperation(current != nil, target.Trashed, a.Name, a.DirID, a.OldName, a.OldDirID)
+	return a
+}
+
+// bulkFilesActivities returns the entries for the journal that the documents
+// of io.cozy.files sent by a member will produce. The files that are not in
+// the trash are synchronized by SyncFile, and their activity is recorded
+// there.
+func (s *Sharing) bulkFilesActivities(inst *instance.Instance, docs DocsList) []*Activity {
+	if !s.Owner || len(docs) == 0 {
+		return nil
+	}
+	var activities []*Activity
+	fs := inst.VFS()
+	for _, doc := range docs {
+		id, _ := doc["_id"].(string)
+		_, deleted := doc["_deleted"]
+		if !deleted && doc["type"] != consts.DirType {
+			continue
+		}
+		dir, file, err := fs.DirOrFileByID(id)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		a := &Activity{FileID: id, FileType: consts.DirType}
+		switch {
+		case dir != nil:
+			a.OldName, a.OldDirID = dir.DocName, dir.DirID
+		case file != nil:
+			a.FileType = consts.FileType
+			a.OldName, a.OldDirID = file.DocName, file.DirID
+		case deleted:
+			continue
+		}
+		a.Name, _ = doc["name"].(string)
+		a.DirID, _ = doc["dir_id"].(string)
+		if deleted {
+			a.Name = a.OldName
+		}
+		exists := dir != nil || file != nil
+		a.Operation = activityOperation(exists, deleted, a.Name, a.DirID, a.OldName, a.OldDirID)
+		activities = append(activities, a)
+	}
+	return activities
+}
+
+// recordLocalActivity adds an entry to the journal for a change on a file or
+// directory made on the cozy of the sharer, as seen by the share-track worker.
+func recordLocalActivity(inst *instance.Instance, sharingID string, evt TrackEvent) {
+	s, err := FindSharing(inst, sharingID)
+	if err != nil || !s.Owner {
+		return
+	}
+	fileType, _ := evt.Doc.Get("type").(string)
+	if fileType != consts.FileType && fileType != consts.DirType {
+		return
+	}
+	name, _ := evt.Doc.Get("name").(string)
+	dirID, _ := evt.Doc.Get("dir_id").(string)
+	a := &Activity{
+		FileID:   evt.Doc.ID(),
+		FileType: fileType,
+		Name:     name,
+		DirID:    dirID,
+	}
+	exists := evt.Verb != "CREATED"
+	if evt.OldDoc != nil {
+		a.OldName, _ = evt.OldDoc.Get("name").(string)
+		a.OldDirID, _ = evt.OldDoc.Get("dir_id").(string)
+	} else {
+		a.OldName, a.OldDirID = name, dirID
+	}
+	trashed := evt.Verb == "DELETED" || isTrashed(evt.Doc)
+	a.Operation = activityOperation(exists, trashed, a.Name, a.DirID, a.OldName, a.OldDirID)
+	s.recordActivity(inst, &s.Members[0], a)
+}
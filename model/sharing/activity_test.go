@@ -0,0 +1,121 @@
+package sharing
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityOperation(t *testing.T) {
+	assert.Equal(t, ActivityCreate, activityOperation(false, false, "foo", "dir", "", ""))
+	assert.Equal(t, ActivityTrash, activityOperation(true, true, "foo", "dir", "bar", "dir"))
+	assert.Equal(t, ActivityRename, activityOperation(true, false, "foo", "dir", "bar", "dir"))
+	assert.Equal(t, ActivityMove, activityOperation(true, false, "foo", "other", "foo", "dir"))
+	assert.Equal(t, ActivityUpdate, activityOperation(true, false, "foo", "dir", "foo", "dir"))
+	// The dir_id is not sent for the files at the root of a rule
+	assert.Equal(t, ActivityUpdate, activityOperation(true, false, "foo", "", "foo", "dir"))
+}
+
+func TestActivity(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+
+	newSharing := func(owner bool) *Sharing {
+		s := &Sharing{
+			Owner:  owner,
+			Active: true,
+			Members: []Member{
+				{Status: MemberStatusOwner, Name: "Alice"},
+				{Status: MemberStatusReady, Name: "Bob"},
+			},
+		}
+		require.NoError(t, couchdb.CreateDoc(inst, s))
+		return s
+	}
+
+	dirEvent := func(verb, id, name, oldName string) TrackEvent {
+		evt := TrackEvent{
+			Verb: verb,
+			Doc: couchdb.JSONDoc{Type: consts.Files, M: map[string]interface{}{
+				"_id":    id,
+				"type":   consts.DirType,
+				"name":   name,
+				"dir_id": "parent",
+			}},
+		}
+		if oldName != "" {
+			evt.OldDoc = &couchdb.JSONDoc{Type: consts.Files, M: map[string]interface{}{
+				"_id":    id,
+				"type":   consts.DirType,
+				"name":   oldName,
+				"dir_id": "parent",
+			}}
+		}
+		return evt
+	}
+
+	t.Run("LocalDirectory", func(t *testing.T) {
+		s := newSharing(true)
+		dirID := uuidv7()
+		recordLocalActivity(inst, s.SID, dirEvent("CREATED", dirID, "foo", ""))
+		recordLocalActivity(inst, s.SID, dirEvent("UPDATED", dirID, "bar", "foo"))
+
+		activities, _, err := s.ListActivity(inst, 10, "")
+		require.NoError(t, err)
+		require.Len(t, activities, 2)
+		ops := []string{activities[0].Operation, activities[1].Operation}
+		assert.ElementsMatch(t, []string{ActivityCreate, ActivityRename}, ops)
+		for _, a := range activities {
+			assert.Equal(t, dirID, a.FileID)
+			assert.Equal(t, consts.DirType, a.FileType)
+			assert.Equal(t, 0, a.MemberIndex)
+			assert.Equal(t, "Alice", a.MemberName)
+		}
+	})
+
+	t.Run("NotOnRecipient", func(t *testing.T) {
+		s := newSharing(false)
+		recordLocalActivity(inst, s.SID, dirEvent("CREATED", uuidv7(), "foo", ""))
+		s.recordActivity(inst, &s.Members[1], &Activity{
+			FileID:    uuidv7(),
+			FileType:  consts.FileType,
+			Name:      "bar",
+			Operation: ActivityCreate,
+		})
+
+		activities, _, err := s.ListActivity(inst, 10, "")
+		require.NoError(t, err)
+		assert.Empty(t, activities)
+	})
+
+	t.Run("RemovedOnRevocation", func(t *testing.T) {
+		s := newSharing(true)
+		other := newSharing(true)
+		recordLocalActivity(inst, s.SID, dirEvent("CREATED", uuidv7(), "foo", ""))
+		recordLocalActivity(inst, other.SID, dirEvent("CREATED", uuidv7(), "foo", ""))
+
+		s.Members[1].Status = MemberStatusRevoked
+		require.NoError(t, s.NoMoreRecipient(inst))
+		assert.False(t, s.Active)
+
+		activities, _, err := s.ListActivity(inst, 10, "")
+		require.NoError(t, err)
+		assert.Empty(t, activities)
+
+		// The journal of the other sharings is kept
+		activities, _, err = other.ListActivity(inst, 10, "")
+		require.NoError(t, err)
+		assert.Len(t, activities, 1)
+	})
+}
@@ -61,4 +61,15 @@ var (
 	// ErrMemberAlreadyInGroup is used when trying to add a group with a member
 	// already in another group of the sharing with different rights.
 	ErrMemberAlreadyInGroup = errors.New("A group member cannot be added as they are already in another group of the sharing")
+	// ErrInvalidExpiration is used when the expiration date of a sharing or
+	// a member is not in the future
+	ErrInvalidExpiration = errors.New("The expiration date must be in the future")
+	// ErrSharingExpired is used when a member tries to access a sharing after
+	// its expiration date, or the expiration date of their access
+	ErrSharingExpired = errors.New("The sharing has expired")
+	// ErrInvalidRole is used when trying to give an unknown role to a member
+	ErrInvalidRole = errors.New("This role is not valid")
+	// ErrInvalidExcludedDir is used when trying to exclude a directory that
+	// is not inside the directory of the sharing
+	ErrInvalidExcludedDir = errors.New("This directory cannot be excluded from the sharing")
 )
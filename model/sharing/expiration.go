@@ -0,0 +1,277 @@
+package sharing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// expirationRetryDelay is the minimal delay between two retries of the
+// revocation of an expired sharing or member, when an access is refused.
+const expirationRetryDelay = 5 * time.Minute
+
+// ExpirationReminders are the delays before the expiration of a sharing (or
+// of the access of a member) when the owner is reminded of it.
+var ExpirationReminders = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour}
+
+// ExpireMsg is used for the share-expire worker. A member index of 0 is for
+// the whole sharing.
+type ExpireMsg struct {
+	SharingID   string    `json:"sharing_id"`
+	MemberIndex int       `json:"member_index,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Reminder    bool      `json:"reminder,omitempty"`
+}
+
+// Expired returns true if the sharing has an expiration date in the past.
+func (s *Sharing) Expired() bool {
+	return s.ExpiresAt != nil && s.ExpiresAt.Before(time.Now())
+}
+
+// Expired returns true if the access of the member has an expiration date in
+// the past.
+func (m *Member) Expired() bool {
+	return m.ExpiresAt != nil && m.ExpiresAt.Before(time.Now())
+}
+
+// CheckExpiration returns ErrSharingExpired if the sharing, or the access of
+// the given member, has expired on the owner side. The expiration is checked
+// at each access, as the revocation made by the share-expire worker may have
+// failed, and in such case, the revocation is retried.
+func (s *Sharing) CheckExpiration(inst *instance.Instance, m *Member) error {
+	if !s.Owner || !s.Active {
+		return nil
+	}
+	index := 0
+	if !s.Expired() {
+		if m == nil || !m.Expired() {
+			return nil
+		}
+		index = s.memberIndex(m)
+		if index <= 0 {
+			return ErrSharingExpired
+		}
+	}
+	s.retryExpiration(inst, index)
+	return ErrSharingExpired
+}
+
+// retryExpiration pushes a job to revoke the expired sharing or member. It is
+// done at most once every expirationRetryDelay, to not flood the job queue
+// when the member keeps trying to access the sharing.
+func (s *Sharing) retryExpiration(inst *instance.Instance, index int) {
+	cache := config.GetConfig().CacheStorage
+	key := fmt.Sprintf("sharing-expire:%s:%s:%d", inst.Domain, s.SID, index)
+	if _, ok := cache.Get(key); ok {
+		return
+	}
+	cache.Set(key, []byte("1"), expirationRetryDelay)
+	msg, err := job.NewMessage(&ExpireMsg{
+		SharingID:   s.SID,
+		MemberIndex: index,
+		ExpiresAt:   *s.expiresAt(index),
+	})
+	if err == nil {
+		_, err = job.System().PushJob(inst, &job.JobRequest{
+			WorkerType: "share-expire",
+			Message:    msg,
+		})
+	}
+	if err != nil {
+		inst.Logger().WithNamespace("sharing").
+			Warnf("Cannot retry the expiration of %s: %s", s.SID, err)
+	}
+}
+
+// SetExpiresAt changes the expiration date of the sharing, and schedules its
+// revocation. A nil date removes the expiration.
+func (s *Sharing) SetExpiresAt(inst *instance.Instance, at *time.Time) error {
+	if !s.Owner || !s.Active {
+		return ErrInvalidSharing
+	}
+	if at != nil && !at.After(time.Now()) {
+		return ErrInvalidExpiration
+	}
+	s.ExpiresAt = at
+	s.UpdatedAt = time.Now()
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return err
+	}
+	return s.ScheduleExpiration(inst, 0)
+}
+
+// SetMemberExpiresAt changes the expiration date of the access for a member
+// of the sharing, and schedules its revocation. A nil date removes the
+// expiration.
+func (s *Sharing) SetMemberExpiresAt(inst *instance.Instance, index int, at *time.Time) error {
+	if !s.Owner || !s.Active {
+		return ErrInvalidSharing
+	}
+	if index <= 0 || index >= len(s.Members) || s.Members[index].Status == MemberStatusRevoked {
+		return ErrMemberNotFound
+	}
+	if at != nil && !at.After(time.Now()) {
+		return ErrInvalidExpiration
+	}
+	s.Members[index].ExpiresAt = at
+	s.UpdatedAt = time.Now()
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return err
+	}
+	return s.ScheduleExpiration(inst, index)
+}
+
+// ScheduleExpiration adds the @at triggers for the reminders and the
+// revocation of the sharing (index 0) or of a member. The triggers for a
+// previous date are not removed: the worker ignores them as the date in their
+// message is no longer the current one.
+func (s *Sharing) ScheduleExpiration(inst *instance.Instance, index int) error {
+	expiresAt := s.expiresAt(index)
+	if expiresAt == nil {
+		return nil
+	}
+	for _, at := range expirationSchedule(*expiresAt, time.Now()) {
+		err := addExpireTrigger(inst, at, &ExpireMsg{
+			SharingID:   s.SID,
+			MemberIndex: index,
+			ExpiresAt:   *expiresAt,
+			Reminder:    !at.Equal(*expiresAt),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addExpireTrigger(inst *instance.Instance, at time.Time, msg *ExpireMsg) error {
+	m, err := job.NewMessage(msg)
+	if err != nil {
+		return err
+	}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: "share-expire",
+		Arguments:  at.Format(time.RFC3339),
+	}, m)
+	if err != nil {
+		return err
+	}
+	return job.System().AddTrigger(t)
+}
+
+// Expire is called by the share-expire worker: it sends a reminder to the
+// owner, or revokes the sharing or the member when the expiration date has
+// been reached.
+func Expire(inst *instance.Instance, msg *ExpireMsg) error {
+	s, err := FindSharing(inst, msg.SharingID)
+	if err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	if !s.Owner || !s.Active || msg.MemberIndex < 0 || msg.MemberIndex >= len(s.Members) {
+		return nil
+	}
+	if msg.MemberIndex > 0 && s.Members[msg.MemberIndex].Status == MemberStatusRevoked {
+		return nil
+	}
+	// The expiration date may have been changed or removed since the trigger
+	// was added
+	expiresAt := s.expiresAt(msg.MemberIndex)
+	if expiresAt == nil || !expiresAt.Equal(msg.ExpiresAt) {
+		return nil
+	}
+
+	if msg.Reminder {
+		return s.notifyExpiration(inst, msg.MemberIndex, center.NotificationSharingExpiring)
+	}
+	if msg.MemberIndex == 0 {
+		err = s.Revoke(inst)
+	} else if err = s.RevokeRecipient(inst, msg.MemberIndex); err == nil {
+		s.NotifyRecipients(inst, nil)
+	}
+	if err != nil {
+		// The revocation is retried later by a new trigger, until it
+		// succeeds. In the meantime, the accesses are refused by
+		// CheckExpiration. The error is only returned, for the job system to
+		// retry the job, if the trigger cannot be added.
+		log := inst.Logger().WithNamespace("sharing")
+		at := time.Now().Add(expirationRetryDelay)
+		if errt := addExpireTrigger(inst, at, msg); errt != nil {
+			log.Warnf("Cannot schedule the expiration of %s again: %s", s.SID, errt)
+			return err
+		}
+		log.Infof("Cannot revoke the expired sharing %s, retry at %s: %s", s.SID, at, err)
+		return nil
+	}
+	return s.notifyExpiration(inst, msg.MemberIndex, center.NotificationSharingExpired)
+}
+
+func (s *Sharing) expiresAt(index int) *time.Time {
+	if index == 0 {
+		return s.ExpiresAt
+	}
+	return s.Members[index].ExpiresAt
+}
+
+// expirationSchedule returns the dates of the reminders that are still in the
+// future, followed by the expiration date.
+func expirationSchedule(expiresAt, now time.Time) []time.Time {
+	dates := make([]time.Time, 0, len(ExpirationReminders)+1)
+	for _, delay := range ExpirationReminders {
+		if at := expiresAt.Add(-delay); at.After(now) {
+			dates = append(dates, at)
+		}
+	}
+	return append(dates, expiresAt)
+}
+
+func (s *Sharing) notifyExpiration(inst *instance.Instance, index int, category string) error {
+	expiresAt := s.expiresAt(index).UTC().Format("2006-01-02 15:04 MST")
+	var title, message string
+	if category == center.NotificationSharingExpiring {
+		title = inst.Translate("Notifications Sharing Expiring Subject", s.Description)
+		if index == 0 {
+			message = inst.Translate("Notifications Sharing Expiring Sharing", s.Description, expiresAt)
+		} else {
+			message = inst.Translate("Notifications Sharing Expiring Member",
+				s.Members[index].PrimaryName(), s.Description, expiresAt)
+		}
+	} else {
+		title = inst.Translate("Notifications Sharing Expired Subject", s.Description)
+		if index == 0 {
+			message = inst.Translate("Notifications Sharing Expired Sharing", s.Description)
+		} else {
+			message = inst.Translate("Notifications Sharing Expired Member",
+				s.Members[index].PrimaryName(), s.Description)
+		}
+	}
+
+	sharingsLink := inst.SubDomain(consts.DriveSlug)
+	sharingsLink.Fragment = "/sharings"
+	n := &notification.Notification{
+		Title:   title,
+		Message: message,
+		Slug:    consts.DriveSlug,
+		Data: map[string]interface{}{
+			// For email notification
+			"SharingName":  s.Description,
+			"Message":      message,
+			"SharingsLink": sharingsLink.String(),
+
+			// For mobile push notification
+			"appName":      "",
+			"redirectLink": consts.DriveSlug + "/#/sharings",
+		},
+	}
+	return center.PushStack(inst.DomainName(), category, n)
+}
@@ -0,0 +1,66 @@
+package sharing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpirationSchedule(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	expiresAt := now.Add(30 * 24 * time.Hour)
+	dates := expirationSchedule(expiresAt, now)
+	if assert.Len(t, dates, 3) {
+		assert.Equal(t, expiresAt.Add(-7*24*time.Hour), dates[0])
+		assert.Equal(t, expiresAt.Add(-24*time.Hour), dates[1])
+		assert.Equal(t, expiresAt, dates[2])
+	}
+
+	// The reminders in the past are skipped
+	expiresAt = now.Add(3 * 24 * time.Hour)
+	dates = expirationSchedule(expiresAt, now)
+	if assert.Len(t, dates, 2) {
+		assert.Equal(t, expiresAt.Add(-24*time.Hour), dates[0])
+		assert.Equal(t, expiresAt, dates[1])
+	}
+
+	expiresAt = now.Add(time.Hour)
+	dates = expirationSchedule(expiresAt, now)
+	assert.Equal(t, []time.Time{expiresAt}, dates)
+}
+
+func TestExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	s := Sharing{Members: []Member{{}, {ExpiresAt: &past}, {ExpiresAt: &future}}}
+	assert.False(t, s.Expired())
+	assert.False(t, s.Members[0].Expired())
+	assert.True(t, s.Members[1].Expired())
+	assert.False(t, s.Members[2].Expired())
+
+	s.ExpiresAt = &past
+	assert.True(t, s.Expired())
+}
+
+func TestCheckExpirationNotExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	s := Sharing{
+		Owner:     true,
+		Active:    true,
+		ExpiresAt: &future,
+		Members:   []Member{{}, {ExpiresAt: &future}, {}},
+	}
+	assert.NoError(t, s.CheckExpiration(nil, &s.Members[1]))
+	assert.NoError(t, s.CheckExpiration(nil, &s.Members[2]))
+	assert.NoError(t, s.CheckExpiration(nil, nil))
+
+	// The expiration is only enforced by the owner
+	s.Owner = false
+	s.ExpiresAt = &past
+	assert.NoError(t, s.CheckExpiration(nil, &s.Members[1]))
+}
@@ -582,6 +582,11 @@ func (s *Sharing) ApplyBulkFiles(inst *instance.Instance, docs DocsList) error {
 			continue
 		}
 		if _, ok := target["_deleted"]; ok {
+			if dir == nil && file == nil && !s.Owner {
+				if err = s.removePlaceholder(inst, id); err != nil {
+					errm = multierror.Append(errm, err)
+				}
+			}
 			if ref == nil || infos.Removed {
 				continue
 			}
@@ -179,6 +179,127 @@ func TestFiles(t *testing.T) {
 		}
 	})
 
+	t.Run("ExcludedDirs", func(t *testing.T) {
+		s := Sharing{
+			SID: uuidv7(),
+			Rules: []Rule{
+				{
+					Title:   "Test excluded dirs",
+					DocType: consts.Files,
+					Values:  []string{uuidv7()},
+				},
+			},
+		}
+		root, err := s.CreateDirForSharing(inst, &s.Rules[0], "")
+		require.NoError(t, err)
+
+		idFoo, idBar := uuidv7(), uuidv7()
+		require.NoError(t, s.CreateDir(inst, map[string]interface{}{
+			"_id":  idFoo,
+			"_rev": "1-6b501ca58928b02b90c430fd730e8b17",
+			"_revisions": map[string]interface{}{
+				"start": float64(1),
+				"ids":   []interface{}{"6b501ca58928b02b90c430fd730e8b17"},
+			},
+			"name": "Foo",
+		}, resolveResolution))
+		require.NoError(t, s.CreateDir(inst, map[string]interface{}{
+			"_id":  idBar,
+			"_rev": "1-2ee767305024673cfb3f5af037cd2729",
+			"_revisions": map[string]interface{}{
+				"start": float64(1),
+				"ids":   []interface{}{"2ee767305024673cfb3f5af037cd2729"},
+			},
+			"dir_id": idFoo,
+			"name":   "Bar",
+		}, resolveResolution))
+
+		assert.False(t, s.isExcluded(inst, idBar))
+		s.ExcludedDirs = []string{idFoo}
+		assert.True(t, s.isExcluded(inst, idFoo))
+		assert.True(t, s.isExcluded(inst, idBar))
+		assert.False(t, s.isExcluded(inst, root.DocID))
+
+		target := &FileDocWithRevisions{
+			FileDoc: &vfs.FileDoc{
+				DocID:    uuidv7(),
+				DocName:  "report.pdf",
+				DirID:    idBar,
+				ByteSize: 42,
+				Mime:     "application/pdf",
+			},
+		}
+		require.NoError(t, s.savePlaceholder(inst, target.FileDoc))
+		target.DocName = "report-v2.pdf"
+		require.NoError(t, s.savePlaceholder(inst, target.FileDoc))
+		placeholders, _, err := s.ListPlaceholders(inst, idBar, 10, "")
+		require.NoError(t, err)
+		if assert.Len(t, placeholders, 1) {
+			assert.Equal(t, target.DocID, placeholders[0].DocID)
+			assert.Equal(t, "report-v2.pdf", placeholders[0].Name)
+			assert.EqualValues(t, 42, placeholders[0].Size)
+		}
+
+		require.NoError(t, s.removePlaceholder(inst, target.DocID))
+		placeholders, _, err = s.ListPlaceholders(inst, idBar, 10, "")
+		require.NoError(t, err)
+		assert.Len(t, placeholders, 0)
+
+		// The placeholders are paginated
+		for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+			doc := &vfs.FileDoc{DocID: uuidv7(), DocName: name, DirID: idFoo}
+			require.NoError(t, s.savePlaceholder(inst, doc))
+		}
+		placeholders, bookmark, err := s.ListPlaceholders(inst, idFoo, 2, "")
+		require.NoError(t, err)
+		assert.Len(t, placeholders, 2)
+		assert.NotEmpty(t, bookmark)
+		placeholders, _, err = s.ListPlaceholders(inst, idFoo, 2, bookmark)
+		require.NoError(t, err)
+		assert.Len(t, placeholders, 1)
+	})
+
+	t.Run("ExcludeExistingFiles", func(t *testing.T) {
+		s := Sharing{
+			SID: uuidv7(),
+			Rules: []Rule{
+				{
+					Title:   "Test exclude existing files",
+					DocType: consts.Files,
+					Values:  []string{uuidv7()},
+				},
+			},
+		}
+		root, err := s.CreateDirForSharing(inst, &s.Rules[0], "")
+		require.NoError(t, err)
+
+		fs := inst.VFS()
+		dir := createTree(t, fs, H{"Excluded/": H{"Sub/": H{"report.pdf": nil}}}, root.DocID)
+		sub, err := fs.DirByPath(dir.Fullpath + "/Sub")
+		require.NoError(t, err)
+		file, err := fs.FileByPath(sub.Fullpath + "/report.pdf")
+		require.NoError(t, err)
+		ref := SharedRef{
+			SID:       consts.Files + "/" + file.DocID,
+			Revisions: &RevsTree{Rev: file.DocRev},
+			Infos:     map[string]SharedInfo{s.SID: {Rule: 0, Binary: true}},
+		}
+		require.NoError(t, couchdb.CreateNamedDocWithDB(inst, &ref))
+
+		require.NoError(t, s.excludeDir(inst, dir))
+
+		_, err = fs.FileByID(file.DocID)
+		assert.Error(t, err)
+		err = couchdb.GetDoc(inst, consts.Shared, ref.SID, &SharedRef{})
+		assert.True(t, couchdb.IsNotFoundError(err))
+		placeholders, _, err := s.ListPlaceholders(inst, sub.DocID, 10, "")
+		require.NoError(t, err)
+		if assert.Len(t, placeholders, 1) {
+			assert.Equal(t, file.DocID, placeholders[0].DocID)
+			assert.Equal(t, "report.pdf", placeholders[0].Name)
+		}
+	})
+
 	t.Run("UpdateDir", func(t *testing.T) {
 		s := Sharing{
 			SID: uuidv7(),
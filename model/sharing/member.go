@@ -68,6 +68,14 @@ type Member struct {
 	ReadOnly     bool   `json:"read_only,omitempty"`
 	OnlyInGroups bool   `json:"only_in_groups,omitempty"` // False if the member has been added as an io.cozy.contacts
 	Groups       []int  `json:"groups,omitempty"`         // The indexes of the groups a member is part of
+
+	// ExpiresAt is the date when the access of this member is automatically
+	// revoked
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Role is the permission level of this member inside the sharing (see
+	// EffectiveRole for members without an explicit role)
+	Role string `json:"role,omitempty"`
 }
 
 // PrimaryName returns the main name of this member
@@ -515,6 +523,8 @@ func (s *Sharing) UpdateRecipients(inst *instance.Instance, params PutRecipients
 		s.Members[i].PublicName = m.PublicName
 		s.Members[i].Status = m.Status
 		s.Members[i].ReadOnly = m.ReadOnly
+		s.Members[i].ExpiresAt = m.ExpiresAt
+		s.Members[i].Role = m.Role
 	}
 	s.Groups = params.Groups
 	return couchdb.UpdateDoc(inst, s)
@@ -1115,6 +1125,8 @@ func (s *Sharing) NotifyRecipients(inst *instance.Instance, except *Member) {
 			PublicName: m.PublicName,
 			Email:      m.Email,
 			ReadOnly:   m.ReadOnly,
+			ExpiresAt:  m.ExpiresAt,
+			Role:       m.Role,
 			// Instance and name are private
 		}
 	}
@@ -145,7 +145,7 @@ func (o *FileOpener) GetSharecode(memberIndex int, readOnly bool) (string, error
 		if member == nil {
 			return "", ErrMemberNotFound
 		}
-		if member.ReadOnly {
+		if !member.CanEdit() {
 			readOnly = true
 		} else {
 			readOnly = s.ReadOnlyRules()
@@ -155,7 +155,7 @@ func (o *FileOpener) GetSharecode(memberIndex int, readOnly bool) (string, error
 		if err != nil {
 			return "", err
 		}
-		if member.ReadOnly {
+		if !member.CanEdit() {
 			readOnly = true
 		} else {
 			readOnly = s.ReadOnlyRules()
@@ -279,7 +279,7 @@ func (o *FileOpener) PrepareRequestForSharedFile() (*PreparedRequest, error) {
 			for i, c := range s.Credentials {
 				if c.InboundClientID == o.ClientID {
 					prepared.MemberIndex = i + 1
-					prepared.ReadOnly = s.Members[i+1].ReadOnly
+					prepared.ReadOnly = !s.Members[i+1].CanEdit()
 				}
 			}
 		}
@@ -0,0 +1,332 @@
+package sharing
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/client/request"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// Placeholder is the metadata of a file of a sharing that is in an excluded
+// directory on the cozy of a recipient: the file is not created in the VFS,
+// and its content can be fetched from the sharer when it is needed.
+type Placeholder struct {
+	DocID     string    `json:"_id,omitempty"`
+	DocRev    string    `json:"_rev,omitempty"`
+	SharingID string    `json:"sharing_id"`
+	Name      string    `json:"name"`
+	DirID     string    `json:"dir_id"`
+	Size      int64     `json:"size,string"`
+	MD5Sum    []byte    `json:"md5sum,omitempty"`
+	Mime      string    `json:"mime,omitempty"`
+	Class     string    `json:"class,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ID returns the placeholder qualified identifier
+func (p *Placeholder) ID() string { return p.DocID }
+
+// Rev returns the placeholder revision
+func (p *Placeholder) Rev() string { return p.DocRev }
+
+// DocType returns the placeholder document type
+func (p *Placeholder) DocType() string { return consts.SharingsPlaceholders }
+
+// Clone implements couchdb.Doc
+func (p *Placeholder) Clone() couchdb.Doc {
+	cloned := *p
+	cloned.MD5Sum = make([]byte, len(p.MD5Sum))
+	copy(cloned.MD5Sum, p.MD5Sum)
+	return &cloned
+}
+
+// SetID changes the placeholder qualified identifier
+func (p *Placeholder) SetID(id string) { p.DocID = id }
+
+// SetRev changes the placeholder revision
+func (p *Placeholder) SetRev(rev string) { p.DocRev = rev }
+
+// SetExcludedDirs changes the list of the directories of the sharing that are
+// not synchronized on the cozy of a recipient. The files in the newly excluded
+// directories are replaced by placeholders, and the new files and the new
+// versions of the files in these directories are saved as placeholders too.
+// When a directory is no longer excluded, the sharer is asked to send the
+// files again.
+func (s *Sharing) SetExcludedDirs(inst *instance.Instance, dirIDs []string) error {
+	if s.Owner || !s.Active || s.FirstFilesRule() == nil {
+		return ErrInvalidSharing
+	}
+	root, err := s.GetSharingDir(inst)
+	if err != nil {
+		return err
+	}
+	fs := inst.VFS()
+	var dirs []*vfs.DirDoc
+	for _, id := range dirIDs {
+		dir, err := fs.DirByID(id)
+		if err != nil || !strings.HasPrefix(dir.Fullpath, root.Fullpath+"/") {
+			return ErrInvalidExcludedDir
+		}
+		dirs = append(dirs, dir)
+	}
+	if len(dirIDs) == 0 {
+		dirIDs = nil
+	}
+	previous := s.ExcludedDirs
+	s.ExcludedDirs = dirIDs
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return err
+	}
+
+	var errm error
+	for _, dir := range dirs {
+		if !utils.IsInArray(dir.DocID, previous) {
+			if err := s.excludeDir(inst, dir); err != nil {
+				errm = multierror.Append(errm, err)
+			}
+		}
+	}
+	for _, id := range previous {
+		if !utils.IsInArray(id, dirIDs) {
+			if err := s.AskResync(inst); err != nil {
+				inst.Logger().WithNamespace("sharing").
+					Warnf("Cannot ask a resync for %s: %s", s.SID, err)
+			}
+			break
+		}
+	}
+	return errm
+}
+
+// excludeDir replaces the files of an excluded directory, and of its
+// sub-directories, by placeholders.
+func (s *Sharing) excludeDir(inst *instance.Instance, dir *vfs.DirDoc) error {
+	var errm error
+	iter := inst.VFS().DirIterator(dir, nil)
+	for {
+		d, f, err := iter.Next()
+		if errors.Is(err, vfs.ErrIteratorDone) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			err = s.excludeDir(inst, d)
+		} else {
+			err = s.excludeFile(inst, f)
+		}
+		if err != nil {
+			errm = multierror.Append(errm, err)
+		}
+	}
+	return errm
+}
+
+// excludeFile replaces a file of an excluded directory by a placeholder.
+func (s *Sharing) excludeFile(inst *instance.Instance, file *vfs.FileDoc) error {
+	sid := consts.Files + "/" + file.DocID
+	mu := config.Lock().ReadWrite(inst, "shared/"+sid)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	var ref SharedRef
+	if err := couchdb.GetDoc(inst, consts.Shared, sid, &ref); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	if !s.canDropFile(&ref) {
+		return nil
+	}
+	if err := s.savePlaceholder(inst, file); err != nil {
+		return err
+	}
+	return s.dropFile(inst, file, &ref)
+}
+
+// canDropFile returns true if the file can be replaced by a placeholder: it
+// must be only in this sharing, as the other sharings would see its deletion.
+func (s *Sharing) canDropFile(ref *SharedRef) bool {
+	infos, ok := ref.Infos[s.SID]
+	return ok && !infos.Removed && len(ref.Infos) == 1
+}
+
+// dropFile destroys a file that has been replaced by a placeholder. The
+// io.cozy.shared is deleted first, so that the deletion of the file is not
+// sent to the other members.
+func (s *Sharing) dropFile(inst *instance.Instance, file *vfs.FileDoc, ref *SharedRef) error {
+	if err := couchdb.DeleteDoc(inst, ref); err != nil && !couchdb.IsNotFoundError(err) {
+		return err
+	}
+	return inst.VFS().DestroyFile(file)
+}
+
+// isExcluded returns true if the given directory is one of the excluded
+// directories, or is inside one of them.
+func (s *Sharing) isExcluded(inst *instance.Instance, dirID string) bool {
+	if s.Owner || len(s.ExcludedDirs) == 0 || dirID == "" {
+		return false
+	}
+	fs := inst.VFS()
+	parent, err := fs.DirByID(dirID)
+	if err != nil {
+		return false
+	}
+	for _, id := range s.ExcludedDirs {
+		if id == dirID {
+			return true
+		}
+		excluded, err := fs.DirByID(id)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(parent.Fullpath, excluded.Fullpath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// savePlaceholder creates or updates the placeholder for a file of an
+// excluded directory.
+func (s *Sharing) savePlaceholder(inst *instance.Instance, doc *vfs.FileDoc) error {
+	p := &Placeholder{}
+	err := couchdb.GetDoc(inst, consts.SharingsPlaceholders, doc.DocID, p)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return err
+	}
+	p.SharingID = s.SID
+	p.Name = doc.DocName
+	p.DirID = doc.DirID
+	p.Size = doc.ByteSize
+	p.MD5Sum = doc.MD5Sum
+	p.Mime = doc.Mime
+	p.Class = doc.Class
+	p.CreatedAt = doc.CreatedAt
+	p.UpdatedAt = doc.UpdatedAt
+	if p.DocRev == "" {
+		p.DocID = doc.DocID
+		return couchdb.CreateNamedDocWithDB(inst, p)
+	}
+	return couchdb.UpdateDoc(inst, p)
+}
+
+// removePlaceholder deletes the placeholder for a file, if there is one.
+func (s *Sharing) removePlaceholder(inst *instance.Instance, fileID string) error {
+	p := &Placeholder{}
+	err := couchdb.GetDoc(inst, consts.SharingsPlaceholders, fileID, p)
+	if err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	return couchdb.DeleteDoc(inst, p)
+}
+
+// ListPlaceholders returns the placeholders of the files in the given
+// directory, with a bookmark for the next page.
+func (s *Sharing) ListPlaceholders(inst *instance.Instance, dirID string, limit int, bookmark string) ([]*Placeholder, string, error) {
+	var placeholders []*Placeholder
+	req := &couchdb.FindRequest{
+		UseIndex: "by-sharing-and-dir-id",
+		Selector: mango.And(
+			mango.Equal("sharing_id", s.SID),
+			mango.Equal("dir_id", dirID),
+		),
+		Bookmark: bookmark,
+		Limit:    limit,
+	}
+	res, err := couchdb.FindDocsRaw(inst, consts.SharingsPlaceholders, req, &placeholders)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return placeholders, res.Bookmark, nil
+}
+
+// FindPlaceholder returns the placeholder of a file of this sharing.
+func (s *Sharing) FindPlaceholder(inst *instance.Instance, fileID string) (*Placeholder, error) {
+	p := &Placeholder{}
+	if err := couchdb.GetDoc(inst, consts.SharingsPlaceholders, fileID, p); err != nil {
+		return nil, err
+	}
+	if p.SharingID != s.SID {
+		return nil, ErrSafety
+	}
+	return p, nil
+}
+
+// FetchPlaceholderContent asks the sharer the content of a file that is only
+// a placeholder on the cozy of this recipient. The caller must close the body
+// of the response.
+func (s *Sharing) FetchPlaceholderContent(inst *instance.Instance, p *Placeholder) (*http.Response, error) {
+	if s.Owner || len(s.Credentials) == 0 || s.Credentials[0].AccessToken == nil {
+		return nil, ErrInvalidSharing
+	}
+	u, err := url.Parse(s.Members[0].Instance)
+	if err != nil {
+		return nil, ErrInvalidSharing
+	}
+	creds := &s.Credentials[0]
+	opts := &request.Options{
+		Method: http.MethodGet,
+		Scheme: u.Scheme,
+		Domain: u.Host,
+		Path:   "/sharings/" + s.SID + "/io.cozy.files/" + p.DocID + "/content",
+		Headers: request.Headers{
+			"Authorization": "Bearer " + creds.AccessToken.AccessToken,
+		},
+		ParseError: ParseRequestError,
+	}
+	res, err := request.Req(opts)
+	if res != nil && res.StatusCode/100 == 4 {
+		res, err = RefreshToken(inst, err, s, &s.Members[0], creds, opts, nil)
+	}
+	if err != nil {
+		if res != nil && res.StatusCode/100 == 5 {
+			return nil, ErrInternalServerError
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetFileForContent returns the file that a member has asked for its content
+// (with a XORed ID), after checking that it is part of the sharing.
+func (s *Sharing) GetFileForContent(inst *instance.Instance, m *Member, xoredID string) (*vfs.FileDoc, error) {
+	if !m.ReceivesDocuments() {
+		return nil, ErrSafety
+	}
+	creds := s.FindCredentials(m)
+	if creds == nil {
+		return nil, ErrInvalidSharing
+	}
+	fileID := XorID(xoredID, creds.XorKey)
+	ref := &SharedRef{}
+	if err := couchdb.GetDoc(inst, consts.Shared, consts.Files+"/"+fileID, ref); err != nil {
+		return nil, err
+	}
+	if info, ok := ref.Infos[s.SID]; !ok || info.Removed {
+		return nil, ErrSafety
+	}
+	return inst.VFS().FileByID(fileID)
+}
@@ -169,6 +169,13 @@ func (s *Sharing) ReplicateTo(inst *instance.Instance, m *Member, initial bool)
 	if m.Instance == "" {
 		return false, ErrInvalidURL
 	}
+	// A member with the uploader role can only drop documents in the sharing
+	if s.Owner && !m.ReceivesDocuments() {
+		return false, nil
+	}
+	if err := s.CheckExpiration(inst, m); err != nil {
+		return false, nil
+	}
 	creds := s.FindCredentials(m)
 	if creds == nil {
 		return false, ErrInvalidSharing
@@ -732,6 +739,24 @@ func (s *Sharing) ApplyBulkDocs(inst *instance.Instance, payload DocsByDoctype)
 	return couchdb.BulkUpdateDocs(inst, consts.Shared, refsToUpdate, olds)
 }
 
+// ApplyBulkDocsFromMember applies the changes sent by a member of the
+// sharing: the changes that their role doesn't allow are ignored, and the
+// operations on the files are written in the activity journal.
+func (s *Sharing) ApplyBulkDocsFromMember(inst *instance.Instance, m *Member, payload DocsByDoctype) error {
+	payload, err := s.FilterDocsForMember(inst, m, payload)
+	if err != nil {
+		return err
+	}
+	activities := s.bulkFilesActivities(inst, payload[consts.Files])
+	if err := s.ApplyBulkDocs(inst, payload); err != nil {
+		return err
+	}
+	for _, a := range activities {
+		s.recordActivity(inst, m, a)
+	}
+	return nil
+}
+
 // partitionDocsPayload returns two slices: the first with documents that are new,
 // the second with documents that already exist on this cozy and must be updated.
 func partitionDocsPayload(inst *instance.Instance, doctype string, docs DocsList) (news DocsList, existings DocsList, err error) {
@@ -7,6 +7,7 @@ import (
 	"github.com/cozy/cozy-stack/client/request"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	multierror "github.com/hashicorp/go-multierror"
@@ -44,7 +45,7 @@ func AskReupload(inst *instance.Instance) error {
 				if m.Status != MemberStatusReady {
 					continue
 				}
-				if err := askReuploadTo(inst, s, &s.Members[i], &s.Credentials[i-1]); err != nil {
+				if err := askReuploadTo(inst, s, &s.Members[i], &s.Credentials[i-1], false); err != nil {
 					errm = multierror.Append(errm, err)
 				}
 			}
@@ -53,7 +54,7 @@ func AskReupload(inst *instance.Instance) error {
 		}
 
 		if len(s.Credentials) > 0 {
-			if err := askReuploadTo(inst, s, &s.Members[0], &s.Credentials[0]); err != nil {
+			if err := askReuploadTo(inst, s, &s.Members[0], &s.Credentials[0], false); err != nil {
 				errm = multierror.Append(errm, err)
 			}
 		}
@@ -61,7 +62,7 @@ func AskReupload(inst *instance.Instance) error {
 	return errm
 }
 
-func askReuploadTo(inst *instance.Instance, s *Sharing, m *Member, c *Credentials) error {
+func askReuploadTo(inst *instance.Instance, s *Sharing, m *Member, c *Credentials, resync bool) error {
 	if c == nil || c.AccessToken == nil {
 		return nil
 	}
@@ -79,6 +80,9 @@ func askReuploadTo(inst *instance.Instance, s *Sharing, m *Member, c *Credential
 		},
 		ParseError: ParseRequestError,
 	}
+	if resync {
+		opts.Queries = url.Values{"resync": {"true"}}
+	}
 	res, err := request.Req(opts)
 	if res != nil && res.StatusCode/100 == 4 {
 		res, err = RefreshToken(inst, err, s, m, c, opts, nil)
@@ -97,3 +101,29 @@ func PushUploadJob(s *Sharing, inst *instance.Instance) {
 		s.pushJob(inst, "share-upload")
 	}
 }
+
+// AskResync is used by a recipient to ask the sharer to send again all the
+// files of the sharing, for example when a directory is no longer excluded
+// and its placeholders must be replaced by the files.
+func (s *Sharing) AskResync(inst *instance.Instance) error {
+	if s.Owner || !s.Active || len(s.Credentials) == 0 {
+		return ErrInvalidSharing
+	}
+	return askReuploadTo(inst, s, &s.Members[0], &s.Credentials[0], true)
+}
+
+// ResyncFiles is called on the sharer when a recipient has asked to receive
+// again all the files: the upload restarts from the beginning of the changes
+// feed for this member. The files that the recipient already has are just
+// seen as echoes.
+func (s *Sharing) ResyncFiles(inst *instance.Instance, m *Member) error {
+	if !s.Owner || s.memberIndex(m) <= 0 {
+		return ErrInvalidSharing
+	}
+	mu := config.Lock().ReadWrite(inst, "sharings/"+s.SID+"/upload")
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+	return s.clearLastSequenceNumber(inst, m, "upload")
+}
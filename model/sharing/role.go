@@ -0,0 +1,234 @@
+package sharing
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+const (
+	// RoleViewer is for a member that can only read the shared documents. It
+	// is the same as the read-only flag.
+	RoleViewer = "viewer"
+	// RoleCommenter is for a member that can read the shared documents and
+	// annotate them (tags, metadata), but not rename, move, edit or delete
+	// them.
+	RoleCommenter = "commenter"
+	// RoleUploader is for a member that can only add new documents, like a
+	// drop-box: they don't receive the documents of the other members.
+	RoleUploader = "uploader"
+	// RoleEditor is for a member that can create, edit and delete the shared
+	// documents. It is the default for a member without the read-only flag.
+	RoleEditor = "editor"
+	// RoleCoOwner is for an editor that can also manage the members of the
+	// sharing, even if the sharing is not open.
+	RoleCoOwner = "co-owner"
+)
+
+// IsValidRole returns true if the given string is a known role.
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleViewer, RoleCommenter, RoleUploader, RoleEditor, RoleCoOwner:
+		return true
+	}
+	return false
+}
+
+// EffectiveRole returns the role of the member. The read-only flag has the
+// priority, and a legacy member without a role is an editor.
+func (m *Member) EffectiveRole() string {
+	if m.ReadOnly {
+		return RoleViewer
+	}
+	if m.Role == "" {
+		return RoleEditor
+	}
+	return m.Role
+}
+
+// CanCreate returns true if the member can add new documents to the sharing.
+func (m *Member) CanCreate() bool {
+	switch m.EffectiveRole() {
+	case RoleUploader, RoleEditor, RoleCoOwner:
+		return true
+	}
+	return false
+}
+
+// CanEdit returns true if the member can modify and delete the documents of
+// the sharing.
+func (m *Member) CanEdit() bool {
+	switch m.EffectiveRole() {
+	case RoleEditor, RoleCoOwner:
+		return true
+	}
+	return false
+}
+
+// CanAnnotate returns true if the member can change the tags and metadata of
+// the documents of the sharing.
+func (m *Member) CanAnnotate() bool {
+	return m.EffectiveRole() == RoleCommenter || m.CanEdit()
+}
+
+// ReceivesDocuments returns false for a member that only drops documents in
+// the sharing.
+func (m *Member) ReceivesDocuments() bool {
+	return m.EffectiveRole() != RoleUploader
+}
+
+// CanManageMembers returns true if the member can add members to the
+// sharing on the behalf of the owner.
+func (m *Member) CanManageMembers() bool {
+	return m.Status == MemberStatusOwner || m.EffectiveRole() == RoleCoOwner
+}
+
+// SetMemberRole changes the role of a member. Going to or from the viewer role
+// adds or removes the read-only flag, with the exchange of credentials that
+// it implies.
+func (s *Sharing) SetMemberRole(inst *instance.Instance, index int, role string) error {
+	if !s.Owner || !s.Active {
+		return ErrInvalidSharing
+	}
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	if index <= 0 || index >= len(s.Members) {
+		return ErrMemberNotFound
+	}
+	m := &s.Members[index]
+	if m.Status == MemberStatusRevoked {
+		return ErrMemberNotFound
+	}
+	m.Role = role
+	if m.Status == MemberStatusReady {
+		if role == RoleViewer && !m.ReadOnly {
+			return s.AddReadOnlyFlag(inst, index)
+		}
+		if role != RoleViewer && m.ReadOnly {
+			return s.RemoveReadOnlyFlag(inst, index)
+		}
+	} else {
+		// The credentials are exchanged when the recipient accepts the
+		// sharing, and they will use the read-only flag at this time
+		m.ReadOnly = role == RoleViewer
+	}
+	return couchdb.UpdateDoc(inst, s)
+}
+
+// FilterDocsForMember removes from the payload sent by a member the documents
+// that their role doesn't allow to create, update or delete. The files are
+// checked for their content in SyncFile.
+func (s *Sharing) FilterDocsForMember(inst *instance.Instance, m *Member, payload DocsByDoctype) (DocsByDoctype, error) {
+	if m.CanEdit() {
+		return payload, nil
+	}
+	filtered := make(DocsByDoctype, len(payload))
+	for doctype, docs := range payload {
+		var allowed DocsList
+		if doctype == consts.Files {
+			var err error
+			allowed, err = s.filterFilesForMember(inst, m, docs)
+			if err != nil {
+				return nil, err
+			}
+		} else if m.CanCreate() {
+			news, _, err := partitionDocsPayload(inst, doctype, docs)
+			if err != nil {
+				// The database does not exist yet: all the documents are new
+				news = docs
+			}
+			for _, doc := range news {
+				if _, ok := doc["_deleted"]; !ok {
+					allowed = append(allowed, doc)
+				}
+			}
+		}
+		if len(allowed) < len(docs) {
+			inst.Logger().WithNamespace("replicator").
+				Infof("%d %s refused for the role %s on sharing %s",
+					len(docs)-len(allowed), doctype, m.EffectiveRole(), s.SID)
+		}
+		if len(allowed) > 0 {
+			filtered[doctype] = allowed
+		}
+	}
+	return filtered, nil
+}
+
+func (s *Sharing) filterFilesForMember(inst *instance.Instance, m *Member, docs DocsList) (DocsList, error) {
+	var allowed DocsList
+	fs := inst.VFS()
+	for _, doc := range docs {
+		id, _ := doc["_id"].(string)
+		dir, file, err := fs.DirOrFileByID(id)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		_, deleted := doc["_deleted"]
+		switch {
+		case dir == nil && file == nil:
+			if m.CanCreate() && !deleted {
+				allowed = append(allowed, doc)
+			}
+		case deleted || !m.CanAnnotate():
+			// Refused
+		case dir != nil:
+			name, _ := doc["name"].(string)
+			dirID, _ := doc["dir_id"].(string)
+			if s.samePlacement(id, name, dirID, dir.DocName, dir.DirID) {
+				allowed = append(allowed, doc)
+			}
+		default:
+			// Let SyncFile check the changes on the file
+			allowed = append(allowed, doc)
+		}
+	}
+	return allowed, nil
+}
+
+// canSyncFile returns true if the role of the member allows the changes made
+// on a file. The current file is nil for a new file.
+func (s *Sharing) canSyncFile(m *Member, target *FileDocWithRevisions, current *vfs.FileDoc) bool {
+	if current == nil {
+		return m.CanCreate()
+	}
+	if m.CanEdit() {
+		return true
+	}
+	if !m.CanAnnotate() || target.Trashed != current.Trashed {
+		return false
+	}
+	if !bytes.Equal(target.MD5Sum, current.MD5Sum) {
+		return false
+	}
+	return s.samePlacement(target.DocID, target.DocName, target.DirID, current.DocName, current.DirID)
+}
+
+// samePlacement returns true if a document sent by a member keeps the name and
+// the parent directory of the local document. The dir_id is removed from the
+// documents at the root of a rule before being sent.
+func (s *Sharing) samePlacement(id, name, dirID, currentName, currentDirID string) bool {
+	if name != currentName {
+		return false
+	}
+	if dirID != "" {
+		return dirID == currentDirID
+	}
+	for _, rule := range s.Rules {
+		if rule.Selector == couchdb.SelectorReferencedBy {
+			return true
+		}
+		for _, v := range rule.Values {
+			if v == id || v == currentDirID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package sharing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberRoles(t *testing.T) {
+	legacy := Member{}
+	assert.Equal(t, RoleEditor, legacy.EffectiveRole())
+	assert.True(t, legacy.CanEdit())
+
+	// A viewer stays a viewer, even if the read-only flag has not been set
+	viewer := Member{Role: RoleViewer}
+	assert.Equal(t, RoleViewer, viewer.EffectiveRole())
+	assert.False(t, viewer.CanCreate())
+	assert.False(t, viewer.CanAnnotate())
+
+	readOnly := Member{ReadOnly: true, Role: RoleCoOwner}
+	assert.Equal(t, RoleViewer, readOnly.EffectiveRole())
+	assert.False(t, readOnly.CanCreate())
+	assert.False(t, readOnly.CanAnnotate())
+	assert.True(t, readOnly.ReceivesDocuments())
+
+	commenter := Member{Role: RoleCommenter}
+	assert.False(t, commenter.CanCreate())
+	assert.False(t, commenter.CanEdit())
+	assert.True(t, commenter.CanAnnotate())
+
+	uploader := Member{Role: RoleUploader}
+	assert.True(t, uploader.CanCreate())
+	assert.False(t, uploader.CanEdit())
+	assert.False(t, uploader.ReceivesDocuments())
+
+	coOwner := Member{Role: RoleCoOwner}
+	assert.True(t, coOwner.CanEdit())
+	assert.True(t, coOwner.CanManageMembers())
+	assert.False(t, legacy.CanManageMembers())
+	assert.True(t, (&Member{Status: MemberStatusOwner}).CanManageMembers())
+
+	assert.True(t, IsValidRole(RoleUploader))
+	assert.False(t, IsValidRole("admin"))
+}
+
+func TestSamePlacement(t *testing.T) {
+	s := Sharing{Rules: []Rule{{DocType: "io.cozy.files", Values: []string{"root"}}}}
+	assert.True(t, s.samePlacement("a", "foo", "dir", "foo", "dir"))
+	assert.False(t, s.samePlacement("a", "bar", "dir", "foo", "dir"))
+	assert.False(t, s.samePlacement("a", "foo", "other", "foo", "dir"))
+	// The dir_id is removed for the documents at the root of the rule
+	assert.True(t, s.samePlacement("a", "foo", "", "foo", "root"))
+	assert.True(t, s.samePlacement("root", "foo", "", "foo", "parent"))
+	assert.False(t, s.samePlacement("a", "foo", "", "foo", "dir"))
+}
@@ -426,6 +426,12 @@ func UpdateShared(inst *instance.Instance, msg TrackMessage, evt TrackEvent) err
 		}
 	}
 
+	// The changes received from the other members have been filtered above,
+	// so it is a change made on this cozy
+	if msg.DocType == consts.Files && !removed {
+		recordLocalActivity(inst, msg.SharingID, evt)
+	}
+
 	// For a directory, we have to update the Removed flag for the files inside
 	// it, as we won't have any events for them.
 	if needToUpdateFiles {
@@ -65,6 +65,13 @@ type Sharing struct {
 	ShortcutID  string    `json:"shortcut_id,omitempty"`
 	MovedFrom   string    `json:"moved_from,omitempty"`
 
+	// ExpiresAt is the date when the sharing is automatically revoked
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// ExcludedDirs are the directories on the cozy of a recipient where the
+	// files are kept as placeholders, without their content
+	ExcludedDirs []string `json:"excluded_dirs,omitempty"`
+
 	Rules []Rule `json:"rules"`
 
 	// Members[0] is the owner, Members[1...] are the recipients
@@ -94,6 +101,14 @@ func (s *Sharing) SetRev(rev string) { s.SRev = rev }
 // Clone implements couchdb.Doc
 func (s *Sharing) Clone() couchdb.Doc {
 	cloned := *s
+	if s.ExpiresAt != nil {
+		tmp := *s.ExpiresAt
+		cloned.ExpiresAt = &tmp
+	}
+	if s.ExcludedDirs != nil {
+		cloned.ExcludedDirs = make([]string, len(s.ExcludedDirs))
+		copy(cloned.ExcludedDirs, s.ExcludedDirs)
+	}
 	cloned.Rules = make([]Rule, len(s.Rules))
 	copy(cloned.Rules, s.Rules)
 	for i := range cloned.Rules {
@@ -373,10 +388,19 @@ func (s *Sharing) Create(inst *instance.Instance) (*permission.Permission, error
 	if len(s.Members) < 2 {
 		return nil, ErrNoRecipients
 	}
+	if s.Expired() {
+		return nil, ErrInvalidExpiration
+	}
 
 	if err := couchdb.CreateDoc(inst, s); err != nil {
 		return nil, err
 	}
+	if s.Owner {
+		if err := s.ScheduleExpiration(inst, 0); err != nil {
+			inst.Logger().WithNamespace("sharing").
+				Warnf("Cannot schedule the expiration of %s: %s", s.SID, err)
+		}
+	}
 	if rule := s.FirstFilesRule(); rule != nil && rule.Selector != couchdb.SelectorReferencedBy {
 		if err := s.AddReferenceForSharingDir(inst, rule); err != nil {
 			inst.Logger().WithNamespace("sharing").
@@ -454,6 +478,7 @@ func (s *Sharing) Revoke(inst *instance.Instance) error {
 	if err := RemoveSharedRefs(inst, s.SID); err != nil {
 		return err
 	}
+	s.removeActivity(inst)
 	if s.PreviewPath != "" {
 		if err := s.RevokePreviewPermissions(inst); err != nil {
 			return err
@@ -694,6 +719,9 @@ func (s *Sharing) NoMoreRecipient(inst *instance.Instance) error {
 	if err := couchdb.UpdateDoc(inst, s); err != nil {
 		return err
 	}
+	if s.Owner {
+		s.removeActivity(inst)
+	}
 	return RemoveSharedRefs(inst, s.SID)
 }
 
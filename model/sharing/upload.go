@@ -150,6 +150,12 @@ func (s *Sharing) UploadBatchTo(
 	if m.Instance == "" {
 		return false, ErrInvalidURL
 	}
+	if s.Owner && !m.ReceivesDocuments() {
+		return false, nil
+	}
+	if err := s.CheckExpiration(inst, m); err != nil {
+		return false, nil
+	}
 	creds := s.FindCredentials(m)
 	if creds == nil {
 		return false, ErrInvalidSharing
@@ -432,6 +438,10 @@ func (s *Sharing) optimizedUploadFile(
 type FileDocWithRevisions struct {
 	*vfs.FileDoc
 	Revisions RevsStruct `json:"_revisions"`
+
+	// MemberIndex is the index of the member who has sent the file. It is
+	// set by SyncFile before putting the document in the upload store.
+	MemberIndex int `json:"member_index,omitempty"`
 }
 
 // Clone is part of the couchdb.Doc interface
@@ -454,8 +464,9 @@ func (s *Sharing) createUploadKey(inst *instance.Instance, target *FileDocWithRe
 }
 
 // SyncFile tries to synchronize a file with just the metadata. If it can't,
-// it will return a key to upload the content.
-func (s *Sharing) SyncFile(inst *instance.Instance, target *FileDocWithRevisions) (*KeyToUpload, error) {
+// it will return a key to upload the content. The changes that the role of
+// the member who sent them doesn't allow are ignored.
+func (s *Sharing) SyncFile(inst *instance.Instance, m *Member, target *FileDocWithRevisions) (*KeyToUpload, error) {
 	inst.Logger().WithNamespace("upload").Debugf("SyncFile %#v", target)
 
 	if len(target.MD5Sum) == 0 {
@@ -487,6 +498,22 @@ func (s *Sharing) SyncFile(inst *instance.Instance, target *FileDocWithRevisions
 			if rule, _ := s.findRuleForNewFile(target.FileDoc); rule == nil {
 				return nil, ErrSafety
 			}
+			if !s.canSyncFile(m, target, nil) {
+				inst.Logger().WithNamespace("upload").
+					Infof("New file %s refused for the role %s", target.DocID, m.EffectiveRole())
+				return nil, nil
+			}
+			if !s.Owner {
+				// The content of the files in an excluded directory is not
+				// synchronized, only a placeholder is kept for them
+				if s.isExcluded(inst, target.DirID) {
+					return nil, s.savePlaceholder(inst, target.FileDoc)
+				}
+				if err := s.removePlaceholder(inst, target.DocID); err != nil {
+					return nil, err
+				}
+			}
+			target.MemberIndex = s.memberIndex(m)
 			return s.createUploadKey(inst, target)
 		}
 		return nil, err
@@ -515,10 +542,29 @@ func (s *Sharing) SyncFile(inst *instance.Instance, target *FileDocWithRevisions
 		// It's just the echo, there is nothing to do
 		return nil, nil
 	}
+	if !s.canSyncFile(m, target, current) {
+		inst.Logger().WithNamespace("upload").
+			Infof("Changes on file %s refused for the role %s", target.DocID, m.EffectiveRole())
+		return nil, nil
+	}
+	if !s.Owner && s.isExcluded(inst, target.DirID) && s.canDropFile(&ref) {
+		// The file is now in an excluded directory: its content is no longer
+		// kept on this cozy, and it is replaced by a placeholder
+		if err := s.savePlaceholder(inst, target.FileDoc); err != nil {
+			return nil, err
+		}
+		return nil, s.dropFile(inst, current, &ref)
+	}
 	if !bytes.Equal(target.MD5Sum, current.MD5Sum) {
+		target.MemberIndex = s.memberIndex(m)
 		return s.createUploadKey(inst, target)
 	}
-	return nil, s.updateFileMetadata(inst, target, current, &ref)
+	activity := fileActivity(target, current)
+	if err := s.updateFileMetadata(inst, target, current, &ref); err != nil {
+		return nil, err
+	}
+	s.recordActivity(inst, m, activity)
+	return nil, nil
 }
 
 // prepareFileWithAncestors find the parent directory for file, and recreates it
@@ -632,10 +678,18 @@ func (s *Sharing) HandleFileUpload(inst *instance.Instance, key string, create f
 		return err
 	}
 
+	var activity *Activity
 	if current == nil {
-		return s.UploadNewFile(inst, target, create)
+		activity = fileActivity(target, nil)
+		err = s.UploadNewFile(inst, target, create)
+	} else {
+		activity = fileActivity(target, current)
+		err = s.UploadExistingFile(inst, target, current, create)
 	}
-	return s.UploadExistingFile(inst, target, current, create)
+	if err == nil && target.MemberIndex > 0 && target.MemberIndex < len(s.Members) {
+		s.recordActivity(inst, &s.Members[target.MemberIndex], activity)
+	}
+	return err
 }
 
 // UploadNewFile is used to receive a new file.
@@ -0,0 +1,423 @@
+// Package upload is used for the resumable uploads of files: the content of a
+// file is sent in several chunks, and the client can resume the upload after a
+// network failure without restarting from the first byte.
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/note"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/lock"
+)
+
+// TTL is the duration after which an upload session without activity is
+// considered as abandoned, and can be removed.
+var TTL = 24 * time.Hour
+
+var (
+	// ErrSessionNotFound is used when the upload session does not exist or has
+	// expired.
+	ErrSessionNotFound = errors.New("Upload session not found")
+	// ErrOffsetMismatch is used when a chunk is sent for an offset that is not
+	// the number of bytes already uploaded.
+	ErrOffsetMismatch = errors.New("The offset does not match the uploaded size")
+	// ErrChunkTooLarge is used when a chunk goes past the announced size of the
+	// file.
+	ErrChunkTooLarge = errors.New("The chunk goes past the size of the file")
+	// ErrIncomplete is used when trying to finalize an upload before all the
+	// bytes have been sent.
+	ErrIncomplete = errors.New("The upload is not complete")
+	// ErrNotSupported is used when the VFS can't store the chunks.
+	ErrNotSupported = errors.New("The resumable uploads are not supported by this VFS")
+)
+
+// Session is an upload in progress. The file document is prepared when the
+// session is created, and the chunks are kept in the storage of the VFS, so
+// that the requests for an upload can be served by any stack, until the upload
+// is finalized: the chunks are then streamed to the VFS.
+//
+// The size of the file is reserved in the disk quota while the session exists
+// (see the DiskUsage of the VFS indexer), except when it is finalized, as the
+// file is then counted with the other files.
+type Session struct {
+	DocID      string       `json:"_id,omitempty"`
+	DocRev     string       `json:"_rev,omitempty"`
+	File       *vfs.FileDoc `json:"file"`
+	FileRev    string       `json:"file_rev,omitempty"` // Only for an overwrite
+	Chunks     []int64      `json:"chunks,omitempty"`   // The sizes of the chunks
+	Finalizing bool         `json:"finalizing,omitempty"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+}
+
+// ID returns the session identifier
+func (s *Session) ID() string { return s.DocID }
+
+// Rev returns the session revision
+func (s *Session) Rev() string { return s.DocRev }
+
+// DocType returns the session document type
+func (s *Session) DocType() string { return consts.FilesUploads }
+
+// Clone implements couchdb.Doc
+func (s *Session) Clone() couchdb.Doc {
+	cloned := *s
+	cloned.Chunks = append([]int64(nil), s.Chunks...)
+	if s.File != nil {
+		cloned.File = s.File.Clone().(*vfs.FileDoc)
+	}
+	return &cloned
+}
+
+// SetID changes the session identifier
+func (s *Session) SetID(id string) { s.DocID = id }
+
+// SetRev changes the session revision
+func (s *Session) SetRev(rev string) { s.DocRev = rev }
+
+// Included is part of jsonapi.Object interface
+func (s *Session) Included() []jsonapi.Object { return nil }
+
+// Relationships is part of jsonapi.Object interface
+func (s *Session) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Links is part of jsonapi.Object interface
+func (s *Session) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/files/upload/sessions/" + s.DocID}
+}
+
+// IsOverwrite returns true if the session is for a new content of an existing
+// file.
+func (s *Session) IsOverwrite() bool {
+	return s.FileRev != ""
+}
+
+// ExpireMessage is the message used by the clean-upload-session worker.
+type ExpireMessage struct {
+	SessionID string `json:"session_id"`
+}
+
+// NewSession creates an upload session for the given file document. olddoc is
+// the current file for an overwrite, or nil for a new file. The disk quota is
+// checked for the whole file before any chunk is sent, taking into account the
+// other uploads in progress.
+func NewSession(inst *instance.Instance, doc, olddoc *vfs.FileDoc) (*Session, error) {
+	if _, err := chunksStorer(inst); err != nil {
+		return nil, err
+	}
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(inst.VFS(), doc); err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		File:      doc,
+		ExpiresAt: time.Now().Add(TTL),
+	}
+	if olddoc != nil {
+		s.FileRev = olddoc.Rev()
+	}
+	if err := couchdb.CreateDoc(inst, s); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleExpiration(inst); err != nil {
+		inst.Logger().WithNamespace("upload").
+			Warnf("Cannot schedule the expiration of %s: %s", s.DocID, err)
+	}
+	return s, nil
+}
+
+// Get returns the upload session with the given identifier.
+func Get(inst *instance.Instance, sessionID string) (*Session, error) {
+	var s Session
+	if err := couchdb.GetDoc(inst, consts.FilesUploads, sessionID, &s); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	if s.File == nil || time.Now().After(s.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return &s, nil
+}
+
+// Offset returns the number of bytes already uploaded.
+func (s *Session) Offset() (int64, error) {
+	var offset int64
+	for _, size := range s.Chunks {
+		offset += size
+	}
+	return offset, nil
+}
+
+// reload fetches the session again, as it may have been modified by another
+// stack before the lock was taken.
+func (s *Session) reload(inst *instance.Instance) error {
+	fresh, err := Get(inst, s.DocID)
+	if err != nil {
+		return err
+	}
+	*s = *fresh
+	return nil
+}
+
+// WriteChunk appends a chunk to the uploaded content. The offset must be the
+// number of bytes already uploaded. It returns the new offset, even in case of
+// error, as the bytes received before a network failure are kept.
+func (s *Session) WriteChunk(inst *instance.Instance, offset int64, chunk io.Reader) (int64, error) {
+	mu := s.lock(inst)
+	if err := mu.Lock(); err != nil {
+		return 0, err
+	}
+	defer mu.Unlock()
+
+	if err := s.reload(inst); err != nil {
+		return 0, err
+	}
+	current, err := s.Offset()
+	if err != nil {
+		return 0, err
+	}
+	if offset != current {
+		return current, ErrOffsetMismatch
+	}
+
+	store, err := chunksStorer(inst)
+	if err != nil {
+		return current, err
+	}
+	w, err := store.CreateChunk(s.DocID, len(s.Chunks))
+	if err != nil {
+		return current, err
+	}
+	n, err := io.Copy(w, io.LimitReader(chunk, s.File.ByteSize-offset))
+	if err == nil {
+		if extra, _ := io.CopyN(io.Discard, chunk, 1); extra > 0 {
+			err = ErrChunkTooLarge
+		}
+	}
+	errc := w.Close()
+	if err == nil {
+		err = errc
+	}
+	// The bytes received before a network failure are kept, but not the
+	// chunk if it is too large or can't be saved: it will be overwritten by
+	// the next one.
+	if errc != nil || errors.Is(err, ErrChunkTooLarge) {
+		n = 0
+	}
+	if n > 0 {
+		s.Chunks = append(s.Chunks, n)
+	}
+
+	s.ExpiresAt = time.Now().Add(TTL)
+	if erru := couchdb.UpdateDoc(inst, s); err == nil {
+		err = erru
+	}
+	return offset + n, err
+}
+
+// Finalize creates or updates the file with the uploaded content, and removes
+// the session. If md5sum is given, it is checked against the content.
+func (s *Session) Finalize(inst *instance.Instance, md5sum []byte) (*vfs.FileDoc, error) {
+	mu := s.lock(inst)
+	if err := mu.Lock(); err != nil {
+		return nil, err
+	}
+	defer mu.Unlock()
+
+	if err := s.reload(inst); err != nil {
+		return nil, err
+	}
+	offset, err := s.Offset()
+	if err != nil {
+		return nil, err
+	}
+	if offset != s.File.ByteSize {
+		return nil, ErrIncomplete
+	}
+
+	newdoc := s.File.Clone().(*vfs.FileDoc)
+	if md5sum != nil {
+		if newdoc.MD5Sum != nil && !bytes.Equal(newdoc.MD5Sum, md5sum) {
+			return nil, vfs.ErrInvalidHash
+		}
+		newdoc.MD5Sum = md5sum
+	}
+
+	fs := inst.VFS()
+	var olddoc *vfs.FileDoc
+	if s.IsOverwrite() {
+		olddoc, err = fs.FileByID(newdoc.ID())
+		if err != nil {
+			return nil, err
+		}
+		if olddoc.Rev() != s.FileRev {
+			return nil, vfs.ErrConflict
+		}
+	}
+
+	store, err := chunksStorer(inst)
+	if err != nil {
+		return nil, err
+	}
+	content := &chunksReader{store: store, sessionID: s.DocID, count: len(s.Chunks)}
+	defer content.Close()
+
+	// The space reserved by the session is released, as the quota is checked
+	// again for the file by the VFS. It is reserved again if the file can't be
+	// created.
+	s.Finalizing = true
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return nil, err
+	}
+	if err := s.createFile(inst, newdoc, olddoc, content); err != nil {
+		s.Finalizing = false
+		_ = couchdb.UpdateDoc(inst, s)
+		return nil, err
+	}
+
+	_ = s.remove(inst)
+	return newdoc, nil
+}
+
+// createFile creates or updates the file in the VFS with the given content.
+func (s *Session) createFile(inst *instance.Instance, newdoc, olddoc *vfs.FileDoc, content io.ReadCloser) error {
+	if filepath.Ext(newdoc.DocName) == ".cozy-note" {
+		return note.ImportFile(inst, newdoc, olddoc, content)
+	}
+	file, err := inst.VFS().CreateFile(newdoc, olddoc)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, content)
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Abort removes the session and the chunks already uploaded.
+func (s *Session) Abort(inst *instance.Instance) error {
+	mu := s.lock(inst)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+	return s.remove(inst)
+}
+
+// Expire is called when the expiration date of a session has been reached.
+// If the session has been used since the expiration was scheduled, it is
+// scheduled again. Else, the session is aborted.
+func Expire(inst *instance.Instance, sessionID string) error {
+	var s Session
+	if err := couchdb.GetDoc(inst, consts.FilesUploads, sessionID, &s); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	if time.Now().Before(s.ExpiresAt) {
+		return s.scheduleExpiration(inst)
+	}
+	return s.Abort(inst)
+}
+
+func (s *Session) remove(inst *instance.Instance) error {
+	store, err := chunksStorer(inst)
+	if err != nil {
+		return err
+	}
+	// The chunk after the last one may have been written by a failed request
+	if err := store.RemoveChunks(s.DocID, len(s.Chunks)+1); err != nil {
+		return err
+	}
+	return couchdb.DeleteDoc(inst, s)
+}
+
+func (s *Session) scheduleExpiration(inst *instance.Instance) error {
+	msg, err := job.NewMessage(&ExpireMessage{SessionID: s.DocID})
+	if err != nil {
+		return err
+	}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: "clean-upload-session",
+		Arguments:  s.ExpiresAt.Format(time.RFC3339),
+	}, msg)
+	if err != nil {
+		return err
+	}
+	return job.System().AddTrigger(t)
+}
+
+// lock returns the lock of the session. It is refreshed while it is held, as a
+// chunk can be streamed or the file copied for longer than the lock timeout.
+func (s *Session) lock(inst *instance.Instance) lock.ErrorLocker {
+	return config.Lock().LongOperation(inst, "uploads/"+s.DocID)
+}
+
+func chunksStorer(inst *instance.Instance) (vfs.ChunksStorer, error) {
+	store, ok := inst.VFS().(vfs.ChunksStorer)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return store, nil
+}
+
+// chunksReader reads the chunks of a session one after the other, so that
+// they can be streamed to the VFS without opening them all at once.
+type chunksReader struct {
+	store     vfs.ChunksStorer
+	sessionID string
+	count     int
+	index     int
+	current   io.ReadCloser
+}
+
+func (r *chunksReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.index >= r.count {
+				return 0, io.EOF
+			}
+			f, err := r.store.OpenChunk(r.sessionID, r.index)
+			if err != nil {
+				return 0, err
+			}
+			r.current = f
+			r.index++
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			err = r.current.Close()
+			r.current = nil
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunksReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
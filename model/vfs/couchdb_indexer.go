@@ -68,6 +68,36 @@ func (c *couchdbIndexer) DiskUsage() (int64, error) {
 		used += versions
 	}
 
+	if uploads, err := c.UploadsUsage(); err == nil {
+		used += uploads
+	}
+
+	return used, nil
+}
+
+// UploadsUsage returns the space reserved by the resumable uploads in
+// progress: their chunks are kept in the VFS until the upload is finalized,
+// and the whole size of the file is reserved when the session is created.
+func (c *couchdbIndexer) UploadsUsage() (int64, error) {
+	var sessions []struct {
+		File       *FileDoc `json:"file"`
+		Finalizing bool     `json:"finalizing,omitempty"`
+	}
+	req := &couchdb.AllDocsRequest{}
+	if err := couchdb.GetAllDocs(c.db, consts.FilesUploads, req, &sessions); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var used int64
+	for _, s := range sessions {
+		// When a session is finalized, the file is created and counted by
+		// the files usage.
+		if s.File != nil && !s.Finalizing {
+			used += s.File.ByteSize
+		}
+	}
 	return used, nil
 }
 
@@ -52,6 +52,15 @@ const (
 	// ThumbnailWithNoFile is used when there is a thumbnail but not the file
 	// that was used to create it.
 	ThumbnailWithNoFile = "thumbnail_with_no_file"
+	// BlobOrphan is used when a blob of a deduplicated store is not used by
+	// any file or version.
+	BlobOrphan FsckLogType = "blob_orphan"
+	// BlobOverReferenced is used when the reference counter of a blob is
+	// greater than the number of files and versions that use it.
+	BlobOverReferenced FsckLogType = "blob_over_referenced"
+	// BlobUnderReferenced is used when the reference counter of a blob is
+	// lower than the number of files and versions that use it.
+	BlobUnderReferenced FsckLogType = "blob_under_referenced"
 )
 
 // FsckLog is a struct for an inconsistency in the VFS
@@ -64,6 +73,7 @@ type FsckLog struct {
 	IsVersion        bool                 `json:"is_version"`
 	ContentMismatch  *FsckContentMismatch `json:"content_mismatch,omitempty"`
 	ExpectedFullpath string               `json:"expected_fullpath,omitempty"`
+	BlobMismatch     *FsckBlobMismatch    `json:"blob_mismatch,omitempty"`
 }
 
 // String returns a string describing the FsckLog
@@ -108,6 +118,12 @@ func (f *FsckLog) String() string {
 		return "this document has a conflict in CouchDB between two branches of revisions"
 	case ThumbnailWithNoFile:
 		return "a thumbnail exists but its original file has been removed"
+	case BlobOrphan:
+		return "a blob is stored but no file or version uses it"
+	case BlobOverReferenced:
+		return "the reference counter of a blob is greater than its number of uses"
+	case BlobUnderReferenced:
+		return "the reference counter of a blob is lower than its number of uses"
 	}
 	panic(fmt.Sprintf("bad FsckLog type: %#v", f))
 }
@@ -121,6 +137,15 @@ type FsckContentMismatch struct {
 	MD5SumFile  []byte `json:"md5sum_file"`
 }
 
+// FsckBlobMismatch is a struct used by the FSCK when the reference counter of
+// a blob in a deduplicated store does not match the files and versions that
+// use it.
+type FsckBlobMismatch struct {
+	BlobID       string `json:"blob_id"`
+	RefsStored   int    `json:"refs_stored"`
+	RefsExpected int    `json:"refs_expected"`
+}
+
 // Tree is returned by the BuildTree method on the indexes. It contains a
 // pointer to the root element of the tree, a map of directories indexed by
 // their ID, and a map of a potential list of orphan file or directories
@@ -42,6 +42,9 @@ const (
 	// VersionsDirName is the path of the directory where old versions of files
 	// are persisted.
 	VersionsDirName = "/.cozy_versions"
+	// UploadsDirName is the path of the directory where the chunks of the
+	// resumable uploads are kept until the upload is finalized.
+	UploadsDirName = "/.cozy_uploads"
 )
 
 const conflictFormat = "%s (%s)"
@@ -162,7 +165,7 @@ type Indexer interface {
 	FilePather
 
 	// DiskUsage computes the total size of the files contained in the VFS,
-	// including versions.
+	// including versions and the space reserved by the uploads in progress.
 	DiskUsage() (int64, error)
 	// FilesUsage computes the total size of the files contained in the VFS,
 	// excluding versions.
@@ -312,6 +315,38 @@ type VFS interface {
 	GetIndexer() Indexer
 }
 
+// Deduplicator is an optional interface for the VFS that store the contents
+// of the files by their hash. It can be used to create or update a file with
+// the content of another file, without copying the bytes.
+type Deduplicator interface {
+	// CreateFileWithContentOf creates or updates the newdoc file (olddoc is
+	// nil for a creation) with the same content as the src file.
+	CreateFileWithContentOf(newdoc, olddoc, src *FileDoc) error
+}
+
+// ChunksStorer is an optional interface for the VFS that can keep the chunks
+// of the resumable uploads in the same storage as the files, so that all the
+// stacks can access them.
+type ChunksStorer interface {
+	// CreateChunk returns a writer for the chunk at the given index of an
+	// upload session. The chunk is available only after Close.
+	CreateChunk(sessionID string, index int) (io.WriteCloser, error)
+	// OpenChunk returns a reader for the chunk at the given index of an upload
+	// session.
+	OpenChunk(sessionID string, index int) (io.ReadCloser, error)
+	// RemoveChunks deletes the count first chunks of an upload session.
+	RemoveChunks(sessionID string, count int) error
+}
+
+// StorageKeysRewrapper is an optional interface for the VFS and thumbnails
+// filesystems that encrypt the contents of the files at rest.
+type StorageKeysRewrapper interface {
+	// RewrapStorageKeys seals again the data keys of the files with the
+	// current storage key of the instance. It returns the versions of the
+	// storage keys that are still used after that.
+	RewrapStorageKeys() (map[uint32]bool, error)
+}
+
 // Prefixer interface describes a prefixer that can also give the context for
 // the targeted instance.
 type Prefixer interface {
@@ -4,6 +4,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -794,6 +796,142 @@ func (c *contexter) DomainName() string     { return c.domain }
 func (c *contexter) DBPrefix() string       { return c.prefix }
 func (c *contexter) GetContextName() string { return c.context }
 
+func TestDedup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+
+	fs, tempdir := makeDedupFS(t)
+
+	createFile := func(t *testing.T, name, content string) *vfs.FileDoc {
+		doc, err := vfs.NewFileDoc(name, consts.RootDirID, -1, nil, "text/plain", "text", time.Now(), false, false, false, nil)
+		require.NoError(t, err)
+		file, err := fs.CreateFile(doc, nil)
+		require.NoError(t, err)
+		_, err = io.WriteString(file, content)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		return doc
+	}
+
+	readFile := func(t *testing.T, doc *vfs.FileDoc) string {
+		file, err := fs.OpenFile(doc)
+		require.NoError(t, err)
+		defer file.Close()
+		buf, err := io.ReadAll(file)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	fsck := func(t *testing.T) []*vfs.FsckLog {
+		var logs []*vfs.FsckLog
+		require.NoError(t, fs.Fsck(func(log *vfs.FsckLog) {
+			logs = append(logs, log)
+		}, false))
+		return logs
+	}
+
+	var doc, copied *vfs.FileDoc
+
+	t.Run("CopyFileReusesTheBlob", func(t *testing.T) {
+		doc = createFile(t, "dedup.txt", "hello dedup")
+		assert.NotEmpty(t, doc.InternalID)
+
+		copied = vfs.CreateFileDocCopy(doc, "", "dedup (copy).txt")
+		require.NoError(t, fs.CopyFile(doc, copied))
+		assert.Equal(t, doc.InternalID, copied.InternalID)
+		assert.Equal(t, "hello dedup", readFile(t, copied))
+
+		other := createFile(t, "same.txt", "hello dedup")
+		assert.Equal(t, doc.InternalID, other.InternalID)
+		require.NoError(t, fs.DestroyFile(other))
+
+		assert.Empty(t, fsck(t))
+	})
+
+	t.Run("DestroyFileReleasesTheBlob", func(t *testing.T) {
+		require.NoError(t, fs.DestroyFile(doc))
+		assert.Equal(t, "hello dedup", readFile(t, copied))
+		assert.Empty(t, fsck(t))
+
+		require.NoError(t, fs.DestroyFile(copied))
+		assert.Empty(t, fsck(t))
+		blobs, err := os.ReadDir(path.Join(tempdir, "io.cozy.vfs.test", vfsafero.BlobsDirName,
+			copied.InternalID[:2]))
+		require.NoError(t, err)
+		assert.Empty(t, blobs)
+	})
+
+	t.Run("CollisionIsNotReused", func(t *testing.T) {
+		sum := md5.Sum([]byte("hello collision"))
+		key := hex.EncodeToString(sum[:])
+		dir := path.Join(tempdir, "io.cozy.vfs.test", vfsafero.BlobsDirName, key[:2])
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		blob := path.Join(dir, key[2:])
+		require.NoError(t, os.WriteFile(blob, []byte("other content"), 0644))
+		require.NoError(t, os.WriteFile(blob+".refs", []byte("1"), 0644))
+		defer func() {
+			_ = os.Remove(blob)
+			_ = os.Remove(blob + ".refs")
+		}()
+
+		doc, err := vfs.NewFileDoc("collision.txt", consts.RootDirID, -1, nil, "text/plain", "text", time.Now(), false, false, false, nil)
+		require.NoError(t, err)
+		file, err := fs.CreateFile(doc, nil)
+		require.NoError(t, err)
+		_, err = io.WriteString(file, "hello collision")
+		require.NoError(t, err)
+		assert.ErrorIs(t, file.Close(), vfs.ErrConflict)
+
+		_, err = fs.FileByPath("/collision.txt")
+		assert.True(t, os.IsNotExist(err))
+		buf, err := os.ReadFile(blob)
+		require.NoError(t, err)
+		assert.Equal(t, "other content", string(buf))
+	})
+
+	t.Run("FsckDetectsOrphanBlobs", func(t *testing.T) {
+		dir := path.Join(tempdir, "io.cozy.vfs.test", vfsafero.BlobsDirName, "00")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		blob := path.Join(dir, "112233445566778899aabbccddeeff")
+		require.NoError(t, os.WriteFile(blob, []byte("orphan"), 0644))
+		require.NoError(t, os.WriteFile(blob+".refs", []byte("1"), 0644))
+
+		logs := fsck(t)
+		require.Len(t, logs, 1)
+		assert.Equal(t, vfs.BlobOrphan, logs[0].Type)
+		assert.Equal(t, "00112233445566778899aabbccddeeff", logs[0].BlobMismatch.BlobID)
+	})
+}
+
+func makeDedupFS(t *testing.T) (vfs.VFS, string) {
+	t.Helper()
+
+	tempdir := t.TempDir()
+
+	db := &contexter{0, "dedup.testvfs.example.org", "dedup.testvfs.example.org", "cozy_beta"}
+	index := vfs.NewCouchdbIndexer(db)
+	mu := config.Lock().ReadWrite(db, "vfs-dedup-test")
+	dedupFs, err := vfsafero.NewDedup(db, index, &diskImpl{}, mu,
+		&url.URL{Scheme: "file", Host: "localhost", Path: tempdir}, "io.cozy.vfs.test")
+	require.NoError(t, err)
+
+	require.NoError(t, couchdb.ResetDB(db, consts.Files))
+	t.Cleanup(func() { _ = couchdb.DeleteDB(db, consts.Files) })
+
+	g, _ := errgroup.WithContext(context.Background())
+	couchdb.DefineIndexes(g, db, couchdb.IndexesByDoctype(consts.Files))
+	couchdb.DefineViews(g, db, couchdb.ViewsByDoctype(consts.Files))
+
+	require.NoError(t, g.Wait())
+	require.NoError(t, dedupFs.InitFs())
+
+	return dedupFs, tempdir
+}
+
 func makeAferoFS(t *testing.T) vfs.VFS {
 	t.Helper()
 
@@ -0,0 +1,34 @@
+package vfsafero
+
+import (
+	"io"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+)
+
+func chunkPath(sessionID string, index int) string {
+	return path.Join(vfs.UploadsDirName, sessionID, strconv.Itoa(index))
+}
+
+// CreateChunk is required by the vfs.ChunksStorer interface.
+func (afs *aferoVFS) CreateChunk(sessionID string, index int) (io.WriteCloser, error) {
+	if err := afs.fs.MkdirAll(path.Join(vfs.UploadsDirName, sessionID), 0755); err != nil {
+		return nil, err
+	}
+	return afs.fs.OpenFile(chunkPath(sessionID, index), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+}
+
+// OpenChunk is required by the vfs.ChunksStorer interface.
+func (afs *aferoVFS) OpenChunk(sessionID string, index int) (io.ReadCloser, error) {
+	return afs.fs.Open(chunkPath(sessionID, index))
+}
+
+// RemoveChunks is required by the vfs.ChunksStorer interface.
+func (afs *aferoVFS) RemoveChunks(sessionID string, count int) error {
+	return afs.fs.RemoveAll(path.Join(vfs.UploadsDirName, sessionID))
+}
+
+var _ vfs.ChunksStorer = &aferoVFS{}
@@ -0,0 +1,66 @@
+package vfsafero
+
+import (
+	"errors"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/model/vfs/vfscrypt"
+)
+
+// ErrEncryptionNotSupported is used when the encryption at rest is asked for a
+// VFS that doesn't support it.
+var ErrEncryptionNotSupported = errors.New("vfsafero: encryption at rest is not supported")
+
+// Encrypt enables the encryption at rest of the contents of the files for a
+// VFS returned by New or NewDedup. The files written before are still
+// readable.
+func Encrypt(fs vfs.VFS, keys vfscrypt.Keys) (vfs.VFS, error) {
+	switch v := fs.(type) {
+	case *aferoVFS:
+		v.fs = vfscrypt.NewFs(v.fs, keys)
+	case *dedupVFS:
+		v.fs = vfscrypt.NewFs(v.fs, keys)
+	default:
+		return nil, ErrEncryptionNotSupported
+	}
+	return fs, nil
+}
+
+// NewEncryptedThumbsFs creates a new thumb filesystem, where the thumbnails
+// are encrypted at rest.
+func NewEncryptedThumbsFs(fs *vfscrypt.Fs) vfs.Thumbser {
+	return &thumbs{fs}
+}
+
+func (afs *aferoVFS) encrypted() bool {
+	_, ok := afs.fs.(*vfscrypt.Fs)
+	return ok
+}
+
+// RewrapStorageKeys is required by the vfs.StorageKeysRewrapper interface.
+func (afs *aferoVFS) RewrapStorageKeys() (map[uint32]bool, error) {
+	fs, ok := afs.fs.(*vfscrypt.Fs)
+	if !ok {
+		return nil, ErrEncryptionNotSupported
+	}
+	if lockerr := afs.mu.Lock(); lockerr != nil {
+		return nil, lockerr
+	}
+	defer afs.mu.Unlock()
+	// The thumbnails are rewrapped by the thumbs filesystem
+	return fs.RewrapAll(vfs.ThumbsDirName)
+}
+
+// RewrapStorageKeys is required by the vfs.StorageKeysRewrapper interface.
+func (t *thumbs) RewrapStorageKeys() (map[uint32]bool, error) {
+	fs, ok := t.fs.(*vfscrypt.Fs)
+	if !ok {
+		return nil, ErrEncryptionNotSupported
+	}
+	return fs.RewrapAll()
+}
+
+var (
+	_ vfs.StorageKeysRewrapper = &aferoVFS{}
+	_ vfs.StorageKeysRewrapper = &thumbs{}
+)
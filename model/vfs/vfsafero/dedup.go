@@ -0,0 +1,820 @@
+package vfsafero
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/lock"
+	"github.com/spf13/afero"
+)
+
+// BlobsDirName is the directory where the contents of the files and versions
+// are stored by their md5sum when the deduplication is enabled.
+const BlobsDirName = "/.cozy_blobs"
+
+// dedupVFS is a vfs.VFS where the contents of the files and versions are
+// stored only once per instance, in blobs named after their md5sum. Each blob
+// has a reference counter (in a .refs file next to it) for the number of
+// files and versions that use it, and the blob is removed when this counter
+// drops to zero. The internal_vfs_id of a file is the name of its blob.
+//
+// The files created before the deduplication was enabled are still stored at
+// their path, and their content is moved to the blobs when they are modified.
+type dedupVFS struct {
+	*aferoVFS
+}
+
+// NewDedup returns a vfs.VFS like New, but where the contents are
+// deduplicated.
+func NewDedup(db vfs.Prefixer, index vfs.Indexer, disk vfs.DiskThresholder, mu lock.ErrorRWLocker, fsURL *url.URL, pathSegment string) (vfs.VFS, error) {
+	fs, err := New(db, index, disk, mu, fsURL, pathSegment)
+	if err != nil {
+		return nil, err
+	}
+	return &dedupVFS{fs.(*aferoVFS)}, nil
+}
+
+func (dfs *dedupVFS) UseSharingIndexer(index vfs.Indexer) vfs.VFS {
+	return &dedupVFS{dfs.aferoVFS.UseSharingIndexer(index).(*aferoVFS)}
+}
+
+func (dfs *dedupVFS) CreateFile(newdoc, olddoc *vfs.FileDoc, opts ...vfs.CreateOptions) (vfs.File, error) {
+	file, err := dfs.aferoVFS.CreateFile(newdoc, olddoc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dedupFileCreation{file.(*aferoFileCreation), dfs}, nil
+}
+
+func (dfs *dedupVFS) CopyFile(olddoc, newdoc *vfs.FileDoc) error {
+	if _, ok := fileBlob(olddoc); !ok {
+		return dfs.copyContent(newdoc, nil, dfs, olddoc)
+	}
+
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(dfs, olddoc); err != nil {
+		return err
+	}
+	return dfs.reuseBlob(newdoc, nil, olddoc)
+}
+
+// CreateFileWithContentOf is required by the vfs.Deduplicator interface.
+func (dfs *dedupVFS) CreateFileWithContentOf(newdoc, olddoc, src *vfs.FileDoc) error {
+	if _, ok := fileBlob(src); !ok {
+		return dfs.copyContent(newdoc, olddoc, dfs, src)
+	}
+
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(dfs, src); err != nil {
+		return err
+	}
+	if olddoc != nil {
+		newdoc.SetID(olddoc.ID())
+		newdoc.SetRev(olddoc.Rev())
+		newdoc.CreatedAt = olddoc.CreatedAt
+	}
+	return dfs.reuseBlob(newdoc, olddoc, src)
+}
+
+func (dfs *dedupVFS) CopyFileFromOtherFS(
+	newdoc, olddoc *vfs.FileDoc,
+	srcFS vfs.Fs,
+	srcDoc *vfs.FileDoc,
+) error {
+	if src, ok := srcFS.(*dedupVFS); ok {
+		if _, ok := fileBlob(srcDoc); ok {
+			done, err := dfs.linkBlob(newdoc, olddoc, src, srcDoc)
+			if done {
+				return err
+			}
+		}
+	}
+	return dfs.copyContent(newdoc, olddoc, srcFS, srcDoc)
+}
+
+// linkBlob creates or updates newdoc with the content of srcDoc from another
+// instance, without copying the bytes: either the blob is already known by
+// this instance, or it is added as a hard link to the blob of the other
+// instance. It returns false if it was not possible, and the content must
+// be copied.
+func (dfs *dedupVFS) linkBlob(newdoc, olddoc *vfs.FileDoc, src *dedupVFS, srcDoc *vfs.FileDoc) (bool, error) {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return true, lockerr
+	}
+	defer dfs.mu.Unlock()
+
+	key, _ := fileBlob(srcDoc)
+	linked := false
+	if _, err := dfs.fs.Stat(blobPath(key)); err == nil {
+		// The blob of this instance is only reused if it has the same bytes,
+		// as the md5sum alone is not enough to identify a content.
+		same, err := sameContent(src.fs, blobPath(key), dfs.fs, blobPath(key))
+		if err != nil || !same {
+			return false, nil
+		}
+	} else {
+		// The encrypted blobs are sealed with the storage keys of their
+		// instance, and can't be shared with another instance.
+		if !os.IsNotExist(err) || !dfs.osFS || !src.osFS || dfs.encrypted() || src.encrypted() {
+			return false, nil
+		}
+		from := path.Join(src.pth, blobPath(key))
+		to := path.Join(dfs.pth, blobPath(key))
+		_ = dfs.fs.MkdirAll(path.Dir(blobPath(key)), 0755)
+		if err := os.Link(from, to); err != nil {
+			return false, nil
+		}
+		linked = true
+	}
+
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(dfs, srcDoc); err != nil {
+		if linked {
+			_ = dfs.fs.Remove(blobPath(key))
+		}
+		return true, err
+	}
+	if olddoc != nil {
+		newdoc.SetID(olddoc.ID())
+		newdoc.SetRev(olddoc.Rev())
+		newdoc.CreatedAt = olddoc.CreatedAt
+	}
+	err := dfs.reuseBlob(newdoc, olddoc, srcDoc)
+	if err != nil && linked {
+		_ = dfs.fs.Remove(blobPath(key))
+	}
+	return true, err
+}
+
+// copyContent creates or updates newdoc by copying the content of srcDoc. It
+// is used when the content of srcDoc is not in a blob that can be reused.
+func (dfs *dedupVFS) copyContent(newdoc, olddoc *vfs.FileDoc, srcFS vfs.Fs, srcDoc *vfs.FileDoc) error {
+	content, err := srcFS.OpenFile(srcDoc)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	fd, err := dfs.CreateFile(newdoc, olddoc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fd, content)
+	errc := fd.Close()
+	if err != nil {
+		return err
+	}
+	return errc
+}
+
+// reuseBlob creates or updates newdoc with the same blob as src. It must be
+// called with the VFS lock.
+func (dfs *dedupVFS) reuseBlob(newdoc, olddoc, src *vfs.FileDoc) error {
+	key, _ := fileBlob(src)
+	if _, err := dfs.fs.Stat(blobPath(key)); err != nil {
+		return err
+	}
+	newdoc.MD5Sum = append([]byte(nil), src.MD5Sum...)
+	newdoc.ByteSize = src.ByteSize
+	return dfs.indexContent(newdoc, olddoc, dfs.incref)
+}
+
+// indexContent creates or updates the newdoc in the index, for a content that
+// is stored in a blob. The store function is called first to add the reference
+// of newdoc to its blob, and this reference is released if the index can't be
+// updated. The old content is kept as a version if needed. It must
+// be called with the VFS lock.
+func (dfs *dedupVFS) indexContent(newdoc, olddoc *vfs.FileDoc, store func(key string) error) error {
+	// Check again that a file with the same path does not exist. It can happen
+	// when the same file is uploaded twice in parallel.
+	if olddoc == nil {
+		exists, err := dfs.Indexer.DirChildExists(newdoc.DirID, newdoc.DocName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return os.ErrExist
+		}
+	}
+
+	newpath, err := dfs.Indexer.FilePath(newdoc)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(newpath, vfs.TrashDirName+"/") {
+		return vfs.ErrParentInTrash
+	}
+
+	var oldpath string
+	if olddoc != nil {
+		if _, ok := fileBlob(olddoc); !ok {
+			if oldpath, err = dfs.Indexer.FilePath(olddoc); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The blob is put in place before the document references it, so that
+	// the document never points to a missing content.
+	key := hex.EncodeToString(newdoc.MD5Sum)
+	if err = store(key); err != nil {
+		return err
+	}
+	newdoc.InternalID = key
+
+	var v *vfs.Version
+	if olddoc != nil {
+		v = vfs.NewVersion(olddoc)
+		err = dfs.Indexer.UpdateFileDoc(olddoc, newdoc)
+	} else if newdoc.ID() == "" {
+		err = dfs.Indexer.CreateFileDoc(newdoc)
+	} else {
+		err = dfs.Indexer.CreateNamedFileDoc(newdoc)
+	}
+	if err != nil {
+		_ = dfs.decref(key)
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+
+	oldKey, ok := fileBlob(olddoc)
+	if !ok {
+		// The old content was stored at its path before the deduplication was
+		// enabled: it is moved to the blobs to be used by the version.
+		oldKey = hex.EncodeToString(olddoc.MD5Sum)
+		// If it fails, the old content is left at its path, so that it can
+		// still be recovered.
+		if err := dfs.storeBlob(oldpath, oldKey); err != nil {
+			return err
+		}
+	}
+
+	actionV, toClean, _ := vfs.FindVersionsToClean(dfs, newdoc.DocID, v)
+	if bytes.Equal(newdoc.MD5Sum, olddoc.MD5Sum) {
+		actionV = vfs.CleanCandidateVersion
+	}
+	if actionV == vfs.KeepCandidateVersion {
+		if errv := dfs.Indexer.CreateVersion(v); errv != nil {
+			actionV = vfs.CleanCandidateVersion
+		}
+	}
+	if actionV == vfs.CleanCandidateVersion {
+		_ = dfs.decref(oldKey)
+	}
+	for _, old := range toClean {
+		_ = dfs.cleanOldVersion(old)
+	}
+	return nil
+}
+
+func (dfs *dedupVFS) DissociateFile(src, dst *vfs.FileDoc) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+
+	// Move the source file to the destination, except if its content is in a
+	// blob that can just be used by the destination
+	needRename := false
+	var from, to string
+	if key, ok := fileBlob(src); ok {
+		dst.InternalID = key
+	} else {
+		var err error
+		needRename = true
+		from, err = dfs.Indexer.FilePath(src)
+		if errors.Is(err, vfs.ErrParentDoesNotExist) {
+			needRename = false // The parent directory has already been dissociated
+		} else if err != nil {
+			return err
+		}
+		to, err = dfs.Indexer.FilePath(dst)
+		if err != nil {
+			return err
+		}
+		if from == to {
+			needRename = false
+		}
+	}
+
+	if needRename {
+		if err := safeRenameFile(dfs.fs, from, to); err != nil {
+			return err
+		}
+	}
+	if err := dfs.Indexer.CreateFileDoc(dst); err != nil {
+		if needRename {
+			_ = dfs.fs.Rename(to, from)
+		}
+		return err
+	}
+
+	// Clean the source file and its versions
+	if err := dfs.Indexer.DeleteFileDoc(src); err != nil {
+		return err
+	}
+	versions, err := vfs.VersionsFor(dfs, src.DocID)
+	if err == nil {
+		dfs.releaseVersions(versions)
+	}
+	_ = dfs.fs.RemoveAll(pathForVersions(src.DocID))
+	if err == nil {
+		_ = dfs.Indexer.BatchDeleteVersions(versions)
+	}
+	return nil
+}
+
+func (dfs *dedupVFS) DestroyDirContent(doc *vfs.DirDoc, push func(vfs.TrashJournal) error) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+	diskUsage, _ := dfs.DiskUsage()
+	files, destroyed, err := dfs.Indexer.DeleteDirDocAndContent(doc, true)
+	if err != nil {
+		return err
+	}
+	vfs.DiskQuotaAfterDestroy(dfs, diskUsage, destroyed)
+	infos, err := afero.ReadDir(dfs.fs, doc.Fullpath)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		fullpath := path.Join(doc.Fullpath, info.Name())
+		if info.IsDir() {
+			err = dfs.fs.RemoveAll(fullpath)
+		} else {
+			err = dfs.fs.Remove(fullpath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return dfs.destroyContents(files)
+}
+
+func (dfs *dedupVFS) DestroyDirAndContent(doc *vfs.DirDoc, push func(vfs.TrashJournal) error) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+	diskUsage, _ := dfs.DiskUsage()
+	files, destroyed, err := dfs.Indexer.DeleteDirDocAndContent(doc, false)
+	if err != nil {
+		return err
+	}
+	vfs.DiskQuotaAfterDestroy(dfs, diskUsage, destroyed)
+	if err = dfs.fs.RemoveAll(doc.Fullpath); err != nil {
+		return err
+	}
+	return dfs.destroyContents(files)
+}
+
+// destroyContents releases the blobs and versions of files that have been
+// removed from the index. It must be called with the VFS lock.
+func (dfs *dedupVFS) destroyContents(files []*vfs.FileDoc) error {
+	var allVersions []*vfs.Version
+	for _, file := range files {
+		if key, ok := fileBlob(file); ok {
+			_ = dfs.decref(key)
+		}
+		if versions, err := vfs.VersionsFor(dfs, file.DocID); err == nil {
+			dfs.releaseVersions(versions)
+			allVersions = append(allVersions, versions...)
+		}
+		_ = dfs.fs.RemoveAll(pathForVersions(file.DocID))
+	}
+	return dfs.Indexer.BatchDeleteVersions(allVersions)
+}
+
+func (dfs *dedupVFS) DestroyFile(doc *vfs.FileDoc) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+	diskUsage, _ := dfs.DiskUsage()
+	key, isBlob := fileBlob(doc)
+	if !isBlob {
+		name, err := dfs.Indexer.FilePath(doc)
+		if err != nil {
+			return err
+		}
+		err = dfs.fs.Remove(name)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	vfs.DiskQuotaAfterDestroy(dfs, diskUsage, doc.ByteSize)
+	if err := dfs.Indexer.DeleteFileDoc(doc); err != nil {
+		return err
+	}
+	if isBlob {
+		_ = dfs.decref(key)
+	}
+	versions, err := vfs.VersionsFor(dfs, doc.DocID)
+	if err != nil {
+		return err
+	}
+	dfs.releaseVersions(versions)
+	_ = dfs.fs.RemoveAll(pathForVersions(doc.DocID))
+	return dfs.Indexer.BatchDeleteVersions(versions)
+}
+
+func (dfs *dedupVFS) openFile(doc *vfs.FileDoc) (vfs.File, error) {
+	key, ok := fileBlob(doc)
+	if !ok {
+		return dfs.aferoVFS.openFile(doc)
+	}
+	f, err := dfs.fs.Open(blobPath(key))
+	if err != nil {
+		return nil, err
+	}
+	return &aferoFileOpen{f}, nil
+}
+
+func (dfs *dedupVFS) OpenFile(doc *vfs.FileDoc) (vfs.File, error) {
+	if lockerr := dfs.mu.RLock(); lockerr != nil {
+		return nil, lockerr
+	}
+	defer dfs.mu.RUnlock()
+	return dfs.openFile(doc)
+}
+
+func (dfs *dedupVFS) OpenFileVersion(doc *vfs.FileDoc, version *vfs.Version) (vfs.File, error) {
+	if lockerr := dfs.mu.RLock(); lockerr != nil {
+		return nil, lockerr
+	}
+	defer dfs.mu.RUnlock()
+	name := pathForVersion(version)
+	if key, ok := dfs.versionBlob(version); ok {
+		name = blobPath(key)
+	}
+	f, err := dfs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &aferoFileOpen{f}, nil
+}
+
+func (dfs *dedupVFS) ImportFileVersion(version *vfs.Version, content io.ReadCloser) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+
+	diskQuota := dfs.DiskQuota()
+	if diskQuota > 0 {
+		diskUsage, err := dfs.DiskUsage()
+		if err != nil {
+			return err
+		}
+		if diskUsage+version.ByteSize > diskQuota {
+			return vfs.ErrFileTooBig
+		}
+	}
+
+	// Even if the blob is already known, the content is read to be compared
+	// with the blob by storeBlob.
+	f, err := afero.TempFile(dfs.fs, "/", "version")
+	if err != nil {
+		_ = content.Close()
+		return err
+	}
+	tmppath := path.Join("/", f.Name())
+	h := md5.New()
+	_, err = io.Copy(io.MultiWriter(f, h), content)
+	if errc := content.Close(); err == nil {
+		err = errc
+	}
+	if errc := f.Close(); err == nil {
+		err = errc
+	}
+	md5sum := h.Sum(nil)
+	if err == nil && version.MD5Sum == nil {
+		version.MD5Sum = md5sum
+	}
+	if err == nil && !bytes.Equal(version.MD5Sum, md5sum) {
+		err = vfs.ErrInvalidHash
+	}
+	if err == nil {
+		err = dfs.storeBlob(tmppath, hex.EncodeToString(md5sum))
+	}
+	if err != nil {
+		// remove the temporary file if an error occurred
+		_ = dfs.fs.Remove(tmppath)
+		return err
+	}
+
+	if err = dfs.Indexer.CreateVersion(version); err != nil {
+		_ = dfs.decref(hex.EncodeToString(md5sum))
+		return err
+	}
+	return nil
+}
+
+func (dfs *dedupVFS) RevertFileVersion(doc *vfs.FileDoc, version *vfs.Version) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+
+	key, ok := dfs.versionBlob(version)
+	if !ok {
+		key = hex.EncodeToString(version.MD5Sum)
+		if err := dfs.storeBlob(pathForVersion(version), key); err != nil {
+			return err
+		}
+	}
+
+	var mainpath string
+	if _, ok := fileBlob(doc); !ok {
+		var err error
+		if mainpath, err = dfs.Indexer.FilePath(doc); err != nil {
+			return err
+		}
+	}
+
+	save := vfs.NewVersion(doc)
+	newdoc := doc.Clone().(*vfs.FileDoc)
+	vfs.SetMetaFromVersion(newdoc, version)
+	newdoc.InternalID = key
+	if err := dfs.Indexer.UpdateFileDoc(doc, newdoc); err != nil {
+		return err
+	}
+
+	// The reference of the version to its blob is now used by the file
+	_ = dfs.Indexer.DeleteVersion(version)
+
+	saveKey, ok := fileBlob(doc)
+	if !ok {
+		saveKey = hex.EncodeToString(doc.MD5Sum)
+		if err := dfs.storeBlob(mainpath, saveKey); err != nil {
+			_ = dfs.fs.Remove(mainpath)
+			return nil
+		}
+	}
+	if err := dfs.Indexer.CreateVersion(save); err != nil {
+		_ = dfs.decref(saveKey)
+	}
+	return nil
+}
+
+// UpdateFileDoc overrides the aferoVFS one, as moving a file whose content is
+// in a blob is just a change in the index.
+//
+// @override Indexer.UpdateFileDoc
+func (dfs *dedupVFS) UpdateFileDoc(olddoc, newdoc *vfs.FileDoc) error {
+	if _, ok := fileBlob(olddoc); !ok {
+		return dfs.aferoVFS.UpdateFileDoc(olddoc, newdoc)
+	}
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+	return dfs.Indexer.UpdateFileDoc(olddoc, newdoc)
+}
+
+func (dfs *dedupVFS) CleanOldVersion(fileID string, version *vfs.Version) error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+	return dfs.cleanOldVersion(version)
+}
+
+func (dfs *dedupVFS) cleanOldVersion(version *vfs.Version) error {
+	key, ok := dfs.versionBlob(version)
+	if err := dfs.Indexer.DeleteVersion(version); err != nil {
+		return err
+	}
+	if !ok {
+		return dfs.fs.Remove(pathForVersion(version))
+	}
+	return dfs.decref(key)
+}
+
+func (dfs *dedupVFS) ClearOldVersions() error {
+	if lockerr := dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer dfs.mu.Unlock()
+	versions, err := dfs.Indexer.AllVersions()
+	if err != nil {
+		return err
+	}
+	if err := dfs.Indexer.BatchDeleteVersions(versions); err != nil {
+		return err
+	}
+	dfs.releaseVersions(versions)
+	return dfs.fs.RemoveAll(vfs.VersionsDirName)
+}
+
+// releaseVersions removes the references of the given versions to their
+// blobs. The versions still stored at their path are not removed. It must be
+// called with the VFS lock.
+func (dfs *dedupVFS) releaseVersions(versions []*vfs.Version) {
+	for _, v := range versions {
+		if key, ok := dfs.versionBlob(v); ok {
+			_ = dfs.decref(key)
+		}
+	}
+}
+
+// fileBlob returns the name of the blob used by the file, or false if its
+// content is stored at its path.
+func fileBlob(doc *vfs.FileDoc) (string, bool) {
+	return blobKey(doc.InternalID, doc.MD5Sum)
+}
+
+func blobKey(internalID string, md5sum []byte) (string, bool) {
+	if len(md5sum) != md5.Size || internalID != hex.EncodeToString(md5sum) {
+		return "", false
+	}
+	return internalID, true
+}
+
+// versionBlob returns the name of the blob used by the version, or false if
+// its content is stored at its path.
+func (dfs *dedupVFS) versionBlob(v *vfs.Version) (string, bool) {
+	if len(v.MD5Sum) != md5.Size {
+		return "", false
+	}
+	if _, err := dfs.fs.Stat(pathForVersion(v)); err == nil {
+		return "", false
+	}
+	return hex.EncodeToString(v.MD5Sum), true
+}
+
+func blobPath(key string) string {
+	// Avoid too many files in the same directory by using some sub-directories
+	return path.Join(BlobsDirName, key[:2], key[2:])
+}
+
+func refsPath(key string) string {
+	return blobPath(key) + ".refs"
+}
+
+// storeBlob moves the file at the given path to the blob, or removes it if the
+// blob already exists, and adds a reference to this blob. As the blobs are
+// named after the md5sum, the bytes are compared before reusing an existing
+// blob, and vfs.ErrConflict is returned for a collision.
+func (dfs *dedupVFS) storeBlob(from, key string) error {
+	if len(key) != 2*md5.Size {
+		return vfs.ErrInvalidHash
+	}
+	if _, err := dfs.fs.Stat(blobPath(key)); err == nil {
+		same, err := sameContent(dfs.fs, from, dfs.fs, blobPath(key))
+		if err != nil {
+			return err
+		}
+		if !same {
+			return vfs.ErrConflict
+		}
+		if err = dfs.incref(key); err != nil {
+			return err
+		}
+		_ = dfs.fs.Remove(from)
+		return nil
+	}
+	_ = dfs.fs.MkdirAll(path.Dir(blobPath(key)), 0755)
+	if err := dfs.fs.Rename(from, blobPath(key)); err != nil {
+		return err
+	}
+	return dfs.writeRefs(key, 1)
+}
+
+// sameContent returns true if the two files have the same bytes.
+func sameContent(fsA afero.Fs, pathA string, fsB afero.Fs, pathB string) (bool, error) {
+	a, err := fsA.Open(pathA)
+	if err != nil {
+		return false, err
+	}
+	defer a.Close()
+	b, err := fsB.Open(pathB)
+	if err != nil {
+		return false, err
+	}
+	defer b.Close()
+
+	bufA := make([]byte, 32*1024)
+	bufB := make([]byte, 32*1024)
+	for {
+		nA, errA := io.ReadFull(a, bufA)
+		nB, errB := io.ReadFull(b, bufB)
+		if !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+		endA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		endB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if errA != nil && !endA {
+			return false, errA
+		}
+		if errB != nil && !endB {
+			return false, errB
+		}
+		if endA || endB {
+			return endA == endB, nil
+		}
+	}
+}
+
+func (dfs *dedupVFS) readRefs(key string) (int, error) {
+	buf, err := afero.ReadFile(dfs.fs, refsPath(key))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(buf)))
+}
+
+func (dfs *dedupVFS) writeRefs(key string, refs int) error {
+	return afero.WriteFile(dfs.fs, refsPath(key), []byte(strconv.Itoa(refs)), 0644)
+}
+
+func (dfs *dedupVFS) incref(key string) error {
+	refs, err := dfs.readRefs(key)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return dfs.writeRefs(key, refs+1)
+}
+
+// decref removes a reference to the blob, and removes the blob if it was the
+// last one.
+func (dfs *dedupVFS) decref(key string) error {
+	refs, err := dfs.readRefs(key)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if refs > 1 {
+		return dfs.writeRefs(key, refs-1)
+	}
+	if err := dfs.fs.Remove(blobPath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := dfs.fs.Remove(refsPath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// dedupFileCreation is an aferoFileCreation where the content is moved to a
+// blob when the file is closed.
+type dedupFileCreation struct {
+	*aferoFileCreation
+	dfs *dedupVFS
+}
+
+func (f *dedupFileCreation) Close() (err error) {
+	defer func() {
+		if err != nil {
+			f.abort()
+		}
+	}()
+
+	if err = f.checkContent(); err != nil {
+		return err
+	}
+
+	if lockerr := f.dfs.mu.Lock(); lockerr != nil {
+		return lockerr
+	}
+	defer f.dfs.mu.Unlock()
+
+	err = f.dfs.indexContent(f.newdoc, f.olddoc, func(key string) error {
+		return f.dfs.storeBlob(f.tmppath, key)
+	})
+	if err != nil {
+		return err
+	}
+
+	if f.capsize > 0 && f.size >= f.capsize {
+		vfs.PushDiskQuotaAlert(f.dfs, true)
+	}
+
+	return nil
+}
+
+var (
+	_ vfs.VFS          = &dedupVFS{}
+	_ vfs.Deduplicator = &dedupVFS{}
+	_ vfs.File         = &dedupFileCreation{}
+)
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -55,6 +56,16 @@ func (afs *aferoVFS) checkFiles(
 	accumulate func(log *vfs.FsckLog),
 	failFast bool,
 ) error {
+	versions, err := afs.versionsByPath()
+	if err != nil {
+		return err
+	}
+	return afs.checkPaths(entries, versions, accumulate, failFast)
+}
+
+// versionsByPath returns the versions from the index, indexed by the path
+// where their content is stored.
+func (afs *aferoVFS) versionsByPath() (map[string]*vfs.Version, error) {
 	versions := make(map[string]*vfs.Version, 1024)
 	err := couchdb.ForeachDocs(afs, consts.FilesVersions, func(_ string, data json.RawMessage) error {
 		v := &vfs.Version{}
@@ -65,17 +76,29 @@ func (afs *aferoVFS) checkFiles(
 		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return versions, nil
+}
 
-	err = afero.Walk(afs.fs, "/", func(fullpath string, info os.FileInfo, err error) error {
+// checkPaths walks the filesystem to compare it with the given entries and
+// versions.
+func (afs *aferoVFS) checkPaths(
+	entries map[string]*vfs.TreeFile,
+	versions map[string]*vfs.Version,
+	accumulate func(log *vfs.FsckLog),
+	failFast bool,
+) error {
+	err := afero.Walk(afs.fs, "/", func(fullpath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if fullpath == vfs.WebappsDirName ||
 			fullpath == vfs.KonnectorsDirName ||
-			fullpath == vfs.ThumbsDirName {
+			fullpath == vfs.ThumbsDirName ||
+			fullpath == vfs.UploadsDirName ||
+			fullpath == BlobsDirName {
 			return filepath.SkipDir
 		}
 
@@ -199,6 +222,213 @@ func (afs *aferoVFS) checkFiles(
 	return nil
 }
 
+func (dfs *dedupVFS) Fsck(accumulate func(log *vfs.FsckLog), failFast bool) error {
+	entries := make(map[string]*vfs.TreeFile, 1024)
+	tree, err := dfs.BuildTree(func(f *vfs.TreeFile) {
+		if !f.IsOrphan {
+			entries[f.Fullpath] = f
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if err = dfs.CheckTreeIntegrity(tree, accumulate, failFast); err != nil {
+		if errors.Is(err, vfs.ErrFsckFailFast) {
+			return nil
+		}
+		return err
+	}
+	return dfs.checkFiles(entries, accumulate, failFast)
+}
+
+func (dfs *dedupVFS) CheckFilesConsistency(accumulate func(log *vfs.FsckLog), failFast bool) error {
+	entries := make(map[string]*vfs.TreeFile, 1024)
+	_, err := dfs.BuildTree(func(f *vfs.TreeFile) {
+		if !f.IsOrphan {
+			entries[f.Fullpath] = f
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return dfs.checkFiles(entries, accumulate, failFast)
+}
+
+// checkFiles checks the files and versions stored at their path like for
+// aferoVFS, and then the blobs for the other files and versions.
+func (dfs *dedupVFS) checkFiles(
+	entries map[string]*vfs.TreeFile,
+	accumulate func(log *vfs.FsckLog),
+	failFast bool,
+) error {
+	versions, err := dfs.versionsByPath()
+	if err != nil {
+		return err
+	}
+
+	blobFiles := make(map[string][]*vfs.TreeFile)
+	for fullpath, f := range entries {
+		if f.IsDir {
+			continue
+		}
+		if key, ok := blobKey(f.InternalID, f.MD5Sum); ok {
+			blobFiles[key] = append(blobFiles[key], f)
+			delete(entries, fullpath)
+		}
+	}
+	blobVersions := make(map[string][]*vfs.Version)
+	for vpath, v := range versions {
+		if key, ok := dfs.versionBlob(v); ok {
+			blobVersions[key] = append(blobVersions[key], v)
+			delete(versions, vpath)
+		}
+	}
+
+	if err = dfs.checkPaths(entries, versions, accumulate, failFast); err != nil {
+		return err
+	}
+	return dfs.checkBlobs(blobFiles, blobVersions, accumulate, failFast)
+}
+
+// checkBlobs compares the blobs with the files and versions that use them.
+func (dfs *dedupVFS) checkBlobs(
+	blobFiles map[string][]*vfs.TreeFile,
+	blobVersions map[string][]*vfs.Version,
+	accumulate func(log *vfs.FsckLog),
+	failFast bool,
+) error {
+	expected := make(map[string]int)
+	for key, files := range blobFiles {
+		expected[key] += len(files)
+	}
+	for key, versions := range blobVersions {
+		expected[key] += len(versions)
+	}
+
+	err := afero.Walk(dfs.fs, BlobsDirName, func(fullpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		if strings.HasSuffix(fullpath, ".refs") {
+			// A reference counter without its blob
+			key := path.Base(path.Dir(fullpath)) + strings.TrimSuffix(path.Base(fullpath), ".refs")
+			if _, err := dfs.fs.Stat(blobPath(key)); os.IsNotExist(err) && expected[key] == 0 {
+				refs, _ := dfs.readRefs(key)
+				accumulate(&vfs.FsckLog{
+					Type:         vfs.BlobOrphan,
+					BlobMismatch: &vfs.FsckBlobMismatch{BlobID: key, RefsStored: refs},
+				})
+				if failFast {
+					return errFailFast
+				}
+			}
+			return nil
+		}
+
+		key := path.Base(path.Dir(fullpath)) + path.Base(fullpath)
+		refs, _ := dfs.readRefs(key)
+		count, ok := expected[key]
+		delete(expected, key)
+		var logType vfs.FsckLogType
+		switch {
+		case !ok:
+			logType = vfs.BlobOrphan
+		case refs > count:
+			logType = vfs.BlobOverReferenced
+		case refs < count:
+			logType = vfs.BlobUnderReferenced
+		}
+		if logType != "" {
+			accumulate(&vfs.FsckLog{
+				Type: logType,
+				BlobMismatch: &vfs.FsckBlobMismatch{
+					BlobID:       key,
+					RefsStored:   refs,
+					RefsExpected: count,
+				},
+			})
+			if failFast {
+				return errFailFast
+			}
+		}
+		if len(blobFiles[key]) == 0 {
+			return nil
+		}
+
+		fd, err := dfs.fs.Open(fullpath)
+		if err != nil {
+			return err
+		}
+		h := md5.New()
+		if _, err = io.Copy(h, fd); err != nil {
+			fd.Close()
+			return err
+		}
+		if err = fd.Close(); err != nil {
+			return err
+		}
+		md5sum := h.Sum(nil)
+		for _, f := range blobFiles[key] {
+			if !bytes.Equal(md5sum, f.MD5Sum) || f.ByteSize != info.Size() {
+				accumulate(&vfs.FsckLog{
+					Type:    vfs.ContentMismatch,
+					IsFile:  true,
+					FileDoc: f,
+					ContentMismatch: &vfs.FsckContentMismatch{
+						SizeFile:    info.Size(),
+						SizeIndex:   f.ByteSize,
+						MD5SumFile:  md5sum,
+						MD5SumIndex: f.MD5Sum,
+					},
+				})
+				if failFast {
+					return errFailFast
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errFailFast) {
+			return nil
+		}
+		return err
+	}
+
+	// The remaining blobs are missing
+	for key := range expected {
+		for _, f := range blobFiles[key] {
+			accumulate(&vfs.FsckLog{
+				Type:    vfs.FSMissing,
+				IsFile:  true,
+				FileDoc: f,
+			})
+			if failFast {
+				return nil
+			}
+		}
+		for _, v := range blobVersions[key] {
+			accumulate(&vfs.FsckLog{
+				Type:       vfs.FSMissing,
+				IsVersion:  true,
+				VersionDoc: v,
+			})
+			if failFast {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
 func fileInfosToDirDoc(fullpath string, fileinfo os.FileInfo) *vfs.TreeFile {
 	return &vfs.TreeFile{
 		DirOrFileDoc: vfs.DirOrFileDoc{
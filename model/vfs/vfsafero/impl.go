@@ -798,53 +798,14 @@ func (f *aferoFileCreation) Write(p []byte) (int, error) {
 func (f *aferoFileCreation) Close() (err error) {
 	defer func() {
 		if err != nil {
-			// Remove the temporary file if an error occurred
-			_ = f.afs.fs.Remove(f.tmppath)
-			// If an error has occurred when creating a new file, we should
-			// also delete the file from the index.
-			if f.olddoc == nil {
-				_ = f.afs.Indexer.DeleteFileDoc(f.newdoc)
-			}
+			f.abort()
 		}
 	}()
 
-	if err = f.f.Close(); err != nil {
-		if f.meta != nil {
-			(*f.meta).Abort(err)
-		}
-		if f.err == nil {
-			f.err = err
-		}
-	}
-
-	newdoc, olddoc, written := f.newdoc, f.olddoc, f.w
-
-	if f.meta != nil {
-		if errc := (*f.meta).Close(); errc == nil {
-			vfs.MergeMetadata(newdoc, (*f.meta).Result())
-		}
-	}
-
-	if f.err != nil {
-		return f.err
-	}
-
-	md5sum := f.hash.Sum(nil)
-	if newdoc.MD5Sum == nil {
-		newdoc.MD5Sum = md5sum
-	}
-
-	if !bytes.Equal(newdoc.MD5Sum, md5sum) {
-		return vfs.ErrInvalidHash
-	}
-
-	if f.size < 0 {
-		newdoc.ByteSize = written
-	}
-
-	if newdoc.ByteSize != written {
-		return vfs.ErrContentLengthMismatch
+	if err = f.checkContent(); err != nil {
+		return err
 	}
+	newdoc, olddoc := f.newdoc, f.olddoc
 
 	lockerr := f.afs.mu.Lock()
 	if lockerr != nil {
@@ -931,6 +892,61 @@ func (f *aferoFileCreation) Close() (err error) {
 	return nil
 }
 
+// checkContent closes the temporary file, and checks that the content that
+// has been written matches the size and md5sum announced for the new
+// document.
+func (f *aferoFileCreation) checkContent() error {
+	if err := f.f.Close(); err != nil {
+		if f.meta != nil {
+			(*f.meta).Abort(err)
+		}
+		if f.err == nil {
+			f.err = err
+		}
+	}
+
+	newdoc, written := f.newdoc, f.w
+
+	if f.meta != nil {
+		if errc := (*f.meta).Close(); errc == nil {
+			vfs.MergeMetadata(newdoc, (*f.meta).Result())
+		}
+	}
+
+	if f.err != nil {
+		return f.err
+	}
+
+	md5sum := f.hash.Sum(nil)
+	if newdoc.MD5Sum == nil {
+		newdoc.MD5Sum = md5sum
+	}
+
+	if !bytes.Equal(newdoc.MD5Sum, md5sum) {
+		return vfs.ErrInvalidHash
+	}
+
+	if f.size < 0 {
+		newdoc.ByteSize = written
+	}
+
+	if newdoc.ByteSize != written {
+		return vfs.ErrContentLengthMismatch
+	}
+	return nil
+}
+
+// abort removes the temporary file, and the document from the index if it
+// was a new file.
+func (f *aferoFileCreation) abort() {
+	_ = f.afs.fs.Remove(f.tmppath)
+	// If an error has occurred when creating a new file, we should
+	// also delete the file from the index.
+	if f.olddoc == nil {
+		_ = f.afs.Indexer.DeleteFileDoc(f.newdoc)
+	}
+}
+
 func safeRenameFile(fs afero.Fs, oldpath, newpath string) error {
 	newpath = path.Clean(newpath)
 	oldpath = path.Clean(oldpath)
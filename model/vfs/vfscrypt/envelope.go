@@ -0,0 +1,313 @@
+package vfscrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// The encrypted files start with a header, followed by the content split in
+// chunks. Each chunk is encrypted with AES-256-GCM by the data key of the
+// file, and the data key is sealed in the header by the storage key of the
+// instance:
+//
+//	magic (8 bytes) | format (1 byte) | reserved (3 bytes) | key version (4 bytes)
+//	nonce (12 bytes) | sealed data key (32 bytes) | tag (16 bytes)
+//	chunk 0 | chunk 1 | ... | last chunk
+//
+// A chunk has ChunkSize bytes of plaintext and a tag of 16 bytes, except the
+// last one that can be shorter. The index of the chunk is used as the nonce,
+// and a flag for the last chunk is authenticated, so that the chunks can't be
+// reordered and the content can't be truncated.
+const (
+	// ChunkSize is the size of the plaintext of a chunk.
+	ChunkSize = 64 * 1024
+	// HeaderSize is the size of the header of an encrypted file.
+	HeaderSize = prefixSize + nonceSize + dataKeySize + tagSize
+
+	formatVersion = 1
+	prefixSize    = 16
+	nonceSize     = 12
+	tagSize       = 16
+	dataKeySize   = 32
+
+	encryptedChunkSize = ChunkSize + tagSize
+)
+
+var magic = []byte("\x00cozyenc")
+
+var (
+	// ErrCorrupted is used when the content of an encrypted file can't be
+	// authenticated.
+	ErrCorrupted = errors.New("vfscrypt: the encrypted content is corrupted")
+	// ErrUnknownKey is used when the storage key used for a file is not
+	// known.
+	ErrUnknownKey = errors.New("vfscrypt: unknown storage key")
+)
+
+// header is the parsed header of an encrypted file.
+type header struct {
+	version uint32
+	sealed  []byte // nonce + sealed data key + tag
+}
+
+// isEncrypted returns true if the given bytes starts with the header of an
+// encrypted file.
+func isEncrypted(start []byte) bool {
+	return len(start) >= HeaderSize && bytes.Equal(start[:len(magic)], magic)
+}
+
+func parseHeader(buf []byte) (*header, error) {
+	if !isEncrypted(buf) || buf[len(magic)] != formatVersion {
+		return nil, ErrCorrupted
+	}
+	return &header{
+		version: binary.BigEndian.Uint32(buf[12:prefixSize]),
+		sealed:  buf[prefixSize:HeaderSize],
+	}, nil
+}
+
+// sealHeader generates the header for the data key, sealed with the storage
+// key of the given version.
+func sealHeader(version uint32, storageKey *[32]byte, dataKey []byte) ([]byte, error) {
+	buf := make([]byte, prefixSize, HeaderSize)
+	copy(buf, magic)
+	buf[len(magic)] = formatVersion
+	binary.BigEndian.PutUint32(buf[12:prefixSize], version)
+
+	aead, err := newAEAD(storageKey[:])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	buf = append(buf, nonce...)
+	return aead.Seal(buf, nonce, dataKey, buf[:prefixSize]), nil
+}
+
+// openHeader returns the data key of the header.
+func openHeader(buf []byte, h *header, storageKey *[32]byte) ([]byte, error) {
+	aead, err := newAEAD(storageKey[:])
+	if err != nil {
+		return nil, err
+	}
+	nonce, sealed := h.sealed[:nonceSize], h.sealed[nonceSize:]
+	dataKey, err := aead.Open(nil, nonce, sealed, buf[:prefixSize])
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(index int64) []byte {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce[4:], uint64(index))
+	return nonce
+}
+
+func chunkAAD(index int64, last bool) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, uint64(index))
+	if last {
+		aad[8] = 1
+	}
+	return aad
+}
+
+// PlaintextSize returns the size of the content of an encrypted file from the
+// size of the file on the storage.
+func PlaintextSize(size int64) (int64, error) {
+	n := size - HeaderSize
+	if n < tagSize {
+		return 0, ErrCorrupted
+	}
+	full, rem := n/encryptedChunkSize, n%encryptedChunkSize
+	if rem == 0 {
+		return full * ChunkSize, nil
+	}
+	if rem < tagSize {
+		return 0, ErrCorrupted
+	}
+	return full*ChunkSize + rem - tagSize, nil
+}
+
+// writer encrypts the content written to it, chunk by chunk. The last chunk
+// is written on Close.
+type writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	index   int64
+	written int64
+	err     error
+}
+
+func newWriter(w io.Writer, dataKey []byte) (*writer, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{w: w, aead: aead, buf: make([]byte, 0, ChunkSize)}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full chunk is written only when more data comes, as we don't
+		// know before if it is the last one.
+		if len(w.buf) == ChunkSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		m := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	w.written += int64(n)
+	return n, nil
+}
+
+func (w *writer) flush(last bool) error {
+	nonce := chunkNonce(w.index)
+	out := w.aead.Seal(nil, nonce, w.buf, chunkAAD(w.index, last))
+	if _, err := w.w.Write(out); err != nil {
+		w.err = err
+		return err
+	}
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	err := w.flush(true)
+	w.err = errors.New("vfscrypt: writer closed")
+	return err
+}
+
+// reader decrypts the content of an encrypted file. It supports random
+// access, which is needed for range requests and archives.
+type reader struct {
+	r       io.ReaderAt
+	aead    cipher.AEAD
+	size    int64 // plaintext size
+	nchunks int64
+	pos     int64
+
+	mu     sync.Mutex
+	cached int64
+	chunk  []byte
+}
+
+func newReader(r io.ReaderAt, dataKey []byte, encryptedSize int64) (*reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	size, err := PlaintextSize(encryptedSize)
+	if err != nil {
+		return nil, err
+	}
+	nchunks := (size + ChunkSize - 1) / ChunkSize
+	if nchunks == 0 {
+		nchunks = 1
+	}
+	return &reader{r: r, aead: aead, size: size, nchunks: nchunks, cached: -1}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.pos)
+	r.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("vfscrypt: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for n < len(p) && off < r.size {
+		index := off / ChunkSize
+		chunk, err := r.readChunk(index)
+		if err != nil {
+			return n, err
+		}
+		m := copy(p[n:], chunk[off-index*ChunkSize:])
+		n += m
+		off += int64(m)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("vfscrypt: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("vfscrypt: negative position")
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+func (r *reader) readChunk(index int64) ([]byte, error) {
+	if r.cached == index {
+		return r.chunk, nil
+	}
+	last := index == r.nchunks-1
+	length := int64(encryptedChunkSize)
+	if last {
+		length = r.size - index*ChunkSize + tagSize
+	}
+	buf := make([]byte, length)
+	if _, err := r.r.ReadAt(buf, HeaderSize+index*encryptedChunkSize); err != nil && err != io.EOF {
+		return nil, err
+	}
+	chunk, err := r.aead.Open(buf[:0], chunkNonce(index), buf, chunkAAD(index, last))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	r.cached = index
+	r.chunk = chunk
+	return chunk, nil
+}
@@ -0,0 +1,289 @@
+// Package vfscrypt is used for the encryption at rest of the content of the
+// files. It offers an afero.Fs that encrypts the files when they are written
+// on the underlying storage, and decrypts them when they are read. Each file
+// has its own random data key, which is sealed by a storage key of the
+// instance, so that a rotation of the storage key only has to rewrite the
+// headers of the files, not their content.
+//
+// The files written before the encryption was enabled don't have the header,
+// and they are read as they are.
+package vfscrypt
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+	"path"
+
+	"github.com/spf13/afero"
+)
+
+// Keys is the interface for accessing the storage keys of an instance.
+type Keys interface {
+	// CurrentStorageKey returns the storage key that must be used for the new
+	// files, with its version.
+	CurrentStorageKey() (uint32, *[32]byte, error)
+	// StorageKey returns the storage key for the given version.
+	StorageKey(version uint32) (*[32]byte, error)
+}
+
+// Fs is an afero.Fs that encrypts the content of the files on the base Fs.
+type Fs struct {
+	afero.Fs
+	keys Keys
+}
+
+// NewFs returns an afero.Fs that encrypts the files on the given Fs with the
+// storage keys.
+func NewFs(base afero.Fs, keys Keys) *Fs {
+	return &Fs{Fs: base, keys: keys}
+}
+
+// Base returns the underlying Fs, where the files are encrypted.
+func (fs *Fs) Base() afero.Fs { return fs.Fs }
+
+// Name implements the afero.Fs interface.
+func (fs *Fs) Name() string { return "vfscrypt(" + fs.Fs.Name() + ")" }
+
+// Create implements the afero.Fs interface.
+func (fs *Fs) Create(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// Open implements the afero.Fs interface.
+func (fs *Fs) Open(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile implements the afero.Fs interface. A file can be opened for
+// writing only if its content is replaced: the encrypted files can't be
+// modified in place.
+func (fs *Fs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return fs.openForReading(name, flag, perm)
+	}
+	if flag&os.O_APPEND != 0 || flag&(os.O_TRUNC|os.O_EXCL) == 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrInvalid}
+	}
+
+	version, storageKey, err := fs.keys.CurrentStorageKey()
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	hdr, err := sealHeader(version, storageKey, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	w, err := newWriter(nil, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := fs.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(hdr); err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.w = f
+	return &writeFile{File: f, w: w}, nil
+}
+
+func (fs *Fs) openForReading(name string, flag int, perm os.FileMode) (afero.File, error) {
+	f, err := fs.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if infos.IsDir() {
+		return &dirFile{File: f, fs: fs, name: name}, nil
+	}
+
+	hdr, err := readHeader(f, infos.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if hdr == nil {
+		return f, nil
+	}
+	r, err := fs.newReader(f, hdr, infos.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &readFile{File: f, r: r}, nil
+}
+
+func (fs *Fs) newReader(f io.ReaderAt, buf []byte, size int64) (*reader, error) {
+	hdr, err := parseHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	storageKey, err := fs.keys.StorageKey(hdr.version)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := openHeader(buf, hdr, storageKey)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(f, dataKey, size)
+}
+
+// readHeader returns the header of the file, or nil if the file is not
+// encrypted.
+func readHeader(f io.ReaderAt, size int64) ([]byte, error) {
+	if size < HeaderSize {
+		return nil, nil
+	}
+	buf := make([]byte, HeaderSize)
+	if _, err := f.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !isEncrypted(buf) {
+		return nil, nil
+	}
+	return buf, nil
+}
+
+// Stat implements the afero.Fs interface. The size of the encrypted files is
+// the size of their plaintext.
+func (fs *Fs) Stat(name string) (os.FileInfo, error) {
+	infos, err := fs.Fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.plaintextInfos(name, infos)
+}
+
+func (fs *Fs) plaintextInfos(name string, infos os.FileInfo) (os.FileInfo, error) {
+	if !infos.Mode().IsRegular() || infos.Size() < HeaderSize {
+		return infos, nil
+	}
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hdr, err := readHeader(f, infos.Size())
+	if err != nil || hdr == nil {
+		return infos, err
+	}
+	size, err := PlaintextSize(infos.Size())
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{FileInfo: infos, size: size}, nil
+}
+
+type fileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (fi *fileInfo) Size() int64 { return fi.size }
+
+// readFile is an encrypted file opened for reading.
+type readFile struct {
+	afero.File
+	r *reader
+}
+
+func (f *readFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+
+func (f *readFile) ReadAt(p []byte, off int64) (int, error) { return f.r.ReadAt(p, off) }
+
+func (f *readFile) Seek(offset int64, whence int) (int64, error) {
+	return f.r.Seek(offset, whence)
+}
+
+func (f *readFile) Stat() (os.FileInfo, error) {
+	infos, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{FileInfo: infos, size: f.r.size}, nil
+}
+
+func (f *readFile) Write(p []byte) (int, error) { return 0, f.invalid("write") }
+
+func (f *readFile) WriteAt(p []byte, off int64) (int, error) { return 0, f.invalid("write") }
+
+func (f *readFile) WriteString(s string) (int, error) { return 0, f.invalid("write") }
+
+func (f *readFile) Truncate(size int64) error { return f.invalid("truncate") }
+
+func (f *readFile) invalid(op string) error {
+	return &os.PathError{Op: op, Path: f.Name(), Err: os.ErrInvalid}
+}
+
+// writeFile is an encrypted file opened for writing. The content is written
+// sequentially, and the last chunk is written when the file is closed.
+type writeFile struct {
+	afero.File
+	w *writer
+}
+
+func (f *writeFile) Write(p []byte) (int, error) { return f.w.Write(p) }
+
+func (f *writeFile) WriteString(s string) (int, error) { return f.w.Write([]byte(s)) }
+
+func (f *writeFile) Close() error {
+	err := f.w.Close()
+	if errc := f.File.Close(); err == nil {
+		err = errc
+	}
+	return err
+}
+
+func (f *writeFile) Stat() (os.FileInfo, error) {
+	infos, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{FileInfo: infos, size: f.w.written}, nil
+}
+
+func (f *writeFile) Read(p []byte) (int, error) { return 0, f.invalid("read") }
+
+func (f *writeFile) ReadAt(p []byte, off int64) (int, error) { return 0, f.invalid("read") }
+
+func (f *writeFile) Seek(offset int64, whence int) (int64, error) { return 0, f.invalid("seek") }
+
+func (f *writeFile) WriteAt(p []byte, off int64) (int, error) { return 0, f.invalid("write") }
+
+func (f *writeFile) Truncate(size int64) error { return f.invalid("truncate") }
+
+func (f *writeFile) invalid(op string) error {
+	return &os.PathError{Op: op, Path: f.Name(), Err: os.ErrInvalid}
+}
+
+// dirFile is a directory, where the size of the encrypted files is the size
+// of their plaintext when listing it.
+type dirFile struct {
+	afero.File
+	fs   *Fs
+	name string
+}
+
+func (f *dirFile) Readdir(count int) ([]os.FileInfo, error) {
+	list, err := f.File.Readdir(count)
+	for i, infos := range list {
+		fixed, errs := f.fs.plaintextInfos(path.Join(f.name, infos.Name()), infos)
+		if errs == nil {
+			list[i] = fixed
+		}
+	}
+	return list, err
+}
@@ -0,0 +1,96 @@
+package vfscrypt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// Rewrap seals again the data key of the file with the current storage key.
+// Only the header of the file is rewritten. It returns the version of the
+// storage key used for the file, or false if the file is not encrypted.
+func (fs *Fs) Rewrap(name string) (uint32, bool, error) {
+	f, err := fs.Fs.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+	infos, err := f.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+	buf, err := readHeader(f, infos.Size())
+	if err != nil || buf == nil {
+		return 0, false, err
+	}
+	hdr, err := parseHeader(buf)
+	if err != nil {
+		return 0, false, err
+	}
+
+	version, storageKey, err := fs.keys.CurrentStorageKey()
+	if err != nil {
+		return 0, false, err
+	}
+	if hdr.version == version {
+		return version, true, nil
+	}
+	oldKey, err := fs.keys.StorageKey(hdr.version)
+	if err != nil {
+		return hdr.version, true, err
+	}
+	dataKey, err := openHeader(buf, hdr, oldKey)
+	if err != nil {
+		return hdr.version, true, err
+	}
+	rewrapped, err := sealHeader(version, storageKey, dataKey)
+	if err != nil {
+		return hdr.version, true, err
+	}
+	if _, err := f.WriteAt(rewrapped, 0); err != nil {
+		return hdr.version, true, err
+	}
+	return version, true, nil
+}
+
+// RewrapAll rewraps all the encrypted files of the Fs with the current
+// storage key. It returns the versions of the storage keys that are still
+// used by the files after that. The directories in skip are not walked.
+func (fs *Fs) RewrapAll(skip ...string) (map[uint32]bool, error) {
+	inUse := make(map[uint32]bool)
+	var errm error
+	err := afero.Walk(fs.Fs, "/", func(name string, infos os.FileInfo, err error) error {
+		if err != nil {
+			// The file may have been deleted during the walk
+			if !os.IsNotExist(err) {
+				errm = errors.Join(errm, err)
+			}
+			return nil
+		}
+		if infos.IsDir() {
+			for _, dir := range skip {
+				if name == dir {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !infos.Mode().IsRegular() {
+			return nil
+		}
+		version, encrypted, err := fs.Rewrap(name)
+		if encrypted {
+			inUse[version] = true
+		}
+		if err != nil && !os.IsNotExist(err) {
+			errm = errors.Join(errm, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return inUse, err
+	}
+	return inUse, errm
+}
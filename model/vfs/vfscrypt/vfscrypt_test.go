@@ -0,0 +1,232 @@
+package vfscrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	current uint32
+	keys    map[uint32]*[32]byte
+}
+
+func newTestKeys() *testKeys {
+	k := &testKeys{keys: make(map[uint32]*[32]byte)}
+	k.rotate()
+	return k
+}
+
+func (k *testKeys) rotate() {
+	key := new([32]byte)
+	_, _ = io.ReadFull(rand.Reader, key[:])
+	k.current++
+	k.keys[k.current] = key
+}
+
+func (k *testKeys) CurrentStorageKey() (uint32, *[32]byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *testKeys) StorageKey(version uint32) (*[32]byte, error) {
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func randomContent(t *testing.T, size int) []byte {
+	content := make([]byte, size)
+	_, err := io.ReadFull(rand.Reader, content)
+	require.NoError(t, err)
+	return content
+}
+
+func writeTestFile(t *testing.T, fs afero.Fs, name string, content []byte) {
+	f, err := fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	require.NoError(t, err)
+	// Write in small pieces to check the buffering of the chunks
+	for i := 0; i < len(content); i += 1000 {
+		end := i + 1000
+		if end > len(content) {
+			end = len(content)
+		}
+		_, err = f.Write(content[i:end])
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+}
+
+func TestRoundTrip(t *testing.T) {
+	base := afero.NewMemMapFs()
+	fs := NewFs(base, newTestKeys())
+
+	sizes := []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 42}
+	for _, size := range sizes {
+		content := randomContent(t, size)
+		name := "/file"
+		_ = fs.Remove(name)
+		writeTestFile(t, fs, name, content)
+
+		raw, err := afero.ReadFile(base, name)
+		require.NoError(t, err)
+		if size > 0 {
+			assert.NotContains(t, string(raw), string(content))
+		}
+		plainSize, err := PlaintextSize(int64(len(raw)))
+		require.NoError(t, err)
+		assert.EqualValues(t, size, plainSize)
+
+		infos, err := fs.Stat(name)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, infos.Size())
+
+		read, err := afero.ReadFile(fs, name)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(content, read), "size %d", size)
+	}
+}
+
+func TestReadAtAndSeek(t *testing.T) {
+	fs := NewFs(afero.NewMemMapFs(), newTestKeys())
+	content := randomContent(t, 2*ChunkSize+500)
+	writeTestFile(t, fs, "/file", content)
+
+	f, err := fs.Open("/file")
+	require.NoError(t, err)
+	defer f.Close()
+
+	buf := make([]byte, 1000)
+	n, err := f.ReadAt(buf, ChunkSize-300)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, n)
+	assert.Equal(t, content[ChunkSize-300:ChunkSize+700], buf)
+
+	n, err = f.ReadAt(buf, int64(len(content)-200))
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 200, n)
+	assert.Equal(t, content[len(content)-200:], buf[:n])
+
+	pos, err := f.Seek(-100, io.SeekEnd)
+	require.NoError(t, err)
+	assert.EqualValues(t, len(content)-100, pos)
+	rest, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, content[len(content)-100:], rest)
+}
+
+func TestLegacyPlaintext(t *testing.T) {
+	base := afero.NewMemMapFs()
+	content := []byte("this file was written before the encryption")
+	require.NoError(t, afero.WriteFile(base, "/legacy", content, 0644))
+
+	fs := NewFs(base, newTestKeys())
+	read, err := afero.ReadFile(fs, "/legacy")
+	require.NoError(t, err)
+	assert.Equal(t, content, read)
+
+	infos, err := fs.Stat("/legacy")
+	require.NoError(t, err)
+	assert.EqualValues(t, len(content), infos.Size())
+}
+
+func TestReaddir(t *testing.T) {
+	fs := NewFs(afero.NewMemMapFs(), newTestKeys())
+	require.NoError(t, fs.MkdirAll("/dir", 0755))
+	writeTestFile(t, fs, "/dir/file", randomContent(t, 1234))
+
+	list, err := afero.ReadDir(fs, "/dir")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.EqualValues(t, 1234, list[0].Size())
+}
+
+func TestTamperedContent(t *testing.T) {
+	base := afero.NewMemMapFs()
+	fs := NewFs(base, newTestKeys())
+	writeTestFile(t, fs, "/file", randomContent(t, ChunkSize+10))
+
+	raw, err := afero.ReadFile(base, "/file")
+	require.NoError(t, err)
+
+	tampered := append([]byte(nil), raw...)
+	tampered[HeaderSize+10] ^= 0xff
+	require.NoError(t, afero.WriteFile(base, "/file", tampered, 0644))
+	_, err = afero.ReadFile(fs, "/file")
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	// Removing the last chunk must be detected
+	truncated := raw[:HeaderSize+encryptedChunkSize]
+	require.NoError(t, afero.WriteFile(base, "/file", truncated, 0644))
+	_, err = afero.ReadFile(fs, "/file")
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestNoInPlaceModification(t *testing.T) {
+	fs := NewFs(afero.NewMemMapFs(), newTestKeys())
+	writeTestFile(t, fs, "/file", []byte("foo"))
+
+	_, err := fs.OpenFile("/file", os.O_WRONLY|os.O_APPEND, 0644)
+	assert.Error(t, err)
+	_, err = fs.OpenFile("/file", os.O_RDWR, 0644)
+	assert.Error(t, err)
+}
+
+func TestRewrapAll(t *testing.T) {
+	base := afero.NewMemMapFs()
+	keys := newTestKeys()
+	fs := NewFs(base, keys)
+
+	require.NoError(t, fs.MkdirAll("/dir", 0755))
+	content := randomContent(t, ChunkSize+10)
+	writeTestFile(t, fs, "/dir/file1", content)
+	writeTestFile(t, fs, "/file2", []byte("foo"))
+	require.NoError(t, afero.WriteFile(base, "/legacy", []byte("bar"), 0644))
+
+	keys.rotate()
+	inUse, err := fs.RewrapAll()
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32]bool{2: true}, inUse)
+
+	// The old key is no longer needed
+	delete(keys.keys, 1)
+	read, err := afero.ReadFile(fs, "/dir/file1")
+	require.NoError(t, err)
+	assert.Equal(t, content, read)
+	read, err = afero.ReadFile(fs, "/file2")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foo"), read)
+	read, err = afero.ReadFile(fs, "/legacy")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), read)
+}
+
+func TestRewrapAllSkip(t *testing.T) {
+	base := afero.NewMemMapFs()
+	keys := newTestKeys()
+	fs := NewFs(base, keys)
+
+	require.NoError(t, fs.MkdirAll("/skipped", 0755))
+	writeTestFile(t, fs, "/skipped/file", []byte("foo"))
+	writeTestFile(t, fs, "/file", []byte("bar"))
+
+	keys.rotate()
+	inUse, err := fs.RewrapAll("/skipped")
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32]bool{2: true}, inUse)
+
+	// The file in the skipped directory still needs the old key
+	delete(keys.keys, 1)
+	_, err = afero.ReadFile(fs, "/skipped/file")
+	assert.Error(t, err)
+	read, err := afero.ReadFile(fs, "/file")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), read)
+}
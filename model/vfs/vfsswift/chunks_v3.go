@@ -0,0 +1,48 @@
+package vfsswift
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/ncw/swift/v2"
+)
+
+// chunksPrefix is the prefix of the objects for the chunks of the resumable
+// uploads.
+const chunksPrefix = "uploads/"
+
+func chunkObjectName(sessionID string, index int) string {
+	return chunksPrefix + sessionID + "/" + strconv.Itoa(index)
+}
+
+// CreateChunk is required by the vfs.ChunksStorer interface.
+func (sfs *swiftVFSV3) CreateChunk(sessionID string, index int) (io.WriteCloser, error) {
+	objName := chunkObjectName(sessionID, index)
+	return sfs.c.ObjectCreate(sfs.ctx, sfs.container, objName, true, "", "application/octet-stream", nil)
+}
+
+// OpenChunk is required by the vfs.ChunksStorer interface.
+func (sfs *swiftVFSV3) OpenChunk(sessionID string, index int) (io.ReadCloser, error) {
+	f, _, err := sfs.c.ObjectOpen(sfs.ctx, sfs.container, chunkObjectName(sessionID, index), false, nil)
+	if errors.Is(err, swift.ObjectNotFound) {
+		return nil, os.ErrNotExist
+	}
+	return f, err
+}
+
+// RemoveChunks is required by the vfs.ChunksStorer interface.
+func (sfs *swiftVFSV3) RemoveChunks(sessionID string, count int) error {
+	var errm error
+	for i := 0; i < count; i++ {
+		err := sfs.c.ObjectDelete(sfs.ctx, sfs.container, chunkObjectName(sessionID, i))
+		if err != nil && !errors.Is(err, swift.ObjectNotFound) {
+			errm = errors.Join(errm, err)
+		}
+	}
+	return errm
+}
+
+var _ vfs.ChunksStorer = &swiftVFSV3{}
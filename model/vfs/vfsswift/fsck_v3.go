@@ -91,7 +91,7 @@ func (sfs *swiftVFSV3) checkFiles(
 			return nil, err
 		}
 		for _, obj := range objs {
-			if obj.Name == "avatar" {
+			if obj.Name == "avatar" || strings.HasPrefix(obj.Name, chunksPrefix) {
 				continue
 			}
 			if strings.HasPrefix(obj.Name, "thumbs/") {
@@ -0,0 +1,102 @@
+package webauthn
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// Credential is a public key credential (a passkey) that has been registered
+// by the user on an authenticator.
+type Credential struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+
+	// CredentialID is the identifier of the credential, encoded in base64url
+	CredentialID string `json:"credential_id"`
+	// PublicKey is the public key of the credential, in the COSE format
+	PublicKey []byte `json:"public_key"`
+	// SignCount is the last value of the signature counter of the
+	// authenticator, used to detect cloned authenticators
+	SignCount uint32 `json:"sign_count"`
+	// AAGUID identifies the model of the authenticator (hex encoded)
+	AAGUID     string   `json:"aaguid,omitempty"`
+	Transports []string `json:"transports,omitempty"`
+	// Name is a label chosen by the user
+	Name       string     `json:"name,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (c *Credential) ID() string { return c.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (c *Credential) Rev() string { return c.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (c *Credential) DocType() string { return consts.WebAuthnCredentials }
+
+// SetID is used to implement the couchdb.Doc interface
+func (c *Credential) SetID(id string) { c.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (c *Credential) SetRev(rev string) { c.DocRev = rev }
+
+// Clone implements couchdb.Doc
+func (c *Credential) Clone() couchdb.Doc {
+	cloned := *c
+	cloned.PublicKey = append([]byte(nil), c.PublicKey...)
+	cloned.Transports = append([]string(nil), c.Transports...)
+	if c.LastUsedAt != nil {
+		t := *c.LastUsedAt
+		cloned.LastUsedAt = &t
+	}
+	return &cloned
+}
+
+// ListCredentials returns the credentials registered on the instance.
+func ListCredentials(db prefixer.Prefixer) ([]*Credential, error) {
+	var creds []*Credential
+	err := couchdb.GetAllDocs(db, consts.WebAuthnCredentials, nil, &creds)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// GetCredential returns the credential with the given document identifier.
+func GetCredential(db prefixer.Prefixer, id string) (*Credential, error) {
+	cred := &Credential{}
+	if err := couchdb.GetDoc(db, consts.WebAuthnCredentials, id, cred); err != nil {
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return nil, ErrUnknownCredential
+		}
+		return nil, err
+	}
+	return cred, nil
+}
+
+// findCredential returns the credential with the given credential ID (not the
+// document identifier).
+func findCredential(db prefixer.Prefixer, credentialID string) (*Credential, error) {
+	creds, err := ListCredentials(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, cred := range creds {
+		if cred.CredentialID == credentialID {
+			return cred, nil
+		}
+	}
+	return nil, ErrUnknownCredential
+}
+
+// Delete removes the credential. It can no longer be used to log in.
+func (c *Credential) Delete(db prefixer.Prefixer) error {
+	return couchdb.DeleteDoc(db, c)
+}
+
+var _ couchdb.Doc = &Credential{}
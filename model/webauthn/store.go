@@ -0,0 +1,104 @@
+package webauthn
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is an object to store and retrieve the challenges of the ceremonies.
+type Store interface {
+	SaveChallenge(db prefixer.Prefixer, challenge string) error
+	CheckAndClearChallenge(db prefixer.Prefixer, challenge string) bool
+}
+
+// storeTTL is the time a challenge stay alive (5 minutes)
+var storeTTL = 5 * time.Minute
+
+// storeCleanInterval is the time interval between each cleanup.
+var storeCleanInterval = 1 * time.Minute
+
+var mu sync.Mutex
+var globalStore Store
+
+// GetStore returns the store for the challenges.
+func GetStore() Store {
+	mu.Lock()
+	defer mu.Unlock()
+	if globalStore != nil {
+		return globalStore
+	}
+	cli := config.GetConfig().SessionStorage
+	if cli == nil {
+		globalStore = newMemStore()
+	} else {
+		ctx := context.Background()
+		globalStore = &redisStore{cli, ctx}
+	}
+	return globalStore
+}
+
+func newMemStore() Store {
+	store := &memStore{vals: make(map[string]time.Time)}
+	go store.cleaner()
+	return store
+}
+
+type memStore struct {
+	mu   sync.Mutex
+	vals map[string]time.Time // challenge -> expiration time
+}
+
+func (s *memStore) cleaner() {
+	for range time.Tick(storeCleanInterval) {
+		now := time.Now()
+		s.mu.Lock()
+		for k, v := range s.vals {
+			if now.After(v) {
+				delete(s.vals, k)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *memStore) SaveChallenge(db prefixer.Prefixer, challenge string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vals[challengeKey(db, challenge)] = time.Now().Add(storeTTL)
+	return nil
+}
+
+func (s *memStore) CheckAndClearChallenge(db prefixer.Prefixer, challenge string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := challengeKey(db, challenge)
+	exp, ok := s.vals[key]
+	if !ok {
+		return false
+	}
+	delete(s.vals, key)
+	return time.Now().Before(exp)
+}
+
+type redisStore struct {
+	c   redis.UniversalClient
+	ctx context.Context
+}
+
+func (s *redisStore) SaveChallenge(db prefixer.Prefixer, challenge string) error {
+	return s.c.Set(s.ctx, challengeKey(db, challenge), "1", storeTTL).Err()
+}
+
+func (s *redisStore) CheckAndClearChallenge(db prefixer.Prefixer, challenge string) bool {
+	n, err := s.c.Del(s.ctx, challengeKey(db, challenge)).Result()
+	return err == nil && n > 0
+}
+
+func challengeKey(db prefixer.Prefixer, challenge string) string {
+	return db.DBPrefix() + ":webauthn:" + challenge
+}
@@ -0,0 +1,354 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ugorji/go/codec"
+)
+
+// COSE algorithms supported for the credentials.
+// Cf https://www.iana.org/assignments/cose/cose.xhtml#algorithms
+const (
+	algES256 = -7
+	algEdDSA = -8
+	algRS256 = -257
+)
+
+// COSE key types and curves.
+const (
+	ktyOKP     = 1
+	ktyEC2     = 2
+	ktyRSA     = 3
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// Flags of the authenticator data.
+// Cf https://www.w3.org/TR/webauthn-2/#flags
+const (
+	flagUserPresent   byte = 0x01
+	flagUserVerified  byte = 0x04
+	flagAttestedData  byte = 0x40
+	flagExtensionData byte = 0x80
+)
+
+// relyingParty is the identity of the server for the ceremonies: the RP ID is
+// the domain of the instance, and the origins are the ones of the pages where
+// the ceremonies can happen (login pages and settings app).
+type relyingParty struct {
+	ID      string
+	Origins []string
+}
+
+// clientData is the JSON object built by the browser for a ceremony.
+// Cf https://www.w3.org/TR/webauthn-2/#dictionary-client-data
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin,omitempty"`
+}
+
+// authenticatorData is described by
+// https://www.w3.org/TR/webauthn-2/#sctn-authenticator-data
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+type attestationObject struct {
+	Format       string                 `codec:"fmt"`
+	AttStatement map[string]interface{} `codec:"attStmt,omitempty"`
+	AuthData     []byte                 `codec:"authData"`
+}
+
+// verifyRegistration checks the response of the authenticator for the
+// creation of a new credential, and returns this credential.
+//
+// Only the "none" attestation is requested, so the attestation statement is
+// not verified: we don't need to know the model of the authenticator.
+func (rp *relyingParty) verifyRegistration(res *RegistrationResponse, checkChallenge func(string) bool) (*Credential, error) {
+	if err := rp.checkClientData(res.Response.ClientDataJSON, "webauthn.create", checkChallenge); err != nil {
+		return nil, err
+	}
+
+	raw, err := decodeBase64(res.Response.AttestationObject)
+	if err != nil {
+		return nil, invalidResponse("attestation object: %s", err)
+	}
+	var obj attestationObject
+	cborHandler := codec.CborHandle{}
+	if err := codec.NewDecoderBytes(raw, &cborHandler).Decode(&obj); err != nil {
+		return nil, invalidResponse("attestation object: %s", err)
+	}
+	data, err := parseAuthData(obj.AuthData)
+	if err != nil {
+		return nil, err
+	}
+	// The user verification is only preferred for the registration, as some
+	// authenticators can't do it. It is checked on login when needed.
+	if err := rp.checkAuthData(data, false); err != nil {
+		return nil, err
+	}
+	if data.Flags&flagAttestedData == 0 {
+		return nil, invalidResponse("missing attested credential data")
+	}
+	if _, _, err := parsePublicKey(data.PublicKey); err != nil {
+		return nil, err
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(data.CredentialID)
+	if res.ID != "" && res.ID != credentialID {
+		return nil, invalidResponse("credential id mismatch")
+	}
+	return &Credential{
+		CredentialID: credentialID,
+		PublicKey:    data.PublicKey,
+		SignCount:    data.SignCount,
+		AAGUID:       hex.EncodeToString(data.AAGUID),
+		Transports:   res.Response.Transports,
+	}, nil
+}
+
+// verifyAssertion checks the signature made by the authenticator with the
+// given credential, and returns the new value of the signature counter.
+func (rp *relyingParty) verifyAssertion(cred *Credential, res *AssertionResponse, requireUV bool, checkChallenge func(string) bool) (uint32, error) {
+	if err := rp.checkClientData(res.Response.ClientDataJSON, "webauthn.get", checkChallenge); err != nil {
+		return 0, err
+	}
+	clientDataJSON, err := decodeBase64(res.Response.ClientDataJSON)
+	if err != nil {
+		return 0, invalidResponse("client data: %s", err)
+	}
+	rawAuthData, err := decodeBase64(res.Response.AuthenticatorData)
+	if err != nil {
+		return 0, invalidResponse("authenticator data: %s", err)
+	}
+	data, err := parseAuthData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.checkAuthData(data, requireUV); err != nil {
+		return 0, err
+	}
+
+	sig, err := decodeBase64(res.Response.Signature)
+	if err != nil {
+		return 0, invalidResponse("signature: %s", err)
+	}
+	hash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), hash[:]...)
+	if err := verifySignature(cred.PublicKey, signed, sig); err != nil {
+		return 0, err
+	}
+
+	// When the authenticator supports a signature counter, it must always
+	// increase, else the authenticator may have been cloned.
+	if (data.SignCount != 0 || cred.SignCount != 0) && data.SignCount <= cred.SignCount {
+		return 0, ErrClonedAuthenticator
+	}
+	return data.SignCount, nil
+}
+
+func (rp *relyingParty) checkClientData(encoded, typ string, checkChallenge func(string) bool) error {
+	raw, err := decodeBase64(encoded)
+	if err != nil {
+		return invalidResponse("client data: %s", err)
+	}
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return invalidResponse("client data: %s", err)
+	}
+	if data.Type != typ {
+		return invalidResponse("unexpected type %q", data.Type)
+	}
+	if !rp.hasOrigin(data.Origin) || data.CrossOrigin {
+		return invalidResponse("unexpected origin %q", data.Origin)
+	}
+	if !checkChallenge(data.Challenge) {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
+
+func (rp *relyingParty) hasOrigin(origin string) bool {
+	for _, o := range rp.Origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func (rp *relyingParty) checkAuthData(data *authenticatorData, requireUV bool) error {
+	hash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(data.RPIDHash, hash[:]) {
+		return invalidResponse("unexpected RP ID hash")
+	}
+	if data.Flags&flagUserPresent == 0 {
+		return invalidResponse("user not present")
+	}
+	if requireUV && data.Flags&flagUserVerified == 0 {
+		return invalidResponse("user not verified")
+	}
+	return nil
+}
+
+func parseAuthData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, invalidResponse("authenticator data is too short")
+	}
+	data := &authenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	if data.Flags&flagAttestedData == 0 {
+		return data, nil
+	}
+
+	if len(raw) < 55 {
+		return nil, invalidResponse("authenticator data is too short")
+	}
+	data.AAGUID = raw[37:53]
+	idLength := int(binary.BigEndian.Uint16(raw[53:55]))
+	if len(raw) < 55+idLength {
+		return nil, invalidResponse("authenticator data is too short")
+	}
+	data.CredentialID = raw[55 : 55+idLength]
+
+	// The public key is a CBOR map, that can be followed by the extensions:
+	// it is decoded to know where it ends.
+	rest := raw[55+idLength:]
+	var key map[int]interface{}
+	cborHandler := codec.CborHandle{}
+	dec := codec.NewDecoderBytes(rest, &cborHandler)
+	if err := dec.Decode(&key); err != nil {
+		return nil, invalidResponse("credential public key: %s", err)
+	}
+	n := dec.NumBytesRead()
+	if n < len(rest) && data.Flags&flagExtensionData == 0 {
+		return nil, invalidResponse("unexpected data after the credential public key")
+	}
+	data.PublicKey = rest[:n]
+	return data, nil
+}
+
+// parsePublicKey parses a public key in the COSE format, and returns it with
+// its algorithm.
+func parsePublicKey(raw []byte) (crypto.PublicKey, int64, error) {
+	var key map[int]interface{}
+	cborHandler := codec.CborHandle{}
+	if err := codec.NewDecoderBytes(raw, &cborHandler).Decode(&key); err != nil {
+		return nil, 0, fmt.Errorf("%w: %s", ErrUnsupportedKey, err)
+	}
+	kty, _ := toInt(key[1])
+	alg, _ := toInt(key[3])
+
+	switch {
+	case kty == ktyEC2 && alg == algES256:
+		crv, _ := toInt(key[-1])
+		x, _ := key[-2].([]byte)
+		y, _ := key[-3].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, ErrUnsupportedKey
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, 0, ErrUnsupportedKey
+		}
+		return pub, alg, nil
+
+	case kty == ktyOKP && alg == algEdDSA:
+		crv, _ := toInt(key[-1])
+		x, _ := key[-2].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), alg, nil
+
+	case kty == ktyRSA && alg == algRS256:
+		n, _ := key[-1].([]byte)
+		e, _ := key[-2].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, ErrUnsupportedKey
+		}
+		exp := 0
+		for _, b := range e {
+			exp = exp<<8 | int(b)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}, alg, nil
+	}
+	return nil, 0, ErrUnsupportedKey
+}
+
+func verifySignature(rawKey, signed, sig []byte) error {
+	pub, _, err := parsePublicKey(rawKey)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(signed)
+	ok := false
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(pub, hash[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, signed, sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) == nil
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// decodeBase64 decodes the binary fields of the responses, that are encoded
+// in base64url by the browsers, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	b, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid base64url")
+	}
+	return b, nil
+}
+
+func invalidResponse(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidResponse, fmt.Sprintf(format, args...))
+}
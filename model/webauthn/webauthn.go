@@ -0,0 +1,295 @@
+// Package webauthn implements the server side of the Web Authentication API,
+// to register passkeys and use them as a second factor or for a passwordless
+// login.
+//
+// Cf https://www.w3.org/TR/webauthn-2/
+package webauthn
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+)
+
+var (
+	// ErrInvalidResponse is used when the response of the authenticator
+	// cannot be parsed or is not valid
+	ErrInvalidResponse = errors.New("webauthn: invalid response")
+	// ErrInvalidChallenge is used when the challenge is unknown or has
+	// expired
+	ErrInvalidChallenge = errors.New("webauthn: invalid or expired challenge")
+	// ErrInvalidSignature is used when the signature of an assertion is not
+	// valid
+	ErrInvalidSignature = errors.New("webauthn: invalid signature")
+	// ErrUnsupportedKey is used when the public key of a credential uses an
+	// algorithm that is not supported
+	ErrUnsupportedKey = errors.New("webauthn: unsupported public key")
+	// ErrUnknownCredential is used when the credential has not been
+	// registered on this instance
+	ErrUnknownCredential = errors.New("webauthn: unknown credential")
+	// ErrAlreadyRegistered is used when the credential is already registered
+	ErrAlreadyRegistered = errors.New("webauthn: credential already registered")
+	// ErrNoCredentials is used for a login when no passkey has been
+	// registered
+	ErrNoCredentials = errors.New("webauthn: no passkey has been registered")
+	// ErrClonedAuthenticator is used when the signature counter has not
+	// increased, which can be the sign of a cloned authenticator
+	ErrClonedAuthenticator = errors.New("webauthn: the signature counter has not increased")
+)
+
+// The values for the user verification requirement.
+const (
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+)
+
+// RelyingPartyEntity describes the server for the authenticator.
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity describes the user account for the authenticator.
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameters is an algorithm that can be used for a new credential.
+type CredentialParameters struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor identifies a credential.
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection are the requirements for the authenticator used to
+// create a new credential.
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions are the options given to navigator.credentials.create() in
+// the browser, with the binary fields encoded in base64url.
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameters `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the options given to navigator.credentials.get() in the
+// browser, with the binary fields encoded in base64url.
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse is the PublicKeyCredential returned by the browser
+// after the creation of a credential, with the binary fields encoded in
+// base64url.
+type RegistrationResponse struct {
+	ID       string                           `json:"id"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+// AuthenticatorAttestationResponse is the response of the authenticator for
+// the creation of a credential.
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// AssertionResponse is the PublicKeyCredential returned by the browser after
+// an assertion, with the binary fields encoded in base64url.
+type AssertionResponse struct {
+	ID       string                         `json:"id"`
+	Type     string                         `json:"type"`
+	Response AuthenticatorAssertionResponse `json:"response"`
+}
+
+// AuthenticatorAssertionResponse is the response of the authenticator for an
+// assertion.
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// BeginRegistration starts the ceremony for registering a new passkey.
+func BeginRegistration(inst *instance.Instance) (*CreationOptions, error) {
+	creds, err := ListCredentials(inst)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := newChallenge(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := newRelyingParty(inst)
+	displayName, _ := inst.SettingsPublicName()
+	if displayName == "" {
+		displayName = inst.Domain
+	}
+	return &CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingPartyEntity{ID: rp.ID, Name: inst.TemplateTitle()},
+		User: UserEntity{
+			ID:          userHandle(inst),
+			Name:        inst.Domain,
+			DisplayName: displayName,
+		},
+		PubKeyCredParams: []CredentialParameters{
+			{Type: "public-key", Alg: algES256},
+			{Type: "public-key", Alg: algEdDSA},
+			{Type: "public-key", Alg: algRS256},
+		},
+		Timeout:            storeTTL.Milliseconds(),
+		ExcludeCredentials: descriptors(creds),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   UserVerificationPreferred,
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration checks the response of the authenticator, and saves the
+// new passkey with the given name.
+func FinishRegistration(inst *instance.Instance, res *RegistrationResponse, name string) (*Credential, error) {
+	rp := newRelyingParty(inst)
+	cred, err := rp.verifyRegistration(res, checkChallenge(inst))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findCredential(inst, cred.CredentialID); err == nil {
+		return nil, ErrAlreadyRegistered
+	} else if !errors.Is(err, ErrUnknownCredential) {
+		return nil, err
+	}
+	cred.Name = name
+	cred.CreatedAt = time.Now().UTC()
+	if err := couchdb.CreateDoc(inst, cred); err != nil {
+		return nil, err
+	}
+	return cred, nil
+}
+
+// BeginLogin starts the ceremony for an assertion with one of the registered
+// passkeys. The user verification is required for a passwordless login, but
+// only preferred when the passkey is used as a second factor.
+func BeginLogin(inst *instance.Instance, userVerification string) (*RequestOptions, error) {
+	creds, err := ListCredentials(inst)
+	if err != nil {
+		return nil, err
+	}
+	if len(creds) == 0 {
+		return nil, ErrNoCredentials
+	}
+	challenge, err := newChallenge(inst)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             newRelyingParty(inst).ID,
+		Timeout:          storeTTL.Milliseconds(),
+		AllowCredentials: descriptors(creds),
+		UserVerification: userVerification,
+	}, nil
+}
+
+// FinishLogin checks the assertion made by the authenticator, and returns the
+// passkey that has been used.
+func FinishLogin(inst *instance.Instance, res *AssertionResponse, requireUV bool) (*Credential, error) {
+	cred, err := findCredential(inst, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Response.UserHandle != "" && res.Response.UserHandle != userHandle(inst) {
+		return nil, invalidResponse("unexpected user handle")
+	}
+	rp := newRelyingParty(inst)
+	counter, err := rp.verifyAssertion(cred, res, requireUV, checkChallenge(inst))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	cred.SignCount = counter
+	cred.LastUsedAt = &now
+	if err := couchdb.UpdateDoc(inst, cred); err != nil {
+		return nil, err
+	}
+	return cred, nil
+}
+
+func newRelyingParty(inst *instance.Instance) *relyingParty {
+	id := inst.ContextualDomain()
+	if host, _, err := net.SplitHostPort(id); err == nil {
+		id = host
+	}
+	settings := inst.SubDomain(consts.SettingsSlug)
+	settings.Path = ""
+	return &relyingParty{
+		ID:      id,
+		Origins: []string{inst.PageURL("", nil), settings.String()},
+	}
+}
+
+func newChallenge(inst *instance.Instance) (string, error) {
+	challenge := base64.RawURLEncoding.EncodeToString(crypto.GenerateRandomBytes(32))
+	if err := GetStore().SaveChallenge(inst, challenge); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+func checkChallenge(inst *instance.Instance) func(string) bool {
+	return func(challenge string) bool {
+		return GetStore().CheckAndClearChallenge(inst, challenge)
+	}
+}
+
+// userHandle is the identifier of the user for the authenticators: there is
+// only one user per instance.
+func userHandle(inst *instance.Instance) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(inst.ID()))
+}
+
+func descriptors(creds []*Credential) []CredentialDescriptor {
+	list := make([]CredentialDescriptor, 0, len(creds))
+	for _, cred := range creds {
+		list = append(list, CredentialDescriptor{
+			Type:       "public-key",
+			ID:         cred.CredentialID,
+			Transports: cred.Transports,
+		})
+	}
+	return list
+}
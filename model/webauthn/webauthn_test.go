@@ -0,0 +1,259 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+var testRP = &relyingParty{
+	ID: "alice.cozy.localhost",
+	Origins: []string{
+		"https://alice.cozy.localhost",
+		"https://alice-settings.cozy.localhost",
+	},
+}
+
+// testAuthenticator simulates an authenticator with an ES256 key.
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	counter      uint32
+	rpID         string
+	origin       string
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+	return &testAuthenticator{
+		key:          key,
+		credentialID: id,
+		rpID:         testRP.ID,
+		origin:       testRP.Origins[0],
+	}
+}
+
+func (a *testAuthenticator) coseKey(t *testing.T) []byte {
+	key := map[int]interface{}{
+		1:  ktyEC2,
+		3:  algES256,
+		-1: crvP256,
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	}
+	var raw []byte
+	cborHandler := codec.CborHandle{}
+	cborHandler.Canonical = true
+	require.NoError(t, codec.NewEncoderBytes(&raw, &cborHandler).Encode(key))
+	return raw
+}
+
+func (a *testAuthenticator) authData(t *testing.T, flags byte) []byte {
+	hash := sha256.Sum256([]byte(a.rpID))
+	data := append([]byte{}, hash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	if flags&flagAttestedData != 0 {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey(t)...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) clientData(t *testing.T, typ, challenge string) []byte {
+	raw, err := json.Marshal(clientData{Type: typ, Challenge: challenge, Origin: a.origin})
+	require.NoError(t, err)
+	return raw
+}
+
+func (a *testAuthenticator) register(t *testing.T, challenge string, flags byte) *RegistrationResponse {
+	obj := attestationObject{
+		Format:       "none",
+		AttStatement: map[string]interface{}{},
+		AuthData:     a.authData(t, flags|flagAttestedData),
+	}
+	var raw []byte
+	cborHandler := codec.CborHandle{}
+	require.NoError(t, codec.NewEncoderBytes(&raw, &cborHandler).Encode(obj))
+	enc := base64.RawURLEncoding
+	return &RegistrationResponse{
+		ID:   enc.EncodeToString(a.credentialID),
+		Type: "public-key",
+		Response: AuthenticatorAttestationResponse{
+			ClientDataJSON:    enc.EncodeToString(a.clientData(t, "webauthn.create", challenge)),
+			AttestationObject: enc.EncodeToString(raw),
+		},
+	}
+}
+
+func (a *testAuthenticator) assert(t *testing.T, challenge string, flags byte) *AssertionResponse {
+	a.counter++
+	authData := a.authData(t, flags)
+	clientDataJSON := a.clientData(t, "webauthn.get", challenge)
+	hash := sha256.Sum256(clientDataJSON)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), hash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, signed[:])
+	require.NoError(t, err)
+	enc := base64.RawURLEncoding
+	return &AssertionResponse{
+		ID:   enc.EncodeToString(a.credentialID),
+		Type: "public-key",
+		Response: AuthenticatorAssertionResponse{
+			ClientDataJSON:    enc.EncodeToString(clientDataJSON),
+			AuthenticatorData: enc.EncodeToString(authData),
+			Signature:         enc.EncodeToString(sig),
+		},
+	}
+}
+
+func acceptChallenge(expected string) func(string) bool {
+	return func(challenge string) bool { return challenge == expected }
+}
+
+func TestRegistration(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		res := a.register(t, "challenge-1", flagUserPresent|flagUserVerified)
+		cred, err := testRP.verifyRegistration(res, acceptChallenge("challenge-1"))
+		require.NoError(t, err)
+		assert.Equal(t, res.ID, cred.CredentialID)
+		assert.Equal(t, a.coseKey(t), cred.PublicKey)
+		assert.Equal(t, "00000000000000000000000000000000", cred.AAGUID)
+	})
+
+	t.Run("FromSettings", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		a.origin = "https://alice-settings.cozy.localhost"
+		res := a.register(t, "challenge-1", flagUserPresent|flagUserVerified)
+		_, err := testRP.verifyRegistration(res, acceptChallenge("challenge-1"))
+		require.NoError(t, err)
+	})
+
+	t.Run("InvalidChallenge", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		res := a.register(t, "challenge-1", flagUserPresent|flagUserVerified)
+		_, err := testRP.verifyRegistration(res, acceptChallenge("challenge-2"))
+		assert.ErrorIs(t, err, ErrInvalidChallenge)
+	})
+
+	t.Run("InvalidOrigin", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		a.origin = "https://evil.example"
+		res := a.register(t, "challenge-1", flagUserPresent|flagUserVerified)
+		_, err := testRP.verifyRegistration(res, acceptChallenge("challenge-1"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("InvalidRPID", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		a.rpID = "evil.example"
+		res := a.register(t, "challenge-1", flagUserPresent|flagUserVerified)
+		_, err := testRP.verifyRegistration(res, acceptChallenge("challenge-1"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("WithoutUserVerification", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		res := a.register(t, "challenge-1", flagUserPresent)
+		_, err := testRP.verifyRegistration(res, acceptChallenge("challenge-1"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("UserNotPresent", func(t *testing.T) {
+		a := newTestAuthenticator(t)
+		res := a.register(t, "challenge-1", flagUserVerified)
+		_, err := testRP.verifyRegistration(res, acceptChallenge("challenge-1"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestAssertion(t *testing.T) {
+	a := newTestAuthenticator(t)
+	res := a.register(t, "register", flagUserPresent|flagUserVerified)
+	cred, err := testRP.verifyRegistration(res, acceptChallenge("register"))
+	require.NoError(t, err)
+
+	t.Run("Valid", func(t *testing.T) {
+		res := a.assert(t, "login-1", flagUserPresent|flagUserVerified)
+		counter, err := testRP.verifyAssertion(cred, res, true, acceptChallenge("login-1"))
+		require.NoError(t, err)
+		assert.Equal(t, a.counter, counter)
+		cred.SignCount = counter
+	})
+
+	t.Run("SecondFactorWithoutUserVerification", func(t *testing.T) {
+		res := a.assert(t, "login-2", flagUserPresent)
+		_, err := testRP.verifyAssertion(cred, res, true, acceptChallenge("login-2"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+		counter, err := testRP.verifyAssertion(cred, res, false, acceptChallenge("login-2"))
+		require.NoError(t, err)
+		cred.SignCount = counter
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		res := a.assert(t, "login-3", flagUserPresent|flagUserVerified)
+		other := a.assert(t, "login-3", flagUserPresent|flagUserVerified)
+		res.Response.Signature = other.Response.Signature
+		_, err := testRP.verifyAssertion(cred, res, true, acceptChallenge("login-3"))
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("ClonedAuthenticator", func(t *testing.T) {
+		a.counter = cred.SignCount - 1
+		res := a.assert(t, "login-4", flagUserPresent|flagUserVerified)
+		_, err := testRP.verifyAssertion(cred, res, true, acceptChallenge("login-4"))
+		assert.ErrorIs(t, err, ErrClonedAuthenticator)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		res := a.register(t, "login-5", flagUserPresent|flagUserVerified)
+		assertion := &AssertionResponse{
+			ID: res.ID,
+			Response: AuthenticatorAssertionResponse{
+				ClientDataJSON: res.Response.ClientDataJSON,
+			},
+		}
+		_, err := testRP.verifyAssertion(cred, assertion, true, acceptChallenge("login-5"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key := map[int]interface{}{1: ktyOKP, 3: algEdDSA, -1: crvEd25519, -2: []byte(pub)}
+	var raw []byte
+	cborHandler := codec.CborHandle{}
+	require.NoError(t, codec.NewEncoderBytes(&raw, &cborHandler).Encode(key))
+
+	parsed, alg, err := parsePublicKey(raw)
+	require.NoError(t, err)
+	assert.EqualValues(t, algEdDSA, alg)
+	assert.Equal(t, pub, parsed)
+
+	sig := ed25519.Sign(priv, []byte("foo"))
+	assert.NoError(t, verifySignature(raw, []byte("foo"), sig))
+	assert.ErrorIs(t, verifySignature(raw, []byte("bar"), sig), ErrInvalidSignature)
+
+	key[3] = -36 // ES512 is not supported
+	raw = nil
+	require.NoError(t, codec.NewEncoderBytes(&raw, &cborHandler).Encode(key))
+	_, _, err = parsePublicKey(raw)
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
@@ -133,6 +133,7 @@ type Config struct {
 
 	Lock              lock.Getter
 	Limiter           *limits.RateLimiter
+	RateLimits        RateLimits
 	SessionStorage    redis.UniversalClient
 	DownloadStorage   redis.UniversalClient
 	OauthStateStorage redis.UniversalClient
@@ -157,6 +158,14 @@ type Config struct {
 	AssetsPollingInterval time.Duration
 }
 
+// RateLimits contains the configuration of the token buckets used to limit the
+// number of requests on the API, by group of routes. The limits for a group
+// can be overridden for a context.
+type RateLimits struct {
+	Groups   map[string]limits.BucketConfig
+	Contexts map[string]map[string]limits.BucketConfig
+}
+
 // ClouderyConfig for [cloudery.ClouderyService].
 type ClouderyConfig struct {
 	API ClouderyAPI `mapstructure:"api"`
@@ -174,6 +183,8 @@ type Fs struct {
 	Transport             http.RoundTripper
 	DefaultLayout         int
 	CanQueryInfo          bool
+	Dedup                 bool
+	Encryption            bool
 	AutoCleanTrashedAfter map[string]string
 	Versioning            FsVersioning
 	Contexts              map[string]interface{}
@@ -237,7 +248,7 @@ type RAGServer struct {
 }
 
 // Notifications contains the configuration for the mobile push-notification
-// center, for Android and iOS
+// center, for Android and iOS, and for the Web Push in the browsers
 type Notifications struct {
 	Development bool
 
@@ -251,6 +262,10 @@ type Notifications struct {
 	HuaweiGetTokenURL     string
 	HuaweiSendMessagesURL string
 
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+
 	Contexts map[string]SMS
 }
 
@@ -264,11 +279,25 @@ type Flagship struct {
 	AppleAppIDs                   []string
 }
 
-// SMS contains the configuration to send notifications by SMS.
+// SMS contains the configuration to send notifications by SMS. The fields
+// that are used depend on the provider.
 type SMS struct {
 	Provider string
 	URL      string
 	Token    string
+
+	// For Twilio
+	AccountSID string
+	From       string
+
+	// For the generic HTTP provider
+	Method      string
+	ContentType string
+	Headers     map[string]string
+	Body        string
+
+	// For the email-to-SMS gateway
+	Gateway string
 }
 
 // DeprecatedAppsCfg describes the config used to setup [github.com/cozy/cozy-stack/web/auth.DeprecatedAppList].
@@ -291,10 +320,16 @@ type DeprecatedApp struct {
 
 // Worker contains the configuration fields for a specific worker type.
 type Worker struct {
-	WorkerType   string
-	Concurrency  *int
-	MaxExecCount *int
-	Timeout      *time.Duration
+	WorkerType    string
+	Concurrency   *int
+	MaxExecCount  *int
+	Timeout       *time.Duration
+	RetryDelay    *time.Duration
+	MaxRetryDelay *time.Duration
+	DeadLetter    *bool
+	// MaxConcurrencyPerInstance limits the number of jobs of a single
+	// instance that can be executed at the same time.
+	MaxConcurrencyPerInstance *int
 }
 
 // GetRedis returns a [redis.UniversalClient] for the given db.
@@ -397,6 +432,21 @@ func GetRateLimiter() *limits.RateLimiter {
 	return config.Limiter
 }
 
+// GetRateLimitConfig returns the configuration of the token bucket for the
+// given group of routes and context, and false if these routes should not be
+// rate limited.
+func GetRateLimitConfig(contextName, group string) (limits.BucketConfig, bool) {
+	if contextName == "" {
+		contextName = DefaultInstanceContext
+	}
+	rl := config.RateLimits
+	if cfg, ok := rl.Contexts[contextName][group]; ok {
+		return cfg, cfg.IsValid()
+	}
+	cfg, ok := rl.Groups[group]
+	return cfg, ok && cfg.IsValid()
+}
+
 // GetOIDC returns the OIDC config for the given context (with a boolean to say
 // if OIDC is enabled).
 func GetOIDC(contextName string) (map[string]interface{}, bool) {
@@ -718,6 +768,28 @@ func UseViper(v *viper.Viper) error {
 								}
 								w.Timeout = &d
 							}
+						case "retry_delay", "max_retry_delay":
+							if delay, ok := v.(string); ok {
+								var d time.Duration
+								d, err = time.ParseDuration(delay)
+								if err != nil {
+									return fmt.Errorf("config: could not parse %s duration for worker %q: %s",
+										k, workerType, err)
+								}
+								if k == "retry_delay" {
+									w.RetryDelay = &d
+								} else {
+									w.MaxRetryDelay = &d
+								}
+							}
+						case "dead_letter":
+							if deadLetter, ok := v.(bool); ok {
+								w.DeadLetter = &deadLetter
+							}
+						case "max_concurrency_per_instance":
+							if maxPerInstance, ok := v.(int); ok {
+								w.MaxConcurrencyPerInstance = &maxPerInstance
+							}
 						default:
 							return fmt.Errorf("config: unknown key %q",
 								"jobs.workers."+workerType+"."+k)
@@ -779,6 +851,15 @@ func UseViper(v *viper.Viper) error {
 		return fmt.Errorf("failed to setup the keyring: %w", err)
 	}
 
+	if v.GetBool("fs.encryption") {
+		if fsURL.Scheme != SchemeFile && fsURL.Scheme != SchemeMem {
+			return fmt.Errorf("The encryption at rest is not supported for the %q filesystem", fsURL.Scheme)
+		}
+		if keyring.StorageMasterKey() == nil {
+			return errors.New("The encryption at rest requires a storage_master_key in the vault section")
+		}
+	}
+
 	// Setup default SMTP server
 	mail := &gomail.DialerOptions{
 		Host:                      v.GetString("mail.host"),
@@ -848,6 +929,8 @@ func UseViper(v *viper.Viper) error {
 			Transport:             fsClient.Transport,
 			DefaultLayout:         defaultLayout,
 			CanQueryInfo:          v.GetBool("fs.can_query_info"),
+			Dedup:                 v.GetBool("fs.dedup"),
+			Encryption:            v.GetBool("fs.encryption"),
 			AutoCleanTrashedAfter: v.GetStringMapString("fs.auto_clean_trashed_after"),
 			Versioning: FsVersioning{
 				MaxNumberToKeep:            v.GetInt("fs.versioning.max_number_of_versions_to_keep"),
@@ -877,6 +960,10 @@ func UseViper(v *viper.Viper) error {
 			HuaweiGetTokenURL:     v.GetString("notifications.huawei_get_token"),
 			HuaweiSendMessagesURL: v.GetString("notifications.huawei_send_message"),
 
+			VAPIDPublicKey:  v.GetString("notifications.vapid_public_key"),
+			VAPIDPrivateKey: v.GetString("notifications.vapid_private_key"),
+			VAPIDSubject:    v.GetString("notifications.vapid_subject"),
+
 			Contexts: makeSMS(v.GetStringMap("notifications.contexts")),
 		},
 		Flagship: Flagship{
@@ -891,6 +978,7 @@ func UseViper(v *viper.Viper) error {
 		SessionStorage:         sessionsRedis,
 		DownloadStorage:        downloadRedis,
 		Limiter:                limits.NewRateLimiter(rateLimitingRedis),
+		RateLimits:             makeRateLimits(v),
 		OauthStateStorage:      oauthStateRedis,
 		Realtime:               realtimeRedis,
 		CacheStorage:           cacheStorage,
@@ -1116,6 +1204,34 @@ func makeOffice(v *viper.Viper) (map[string]Office, error) {
 	return office, nil
 }
 
+func makeRateLimits(v *viper.Viper) RateLimits {
+	rl := RateLimits{
+		Groups:   make(map[string]limits.BucketConfig),
+		Contexts: make(map[string]map[string]limits.BucketConfig),
+	}
+	for group := range v.GetStringMap("rate_limits") {
+		if group == "contexts" {
+			continue
+		}
+		rl.Groups[group] = makeBucketConfig(v, "rate_limits."+group)
+	}
+	for ctx := range v.GetStringMap("rate_limits.contexts") {
+		groups := make(map[string]limits.BucketConfig)
+		for group := range v.GetStringMap("rate_limits.contexts." + ctx) {
+			groups[group] = makeBucketConfig(v, "rate_limits.contexts."+ctx+"."+group)
+		}
+		rl.Contexts[ctx] = groups
+	}
+	return rl
+}
+
+func makeBucketConfig(v *viper.Viper, key string) limits.BucketConfig {
+	return limits.BucketConfig{
+		Limit:  v.GetInt64(key + ".limit"),
+		Period: v.GetDuration(key + ".period"),
+	}
+}
+
 func makeSMS(raw map[string]interface{}) map[string]SMS {
 	sms := make(map[string]SMS)
 	for name, val := range raw {
@@ -1127,9 +1243,22 @@ func makeSMS(raw map[string]interface{}) map[string]SMS {
 		if provider == "" {
 			continue
 		}
-		url, _ := entry["url"].(string)
-		token, _ := entry["token"].(string)
-		sms[name] = SMS{Provider: provider, URL: url, Token: token}
+		cfg := SMS{Provider: provider}
+		cfg.URL, _ = entry["url"].(string)
+		cfg.Token, _ = entry["token"].(string)
+		cfg.AccountSID, _ = entry["account_sid"].(string)
+		cfg.From, _ = entry["from"].(string)
+		cfg.Method, _ = entry["method"].(string)
+		cfg.ContentType, _ = entry["content_type"].(string)
+		cfg.Body, _ = entry["body"].(string)
+		cfg.Gateway, _ = entry["gateway"].(string)
+		if headers, ok := entry["headers"].(map[string]interface{}); ok {
+			cfg.Headers = make(map[string]string, len(headers))
+			for k, v := range headers {
+				cfg.Headers[k] = fmt.Sprintf("%v", v)
+			}
+		}
+		sms[name] = cfg
 	}
 	return sms
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/limits"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/gomail"
 	"github.com/sirupsen/logrus"
@@ -51,15 +52,22 @@ func TestConfigUnmarshal(t *testing.T) {
 	// Jobs
 	one := 1
 	oneHour := time.Hour
+	oneMinute := time.Minute
+	oneSecond := time.Second
+	yes := true
 	assert.Equal(t, "some-cmd", cfg.Jobs.ImageMagickConvertCmd)
 	assert.Equal(t, "1H", cfg.Jobs.DefaultDurationToKeep)
 	assert.Equal(t, true, cfg.Jobs.AllowList)
 	assert.EqualValues(t, []Worker{
 		{
-			WorkerType:   "zip",
-			Concurrency:  &one,
-			MaxExecCount: &one,
-			Timeout:      &oneHour,
+			WorkerType:                "zip",
+			Concurrency:               &one,
+			MaxExecCount:              &one,
+			Timeout:                   &oneHour,
+			RetryDelay:                &oneSecond,
+			MaxRetryDelay:             &oneMinute,
+			DeadLetter:                &yes,
+			MaxConcurrencyPerInstance: &one,
 		},
 	}, cfg.Jobs.Workers)
 
@@ -75,12 +83,29 @@ func TestConfigUnmarshal(t *testing.T) {
 		IOSTeamID:              "team-id",
 		HuaweiGetTokenURL:      "huawei-token",
 		HuaweiSendMessagesURL:  "huawei-message",
+		VAPIDPublicKey:         "vapid-public",
+		VAPIDPrivateKey:        "vapid-private",
+		VAPIDSubject:           "mailto:admin@example.org",
 		Contexts: map[string]SMS{
 			"my-context": {
 				Provider: "notif-provider",
 				URL:      "https://some-notif-url",
 				Token:    "some-token",
 			},
+			"twilio-context": {
+				Provider:   "twilio",
+				AccountSID: "AC123",
+				Token:      "twilio-token",
+				From:       "+33600000000",
+			},
+			"http-context": {
+				Provider:    "http",
+				URL:         "https://sms.example.org/send",
+				Method:      "PUT",
+				ContentType: "application/json",
+				Headers:     map[string]string{"x-api-key": "some-key"},
+				Body:        `{"to": "{number}", "text": "{text}"}`,
+			},
 		},
 	}, cfg.Notifications)
 
@@ -207,6 +232,27 @@ func TestConfigUnmarshal(t *testing.T) {
 		"example": {u1, u2},
 	}, cfg.Registries)
 
+	// Rate limits
+	assert.EqualValues(t, RateLimits{
+		Groups: map[string]limits.BucketConfig{
+			"files": {Limit: 600, Period: time.Minute},
+			"data":  {Limit: 1200, Period: time.Minute},
+		},
+		Contexts: map[string]map[string]limits.BucketConfig{
+			"my-context": {
+				"files": {Limit: 100, Period: 10 * time.Second},
+			},
+		},
+	}, cfg.RateLimits)
+	files, ok := GetRateLimitConfig("my-context", "files")
+	assert.True(t, ok)
+	assert.EqualValues(t, 100, files.Limit)
+	data, ok := GetRateLimitConfig("my-context", "data")
+	assert.True(t, ok)
+	assert.EqualValues(t, 1200, data.Limit)
+	_, ok = GetRateLimitConfig("", "jobs")
+	assert.False(t, ok)
+
 	// Clouderies
 	assert.EqualValues(t, map[string]ClouderyConfig{
 		"default": {
@@ -35,6 +35,9 @@ const (
 	ClientsUsageID = "io.cozy.settings.clients-usage"
 	// DiskUsageID is the id of the settings JSON-API response for disk-usage
 	DiskUsageID = "io.cozy.settings.disk-usage"
+	// NotificationsPreferencesID is the id of the document with the
+	// notification preferences of the user
+	NotificationsPreferencesID = "io.cozy.notifications.preferences"
 	// InstanceSettingsID is the id of settings document for the instance
 	InstanceSettingsID = "io.cozy.settings.instance"
 	// CapabilitiesSettingsID is the id of the settings document with the
@@ -36,6 +36,10 @@ const (
 	FilesMetadata = "io.cozy.files.metadata"
 	// FilesVersions doc type for versioning file contents
 	FilesVersions = "io.cozy.files.versions"
+	// FilesUploads doc type for the sessions of resumable uploads
+	FilesUploads = "io.cozy.files.uploads"
+	// FilesSearch doc type for the full-text search index of files
+	FilesSearch = "io.cozy.files.search"
 	// FilesShortcuts doc type for high-level information about .url files
 	FilesShortcuts = "io.cozy.files.shortcuts"
 	// Thumbnails is a synthetic doctype for thumbnails, used for realtime
@@ -56,16 +60,23 @@ const (
 	Intents = "io.cozy.intents"
 	// Jobs doc type for queued jobs
 	Jobs = "io.cozy.jobs"
+	// JobsWorkflows doc type for the workflows of jobs
+	JobsWorkflows = "io.cozy.jobs.workflows"
 	// JobEvents doc type for real time events sent by jobs
 	JobEvents = "io.cozy.jobs.events"
 	// Support doc type for sending mail to the support
 	Support = "io.cozy.support"
 	// Notifications doc type for notifications
 	Notifications = "io.cozy.notifications"
+	// NotificationsPreferences doc type for the notification preferences of
+	// the user
+	NotificationsPreferences = "io.cozy.notifications.preferences"
 	// OAuthAccessCodes doc type for OAuth2 access codes
 	OAuthAccessCodes = "io.cozy.oauth.access_codes"
 	// OAuthClients doc type for OAuth2 clients
 	OAuthClients = "io.cozy.oauth.clients"
+	// WebAuthnCredentials doc type for the passkeys registered by the user
+	WebAuthnCredentials = "io.cozy.webauthn.credentials"
 	// Permissions doc type for permissions identifying a connection
 	Permissions = "io.cozy.permissions"
 	// Contacts doc type for sharing
@@ -95,6 +106,12 @@ const (
 	// SharingsInitialSync doc type for real-time events for initial sync of a
 	// sharing
 	SharingsInitialSync = "io.cozy.sharings.initial_sync"
+	// SharingsActivity doc type for the journal of the operations made by the
+	// members of a sharing
+	SharingsActivity = "io.cozy.sharings.activity"
+	// SharingsPlaceholders doc type for the files of a sharing that are not
+	// synchronized on the cozy of a recipient
+	SharingsPlaceholders = "io.cozy.sharings.placeholders"
 	// Triggers doc type for triggers, jobs launchers
 	Triggers = "io.cozy.triggers"
 	// TriggersState doc type for triggers current state, jobs launchers
@@ -116,6 +133,15 @@ const (
 	// BitwardenContacts doc type for Bitwarden users that can be added to
 	// an organization
 	BitwardenContacts = "com.bitwarden.contacts"
+	// BitwardenSends doc type for the ephemeral shares of texts and files
+	// from the vault
+	BitwardenSends = "io.cozy.bitwarden.sends"
+	// BitwardenEmergencyAccesses doc type for the trusted contacts that can
+	// ask for an access to the vault in an emergency
+	BitwardenEmergencyAccesses = "io.cozy.bitwarden.emergency_accesses"
+	// BitwardenEmergencyGrants doc type for the emergency accesses given to
+	// the user by other cozy users
+	BitwardenEmergencyGrants = "io.cozy.bitwarden.emergency_grants"
 	// NotesDocuments doc type is used for manipulating the documents that
 	// represents a note before they are persisted to a file.
 	NotesDocuments = "io.cozy.notes.documents"
@@ -21,6 +21,9 @@ const (
 	// DrivesDirID is the identifier of the directory where the
 	// (shared|external) drives are saved.
 	SharedDrivesDirID = "io.cozy.files.shared-drives-dir"
+	// BitwardenDirID is the identifier of the hidden directory where the
+	// encrypted files of the bitwarden vault (attachments) are stored.
+	BitwardenDirID = "io.cozy.files.bitwarden-dir"
 )
 
 const (
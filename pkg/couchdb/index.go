@@ -14,7 +14,7 @@ import (
 
 // IndexViewsVersion is the version of current definition of views & indexes.
 // This number should be incremented when this file changes.
-const IndexViewsVersion int = 37
+const IndexViewsVersion int = 40
 
 // Indexes is the index list required by an instance to run properly.
 var Indexes = []*mango.Index{
@@ -60,6 +60,9 @@ var Indexes = []*mango.Index{
 	// date
 	mango.MakeIndex(consts.Notifications, "by-source-id", mango.IndexDef{Fields: []string{"source_id", "created_at"}}),
 
+	// Used to find the notifications waiting for the daily digest
+	mango.MakeIndex(consts.Notifications, "by-digest", mango.IndexDef{Fields: []string{"digest", "created_at"}}),
+
 	// Used to find the myself document
 	mango.MakeIndex(consts.Contacts, "by-me", mango.IndexDef{Fields: []string{"me"}}),
 
@@ -72,6 +75,12 @@ var Indexes = []*mango.Index{
 
 	// Used to find the active sharings
 	mango.MakeIndex(consts.Sharings, "active", mango.IndexDef{Fields: []string{"active"}}),
+
+	// Used to list the activity of a sharing, from the most recent
+	mango.MakeIndex(consts.SharingsActivity, "by-sharing-id", mango.IndexDef{Fields: []string{"sharing_id", "created_at"}}),
+
+	// Used to list the placeholders of a directory excluded from a sharing
+	mango.MakeIndex(consts.SharingsPlaceholders, "by-sharing-and-dir-id", mango.IndexDef{Fields: []string{"sharing_id", "dir_id"}}),
 }
 
 // DiskUsageView is the view used for computing the disk usage for files
@@ -162,6 +171,28 @@ function(doc) {
 	Reduce: "_count",
 }
 
+// FilesSearchTermsView is the view used for the full-text search on files: it
+// is the inverted index from the terms to the files that contain them. The
+// terms from the name and tags of a file are boosted.
+var FilesSearchTermsView = &View{
+	Name:    "by-term",
+	Doctype: consts.FilesSearch,
+	Map: `
+function(doc) {
+  var terms = {};
+  var term;
+  for (term in (doc.content_terms || {})) {
+    terms[term] = doc.content_terms[term];
+  }
+  for (term in (doc.name_terms || {})) {
+    terms[term] = (terms[term] || 0) + 10 * doc.name_terms[term];
+  }
+  for (term in terms) {
+    emit(term, terms[term]);
+  }
+}`,
+}
+
 // PermissionsShareByCView is the view for fetching the permissions associated
 // to a document via a token code.
 var PermissionsShareByCView = &View{
@@ -274,6 +305,21 @@ function(doc) {
 `,
 }
 
+// JobsWaitingByDependencyView is the view used for finding the jobs that are
+// waiting for another job to be finished.
+var JobsWaitingByDependencyView = &View{
+	Name:    "waiting-by-dependency",
+	Doctype: consts.Jobs,
+	Map: `
+function(doc) {
+  if (doc.state === "waiting" && isArray(doc.depends_on)) {
+    for (var i = 0; i < doc.depends_on.length; i++) {
+      emit(doc.depends_on[i]);
+    }
+  }
+}`,
+}
+
 // Views is the list of all views that are created by the stack.
 var Views = []*View{
 	DiskUsageView,
@@ -282,6 +328,7 @@ var Views = []*View{
 	FilesReferencedByView,
 	ReferencedBySortedByDatetimeView,
 	FilesByParentView,
+	FilesSearchTermsView,
 	PermissionsShareByCView,
 	PermissionsShareByDocView,
 	PermissionsByDoctype,
@@ -289,6 +336,7 @@ var Views = []*View{
 	SharedDocsBySharingID,
 	SharingsByDocTypeView,
 	ContactByEmail,
+	JobsWaitingByDependencyView,
 }
 
 // ViewsByDoctype returns the list of views for a specified doc type.
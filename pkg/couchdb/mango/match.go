@@ -0,0 +1,394 @@
+package mango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSelector is used when a selector can't be compiled
+var ErrInvalidSelector = errors.New("mango: invalid selector")
+
+// A Matcher is a compiled selector that can be evaluated in Go against a
+// document, without asking CouchDB.
+//
+// The supported operators are $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin,
+// $exists, $type, $size, $regex, $all, $elemMatch, $and, $or, $nor and $not.
+// The values of different types are never equal, and can't be compared,
+// except for a number and a string with a number (the size of the files is
+// serialized as a string in JSON). Like in CouchDB, a missing field matches
+// only {"$exists": false}.
+type Matcher struct {
+	match predicate
+}
+
+type predicate func(value interface{}, found bool) bool
+
+// Compile checks a selector and returns a Matcher for it.
+func Compile(selector Map) (*Matcher, error) {
+	// The selector is normalized to have the same types as a document
+	// unmarshaled from JSON (float64 for the numbers for example).
+	raw, err := json.Marshal(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSelector, err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSelector, err)
+	}
+	match, err := compileSelector(normalized)
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{match: match}, nil
+}
+
+// Match returns true if the document matches the selector. The document must
+// be a map with the same types as a document unmarshaled from JSON.
+func (m *Matcher) Match(doc map[string]interface{}) bool {
+	return m.match(doc, true)
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSelector, fmt.Sprintf(format, args...))
+}
+
+// compileSelector compiles a selector for an object, like the whole document
+// or the value of a field with sub-fields.
+func compileSelector(selector map[string]interface{}) (predicate, error) {
+	preds := make([]predicate, 0, len(selector))
+	for key, value := range selector {
+		var pred predicate
+		var err error
+		if strings.HasPrefix(key, "$") {
+			pred, err = compileCombination(key, value)
+		} else {
+			pred, err = compileField(strings.Split(key, "."), value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+	return allOf(preds), nil
+}
+
+func compileCombination(op string, value interface{}) (predicate, error) {
+	if op == string(not) {
+		sub, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, invalid("%s expects an object", op)
+		}
+		pred, err := compileSelector(sub)
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, found bool) bool { return !pred(v, found) }, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, invalid("%s expects an array", op)
+	}
+	preds := make([]predicate, len(list))
+	for i, item := range list {
+		sub, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, invalid("%s expects an array of objects", op)
+		}
+		pred, err := compileSelector(sub)
+		if err != nil {
+			return nil, err
+		}
+		preds[i] = pred
+	}
+	switch op {
+	case string(and):
+		return allOf(preds), nil
+	case string(or):
+		return anyOf(preds), nil
+	case string(nor):
+		pred := anyOf(preds)
+		return func(v interface{}, found bool) bool { return !pred(v, found) }, nil
+	}
+	return nil, invalid("unknown operator %s", op)
+}
+
+// compileField compiles the condition for the field at the given path.
+func compileField(path []string, condition interface{}) (predicate, error) {
+	pred, err := compileCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	return func(v interface{}, found bool) bool {
+		for _, key := range path {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				v, found = nil, false
+				break
+			}
+			v, found = obj[key]
+		}
+		return pred(v, found)
+	}, nil
+}
+
+// compileCondition compiles the condition on the value of a field: it can
+// be an implicit $eq, a map of operators, or a selector on the sub-fields.
+func compileCondition(condition interface{}) (predicate, error) {
+	obj, ok := condition.(map[string]interface{})
+	if !ok {
+		return compileOperator(string(eq), condition)
+	}
+	hasOps := false
+	for key := range obj {
+		if strings.HasPrefix(key, "$") {
+			hasOps = true
+			break
+		}
+	}
+	if !hasOps {
+		return compileSelector(obj)
+	}
+	preds := make([]predicate, 0, len(obj))
+	for op, arg := range obj {
+		pred, err := compileOperator(op, arg)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+	return allOf(preds), nil
+}
+
+// eq ($eq) checks that field == value
+const eq ValueOperator = "$eq"
+
+func compileOperator(op string, arg interface{}) (predicate, error) {
+	switch ValueOperator(op) {
+	case eq:
+		return func(v interface{}, found bool) bool {
+			return found && equal(v, arg)
+		}, nil
+	case ne:
+		return func(v interface{}, found bool) bool {
+			return found && !equal(v, arg)
+		}, nil
+	case gt, gte, lt, lte:
+		if _, ok := orderable(arg); !ok {
+			return nil, invalid("%s expects a number or a string", op)
+		}
+		return func(v interface{}, found bool) bool {
+			c, ok := compare(v, arg)
+			if !found || !ok {
+				return false
+			}
+			switch ValueOperator(op) {
+			case gt:
+				return c > 0
+			case gte:
+				return c >= 0
+			case lt:
+				return c < 0
+			default:
+				return c <= 0
+			}
+		}, nil
+	case in, "$nin":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return nil, invalid("%s expects an array", op)
+		}
+		isIn := func(v interface{}) bool {
+			for _, item := range list {
+				if equal(v, item) {
+					return true
+				}
+			}
+			return false
+		}
+		if op == string(in) {
+			return func(v interface{}, found bool) bool { return found && isIn(v) }, nil
+		}
+		return func(v interface{}, found bool) bool { return found && !isIn(v) }, nil
+	case exists:
+		want, ok := arg.(bool)
+		if !ok {
+			return nil, invalid("%s expects a boolean", op)
+		}
+		return func(_ interface{}, found bool) bool { return found == want }, nil
+	case "$type":
+		want, ok := arg.(string)
+		if !ok {
+			return nil, invalid("%s expects a string", op)
+		}
+		return func(v interface{}, found bool) bool { return found && typeOf(v) == want }, nil
+	case "$size":
+		size, ok := arg.(float64)
+		if !ok {
+			return nil, invalid("%s expects a number", op)
+		}
+		return func(v interface{}, found bool) bool {
+			list, ok := v.([]interface{})
+			return found && ok && float64(len(list)) == size
+		}, nil
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return nil, invalid("%s expects a string", op)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, invalid("%s: %s", op, err)
+		}
+		return func(v interface{}, found bool) bool {
+			s, ok := v.(string)
+			return found && ok && re.MatchString(s)
+		}, nil
+	case "$all":
+		wanted, ok := arg.([]interface{})
+		if !ok {
+			return nil, invalid("%s expects an array", op)
+		}
+		return func(v interface{}, found bool) bool {
+			list, ok := v.([]interface{})
+			if !found || !ok {
+				return false
+			}
+			for _, w := range wanted {
+				contained := false
+				for _, item := range list {
+					if equal(item, w) {
+						contained = true
+						break
+					}
+				}
+				if !contained {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case "$elemMatch":
+		pred, err := compileCondition(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, found bool) bool {
+			list, ok := v.([]interface{})
+			if !found || !ok {
+				return false
+			}
+			for _, item := range list {
+				if pred(item, true) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case ValueOperator(not):
+		pred, err := compileCondition(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, found bool) bool { return !pred(v, found) }, nil
+	}
+	return nil, invalid("unknown operator %s", op)
+}
+
+func allOf(preds []predicate) predicate {
+	return func(v interface{}, found bool) bool {
+		for _, pred := range preds {
+			if !pred(v, found) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func anyOf(preds []predicate) predicate {
+	return func(v interface{}, found bool) bool {
+		for _, pred := range preds {
+			if pred(v, found) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return ""
+}
+
+func equal(a, b interface{}) bool {
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// orderable returns the value as a float64 or a string, if it can be
+// compared with the $gt/$lt operators.
+func orderable(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case float64, string:
+		return v, true
+	}
+	return nil, false
+}
+
+// compare returns -1, 0 or 1 when a is lower, equal or greater than b, and
+// false if the two values can't be compared.
+func compare(a, b interface{}) (int, bool) {
+	a, okA := orderable(a)
+	b, okB := orderable(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	sa, isStrA := a.(string)
+	sb, isStrB := b.(string)
+	switch {
+	case isStrA && isStrB:
+		return strings.Compare(sa, sb), true
+	case isStrA:
+		f, err := strconv.ParseFloat(sa, 64)
+		if err != nil {
+			return 0, false
+		}
+		a = f
+	case isStrB:
+		f, err := strconv.ParseFloat(sb, 64)
+		if err != nil {
+			return 0, false
+		}
+		b = f
+	}
+	fa, fb := a.(float64), b.(float64)
+	switch {
+	case fa < fb:
+		return -1, true
+	case fa > fb:
+		return 1, true
+	}
+	return 0, true
+}
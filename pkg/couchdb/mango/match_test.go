@@ -0,0 +1,59 @@
+package mango
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "file",
+		"class": "image",
+		"size": "2097152",
+		"tags": ["holidays", "beach"],
+		"metadata": {"datetime": "2024-07-14T10:00:00Z", "width": 1920}
+	}`), &doc))
+
+	cases := []struct {
+		selector Map
+		expected bool
+	}{
+		{Map{"class": "image"}, true},
+		{Map{"class": "video"}, false},
+		{Map{"class": "image", "size": Map{"$gt": 1048576}}, true},
+		{Map{"class": "image", "size": Map{"$gt": 4194304}}, false},
+		{Map{"metadata.width": Map{"$gte": 1920, "$lt": 4000}}, true},
+		{Map{"metadata": Map{"width": 1920}}, true},
+		{Map{"metadata.height": Map{"$exists": false}}, true},
+		{Map{"metadata.height": Map{"$ne": 1080}}, false},
+		{Map{"class": Map{"$in": []string{"image", "video"}}}, true},
+		{Map{"class": Map{"$nin": []string{"image", "video"}}}, false},
+		{Map{"tags": Map{"$all": []string{"beach"}}}, true},
+		{Map{"tags": Map{"$elemMatch": Map{"$regex": "^hol"}}}, true},
+		{Map{"tags": Map{"$size": 3}}, false},
+		{Map{"metadata.datetime": Map{"$type": "string"}}, true},
+		{Map{"$or": []Map{{"class": "video"}, {"type": "file"}}}, true},
+		{Map{"$nor": []Map{{"class": "video"}, {"type": "file"}}}, false},
+		{Map{"$not": Map{"class": "video"}}, true},
+		{Map{"class": Map{"$not": Map{"$eq": "image"}}}, false},
+	}
+	for _, c := range cases {
+		m, err := Compile(c.selector)
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, m.Match(doc), "%v", c.selector)
+	}
+
+	for _, selector := range []Map{
+		{"class": Map{"$unknown": 1}},
+		{"$or": Map{"class": "image"}},
+		{"size": Map{"$gt": true}},
+		{"name": Map{"$regex": "("}},
+	} {
+		_, err := Compile(selector)
+		assert.ErrorIs(t, err, ErrInvalidSelector)
+	}
+}
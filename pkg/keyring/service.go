@@ -18,12 +18,17 @@ type Keyring interface {
 	// CredentialsDecryptorKey returns the key used to decrypt credentials values,
 	// stored in accounts.
 	CredentialsDecryptorKey() *NACLKey
+	// StorageMasterKey returns the key used to seal the storage keys of the
+	// instances, for the encryption at rest of the files. It can be nil if no
+	// key has been configured.
+	StorageMasterKey() *[32]byte
 }
 
 // Config used to setup a [Keyring] service.
 type Config struct {
 	EncryptorKeyPath string `mapstructure:"credentials_encryptor_key"`
 	DecryptorKeyPath string `mapstructure:"credentials_decryptor_key"`
+	StorageKeyPath   string `mapstructure:"storage_master_key"`
 }
 
 // Service contains security keys used for various encryption or signing of
@@ -31,11 +36,22 @@ type Config struct {
 type Service struct {
 	credsEncryptor *NACLKey
 	credsDecryptor *NACLKey
+	storageMaster  *[32]byte
 }
 
 func NewFromConfig(conf Config) (Keyring, error) {
 	if conf.DecryptorKeyPath == "" || conf.EncryptorKeyPath == "" {
-		return NewStub()
+		stub, err := NewStub()
+		if err != nil {
+			return nil, err
+		}
+		if conf.StorageKeyPath != "" {
+			stub.storageMaster, err = decodeStorageKeyFromPath(conf.StorageKeyPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return stub, nil
 	}
 
 	return NewService(conf)
@@ -61,7 +77,15 @@ func NewService(conf Config) (*Service, error) {
 		return nil, err
 	}
 
-	return &Service{credsEncryptor, credsDecryptor}, nil
+	var storageMaster *[32]byte
+	if conf.StorageKeyPath != "" {
+		storageMaster, err = decodeStorageKeyFromPath(conf.StorageKeyPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Service{credsEncryptor, credsDecryptor, storageMaster}, nil
 }
 
 func (s *Service) CredentialsEncryptorKey() *NACLKey {
@@ -72,6 +96,10 @@ func (s *Service) CredentialsDecryptorKey() *NACLKey {
 	return s.credsDecryptor
 }
 
+func (s *Service) StorageMasterKey() *[32]byte {
+	return s.storageMaster
+}
+
 func decodeKeyFromPath(path string) (*NACLKey, error) {
 	keyBytes, err := os.ReadFile(path)
 	if err != nil {
@@ -85,3 +113,17 @@ func decodeKeyFromPath(path string) (*NACLKey, error) {
 
 	return creds, nil
 }
+
+func decodeStorageKeyFromPath(path string) (*[32]byte, error) {
+	keyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %w", path, err)
+	}
+
+	key, err := UnmarshalStorageKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal storage key: %w", err)
+	}
+
+	return key, nil
+}
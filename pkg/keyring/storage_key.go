@@ -0,0 +1,91 @@
+package keyring
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+const (
+	storageKeyBlockType = "STORAGE KEY"
+
+	storageKeyLen   = 32
+	storageNonceLen = 24
+)
+
+var (
+	errStorageBadKey    = errors.New("storage: bad storage key")
+	errStorageBadSealed = errors.New("storage: cannot open the sealed key")
+)
+
+// GenerateStorageKey returns a random key that can be used for the
+// encryption at rest of the files.
+func GenerateStorageKey(r io.Reader) (*[storageKeyLen]byte, error) {
+	key := new([storageKeyLen]byte)
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenerateEncodedStorageKey returns the encoded value of a storage key freshly
+// generated, to be used as the storage master key.
+func GenerateEncodedStorageKey() ([]byte, error) {
+	key, err := GenerateStorageKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalStorageKey(key), nil
+}
+
+// MarshalStorageKey takes a storage key and returns its encoded version.
+func MarshalStorageKey(key *[storageKeyLen]byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  storageKeyBlockType,
+		Bytes: key[:],
+	})
+}
+
+// UnmarshalStorageKey takes the encoded value of a storage key and returns
+// the key.
+func UnmarshalStorageKey(marshaledKey []byte) (*[storageKeyLen]byte, error) {
+	bytes, err := unmarshalPEMBlock(marshaledKey, storageKeyBlockType)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) != storageKeyLen {
+		return nil, errStorageBadKey
+	}
+	key := new([storageKeyLen]byte)
+	copy(key[:], bytes)
+	return key, nil
+}
+
+// SealStorageKey encrypts the storage key of an instance with the master key,
+// so that it can be persisted in the instance document.
+func SealStorageKey(master, key *[storageKeyLen]byte) ([]byte, error) {
+	var nonce [storageNonceLen]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	return secretbox.Seal(nonce[:], key[:], &nonce, master), nil
+}
+
+// OpenStorageKey decrypts a storage key sealed by SealStorageKey.
+func OpenStorageKey(master *[storageKeyLen]byte, sealed []byte) (*[storageKeyLen]byte, error) {
+	if len(sealed) < storageNonceLen+secretbox.Overhead {
+		return nil, errStorageBadSealed
+	}
+	var nonce [storageNonceLen]byte
+	copy(nonce[:], sealed[:storageNonceLen])
+	opened, ok := secretbox.Open(nil, sealed[storageNonceLen:], &nonce, master)
+	if !ok || len(opened) != storageKeyLen {
+		return nil, errStorageBadSealed
+	}
+	key := new([storageKeyLen]byte)
+	copy(key[:], opened)
+	return key, nil
+}
@@ -0,0 +1,45 @@
+package keyring
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageKey(t *testing.T) {
+	master, err := GenerateStorageKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := GenerateStorageKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("MarshalAndUnmarshal", func(t *testing.T) {
+		encoded := MarshalStorageKey(master)
+		decoded, err := UnmarshalStorageKey(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, master, decoded)
+
+		_, err = UnmarshalStorageKey(MarshalNACLKey(&NACLKey{new([32]byte), new([32]byte)}))
+		assert.Error(t, err)
+	})
+
+	t.Run("SealAndOpen", func(t *testing.T) {
+		sealed, err := SealStorageKey(master, key)
+		require.NoError(t, err)
+		assert.NotContains(t, string(sealed), string(key[:]))
+
+		opened, err := OpenStorageKey(master, sealed)
+		require.NoError(t, err)
+		assert.Equal(t, key, opened)
+
+		other, err := GenerateStorageKey(rand.Reader)
+		require.NoError(t, err)
+		_, err = OpenStorageKey(other, sealed)
+		assert.Error(t, err)
+
+		sealed[len(sealed)-1] ^= 0xff
+		_, err = OpenStorageKey(master, sealed)
+		assert.Error(t, err)
+	})
+}
@@ -17,6 +17,7 @@ import (
 type Stub struct {
 	credsEncryptor *NACLKey
 	credsDecryptor *NACLKey
+	storageMaster  *[32]byte
 }
 
 // NewStub instantiate a new [Stub].
@@ -28,7 +29,10 @@ func NewStub() (*Stub, error) {
 		return nil, fmt.Errorf("failed to generate NACL key pair: %w", err)
 	}
 
-	return &Stub{credsEncryptor, credsDecryptor}, nil
+	// The storage master key is not generated: the storage keys sealed with
+	// it must still be readable after a restart, or on another stack, so it
+	// is only set when it is configured.
+	return &Stub{credsEncryptor: credsEncryptor, credsDecryptor: credsDecryptor}, nil
 }
 
 func (s *Stub) CredentialsEncryptorKey() *NACLKey {
@@ -38,3 +42,7 @@ func (s *Stub) CredentialsEncryptorKey() *NACLKey {
 func (s *Stub) CredentialsDecryptorKey() *NACLKey {
 	return s.credsDecryptor
 }
+
+func (s *Stub) StorageMasterKey() *[32]byte {
+	return s.storageMaster
+}
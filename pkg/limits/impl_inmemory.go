@@ -12,15 +12,25 @@ type memRef struct {
 	exp time.Time
 }
 
-// InMemory implementation ofr [Counter].
+type memBucket struct {
+	tokens float64
+	last   time.Time
+	exp    time.Time
+}
+
+// InMemory implementation ofr [Counter] and [TokenBucket].
 type InMemory struct {
-	mu   sync.Mutex
-	vals map[string]*memRef
+	mu      sync.Mutex
+	vals    map[string]*memRef
+	buckets map[string]*memBucket
 }
 
 // NewInMemory returns a in-memory counter.
 func NewInMemory() *InMemory {
-	counter := &InMemory{vals: make(map[string]*memRef)}
+	counter := &InMemory{
+		vals:    make(map[string]*memRef),
+		buckets: make(map[string]*memBucket),
+	}
 
 	go counter.cleaner()
 
@@ -37,6 +47,11 @@ func (i *InMemory) cleaner() {
 				delete(i.vals, k)
 			}
 		}
+		for k, b := range i.buckets {
+			if now.After(b.exp) {
+				delete(i.buckets, k)
+			}
+		}
 
 		i.mu.Unlock()
 	}
@@ -64,3 +79,25 @@ func (i *InMemory) Reset(key string) error {
 
 	return nil
 }
+
+func (i *InMemory) Take(key string, cfg BucketConfig) (*BucketResult, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now()
+	b, ok := i.buckets[key]
+	if !ok {
+		b = &memBucket{tokens: float64(cfg.Limit), last: now}
+		i.buckets[key] = b
+	}
+	b.tokens = refillBucket(b.tokens, now.Sub(b.last), cfg)
+	b.last = now
+	// Once the bucket is full again, it can be forgotten
+	b.exp = now.Add(cfg.Period)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return bucketResult(allowed, b.tokens, cfg), nil
+}
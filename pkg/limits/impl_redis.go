@@ -2,13 +2,14 @@ package limits
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// Redis implementation of [Counter].
+// Redis implementation of [Counter] and [TokenBucket].
 //
 // This implementation is safe to use in multi-instances installation.
 type Redis struct {
@@ -45,3 +46,50 @@ func (r *Redis) Reset(key string) error {
 	_, err := r.Client.Del(r.ctx, key).Result()
 	return err
 }
+
+// takeToken is a lua script for redis to refill a token bucket for the time
+// elapsed since the last call, and to take a token from it if possible. The
+// time of the redis server is used, so that the buckets are consistent
+// between the cozy-stack processes.
+const takeToken = `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1]) or limit
+local last = tonumber(state[2]) or now
+if now > last then
+  tokens = tokens + (now - last) * limit / period
+end
+if tokens > limit then
+  tokens = limit
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", now)
+redis.call("PEXPIRE", KEYS[1], period)
+return {allowed, tostring(tokens)}
+`
+
+func (r *Redis) Take(key string, cfg BucketConfig) (*BucketResult, error) {
+	period := strconv.FormatInt(cfg.Period.Milliseconds(), 10)
+	limit := strconv.FormatInt(cfg.Limit, 10)
+	res, err := r.Client.Eval(r.ctx, takeToken, []string{key}, limit, period).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 2 {
+		return nil, errors.New("Unexpected response from redis")
+	}
+	allowed, _ := res[0].(int64)
+	raw, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return bucketResult(allowed == 1, tokens, cfg), nil
+}
@@ -1,6 +1,7 @@
 package limits
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -77,6 +78,9 @@ const (
 	MagicLinkType
 	// ResendOnboardingMailType is used for resending the onboarding link by email
 	ResendOnboardingMailType
+	// PasskeyChallengeType is used for counting the challenges generated for
+	// a login with a passkey
+	PasskeyChallengeType
 )
 
 type counterConfig struct {
@@ -230,6 +234,12 @@ var configs = []counterConfig{
 		Limit:  2,
 		Period: 1 * time.Hour,
 	},
+	// PasskeyChallengeType
+	{
+		Prefix: "passkey-challenge",
+		Limit:  100,
+		Period: 1 * time.Hour,
+	},
 }
 
 // Counter is an interface for counting number of attempts that can be used to
@@ -243,6 +253,7 @@ type Counter interface {
 // RateLimiter allow to rate limite the access to some resource.
 type RateLimiter struct {
 	counter Counter
+	buckets TokenBucket
 }
 
 // NewRateLimiter instantiate a new [RateLimiter].
@@ -252,10 +263,12 @@ type RateLimiter struct {
 // be chosen.
 func NewRateLimiter(client redis.UniversalClient) *RateLimiter {
 	if client == nil {
-		return &RateLimiter{NewInMemory()}
+		mem := NewInMemory()
+		return &RateLimiter{counter: mem, buckets: mem}
 	}
 
-	return &RateLimiter{NewRedis(client)}
+	r := &Redis{client, context.Background()}
+	return &RateLimiter{counter: r, buckets: r}
 }
 
 // CheckRateLimit returns an error if the counter for the given type and
@@ -295,6 +308,15 @@ func (r *RateLimiter) ResetCounter(p prefixer.Prefixer, ct CounterType) {
 	_ = r.counter.Reset(key)
 }
 
+// TakeToken takes a token from the bucket for the given key, and returns the
+// state of the bucket. The request must be refused if no token was available.
+func (r *RateLimiter) TakeToken(key string, cfg BucketConfig) (*BucketResult, error) {
+	if !cfg.IsValid() {
+		return nil, errors.New("Invalid configuration for the token bucket")
+	}
+	return r.buckets.Take("bucket:"+key, cfg)
+}
+
 // IsLimitReachedOrExceeded return true if the limit has been reached or
 // exceeded, false otherwise.
 func IsLimitReachedOrExceeded(err error) bool {
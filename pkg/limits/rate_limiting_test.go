@@ -1,10 +1,14 @@
 package limits
 
 import (
+	"context"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -84,3 +88,59 @@ func TestRate(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenBucket(t *testing.T) {
+	rOpt, err := redis.ParseURL("redis://localhost:6379/0")
+	require.NoError(t, err)
+
+	redisClient := redis.NewClient(rOpt)
+
+	tests := []struct {
+		Name      string
+		Buckets   TokenBucket
+		NeedRedis bool
+	}{
+		{
+			Name:      "InMemory",
+			Buckets:   NewInMemory(),
+			NeedRedis: false,
+		},
+		{
+			Name:      "Redis",
+			Buckets:   &Redis{redisClient, context.Background()},
+			NeedRedis: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.NeedRedis && testing.Short() {
+				t.Skip("a redis is required for this test: test skipped due to the use of --short flag")
+			}
+
+			limiter := &RateLimiter{buckets: test.Buckets}
+			key := "test:" + strconv.FormatInt(time.Now().UnixNano(), 10)
+			cfg := BucketConfig{Limit: 3, Period: 1 * time.Hour}
+
+			for i := 2; i >= 0; i-- {
+				res, err := limiter.TakeToken(key, cfg)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+				assert.EqualValues(t, 3, res.Limit)
+				assert.EqualValues(t, i, res.Remaining)
+				assert.Zero(t, res.RetryAfter)
+			}
+
+			res, err := limiter.TakeToken(key, cfg)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.EqualValues(t, 0, res.Remaining)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, res.RetryAfter, 20*time.Minute)
+			assert.LessOrEqual(t, res.Reset, 1*time.Hour)
+
+			_, err = limiter.TakeToken(key, BucketConfig{})
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package limits
+
+import (
+	"math"
+	"time"
+)
+
+// BucketConfig is the configuration of a token bucket: the bucket can hold up
+// to Limit tokens, and it is refilled continuously, at the rate of Limit
+// tokens per Period.
+type BucketConfig struct {
+	Limit  int64
+	Period time.Duration
+}
+
+// IsValid returns true if the configuration can be used for a bucket.
+func (cfg BucketConfig) IsValid() bool {
+	return cfg.Limit > 0 && cfg.Period > 0
+}
+
+// BucketResult is the state of a bucket after an attempt to take a token
+// from it.
+type BucketResult struct {
+	// Allowed is true if a token has been taken
+	Allowed bool
+	// Limit is the maximal number of tokens in the bucket
+	Limit int64
+	// Remaining is the number of tokens still available in the bucket
+	Remaining int64
+	// RetryAfter is the time to wait before a token can be taken again (only
+	// when Allowed is false)
+	RetryAfter time.Duration
+	// Reset is the time before the bucket is full again
+	Reset time.Duration
+}
+
+// TokenBucket is an interface for the backends that keep the state of the
+// token buckets, used to rate limit the requests on the API.
+type TokenBucket interface {
+	Take(key string, cfg BucketConfig) (*BucketResult, error)
+}
+
+// refillBucket returns the number of tokens in a bucket after some time has
+// elapsed since the last refill.
+func refillBucket(tokens float64, elapsed time.Duration, cfg BucketConfig) float64 {
+	if elapsed > 0 {
+		tokens += float64(elapsed) * float64(cfg.Limit) / float64(cfg.Period)
+	}
+	return math.Min(tokens, float64(cfg.Limit))
+}
+
+// bucketResult computes the state of a bucket that has the given number of
+// tokens after the attempt to take one.
+func bucketResult(allowed bool, tokens float64, cfg BucketConfig) *BucketResult {
+	perToken := float64(cfg.Period) / float64(cfg.Limit)
+	res := &BucketResult{
+		Allowed:   allowed,
+		Limit:     cfg.Limit,
+		Remaining: int64(math.Floor(tokens)),
+		Reset:     time.Duration((float64(cfg.Limit) - tokens) * perToken),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return res
+}
@@ -48,3 +48,30 @@ func (s *Service) ExtractPage(stdin io.Reader, page int) (*bytes.Buffer, error)
 	}
 	return &stdout, nil
 }
+
+// ExtractText extracts the text of a PDF.
+func (s *Service) ExtractText(stdin io.Reader) (*bytes.Buffer, error) {
+	args := []string{
+		"-q",
+		"-sDEVICE=txtwrite",
+		"-dNOPAUSE",
+		"-dBATCH",
+		"-dSAFER",
+		"-sOutputFile=-",
+		"-", // Use stdin for input
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.ghostscriptCmd, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		logger.WithNamespace("pdf").
+			WithField("stderr", stderr.String()).
+			Errorf("ghostscript failed: %s", err)
+		return nil, fmt.Errorf("failed to run the cmd %q: %w", s.ghostscriptCmd, err)
+	}
+	return &stdout, nil
+}
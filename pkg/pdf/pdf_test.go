@@ -36,3 +36,18 @@ func Test_Extract_Page(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte(expected), signature)
 }
+
+func Test_Extract_Text(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("this test require the \"gs\" binary, skip it due to the \"--short\" flag")
+	}
+
+	service := NewService("gs")
+	input, err := os.Open("../../tests/fixtures/dev-desktop.pdf")
+	require.NoError(t, err)
+	defer input.Close()
+
+	extracted, err := service.ExtractText(input)
+	require.NoError(t, err)
+	require.NotNil(t, extracted)
+}
@@ -0,0 +1,120 @@
+package realtime
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// historySize is the number of events kept for each instance, to allow
+	// a client to resume after a short disconnection.
+	historySize = 100
+
+	// historyTTL is the duration after which the history of an instance with
+	// no new events is removed.
+	historyTTL = 10 * time.Minute
+)
+
+// ErrEventsLost is used when the history can't give the events since a given
+// event ID, as the older events have already been dropped.
+var ErrEventsLost = errors.New("realtime: the events since this ID are no longer in the history")
+
+// memHistory is a ring buffer of the last events of each instance, for the
+// in-memory hub. The event IDs are made of the creation time of the ring and a
+// sequence number, to not mix them with the IDs given before a restart, or
+// before the ring of an idle instance has been removed.
+type memHistory struct {
+	mu      sync.Mutex
+	rings   map[string]*memRing // the key is the prefix of the instance
+	maxLen  int
+	ttl     time.Duration
+	sweptAt time.Time
+}
+
+type memRing struct {
+	epoch     string
+	events    []*Event
+	next      int // the index in events where the next event will be written
+	seq       int64
+	updatedAt time.Time
+}
+
+func newMemHistory(maxLen int) *memHistory {
+	return &memHistory{
+		rings:   make(map[string]*memRing),
+		maxLen:  maxLen,
+		ttl:     historyTTL,
+		sweptAt: time.Now(),
+	}
+}
+
+// add gives an ID to the event and puts it in the history.
+func (h *memHistory) add(e *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.sweep(now)
+	r, ok := h.rings[e.DBPrefix()]
+	if !ok {
+		r = &memRing{
+			epoch:  strconv.FormatInt(now.UnixMilli(), 10),
+			events: make([]*Event, 0, h.maxLen),
+		}
+		h.rings[e.DBPrefix()] = r
+	}
+	r.seq++
+	r.updatedAt = now
+	e.EventID = r.epoch + "-" + strconv.FormatInt(r.seq, 10)
+	if len(r.events) < h.maxLen {
+		r.events = append(r.events, e)
+	} else {
+		r.events[r.next] = e
+	}
+	r.next = (r.next + 1) % h.maxLen
+}
+
+// sweep removes the rings of the instances with no new events since the TTL.
+// It must be called with the lock.
+func (h *memHistory) sweep(now time.Time) {
+	if now.Sub(h.sweptAt) < h.ttl {
+		return
+	}
+	h.sweptAt = now
+	for prefix, r := range h.rings {
+		if now.Sub(r.updatedAt) > h.ttl {
+			delete(h.rings, prefix)
+		}
+	}
+}
+
+// since returns the events published after the one with the given ID.
+func (h *memHistory) since(prefix, lastEventID string) ([]*Event, error) {
+	epoch, seq, _ := strings.Cut(lastEventID, "-")
+	lastSeq, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil {
+		return nil, ErrEventsLost
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rings[prefix]
+	if !ok || r.epoch != epoch || lastSeq < 0 || lastSeq > r.seq {
+		return nil, ErrEventsLost
+	}
+	if time.Since(r.updatedAt) > h.ttl {
+		return nil, ErrEventsLost
+	}
+	count := int(r.seq - lastSeq)
+	if count > len(r.events) {
+		return nil, ErrEventsLost
+	}
+	events := make([]*Event, 0, count)
+	for i := count; i > 0; i-- {
+		idx := (r.next - i + h.maxLen) % h.maxLen
+		events = append(events, r.events[idx])
+	}
+	return events, nil
+}
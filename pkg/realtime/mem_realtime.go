@@ -13,12 +13,14 @@ type memHub struct {
 	sync.RWMutex
 	topics        map[string]*topic
 	bySubscribers map[*Subscriber][]string // the list of topic keys by subscriber
+	history       *memHistory
 }
 
 func newMemHub() *memHub {
 	return &memHub{
 		topics:        make(map[string]*topic),
 		bySubscribers: make(map[*Subscriber][]string),
+		history:       newMemHistory(historySize),
 	}
 }
 
@@ -27,7 +29,20 @@ func (h *memHub) Publish(db prefixer.Prefixer, verb string, doc, oldDoc Doc) {
 	defer h.RUnlock()
 
 	e := newEvent(db, verb, doc, oldDoc)
-	key := topicKey(db, doc.DocType())
+	h.history.add(e)
+	h.broadcast(e)
+}
+
+// publish sends an event that has already been recorded in an history, like
+// the events received from redis.
+func (h *memHub) publish(e *Event) {
+	h.RLock()
+	defer h.RUnlock()
+	h.broadcast(e)
+}
+
+func (h *memHub) broadcast(e *Event) {
+	key := topicKey(e, e.Doc.DocType())
 	it := h.topics[key]
 	if it != nil {
 		select {
@@ -46,6 +61,14 @@ func (h *memHub) Publish(db prefixer.Prefixer, verb string, doc, oldDoc Doc) {
 	}
 }
 
+func (h *memHub) History(db prefixer.Prefixer, lastEventID string) ([]*Event, error) {
+	return h.history.since(db.DBPrefix(), lastEventID)
+}
+
+// KeepHistory does nothing, as the in-memory hub keeps the events of all the
+// instances, and removes them when an instance has been idle for a while.
+func (h *memHub) KeepHistory(db prefixer.Prefixer) {}
+
 func (h *memHub) Subscriber(db prefixer.Prefixer) *Subscriber {
 	return newSubscriber(h, db)
 }
@@ -58,35 +81,46 @@ func (h *memHub) SubscribeFirehose() *Subscriber {
 }
 
 func (h *memHub) subscribe(sub *Subscriber, key string) {
+	h.addWatcher(key, &toWatch{sub, "", nil}, "subscribe")
+}
+
+func (h *memHub) subscribeSelector(sub *Subscriber, key string, sel *Selector) {
+	h.addWatcher(key, &toWatch{sub, "", sel}, "subscribe")
+}
+
+func (h *memHub) unsubscribe(sub *Subscriber, key string) {
 	h.Lock()
 	go func() {
 		defer h.Unlock()
 
-		h.addTopic(sub, key)
+		it, exists := h.topics[key]
+		if !exists {
+			return
+		}
 
-		w := &toWatch{sub, ""}
-		for {
-			it, exists := h.topics[key]
-			if !exists {
-				it = newTopic()
-				h.topics[key] = it
-			}
+		h.removeTopic(sub, key)
 
-			select {
-			case it.subscribe <- w:
-				return
-			case running := <-it.running:
-				logger.WithNamespace("realtime").
-					Warnf("unexpected state: subscribe with running=%v", running)
-				if !running {
-					delete(h.topics, key)
-				}
+		w := &toWatch{sub, "", nil}
+		select {
+		case it.unsubscribe <- w:
+			if running := <-it.running; !running {
+				delete(h.topics, key)
+			}
+		case running := <-it.running:
+			logger.WithNamespace("realtime").
+				Warnf("unexpected state: unsubscribe with running=%v", running)
+			if !running {
+				delete(h.topics, key)
 			}
 		}
 	}()
 }
 
-func (h *memHub) unsubscribe(sub *Subscriber, key string) {
+func (h *memHub) watch(sub *Subscriber, key, id string) {
+	h.addWatcher(key, &toWatch{sub, id, nil}, "watch")
+}
+
+func (h *memHub) unwatch(sub *Subscriber, key, id string) {
 	h.Lock()
 	go func() {
 		defer h.Unlock()
@@ -96,9 +130,7 @@ func (h *memHub) unsubscribe(sub *Subscriber, key string) {
 			return
 		}
 
-		h.removeTopic(sub, key)
-
-		w := &toWatch{sub, ""}
+		w := &toWatch{sub, id, nil}
 		select {
 		case it.unsubscribe <- w:
 			if running := <-it.running; !running {
@@ -106,7 +138,7 @@ func (h *memHub) unsubscribe(sub *Subscriber, key string) {
 			}
 		case running := <-it.running:
 			logger.WithNamespace("realtime").
-				Warnf("unexpected state: unsubscribe with running=%v", running)
+				Warnf("unexpected state: unwatch with running=%v", running)
 			if !running {
 				delete(h.topics, key)
 			}
@@ -114,14 +146,15 @@ func (h *memHub) unsubscribe(sub *Subscriber, key string) {
 	}()
 }
 
-func (h *memHub) watch(sub *Subscriber, key, id string) {
+// addWatcher registers the subscriber on the topic for the given key, and
+// creates the topic if needed. The action is only used for logging.
+func (h *memHub) addWatcher(key string, w *toWatch, action string) {
 	h.Lock()
 	go func() {
 		defer h.Unlock()
 
-		h.addTopic(sub, key)
+		h.addTopic(w.sub, key)
 
-		w := &toWatch{sub, id}
 		for {
 			it, exists := h.topics[key]
 			if !exists {
@@ -134,7 +167,7 @@ func (h *memHub) watch(sub *Subscriber, key, id string) {
 				return
 			case running := <-it.running:
 				logger.WithNamespace("realtime").
-					Warnf("unexpected state: watch with running=%v", running)
+					Warnf("unexpected state: %s with running=%v", action, running)
 				if !running {
 					delete(h.topics, key)
 				}
@@ -143,32 +176,6 @@ func (h *memHub) watch(sub *Subscriber, key, id string) {
 	}()
 }
 
-func (h *memHub) unwatch(sub *Subscriber, key, id string) {
-	h.Lock()
-	go func() {
-		defer h.Unlock()
-
-		it, exists := h.topics[key]
-		if !exists {
-			return
-		}
-
-		w := &toWatch{sub, id}
-		select {
-		case it.unsubscribe <- w:
-			if running := <-it.running; !running {
-				delete(h.topics, key)
-			}
-		case running := <-it.running:
-			logger.WithNamespace("realtime").
-				Warnf("unexpected state: unwatch with running=%v", running)
-			if !running {
-				delete(h.topics, key)
-			}
-		}
-	}()
-}
-
 func (h *memHub) close(sub *Subscriber) {
 	h.RLock()
 	list := h.bySubscribers[sub]
@@ -29,6 +29,7 @@ type Event struct {
 	Verb    string `json:"verb"`
 	Doc     Doc    `json:"doc"`
 	OldDoc  Doc    `json:"old,omitempty"`
+	EventID string `json:"event_id,omitempty"` // Used to resume from the history
 }
 
 func newEvent(db prefixer.Prefixer, verb string, doc Doc, oldDoc Doc) *Event {
@@ -75,7 +76,18 @@ type Hub interface {
 	// cozy-stack process.
 	SubscribeFirehose() *Subscriber
 
+	// History returns the last events of an instance, published after the
+	// one with the given ID. ErrEventsLost is returned if some events are no
+	// longer available.
+	History(db prefixer.Prefixer, lastEventID string) ([]*Event, error)
+
+	// KeepHistory asks the hub to record the events of an instance in its
+	// history, for the next 10 minutes. It is called while there is a
+	// client that may ask for the history.
+	KeepHistory(db prefixer.Prefixer)
+
 	subscribe(sub *Subscriber, key string)
+	subscribeSelector(sub *Subscriber, key string, sel *Selector)
 	unsubscribe(sub *Subscriber, key string)
 	watch(sub *Subscriber, key, id string)
 	unwatch(sub *Subscriber, key, id string)
@@ -5,9 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testingDB = prefixer.NewPrefixer(0, "testing", "testing")
@@ -215,6 +217,156 @@ func TestSubscribeWatchUnwatch(t *testing.T) {
 	assert.Equal(t, "id2", e.Doc.ID())
 }
 
+func TestSubscribeSelector(t *testing.T) {
+	h := newMemHub()
+	sub := h.Subscriber(testingDB)
+	defer sub.Close()
+
+	matcher, err := mango.Compile(mango.Map{"dir_id": "dir1"})
+	require.NoError(t, err)
+	sub.SubscribeSelector("io.cozy.testobject", &Selector{
+		Matcher: matcher,
+		Allow:   func(e *Event) bool { return e.Doc.ID() != "forbidden" },
+	})
+	sub.Watch("io.cozy.testobject", "watched")
+	time.Sleep(1 * time.Millisecond)
+
+	newDoc := func(id, dirID string) *JSONDoc {
+		return &JSONDoc{
+			M:    map[string]interface{}{"_id": id, "dir_id": dirID},
+			Type: "io.cozy.testobject",
+		}
+	}
+	h.Publish(testingDB, EventCreate, newDoc("other", "dir2"), nil)
+	h.Publish(testingDB, EventCreate, newDoc("forbidden", "dir1"), nil)
+	h.Publish(testingDB, EventCreate, newDoc("id1", "dir1"), nil)
+	e := <-sub.Channel
+	assert.Equal(t, "id1", e.Doc.ID())
+
+	h.Publish(testingDB, EventUpdate, newDoc("id1", "dir2"), newDoc("id1", "dir1"))
+	e = <-sub.Channel
+	assert.Equal(t, "id1", e.Doc.ID())
+	assert.Equal(t, EventUpdate, e.Verb)
+
+	h.Publish(testingDB, EventUpdate, newDoc("id1", "dir3"), newDoc("id1", "dir2"))
+	h.Publish(testingDB, EventUpdate, newDoc("watched", "dir2"), nil)
+	e = <-sub.Channel
+	assert.Equal(t, "watched", e.Doc.ID())
+
+	sub.Unsubscribe("io.cozy.testobject")
+	time.Sleep(1 * time.Millisecond)
+	h.Publish(testingDB, EventCreate, newDoc("id2", "dir1"), nil)
+	time.Sleep(1 * time.Millisecond)
+	select {
+	case e = <-sub.Channel:
+		t.Fatalf("unexpected event for %s", e.Doc.ID())
+	default:
+	}
+}
+
+func TestSlowAllow(t *testing.T) {
+	h := newMemHub()
+	slow := h.Subscriber(testingDB)
+	defer slow.Close()
+	other := h.Subscriber(testingDB)
+	defer other.Close()
+
+	blocked := make(chan struct{})
+	slow.SubscribeSelector("io.cozy.testobject", &Selector{
+		Allow: func(e *Event) bool {
+			<-blocked
+			return true
+		},
+	})
+	other.Subscribe("io.cozy.testobject")
+	time.Sleep(1 * time.Millisecond)
+
+	// The check for a subscriber doesn't block the events for the others
+	h.Publish(testingDB, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: "id1"}, nil)
+	h.Publish(testingDB, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: "id2"}, nil)
+	e := <-other.Channel
+	assert.Equal(t, "id1", e.Doc.ID())
+	e = <-other.Channel
+	assert.Equal(t, "id2", e.Doc.ID())
+
+	close(blocked)
+	e = <-slow.Channel
+	assert.Equal(t, "id1", e.Doc.ID())
+	e = <-slow.Channel
+	assert.Equal(t, "id2", e.Doc.ID())
+}
+
+func TestMemHistory(t *testing.T) {
+	h := newMemHub()
+	h.history = newMemHistory(3)
+	sub := h.Subscriber(testingDB)
+	defer sub.Close()
+	sub.Subscribe("io.cozy.testobject")
+	time.Sleep(1 * time.Millisecond)
+
+	var ids []string
+	for _, id := range []string{"id1", "id2", "id3", "id4", "id5"} {
+		h.Publish(testingDB, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: id}, nil)
+		e := <-sub.Channel
+		require.NotEmpty(t, e.EventID)
+		ids = append(ids, e.EventID)
+	}
+
+	events, err := h.History(testingDB, ids[2])
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "id4", events[0].Doc.ID())
+	assert.Equal(t, ids[3], events[0].EventID)
+	assert.Equal(t, "id5", events[1].Doc.ID())
+
+	events, err = h.History(testingDB, ids[1])
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	events, err = h.History(testingDB, ids[4])
+	require.NoError(t, err)
+	assert.Len(t, events, 0)
+
+	_, err = h.History(testingDB, ids[0])
+	assert.ErrorIs(t, err, ErrEventsLost)
+	_, err = h.History(testingDB, "invalid")
+	assert.ErrorIs(t, err, ErrEventsLost)
+	other := prefixer.NewPrefixer(0, "other", "other")
+	_, err = h.History(other, ids[4])
+	assert.ErrorIs(t, err, ErrEventsLost)
+}
+
+func TestMemHistoryTTL(t *testing.T) {
+	h := newMemHub()
+	h.history.ttl = 10 * time.Millisecond
+	sub := h.Subscriber(testingDB)
+	defer sub.Close()
+	sub.Subscribe("io.cozy.testobject")
+	time.Sleep(1 * time.Millisecond)
+
+	h.Publish(testingDB, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: "id1"}, nil)
+	e := <-sub.Channel
+	_, err := h.History(testingDB, e.EventID)
+	require.NoError(t, err)
+
+	// The ring of an idle instance is removed on the next event of another
+	// instance, and an event ID of the old ring can't be used with a new one
+	time.Sleep(20 * time.Millisecond)
+	other := prefixer.NewPrefixer(0, "other", "other")
+	h.Publish(other, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: "id2"}, nil)
+	h.history.mu.Lock()
+	assert.Len(t, h.history.rings, 1)
+	h.history.mu.Unlock()
+	_, err = h.History(testingDB, e.EventID)
+	assert.ErrorIs(t, err, ErrEventsLost)
+
+	h.Publish(testingDB, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: "id3"}, nil)
+	e2 := <-sub.Channel
+	assert.NotEqual(t, e.EventID, e2.EventID)
+	_, err = h.History(testingDB, e.EventID)
+	assert.ErrorIs(t, err, ErrEventsLost)
+}
+
 func TestRedisRealtime(t *testing.T) {
 	if testing.Short() {
 		t.Skip("a redis is required for this test: test skipped due to the use of --short flag")
@@ -3,27 +3,62 @@ package realtime
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	redis "github.com/redis/go-redis/v9"
 )
 
-const eventsRedisKey = "realtime:events"
+const (
+	eventsRedisKey    = "realtime:events"
+	listeningRedisKey = "realtime:listening"
+
+	// listeningCacheTTL is the duration for which a stack remembers if the
+	// history of an instance must be kept, without asking redis.
+	listeningCacheTTL = time.Minute
+)
+
+func historyRedisKey(db prefixer.Prefixer) string {
+	return "realtime:history:" + db.DBPrefix()
+}
+
+func listeningKey(prefix string) string {
+	return listeningRedisKey + ":" + prefix
+}
 
 type redisHub struct {
 	c        redis.UniversalClient
 	ctx      context.Context
 	mem      *memHub
 	firehose *topic
+
+	// listening is a cache of the instances for which the history is kept
+	mu        sync.Mutex
+	listening map[string]listening // the key is the prefix of the instance
+	sweptAt   time.Time
+}
+
+type listening struct {
+	keep  bool
+	until time.Time
 }
 
 func newRedisHub(c redis.UniversalClient) *redisHub {
 	ctx := context.Background()
 	firehose := newTopic()
 	mem := newMemHub()
-	hub := &redisHub{c, ctx, mem, firehose}
+	hub := &redisHub{
+		c:         c,
+		ctx:       ctx,
+		mem:       mem,
+		firehose:  firehose,
+		listening: make(map[string]listening),
+		sweptAt:   time.Now(),
+	}
 	go hub.start()
 	return hub
 }
@@ -74,6 +109,7 @@ type jsonEvent struct {
 	Verb    string
 	Doc     *JSONDoc
 	Old     *JSONDoc
+	EventID string
 }
 
 func (j *jsonEvent) UnmarshalJSON(buf []byte) error {
@@ -87,6 +123,7 @@ func (j *jsonEvent) UnmarshalJSON(buf []byte) error {
 	j.Domain, _ = m["domain"].(string)
 	j.Prefix, _ = m["prefix"].(string)
 	j.Verb, _ = m["verb"].(string)
+	j.EventID, _ = m["event_id"].(string)
 	if doc, ok := m["doc"].(map[string]interface{}); ok {
 		j.Doc = toJSONDoc(doc)
 	}
@@ -97,54 +134,167 @@ func (j *jsonEvent) UnmarshalJSON(buf []byte) error {
 }
 
 func (h *redisHub) start() {
-	sub := h.c.Subscribe(h.ctx, eventsRedisKey)
+	sub := h.c.Subscribe(h.ctx, eventsRedisKey, listeningRedisKey)
 	log := logger.WithNamespace("realtime-redis")
 	for msg := range sub.Channel() {
-		parts := strings.SplitN(msg.Payload, ",", 2)
-		if len(parts) < 2 {
-			log.Warnf("Invalid payload: %s", msg.Payload)
+		if msg.Channel == listeningRedisKey {
+			h.setListening(msg.Payload, true)
 			continue
 		}
-		// We clone the doctype to allow the GC to collect the payload even if
-		// the jsonEvent is still in use.
-		doctype := strings.Clone(parts[0])
-		r := strings.NewReader(parts[1])
-		je := jsonEvent{}
-		if err := json.NewDecoder(r).Decode(&je); err != nil {
+		e, err := decodeEvent(msg.Payload)
+		if err != nil {
 			log.Warnf("Error on start: %s", err)
 			continue
 		}
-		if je.Doc != nil {
-			je.Doc.Type = doctype
-		}
-		if je.Old != nil {
-			je.Old.Type = doctype
-		}
-		db := prefixer.NewPrefixer(je.Cluster, je.Domain, je.Prefix)
-		h.mem.Publish(db, je.Verb, je.Doc, je.Old)
+		h.mem.publish(e)
 	}
 	logger.WithNamespace("realtime-redis").Infof("End of subscribe channel")
 }
 
+// decodeEvent parses an event serialized as its doctype, a comma, and the
+// JSON of the event.
+func decodeEvent(payload string) (*Event, error) {
+	parts := strings.SplitN(payload, ",", 2)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid payload: %s", payload)
+	}
+	// We clone the doctype to allow the GC to collect the payload even if
+	// the jsonEvent is still in use.
+	doctype := strings.Clone(parts[0])
+	r := strings.NewReader(parts[1])
+	je := jsonEvent{}
+	if err := json.NewDecoder(r).Decode(&je); err != nil {
+		return nil, err
+	}
+	if je.Doc != nil {
+		je.Doc.Type = doctype
+	}
+	var old Doc
+	if je.Old != nil {
+		je.Old.Type = doctype
+		old = je.Old
+	}
+	db := prefixer.NewPrefixer(je.Cluster, je.Domain, je.Prefix)
+	e := newEvent(db, je.Verb, je.Doc, old)
+	e.EventID = je.EventID
+	return e, nil
+}
+
 func (h *redisHub) Publish(db prefixer.Prefixer, verb string, doc, oldDoc Doc) {
 	e := newEvent(db, verb, doc, oldDoc)
-	h.firehose.broadcast <- e
+	log := logger.WithNamespace("realtime-redis")
 	buf, err := json.Marshal(e)
 	if err != nil {
-		log := logger.WithNamespace("realtime-redis")
+		h.firehose.broadcast <- e
 		log.Warnf("Error on publish: %s", err)
 		return
 	}
+
+	// The event is added to the history first, to know its ID
+	if h.keepsHistory(db) {
+		key := historyRedisKey(db)
+		var added *redis.StringCmd
+		_, err = h.c.TxPipelined(h.ctx, func(pipe redis.Pipeliner) error {
+			added = pipe.XAdd(h.ctx, &redis.XAddArgs{
+				Stream: key,
+				MaxLen: historySize,
+				Approx: true,
+				Values: []interface{}{"event", e.Doc.DocType() + "," + string(buf)},
+			})
+			pipe.Expire(h.ctx, key, historyTTL)
+			return nil
+		})
+		if err != nil {
+			log.Warnf("Error on history: %s", err)
+		} else {
+			e.EventID = added.Val()
+			if buf, err = json.Marshal(e); err != nil {
+				log.Warnf("Error on publish: %s", err)
+				return
+			}
+		}
+	}
+
+	h.firehose.broadcast <- e
 	h.c.Publish(h.ctx, eventsRedisKey, e.Doc.DocType()+","+string(buf))
 }
 
+func (h *redisHub) History(db prefixer.Prefixer, lastEventID string) ([]*Event, error) {
+	// The range is inclusive, so the first message must be the last event
+	// seen by the client, or some events may have been dropped.
+	msgs, err := h.c.XRange(h.ctx, historyRedisKey(db), lastEventID, "+").Result()
+	if err != nil || len(msgs) == 0 || msgs[0].ID != lastEventID {
+		return nil, ErrEventsLost
+	}
+	events := make([]*Event, 0, len(msgs)-1)
+	for _, msg := range msgs[1:] {
+		payload, _ := msg.Values["event"].(string)
+		e, err := decodeEvent(payload)
+		if err != nil {
+			return nil, err
+		}
+		e.EventID = msg.ID
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// KeepHistory marks the instance in redis, and tells the other stacks that
+// they must record its events.
+func (h *redisHub) KeepHistory(db prefixer.Prefixer) {
+	prefix := db.DBPrefix()
+	_, err := h.c.Pipelined(h.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(h.ctx, listeningKey(prefix), "1", historyTTL)
+		pipe.Publish(h.ctx, listeningRedisKey, prefix)
+		return nil
+	})
+	if err != nil {
+		logger.WithNamespace("realtime-redis").Warnf("Error on keep history: %s", err)
+	}
+	h.setListening(prefix, true)
+}
+
+// keepsHistory returns true if the events of the instance must be recorded
+// in the history. The answer of redis is kept in cache for a short time, and
+// the cache is updated when a stack publishes that an instance is listened.
+func (h *redisHub) keepsHistory(db prefixer.Prefixer) bool {
+	prefix := db.DBPrefix()
+	h.mu.Lock()
+	l, ok := h.listening[prefix]
+	h.mu.Unlock()
+	if ok && time.Now().Before(l.until) {
+		return l.keep
+	}
+	n, err := h.c.Exists(h.ctx, listeningKey(prefix)).Result()
+	// In case of doubt, the history is kept
+	keep := err != nil || n > 0
+	h.setListening(prefix, keep)
+	return keep
+}
+
+func (h *redisHub) setListening(prefix string, keep bool) {
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.listening[prefix] = listening{keep: keep, until: now.Add(listeningCacheTTL)}
+	if now.Sub(h.sweptAt) < listeningCacheTTL {
+		return
+	}
+	h.sweptAt = now
+	for p, l := range h.listening {
+		if now.After(l.until) {
+			delete(h.listening, p)
+		}
+	}
+}
+
 func (h *redisHub) Subscriber(db prefixer.Prefixer) *Subscriber {
 	return h.mem.Subscriber(db)
 }
 
 func (h *redisHub) SubscribeFirehose() *Subscriber {
 	sub := newSubscriber(h, globalPrefixer)
-	h.firehose.subscribe <- &toWatch{sub, ""}
+	h.firehose.subscribe <- &toWatch{sub, "", nil}
 	return sub
 }
 
@@ -152,8 +302,12 @@ func (h *redisHub) subscribe(sub *Subscriber, key string) {
 	panic("not reachable code")
 }
 
+func (h *redisHub) subscribeSelector(sub *Subscriber, key string, sel *Selector) {
+	panic("not reachable code")
+}
+
 func (h *redisHub) unsubscribe(sub *Subscriber, key string) {
-	h.firehose.unsubscribe <- &toWatch{sub, ""}
+	h.firehose.unsubscribe <- &toWatch{sub, "", nil}
 	<-h.firehose.running
 }
 
@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"encoding/json"
+
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+)
+
+// Selector is used to subscribe to the events of a doctype only for the
+// documents that match a mango selector. An update is sent if the new or the
+// old version of the document matches, so that the subscriber can see when a
+// document leaves the set.
+type Selector struct {
+	Matcher *mango.Matcher
+
+	// Allow is an optional check made on the events that match, for example
+	// to send only the events on the documents where the subscriber has the
+	// permissions. It is called in a goroutine of the subscriber, not in the
+	// goroutine of the topic, and can be slow.
+	Allow func(e *Event) bool
+}
+
+// matchingEvent keeps the documents of an event as maps, so that they are
+// serialized only once for all the selectors of a topic.
+type matchingEvent struct {
+	*Event
+	doc       map[string]interface{}
+	old       map[string]interface{}
+	converted bool
+}
+
+func (m *matchingEvent) convert() {
+	if m.converted {
+		return
+	}
+	m.converted = true
+	m.doc = docToMap(m.Doc)
+	if m.OldDoc != nil {
+		m.old = docToMap(m.OldDoc)
+	}
+}
+
+// match returns true if the document of the event matches the selector. The
+// Allow function is not called here.
+func (s *Selector) match(m *matchingEvent) bool {
+	if s.Matcher == nil {
+		return true
+	}
+	m.convert()
+	ok := m.doc != nil && s.Matcher.Match(m.doc)
+	if !ok && m.old != nil {
+		ok = s.Matcher.Match(m.old)
+	}
+	return ok
+}
+
+// docToMap returns the document as a map, with the same types as if it was
+// unmarshaled from JSON, or nil if the document can't be serialized.
+func docToMap(doc Doc) map[string]interface{} {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil
+	}
+	return m
+}
@@ -1,6 +1,9 @@
 package realtime
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 )
 
@@ -10,17 +13,88 @@ type Subscriber struct {
 	Channel EventsChan
 	hub     Hub
 	running chan struct{}
+
+	// When a selector has an Allow function, the events go through the
+	// pending channel, and the checks are made in a goroutine of the
+	// subscriber, so that they don't block the topic and the other
+	// subscribers.
+	pending  chan *pendingEvent
+	checking atomic.Bool
+	once     sync.Once
 }
 
 // EventsChan is a chan of events
 type EventsChan chan *Event
 
+// pendingEvent is an event that is sent to the subscriber if one of the allow
+// functions returns true, or if there are none.
+type pendingEvent struct {
+	*Event
+	allow []func(e *Event) bool
+}
+
+func (p *pendingEvent) allowed() bool {
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, allow := range p.allow {
+		if allow(p.Event) {
+			return true
+		}
+	}
+	return false
+}
+
 func newSubscriber(hub Hub, db prefixer.Prefixer) *Subscriber {
 	return &Subscriber{
 		Prefixer: db,
 		Channel:  make(chan *Event, 100),
 		hub:      hub,
 		running:  make(chan struct{}),
+		pending:  make(chan *pendingEvent, 100),
+	}
+}
+
+// send gives an event to the subscriber, or drops it if the subscriber has
+// been closed. It is called by the topics.
+func (sub *Subscriber) send(e *Event, allow []func(e *Event) bool) {
+	if !sub.checking.Load() {
+		select {
+		case sub.Channel <- e:
+		case <-sub.running:
+		}
+		return
+	}
+	// All the events go through the pending channel to keep their order.
+	select {
+	case sub.pending <- &pendingEvent{Event: e, allow: allow}:
+	case <-sub.running:
+	}
+}
+
+// startChecks starts the goroutine that checks the pending events.
+func (sub *Subscriber) startChecks() {
+	sub.once.Do(func() {
+		go sub.checkLoop()
+		sub.checking.Store(true)
+	})
+}
+
+func (sub *Subscriber) checkLoop() {
+	for {
+		select {
+		case p := <-sub.pending:
+			if !p.allowed() {
+				continue
+			}
+			select {
+			case sub.Channel <- p.Event:
+			case <-sub.running:
+				return
+			}
+		case <-sub.running:
+			return
+		}
 	}
 }
 
@@ -33,6 +107,20 @@ func (sub *Subscriber) Subscribe(doctype string) {
 	sub.hub.subscribe(sub, key)
 }
 
+// SubscribeSelector adds a listener for events on the documents of a doctype
+// that match the given selector. Calling Unsubscribe for the doctype removes
+// all the selectors.
+func (sub *Subscriber) SubscribeSelector(doctype string, sel *Selector) {
+	if sub.hub == nil {
+		return
+	}
+	if sel.Allow != nil {
+		sub.startChecks()
+	}
+	key := topicKey(sub, doctype)
+	sub.hub.subscribeSelector(sub, key, sel)
+}
+
 // Unsubscribe removes a listener for events on a whole doctype
 func (sub *Subscriber) Unsubscribe(doctype string) {
 	if sub.hub == nil {
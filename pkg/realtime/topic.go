@@ -1,13 +1,15 @@
 package realtime
 
 type filter struct {
-	whole bool // true if the events for the whole doctype should be sent
-	ids   []string
+	whole     bool // true if the events for the whole doctype should be sent
+	ids       []string
+	selectors []*Selector
 }
 
 type toWatch struct {
-	sub *Subscriber
-	id  string // empty string means the whole doctype
+	sub      *Subscriber
+	id       string    // empty string means the whole doctype
+	selector *Selector // used only when id is empty
 }
 
 type topic struct {
@@ -49,30 +51,44 @@ func (t *topic) loop() {
 }
 
 func (t *topic) publish(e *Event) {
+	m := &matchingEvent{Event: e}
 	for s, f := range t.subs {
-		ok := false
-		if f.whole {
-			ok = true
-		} else {
-			for _, id := range f.ids {
-				if e.Doc.ID() == id {
-					ok = true
-					break
-				}
+		ok := f.whole
+		for _, id := range f.ids {
+			if ok {
+				break
 			}
+			ok = e.Doc.ID() == id
 		}
-		if ok {
-			select {
-			case s.Channel <- e:
-			case <-s.running: // the subscriber has been closed
+		// The checks of the selectors with an Allow function are left to the
+		// subscriber, as they can be slow.
+		var allow []func(e *Event) bool
+		for _, sel := range f.selectors {
+			if ok {
+				break
+			}
+			if !sel.match(m) {
+				continue
 			}
+			if sel.Allow == nil {
+				ok = true
+			} else {
+				allow = append(allow, sel.Allow)
+			}
+		}
+		if ok {
+			s.send(e, nil)
+		} else if len(allow) > 0 {
+			s.send(e, allow)
 		}
 	}
 }
 
 func (t *topic) doSubscribe(w *toWatch) {
 	f := t.subs[w.sub]
-	if w.id == "" {
+	if w.selector != nil {
+		f.selectors = append(f.selectors, w.selector)
+	} else if w.id == "" {
 		f.whole = true
 	} else {
 		f.ids = append(f.ids, w.id)
@@ -90,7 +106,7 @@ func (t *topic) doUnsubscribe(w *toWatch) {
 				ids = append(ids, id)
 			}
 		}
-		if len(ids) == 0 && !f.whole {
+		if len(ids) == 0 && len(f.selectors) == 0 && !f.whole {
 			delete(t.subs, w.sub)
 		} else {
 			f.ids = ids
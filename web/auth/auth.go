@@ -231,6 +231,7 @@ func renderLoginForm(c echo.Context, i *instance.Instance, code int, credsErrors
 		"MagicLink":         magicLink,
 		"OAuth":             hasOAuth,
 		"FranceConnect":     hasFranceConnect,
+		"Passkey":           !magicLink && hasPasskeys(i),
 		"DataProxyCleanURL": dataProxyCleanURL,
 	})
 }
@@ -357,8 +358,8 @@ func login(c echo.Context) error {
 		// activated.
 		// If device is trusted, skip the 2FA.
 		// If the email has already been verified, skip the 2FA too.
-		if inst.HasAuthMode(instance.TwoFactorMail) && !isTrustedDevice(c, inst) && !hasEmailVerified(c, inst) {
-			twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
+		if inst.HasTwoFactor() && !isTrustedDevice(c, inst) && !hasEmailVerified(c, inst) {
+			twoFactorToken, err := lifecycle.StartTwoFactor(inst)
 			if err != nil {
 				return err
 			}
@@ -667,6 +668,14 @@ func Routes(router *echo.Group) {
 	router.GET("/twofactor", twoFactorForm)
 	router.POST("/twofactor", twoFactor)
 
+	// Passkeys
+	router.POST("/webauthn/register/begin", beginPasskeyRegistration)
+	router.POST("/webauthn/register/finish", finishPasskeyRegistration, middlewares.ContentTypeJSON)
+	router.GET("/webauthn/credentials", listPasskeys)
+	router.DELETE("/webauthn/credentials/:id", deletePasskey)
+	router.POST("/webauthn/login/begin", beginPasskeyLogin, middlewares.CheckOnboardingNotFinished)
+	router.POST("/webauthn/login/finish", finishPasskeyLogin, middlewares.CheckOnboardingNotFinished)
+
 	// Share by link protected by password
 	router.POST("/share-by-link/password", checkPasswordForShareByLink)
 }
@@ -71,8 +71,8 @@ func confirmAuth(c echo.Context) error {
 		})
 	}
 
-	if inst.HasAuthMode(instance.TwoFactorMail) && !isTrustedDevice(c, inst) {
-		twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
+	if inst.HasTwoFactor() && !isTrustedDevice(c, inst) {
+		twoFactorToken, err := lifecycle.StartTwoFactor(inst)
 		if err != nil {
 			return err
 		}
@@ -28,13 +28,17 @@ func CreateSessionCode(c echo.Context) error {
 	case allowedToCreateSessionCode:
 		// OK
 	case need2FAToCreateSessionCode:
+		if inst.HasAuthMode(instance.Passkey) {
+			return PasskeyRequired(c)
+		}
 		twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
 		if err != nil {
 			return err
 		}
 		return c.JSON(http.StatusForbidden, echo.Map{
-			"error":            "two factor needed",
-			"two_factor_token": string(twoFactorToken),
+			"error":             "two factor needed",
+			"two_factor_token":  string(twoFactorToken),
+			"two_factor_method": inst.TwoFactorMethod(),
 		})
 	default:
 		return c.JSON(http.StatusUnauthorized, echo.Map{
@@ -96,7 +100,10 @@ func canCreateSessionCode(c echo.Context, inst *instance.Instance) canCreateSess
 		return cannotCreateSessionCode
 	}
 
-	if inst.HasAuthMode(instance.TwoFactorMail) {
+	if inst.HasAuthMode(instance.Passkey) {
+		return need2FAToCreateSessionCode
+	}
+	if inst.HasTwoFactor() {
 		token := []byte(args.TwoFactorToken)
 		if ok := inst.ValidateTwoFactorPasscode(token, args.TwoFactorCode); !ok {
 			return need2FAToCreateSessionCode
@@ -204,14 +211,18 @@ func loginFlagship(c echo.Context) error {
 		})
 	}
 
-	if inst.HasAuthMode(instance.TwoFactorMail) && !inst.CheckEmailVerifiedCode(args.EmailVerifiedCode) {
+	if inst.HasAuthMode(instance.Passkey) {
+		return PasskeyRequired(c)
+	}
+	if inst.HasTwoFactor() && !inst.CheckEmailVerifiedCode(args.EmailVerifiedCode) {
 		if len(args.TwoFactorToken) == 0 {
 			twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
 			if err != nil {
 				return err
 			}
 			return c.JSON(http.StatusUnauthorized, echo.Map{
-				"two_factor_token": string(twoFactorToken),
+				"two_factor_token":  string(twoFactorToken),
+				"two_factor_method": inst.TwoFactorMethod(),
 			})
 		}
 		twoFactorToken := []byte(args.TwoFactorToken)
@@ -766,8 +766,8 @@ func (a *AuthorizeHTTPHandler) authorizeMove(c echo.Context) error {
 		})
 	}
 
-	if inst.HasAuthMode(instance.TwoFactorMail) && !isTrustedDevice(c, inst) {
-		twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
+	if inst.HasTwoFactor() && !isTrustedDevice(c, inst) {
+		twoFactorToken, err := lifecycle.StartTwoFactor(inst)
 		if err != nil {
 			return err
 		}
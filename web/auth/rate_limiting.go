@@ -24,9 +24,9 @@ func TwoFactorRateExceeded(i *instance.Instance) error {
 	if limits.IsLimitReachedOrExceeded(err) {
 		return TwoFactorGenerationExceeded(i)
 	}
-	// Reset the key and send a new passcode to the user
+	// Reset the key and send a new passcode to the user (if a passcode is used)
 	config.GetRateLimiter().ResetCounter(i, limits.TwoFactorType)
-	_, err = lifecycle.SendTwoFactorPasscode(i)
+	_, err = lifecycle.StartTwoFactor(i)
 	return err
 }
 
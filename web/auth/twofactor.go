@@ -50,6 +50,8 @@ func renderTwoFactorForm(c echo.Context, i *instance.Instance, code int, credsEr
 		"LongRunSession":        longRunSession,
 		"TwoFactorToken":        string(twoFactorToken),
 		"TrustedDeviceCheckBox": trustedCheckbox,
+		"Passkey":               i.HasAuthMode(instance.Passkey),
+		"Authenticator":         i.HasAuthMode(instance.TwoFactorTOTP),
 	})
 }
 
@@ -87,7 +89,7 @@ func twoFactorForm(c echo.Context) error {
 // twoFactor handles a the twoFactor POST request
 func twoFactor(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
-	if !inst.HasAuthMode(instance.TwoFactorMail) {
+	if !inst.HasTwoFactor() {
 		errorMessage := inst.Translate(TwoFactorErrorKey)
 		return c.JSON(http.StatusUnauthorized, echo.Map{
 			"error": errorMessage,
@@ -100,8 +102,16 @@ func twoFactor(c echo.Context) error {
 	generateTrustedDeviceToken, _ := strconv.ParseBool(c.FormValue("two-factor-generate-trusted-device-token"))
 
 	// Handle 2FA failed
-	correctPasscode := inst.ValidateTwoFactorPasscode(token, passcode)
-	if !correctPasscode {
+	var correct bool
+	if inst.HasAuthMode(instance.Passkey) {
+		if inst.ValidateTwoFactorToken(token) {
+			_, err := checkPasskeyAssertion(c, inst, false)
+			correct = err == nil
+		}
+	} else {
+		correct = inst.ValidateTwoFactorPasscode(token, passcode)
+	}
+	if !correct {
 		return twoFactorFailed(c, inst, token)
 	}
 
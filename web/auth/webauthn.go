@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/session"
+	"github.com/cozy/cozy-stack/model/webauthn"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// PasskeyErrorKey is the key for translating the message showed to the user
+// when the passkey cannot be used to log in.
+const PasskeyErrorKey = "Login Passkey error"
+
+// PasskeyRequired is the response for the login flows of the API clients when
+// the second factor is a passkey: they can't make a WebAuthn assertion, and
+// they can't fallback to a passcode sent by mail.
+func PasskeyRequired(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, echo.Map{
+		"error": "a passkey is required for the second factor",
+	})
+}
+
+// hasPasskeys returns true if the user has registered at least one passkey.
+func hasPasskeys(inst *instance.Instance) bool {
+	creds, err := webauthn.ListCredentials(inst)
+	return err == nil && len(creds) > 0
+}
+
+// beginPasskeyRegistration returns the options for creating a new passkey in
+// the browser. Only the settings app can manage the passkeys.
+func beginPasskeyRegistration(c echo.Context) error {
+	if err := middlewares.RequireSettingsApp(c); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	opts, err := webauthn.BeginRegistration(inst)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{"publicKey": opts})
+}
+
+// finishPasskeyRegistration checks the new passkey created by the browser,
+// and saves it.
+func finishPasskeyRegistration(c echo.Context) error {
+	if err := middlewares.RequireSettingsApp(c); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	var args struct {
+		Name       string                         `json:"name"`
+		Credential *webauthn.RegistrationResponse `json:"credential"`
+	}
+	if err := c.Bind(&args); err != nil || args.Credential == nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "missing credential",
+		})
+	}
+	cred, err := webauthn.FinishRegistration(inst, args.Credential, args.Name)
+	if err != nil {
+		return passkeyError(c, err)
+	}
+	return c.JSON(http.StatusCreated, cred)
+}
+
+// listPasskeys returns the passkeys registered by the user.
+func listPasskeys(c echo.Context) error {
+	if err := middlewares.RequireSettingsApp(c); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	creds, err := webauthn.ListCredentials(inst)
+	if err != nil {
+		return err
+	}
+	if creds == nil {
+		creds = []*webauthn.Credential{}
+	}
+	return c.JSON(http.StatusOK, creds)
+}
+
+// deletePasskey removes a passkey. The last passkey cannot be removed while
+// the passkeys are used as the second factor.
+func deletePasskey(c echo.Context) error {
+	if err := middlewares.RequireSettingsApp(c); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	cred, err := webauthn.GetCredential(inst, c.Param("id"))
+	if err != nil {
+		return passkeyError(c, err)
+	}
+	if inst.HasAuthMode(instance.Passkey) {
+		creds, err := webauthn.ListCredentials(inst)
+		if err != nil {
+			return err
+		}
+		if len(creds) <= 1 {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": "the last passkey cannot be deleted while it is used as the second factor",
+			})
+		}
+	}
+	if err := cred.Delete(inst); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// beginPasskeyLogin returns the options for an assertion in the browser. With
+// a two-factor token, the passkey is used as the second factor. Without it,
+// this is a passwordless login, and the user verification is required.
+func beginPasskeyLogin(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	err := config.GetRateLimiter().CheckRateLimit(inst, limits.PasskeyChallengeType)
+	if limits.IsLimitReachedOrExceeded(err) {
+		return c.JSON(http.StatusTooManyRequests, echo.Map{
+			"error": "Too many requests",
+		})
+	}
+	userVerification := webauthn.UserVerificationRequired
+	if token := c.FormValue("two-factor-token"); token != "" {
+		if !inst.ValidateTwoFactorToken([]byte(token)) {
+			return c.JSON(http.StatusUnauthorized, echo.Map{
+				"error": inst.Translate(TwoFactorErrorKey),
+			})
+		}
+		userVerification = webauthn.UserVerificationPreferred
+	} else if inst.HasForcedOIDC() {
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "passwordless login is not allowed",
+		})
+	}
+	opts, err := webauthn.BeginLogin(inst, userVerification)
+	if err != nil {
+		return passkeyError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"publicKey": opts})
+}
+
+// finishPasskeyLogin checks the assertion made for a passwordless login, and
+// creates a new session.
+func finishPasskeyLogin(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if inst.HasForcedOIDC() {
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "passwordless login is not allowed",
+		})
+	}
+	redirect, err := checkRedirectParam(c, inst.DefaultRedirection())
+	if err != nil {
+		return err
+	}
+	longRunSession, _ := strconv.ParseBool(c.FormValue("long-run-session"))
+
+	if _, ok := middlewares.GetSession(c); !ok {
+		if _, err := checkPasskeyAssertion(c, inst, true); err != nil {
+			errorMessage := inst.Translate(PasskeyErrorKey)
+			err := config.GetRateLimiter().CheckRateLimit(inst, limits.AuthType)
+			if limits.IsLimitReachedOrExceeded(err) {
+				if err = LoginRateExceeded(inst); err != nil {
+					inst.Logger().WithNamespace("auth").Warn(err.Error())
+				}
+			}
+			return c.JSON(http.StatusUnauthorized, echo.Map{
+				"error": errorMessage,
+			})
+		}
+
+		duration := session.NormalRun
+		if longRunSession {
+			duration = session.LongRun
+		}
+		if err := newSession(c, inst, redirect, duration, "passkey"); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"redirect": redirect.String(),
+	})
+}
+
+// checkPasskeyAssertion checks the assertion sent by the browser in the
+// webauthn-credential form field.
+func checkPasskeyAssertion(c echo.Context, inst *instance.Instance, requireUV bool) (*webauthn.Credential, error) {
+	var res webauthn.AssertionResponse
+	if err := json.Unmarshal([]byte(c.FormValue("webauthn-credential")), &res); err != nil {
+		return nil, webauthn.ErrInvalidResponse
+	}
+	cred, err := webauthn.FinishLogin(inst, &res, requireUV)
+	if err != nil {
+		inst.Logger().WithNamespace("auth").Infof("Invalid passkey assertion: %s", err)
+		return nil, err
+	}
+	return cred, nil
+}
+
+func passkeyError(c echo.Context, err error) error {
+	code := http.StatusBadRequest
+	switch {
+	case errors.Is(err, webauthn.ErrUnknownCredential), errors.Is(err, webauthn.ErrNoCredentials):
+		code = http.StatusNotFound
+	case errors.Is(err, webauthn.ErrAlreadyRegistered):
+		code = http.StatusConflict
+	case errors.Is(err, webauthn.ErrInvalidResponse),
+		errors.Is(err, webauthn.ErrInvalidChallenge),
+		errors.Is(err, webauthn.ErrInvalidSignature),
+		errors.Is(err, webauthn.ErrUnsupportedKey),
+		errors.Is(err, webauthn.ErrClonedAuthenticator):
+	default:
+		return err
+	}
+	return c.JSON(code, echo.Map{"error": err.Error()})
+}
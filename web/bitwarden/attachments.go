@@ -0,0 +1,341 @@
+package bitwarden
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// fileUploadDirect is the type of upload where the client sends the content
+// of the file to the stack (the other types are for Azure storage).
+const fileUploadDirect = 0
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Vault/Models/Request/AttachmentRequestModel.cs
+type attachmentRequest struct {
+	Key      string `json:"key"`
+	FileName string `json:"fileName"`
+	FileSize int64  `json:"fileSize"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Vault/Models/Response/AttachmentResponseModel.cs
+type attachmentResponse struct {
+	Object   string  `json:"Object"`
+	ID       string  `json:"Id"`
+	URL      *string `json:"Url"`
+	FileName string  `json:"FileName"`
+	Key      string  `json:"Key"`
+	Size     string  `json:"Size"`
+	SizeName string  `json:"SizeName"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Vault/Models/Response/AttachmentUploadDataResponseModel.cs
+type attachmentUploadResponse struct {
+	Object         string          `json:"Object"`
+	AttachmentID   string          `json:"AttachmentId"`
+	URL            string          `json:"Url"`
+	FileUploadType int             `json:"FileUploadType"`
+	CipherResponse *cipherResponse `json:"CipherResponse"`
+}
+
+func newAttachmentResponse(id string, a *bitwarden.Attachment, url string) *attachmentResponse {
+	r := attachmentResponse{
+		Object:   "attachment",
+		ID:       id,
+		FileName: a.FileName,
+		Key:      a.Key,
+		Size:     strconv.FormatInt(a.Size, 10),
+		SizeName: readableSize(a.Size),
+	}
+	if url != "" {
+		r.URL = &url
+	}
+	return &r
+}
+
+func newAttachmentUploadResponse(cipher *bitwarden.Cipher, id string, setting *settings.Settings) *attachmentUploadResponse {
+	return &attachmentUploadResponse{
+		Object:         "attachment-fileUpload",
+		AttachmentID:   id,
+		URL:            "/ciphers/" + cipher.ID() + "/attachment/" + id,
+		FileUploadType: fileUploadDirect,
+		CipherResponse: newCipherResponse(cipher, setting),
+	}
+}
+
+// readableSize returns the size in a human readable format, like the
+// bitwarden server does.
+func readableSize(size int64) string {
+	if size < 1024 {
+		return strconv.FormatInt(size, 10) + " Bytes"
+	}
+	units := []string{"KB", "MB", "GB"}
+	value := float64(size) / 1024
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	value = math.Round(value*100) / 100
+	return strconv.FormatFloat(value, 'f', -1, 64) + " " + units[i]
+}
+
+func getCipherForAttachment(inst *instance.Instance, id string) (*bitwarden.Cipher, error) {
+	cipher := &bitwarden.Cipher{}
+	if err := couchdb.GetDoc(inst, consts.BitwardenCiphers, id, cipher); err != nil {
+		return nil, err
+	}
+	return cipher, nil
+}
+
+func attachmentError(c echo.Context, err error) error {
+	switch {
+	case couchdb.IsNotFoundError(err), errors.Is(err, bitwarden.ErrAttachmentNotFound),
+		errors.Is(err, os.ErrNotExist):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "not found",
+		})
+	case errors.Is(err, bitwarden.ErrAttachmentSize),
+		errors.Is(err, bitwarden.ErrAttachmentOrganization),
+		errors.Is(err, vfs.ErrContentLengthMismatch):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, vfs.ErrFileTooBig), errors.Is(err, vfs.ErrMaxFileSize):
+		return c.JSON(http.StatusRequestEntityTooLarge, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": err.Error(),
+	})
+}
+
+// CreateAttachment is the handler for adding an attachment to a cipher. The
+// response tells the client where to upload the encrypted content.
+func CreateAttachment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	var req attachmentRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	if req.FileName == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "fileName is mandatory",
+		})
+	}
+
+	cipher, err := getCipherForAttachment(inst, c.Param("id"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	id, err := cipher.AddAttachment(inst, req.FileName, req.Key, req.FileSize)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	cipher.Metadata.ChangeUpdatedAt()
+	if err := couchdb.UpdateDoc(inst, cipher); err != nil {
+		return attachmentError(c, err)
+	}
+
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	_ = settings.UpdateRevisionDate(inst, setting)
+	res := newAttachmentUploadResponse(cipher, id, setting)
+	return c.JSON(http.StatusOK, res)
+}
+
+// RenewAttachmentUpload is the handler used by the clients to get again the
+// information for uploading the content of an attachment.
+func RenewAttachmentUpload(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	cipher, err := getCipherForAttachment(inst, c.Param("id"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	id := c.Param("attachment-id")
+	if a, ok := cipher.Attachments[id]; !ok || a.Uploaded {
+		return attachmentError(c, bitwarden.ErrAttachmentNotFound)
+	}
+
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	res := newAttachmentUploadResponse(cipher, id, setting)
+	return c.JSON(http.StatusOK, res)
+}
+
+// UploadAttachment is the handler for uploading the encrypted content of an
+// attachment, as the data field of a multipart form.
+func UploadAttachment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	cipher, err := getCipherForAttachment(inst, c.Param("id"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	if err := uploadAttachmentFromForm(c, inst, cipher, false); err != nil {
+		return err
+	}
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.NoContent(http.StatusOK)
+}
+
+// ShareAttachment is the handler used by the clients when a cipher is shared
+// with an organization: the content of the attachment has been encrypted
+// again with the key of the organization, and it is sent with its new key in
+// a multipart form. Only the cozy organization is accepted, as the content of
+// the attachments is not replicated for the other organizations.
+func ShareAttachment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+	orgID := c.QueryParam("organizationId")
+	if orgID == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "organizationId not provided",
+		})
+	}
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	if orgID != setting.OrganizationID {
+		return attachmentError(c, bitwarden.ErrAttachmentOrganization)
+	}
+
+	cipher, err := getCipherForAttachment(inst, c.Param("id"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	if err := uploadAttachmentFromForm(c, inst, cipher, true); err != nil {
+		return err
+	}
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.NoContent(http.StatusOK)
+}
+
+// uploadAttachmentFromForm reads the multipart form of the request, and
+// writes the content of the data field in the VFS. The key field is used only
+// when the attachment is shared with an organization.
+func uploadAttachmentFromForm(c echo.Context, inst *instance.Instance, cipher *bitwarden.Cipher, withKey bool) error {
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid multipart form",
+		})
+	}
+
+	var key string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		switch part.FormName() {
+		case "key":
+			if withKey {
+				raw, err := io.ReadAll(io.LimitReader(part, 4096))
+				if err != nil {
+					return attachmentError(c, err)
+				}
+				key = string(raw)
+			}
+		case "data":
+			if withKey && key == "" {
+				return c.JSON(http.StatusBadRequest, echo.Map{
+					"error": "key is mandatory",
+				})
+			}
+			err := cipher.UploadAttachment(inst, c.Param("attachment-id"), key, part)
+			if err != nil {
+				return attachmentError(c, err)
+			}
+			return nil
+		}
+	}
+	return c.JSON(http.StatusBadRequest, echo.Map{
+		"error": "data is mandatory",
+	})
+}
+
+// GetAttachment returns the metadata of an attachment, with an URL to
+// download its encrypted content.
+func GetAttachment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	cipher, err := getCipherForAttachment(inst, c.Param("id"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	id := c.Param("attachment-id")
+	url, err := cipher.AttachmentURL(inst, id)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	res := newAttachmentResponse(id, cipher.Attachments[id], url)
+	return c.JSON(http.StatusOK, res)
+}
+
+// DeleteAttachment is the handler for removing an attachment from a cipher.
+func DeleteAttachment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	cipher, err := getCipherForAttachment(inst, c.Param("id"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	if err := cipher.DeleteAttachment(inst, c.Param("attachment-id")); err != nil {
+		return attachmentError(c, err)
+	}
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.NoContent(http.StatusOK)
+}
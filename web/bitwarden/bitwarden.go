@@ -280,7 +280,7 @@ func getInitialCredentials(c echo.Context) error {
 		})
 	}
 
-	if inst.HasAuthMode(instance.TwoFactorMail) {
+	if inst.HasTwoFactor() {
 		if !checkTwoFactor(c, inst) {
 			return nil
 		}
@@ -409,6 +409,16 @@ func checkTwoFactor(c echo.Context, inst *instance.Instance) bool {
 		return true
 	}
 
+	// The bitwarden clients can't make a WebAuthn assertion, and a passcode
+	// sent by mail would be a weaker second factor than a passkey.
+	if inst.HasAuthMode(instance.Passkey) {
+		_ = c.JSON(http.StatusBadRequest, echo.Map{
+			"error":             "invalid_grant",
+			"error_description": "A passkey is required for the second factor.",
+		})
+		return false
+	}
+
 	email, err := inst.SettingsEMail()
 	if err != nil {
 		_ = c.JSON(http.StatusInternalServerError, echo.Map{
@@ -579,6 +589,14 @@ func Routes(router *echo.Group) {
 	ciphers.POST("/:id/share", ShareCipher)
 	ciphers.PUT("/:id/share", ShareCipher)
 
+	ciphers.POST("/:id/attachment/v2", CreateAttachment)
+	ciphers.GET("/:id/attachment/:attachment-id/renew", RenewAttachmentUpload)
+	ciphers.POST("/:id/attachment/:attachment-id", UploadAttachment)
+	ciphers.GET("/:id/attachment/:attachment-id", GetAttachment)
+	ciphers.DELETE("/:id/attachment/:attachment-id", DeleteAttachment)
+	ciphers.POST("/:id/attachment/:attachment-id/delete", DeleteAttachment)
+	ciphers.POST("/:id/attachment/:attachment-id/share", ShareAttachment)
+
 	folders := api.Group("/folders")
 	folders.GET("", ListFolders)
 	folders.POST("", CreateFolder)
@@ -588,6 +606,42 @@ func Routes(router *echo.Group) {
 	folders.DELETE("/:id", DeleteFolder)
 	folders.POST("/:id/delete", DeleteFolder)
 
+	sends := api.Group("/sends")
+	sends.GET("", ListSends)
+	sends.POST("", CreateSend)
+	sends.POST("/file/v2", CreateFileSend)
+	sends.GET("/:id", GetSend)
+	sends.PUT("/:id", UpdateSend)
+	sends.DELETE("/:id", DeleteSend)
+	sends.PUT("/:id/remove-password", RemoveSendPassword)
+	sends.GET("/:id/file/:file-id", RenewSendFileUpload)
+	sends.POST("/:id/file/:file-id", UploadSendFile)
+	sends.POST("/access/:access-id", AccessSend,
+		middlewares.RateLimitByKey(sendsAccessGroup, sendAccessKey("access-id"), sendsAccessLimit))
+	sends.POST("/:id/access/file/:file-id", AccessSendFile,
+		middlewares.RateLimitByKey(sendsAccessGroup, sendAccessKey("id"), sendsAccessLimit))
+
+	emergency := api.Group("/emergency-access")
+	emergency.GET("/trusted", ListTrustedEmergencyAccesses)
+	emergency.GET("/granted", ListGrantedEmergencyAccesses)
+	emergency.POST("/granted", SaveGrantedEmergencyAccess)
+	emergency.POST("/invite", InviteEmergencyAccess)
+	emergency.GET("/:id", GetEmergencyAccess)
+	emergency.POST("/:id", UpdateEmergencyAccess)
+	emergency.PUT("/:id", UpdateEmergencyAccess)
+	emergency.DELETE("/:id", DeleteEmergencyAccess)
+	emergency.POST("/:id/delete", DeleteEmergencyAccess)
+	emergency.POST("/:id/reinvite", ReinviteEmergencyAccess)
+	emergency.POST("/:id/confirm", ConfirmEmergencyAccess)
+	emergency.POST("/:id/approve", ApproveEmergencyAccess)
+	emergency.POST("/:id/reject", RejectEmergencyAccess)
+	emergency.POST("/:id/accept", AcceptEmergencyAccess)
+	emergency.POST("/:id/challenge", ChallengeEmergencyAccess)
+	emergency.POST("/:id/initiate", InitiateEmergencyAccess)
+	emergency.POST("/:id/view", ViewEmergencyAccess)
+	emergency.POST("/:id/takeover", TakeoverEmergencyAccess)
+	emergency.POST("/:id/password", PasswordEmergencyAccess)
+
 	orgs := api.Group("/organizations")
 	orgs.POST("", CreateOrganization)
 	orgs.GET("/:id", GetOrganization)
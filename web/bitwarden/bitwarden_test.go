@@ -1,10 +1,16 @@
 package bitwarden
 
 import (
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -369,6 +375,75 @@ func TestBitwarden(t *testing.T) {
 		obj.Value("OrganizationId").Null()
 	})
 
+	t.Run("Attachments", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		obj := e.POST("/bitwarden/api/ciphers/"+cipherID+"/attachment/v2").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{
+      "key": "2.wEVkDJPjXRVAsTo5GvtMOQ==|5s6Ec5OKwyB0WO9ZyR0uhw==|ju23RGWwQ6jvExSK7nrGrxkLNwP3RbuxwS3kJNEqVUI=",
+      "fileName": "2./ZXu4RPjXjMMo7HtR4JrvQ==|hYo0BQLzFhUJCAIV2bZw8w==|Nm0dtm9uePZrzqrzodGWfNQPUxD6rpDSJ34Bmm3gXMo=",
+      "fileSize": 11
+    }`)).
+			Expect().Status(200).
+			JSON().Object()
+
+		obj.ValueEqual("Object", "attachment-fileUpload")
+		obj.ValueEqual("FileUploadType", 0)
+		attachmentID := obj.Value("AttachmentId").String().NotEmpty().Raw()
+		obj.Value("CipherResponse").Object().Value("Attachments").Null()
+
+		e.POST("/bitwarden/api/ciphers/"+cipherID+"/attachment/"+attachmentID).
+			WithHeader("Authorization", "Bearer "+token).
+			WithMultipart().
+			WithFile("data", "blob", strings.NewReader("hello world")).
+			Expect().Status(200)
+
+		obj = e.GET("/bitwarden/api/ciphers/"+cipherID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		attachments := obj.Value("Attachments").Array()
+		attachments.Length().Equal(1)
+		attachment := attachments.First().Object()
+		attachment.ValueEqual("Id", attachmentID)
+		attachment.ValueEqual("Size", "11")
+		attachment.ValueEqual("SizeName", "11 Bytes")
+
+		obj = e.GET("/bitwarden/api/ciphers/"+cipherID+"/attachment/"+attachmentID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "attachment")
+		obj.Value("Url").String().Contains("/files/downloads/")
+
+		file, err := inst.VFS().FileByID(attachmentID)
+		require.NoError(t, err)
+		assert.Equal(t, consts.BitwardenDirID, file.DirID)
+		assert.True(t, file.Encrypted)
+
+		// The content of the attachments is not replicated for the other
+		// organizations than the cozy one
+		e.POST("/bitwarden/api/ciphers/"+cipherID+"/attachment/"+attachmentID+"/share").
+			WithQuery("organizationId", "38ac39d0-d48d-11e9-91bf-f37e45d48c79").
+			WithHeader("Authorization", "Bearer "+token).
+			WithMultipart().
+			WithFormField("key", "2.wEVkDJPjXRVAsTo5GvtMOQ==|5s6Ec5OKwyB0WO9ZyR0uhw==|ju23RGWwQ6jvExSK7nrGrxkLNwP3RbuxwS3kJNEqVUI=").
+			WithFile("data", "blob", strings.NewReader("HELLO WORLD")).
+			Expect().Status(400)
+
+		e.DELETE("/bitwarden/api/ciphers/"+cipherID+"/attachment/"+attachmentID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200)
+
+		e.GET("/bitwarden/api/ciphers/"+cipherID+"/attachment/"+attachmentID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+		_, err = inst.VFS().FileByID(attachmentID)
+		assert.Error(t, err)
+	})
+
 	t.Run("DeleteCipher", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -518,6 +593,330 @@ func TestBitwarden(t *testing.T) {
 		domains.ValueEqual("Object", "domains")
 	})
 
+	t.Run("Sends", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		deletionDate := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+
+		obj := e.POST("/bitwarden/api/sends").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{
+      "type": 0,
+      "name": "2.d7MttWzJTSSKx1qXjHUxlQ==|01Ath5UqFZHk7csk5DVtkQ==|EMLoLREgCUP5Cu4HqIhcLqhiZHn+NsUDp8dAg1Xu0Io=",
+      "key": "2.wEVkDJPjXRVAsTo5GvtMOQ==|5s6Ec5OKwyB0WO9ZyR0uhw==|ju23RGWwQ6jvExSK7nrGrxkLNwP3RbuxwS3kJNEqVUI=",
+      "text": {
+        "text": "2.T57BwAuV8ubIn/sZPbQC+A==|EhUSSpJWSzSYOdJ/AQzfXuUXxwzcs/6C4tOXqhWAqcM=|OWV2VIqLfoWPs9DiouXGUOtTEkVeklbtJQHkQFIXkC8=",
+        "hidden": false
+      },
+      "maxAccessCount": 2,
+      "password": "c2VuZC1wYXNzd29yZA==",
+      "deletionDate": "` + deletionDate + `"
+    }`)).
+			Expect().Status(200).
+			JSON().Object()
+
+		obj.ValueEqual("Object", "send")
+		obj.ValueEqual("Type", 0)
+		obj.ValueEqual("AccessCount", 0)
+		obj.Value("Password").String().NotEmpty()
+		sendID := obj.Value("Id").String().NotEmpty().Raw()
+		accessID := obj.Value("AccessId").String().NotEmpty().Raw()
+
+		obj = e.GET("/bitwarden/api/sends").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("Data").Array().Length().Equal(1)
+
+		e.POST("/bitwarden/api/sends/access/"+accessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{}`)).
+			Expect().Status(401)
+		e.POST("/bitwarden/api/sends/access/"+accessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"password": "d3Jvbmc="}`)).
+			Expect().Status(400)
+
+		obj = e.POST("/bitwarden/api/sends/access/"+accessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"password": "c2VuZC1wYXNzd29yZA=="}`)).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "send-access")
+		obj.ValueEqual("Id", accessID)
+		obj.ValueEqual("CreatorIdentifier", "me@bitwarden.example.net")
+		obj.Value("Text").Object().Value("Text").String().NotEmpty()
+
+		obj = e.PUT("/bitwarden/api/sends/"+sendID+"/remove-password").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("Password").Null()
+		obj.ValueEqual("AccessCount", 1)
+
+		e.POST("/bitwarden/api/sends/access/"+accessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{}`)).
+			Expect().Status(200)
+		e.POST("/bitwarden/api/sends/access/"+accessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{}`)).
+			Expect().Status(404)
+
+		obj = e.POST("/bitwarden/api/sends/file/v2").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{
+      "type": 1,
+      "name": "2.d7MttWzJTSSKx1qXjHUxlQ==|01Ath5UqFZHk7csk5DVtkQ==|EMLoLREgCUP5Cu4HqIhcLqhiZHn+NsUDp8dAg1Xu0Io=",
+      "key": "2.wEVkDJPjXRVAsTo5GvtMOQ==|5s6Ec5OKwyB0WO9ZyR0uhw==|ju23RGWwQ6jvExSK7nrGrxkLNwP3RbuxwS3kJNEqVUI=",
+      "file": {
+        "fileName": "2./ZXu4RPjXjMMo7HtR4JrvQ==|hYo0BQLzFhUJCAIV2bZw8w==|Nm0dtm9uePZrzqrzodGWfNQPUxD6rpDSJ34Bmm3gXMo="
+      },
+      "fileLength": 11,
+      "hideEmail": true,
+      "deletionDate": "` + deletionDate + `"
+    }`)).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "send-fileUpload")
+		send := obj.Value("SendResponse").Object()
+		fileSendID := send.Value("Id").String().NotEmpty().Raw()
+		fileAccessID := send.Value("AccessId").String().NotEmpty().Raw()
+		fileID := send.Value("File").Object().Value("Id").String().NotEmpty().Raw()
+		obj.ValueEqual("Url", "/sends/"+fileSendID+"/file/"+fileID)
+
+		e.POST("/bitwarden/api/sends/access/"+fileAccessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{}`)).
+			Expect().Status(404)
+
+		e.POST("/bitwarden/api/sends/"+fileSendID+"/file/"+fileID).
+			WithHeader("Authorization", "Bearer "+token).
+			WithMultipart().
+			WithFile("data", "blob", strings.NewReader("hello world")).
+			Expect().Status(200)
+
+		obj = e.POST("/bitwarden/api/sends/access/"+fileAccessID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{}`)).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("CreatorIdentifier").Null()
+		obj.Value("File").Object().ValueEqual("SizeName", "11 Bytes")
+
+		obj = e.POST("/bitwarden/api/sends/"+fileAccessID+"/access/file/"+fileID).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{}`)).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "send-fileDownload")
+		obj.Value("Url").String().Contains("/files/downloads/")
+
+		obj = e.GET("/bitwarden/api/sync").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("Sends").Array().Length().Equal(2)
+
+		e.DELETE("/bitwarden/api/sends/"+fileSendID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200)
+		_, err := inst.VFS().FileByID(fileID)
+		assert.Error(t, err)
+
+		// A send after its deletion date is removed by the worker
+		doc, err := bitwarden.FindSend(inst, sendID)
+		require.NoError(t, err)
+		doc.DeletionDate = time.Now().Add(-time.Minute)
+		require.NoError(t, couchdb.UpdateDoc(inst, doc))
+		msg := &bitwarden.CleanSendMessage{SendID: sendID}
+		require.NoError(t, bitwarden.CleanSend(inst, msg))
+		e.GET("/bitwarden/api/sends/"+sendID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+	})
+
+	t.Run("EmergencyAccess", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		obj := e.POST("/bitwarden/api/emergency-access/invite").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{"email": "grantee@example.net", "type": 1, "waitTimeDays": 7}`)).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "emergencyAccessGranteeDetails")
+		obj.ValueEqual("Status", 0)
+		obj.Value("GranteeId").Null()
+		accessID := obj.Value("Id").String().NotEmpty().Raw()
+
+		// The token is sent by mail to the grantee
+		access, err := bitwarden.FindEmergencyAccess(inst, accessID)
+		require.NoError(t, err)
+		hash, err := crypto.GenerateFromPassphrase([]byte("grantee-token"))
+		require.NoError(t, err)
+		access.TokenHash = string(hash)
+		require.NoError(t, couchdb.UpdateDoc(inst, access))
+
+		// The grantee has a RSA key pair for their bitwarden account
+		granteeKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&granteeKey.PublicKey)
+		require.NoError(t, err)
+		publicKey := base64.StdEncoding.EncodeToString(der)
+
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/accept").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"token": "wrong", "userId": "grantee-id", "publicKey": "` + publicKey + `"}`)).
+			Expect().Status(401)
+		granteeToken := e.POST("/bitwarden/api/emergency-access/"+accessID+"/accept").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"token": "grantee-token", "userId": "grantee-id", "publicKey": "` + publicKey + `"}`)).
+			Expect().Status(200).
+			JSON().Object().Value("token").String().NotEmpty().Raw()
+
+		// The token of the invitation can't be used twice
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/challenge").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"token": "grantee-token"}`)).
+			Expect().Status(401)
+
+		// signedRequest returns the body of a request of the grantee, with the
+		// signature of a new challenge
+		signedRequest := func() []byte {
+			challenge := e.POST("/bitwarden/api/emergency-access/"+accessID+"/challenge").
+				WithHeader("Content-Type", "application/json").
+				WithBytes([]byte(`{"token": "` + granteeToken + `"}`)).
+				Expect().Status(200).
+				JSON().Object().Value("challenge").String().NotEmpty().Raw()
+			hashed := sha256.Sum256([]byte(challenge))
+			sig, err := rsa.SignPKCS1v15(rand.Reader, granteeKey, stdcrypto.SHA256, hashed[:])
+			require.NoError(t, err)
+			signature := base64.StdEncoding.EncodeToString(sig)
+			return []byte(`{"token": "` + granteeToken + `", "signature": "` + signature + `"}`)
+		}
+
+		// The public key is kept on the emergency access, not as a contact
+		obj = e.GET("/bitwarden/api/emergency-access/"+accessID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Status", 1)
+		obj.ValueEqual("PublicKey", publicKey)
+		e.GET("/bitwarden/api/users/grantee-id/public-key").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/confirm").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{"key": "4.encrypted-key"}`)).
+			Expect().Status(200)
+
+		obj = e.GET("/bitwarden/api/emergency-access/"+accessID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Status", 2)
+		obj.ValueEqual("GranteeId", "grantee-id")
+
+		// The token is not enough without the signature of the challenge
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/initiate").
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"token": "` + granteeToken + `"}`)).
+			Expect().Status(401)
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/initiate").
+			WithHeader("Content-Type", "application/json").
+			WithBytes(signedRequest()).
+			Expect().Status(200)
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/view").
+			WithHeader("Content-Type", "application/json").
+			WithBytes(signedRequest()).
+			Expect().Status(400)
+
+		// A trigger for an old request does nothing
+		msg := &bitwarden.EmergencyAccessMessage{
+			EmergencyAccessID: accessID,
+			InitiatedAt:       time.Now().Add(-time.Hour),
+		}
+		require.NoError(t, bitwarden.ApproveAfterWaitTime(inst, msg))
+		access, err = bitwarden.FindEmergencyAccess(inst, accessID)
+		require.NoError(t, err)
+		assert.Equal(t, bitwarden.EmergencyAccessRecoveryInitiated, access.Status)
+
+		msg.InitiatedAt = *access.RecoveryInitiatedAt
+		require.NoError(t, bitwarden.ApproveAfterWaitTime(inst, msg))
+
+		body := signedRequest()
+		obj = e.POST("/bitwarden/api/emergency-access/"+accessID+"/view").
+			WithHeader("Content-Type", "application/json").
+			WithBytes(body).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "emergencyAccessView")
+		obj.ValueEqual("KeyEncrypted", "4.encrypted-key")
+		obj.Value("Ciphers").Array().NotEmpty()
+
+		// A challenge can be used only once
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/view").
+			WithHeader("Content-Type", "application/json").
+			WithBytes(body).
+			Expect().Status(401)
+
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/takeover").
+			WithHeader("Content-Type", "application/json").
+			WithBytes(signedRequest()).
+			Expect().Status(400)
+
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/reject").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200)
+		e.POST("/bitwarden/api/emergency-access/"+accessID+"/view").
+			WithHeader("Content-Type", "application/json").
+			WithBytes(signedRequest()).
+			Expect().Status(400)
+
+		obj = e.GET("/bitwarden/api/emergency-access/trusted").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("Data").Array().Length().Equal(1)
+
+		e.DELETE("/bitwarden/api/emergency-access/"+accessID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200)
+		e.GET("/bitwarden/api/emergency-access/"+accessID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+
+		// The client of the grantee saves the access on their cozy
+		e.POST("/bitwarden/api/emergency-access/granted").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{"cozy": "https://alice.example.net/", "id": "` + accessID + `", "token": "` + granteeToken + `", "email": "alice@example.net", "type": 1, "status": 1, "waitTimeDays": 7}`)).
+			Expect().Status(200)
+		obj = e.POST("/bitwarden/api/emergency-access/granted").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(`{"cozy": "https://alice.example.net", "id": "` + accessID + `", "token": "` + granteeToken + `", "email": "alice@example.net", "type": 1, "status": 2, "waitTimeDays": 7}`)).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("Object", "emergencyAccessGrantorDetails")
+		obj.ValueEqual("Cozy", "https://alice.example.net")
+
+		obj = e.GET("/bitwarden/api/emergency-access/granted").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("Data").Array().Length().Equal(1)
+		item := obj.Value("Data").Array().First().Object()
+		item.ValueEqual("Email", "alice@example.net")
+		item.ValueEqual("Status", 2)
+		item.ValueEqual("AccessId", accessID)
+		item.ValueEqual("Token", granteeToken)
+	})
+
 	t.Run("BulkDeleteCiphers", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/cozy/cozy-stack/model/bitwarden"
@@ -39,6 +40,10 @@ type cipherRequest struct {
 	SecureNote     bitwarden.MapData    `json:"securenote"`
 	Card           bitwarden.MapData    `json:"card"`
 	Identity       bitwarden.MapData    `json:"identity"`
+	Attachments2   map[string]struct {
+		FileName string `json:"fileName"`
+		Key      string `json:"key"`
+	} `json:"attachments2"`
 }
 
 func (r *cipherRequest) toCipher() (*bitwarden.Cipher, error) {
@@ -80,6 +85,26 @@ func (r *cipherRequest) toCipher() (*bitwarden.Cipher, error) {
 	return &c, nil
 }
 
+// keepAttachments copies the attachments of the old cipher to the new one,
+// with the file names and keys sent by the client (they can have been
+// encrypted again when the cipher is shared with an organization).
+func (r *cipherRequest) keepAttachments(cipher, old *bitwarden.Cipher) {
+	if len(old.Attachments) == 0 {
+		return
+	}
+	cipher.Attachments = old.Clone().(*bitwarden.Cipher).Attachments
+	for id, a := range cipher.Attachments {
+		if sent, ok := r.Attachments2[id]; ok {
+			if sent.FileName != "" {
+				a.FileName = sent.FileName
+			}
+			if sent.Key != "" {
+				a.Key = sent.Key
+			}
+		}
+	}
+}
+
 type importCipherRequest struct {
 	Ciphers             []cipherRequest `json:"ciphers"`
 	Folders             []folderRequest `json:"folders"`
@@ -121,7 +146,7 @@ type cipherResponse struct {
 	OrganizationID *string                `json:"OrganizationId"`
 	CollectionIDs  []string               `json:"CollectionIds"`
 	Fields         interface{}            `json:"Fields"`
-	Attachments    *string                `json:"Attachments"`
+	Attachments    []*attachmentResponse  `json:"Attachments"`
 	Login          *loginResponse         `json:"Login,omitempty"`
 	SecureNote     map[string]interface{} `json:"SecureNote,omitempty"`
 	Card           map[string]interface{} `json:"Card,omitempty"`
@@ -191,6 +216,17 @@ func newCipherResponse(c *bitwarden.Cipher, setting *settings.Settings) *cipherR
 		r.Fields = fields
 	}
 
+	ids := make([]string, 0, len(c.Attachments))
+	for id, a := range c.Attachments {
+		if a.Uploaded {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		r.Attachments = append(r.Attachments, newAttachmentResponse(id, c.Attachments[id], ""))
+	}
+
 	switch c.Type {
 	case bitwarden.LoginType:
 		if c.Login != nil {
@@ -485,6 +521,7 @@ func UpdateCipher(c echo.Context) error {
 		}
 	}
 
+	req.keepAttachments(cipher, old)
 	if old.Metadata != nil {
 		cipher.Metadata = old.Metadata.Clone()
 	}
@@ -542,6 +579,10 @@ func DeleteCipher(c echo.Context) error {
 			"error": err.Error(),
 		})
 	}
+	if err := cipher.DeleteAttachmentsContent(inst); err != nil {
+		inst.Logger().WithNamespace("bitwarden").
+			Warnf("Cannot delete the attachments of %s: %s", cipher.ID(), err)
+	}
 
 	_ = settings.UpdateRevisionDate(inst, nil)
 	return c.NoContent(http.StatusOK)
@@ -679,6 +720,12 @@ func BulkDeleteCiphers(c echo.Context) error {
 			"error": err.Error(),
 		})
 	}
+	for i := range ciphers {
+		if err := ciphers[i].DeleteAttachmentsContent(inst); err != nil {
+			inst.Logger().WithNamespace("bitwarden").
+				Warnf("Cannot delete the attachments of %s: %s", ciphers[i].ID(), err)
+		}
+	}
 
 	_ = settings.UpdateRevisionDate(inst, nil)
 	return c.NoContent(http.StatusOK)
@@ -871,6 +918,15 @@ func ShareCipher(c echo.Context) error {
 		}
 	}
 
+	// The content of the attachments is not replicated to the cozy instances
+	// of the other members of the organization
+	if cipher.OrganizationID != "" && len(old.Attachments) > 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": bitwarden.ErrAttachmentOrganization.Error(),
+		})
+	}
+
+	req.Cipher.keepAttachments(cipher, old)
 	if old.Metadata != nil {
 		cipher.Metadata = old.Metadata.Clone()
 	}
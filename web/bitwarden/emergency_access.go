@@ -0,0 +1,548 @@
+package bitwarden
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Auth/Models/Request/EmergencyAccessRequestModels.cs
+type emergencyAccessInviteRequest struct {
+	Email        string                        `json:"email"`
+	Type         bitwarden.EmergencyAccessType `json:"type"`
+	WaitTimeDays int                           `json:"waitTimeDays"`
+}
+
+type emergencyAccessUpdateRequest struct {
+	Type         bitwarden.EmergencyAccessType `json:"type"`
+	WaitTimeDays int                           `json:"waitTimeDays"`
+	KeyEncrypted string                        `json:"keyEncrypted"`
+}
+
+type emergencyAccessConfirmRequest struct {
+	Key string `json:"key"`
+}
+
+// The requests of the grantee are authenticated by their token, as the
+// grantee has no session on the instance of the grantor, and by the signature
+// of a challenge with their private key.
+type emergencyAccessGranteeRequest struct {
+	Token     string `json:"token"`
+	Signature string `json:"signature"`
+	UserID    string `json:"userId"`
+	PublicKey string `json:"publicKey"`
+}
+
+type emergencyAccessPasswordRequest struct {
+	Token                 string `json:"token"`
+	Signature             string `json:"signature"`
+	NewMasterPasswordHash string `json:"newMasterPasswordHash"`
+	Key                   string `json:"key"`
+}
+
+type emergencyGrantRequest struct {
+	Cozy         string                          `json:"cozy"`
+	ID           string                          `json:"id"`
+	Token        string                          `json:"token"`
+	Name         string                          `json:"name"`
+	Email        string                          `json:"email"`
+	Type         bitwarden.EmergencyAccessType   `json:"type"`
+	Status       bitwarden.EmergencyAccessStatus `json:"status"`
+	WaitTimeDays int                             `json:"waitTimeDays"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Auth/Models/Response/EmergencyAccessResponseModel.cs
+type emergencyAccessResponse struct {
+	ID           string                          `json:"Id"`
+	GranteeID    *string                         `json:"GranteeId"`
+	PublicKey    *string                         `json:"PublicKey"`
+	Name         *string                         `json:"Name"`
+	Email        string                          `json:"Email"`
+	Type         bitwarden.EmergencyAccessType   `json:"Type"`
+	Status       bitwarden.EmergencyAccessStatus `json:"Status"`
+	WaitTimeDays int                             `json:"WaitTimeDays"`
+	CreationDate time.Time                       `json:"CreationDate"`
+	Object       string                          `json:"Object"`
+}
+
+func newEmergencyAccessResponse(e *bitwarden.EmergencyAccess) *emergencyAccessResponse {
+	r := emergencyAccessResponse{
+		ID:           e.CouchID,
+		Email:        e.Email,
+		Type:         e.Type,
+		Status:       e.Status,
+		WaitTimeDays: e.WaitTimeDays,
+		Object:       "emergencyAccessGranteeDetails",
+	}
+	if e.GranteeID != "" {
+		r.GranteeID = &e.GranteeID
+	}
+	if e.GranteePublicKey != "" {
+		r.PublicKey = &e.GranteePublicKey
+	}
+	if e.Metadata != nil {
+		r.CreationDate = e.Metadata.CreatedAt.UTC()
+	}
+	return &r
+}
+
+type emergencyAccessList struct {
+	Data   []*emergencyAccessResponse `json:"Data"`
+	Object string                     `json:"Object"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Auth/Models/Response/EmergencyAccessResponseModel.cs
+// with the fields needed by the client to send the requests to the cozy of
+// the grantor.
+type emergencyGrantResponse struct {
+	ID           string                          `json:"Id"`
+	Name         *string                         `json:"Name"`
+	Email        *string                         `json:"Email"`
+	Type         bitwarden.EmergencyAccessType   `json:"Type"`
+	Status       bitwarden.EmergencyAccessStatus `json:"Status"`
+	WaitTimeDays int                             `json:"WaitTimeDays"`
+	CreationDate time.Time                       `json:"CreationDate"`
+	Cozy         string                          `json:"Cozy"`
+	AccessID     string                          `json:"AccessId"`
+	Token        string                          `json:"Token"`
+	Object       string                          `json:"Object"`
+}
+
+func newEmergencyGrantResponse(g *bitwarden.EmergencyGrant) *emergencyGrantResponse {
+	r := emergencyGrantResponse{
+		ID:           g.CouchID,
+		Type:         g.Type,
+		Status:       g.Status,
+		WaitTimeDays: g.WaitTimeDays,
+		Cozy:         g.Cozy,
+		AccessID:     g.AccessID,
+		Token:        g.Token,
+		Object:       "emergencyAccessGrantorDetails",
+	}
+	if g.GrantorName != "" {
+		r.Name = &g.GrantorName
+	}
+	if g.GrantorEmail != "" {
+		r.Email = &g.GrantorEmail
+	}
+	if g.Metadata != nil {
+		r.CreationDate = g.Metadata.CreatedAt.UTC()
+	}
+	return &r
+}
+
+type emergencyGrantList struct {
+	Data   []*emergencyGrantResponse `json:"Data"`
+	Object string                    `json:"Object"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Auth/Models/Response/EmergencyAccessResponseModel.cs
+type emergencyAccessViewResponse struct {
+	KeyEncrypted string            `json:"KeyEncrypted"`
+	Ciphers      []*cipherResponse `json:"Ciphers"`
+	Object       string            `json:"Object"`
+}
+
+type emergencyAccessTakeoverResponse struct {
+	KeyEncrypted  string `json:"KeyEncrypted"`
+	Kdf           int    `json:"Kdf"`
+	KdfIterations int    `json:"KdfIterations"`
+	Object        string `json:"Object"`
+}
+
+var (
+	errInvalidToken = errors.New("invalid token")
+	errInvalidJSON  = errors.New("invalid JSON")
+)
+
+func emergencyAccessError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errInvalidToken):
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errInvalidJSON):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, bitwarden.ErrEmergencyAccessNotFound), couchdb.IsNotFoundError(err):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "not found",
+		})
+	case errors.Is(err, bitwarden.ErrEmergencyAccessToken),
+		errors.Is(err, bitwarden.ErrEmergencyAccessProof):
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, bitwarden.ErrEmergencyAccessStatus),
+		errors.Is(err, bitwarden.ErrEmergencyAccessInvalid):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": err.Error(),
+	})
+}
+
+// getEmergencyAccessForGrantor checks the permissions of the grantor, and
+// loads the emergency access.
+func getEmergencyAccessForGrantor(c echo.Context, verb permission.Verb) (*bitwarden.EmergencyAccess, error) {
+	if err := middlewares.AllowWholeType(c, verb, consts.BitwardenProfiles); err != nil {
+		return nil, errInvalidToken
+	}
+	inst := middlewares.GetInstance(c)
+	return bitwarden.FindEmergencyAccess(inst, c.Param("id"))
+}
+
+// ListTrustedEmergencyAccesses is the route for listing the grantees of the
+// user.
+func ListTrustedEmergencyAccesses(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.BitwardenProfiles); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	accesses, err := bitwarden.FindAllEmergencyAccesses(inst)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	res := &emergencyAccessList{Object: "list", Data: []*emergencyAccessResponse{}}
+	for _, e := range accesses {
+		res.Data = append(res.Data, newEmergencyAccessResponse(e))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// ListGrantedEmergencyAccesses is the route for listing the emergency
+// accesses where the user is the grantee, as saved by their client (see
+// SaveGrantedEmergencyAccess).
+func ListGrantedEmergencyAccesses(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.BitwardenProfiles); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	grants, err := bitwarden.FindAllEmergencyGrants(inst)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	res := &emergencyGrantList{Object: "list", Data: []*emergencyGrantResponse{}}
+	for _, g := range grants {
+		res.Data = append(res.Data, newEmergencyGrantResponse(g))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// SaveGrantedEmergencyAccess is the route used by the client of the grantee
+// to save on their cozy an emergency access given by a grantor, after having
+// accepted the invitation on the cozy of the grantor, or to update it.
+func SaveGrantedEmergencyAccess(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenProfiles); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	var req emergencyGrantRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	g := &bitwarden.EmergencyGrant{
+		Cozy:         req.Cozy,
+		AccessID:     req.ID,
+		Token:        req.Token,
+		GrantorName:  req.Name,
+		GrantorEmail: req.Email,
+		Type:         req.Type,
+		Status:       req.Status,
+		WaitTimeDays: req.WaitTimeDays,
+	}
+	if err := bitwarden.SaveEmergencyGrant(inst, g); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, newEmergencyGrantResponse(g))
+}
+
+// InviteEmergencyAccess is the route for inviting a trusted contact as a
+// grantee. An invitation is sent to them by mail.
+func InviteEmergencyAccess(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenProfiles); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	var req emergencyAccessInviteRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	e, err := bitwarden.NewEmergencyAccess(req.Email, req.Type, req.WaitTimeDays)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	if err := e.Invite(inst); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, newEmergencyAccessResponse(e))
+}
+
+// ReinviteEmergencyAccess is the route for sending again the invitation to
+// the grantee, with a new token.
+func ReinviteEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.POST)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	if err := e.Invite(middlewares.GetInstance(c)); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// GetEmergencyAccess returns information about an emergency access.
+func GetEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.GET)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, newEmergencyAccessResponse(e))
+}
+
+// UpdateEmergencyAccess is the route for changing the type and the wait time
+// of an emergency access. The client can also send the key encrypted again
+// for the grantee.
+func UpdateEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.PUT)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+
+	var req emergencyAccessUpdateRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	inst := middlewares.GetInstance(c)
+	if err := e.Update(inst, req.Type, req.WaitTimeDays, req.KeyEncrypted); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, newEmergencyAccessResponse(e))
+}
+
+// DeleteEmergencyAccess is the route for removing an emergency access.
+func DeleteEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.DELETE)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	if err := e.Delete(middlewares.GetInstance(c)); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ConfirmEmergencyAccess is the route used by the grantor to give their key,
+// encrypted with the public key of the grantee (given in the PublicKey field
+// of the emergency access), after the grantee has accepted the invitation.
+func ConfirmEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.POST)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+
+	var req emergencyAccessConfirmRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	if err := e.Confirm(middlewares.GetInstance(c), req.Key); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ApproveEmergencyAccess is the route used by the grantor to give the access
+// to the grantee without waiting for the end of the wait time.
+func ApproveEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.POST)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	if err := e.Approve(middlewares.GetInstance(c)); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// RejectEmergencyAccess is the route used by the grantor to refuse the
+// access asked by the grantee.
+func RejectEmergencyAccess(c echo.Context) error {
+	e, err := getEmergencyAccessForGrantor(c, permission.POST)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	if err := e.Reject(middlewares.GetInstance(c)); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// getEmergencyAccessForGrantee loads the emergency access and decodes the
+// request of the grantee. It doesn't need a bearer token.
+func getEmergencyAccessForGrantee(c echo.Context, req interface{}) (*bitwarden.EmergencyAccess, error) {
+	if err := json.NewDecoder(c.Request().Body).Decode(req); err != nil {
+		return nil, errInvalidJSON
+	}
+	inst := middlewares.GetInstance(c)
+	return bitwarden.FindEmergencyAccess(inst, c.Param("id"))
+}
+
+// AcceptEmergencyAccess is the route used by the grantee to accept the
+// invitation. They send the identifier and the public key of their bitwarden
+// account, and they receive a new token for the next requests.
+func AcceptEmergencyAccess(c echo.Context) error {
+	var req emergencyAccessGranteeRequest
+	e, err := getEmergencyAccessForGrantee(c, &req)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	token, err := e.Accept(middlewares.GetInstance(c), req.Token, req.UserID, req.PublicKey)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"token": token,
+	})
+}
+
+// ChallengeEmergencyAccess is the route used by the grantee to get the
+// challenge that they must sign with their private key for their next
+// request.
+func ChallengeEmergencyAccess(c echo.Context) error {
+	var req emergencyAccessGranteeRequest
+	e, err := getEmergencyAccessForGrantee(c, &req)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	challenge, err := e.NewChallenge(middlewares.GetInstance(c), req.Token)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"challenge": challenge,
+	})
+}
+
+// InitiateEmergencyAccess is the route used by the grantee to ask for the
+// access. The grantor is notified.
+func InitiateEmergencyAccess(c echo.Context) error {
+	var req emergencyAccessGranteeRequest
+	e, err := getEmergencyAccessForGrantee(c, &req)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	if err := e.Initiate(middlewares.GetInstance(c), req.Token, req.Signature); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ViewEmergencyAccess is the route used by the grantee to read the ciphers of
+// the grantor, when a view access has been approved. The ciphers of the
+// organizations are not included.
+func ViewEmergencyAccess(c echo.Context) error {
+	var req emergencyAccessGranteeRequest
+	e, err := getEmergencyAccessForGrantee(c, &req)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+	if err := e.CheckGranted(inst, req.Token, req.Signature, bitwarden.EmergencyAccessView); err != nil {
+		return emergencyAccessError(c, err)
+	}
+
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	var ciphers []*bitwarden.Cipher
+	if err := couchdb.GetAllDocs(inst, consts.BitwardenCiphers, &couchdb.AllDocsRequest{}, &ciphers); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	res := &emergencyAccessViewResponse{
+		KeyEncrypted: e.KeyEncrypted,
+		Ciphers:      []*cipherResponse{},
+		Object:       "emergencyAccessView",
+	}
+	for _, cipher := range ciphers {
+		if cipher.OrganizationID == "" && cipher.DeletedDate == nil {
+			res.Ciphers = append(res.Ciphers, newCipherResponse(cipher, setting))
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// TakeoverEmergencyAccess is the route used by the grantee to get what they
+// need for choosing a new passphrase for the grantor, when a takeover access
+// has been approved.
+func TakeoverEmergencyAccess(c echo.Context) error {
+	var req emergencyAccessGranteeRequest
+	e, err := getEmergencyAccessForGrantee(c, &req)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+	if err := e.CheckGranted(inst, req.Token, req.Signature, bitwarden.EmergencyAccessTakeover); err != nil {
+		return emergencyAccessError(c, err)
+	}
+
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, &emergencyAccessTakeoverResponse{
+		KeyEncrypted:  e.KeyEncrypted,
+		Kdf:           setting.PassphraseKdf,
+		KdfIterations: setting.PassphraseKdfIterations,
+		Object:        "emergencyAccessTakeover",
+	})
+}
+
+// PasswordEmergencyAccess is the route used by the grantee to change the
+// passphrase of the grantor after a takeover. The sessions of the grantor are
+// closed.
+func PasswordEmergencyAccess(c echo.Context) error {
+	var req emergencyAccessPasswordRequest
+	e, err := getEmergencyAccessForGrantee(c, &req)
+	if err != nil {
+		return emergencyAccessError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+	pass := []byte(req.NewMasterPasswordHash)
+	if err := e.Takeover(inst, req.Token, req.Signature, pass, req.Key); err != nil {
+		return emergencyAccessError(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,585 @@
+package bitwarden
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Tools/Models/Request/SendRequestModel.cs
+type sendRequest struct {
+	Type           bitwarden.SendType `json:"type"`
+	FileLength     int64              `json:"fileLength"`
+	Name           string             `json:"name"`
+	Notes          string             `json:"notes"`
+	Key            string             `json:"key"`
+	MaxAccessCount *int               `json:"maxAccessCount"`
+	ExpirationDate *time.Time         `json:"expirationDate"`
+	DeletionDate   time.Time          `json:"deletionDate"`
+	File           *struct {
+		FileName string `json:"fileName"`
+	} `json:"file"`
+	Text *struct {
+		Text   string `json:"text"`
+		Hidden bool   `json:"hidden"`
+	} `json:"text"`
+	Password  string `json:"password"`
+	Disabled  bool   `json:"disabled"`
+	HideEmail bool   `json:"hideEmail"`
+}
+
+// update copies the fields of the request that can be changed in the Send.
+// The type and the file can't be changed after the creation.
+func (r *sendRequest) update(send *bitwarden.Send) error {
+	send.Name = r.Name
+	send.Notes = r.Notes
+	send.Key = r.Key
+	send.MaxAccessCount = r.MaxAccessCount
+	send.ExpirationDate = r.ExpirationDate
+	send.DeletionDate = r.DeletionDate
+	send.Disabled = r.Disabled
+	send.HideEmail = r.HideEmail
+	if send.Type == bitwarden.SendTypeText {
+		send.Text = &bitwarden.SendText{}
+		if r.Text != nil {
+			send.Text.Text = r.Text.Text
+			send.Text.Hidden = r.Text.Hidden
+		}
+	}
+	if r.Password != "" {
+		return send.SetPassword(r.Password)
+	}
+	return nil
+}
+
+func (r *sendRequest) toSend() (*bitwarden.Send, error) {
+	send := bitwarden.Send{Type: r.Type}
+	if err := r.update(&send); err != nil {
+		return nil, err
+	}
+	md := metadata.New()
+	md.DocTypeVersion = bitwarden.DocTypeVersion
+	send.Metadata = md
+	return &send, nil
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Tools/Models/SendFileModel.cs
+type sendFileResponse struct {
+	ID       string `json:"Id"`
+	FileName string `json:"FileName"`
+	Size     string `json:"Size"`
+	SizeName string `json:"SizeName"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Tools/Models/SendTextModel.cs
+type sendTextResponse struct {
+	Text   string `json:"Text"`
+	Hidden bool   `json:"Hidden"`
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Tools/Models/Response/SendResponseModel.cs
+type sendResponse struct {
+	ID             string             `json:"Id"`
+	AccessID       string             `json:"AccessId"`
+	Type           bitwarden.SendType `json:"Type"`
+	Name           string             `json:"Name"`
+	Notes          *string            `json:"Notes"`
+	File           *sendFileResponse  `json:"File"`
+	Text           *sendTextResponse  `json:"Text"`
+	Key            string             `json:"Key"`
+	MaxAccessCount *int               `json:"MaxAccessCount"`
+	AccessCount    int                `json:"AccessCount"`
+	Password       *string            `json:"Password"`
+	Disabled       bool               `json:"Disabled"`
+	RevisionDate   time.Time          `json:"RevisionDate"`
+	ExpirationDate *time.Time         `json:"ExpirationDate"`
+	DeletionDate   time.Time          `json:"DeletionDate"`
+	HideEmail      bool               `json:"HideEmail"`
+	Object         string             `json:"Object"`
+}
+
+func newSendFileResponse(send *bitwarden.Send) *sendFileResponse {
+	if send.File == nil {
+		return nil
+	}
+	return &sendFileResponse{
+		ID:       send.File.ID,
+		FileName: send.File.FileName,
+		Size:     strconv.FormatInt(send.File.Size, 10),
+		SizeName: readableSize(send.File.Size),
+	}
+}
+
+func newSendTextResponse(send *bitwarden.Send) *sendTextResponse {
+	if send.Text == nil {
+		return nil
+	}
+	return &sendTextResponse{
+		Text:   send.Text.Text,
+		Hidden: send.Text.Hidden,
+	}
+}
+
+func newSendResponse(send *bitwarden.Send) *sendResponse {
+	r := sendResponse{
+		ID:             send.CouchID,
+		AccessID:       send.AccessID(),
+		Type:           send.Type,
+		Name:           send.Name,
+		File:           newSendFileResponse(send),
+		Text:           newSendTextResponse(send),
+		Key:            send.Key,
+		MaxAccessCount: send.MaxAccessCount,
+		AccessCount:    send.AccessCount,
+		Disabled:       send.Disabled,
+		DeletionDate:   send.DeletionDate.UTC(),
+		HideEmail:      send.HideEmail,
+		Object:         "send",
+	}
+	if send.Notes != "" {
+		r.Notes = &send.Notes
+	}
+	if send.Password != "" {
+		r.Password = &send.Password
+	}
+	if send.ExpirationDate != nil {
+		date := send.ExpirationDate.UTC()
+		r.ExpirationDate = &date
+	}
+	if send.Metadata != nil {
+		r.RevisionDate = send.Metadata.UpdatedAt.UTC()
+	}
+	return &r
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Tools/Models/Response/SendAccessResponseModel.cs
+type sendAccessResponse struct {
+	ID                string             `json:"Id"`
+	Type              bitwarden.SendType `json:"Type"`
+	Name              string             `json:"Name"`
+	File              *sendFileResponse  `json:"File"`
+	Text              *sendTextResponse  `json:"Text"`
+	ExpirationDate    *time.Time         `json:"ExpirationDate"`
+	CreatorIdentifier *string            `json:"CreatorIdentifier"`
+	Object            string             `json:"Object"`
+}
+
+func newSendAccessResponse(inst *instance.Instance, send *bitwarden.Send) *sendAccessResponse {
+	r := sendAccessResponse{
+		ID:     send.AccessID(),
+		Type:   send.Type,
+		Name:   send.Name,
+		File:   newSendFileResponse(send),
+		Text:   newSendTextResponse(send),
+		Object: "send-access",
+	}
+	if send.ExpirationDate != nil {
+		date := send.ExpirationDate.UTC()
+		r.ExpirationDate = &date
+	}
+	if !send.HideEmail {
+		email := string(inst.PassphraseSalt())
+		r.CreatorIdentifier = &email
+	}
+	return &r
+}
+
+// https://github.com/bitwarden/server/blob/main/src/Api/Tools/Models/Response/SendFileUploadDataResponseModel.cs
+type sendFileUploadResponse struct {
+	URL            string        `json:"Url"`
+	FileUploadType int           `json:"FileUploadType"`
+	SendResponse   *sendResponse `json:"SendResponse"`
+	Object         string        `json:"Object"`
+}
+
+func newSendFileUploadResponse(send *bitwarden.Send) *sendFileUploadResponse {
+	return &sendFileUploadResponse{
+		URL:            "/sends/" + send.CouchID + "/file/" + send.File.ID,
+		FileUploadType: fileUploadDirect,
+		SendResponse:   newSendResponse(send),
+		Object:         "send-fileUpload",
+	}
+}
+
+type sendsList struct {
+	Data   []*sendResponse `json:"Data"`
+	Object string          `json:"Object"`
+}
+
+type sendAccessRequest struct {
+	Password string `json:"password"`
+}
+
+func sendError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, bitwarden.ErrSendNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "not found",
+		})
+	case errors.Is(err, bitwarden.ErrSendPasswordRequired):
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, bitwarden.ErrSendInvalidPassword),
+		errors.Is(err, bitwarden.ErrSendDeletionDate),
+		errors.Is(err, bitwarden.ErrSendExpirationDate):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return attachmentError(c, err)
+}
+
+// check returns an error message if a mandatory field is missing or invalid.
+func (r *sendRequest) check() string {
+	if r.Name == "" || r.Key == "" {
+		return "name and key are mandatory"
+	}
+	if r.MaxAccessCount != nil && *r.MaxAccessCount < 0 {
+		return "invalid maxAccessCount"
+	}
+	return ""
+}
+
+// ListSends is the route for listing the Sends.
+func ListSends(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	sends, err := bitwarden.FindAllSends(inst)
+	if err != nil {
+		return sendError(c, err)
+	}
+	res := &sendsList{Object: "list", Data: []*sendResponse{}}
+	for _, s := range sends {
+		res.Data = append(res.Data, newSendResponse(s))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// CreateSend is the route for creating a Send of the text type. The Sends of
+// the file type are created with CreateFileSend.
+func CreateSend(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	var req sendRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	if msg := req.check(); msg != "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": msg,
+		})
+	}
+	if req.Type != bitwarden.SendTypeText {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the file sends must be created with the file/v2 route",
+		})
+	}
+	send, err := req.toSend()
+	if err != nil {
+		return sendError(c, err)
+	}
+	if err := send.CheckDates(); err != nil {
+		return sendError(c, err)
+	}
+	if err := send.Create(inst); err != nil {
+		return sendError(c, err)
+	}
+
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.JSON(http.StatusOK, newSendResponse(send))
+}
+
+// CreateFileSend is the route for creating a Send of the file type. The
+// response tells the client where to upload the encrypted content.
+func CreateFileSend(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	var req sendRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	if msg := req.check(); msg != "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": msg,
+		})
+	}
+	if req.Type != bitwarden.SendTypeFile || req.File == nil || req.File.FileName == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "file is mandatory",
+		})
+	}
+	send, err := req.toSend()
+	if err != nil {
+		return sendError(c, err)
+	}
+	if err := send.CheckDates(); err != nil {
+		return sendError(c, err)
+	}
+	if err := send.AddFile(inst, req.File.FileName, req.FileLength); err != nil {
+		return sendError(c, err)
+	}
+	if err := send.Create(inst); err != nil {
+		return sendError(c, err)
+	}
+
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.JSON(http.StatusOK, newSendFileUploadResponse(send))
+}
+
+// RenewSendFileUpload is the route used by the clients to get again the
+// information for uploading the content of the file of a Send.
+func RenewSendFileUpload(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	send, err := bitwarden.FindSend(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	if send.File == nil || send.File.ID != c.Param("file-id") || send.File.Uploaded {
+		return sendError(c, bitwarden.ErrSendNotFound)
+	}
+	return c.JSON(http.StatusOK, newSendFileUploadResponse(send))
+}
+
+// UploadSendFile is the route for uploading the encrypted content of the
+// file of a Send, as the data field of a multipart form.
+func UploadSendFile(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	send, err := bitwarden.FindSend(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid multipart form",
+		})
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FormName() != "data" {
+			continue
+		}
+		if err := send.UploadFile(inst, c.Param("file-id"), part); err != nil {
+			return sendError(c, err)
+		}
+		_ = settings.UpdateRevisionDate(inst, nil)
+		return c.NoContent(http.StatusOK)
+	}
+	return c.JSON(http.StatusBadRequest, echo.Map{
+		"error": "data is mandatory",
+	})
+}
+
+// GetSend returns information about a single Send.
+func GetSend(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	send, err := bitwarden.FindSend(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.JSON(http.StatusOK, newSendResponse(send))
+}
+
+// UpdateSend is the route for changing a Send. The password is kept if the
+// request doesn't have a new one.
+func UpdateSend(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	send, err := bitwarden.FindSend(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	var req sendRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid JSON",
+		})
+	}
+	if msg := req.check(); msg != "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": msg,
+		})
+	}
+	if req.Type != send.Type {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the type of a send can't be changed",
+		})
+	}
+	old := send.Clone().(*bitwarden.Send)
+	if err := req.update(send); err != nil {
+		return sendError(c, err)
+	}
+	if err := send.CheckDates(); err != nil {
+		return sendError(c, err)
+	}
+	if err := send.Update(inst, old); err != nil {
+		return sendError(c, err)
+	}
+
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.JSON(http.StatusOK, newSendResponse(send))
+}
+
+// RemoveSendPassword is the route for removing the password of a Send.
+func RemoveSendPassword(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	send, err := bitwarden.FindSend(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	old := send.Clone().(*bitwarden.Send)
+	_ = send.SetPassword("")
+	if err := send.Update(inst, old); err != nil {
+		return sendError(c, err)
+	}
+
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.JSON(http.StatusOK, newSendResponse(send))
+}
+
+// DeleteSend is the route for deleting a Send, with its file.
+func DeleteSend(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	send, err := bitwarden.FindSend(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	if err := send.Delete(inst); err != nil {
+		return sendError(c, err)
+	}
+
+	_ = settings.UpdateRevisionDate(inst, nil)
+	return c.NoContent(http.StatusOK)
+}
+
+// sendsAccessGroup is the name of the group in the rate_limits configuration
+// for the anonymous accesses to the Sends.
+const sendsAccessGroup = "sends-access"
+
+// sendsAccessLimit is the default limit on the accesses to a Send, as each
+// attempt checks the password with scrypt.
+var sendsAccessLimit = limits.BucketConfig{Limit: 20, Period: time.Minute}
+
+// sendAccessKey returns the key for the rate limiting of the accesses to a
+// Send: its identifier, decoded from the access identifier in the given path
+// parameter.
+func sendAccessKey(param string) func(c echo.Context) string {
+	return func(c echo.Context) string {
+		id, err := bitwarden.SendIDFromAccessID(c.Param(param))
+		if err != nil {
+			return ""
+		}
+		return id
+	}
+}
+
+// AccessSend is the route used by the recipients of a Send to read it. It
+// doesn't need a token, but the password of the Send if it has one.
+func AccessSend(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	var req sendAccessRequest
+	_ = json.NewDecoder(c.Request().Body).Decode(&req)
+
+	send, err := bitwarden.FindSendByAccessID(inst, c.Param("access-id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	if err := send.Access(inst, req.Password); err != nil {
+		return sendError(c, err)
+	}
+	return c.JSON(http.StatusOK, newSendAccessResponse(inst, send))
+}
+
+// AccessSendFile is the route used by the recipients of a Send to get an URL
+// for downloading the encrypted content of its file. The id parameter is the
+// access identifier of the Send.
+func AccessSendFile(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	var req sendAccessRequest
+	_ = json.NewDecoder(c.Request().Body).Decode(&req)
+
+	send, err := bitwarden.FindSendByAccessID(inst, c.Param("id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	fileID := c.Param("file-id")
+	url, err := send.AccessFile(inst, fileID, req.Password)
+	if err != nil {
+		return sendError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"Id":     fileID,
+		"Url":    url,
+		"Object": "send-fileDownload",
+	})
+}
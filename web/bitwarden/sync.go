@@ -75,6 +75,7 @@ type syncResponse struct {
 	Ciphers     []*cipherResponse     `json:"Ciphers"`
 	Collections []*collectionResponse `json:"Collections"`
 	Domains     *domainsResponse      `json:"Domains"`
+	Sends       []*sendResponse       `json:"Sends"`
 	Object      string                `json:"Object"`
 }
 
@@ -85,6 +86,7 @@ func newSyncResponse(
 	ciphers []*bitwarden.Cipher,
 	folders []*bitwarden.Folder,
 	organizations []*bitwarden.Organization,
+	sends []*bitwarden.Send,
 	domains *domainsResponse,
 ) *syncResponse {
 	foldersResponse := make([]*folderResponse, len(folders))
@@ -99,12 +101,17 @@ func newSyncResponse(
 	for i, o := range organizations {
 		collectionsResponse[i] = newCollectionResponse(inst, o, &o.Collection)
 	}
+	sendsResponse := make([]*sendResponse, len(sends))
+	for i, s := range sends {
+		sendsResponse[i] = newSendResponse(s)
+	}
 	return &syncResponse{
 		Profile:     profile,
 		Folders:     foldersResponse,
 		Ciphers:     ciphersResponse,
 		Collections: collectionsResponse,
 		Domains:     domains,
+		Sends:       sendsResponse,
 		Object:      "sync",
 	}
 }
@@ -158,11 +165,18 @@ func Sync(c echo.Context) error {
 		})
 	}
 
+	sends, err := bitwarden.FindAllSends(inst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
 	var domains *domainsResponse
 	if c.QueryParam("excludeDomains") == "" {
 		domains = newDomainsResponse(setting)
 	}
 
-	res := newSyncResponse(inst, setting, profile, ciphers, folders, organizations, domains)
+	res := newSyncResponse(inst, setting, profile, ciphers, folders, organizations, sends, domains)
 	return c.JSON(http.StatusOK, res)
 }
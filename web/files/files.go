@@ -590,6 +590,13 @@ func CopyVersionHandler(c echo.Context) error {
 	newdoc.Tags = utils.SplitTrimString(c.QueryParam("Tags"), TagSeparator)
 	updateFileCozyMetadata(c, newdoc, true)
 
+	if dedup, ok := fs.(vfs.Deduplicator); ok {
+		if err = dedup.CreateFileWithContentOf(newdoc, olddoc, olddoc); err != nil {
+			return WrapVfsError(err)
+		}
+		return FileData(c, http.StatusOK, newdoc, true, nil)
+	}
+
 	content, err := fs.OpenFile(olddoc)
 	if err != nil {
 		return WrapVfsError(err)
@@ -1981,6 +1988,7 @@ func Routes(router *echo.Group) {
 	router.POST("/_all_docs", GetAllDocs)
 	router.POST("/_find", FindFilesMango)
 	router.GET("/_changes", ChangesFeed)
+	router.GET("/_search", SearchHandler)
 
 	router.HEAD("/:file-id", HeadDirOrFile)
 
@@ -1999,6 +2007,11 @@ func Routes(router *echo.Group) {
 	router.POST("/:file-id", CreationHandler)
 	router.PUT("/:file-id", OverwriteFileContentHandler)
 	router.POST("/upload/metadata", UploadMetadataHandler)
+	router.POST("/upload/sessions", CreateUploadSessionHandler)
+	router.HEAD("/upload/sessions/:session-id", HeadUploadSessionHandler)
+	router.PATCH("/upload/sessions/:session-id", PatchUploadSessionHandler)
+	router.POST("/upload/sessions/:session-id/finalize", FinalizeUploadSessionHandler)
+	router.DELETE("/upload/sessions/:session-id", AbortUploadSessionHandler)
 	router.POST("/:file-id/copy", FileCopyHandler)
 
 	router.GET("/:file-id/icon/:secret", IconHandler)
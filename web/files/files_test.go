@@ -506,6 +506,150 @@ func TestFiles(t *testing.T) {
 			Expect().Status(404)
 	})
 
+	t.Run("UploadSessions", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		// 1. Create the session, without the content
+		res := e.POST("/files/upload/sessions").
+			WithQuery("Name", "resumable.txt").
+			WithHeader("Upload-Length", "9").
+			WithHeader("Content-Type", "text/plain").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201)
+		res.Header("Upload-Offset").Equal("0")
+		res.Header("Upload-Expires").NotEmpty()
+
+		obj := res.JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object()
+		sessionID := obj.Path("$.data.id").String().NotEmpty().Raw()
+		obj.Path("$.data.type").Equal(consts.FilesUploads)
+		res.Header("Location").Equal("/files/upload/sessions/" + sessionID)
+
+		// 2. Send the chunks
+		e.PATCH("/files/upload/sessions/"+sessionID).
+			WithHeader("Upload-Offset", "0").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("foo")).
+			Expect().Status(204).
+			Header("Upload-Offset").Equal("3")
+
+		e.PATCH("/files/upload/sessions/"+sessionID).
+			WithHeader("Upload-Offset", "0").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("bar")).
+			Expect().Status(409).
+			Header("Upload-Offset").Equal("3")
+
+		e.HEAD("/files/upload/sessions/"+sessionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Header("Upload-Offset").Equal("3")
+
+		e.POST("/files/upload/sessions/"+sessionID+"/finalize").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(412)
+
+		e.PATCH("/files/upload/sessions/"+sessionID).
+			WithHeader("Upload-Offset", "3").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("barbazqux")).
+			Expect().Status(413).
+			Header("Upload-Offset").Equal("3")
+
+		e.PATCH("/files/upload/sessions/"+sessionID).
+			WithHeader("Upload-Offset", "3").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("barbaz")).
+			Expect().Status(204).
+			Header("Upload-Offset").Equal("9")
+
+		// 3. Finalize the upload
+		fileID := e.POST("/files/upload/sessions/"+sessionID+"/finalize").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().
+			Path("$.data.id").String().NotEmpty().Raw()
+
+		e.GET("/files/download/"+fileID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Body().Equal("foobarbaz")
+
+		e.HEAD("/files/upload/sessions/"+sessionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+
+		// 4. A session can be aborted
+		sessionID = e.POST("/files/upload/sessions").
+			WithQuery("FileID", fileID).
+			WithHeader("Upload-Length", "3").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().
+			Path("$.data.id").String().NotEmpty().Raw()
+
+		e.DELETE("/files/upload/sessions/"+sessionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+
+		e.PATCH("/files/upload/sessions/"+sessionID).
+			WithHeader("Upload-Offset", "0").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("qux")).
+			Expect().Status(404)
+	})
+
+	t.Run("UploadSessionsReserveTheQuota", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		used, err := testInstance.VFS().DiskUsage()
+		require.NoError(t, err)
+		lifecycle.Patch(testInstance, &lifecycle.Options{DiskQuota: used + 10})
+		defer lifecycle.Patch(testInstance, &lifecycle.Options{DiskQuota: -1})
+
+		sessionID := e.POST("/files/upload/sessions").
+			WithQuery("Name", "reserved.txt").
+			WithHeader("Upload-Length", "8").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().
+			Path("$.data.id").String().NotEmpty().Raw()
+
+		e.POST("/files/upload/sessions").
+			WithQuery("Name", "other-reserved.txt").
+			WithHeader("Upload-Length", "5").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(413)
+
+		e.POST("/files/").
+			WithQuery("Type", "file").
+			WithQuery("Name", "not-reserved.txt").
+			WithHeader("Content-Type", "text/plain").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("bazqux")).
+			Expect().Status(413)
+
+		e.DELETE("/files/upload/sessions/"+sessionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+
+		sessionID = e.POST("/files/upload/sessions").
+			WithQuery("Name", "other-reserved.txt").
+			WithHeader("Upload-Length", "5").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().
+			Path("$.data.id").String().NotEmpty().Raw()
+
+		e.DELETE("/files/upload/sessions/"+sessionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+	})
+
 	t.Run("UploadWithInvalidContentType", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
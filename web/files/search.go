@@ -0,0 +1,103 @@
+package files
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/search"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultSearchLimit = 30
+	maxSearchLimit     = 100
+)
+
+// SearchHandler is the handler for GET /files/_search. It makes a full-text
+// search on the names, tags and contents of the files, and returns the most
+// relevant files first. The files that the client is not allowed to see are
+// filtered out.
+func SearchHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return middlewares.ErrForbidden
+	}
+
+	query := c.QueryParam("q")
+	if len(search.Tokenize(query)) == 0 {
+		return jsonapi.InvalidParameter("q", errors.New("The query has no term to search"))
+	}
+
+	limit := defaultSearchLimit
+	if param := c.QueryParam("page[limit]"); param != "" {
+		if l, err := strconv.Atoi(param); err == nil && l > 0 {
+			limit = l
+		}
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	skip := 0
+	if param := c.QueryParam("page[skip]"); param != "" {
+		if s, err := strconv.Atoi(param); err == nil && s > 0 {
+			skip = s
+		}
+	}
+
+	hits, err := search.Search(inst, query)
+	if err != nil {
+		return err
+	}
+
+	out := make([]jsonapi.Object, 0)
+	fp := vfs.NewFilePatherWithCache(inst.VFS())
+	next := skip
+	for next < len(hits) && len(out) < limit {
+		end := next + limit - len(out)
+		if end > len(hits) {
+			end = len(hits)
+		}
+		ids := make([]string, 0, end-next)
+		for _, hit := range hits[next:end] {
+			ids = append(ids, hit.FileID)
+		}
+		next = end
+
+		var docs []*vfs.FileDoc
+		req := &couchdb.AllDocsRequest{Keys: ids}
+		if err := couchdb.GetAllDocs(inst, consts.Files, req, &docs); err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			// The index can be a bit late on the changes of the files
+			if doc == nil || doc.Type != consts.FileType || doc.Trashed {
+				continue
+			}
+			if err := checkPerm(c, permission.GET, nil, doc); err != nil {
+				continue
+			}
+			file := NewFile(doc, inst)
+			file.IncludePath(fp)
+			out = append(out, file)
+		}
+	}
+
+	var links jsonapi.LinksList
+	if next < len(hits) {
+		params := url.Values{
+			"q":           {query},
+			"page[limit]": {strconv.Itoa(limit)},
+			"page[skip]":  {strconv.Itoa(next)},
+		}
+		links.Next = "/files/_search?" + params.Encode()
+	}
+	return jsonapi.DataList(c, http.StatusOK, out, &links)
+}
@@ -0,0 +1,255 @@
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/upload"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type apiUploadSession struct {
+	session *upload.Session
+	offset  int64
+}
+
+type apiUploadSessionAttrs struct {
+	DirID     string    `json:"dir_id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	FileID    string    `json:"file_id,omitempty"`
+	Size      int64     `json:"size,string"`
+	Offset    int64     `json:"offset,string"`
+	MD5Sum    []byte    `json:"md5sum,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (s *apiUploadSession) ID() string                             { return s.session.ID() }
+func (s *apiUploadSession) Rev() string                            { return s.session.Rev() }
+func (s *apiUploadSession) DocType() string                        { return consts.FilesUploads }
+func (s *apiUploadSession) Clone() couchdb.Doc                     { return s }
+func (s *apiUploadSession) SetID(_ string)                         {}
+func (s *apiUploadSession) SetRev(_ string)                        {}
+func (s *apiUploadSession) Relationships() jsonapi.RelationshipMap { return nil }
+func (s *apiUploadSession) Included() []jsonapi.Object             { return nil }
+func (s *apiUploadSession) Links() *jsonapi.LinksList              { return s.session.Links() }
+func (s *apiUploadSession) MarshalJSON() ([]byte, error) {
+	attrs := apiUploadSessionAttrs{
+		Name:      s.session.File.DocName,
+		DirID:     s.session.File.DirID,
+		Size:      s.session.File.ByteSize,
+		Offset:    s.offset,
+		MD5Sum:    s.session.File.MD5Sum,
+		ExpiresAt: s.session.ExpiresAt,
+	}
+	if s.session.IsOverwrite() {
+		attrs.FileID = s.session.File.ID()
+	}
+	return json.Marshal(attrs)
+}
+
+// CreateUploadSessionHandler handles POST requests on /files/upload/sessions
+// to start a resumable upload. The content of the file is not sent with this
+// request, but later in one or more chunks.
+func CreateUploadSessionHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+
+	size, err := strconv.ParseInt(c.Request().Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		return jsonapi.InvalidParameter("Upload-Length", errors.New("Invalid Upload-Length"))
+	}
+
+	var doc, olddoc *vfs.FileDoc
+	if fileID := c.QueryParam("FileID"); fileID != "" {
+		olddoc, err = fs.FileByID(fileID)
+		if err != nil {
+			return WrapVfsError(err)
+		}
+		if err := CheckIfMatch(c, olddoc.Rev()); err != nil {
+			return WrapVfsError(err)
+		}
+		doc, err = FileDocFromReq(c, olddoc.DocName, olddoc.DirID)
+		if err != nil {
+			return WrapVfsError(err)
+		}
+		if updated := c.QueryParam("UpdatedAt"); updated != "" {
+			if at, err2 := time.Parse(time.RFC3339, updated); err2 == nil {
+				doc.UpdatedAt = at
+			}
+		}
+		doc.ReferencedBy = olddoc.ReferencedBy
+		if olddoc.CozyMetadata != nil {
+			doc.CozyMetadata = olddoc.CozyMetadata.Clone()
+		}
+		updateFileCozyMetadata(c, doc, true)
+		if err := checkPerm(c, permission.PUT, nil, olddoc); err != nil {
+			return err
+		}
+		doc.SetID(olddoc.ID())
+		if err := checkPerm(c, permission.PUT, nil, doc); err != nil {
+			return err
+		}
+	} else {
+		doc, err = FileDocFromReq(c, c.QueryParam("Name"), c.QueryParam("DirID"))
+		if err != nil {
+			return WrapVfsError(err)
+		}
+		if created := c.QueryParam("CreatedAt"); created != "" {
+			if at, err2 := time.Parse(time.RFC3339, created); err2 == nil {
+				doc.CreatedAt = at
+			}
+		}
+		if updated := c.QueryParam("UpdatedAt"); updated != "" {
+			if at, err3 := time.Parse(time.RFC3339, updated); err3 == nil {
+				doc.UpdatedAt = at
+			}
+		}
+		doc.CozyMetadata, _ = CozyMetadataFromClaims(c, true)
+		if err := checkPerm(c, permission.POST, nil, doc); err != nil {
+			return err
+		}
+		// Fail early instead of after the upload of all the chunks
+		exists, err := fs.GetIndexer().DirChildExists(doc.DirID, doc.DocName)
+		if err != nil {
+			return WrapVfsError(err)
+		}
+		if exists {
+			return WrapVfsError(vfs.ErrConflict)
+		}
+	}
+	doc.ByteSize = size
+
+	session, err := upload.NewSession(inst, doc, olddoc)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	setUploadHeaders(c, session, 0)
+	c.Response().Header().Set(echo.HeaderLocation, session.Links().Self)
+	return jsonapi.Data(c, http.StatusCreated, &apiUploadSession{session, 0}, nil)
+}
+
+// HeadUploadSessionHandler handles HEAD requests on
+// /files/upload/sessions/:session-id to know how many bytes have already been
+// received by the stack.
+func HeadUploadSessionHandler(c echo.Context) error {
+	session, err := getUploadSession(c)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	offset, err := session.Offset()
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	setUploadHeaders(c, session, offset)
+	c.Response().Header().Set("Upload-Length", strconv.FormatInt(session.File.ByteSize, 10))
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.NoContent(http.StatusOK)
+}
+
+// PatchUploadSessionHandler handles PATCH requests on
+// /files/upload/sessions/:session-id to send a chunk of the file content. The
+// Upload-Offset header must be the number of bytes already received.
+func PatchUploadSessionHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	session, err := getUploadSession(c)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return jsonapi.InvalidParameter("Upload-Offset", errors.New("Invalid Upload-Offset"))
+	}
+	offset, err = session.WriteChunk(inst, offset, c.Request().Body)
+	setUploadHeaders(c, session, offset)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// FinalizeUploadSessionHandler handles POST requests on
+// /files/upload/sessions/:session-id/finalize to create or update the file
+// with the uploaded content.
+func FinalizeUploadSessionHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	session, err := getUploadSession(c)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	var md5sum []byte
+	if md5Str := c.Request().Header.Get("Content-MD5"); md5Str != "" {
+		if md5sum, err = parseMD5Hash(md5Str); err != nil {
+			return jsonapi.InvalidParameter("Content-MD5", err)
+		}
+	}
+	doc, err := session.Finalize(inst, md5sum)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	status := http.StatusCreated
+	if session.IsOverwrite() {
+		status = http.StatusOK
+	}
+	return FileData(c, status, doc, false, nil)
+}
+
+// AbortUploadSessionHandler handles DELETE requests on
+// /files/upload/sessions/:session-id to cancel an upload.
+func AbortUploadSessionHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	session, err := getUploadSession(c)
+	if err != nil {
+		return wrapUploadError(err)
+	}
+	if err := session.Abort(inst); err != nil {
+		return wrapUploadError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getUploadSession returns the upload session of the request, after checking
+// that the client has the permission to upload the file.
+func getUploadSession(c echo.Context) (*upload.Session, error) {
+	inst := middlewares.GetInstance(c)
+	session, err := upload.Get(inst, c.Param("session-id"))
+	if err != nil {
+		return nil, err
+	}
+	verb := permission.POST
+	if session.IsOverwrite() {
+		verb = permission.PUT
+	}
+	if err := checkPerm(c, verb, nil, session.File); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func setUploadHeaders(c echo.Context, session *upload.Session, offset int64) {
+	header := c.Response().Header()
+	header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	header.Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func wrapUploadError(err error) error {
+	switch err {
+	case upload.ErrSessionNotFound:
+		return jsonapi.NotFound(err)
+	case upload.ErrOffsetMismatch:
+		return jsonapi.Conflict(err)
+	case upload.ErrChunkTooLarge:
+		return jsonapi.Errorf(http.StatusRequestEntityTooLarge, "%s", err)
+	case upload.ErrIncomplete:
+		return jsonapi.PreconditionFailed("Upload-Offset", err)
+	}
+	return WrapVfsError(err)
+}
@@ -467,6 +467,22 @@ func cleanSessions(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func rotateStorageKey(c echo.Context) error {
+	domain := c.Param("domain")
+	inst, err := lifecycle.GetInstance(domain)
+	if err != nil {
+		return err
+	}
+
+	if err := lifecycle.RotateStorageKey(inst); err != nil {
+		if errors.Is(err, lifecycle.ErrStorageNotEncrypted) {
+			return jsonapi.BadRequest(err)
+		}
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 func lastActivity(c echo.Context) error {
 	inst, err := instance.Get(c.Param("domain"))
 	if err != nil {
@@ -769,6 +785,7 @@ func Routes(router *echo.Group) {
 	router.GET("/:domain/swift-prefix", getSwiftBucketName)
 	router.GET("/:domain/sharings/:sharing-id/unxor/:doc-id", unxorID)
 	router.POST("/:domain/notifications", sendNotification)
+	router.POST("/:domain/rotate-storage-key", rotateStorageKey)
 
 	// Config
 	router.POST("/redis", rebuildRedis)
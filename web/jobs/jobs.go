@@ -30,19 +30,23 @@ import (
 
 	// import workers
 	_ "github.com/cozy/cozy-stack/worker/archive"
+	_ "github.com/cozy/cozy-stack/worker/bitwarden"
 	"github.com/cozy/cozy-stack/worker/exec"
 	_ "github.com/cozy/cozy-stack/worker/log"
 	_ "github.com/cozy/cozy-stack/worker/mails"
 	_ "github.com/cozy/cozy-stack/worker/migrations"
 	_ "github.com/cozy/cozy-stack/worker/moves"
 	_ "github.com/cozy/cozy-stack/worker/notes"
+	_ "github.com/cozy/cozy-stack/worker/notifications"
 	_ "github.com/cozy/cozy-stack/worker/oauth"
 	_ "github.com/cozy/cozy-stack/worker/push"
 	_ "github.com/cozy/cozy-stack/worker/rag"
+	_ "github.com/cozy/cozy-stack/worker/search"
 	_ "github.com/cozy/cozy-stack/worker/share"
 	_ "github.com/cozy/cozy-stack/worker/sms"
 	_ "github.com/cozy/cozy-stack/worker/thumbnail"
 	_ "github.com/cozy/cozy-stack/worker/trash"
+	_ "github.com/cozy/cozy-stack/worker/uploads"
 )
 
 type (
@@ -54,11 +58,30 @@ type (
 		Manual      bool            `json:"manual"`
 		ForwardLogs bool            `json:"forward_logs"`
 		Options     *apiJobOptions  `json:"options"`
+		DependsOn   []string        `json:"depends_on"`
+		Priority    job.Priority    `json:"priority"`
 	}
 	apiJobOptions struct {
 		MaxExecCount int `json:"max_exec_count"`
 		Timeout      int `json:"timeout"`
 	}
+	apiWorkflow struct {
+		w    *job.Workflow
+		jobs map[string]*job.Job
+	}
+	apiWorkflowRequest struct {
+		Name string               `json:"name"`
+		Jobs []apiWorkflowJobStep `json:"jobs"`
+	}
+	apiWorkflowJobStep struct {
+		Key        string          `json:"key"`
+		WorkerType string          `json:"worker"`
+		Arguments  json.RawMessage `json:"arguments"`
+		Manual     bool            `json:"manual"`
+		Options    *apiJobOptions  `json:"options"`
+		DependsOn  []string        `json:"depends_on"`
+		Priority   job.Priority    `json:"priority"`
+	}
 	apiSupport struct {
 		Arguments map[string]string `json:"arguments"`
 	}
@@ -82,13 +105,14 @@ type (
 		s *job.TriggerState
 	}
 	apiTriggerRequest struct {
-		Type            string          `json:"type"`
-		Arguments       string          `json:"arguments"`
-		WorkerType      string          `json:"worker"`
-		Message         json.RawMessage `json:"message"`
-		WorkerArguments json.RawMessage `json:"worker_arguments"`
-		Debounce        string          `json:"debounce"`
-		Options         *job.JobOptions `json:"options"`
+		Type            string           `json:"type"`
+		Arguments       string           `json:"arguments"`
+		WorkerType      string           `json:"worker"`
+		Message         json.RawMessage  `json:"message"`
+		WorkerArguments json.RawMessage  `json:"worker_arguments"`
+		Debounce        string           `json:"debounce"`
+		Filter          *job.EventFilter `json:"filter"`
+		Options         *job.JobOptions  `json:"options"`
 	}
 )
 
@@ -108,6 +132,36 @@ func (j apiJob) MarshalJSON() ([]byte, error) {
 	return json.Marshal(j.j)
 }
 
+func (w apiWorkflow) ID() string                             { return w.w.ID() }
+func (w apiWorkflow) Rev() string                            { return w.w.Rev() }
+func (w apiWorkflow) DocType() string                        { return consts.JobsWorkflows }
+func (w apiWorkflow) Clone() couchdb.Doc                     { return w }
+func (w apiWorkflow) SetID(_ string)                         {}
+func (w apiWorkflow) SetRev(_ string)                        {}
+func (w apiWorkflow) Relationships() jsonapi.RelationshipMap { return nil }
+func (w apiWorkflow) Included() []jsonapi.Object             { return nil }
+func (w apiWorkflow) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/jobs/workflows/" + w.ID()}
+}
+
+func (w apiWorkflow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*job.Workflow
+		State job.State           `json:"state"`
+		Jobs  map[string]*job.Job `json:"jobs"`
+	}{w.w, job.WorkflowState(w.jobs), w.jobs})
+}
+
+func (o *apiJobOptions) jobOptions() *job.JobOptions {
+	if o == nil {
+		return nil
+	}
+	return &job.JobOptions{
+		MaxExecCount: o.MaxExecCount,
+		Timeout:      time.Duration(o.Timeout) * time.Second,
+	}
+}
+
 func (q apiQueue) ID() string      { return q.workerType }
 func (q apiQueue) DocType() string { return consts.Jobs }
 func (q apiQueue) Fetch(field string) []string {
@@ -201,20 +255,15 @@ func (h *HTTPHandler) pushJob(c echo.Context) error {
 	if _, err := jsonapi.Bind(c.Request().Body, &req); err != nil {
 		return wrapJobsError(err)
 	}
-	var opts *job.JobOptions
-	if req.Options != nil {
-		opts = &job.JobOptions{
-			MaxExecCount: req.Options.MaxExecCount,
-			Timeout:      time.Duration(req.Options.Timeout) * time.Second,
-		}
-	}
 
 	jr := &job.JobRequest{
 		WorkerType:  c.Param("worker-type"),
-		Options:     opts,
+		Options:     req.Options.jobOptions(),
 		Manual:      req.Manual,
+		Priority:    req.Priority,
 		ForwardLogs: req.ForwardLogs,
 		Message:     job.Message(req.Arguments),
+		DependsOn:   req.DependsOn,
 	}
 
 	if err := middlewares.Allow(c, permission.POST, jr); err != nil {
@@ -242,6 +291,80 @@ func (h *HTTPHandler) pushJob(c echo.Context) error {
 	return jsonapi.Data(c, http.StatusAccepted, apiJob{j}, nil)
 }
 
+func (h *HTTPHandler) pushWorkflow(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+
+	req := apiWorkflowRequest{}
+	if _, err := jsonapi.Bind(c.Request().Body, &req); err != nil {
+		return wrapJobsError(err)
+	}
+	if len(req.Jobs) == 0 {
+		return jsonapi.InvalidAttribute("jobs", errors.New("A workflow must have at least one job"))
+	}
+
+	permd, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+
+	steps := make([]job.WorkflowStep, len(req.Jobs))
+	for i, step := range req.Jobs {
+		jr := &job.JobRequest{
+			WorkerType: step.WorkerType,
+			Options:    step.Options.jobOptions(),
+			Manual:     step.Manual,
+			Priority:   step.Priority,
+			Message:    job.Message(step.Arguments),
+		}
+		if err := middlewares.Allow(c, permission.POST, jr); err != nil {
+			return err
+		}
+		if permd.Type != permission.TypeCLI {
+			if err := checkReservedWorker(jr.WorkerType); err != nil {
+				return err
+			}
+		}
+		steps[i] = job.WorkflowStep{
+			Key:       step.Key,
+			Request:   jr,
+			DependsOn: step.DependsOn,
+		}
+	}
+
+	w, err := job.PushWorkflow(instance, req.Name, steps)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	jobs, err := w.FetchJobs(instance)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	return jsonapi.Data(c, http.StatusAccepted, apiWorkflow{w, jobs}, nil)
+}
+
+func (h *HTTPHandler) getWorkflow(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	w, err := job.GetWorkflow(instance, c.Param("workflow-id"))
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	jobs, err := w.FetchJobs(instance)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	if len(jobs) == 0 {
+		if err := middlewares.AllowWholeType(c, permission.GET, consts.Jobs); err != nil {
+			return err
+		}
+	}
+	for _, j := range jobs {
+		if err := middlewares.Allow(c, permission.GET, j); err != nil {
+			return err
+		}
+	}
+	return jsonapi.Data(c, http.StatusOK, apiWorkflow{w, jobs}, nil)
+}
+
 func (h *HTTPHandler) contactSupport(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 
@@ -314,6 +437,9 @@ func (h *HTTPHandler) newTrigger(c echo.Context) error {
 			return jsonapi.InvalidAttribute("debounce", err)
 		}
 	}
+	if req.Filter != nil && req.Type != "@event" {
+		return jsonapi.InvalidAttribute("filter", errors.New("a filter can be used only with @event triggers"))
+	}
 
 	// Handle metadata
 	md := metadata.New()
@@ -335,6 +461,7 @@ func (h *HTTPHandler) newTrigger(c echo.Context) error {
 		Domain:     instance.Domain,
 		Arguments:  req.Arguments,
 		Debounce:   req.Debounce,
+		Filter:     req.Filter,
 		Options:    req.Options,
 		Metadata:   md,
 	}, msg)
@@ -712,10 +839,78 @@ func (h *HTTPHandler) patchJob(c echo.Context) error {
 	if err != nil {
 		return wrapJobsError(err)
 	}
+	if err := job.ReleaseDependents(j); err != nil {
+		log.Errorf("error while releasing the dependent jobs: %s", err)
+	}
 
 	return jsonapi.Data(c, http.StatusOK, apiJob{j}, nil)
 }
 
+func (h *HTTPHandler) getDeadLetters(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	workerType := c.QueryParam("worker")
+	if workerType != "" {
+		if err := middlewares.Allow(c, permission.GET, apiQueue{workerType: workerType}); err != nil {
+			return err
+		}
+	} else if err := middlewares.AllowWholeType(c, permission.GET, consts.Jobs); err != nil {
+		return err
+	}
+
+	js, err := job.GetDeadLetters(instance, workerType)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+
+	objs := make([]jsonapi.Object, len(js))
+	for i, j := range js {
+		objs[i] = apiJob{j}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func (h *HTTPHandler) requeueDeadLetter(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	j, err := job.Get(instance, c.Param("job-id"))
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	if err := middlewares.Allow(c, permission.POST, j); err != nil {
+		return err
+	}
+	permd, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	if permd.Type != permission.TypeCLI {
+		if err := checkReservedWorker(j.WorkerType); err != nil {
+			return err
+		}
+	}
+
+	j, err = job.RequeueDeadLetter(instance, j.ID())
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	return jsonapi.Data(c, http.StatusAccepted, apiJob{j}, nil)
+}
+
+func (h *HTTPHandler) discardDeadLetter(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	j, err := job.Get(instance, c.Param("job-id"))
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	if err := middlewares.Allow(c, permission.DELETE, j); err != nil {
+		return err
+	}
+
+	if err := job.DiscardDeadLetter(instance, j.ID()); err != nil {
+		return wrapJobsError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (h *HTTPHandler) cleanJobs(c echo.Context) error {
 	instance := middlewares.GetInstance(c)
 	if err := middlewares.AllowWholeType(c, permission.POST, consts.Jobs); err != nil {
@@ -804,9 +999,13 @@ func (h *HTTPHandler) purgeJobs(c echo.Context) error {
 	// Step 3: cleaning.
 	// - Removing jobs from the ids if they exists.
 	// - Skipping worker types
+	// - Keeping the dead letters, they are deleted only when discarded
 	var finalJobs []*job.Job
 
 	for _, j := range jobsBeforeDate {
+		if j.State == job.DeadLetter {
+			continue
+		}
 		validWorker := false
 
 		for _, wt := range workers {
@@ -865,24 +1064,61 @@ func (h *HTTPHandler) Register(router *echo.Group) {
 	router.POST("/triggers/:trigger-id/launch", h.launchTrigger)
 	router.DELETE("/triggers/:trigger-id", h.deleteTrigger)
 
+	router.POST("/workflows", h.pushWorkflow)
+	router.GET("/workflows/:workflow-id", h.getWorkflow)
+
 	router.POST("/webhooks/bi", h.fireBIWebhook)
 	router.POST("/webhooks/:trigger-id", h.fireWebhook)
 
+	router.GET("/dead-letters", h.getDeadLetters)
+	router.POST("/dead-letters/:job-id/requeue", h.requeueDeadLetter)
+	router.DELETE("/dead-letters/:job-id", h.discardDeadLetter)
+
 	router.POST("/clean", h.cleanJobs)
 	router.DELETE("/purge", h.purgeJobs)
 	router.GET("/:job-id", h.getJob)
 	router.PATCH("/:job-id", h.patchJob)
 }
 
+// AdminRoutes sets the routing for the jobs on the admin port.
+func AdminRoutes(router *echo.Group) {
+	router.GET("/queues", getQueuesStats)
+}
+
+// getQueuesStats returns the length of the queues of the workers, with the
+// instances that have the most queued jobs.
+func getQueuesStats(c echo.Context) error {
+	stats, err := job.GetQueuesStats()
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	if stats == nil {
+		stats = []*job.QueueStats{}
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
 func wrapJobsError(err error) error {
+	if errors.Is(err, job.ErrInvalidFilter) {
+		return jsonapi.InvalidAttribute("filter", err)
+	}
 	switch err {
 	case job.ErrNotFoundTrigger,
 		job.ErrNotFoundJob,
+		job.ErrNotFoundWorkflow,
 		job.ErrUnknownWorker:
 		return jsonapi.NotFound(err)
 	case job.ErrUnknownTrigger,
 		job.ErrNotCronTrigger:
 		return jsonapi.InvalidAttribute("Type", err)
+	case job.ErrInvalidPriority:
+		return jsonapi.InvalidAttribute("priority", err)
+	case job.ErrNotDeadLetter:
+		return jsonapi.Conflict(err)
+	case job.ErrInvalidDependency:
+		return jsonapi.InvalidAttribute("depends_on", err)
+	case job.ErrInvalidWorkflow:
+		return jsonapi.InvalidAttribute("jobs", err)
 	case emailer.ErrMissingSubject,
 		emailer.ErrMissingContent,
 		limits.ErrRateLimitReached,
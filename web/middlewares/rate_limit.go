@@ -0,0 +1,110 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RateLimit returns a middleware that limits the number of requests on a
+// group of routes. There is a token bucket for each instance and each client
+// (OAuth client, webapp or konnector), so that a misbehaving client can't
+// prevent the other clients from using the API. The limits are read from the
+// rate_limits section of the configuration, and the routes are not limited if
+// there is no configuration for the group.
+func RateLimit(group string) echo.MiddlewareFunc {
+	return rateLimit(group, middleware.DefaultSkipper, rateLimitClient, nil)
+}
+
+// RateLimitWithSkipper is like RateLimit, but the requests for which the
+// skipper returns true are not limited.
+func RateLimitWithSkipper(group string, skipper middleware.Skipper) echo.MiddlewareFunc {
+	return rateLimit(group, skipper, rateLimitClient, nil)
+}
+
+// RateLimitByKey returns a middleware that limits the number of requests on
+// a route with a token bucket for each instance and each key returned by the
+// given function. It is used for the anonymous routes, where the requests
+// can't be distinguished by their client, like the access to a Send. The
+// limits can be configured in the rate_limits section like for the groups,
+// else the default configuration is used.
+func RateLimitByKey(group string, keyFn func(c echo.Context) string, defaults limits.BucketConfig) echo.MiddlewareFunc {
+	return rateLimit(group, middleware.DefaultSkipper, keyFn, &defaults)
+}
+
+func rateLimit(
+	group string,
+	skipper middleware.Skipper,
+	keyFn func(c echo.Context) string,
+	defaults *limits.BucketConfig,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			inst, ok := GetInstanceSafe(c)
+			if !ok || skipper(c) {
+				return next(c)
+			}
+			cfg, ok := config.GetRateLimitConfig(inst.ContextName, group)
+			if !ok {
+				if defaults == nil {
+					return next(c)
+				}
+				cfg = *defaults
+			}
+
+			key := group + ":" + inst.DomainName() + ":" + keyFn(c)
+			res, err := config.GetRateLimiter().TakeToken(key, cfg)
+			if err != nil {
+				// The rate limiting must not make the API unavailable
+				inst.Logger().WithNamespace("rate_limit").
+					Warnf("Cannot check the rate limit for %s: %s", group, err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+			header.Set("RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			header.Set("RateLimit-Policy", rateLimitPolicy(cfg))
+			if !res.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				return jsonapi.NewError(http.StatusTooManyRequests, "Too many requests")
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimitClient returns the identifier of the client that makes the
+// request. The requests without a valid token are identified by their IP
+// address, so that an anonymous client can't exhaust the bucket of the others.
+func rateLimitClient(c echo.Context) string {
+	pdoc, err := GetPermission(c)
+	if err == nil {
+		return pdoc.SourceID
+	}
+	var ip string
+	if forwardedFor := c.Request().Header.Get(echo.HeaderXForwardedFor); forwardedFor != "" {
+		ip = strings.TrimSpace(strings.SplitN(forwardedFor, ",", 2)[0])
+	}
+	if ip == "" {
+		ip = strings.Split(c.Request().RemoteAddr, ":")[0]
+	}
+	return "ip:" + ip
+}
+
+func rateLimitPolicy(cfg limits.BucketConfig) string {
+	return strconv.FormatInt(cfg.Limit, 10) + ";w=" + strconv.Itoa(ceilSeconds(cfg.Period))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
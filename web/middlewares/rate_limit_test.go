@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	config.UseTestFile(t)
+	cfg := config.GetConfig()
+	cfg.RateLimits = config.RateLimits{
+		Groups: map[string]limits.BucketConfig{
+			"files": {Limit: 2, Period: time.Minute},
+		},
+	}
+
+	inst := &instance.Instance{Domain: "ratelimit.cozy.local"}
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+	request := func(group string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/"+group+"/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("instance", inst)
+		err := RateLimit(group)(handler)(c)
+		return rec, err
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		rec, err := request("files")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+
+		rec, err = request("files")
+		require.NoError(t, err)
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("TooManyRequests", func(t *testing.T) {
+		rec, err := request("files")
+		require.Error(t, err)
+		jsonErr, ok := err.(*jsonapi.Error)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, jsonErr.Status)
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("AnonymousByIP", func(t *testing.T) {
+		// The bucket for the address of httptest is empty, but the anonymous
+		// requests from another address have their own bucket
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/files/", nil)
+		req.Header.Set(echo.HeaderXForwardedFor, "198.51.100.7")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("instance", inst)
+		require.NoError(t, RateLimit("files")(handler)(c))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("Skipper", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/files/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("instance", inst)
+		skipper := func(c echo.Context) bool { return true }
+		require.NoError(t, RateLimitWithSkipper("files", skipper)(handler)(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		rec, err := request("data")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestRateLimitByKey(t *testing.T) {
+	config.UseTestFile(t)
+	cfg := config.GetConfig()
+	cfg.RateLimits = config.RateLimits{}
+
+	inst := &instance.Instance{Domain: "ratelimitkey.cozy.local"}
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+	defaults := limits.BucketConfig{Limit: 1, Period: time.Minute}
+	key := func(c echo.Context) string { return strings.ToLower(c.Param("id")) }
+	request := func(id string) error {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/sends/access/"+id, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set("instance", inst)
+		return RateLimitByKey("sends-access", key, defaults)(handler)(c)
+	}
+
+	// The default limit is used without a configuration, and there is a
+	// bucket for each key
+	require.NoError(t, request("foo"))
+	require.Error(t, request("foo"))
+	require.Error(t, request("FOO"))
+	require.NoError(t, request("bar"))
+}
@@ -2,11 +2,16 @@ package notifications
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/cozy/cozy-stack/model/app"
 	"github.com/cozy/cozy-stack/model/notification"
 	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/model/notification/sms"
+	"github.com/cozy/cozy-stack/model/notification/webpush"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
@@ -34,6 +39,16 @@ func (n *apiNotif) MarshalJSON() ([]byte, error) {
 	return json.Marshal(n.n)
 }
 
+type apiPreferences struct {
+	*notification.Preferences
+}
+
+func (p *apiPreferences) Relationships() jsonapi.RelationshipMap { return nil }
+func (p *apiPreferences) Included() []jsonapi.Object             { return nil }
+func (p *apiPreferences) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/notifications/preferences"}
+}
+
 func createHandler(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 	n := &notification.Notification{}
@@ -50,6 +65,95 @@ func createHandler(c echo.Context) error {
 	return jsonapi.Data(c, http.StatusCreated, &apiNotif{n}, nil)
 }
 
+func getPreferences(c echo.Context) error {
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.NotificationsPreferences); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	prefs, err := notification.GetPreferences(inst)
+	if err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiPreferences{prefs}, nil)
+}
+
+func updatePreferences(c echo.Context) error {
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.NotificationsPreferences); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	prefs := &notification.Preferences{}
+	if _, err := jsonapi.Bind(c.Request().Body, prefs); err != nil {
+		return err
+	}
+	if err := prefs.Validate(); err != nil {
+		return jsonapi.BadRequest(err)
+	}
+
+	// The date of the next digest is managed by the stack
+	old, err := notification.GetPreferences(inst)
+	if err != nil {
+		return err
+	}
+	prefs.DocRev = old.DocRev
+	if prefs.Digest != nil {
+		prefs.Digest.NextAt = nil
+		if old.Digest != nil {
+			prefs.Digest.NextAt = old.Digest.NextAt
+		}
+	}
+	if err := prefs.Save(inst); err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiPreferences{prefs}, nil)
+}
+
+// webPushKeyHandler returns the VAPID public key, that the browsers must use
+// as the applicationServerKey when they subscribe to the push notifications.
+func webPushKeyHandler(c echo.Context) error {
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return err
+	}
+	client, err := webpush.NewClient(config.GetConfig().Notifications)
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"public_key": client.PublicKey()})
+}
+
+// smsCallbackHandler is called by the SMS providers to update the delivery
+// status of a SMS. They can't be authenticated, so the URL has a token.
+func smsCallbackHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	id := c.Param("notification-id")
+	if !sms.CheckCallbackToken(inst, id, c.QueryParam("token")) {
+		return jsonapi.Forbidden(errors.New("invalid token"))
+	}
+	cfg, err := sms.GetConfig(inst)
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	provider, err := sms.New(*cfg)
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	parser, ok := provider.(sms.CallbackParser)
+	if !ok {
+		return jsonapi.NotFound(errors.New("no delivery callback for this provider"))
+	}
+	status, err := parser.ParseCallback(c.Request())
+	if err != nil {
+		return jsonapi.BadRequest(err)
+	}
+	if err := sms.RecordStatus(inst, id, cfg.Provider, status); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return jsonapi.NotFound(err)
+		}
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 func wrapErrors(err error) error {
 	if err == nil {
 		return nil
@@ -72,4 +176,8 @@ func wrapErrors(err error) error {
 // Routes sets the routing for the notification service.
 func Routes(router *echo.Group) {
 	router.POST("", createHandler)
+	router.GET("/preferences", getPreferences)
+	router.PUT("/preferences", updatePreferences)
+	router.GET("/webpush/key", webPushKeyHandler)
+	router.POST("/:notification-id/sms/callback", smsCallbackHandler)
 }
@@ -175,7 +175,7 @@ func Login(c echo.Context) error {
 
 		// Check 2FA if enabled, and if yes, render an HTML page to check if
 		// the browser has a trusted device token in its local storage.
-		if inst.HasAuthMode(instance.TwoFactorMail) {
+		if inst.HasTwoFactor() {
 			return c.Render(http.StatusOK, "oidc_twofactor.html", echo.Map{
 				"Domain":      inst.ContextualDomain(),
 				"AccessToken": token,
@@ -214,7 +214,7 @@ func TwoFactor(c echo.Context) error {
 		return createSessionAndRedirect(c, inst, redirect, confirm, "")
 	}
 
-	twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
+	twoFactorToken, err := lifecycle.StartTwoFactor(inst)
 	if err != nil {
 		return err
 	}
@@ -414,7 +414,10 @@ func AccessToken(c echo.Context) error {
 		})
 	}
 
-	if inst.HasAuthMode(instance.TwoFactorMail) {
+	if inst.HasAuthMode(instance.Passkey) {
+		return auth.PasskeyRequired(c)
+	}
+	if inst.HasTwoFactor() {
 		token := []byte(reqBody.TwoFactorToken)
 		if len(token) == 0 {
 			twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
@@ -422,8 +425,9 @@ func AccessToken(c echo.Context) error {
 				return err
 			}
 			return c.JSON(http.StatusUnauthorized, echo.Map{
-				"error":            "two factor needed",
-				"two_factor_token": string(twoFactorToken),
+				"error":             "two factor needed",
+				"two_factor_token":  string(twoFactorToken),
+				"two_factor_method": inst.TwoFactorMethod(),
 			})
 		}
 		if ok := inst.ValidateTwoFactorPasscode(token, reqBody.TwoFactorCode); !ok {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cozy/cozy-stack/model/instance"
@@ -12,6 +13,7 @@ import (
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
@@ -32,7 +34,11 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 
 	// Maximum message size allowed from peer
-	maxMessageSize = 1024
+	maxMessageSize = 4096
+
+	// Maximum number of directories for which the permissions are kept in
+	// cache for a connection
+	maxCachedDirs = 100
 )
 
 var upgrader = websocket.Upgrader{
@@ -46,8 +52,10 @@ var upgrader = websocket.Upgrader{
 type command struct {
 	Method  string `json:"method"`
 	Payload struct {
-		Type string `json:"type"`
-		ID   string `json:"id"`
+		Type     string    `json:"type"`
+		ID       string    `json:"id"`
+		DirID    string    `json:"dir_id,omitempty"`
+		Selector mango.Map `json:"selector,omitempty"`
 	} `json:"payload"`
 }
 
@@ -122,6 +130,18 @@ func missingType(cmd *command) *wsError {
 	}
 }
 
+func badRequest(cmd *command, title string) *wsError {
+	return &wsError{
+		Event: "error",
+		Payload: wsErrorPayload{
+			Status: "400 Bad Request",
+			Code:   "bad request",
+			Title:  title,
+			Source: cmd,
+		},
+	}
+}
+
 func sendErr(ctx context.Context, errc chan *wsError, e *wsError) {
 	select {
 	case errc <- e:
@@ -129,6 +149,31 @@ func sendErr(ctx context.Context, errc chan *wsError, e *wsError) {
 	}
 }
 
+// permissionFor returns the doctype and id that must be checked in the
+// permissions to listen to the events of the given doctype and id.
+func permissionFor(doctype, id string) (string, string) {
+	// XXX: thumbnails is a synthetic doctype, listening to its events
+	// requires a permissions on io.cozy.files. Same for note events.
+	if doctype == consts.Thumbnails || doctype == consts.NotesEvents {
+		doctype = consts.Files
+	}
+	// XXX: the passphrase settings document is synthetic, and a
+	// permission on the instance settings is enough to watch it.
+	if doctype == consts.Settings && id == consts.PassphraseParametersID {
+		id = consts.InstanceSettingsID
+	}
+	return doctype, id
+}
+
+// needPermission returns false for the doctypes that can be listened without
+// permissions.
+func needPermission(doctype string) bool {
+	// XXX: no permissions are required for io.cozy.sharings.initial_sync
+	// and io.cozy.auth.confirmations
+	return doctype != consts.SharingsInitialSync &&
+		doctype != consts.AuthConfirmations
+}
+
 func authorized(i *instance.Instance, perms permission.Set, permType, id string) bool {
 	if perms.AllowWholeType(permission.GET, permType) {
 		return true
@@ -148,12 +193,142 @@ func authorized(i *instance.Instance, perms permission.Set, permType, id string)
 	}
 }
 
+// allowedSomeDocs returns true if the permissions allow to read at least some
+// documents of the doctype. It is enough to subscribe with a selector, as the
+// events are then filtered with the permissions.
+func allowedSomeDocs(perms permission.Set, permType string) bool {
+	return perms.Some(func(r permission.Rule) bool {
+		return r.Verbs.Contains(permission.GET) && permission.MatchType(r, permType)
+	})
+}
+
+// eventFilter returns a function that checks if the permissions allow to read
+// the document of an event. For the files, the permissions on the parent
+// directory are used, and they are kept in a small cache as the files of a
+// directory are often modified together. The cache is cleared when a
+// directory is moved, as the permissions on its sub-directories can change,
+// until the context is canceled.
+func eventFilter(ctx context.Context, i *instance.Instance, perms permission.Set) func(e *realtime.Event) bool {
+	var mu sync.Mutex
+	dirs := make(map[string]bool)
+	go func() {
+		sub := realtime.GetHub().Subscriber(i)
+		defer sub.Close()
+		sub.Subscribe(consts.Files)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-sub.Channel:
+				if movedDir(e) {
+					mu.Lock()
+					dirs = make(map[string]bool)
+					mu.Unlock()
+				}
+			}
+		}
+	}()
+	allowedDir := func(dirID string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		allowed, ok := dirs[dirID]
+		if !ok {
+			if len(dirs) >= maxCachedDirs {
+				dirs = make(map[string]bool)
+			}
+			allowed = authorized(i, perms, consts.Files, dirID)
+			dirs[dirID] = allowed
+		}
+		return allowed
+	}
+
+	allowedDoc := func(rdoc realtime.Doc) bool {
+		doctype := rdoc.DocType()
+		if perms.AllowID(permission.GET, doctype, rdoc.ID()) {
+			return true
+		}
+		var dirID string
+		var doc permission.Fetcher
+		switch d := rdoc.(type) {
+		case *vfs.FileDoc:
+			dirID, doc = d.DirID, d
+		case *vfs.DirDoc:
+			dirID, doc = d.DirID, d
+		case *realtime.JSONDoc:
+			dirID, _ = d.M["dir_id"].(string)
+			doc = &couchdb.JSONDoc{M: d.M, Type: d.Type}
+		case permission.Fetcher:
+			doc = d
+		default:
+			return false
+		}
+		if perms.Allow(permission.GET, doc) {
+			return true
+		}
+		return doctype == consts.Files && dirID != "" && allowedDir(dirID)
+	}
+
+	// The old version of the document is also checked, so that a file moved
+	// out of a shared directory is seen as leaving it.
+	return func(e *realtime.Event) bool {
+		if allowedDoc(e.Doc) {
+			return true
+		}
+		return e.OldDoc != nil && allowedDoc(e.OldDoc)
+	}
+}
+
+// movedDir returns true if the event is for a directory that has been moved
+// to another parent.
+func movedDir(e *realtime.Event) bool {
+	if e.Verb != realtime.EventUpdate || e.OldDoc == nil {
+		return false
+	}
+	parent, isDir := parentDir(e.Doc)
+	oldParent, _ := parentDir(e.OldDoc)
+	return isDir && parent != oldParent
+}
+
+func parentDir(doc realtime.Doc) (string, bool) {
+	switch d := doc.(type) {
+	case *vfs.DirDoc:
+		return d.DirID, true
+	case *realtime.JSONDoc:
+		typ, _ := d.M["type"].(string)
+		dirID, _ := d.M["dir_id"].(string)
+		return dirID, typ == consts.DirType
+	}
+	return "", false
+}
+
+// selectorFor returns the mango selector for a SUBSCRIBE command, or nil if
+// the command is for a whole doctype or a single document.
+func selectorFor(cmd *command) (mango.Map, *wsError) {
+	selector := cmd.Payload.Selector
+	if cmd.Payload.DirID != "" {
+		if cmd.Payload.Type != consts.Files {
+			return nil, badRequest(cmd, "The dir_id parameter can only be used with io.cozy.files")
+		}
+		byDir := mango.Map{"dir_id": cmd.Payload.DirID}
+		if len(selector) > 0 {
+			selector = mango.Map{"$and": []interface{}{selector, byDir}}
+		} else {
+			selector = byDir
+		}
+	}
+	if len(selector) > 0 && cmd.Payload.ID != "" {
+		return nil, badRequest(cmd, "The id and selector parameters can't be used together")
+	}
+	return selector, nil
+}
+
 func readPump(ctx context.Context, c echo.Context, i *instance.Instance, ws *websocket.Conn,
 	ds *realtime.Subscriber, errc chan *wsError, withAuthentication bool) {
 	defer close(errc)
 
 	var err error
 	var pdoc *permission.Permission
+	var allow func(e *realtime.Event) bool
 
 	if withAuthentication {
 		var auth map[string]string
@@ -197,31 +372,53 @@ func readPump(ctx context.Context, c echo.Context, i *instance.Instance, ws *web
 			sendErr(ctx, errc, missingType(cmd))
 			continue
 		}
-		permType := cmd.Payload.Type
-		permID := cmd.Payload.ID
-		// XXX: thumbnails is a synthetic doctype, listening to its events
-		// requires a permissions on io.cozy.files. Same for note events.
-		if permType == consts.Thumbnails || permType == consts.NotesEvents {
-			permType = consts.Files
+		permType, permID := permissionFor(cmd.Payload.Type, cmd.Payload.ID)
+		var selector mango.Map
+		var sel *realtime.Selector
+		if method == "SUBSCRIBE" {
+			var wsErr *wsError
+			if selector, wsErr = selectorFor(cmd); wsErr != nil {
+				sendErr(ctx, errc, wsErr)
+				continue
+			}
 		}
-		// XXX: the passphrase settings document is synthetic, and a
-		// permission on the instance settings is enough to watch it.
-		if permType == consts.Settings && permID == consts.PassphraseParametersID {
-			permID = consts.InstanceSettingsID
+		if len(selector) > 0 {
+			matcher, err := mango.Compile(selector)
+			if err != nil {
+				sendErr(ctx, errc, badRequest(cmd, err.Error()))
+				continue
+			}
+			sel = &realtime.Selector{Matcher: matcher}
 		}
-		// XXX: no permissions are required for io.cozy.sharings.initial_sync
-		// and io.cozy.auth.confirmations
-		if withAuthentication &&
-			cmd.Payload.Type != consts.SharingsInitialSync &&
-			cmd.Payload.Type != consts.AuthConfirmations {
-			if !authorized(i, pdoc.Permissions, permType, permID) {
+
+		if withAuthentication && needPermission(cmd.Payload.Type) {
+			perms := pdoc.Permissions
+			switch {
+			case authorized(i, perms, permType, permID):
+				// OK
+			case sel != nil && permType == cmd.Payload.Type && allowedSomeDocs(perms, permType):
+				// The token can read only some documents of the doctype, like
+				// for a share-by-link, and the events are filtered on them.
+				if cmd.Payload.DirID != "" && !authorized(i, perms, consts.Files, cmd.Payload.DirID) {
+					sendErr(ctx, errc, forbidden(cmd))
+					continue
+				}
+				if allow == nil {
+					allow = eventFilter(ctx, i, perms)
+				}
+				sel.Allow = allow
+			case method == "UNSUBSCRIBE" && permID == "" && allowedSomeDocs(perms, permType):
+				// OK, it may have been subscribed with a selector
+			default:
 				sendErr(ctx, errc, forbidden(cmd))
 				continue
 			}
 		}
 
 		if method == "SUBSCRIBE" {
-			if cmd.Payload.ID == "" {
+			if sel != nil {
+				ds.SubscribeSelector(cmd.Payload.Type, sel)
+			} else if cmd.Payload.ID == "" {
 				ds.Subscribe(cmd.Payload.Type)
 			} else {
 				ds.Watch(cmd.Payload.Type, cmd.Payload.ID)
@@ -341,5 +538,6 @@ func Notify(c echo.Context) error {
 // Routes set the routing for the realtime service
 func Routes(router *echo.Group) {
 	router.GET("/", Ws)
+	router.GET("/sse", SSE)
 	router.POST("/:doctype/:id", Notify)
 }
@@ -1,14 +1,22 @@
 package realtime
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/realtime"
 	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testDoc struct {
@@ -169,6 +177,188 @@ func TestRealtime(t *testing.T) {
 		payload.ValueEqual("id", "bar-two")
 	})
 
+	t.Run("WSSelector", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		ws := e.GET("/realtime/").
+			WithWebsocketUpgrade().
+			Expect().Status(http.StatusSwitchingProtocols).
+			Websocket()
+		defer ws.Disconnect()
+
+		ws.WriteText(fmt.Sprintf(`{"method": "AUTH", "payload": "%s"}`, token))
+
+		obj := ws.WriteText(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.foos", "dir_id": "foo" }}`).
+			Expect().TextMessage().
+			JSON().Object()
+		obj.ValueEqual("event", "error")
+		obj.Value("payload").Object().ValueEqual("status", "400 Bad Request")
+
+		ws.WriteText(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.foos", "selector": { "color": "blue" } }}`)
+		time.Sleep(30 * time.Millisecond)
+
+		h := realtime.GetHub()
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "foo-red", "color": "red"},
+			Type: "io.cozy.foos",
+		}, nil)
+		// No event
+
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "foo-blue", "color": "blue"},
+			Type: "io.cozy.foos",
+		}, nil)
+
+		obj = ws.Expect().TextMessage().JSON().Object()
+		obj.ValueEqual("event", "CREATED")
+		obj.Value("payload").Object().ValueEqual("id", "foo-blue")
+	})
+
+	t.Run("WSShareByLink", func(t *testing.T) {
+		fs := inst.VFS()
+		shared, err := vfs.MkdirAll(fs, "/realtime-shared")
+		require.NoError(t, err)
+		other, err := vfs.MkdirAll(fs, "/realtime-other")
+		require.NoError(t, err)
+
+		publicToken, err := inst.MakeJWT(consts.ShareAudience, "email", "io.cozy.files", "", time.Now())
+		require.NoError(t, err)
+		rules := permission.Set{
+			permission.Rule{
+				Type:   consts.Files,
+				Verbs:  permission.Verbs(permission.GET),
+				Values: []string{shared.ID()},
+			},
+		}
+		parent := &permission.Permission{Type: permission.TypeWebapp, Permissions: rules}
+		codes := map[string]string{"email": publicToken}
+		_, err = permission.CreateShareSet(inst, parent, "io.cozy.apps/drive", codes, nil,
+			permission.Permission{Permissions: rules}, nil)
+		require.NoError(t, err)
+
+		e := testutils.CreateTestClient(t, ts.URL)
+		ws := e.GET("/realtime/").
+			WithWebsocketUpgrade().
+			Expect().Status(http.StatusSwitchingProtocols).
+			Websocket()
+		defer ws.Disconnect()
+
+		ws.WriteText(fmt.Sprintf(`{"method": "AUTH", "payload": "%s"}`, publicToken))
+
+		obj := ws.WriteText(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.files" }}`).
+			Expect().TextMessage().
+			JSON().Object()
+		obj.Value("payload").Object().ValueEqual("status", "403 Forbidden")
+
+		obj = ws.WriteText(fmt.Sprintf(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.files", "dir_id": "%s" }}`, other.ID())).
+			Expect().TextMessage().
+			JSON().Object()
+		obj.Value("payload").Object().ValueEqual("status", "403 Forbidden")
+
+		ws.WriteText(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.files", "selector": { "class": "image" } }}`)
+		time.Sleep(30 * time.Millisecond)
+
+		h := realtime.GetHub()
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "private-image", "dir_id": other.ID(), "class": "image"},
+			Type: consts.Files,
+		}, nil)
+		// No event
+
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "shared-text", "dir_id": shared.ID(), "class": "text"},
+			Type: consts.Files,
+		}, nil)
+		// No event
+
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "shared-image", "dir_id": shared.ID(), "class": "image"},
+			Type: consts.Files,
+		}, nil)
+
+		obj = ws.Expect().TextMessage().JSON().Object()
+		obj.ValueEqual("event", "CREATED")
+		obj.Value("payload").Object().ValueEqual("id", "shared-image")
+
+		sub, err := vfs.MkdirAll(fs, "/realtime-shared/sub")
+		require.NoError(t, err)
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "sub-image", "dir_id": sub.ID(), "class": "image"},
+			Type: consts.Files,
+		}, nil)
+		obj = ws.Expect().TextMessage().JSON().Object()
+		obj.Value("payload").Object().ValueEqual("id", "sub-image")
+
+		// The permissions in cache are not used after a move
+		otherID := other.ID()
+		_, err = vfs.ModifyDirMetadata(fs, sub, &vfs.DocPatch{DirID: &otherID})
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "moved-image", "dir_id": sub.ID(), "class": "image"},
+			Type: consts.Files,
+		}, nil)
+		// No event
+
+		h.Publish(inst, realtime.EventCreate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "shared-image-2", "dir_id": shared.ID(), "class": "image"},
+			Type: consts.Files,
+		}, nil)
+		obj = ws.Expect().TextMessage().JSON().Object()
+		obj.Value("payload").Object().ValueEqual("id", "shared-image-2")
+
+		// A file moved out of the shared directory is seen as leaving it
+		h.Publish(inst, realtime.EventUpdate, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "shared-image-2", "dir_id": other.ID(), "class": "image"},
+			Type: consts.Files,
+		}, &realtime.JSONDoc{
+			M:    map[string]interface{}{"_id": "shared-image-2", "dir_id": shared.ID(), "class": "image"},
+			Type: consts.Files,
+		})
+		obj = ws.Expect().TextMessage().JSON().Object()
+		obj.ValueEqual("event", "UPDATED")
+		obj.Value("payload").Object().ValueEqual("id", "shared-image-2")
+	})
+
+	t.Run("SSE", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		e.GET("/realtime/sse").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(400)
+		e.GET("/realtime/sse").
+			WithQuery("doctype", "io.cozy.contacts").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(403)
+
+		h := realtime.GetHub()
+		stream, closeStream := openSSE(t, ts.URL, token, "")
+		h.Publish(inst, realtime.EventCreate, &testDoc{doctype: "io.cozy.foos", id: "sse-one"}, nil)
+		id, event, data := readSSE(t, stream)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, "CREATED", event)
+		assert.Contains(t, data, `"id":"sse-one"`)
+		closeStream()
+
+		h.Publish(inst, realtime.EventUpdate, &testDoc{doctype: "io.cozy.foos", id: "sse-two"}, nil)
+		h.Publish(inst, realtime.EventUpdate, &testDoc{doctype: "io.cozy.bars", id: "sse-bar"}, nil)
+
+		stream, closeStream = openSSE(t, ts.URL, token, id)
+		defer closeStream()
+		_, event, data = readSSE(t, stream)
+		assert.Equal(t, "UPDATED", event)
+		assert.Contains(t, data, `"id":"sse-two"`)
+
+		h.Publish(inst, realtime.EventDelete, &testDoc{doctype: "io.cozy.foos", id: "sse-three"}, nil)
+		_, event, data = readSSE(t, stream)
+		assert.Equal(t, "DELETED", event)
+		assert.Contains(t, data, `"id":"sse-three"`)
+
+		lost, closeLost := openSSE(t, ts.URL, token, "unknown-id")
+		defer closeLost()
+		_, event, _ = readSSE(t, lost)
+		assert.Equal(t, "RESET", event)
+	})
+
 	t.Run("WSNotify", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -200,6 +390,48 @@ func TestRealtime(t *testing.T) {
 	})
 }
 
+// openSSE opens a stream of server-sent events for io.cozy.foos, and waits
+// for the subscription to be effective.
+func openSSE(t *testing.T, url, token, lastEventID string) (*bufio.Reader, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/realtime/sse?doctype=io.cozy.foos", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	time.Sleep(30 * time.Millisecond)
+	return bufio.NewReader(res.Body), func() {
+		cancel()
+		res.Body.Close()
+	}
+}
+
+// readSSE reads the next message from a stream of server-sent events.
+func readSSE(t *testing.T, r *bufio.Reader) (id, event, data string) {
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if event != "" {
+				return
+			}
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
 func (t *testDoc) ID() string      { return t.id }
 func (t *testDoc) DocType() string { return t.doctype }
 func (t *testDoc) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,146 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// resetEvent is sent to the client when the events since its Last-Event-ID
+// are no longer available: it should fetch the documents again.
+const resetEvent = "RESET"
+
+// writeSSE writes a message in the text/event-stream format, and flushes it.
+func writeSSE(res *echo.Response, id, event string, data interface{}) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("event: %s\ndata: %s\n\n", event, buf)
+	if id != "" {
+		msg = fmt.Sprintf("id: %s\n%s", id, msg)
+	}
+	if _, err := res.Write([]byte(msg)); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
+
+func writeEvent(res *echo.Response, e *realtime.Event) error {
+	payload := wsResponsePayload{
+		Type: e.Doc.DocType(),
+		ID:   e.Doc.ID(),
+		Doc:  e.Doc,
+	}
+	return writeSSE(res, e.EventID, e.Verb, payload)
+}
+
+// SSE is the API handler for realtime via Server-Sent Events. The client can
+// send the ID of the last event it has received in the Last-Event-ID header
+// when it reconnects, and the events it has missed are sent first.
+func SSE(c echo.Context) error {
+	doctypes := c.QueryParams()["doctype"]
+	if len(doctypes) == 0 {
+		return jsonapi.BadRequest(errors.New("The doctype parameter is mandatory"))
+	}
+
+	// Like for the websockets, the authentication is not needed when there
+	// is no instance, in the administration server.
+	var db prefixer.Prefixer
+	inst, withAuthentication := middlewares.GetInstanceSafe(c)
+	if !withAuthentication {
+		db = prefixer.GlobalPrefixer
+	} else {
+		db = inst
+		pdoc, err := middlewares.GetPermission(c)
+		if err != nil {
+			return err
+		}
+		for _, doctype := range doctypes {
+			permType, _ := permissionFor(doctype, "")
+			if needPermission(doctype) && !pdoc.Permissions.AllowWholeType(permission.GET, permType) {
+				return middlewares.ErrForbidden
+			}
+		}
+	}
+
+	// The events are recorded in the history only for the instances with a
+	// SSE client, and this client may need them when it reconnects.
+	hub := realtime.GetHub()
+	hub.KeepHistory(db)
+	ds := hub.Subscriber(db)
+	defer ds.Close()
+	wanted := make(map[string]bool)
+	for _, doctype := range doctypes {
+		wanted[doctype] = true
+		ds.Subscribe(doctype)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	// Disable the buffering of the response by nginx
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	// The subscription is made before reading the history, so an event can
+	// be in both, and we need to remember which ones have been sent.
+	sent := make(map[string]bool)
+	if lastEventID := c.Request().Header.Get("Last-Event-ID"); lastEventID != "" {
+		events, err := hub.History(db, lastEventID)
+		if err != nil {
+			if !errors.Is(err, realtime.ErrEventsLost) {
+				logger.WithDomain(db.DomainName()).WithNamespace("realtime").
+					Warnf("Cannot read the history: %s", err)
+			}
+			if err := writeSSE(res, "", resetEvent, struct{}{}); err != nil {
+				return nil
+			}
+		}
+		for _, e := range events {
+			if !wanted[e.Doc.DocType()] {
+				continue
+			}
+			sent[e.EventID] = true
+			if err := writeEvent(res, e); err != nil {
+				return nil
+			}
+		}
+	}
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case e := <-ds.Channel:
+			if e.EventID != "" && sent[e.EventID] {
+				continue
+			}
+			if err := writeEvent(res, e); err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			// A comment is sent to keep the connection alive
+			if _, err := res.Write([]byte(": ping\n\n")); err != nil {
+				return nil
+			}
+			res.Flush()
+			hub.KeepHistory(db)
+		}
+	}
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/cozy/cozy-stack/web/swift"
 	"github.com/cozy/cozy-stack/web/tools"
 	"github.com/cozy/cozy-stack/web/version"
+	"github.com/cozy/cozy-stack/web/webdav"
 	"github.com/cozy/cozy-stack/web/wellknown"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -220,23 +221,35 @@ func SetupRoutes(router *echo.Echo, services *stack.Services) error {
 			middlewares.CheckInstanceBlocked,
 			middlewares.CheckTOSDeadlineExpired,
 		)
-		registry.Routes(router.Group("/registry", mws...))
-		data.Routes(router.Group("/data", mws...))
-		files.Routes(router.Group("/files", mws...))
-		contacts.Routes(router.Group("/contacts", mws...))
-		intents.Routes(router.Group("/intents", mws...))
-		jobs.NewHTTPHandler(services.Emailer).Register(router.Group("/jobs", mws...))
-		notifications.Routes(router.Group("/notifications", mws...))
-		move.Routes(router.Group("/move", mws...))
-		permissions.Routes(router.Group("/permissions", mws...))
-		realtime.Routes(router.Group("/realtime", mws...))
-		notes.Routes(router.Group("/notes", mws...))
-		office.Routes(router.Group("/office", mws...))
-		remote.Routes(router.Group("/remote", mws...))
-		sharings.Routes(router.Group("/sharings", mws...))
-		bitwarden.Routes(router.Group("/bitwarden", mws...))
-		shortcuts.Routes(router.Group("/shortcuts", mws...))
-		ai.Routes(router.Group("/ai", mws...))
+		// The requests on these groups can be rate limited, with the name of
+		// the group (without the leading slash) in the configuration.
+		groupWithSkipper := func(prefix string, skipper middleware.Skipper) *echo.Group {
+			limited := make([]echo.MiddlewareFunc, len(mws), len(mws)+1)
+			copy(limited, mws)
+			limited = append(limited, middlewares.RateLimitWithSkipper(prefix[1:], skipper))
+			return router.Group(prefix, limited...)
+		}
+		group := func(prefix string) *echo.Group {
+			return groupWithSkipper(prefix, middleware.DefaultSkipper)
+		}
+		registry.Routes(group("/registry"))
+		data.Routes(group("/data"))
+		files.Routes(group("/files"))
+		contacts.Routes(group("/contacts"))
+		intents.Routes(group("/intents"))
+		jobs.NewHTTPHandler(services.Emailer).Register(group("/jobs"))
+		notifications.Routes(group("/notifications"))
+		move.Routes(group("/move"))
+		permissions.Routes(group("/permissions"))
+		realtime.Routes(group("/realtime"))
+		notes.Routes(group("/notes"))
+		office.Routes(group("/office"))
+		remote.Routes(group("/remote"))
+		// The replication between the members of a sharing is not limited
+		sharings.Routes(groupWithSkipper("/sharings", sharings.IsReplication))
+		bitwarden.Routes(group("/bitwarden"))
+		shortcuts.Routes(group("/shortcuts"))
+		ai.Routes(group("/ai"))
 
 		// The settings routes needs not to be blocked
 		apps.WebappsRoutes(router.Group("/apps", mwsNotBlocked...))
@@ -252,6 +265,14 @@ func SetupRoutes(router *echo.Echo, services *stack.Services) error {
 		// redirection.
 		accounts.Routes(router.Group("/accounts"))
 		oidc.Routes(router.Group("/oidc"))
+
+		// WebDAV clients do not send the Accept header expected by the JSON-API
+		// routes, and authenticate with a bearer token or basic auth.
+		webdav.Routes(router.Group("/dav",
+			middlewares.NeedInstance,
+			middlewares.CheckInstanceBlocked,
+			middlewares.RateLimit("dav"),
+		))
 	}
 
 	// other non-authentified routes
@@ -302,6 +323,7 @@ func SetupAdminRoutes(router *echo.Echo) error {
 	metrics.Routes(router.Group("/metrics", mws...))
 	oauth.Routes(router.Group("/oauth", mws...))
 	oidc.AdminRoutes(router.Group("/oidc", mws...))
+	jobs.AdminRoutes(router.Group("/jobs", mws...))
 	realtime.Routes(router.Group("/realtime", mws...))
 	swift.Routes(router.Group("/swift", mws...))
 	tools.Routes(router.Group("/tools", mws...))
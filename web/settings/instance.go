@@ -3,13 +3,18 @@
 package settings
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"image/png"
 	"net/http"
 	"strings"
 
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/webauthn"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
@@ -17,6 +22,15 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// qrCodeSize is the size in pixels of the QR code for the provisioning of
+	// the authenticator app
+	qrCodeSize = 256
+	// authenticatorCodeLength is the number of digits of the codes generated
+	// by the authenticator app
+	authenticatorCodeLength = 6
+)
+
 type apiInstance struct {
 	doc *couchdb.JSONDoc
 }
@@ -185,6 +199,19 @@ func (h *HTTPHandler) updateInstanceAuthMode(c echo.Context) error {
 		if ok := inst.ValidateMailConfirmationCode(args.TwoFactorActivationCode); !ok {
 			return c.NoContent(http.StatusUnprocessableEntity)
 		}
+	case instance.TwoFactorTOTP:
+		if args.TwoFactorActivationCode == "" {
+			return provisionAuthenticator(c, inst)
+		}
+		return activateAuthenticator(c, inst, args.TwoFactorActivationCode)
+	case instance.Passkey:
+		creds, err := webauthn.ListCredentials(inst)
+		if err != nil {
+			return err
+		}
+		if len(creds) == 0 {
+			return jsonapi.InvalidAttribute("auth_mode", webauthn.ErrNoCredentials)
+		}
 	}
 
 	err = lifecycle.Patch(inst, &lifecycle.Options{AuthMode: args.AuthMode})
@@ -195,6 +222,63 @@ func (h *HTTPHandler) updateInstanceAuthMode(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// provisionAuthenticator generates a new secret for the authenticator app, and
+// returns it with a QR code that can be scanned by the app.
+func provisionAuthenticator(c echo.Context, inst *instance.Instance) error {
+	key, err := inst.GenerateAuthenticatorKey()
+	if err != nil {
+		return err
+	}
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"secret":  key.Secret(),
+		"url":     key.URL(),
+		"qr_code": "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+}
+
+// activateAuthenticator checks that the authenticator app has been correctly
+// configured, and returns the recovery codes that can be used if the app is
+// lost.
+func activateAuthenticator(c echo.Context, inst *instance.Instance, code string) error {
+	if len(code) != authenticatorCodeLength || !inst.ActivateAuthenticatorKey(code) {
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+	codes, err := inst.GenerateRecoveryCodes()
+	if err != nil {
+		return err
+	}
+	mode := instance.AuthModeToString(instance.TwoFactorTOTP)
+	if err := lifecycle.Patch(inst, &lifecycle.Options{AuthMode: mode}); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{"recovery_codes": codes})
+}
+
+// regenerateRecoveryCodes replaces the recovery codes of the authenticator
+// app by new ones.
+func (h *HTTPHandler) regenerateRecoveryCodes(c echo.Context) error {
+	if err := middlewares.RequireSettingsApp(c); err != nil {
+		return err
+	}
+	inst := middlewares.GetInstance(c)
+	if !inst.HasAuthMode(instance.TwoFactorTOTP) {
+		return jsonapi.Conflict(errors.New("the authenticator app is not activated"))
+	}
+	codes, err := inst.GenerateRecoveryCodes()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{"recovery_codes": codes})
+}
+
 func (h *HTTPHandler) askInstanceDeletion(c echo.Context) error {
 	if err := middlewares.RequireSettingsApp(c); err != nil {
 		return err
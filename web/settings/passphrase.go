@@ -17,6 +17,7 @@ import (
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/session"
 	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/model/webauthn"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -249,10 +250,12 @@ func (h *HTTPHandler) updatePassphrase(c echo.Context) error {
 		Iterations        int    `json:"iterations"`
 		TwoFactorPasscode string `json:"two_factor_passcode"`
 		TwoFactorToken    []byte `json:"two_factor_token"`
-		Force             bool   `json:"force,omitempty"`
-		Key               string `json:"key"`
-		PublicKey         string `json:"publicKey"`
-		PrivateKey        string `json:"privateKey"`
+		// The assertion of a passkey, when it is the second factor
+		WebAuthnCredential *webauthn.AssertionResponse `json:"webauthn_credential"`
+		Force              bool                        `json:"force,omitempty"`
+		Key                string                      `json:"key"`
+		PublicKey          string                      `json:"publicKey"`
+		PrivateKey         string                      `json:"privateKey"`
 	}{}
 	err := c.Bind(&args)
 	if err != nil {
@@ -310,16 +313,20 @@ func (h *HTTPHandler) updatePassphrase(c echo.Context) error {
 	}
 
 	// Else, we keep going on the standard checks (2FA, current passphrase, ...)
-	if inst.HasAuthMode(instance.TwoFactorMail) && len(args.TwoFactorToken) == 0 {
+	if inst.HasTwoFactor() && len(args.TwoFactorToken) == 0 {
 		if instance.CheckPassphrase(inst, currentPassphrase) == nil {
+			// When the second factor is a passkey, nothing is sent by mail:
+			// the token is used to begin the WebAuthn assertion.
 			var twoFactorToken []byte
-			twoFactorToken, err = lifecycle.SendTwoFactorPasscode(inst)
+			twoFactorToken, err = lifecycle.StartTwoFactor(inst)
 			if err != nil {
 				return err
 			}
-			return c.JSON(http.StatusOK, echo.Map{
-				"two_factor_token": twoFactorToken,
-			})
+			res := echo.Map{"two_factor_token": twoFactorToken}
+			if inst.HasAuthMode(instance.Passkey) {
+				res["passkey"] = true
+			}
+			return c.JSON(http.StatusOK, res)
 		}
 		return instance.ErrInvalidPassphrase
 	}
@@ -333,13 +340,18 @@ func (h *HTTPHandler) updatePassphrase(c echo.Context) error {
 		return jsonapi.InvalidParameter("KdfIterations", err)
 	}
 
-	err = lifecycle.UpdatePassphrase(inst, currentPassphrase,
-		args.TwoFactorPasscode, args.TwoFactorToken,
-		lifecycle.PassParameters{
-			Pass:       newPassphrase,
-			Iterations: args.Iterations,
-			Key:        args.Key,
-		})
+	params := lifecycle.PassParameters{
+		Pass:       newPassphrase,
+		Iterations: args.Iterations,
+		Key:        args.Key,
+	}
+	if inst.HasAuthMode(instance.Passkey) {
+		err = lifecycle.UpdatePassphraseWithPasskey(inst, args.TwoFactorToken,
+			args.WebAuthnCredential, params)
+	} else {
+		err = lifecycle.UpdatePassphrase(inst, currentPassphrase,
+			args.TwoFactorPasscode, args.TwoFactorToken, params)
+	}
 	if err != nil {
 		return jsonapi.BadRequest(err)
 	}
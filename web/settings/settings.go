@@ -316,6 +316,7 @@ func (h *HTTPHandler) Register(router *echo.Group) {
 	router.PUT("/instance", h.updateInstance)
 	router.POST("/instance/deletion", h.askInstanceDeletion)
 	router.PUT("/instance/auth_mode", h.updateInstanceAuthMode)
+	router.POST("/instance/auth_mode/recovery_codes", h.regenerateRecoveryCodes)
 	router.PUT("/instance/sign_tos", h.updateInstanceTOS)
 	router.DELETE("/instance/moved_from", h.clearMovedFrom)
 
@@ -27,6 +27,7 @@ import (
 	"github.com/cozy/cozy-stack/web/statik"
 	"github.com/gavv/httpexpect/v2"
 	"github.com/labstack/echo/v4"
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -652,6 +653,75 @@ func TestSettings(t *testing.T) {
 			Expect().Status(204)
 	})
 
+	t.Run("ActivateAuthenticatorApp", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, tsURL)
+
+		obj := e.PUT("/settings/instance/auth_mode").
+			WithCookie(sessCookie, "connected").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Accept", "application/json").
+			WithJSON(map[string]interface{}{
+				"auth_mode": "two_factor_totp",
+			}).
+			Expect().Status(200).
+			JSON().Object()
+
+		secret := obj.Value("secret").String().NotEmpty().Raw()
+		obj.Value("url").String().HasPrefix("otpauth://totp/")
+		obj.Value("qr_code").String().HasPrefix("data:image/png;base64,")
+
+		e.PUT("/settings/instance/auth_mode").
+			WithCookie(sessCookie, "connected").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Accept", "application/json").
+			WithJSON(map[string]interface{}{
+				"auth_mode":                  "two_factor_totp",
+				"two_factor_activation_code": "000000",
+			}).
+			Expect().Status(422)
+
+		// The new secret is only used after its activation
+		inst, err := instance.Get(testInstance.Domain)
+		require.NoError(t, err)
+		assert.Empty(t, inst.TOTPSecret)
+		assert.Equal(t, secret, inst.TOTPPendingSecret)
+
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		obj = e.PUT("/settings/instance/auth_mode").
+			WithCookie(sessCookie, "connected").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Accept", "application/json").
+			WithJSON(map[string]interface{}{
+				"auth_mode":                  "two_factor_totp",
+				"two_factor_activation_code": code,
+			}).
+			Expect().Status(200).
+			JSON().Object()
+
+		codes := obj.Value("recovery_codes").Array()
+		codes.Length().IsEqual(instance.RecoveryCodesCount)
+		recoveryCode := codes.Value(0).String().Raw()
+
+		inst, err = instance.Get(testInstance.Domain)
+		require.NoError(t, err)
+		assert.True(t, inst.HasAuthMode(instance.TwoFactorTOTP))
+		assert.Equal(t, secret, inst.TOTPSecret)
+		assert.Empty(t, inst.TOTPPendingSecret)
+		assert.Equal(t, "totp", inst.TwoFactorMethod())
+
+		// The code used for the activation cannot be replayed, but a recovery
+		// code can be used once
+		twoFactorToken, _, err := inst.GenerateTwoFactorSecrets()
+		require.NoError(t, err)
+		assert.False(t, inst.ValidateTwoFactorPasscode(twoFactorToken, code))
+		assert.True(t, inst.ValidateTwoFactorPasscode(twoFactorToken, recoveryCode))
+		assert.False(t, inst.ValidateTwoFactorPasscode(twoFactorToken, recoveryCode))
+
+		err = lifecycle.Patch(inst, &lifecycle.Options{AuthMode: "two_factor_mail"})
+		require.NoError(t, err)
+	})
+
 	t.Run("ListClients", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, tsURL)
 
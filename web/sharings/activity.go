@@ -0,0 +1,66 @@
+package sharings
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultActivityPerPage = 50
+
+type apiActivity struct {
+	*sharing.Activity
+}
+
+func (a *apiActivity) Relationships() jsonapi.RelationshipMap { return nil }
+func (a *apiActivity) Included() []jsonapi.Object             { return nil }
+func (a *apiActivity) Links() *jsonapi.LinksList              { return nil }
+
+// ListActivity returns the journal of the operations made by the members on
+// the files of a sharing, from the most recent.
+func ListActivity(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	sharingID := c.Param("sharing-id")
+	s, err := sharing.FindSharing(inst, sharingID)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if err = checkGetPermissions(c, s); err != nil {
+		return wrapErrors(err)
+	}
+	// The journal is only kept on the cozy of the sharer
+	if !s.Owner {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+
+	bookmark := c.QueryParam("page[cursor]")
+	limit, err := strconv.Atoi(c.QueryParam("page[limit]"))
+	if err != nil || limit <= 0 || limit > consts.MaxItemsPerPageForMango {
+		limit = defaultActivityPerPage
+	}
+	activities, bookmark, err := s.ListActivity(inst, limit, bookmark)
+	if err != nil {
+		return wrapErrors(err)
+	}
+
+	objs := make([]jsonapi.Object, len(activities))
+	for i, a := range activities {
+		objs[i] = &apiActivity{a}
+	}
+	links := &jsonapi.LinksList{}
+	if bookmark != "" && len(objs) == limit {
+		v := url.Values{}
+		v.Set("page[cursor]", bookmark)
+		if limit != defaultActivityPerPage {
+			v.Set("page[limit]", strconv.Itoa(limit))
+		}
+		links.Next = "/sharings/" + s.SID + "/activity?" + v.Encode()
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, links)
+}
@@ -0,0 +1,83 @@
+package sharings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// UpdateExpiration is used by the owner to change the date when the sharing
+// will be revoked.
+func UpdateExpiration(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if _, err = checkCreatePermissions(c, s); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+	expiresAt, err := bindExpiresAt(c)
+	if err != nil {
+		return err
+	}
+	if err = s.SetExpiresAt(inst, expiresAt); err != nil {
+		return wrapErrors(err)
+	}
+	return jsonapiSharingWithDocs(c, s)
+}
+
+// UpdateRecipientExpiration is used by the owner to change the date when the
+// access of a recipient will be revoked.
+func UpdateRecipientExpiration(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if _, err = checkCreatePermissions(c, s); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		return jsonapi.InvalidParameter("index", err)
+	}
+	if index == 0 || index >= len(s.Members) {
+		return jsonapi.InvalidParameter("index", errors.New("Invalid index"))
+	}
+	expiresAt, err := bindExpiresAt(c)
+	if err != nil {
+		return err
+	}
+	if err = s.SetMemberExpiresAt(inst, index, expiresAt); err != nil {
+		return wrapErrors(err)
+	}
+	go s.NotifyRecipients(inst, nil)
+	return jsonapiSharingWithDocs(c, s)
+}
+
+// bindExpiresAt reads the expires_at field of the request body. A null or
+// empty value is used to remove the expiration.
+func bindExpiresAt(c echo.Context) (*time.Time, error) {
+	var args struct {
+		ExpiresAt string `json:"expires_at"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&args); err != nil {
+		return nil, jsonapi.BadJSON()
+	}
+	if args.ExpiresAt == "" {
+		return nil, nil
+	}
+	at, err := time.Parse(time.RFC3339, args.ExpiresAt)
+	if err != nil {
+		return nil, jsonapi.InvalidAttribute("expires_at", err)
+	}
+	return &at, nil
+}
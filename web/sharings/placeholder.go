@@ -0,0 +1,141 @@
+package sharings
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultPlaceholdersPerPage = 50
+
+type apiPlaceholder struct {
+	*sharing.Placeholder
+}
+
+func (p *apiPlaceholder) Relationships() jsonapi.RelationshipMap { return nil }
+func (p *apiPlaceholder) Included() []jsonapi.Object             { return nil }
+func (p *apiPlaceholder) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{
+		Related: "/sharings/" + p.SharingID + "/placeholders/" + p.DocID + "/download",
+	}
+}
+
+// UpdateExcludedDirs is used by a recipient to choose the directories of the
+// sharing where the files are not synchronized.
+func UpdateExcludedDirs(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if _, err = checkCreatePermissions(c, s); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+	var args struct {
+		ExcludedDirs []string `json:"excluded_dirs"`
+	}
+	if err = json.NewDecoder(c.Request().Body).Decode(&args); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if err = s.SetExcludedDirs(inst, args.ExcludedDirs); err != nil {
+		return wrapErrors(err)
+	}
+	return jsonapiSharingWithDocs(c, s)
+}
+
+// ListPlaceholders returns the placeholders for the files of a directory
+// excluded from the synchronization.
+func ListPlaceholders(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if err = checkGetPermissions(c, s); err != nil {
+		return wrapErrors(err)
+	}
+
+	dirID := c.QueryParam("dir_id")
+	bookmark := c.QueryParam("page[cursor]")
+	limit, err := strconv.Atoi(c.QueryParam("page[limit]"))
+	if err != nil || limit <= 0 || limit > consts.MaxItemsPerPageForMango {
+		limit = defaultPlaceholdersPerPage
+	}
+	placeholders, bookmark, err := s.ListPlaceholders(inst, dirID, limit, bookmark)
+	if err != nil {
+		return wrapErrors(err)
+	}
+
+	objs := make([]jsonapi.Object, len(placeholders))
+	for i, p := range placeholders {
+		objs[i] = &apiPlaceholder{p}
+	}
+	links := &jsonapi.LinksList{}
+	if bookmark != "" && len(objs) == limit {
+		v := url.Values{}
+		v.Set("dir_id", dirID)
+		v.Set("page[cursor]", bookmark)
+		if limit != defaultPlaceholdersPerPage {
+			v.Set("page[limit]", strconv.Itoa(limit))
+		}
+		links.Next = "/sharings/" + s.SID + "/placeholders?" + v.Encode()
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, links)
+}
+
+// DownloadPlaceholder sends the content of a file that is only a placeholder
+// on the cozy of the recipient, by fetching it from the sharer.
+func DownloadPlaceholder(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if err = checkGetPermissions(c, s); err != nil {
+		return wrapErrors(err)
+	}
+	p, err := s.FindPlaceholder(inst, c.Param("file-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	res, err := s.FetchPlaceholderContent(inst, p)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	defer res.Body.Close()
+	header := c.Response().Header()
+	if length := res.Header.Get(echo.HeaderContentLength); length != "" {
+		header.Set(echo.HeaderContentLength, length)
+	}
+	header.Set(echo.HeaderContentDisposition, vfs.ContentDisposition("attachment", p.Name))
+	return c.Stream(http.StatusOK, res.Header.Get(echo.HeaderContentType), res.Body)
+}
+
+// FileContent is used by a recipient to get the content of a file that is
+// only a placeholder on their cozy.
+func FileContent(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	member, err := requestMember(c, s)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	doc, err := s.GetFileForContent(inst, member, c.Param("id"))
+	if err != nil {
+		inst.Logger().WithNamespace("replicator").
+			Infof("File %s cannot be sent: %s", c.Param("id"), err)
+		return wrapErrors(err)
+	}
+	return vfs.ServeFileContent(inst.VFS(), doc, nil, "", "attachment", c.Request(), c.Response())
+}
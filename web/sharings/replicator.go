@@ -58,7 +58,12 @@ func BulkDocs(c echo.Context) error {
 		inst.Logger().WithNamespace("replicator").Infof("No bulk docs")
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
-	err = s.ApplyBulkDocs(inst, docs)
+	member, err := requestMember(c, s)
+	if err != nil {
+		inst.Logger().WithNamespace("replicator").Infof("Member was not found: %s", err)
+		return wrapErrors(err)
+	}
+	err = s.ApplyBulkDocsFromMember(inst, member, docs)
 	if err != nil {
 		inst.Logger().WithNamespace("replicator").Warnf("Error on apply: %s", err)
 		return wrapErrors(err)
@@ -109,7 +114,12 @@ func SyncFile(c echo.Context) error {
 		err = errors.New("The identifiers in the URL and in the doc are not the same")
 		return jsonapi.InvalidAttribute("id", err)
 	}
-	key, err := s.SyncFile(inst, &fileDoc)
+	member, err := requestMember(c, s)
+	if err != nil {
+		inst.Logger().WithNamespace("replicator").Infof("Member was not found: %s", err)
+		return wrapErrors(err)
+	}
+	key, err := s.SyncFile(inst, member, &fileDoc)
 	if err != nil {
 		inst.Logger().WithNamespace("replicator").Infof("Error on sync file: %s", err)
 		return wrapErrors(err)
@@ -157,6 +167,15 @@ func ReuploadHandler(c echo.Context) error {
 	if err != nil {
 		return wrapErrors(err)
 	}
+	if c.QueryParam("resync") == "true" {
+		member, err := requestMember(c, s)
+		if err != nil {
+			return wrapErrors(err)
+		}
+		if err = s.ResyncFiles(inst, member); err != nil {
+			return wrapErrors(err)
+		}
+	}
 	sharing.PushUploadJob(s, inst)
 	return c.NoContent(http.StatusNoContent)
 }
@@ -181,6 +200,7 @@ func replicatorRoutes(router *echo.Group) {
 	group.POST("/:sharing-id/_revs_diff", RevsDiff, checkSharingWritePermissions)
 	group.POST("/:sharing-id/_bulk_docs", BulkDocs, checkSharingWritePermissions)
 	group.GET("/:sharing-id/io.cozy.files/:id", GetFolder, checkSharingReadPermissions)
+	group.GET("/:sharing-id/io.cozy.files/:id/content", FileContent, checkSharingReadPermissions)
 	group.PUT("/:sharing-id/io.cozy.files/:id/metadata", SyncFile, checkSharingWritePermissions)
 	group.PUT("/:sharing-id/io.cozy.files/:id", FileHandler, checkSharingWritePermissions)
 	group.POST("/:sharing-id/reupload", ReuploadHandler, checkSharingReadPermissions)
@@ -201,6 +221,9 @@ func checkSharingReadPermissions(next echo.HandlerFunc) echo.HandlerFunc {
 				Infof("Not allowed (%s)", sharingID)
 			return echo.NewHTTPError(http.StatusForbidden)
 		}
+		if err := checkSharingExpiration(c, sharingID); err != nil {
+			return err
+		}
 		return next(c)
 	}
 }
@@ -227,6 +250,24 @@ func hasSharingWritePermissions(c echo.Context) error {
 			Infof("Not allowed (%s)", sharingID)
 		return echo.NewHTTPError(http.StatusForbidden)
 	}
+	return checkSharingExpiration(c, sharingID)
+}
+
+// checkSharingExpiration refuses the requests of a member after the
+// expiration of the sharing or of their access, even if the revocation has
+// not been done yet.
+func checkSharingExpiration(c echo.Context, sharingID string) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, sharingID)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	m, _ := requestMember(c, s)
+	if err := s.CheckExpiration(inst, m); err != nil {
+		inst.Logger().WithNamespace("replicator").
+			Infof("Sharing expired (%s)", sharingID)
+		return wrapErrors(err)
+	}
 	return nil
 }
 
@@ -244,10 +285,30 @@ func checkSharingPermissions(next echo.HandlerFunc) echo.HandlerFunc {
 				Infof("Not allowed (%s)", sharingID)
 			return echo.NewHTTPError(http.StatusForbidden)
 		}
+		if err := checkSharingExpiration(c, sharingID); err != nil {
+			return err
+		}
 		return next(c)
 	}
 }
 
+// IsReplication returns true for the requests made by the cozy of another
+// member of the sharing, with the token given to it for the replication. They
+// are not rate limited, as it would slow down the synchronization.
+func IsReplication(c echo.Context) bool {
+	sharingID := c.Param("sharing-id")
+	client, ok := middlewares.GetOAuthClient(c)
+	if sharingID == "" || !ok || client.ClientKind != "sharing" {
+		return false
+	}
+	s, err := sharing.FindSharing(middlewares.GetInstance(c), sharingID)
+	if err != nil {
+		return false
+	}
+	_, err = s.FindMemberByInboundClientID(client.ClientID)
+	return err == nil
+}
+
 func requestMember(c echo.Context, s *sharing.Sharing) (*sharing.Member, error) {
 	requestPerm, err := middlewares.GetPermission(c)
 	if err != nil {
@@ -0,0 +1,61 @@
+package sharings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// UpdateRecipientRole is used by the owner to change the role of a recipient
+// (viewer, commenter, uploader, editor or co-owner).
+func UpdateRecipientRole(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	s, err := sharing.FindSharing(inst, c.Param("sharing-id"))
+	if err != nil {
+		return wrapErrors(err)
+	}
+	if _, err = checkCreatePermissions(c, s); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		return jsonapi.InvalidParameter("index", err)
+	}
+	if index == 0 || index >= len(s.Members) {
+		return jsonapi.InvalidParameter("index", errors.New("Invalid index"))
+	}
+	var args struct {
+		Role string `json:"role"`
+	}
+	if err = json.NewDecoder(c.Request().Body).Decode(&args); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if err = s.SetMemberRole(inst, index, args.Role); err != nil {
+		return wrapErrors(err)
+	}
+	go s.NotifyRecipients(inst, nil)
+	return jsonapiSharingWithDocs(c, s)
+}
+
+// checkDelegatedPermissions checks that the member who makes a delegated call
+// to the owner can manage the members of the sharing: the sharing must be
+// open, or the member must be a co-owner.
+func checkDelegatedPermissions(c echo.Context, s *sharing.Sharing) (*sharing.Member, error) {
+	if !s.Owner {
+		return nil, echo.NewHTTPError(http.StatusForbidden)
+	}
+	member, err := requestMember(c, s)
+	if err != nil {
+		return nil, wrapErrors(err)
+	}
+	if !s.Open && !member.CanManageMembers() {
+		return nil, echo.NewHTTPError(http.StatusForbidden)
+	}
+	return member, nil
+}
@@ -366,12 +366,9 @@ func AddRecipientsDelegated(c echo.Context) error {
 	if err != nil {
 		return wrapErrors(err)
 	}
-	if !s.Owner || !s.Open {
-		return echo.NewHTTPError(http.StatusForbidden)
-	}
-	member, err := requestMember(c, s)
+	member, err := checkDelegatedPermissions(c, s)
 	if err != nil {
-		return wrapErrors(err)
+		return err
 	}
 	memberIndex := -1
 	for i, m := range s.Members {
@@ -450,8 +447,8 @@ func AddInvitationDelegated(c echo.Context) error {
 	if err != nil {
 		return wrapErrors(err)
 	}
-	if !s.Owner || !s.Open {
-		return echo.NewHTTPError(http.StatusForbidden)
+	if _, err = checkDelegatedPermissions(c, s); err != nil {
+		return err
 	}
 
 	memberIndex, err := strconv.Atoi(c.Param("member-index"))
@@ -516,13 +513,9 @@ func RemoveMemberFromGroup(c echo.Context) error {
 	if err != nil {
 		return wrapErrors(err)
 	}
-	if !s.Owner || !s.Open {
-		return echo.NewHTTPError(http.StatusForbidden)
-	}
-
-	member, err := requestMember(c, s)
+	member, err := checkDelegatedPermissions(c, s)
 	if err != nil {
-		return wrapErrors(err)
+		return err
 	}
 	addedBy := -1
 	for i, m := range s.Members {
@@ -910,6 +903,21 @@ func Routes(router *echo.Group) {
 	router.DELETE("/:sharing-id/answer", RevocationOwnerNotif, checkSharingWritePermissions)                 // On the sharer
 	router.POST("/:sharing-id/public-key", ReceivePublicKey)
 
+	// Expiration of the sharing or of the access of a recipient
+	router.PUT("/:sharing-id/expires_at", UpdateExpiration)                            // On the sharer
+	router.PUT("/:sharing-id/recipients/:index/expires_at", UpdateRecipientExpiration) // On the sharer
+
+	// Role of a recipient
+	router.PUT("/:sharing-id/recipients/:index/role", UpdateRecipientRole) // On the sharer
+
+	// Journal of the operations made by the members
+	router.GET("/:sharing-id/activity", ListActivity) // On the sharer
+
+	// Selective synchronization on a recipient
+	router.PUT("/:sharing-id/excluded_dirs", UpdateExcludedDirs)                   // On the recipient
+	router.GET("/:sharing-id/placeholders", ListPlaceholders)                      // On the recipient
+	router.GET("/:sharing-id/placeholders/:file-id/download", DownloadPlaceholder) // On the recipient
+
 	// Delegated routes for open sharing
 	router.POST("/:sharing-id/recipients/delegated", AddRecipientsDelegated, checkSharingWritePermissions)
 	router.POST("/:sharing-id/members/:index/invitation", AddInvitationDelegated, checkSharingWritePermissions)
@@ -1055,6 +1063,14 @@ func wrapErrors(err error) error {
 		return jsonapi.BadRequest(err)
 	case sharing.ErrMemberNotFound:
 		return jsonapi.NotFound(err)
+	case sharing.ErrInvalidExpiration:
+		return jsonapi.InvalidAttribute("expires_at", err)
+	case sharing.ErrSharingExpired:
+		return jsonapi.Forbidden(err)
+	case sharing.ErrInvalidRole:
+		return jsonapi.InvalidAttribute("role", err)
+	case sharing.ErrInvalidExcludedDir:
+		return jsonapi.InvalidAttribute("excluded_dirs", err)
 	case sharing.ErrInvitationNotSent:
 		return jsonapi.BadRequest(err)
 	case sharing.ErrRequestFailed:
@@ -33862,162 +33862,173 @@ lQv5MZnyEsS48h4n4q3AanEFdgngjGfOJGlP0VQVw/NStmojvp49J8G1/y4wrYJ2
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/en.po
-Size: 37355
+Size: 40317
 
-G+qRAKwHbGM4Xo/lzlEZw832cJdnlKFYdCgfbbqR+TP9qlb/X4jYl6rLRZlrJ7Hr
-AANPGhbWAQIOOWC9cKstStMbXu/ax0VZQq6pVu+m0gUuZPG5eoeEQxKfwgqBtf9m
-8ZjF9hVde+Uy56uzL0RfUdWvxl0tnKuELTq9zZvE2uo7qjNHAKMD/11KdX2c/or2
-i8cMy4u9rBir982UoYwLEh9FxvhMPonJmek/VViY0i0WV4I50Fgz5s0HsFjwztcC
-dHsUTt5GEjNfucJMaQQyibSJzuePFjro+oPYklc8tRLei+V/McXpacP/S/Tr/vAG
-F+vNNKUkvSUe6H69NZ7lvf7b5yvg5HO7n+QHcCiGoomYSI8M3P/AlTIKIHGwzj+f
-q/5W3t+1GXCpnbLwM9zmPYqII+MBjwUOZ2eSVzzk70JqweRY1jkey7+oIj7Fc3us
-wj8fCBPKT+hXp4YkY817stXfveUMGzfqtJRXp7xtgHBHubjtdKVfEl9zigGmSYZX
-H1XXNCVELRKXM3PriOe0JpKyZHxz4/lCnU0luh1SLD0ZKCu6BFbRDo6zmj9ClFvR
-urXGe5zRHoOGKmWdSMtQ3NzxPuCyn0scCUjJMrDx4CQPjvcJxccZxXs3S0fterf/
-2sR1myb7DGGKu/rjXgdViVLPa9J21VTXTYRSowUxCYJ/MSIAEX0kI0TcUGJOJuGZ
-rkk3nhcNwrLMzncqyLYrV0fm0m1r80MVl7nSNOaetv6+rW4lTbp+VDRfCiFs5trN
-tD7cI4AMP8MnTEJK5R3CkzZVIdGWXVDsIGXug2iCN3ELajKu+Xlkjyd4i2t7A4eb
-PckBbrAuWEwgw3wG1R21uwRYpydkCpQMXlg7bZw2bZIw6A8Bvi8l7Byy8+YfPgcW
-1prPOj4fhebvHsWtGa7h5c9X6CokUeNGpQl3C7Aasgg7B9voEEZ/Y8XpPUo5ZEcQ
-3jYIUUIxZfjcgz5/uh5/scgN9FAHq5v1dIIrwHTlWUwXj1lGwRZYdYAlY/oXg9ok
-U5wdqYKFPZnkNvUKtAlB7H0EnrmIAJpqAXM9kOzA1vXrSysR/ke2GivR7SQBaiBJ
-SRrBNDUwP+TDlTkWq2AoRLf9G4ZVtkzebJbRMLyoS9YSoiOAcogbqzPW7JWtv2HF
-SEs1G1dvcaCI5xiL5yh3aNWMCg/peo/p9jA8k1DR9ZAoHe+o5nhyLAojL5n52rAO
-Kwry8fa0XgJQFGtb6C8gBzETJaKWbzvnXFyezEDIfPIiJfWcW+pp9O3mJ7tCz2/Z
-BIk5RhdrWmGz+V2FQsT5dr+Yh+88z+yM4/wm0Kex7W3tLmJGXa+f+qCCthvDDPa5
-U62Jd6yChTYAmX1dqmhXrJfneL5ZrvAIpYuzSmpq6S6FcZo6NLEpwkQ4kdIKuQPi
-5nlPfcb3Dokic3XxsR1scNEDxxgtGtb6T2PPDIMGv5we3QB6e7oMW5YGNGc891EU
-FZYrl4yfTQBxcL8JqvzQ3kbpLCJvNztRSbf6p7Xii+tGLyBruriBgidNrjzx3dG4
-3bd3oGJwZXih3Ln2LGGRvCQ3Wx8qqTYU7paEEZVHlFoc8AVv1uWF8ZPsmKvwn+gl
-DSZaRQzyFapdI8PUybCiur/C/3YecxENcjcEeADUhp9MhuHPx77VQKUVgjVEdlQk
-hcgeDDIaBjlAzA8El7eKD1mpMXMvtZzmeZaf/5GW+q4gO11zDtFjckzDpxBjgZ6Q
-5gWsf/T5pViG7DCDt9QLDfznaSdrXDmsmWGjN1n1EYMPwAj9cwtAmKowfpHk9Ltq
-BQxnprnPfnKKXkhe/TOG7sE2B+lJcYUPk6NThFJFjO/1AMfYTji0ENw2xJuTPYMj
-1RemAYOlEZ8WtfSkYbCAPM05lGTCqQFsrsCRoDJjYAOMFVxN0u55AYcMymrswp41
-wF+Ky65l1IiygnBxrSQVIFY+jYKZSlDjXVCE5Kf7i3bOtGYmAeo6IvaB5fBRtLgR
-gc1D1NtlDyvssq12GExU01D4Dpv+DLbSua8+xO436Swz8nARJOvDnTHYdbdsfP0t
-nBvPsMMTRH6eHRl4GQDIdLuJgl+g53Uvmqou1DvFrFgFuZodXFzmJ6Qq19zgg245
-J+TMRCEUb5hZkUdj6zMFx8gdV+Jpe9gI4mhX4MAVVmvF0MV1A7FxhyNa6VPrh10A
-EOJS3wTnU36aivlESJPHscMxc7eEo/MbO8w0Vreg1AIyjjC2mT/RJXGw3Rlk3XhG
-ccoXa0opJDDVMsTrpJi7yvDtd+h2A1H6DP1Uk0WQ0y3NgXwGmgFA2HXdwpt5t8eb
-cVrfZueiyNaChvVT84aFo4lLn5YGiFfufK0zEOVfWz0vReo6Sjm0+kOxzSQqYCH5
-8Y2vX1QFNbNG7IKEBHK/Re7cCCpifpgVgbBHq/gaPMMquoBFEdmDCIIrJH6lqv/y
-g/2gVh6LYOIy2Oxt0Im08YLHsj9h6+4Ws80N7N5EDbKMFP9qVqF5tRlcF8QtboZs
-jSi5+TTonrqmJQIRrZt9xNdOqu/6DhTcuKfl169HlnJKH6K1cREpqKzEGuhT3mo5
-tyfhZvqAg9iDud2h0442Yv0bSZQWr7gu1K3ZT5x9T90FhUwkHUSVpxHZsRhLLq0A
-/uAXjlrb1KC120nsE326JZ/h0dBDdogOIsIQpze5bIUWtUQRcdM0+H+o0luRMsc6
-ENoxaQtFMlSIfiJcM6B5NHvUJpc59HWAu9bDE8+hOW2OY6PbQUi5cj3WEutpu6n5
-dLrWjm9Q3PHszHXVNDGJkToW14iDNRCERv1G+Pm8xv3ZHW8Ljv9gzsxXPktYtjC/
-jRmdSV14YxlKuHoANxwrToY6IDzY2nmJvQ0VaHsEj/r1WxQA8QvFUKBgqyH89cu/
-MNiMNJSltz2t3t0BVQZFV4sLVwUyY7cv6EXmYv3qreDMrcWOWBmVJJBkgRERJM6L
-QPhhd1diVPofVCTOSiqgsVZ1cLbdJFIBgq9VsIcF+xTtTHz9EjboMIg0GB8e3bKC
-YI6OfdluTeiXkohrsHWfdSRGNhOiCWY2aqk6xL6qRVjASSc8iOFTnrqeTjlrlFA2
-1FkaU+Csq1b/U23HG/GGPfbGxdvrjP1/jX9Z8iAI+OWEODIXIoNrfssHUZhZE6p5
-NG5ainRZqwGcJpIuv+0ebna8s1J7eNvCmCfewrcFizXMDNi7FpOEErzButvdfq1p
-Fozv4IbkgNBRW3G3pU35swrHKTs4QMwRGmmyw6TaLiTkQFnQver27cy2OQOWxglt
-6Knms+Y3RAv4H46X2Bm7BIvjmr56QNcpYhJe5mrde9exVfVeuAigYLeWbywj5edf
-2/wcvJcYL9mAKe3F0HyyI0+81f5nSVCWtJ7Ui9Bf0FX+V9tPk9/X3hM9GHlZXBgO
-wasayXabsOzUDepjhnZUihL3zzDmRJsUTk6GqHFCJo7bccgCR14ZugmK3cNrntuG
-F5oIzVnbn4jgVNDSLOnqHGx/GVHfHR8RHfZ2HhZi5B2Xr6wvMrGfE00ekLY1whsj
-1/pXQ6NIf11IZxRqdDh/rBEddePXJ73S6cbrf/Lmhhl39IhMYPF+h7y9/nkK8JNe
-i2xT4eIRUl9+fdF280MtE7j6F44F8r47mDBgrYqmZciFTJ91cGWb5pbMqmqW6YqE
-SZbGYE/yJg0k4k1R5r3BFlMJijytq7Xyul/N92qCONOl5Zl6WL3xOky4U9xSaNdo
-mwxjy8C67rS6h2A6fm+RtZYD/8vPS40v5j9Z/V1ThDGOZqFggGKg2J7Tq+fNcJyo
-YPgC++jdt4dA4hgP/2lkwBEZpY6XfoPkVHX0Cw2LlLYSHCVWZ3L1ImCNqG8Nldwf
-ZziE2XlwDlt58EjxW/Xt1q9CKudMPMbZT248o4ZNEzAamKExFUb+TWQQDcTJhIEC
-CxwNEwjbuZpu9punwYLV7qKsB2b+3EsRDAigGW60+5w6kEG14wEIoVi+/02SrJRR
-9ELHU8+wOe8Jchz5SfQo2+PwMwV7EozKi/HxcSyUik30J9T+0p0lngwBzxB2kzrU
-nBBkkV4jHO4DDFxCya10c9gBfCLybANLL5lFAkULyg4mVV8fkRfl0b74HWOPMqsO
-J16IgpTWcBImhthVDx1hU9yA6Fb6QpKEkHLqK5wqu0WQmrV9d/50AITVcSSDwc5A
-NiAuWnEaqhOzL6nnSqCjOLRelNBNO9TqTn8P4kq6SPYgzFe2+BacOojCm6QU5HX4
-dY8em7YiEFjMi1cZy8eJQzzmVuadUufKimZH0NuKBEros/fOYEtwqrWC0omD7Odi
-dOC8QutdRtnFQX8OANreG4KBU0AwAgiGEV62sCQOZ3KOG5U3HnDogHD8az7ISrcB
-T1jkns6fi8KMHNXhLuy43g71p5l9xu7qf8wB2ARWHdThGUmAiGcWdyUKsj4ycFg7
-a0xJ76r+tajo5H1qldNRWzoWdWaje5gAejemZ2zng94z6iQk8lSmgZs7aYEjeZv4
-x6bcn0pXlMlLYdKM4eROs0yOvqLCMqNy50hTSIsP854Tz2nkfn733JFh3TtudgDG
-1tFRm6OYnLPmLBmScjp9RCLuoAO4SFa3tt8Aew3BGgh0jUNUQjUJuRrKvxSgYxHo
-DhNDhmdpPBXCtGSLIYXlkPgR4t1wUG+LnEkTTWmw5Cnw03tOvl9/jVGd8yWltF9V
-GlgffWNCveJHqszcreJL4YzbZe3sAQWSWhhZIzy6Mo5swSyrDkviGdTYMUb9AqoX
-DB62CIA7wDUgnglhNFKHH3dxzz6QJIWYY97WX2rxGzRRBmHBe/318CS1KUOBwvVr
-U6QkGizkUfdRnr28TbKf5I7TvyAdS/FY/GFH7imdllR+zfv3TzMsa4risXQehmLu
-jYwDJ656c7NKZZXpPQdBirJnA85x4+Au7TZmd5KuPi6NJiEnRhwwm30DANgGuIz/
-Pd4pt4AD+fsnQIwLM42KpGgFO71xnsBaf9zgwZh/I0KCPEwaP7K0QKXAgn03yLwt
-BXC7MUs584jTB7iLM/FCLKE6q4L8MxurlXaFaI3y9QdwnjJclS9OfEIGFnz7hfsy
-lqWDTqyE3RnwaMXvqbX560RY6HSLGzlXodmjfLDFwGwgBD2BJfEbWeygqQZbewN0
-aIldwV064qNARWJG8o1tRSAqPSMC5OR3Vz91Xi6faIOLMjCVcZLErJM9ZhzWoXYa
-Sz8DdXs/CbfP0hk6eajZF4vJee4P/5BMnayaeCKmD79xoVHJvHs3wKJqkVVFFXx1
-AlpssPFo5aKkSYEd8XN/CDwsIN7wFvTyZ+TtzP5xnUqEkSsmG2up0B3xR5Qci9hW
-gEPziVtfJkAKfarFn0X1eOUnbcmXOi/cnwjmdQognWielnh5Z0YNQCWwIueHj1sk
-lIczkC0VIKexpvQz0pn9rFvmcYeurlms0zIQnq1t9XbRpcpdDo+1vmRlY+9FeSCB
-SsYfqVcr+IGkJYfrLlelvqXPAQAWckYfd0KFhACKoBg3FWBzoc+N08MZF8cRlUW+
-AgnjmqwnoVtzGPd0W7xepv9Bdqd16tINrE489l/B1TSXabnybu4oIC5gJoyfc77S
-bpCB2N87pnX3d9quI1sGuPUIdD4Kq7JxE11f/b2rDaSn62V9Wx7fg8XytYMVcYYi
-nm54dT/6GCYXNn8qlpw3Fv6mzCX0RjPQ6KaDS7/Ylga/jSWS3sPislNNVvCHlNDM
-VHo6AKsIc5V+aeSp9QHypyEVl2+p3a3ARIiA6wPd/9uf5XsEypD7/7vZto0f70ft
-dRBF3uXtHcapNTAie/ATF+vXo/Rw0O73S2WskG3tEKf9gpZT89kHCtHv3ysiFORy
-HE7FVSF40hdk/ccqRw3q5iY66MjletGzcMNg2moQWrkCDzJ3SvpSbR9ECaBJ3ynL
-O4Qr79vLglEKb3z5CVqB2AiiSt4dewwJu3d0pI/IwZ/ttgnMhJ9vC23PZ2dE6Kx+
-3oRzG+nQLDzkxMMzB3sGRWJ1npKnhPXxSVKm32UeVsWTMkIlhzKz9VIUSSSuSRwN
-bCtOJ/dh67FbARAICn+lwTjjvNHyX91ygKxvkdwKK94zLuVumm/jBXObeieXbzpD
-zA21SeOEfHTCU9WEC5nxxqFCWDkzw3KU0U0bivh5I7NtnMRTNIuysNaUaSJejACJ
-RiuoQrmq+Bwj5/nZkjMjAduHJUq5ZiTY16zA2HxvtpwWdr+GZvkTRA9CFanu3D5Z
-6dvCCq5YLLPsznHu7LIqbhU4hayp+t4h94Q8h3yQolJ8Tvf5CpLAfstyPdy++qjH
-IK8dXscR6qX44sWUXDqRmojk+SORodjWhd3kkLJ6kzQYDWKjSEdMp0TMo1w6kZrk
-9IDu2yQamBdmSa/Jwu0wetCvHO89nWB45VTNfdf3Ge+pUov9/BoCNJuwnGoOBZRk
-jeAoSbMhSu2lQXmMO/nyA2TE6mSSx1UcrzkXkKE09Jt+VpS+gtHOngaSrO63fYJT
-0TWFRr+gyYc27DiDSfPWdUQPKVwCVle03UKpkq0X0BRKQsnvGBl5MX5uCtKZkML+
-PuY50M7s88kFWb1UvInARuMR27dCYZFapZIheakROfLakS1AeZ5WbUGQ7Hu3O6Dq
-clHoR3a6ODZ3BDhcf6sZDlOQztNpICliJOOT1HbkVikDwtpuWNCU0IEumhTOAYGh
-IC7HMy+qFJi0fIAAsxWycippLWyhogrKS589epWNADwR/YABmfVWN09wVU6DayIx
-6+bl+7CTAidiWzgEenzLubK2iffYRcl+qpoULBlD45eia9zp8+l4qCME590bGfL1
-sQOQS5cva4ekRvnSf5La8B3Vi+eqjC+skZKCT0ZNXuRd+Hdosu9gC3VywaebKEvY
-Tg/y0VBpxjAvKSFqjnFzbYz+oyJeQfJ9sWmYwmbXfVSRxbWtZ3TNYGx908kIJtZb
-f7nU3mYyvi37xuvr/FuD0v83YLxhfmuY+/9WMKVvFJJ6taww82+Fd6QrYYTmKH5b
-EsX4+/fNwUDLluWqJIwcTDTnOZkmKNxAxwnucnNZInA+k2CxzKoy9uLPJnoIUpxg
-qzOpEJDsab175Q8ZQHV9dAjtSD/Pfd+IiB/zVJlxYm77xhaEqDfSWipF6oUS8Yap
-DzurVfZMGfuT9YAzK4s6SN8g2T6ulMkjSOc6zheY2qzYBuvgefqSG2P7LFKwACsR
-uQLTYJTiMuxi/Xjzk5yK9H+Iq1+pYxWGKK2KWxwQtElVwGTnkOCF5txGwBps6yXA
-DNEBn0dI3Njymw/qTmlbwefMjINdL4F4MD/fpjuTHqkgOVX9Ki+Lv9j8Kh6hhsz4
-xoOVXwhJtnNMeRtuJumWwY42HoInXM6YKXscUo24g3ntrNXQFxZB8BiS6wE5POp0
-2v+nSXaJFvoDZbnQeeoeTrx50pzelZFxuKNsrhw8m8gcFZTx5V24WXGIRQzECP2v
-1nm6r1RDH/aGaogKcKP6DKYszVuRLXN9iCMMLe9qpy6H3pSXt4QKwBmRUsycRgex
-oUuTM+y1VtJB1099zvmeEpdC0z3PYDot8XHN6ms6VepXqGEVavbzjzqiXMpYT07z
-DsAIPI3jij0JfljyXei6C5U40h2eunNu4BZk61jDsin2Ea34QVIBBS1w7YNCGIim
-yg2VN3xgr7rEKCDJuUnjHsIRxUY2CKoUVCgUNSlUQzE7QUh/XBS+UgEW920uJZ3+
-1l8u4eaVRFyJC+LdSK+kKDQjUU6W9sZXLqbEmq0tlcq9glxMLvq2FQclC+3rftnq
-LHP7Qawa88Eha/k8F/FHlIivaPt0FdF3ToMn/4MasPxgsQaOfpAjf/Ohfx1Ik9Aq
-FDhl15IwmjBrcLFUhxraxdIziUkvYMMf173XncVrKTZaTOoRQP5FehYUdACKDSoG
-I0mSa+ARF8l7nHXy5OuyUtacSQOhlMFhZRgz96ObvpBOKZrM/ODJx8Zzn0tbL/WQ
-dUJVmv6pQ/gkqSJz7NkVlz1dw7pXgRuPbaRBx5MjJc8I9my0wPifKo68/R50ZL2t
-yL0lyfgnXRXRORk0Xso8SlrvVW8OrKkazWrQKS7eK5BZHKrTS2vgC2uV30ELACEW
-vNNObH6XgG2wDkTVD/ubbih/EduDhQAa2Krnb53JSyTJZEwjvsmV73Uo/q5f9R98
-6V1B1H9G067Od/knb+/2F8QrPKhiwwNYkYqMjZ4tOwbBZmEH2l9xyApNHqw2Zuvv
-nz7WLnM+vBtdt2FTyNUrSH10OBHIqDTHrbU05PVpczVhIBPluZGE3vkpyzJwZhCO
-sFpei4kKhgy7ml8g4CkjMptUYg8R4EVR8u1w7l9u/XgjwJIyAgUzdlYO39QdzaTh
-kNgd8hnu+MVdLq2yE1T58hEbr5Eu3H64y/NzfK3ANTADdE9F9lKr9KIITlUuU75X
-z4Vt62Su40BtFE8kAYxeLKeaY/NDiJ4m9Kz3Az2XTDcEO4wLjneGQwVFiEEYbAv2
-LcGQKkOT+OADSFhfQ/HZfHA2xSDmE8WSmqoDPk2EnpCgaom/N/h103H7D71EvGWE
-LYbyDfOdhFj6lF5+OewD5cUPIHoqzm5kBKinq28YFPUzJTuYi9xmjND4uL1Nkr0/
-fPJd5F8KwnKCHQFSTvpAGoWaTPYZpVoCK0kwN5cbgeOIj8sSdqBlO9Y48YxJGtvH
-umkPnPIB/rj07VIgD/dbNU/InnbMxi8NZB1jEwn+3t9CkKM4l6kaA+bC39wk2OVe
-RQKPsQFERW6nBMb18LHlmS4nkSGCWHvduItGaPo7LsJ4IW6v0XaGcYbvz6+VtNty
-zQfLQTAG3ETymXGliFVPHPQ+QiOGNmKN1Y+Gu0TYe0DTabjPix6nVYps8+VqmPvs
-U2doTAZVSXLr4LtY3bWZ13aWN7FD8MwiRzskehTqjImt/vAGMd99mhVSd6bsOVkv
-Pzk3pSz0zQNHRf/oF90t1UQPYZiWYPKw9TimQuhUgYzpfyBKdxKXCaJSYTpbNcKm
-ZA4MkjEmR1oxlUtfjs8GVlyMn8KFhR45JPrdJvotnKET8k+neLItPCBs/YI5G2xu
-xr0j0v+/j87fITewAyfbVFs/1KIMCin9bDTf7ORY2rb/qhS1MOOddGy0a1TdjH0u
-AjwgeEy0QMzEnCPHXFphmEx3IFg5rgllAdNRiBwkGIeiJafVT2eqR4g1VB9Jxm+t
-e20Rfvzug01eTeqHG4jQijc/LUa5f82ispz31/jQr+8xhvd5T86085grjXuFLntc
-YaGFl8IAeacyxX5Qq7bdWJ1geh7jsINrm6AwhJVPEJ0byb61RhSeRXxfBYzknWAT
-uIEyHYSx5HM+6AbvigG2JxdnmQsa2zqrIvu7U6bJLgnWCWTYJtgQOhhWflRuZB3Z
-1t9gzesnXnaezmKbkx0DaXPi4PZzL3e2ETxmOTCN9r7dqrhplQglEZRA5TMbGN2g
-49/QublN/OKIQvNtSphZo0TUDViaqDAqQ6hXGeglvi29KS2lNHp7Wkj9xb1tiNVK
-Nxw9lB+ZA7g34KNLodOCdcCkkEnyZqoM1TIu+dvbJnTooPmStFEQB161P4jsAJC4
-V9Z3uRqusOLIA8UGq1pqxoMUUaiu443xHrToFDJNCNV2ZOmemN9TAWmri1zIc04/
-i0O5nxBDcK/kFKQVQTJ7UwgMNuSx04qqAof0MJjfzmI+8K/Gmjr4JEAxD49KVYtS
-WYn/MlNT6HSb1QBP0Lc4peYXM3txxGqeIUIzFgirMUlNRWA+uNOQTVBIhPUNYPKj
-dTuwtzrcqir3Oqyyeubt8KO/A3vrAdEbqwbmzeN1gUIniF2qKy0Nn7jNBg0IJbiY
-x6ys7IETEA==
+G3ydAKwHeMM5quPQ9/g1e9JSmWMtIYQttvVjn3olS1WratfV3QfZwJefq85pJSdy
+SMwORcoBAg45YL1wqy1Ku3Rm/TUVDnLAEflj5QtJFxL6FBYQZv/RijmtLhRda73h
+9a59XJQlJObM1NKqXVUXeUQok9Kfmi6VP5jco3AfNAIvGRZda/jvUqrr4/RXtF88
+Zlhe7GXF+N/7y9IhYJBwFAFwZk7i3Xvv+bdKPVLb07BlwWgJDbNj4AvvfvW0Wmtp
+qFpaahkAI5czrtxh5jSSN4m8iap0aey0zfxhkQDHBIkhuX8r/6s5Tk9b9l+j7e9f
+3jpdrTs1pSR9IB7pfn0wXuS9/sWXK+DkE7s38gM4FEPRRExbjwzcL+eVNgkgx+of
+B3n/QFW/6/mbAZfaoZV/w2Peo4g4Mh66x7zD1YXiDQ/5d9lqwduxrHM8ln9QQ3zI
+cv9aRd8+4BaUn9CvkoYkY83v5Kh/95Yz7LzoZCmvTnlmgHBnuThzcaUfEl9ziQGW
+SYVXH1XXRBKiHonLmbl3m+e0JZKyZHxz5/lXe5tONDungOw5hTLVJbCKKjiuav4E
+UW5F69YW735FewwaapSVkJahuL/nu8NlPy9xJCAly8DGg4vsHB8zio8rinjvqHTU
+r3f11yauO7TYTwhL3NUfjzqoSZR2XpO2a6a6YSKUGq2bSRD8jRkBNtFHMkLEDSXm
+YhKe5Yp0Z7poEJYxS+9UkG03bo7MS3eszQ81XOZGZMwDNf4+oGonEV1fJaKXQgib
+uXWU1i+3GKDAzvQJREhpvEN40qcqJDryqECE9LGcufkA+/K5xmUTgLhGTC286Xy0
+G0wMavDJr004ugtyNC4MEItnMBJFOokctr9xV8WkCDwjXdw9GTjcTwty7mFvWAwg
+w4KJbYK3e9AAKz1FaLZkocPWaeu0L5acxAAO8H1CsHMI+zv3+HdgYG3ZbASFiWv+
+HpbemlGOFPjvP+lrpFARJpXNeeuwfLMBtZmNigijv7Ph+jtKOWRHEN42CFFC8Vb2
+tZ0BAnS75WMVdOihDiY32+kCNgYz92C5YjzJmjiuCOuMUIgQfwvqJlB8CIQKFvZi
+lts0jNE+D7H3EXj8JgA0NVvmdiLZgW3r19eCIvxf2QlaiWZXkVUDufelEUxTj/gx
+zu7MsViNSCG62W8YNtlCbdrN4mH4myHoRESHA22fsK5nbl4rW7/DlpzWlnau3uHM
+Fq8xRs9R7tAyHxUeUk4fbzTEfFJCRbfvEJnjN9plpuZFYeQ1Pt9812FFQT7Une6W
+AYomcAv9AduLQwvMegRnOefi8mRmbq1/0b4jTHmT0FkT5rp4ADho4JjnO6bP++Rp
+dpykQOK4lXJaLbjkMHr/1Gycmhxj0VF8uQzXg9JSUo1/Xrmm6VR7dzy2UyVCz8+s
+gwI7RzcdtOxq61QVCnFsmNu4Q77zemHJ0NLJ4ygGYUWMInvWTQVpYiJou4ntYCGo
+a2932SRtagdQZBpIN+iajvGSnoSYmz1CE+eqip5a2mxh8q4OTWyKMBFOpLRC7oA4
+4v/hI74vkygyqx+f8MMOFz1QjHXUgBWgI+zlgtDgl9Ojo6rMhpdRZ5sGtJCw9iyf
+DGvYw9zPLkA4eDAN/Y4Y00TpLHKQdxvREbb6pw0Ep6tGKyBbuniAgifDGnk2xCNi
+RjRJAG3Ll+H1rHIdWMIieUlu9j5U0jQr3D0JIxqPKLU44OvW1s8bYyfZQcDyfzES
+HQwbWEH5Uah24RT0tFFBY3uz/1meiNPGdJkXclO7i59MhtHb6fh1oNIKwRqhBoOS
+QmQkCFmSgxwgjhiCy0fFhyzfGYJcLaeZ+PaDf9NSqwpS6ZpziB6TE10+REwQe0Ka
+F7D+0ecvxTJk5558pF5o4D9PO1n4zGGtjButy6rPynwAJhh/9wCEbQrjX1f0Mh+t
+gOHMDAYsVlkoLwkb6wdhsIWMVrnBBx06zytpIuYPRtlzWAlHll3YDfHmZM/gSPMF
+2nCwXubTopaeRBsRkEdKp+u8m24AhytwJKjMLdoAY4oryv2BR3CaQXGLXahZA/ym
+uOxbZ+YoKwgXt0rSAGI53GgdqgQ1roKiOSGtLzo405aZpOor1cEHlsNH0eJOBIYw
+0dCYPUzZtXwdMJiopqHQAZv/GjQi22/fh7e7TEgYebgylo3hruh42w80jPfjvHjZ
+FZ6wEfzqqMDLOF3WYEwU/K4NXgyl9YtCu1PMik2wq8nCxkgDkqZcc4PPxOaaUNSf
+QiieMrMij8beZwpOnDxuxNP+sBHE2W7AgSts1oqhi9sG4u0PJ54yprZ/dFVIdhz7
+Ljgnq2oq5sSmJo9zx3SJbl1PaUj75rSBFpBxhHHM/IiCkZPtzSDrwSuKdQAsNKaQ
+wGnNEO+ydG565Gff0O0B2v41stNMFkEud4oSyMdRFAAQHm63Gmt+25MQOdIvCmgU
+2VrQsKhu3rCauHDm024M4+Vc3+oMRPnXXs+LFruLlg0Dx0j0mbQ1XNaBfefrN9pi
+i7tG7IaEBPK4QRncCCriiFkVQNiTSaYRnnYXXdWkiBxABMEVEr982X/5QT2olcci
+mLgMNnsb9CJtvOCx7Ed4/TsNZ3qHDADaIjRGyQQ2WxN4CxL24tAOQj9la0TJ3adB
+9+gVmQjEyWDqiG+dVAn6p1ERu6fx13hmKdd5ItowIhtNlL9cA33ou+r7TsX5qp2R
+dwCxCAQoOqSD2BSBJEqLV9wW6tHsK86+R++DQibiL6LG08hx2YhlrVYAv/QLF60b
+adAacyVGqz7GjJ/wmI7AFUIhIgxxepPLVmhRTxTteFuGOEOV0YqUOVaM0Y5JeygS
+rEOb6qJ1GaKj2VN5eZpDXwe4bzU98Wy7Ry2idAoDhJQb1/OdyKTebvnDUFnz+Q2K
+O56yu66ZJs5B0tHjOnHwi2L3Wa8Rpv3AQX12J2GDk4JYy/TKJ8jKAeYHMKPx/ic+
+WIYS0ubhhmPFyVAHhAdbBy+xt5GiDV+CR/36IwqAmMhiKNC61hD++sufMdqcZajL
+mweavLuz7AyKrhYXbgqEYncsaEXmtH71VXDWpmJH/K26T5QExNHO2B+JAuKPra7E
+vfZfKEi8qeiAprWpg7PZPJEKEHyr0tUwX6eoMvHt7dhgwCAignx49MgUwTEf+7LD
+mtAvJRG3YNs220yMDGlEF8y89dJ0iNFdG7CAkxI8iAtYns+gTmNvlFA21FkaU+Cs
+q1b/S13HGzEMfm3sIh7iiP1/jT9x8mgQ8M0JcWZeaG9k61s+iQJlTTgp0blpKdJl
+rQZwmki6/Lb/dLfznWlt4W0LY554698WLLZwUMCev5oklOAN1t0u+nXmkSMzyt0h
+AKHjvuKipc35vwnHdVw4QXIOjXTZYVLNCrFJ0CB1SdUxV+ybU2BxnLQPPWxBNb/H
+W8AUc0uG8blD2ICg6atn+Z0iJol2rtW9tPa50WiFiwAKdmsOyRilVOHOFpcukFvn
+SxowjFAEDmnOTtFvDj/rXYZJ70mjCP3GZtv/6PBmkrvKe2KfttRu88oJJr8quZe7
+CcvlukP9mrEdhaZ0QDAMieYOAlesQ3R7IRPnLThkgSNtDD2BYv/wmue2YZAn7gNW
+ASlyWRW0dEu6OgeDcCb03465jM6FPA8LcXfPS1bWN3gHqJNXH+x3bIQ3R671b7om
+PQ+6kM4o1BJ1/rNFdNSNH6/zpOTGu38yjjm0Jw/IBJb5+B0+xD9PAb7OGxF4K6xd
+sv+bX/9mt/VfWQhc/QvHCPnYLfIwYK2KyDLkQshnCpePaW4JVVWzTFckTLI0BnuR
+t2kgEW+KMtcGW0wlKPJcv9bK63H1cmsmSFwpep6lh9Ubr8OEleKBVO69bzLcTgPj
+ulP1H4LphACIAL4c+G9/6Wr8MP/J6u+WYlhoaRYKBigbjI18vX0ZiJOHBRcgeIov
+bo6Awok//smoQMmsKHUCFnZIkaqjb6jdpLSV4CgHA/MwVAEjor43VBFQuOwlzOOF
+CxvLk0eK36btMIkWolpX/CGpn9x5Rh2bJmA4MUNnKuydu6jYNBAnEyYKLIU2TCAc
+52a62W+eButs3UWDE2w/usThCATQDjeoPodHci13jCEhFCsMYpcUf20UvdDx0hF1
+5z1BjiO/iJ5lexyeUnAgwagMOq8Zx1er2ESpRuknriw5MQSMZKgmdaj5RpAho0Zx
+eAzQccn2ft2biArgE5GpDSzSZhUFlDcpFUyavj4iLxrFffE7z556WB1OvBAF0b0h
+ESaG2DUP3camuAPRo/SBJAkh5buvcP30NkCU2uzi/LEABKtjUwfXpYFsQJyacJmq
+Ewc46WxLoKM4tL7oRjcdUKs7/T6JV+49ZQ/CkWeLb8GpgygMa7pLvw6/7tFj014E
+Uqx58SrjA7qwT8TcCkLUHdC8oqmbpluRwDKB7HdnxSfYF1tBKeEg+zqNAaQrtL7L
+LLs46c8BwASAhmDguiBMANKChMExLImjheIEjB4kDzh0QDj+PBsUlbPDE77Jx/KJ
+DjNyVIe7vmWF/I4+A+1v7K7+w2yhTWDVQR2eEQ+JeGZx101B1kcGDmtnjSnpXdU/
+NhQvgk+tcjpqT8fy72x0vxMHxjCmZ2zXgzEy6sRmMinTwG1xksORvA3966Hcn4rc
+FOKlcO4aOAWncSZHH1FhWdF5dKQppMWHGZJJDmnk/tzxCUXDunfc7QCMbaOjPkcx
+OWfNWTKihLf6iEQcWCdwcVvd1r4D9hYcOwgMjUNUsmsSwlaURyxAx3LxHSaGDM/S
+eSqEZcFqSmG5UD6B+G3Y6mc899dCQxoM7Q789D3fvl9/jVFd8yOltF9VGhgffWNC
+u+JmqizwR5na4Yz7ZR3sAQWSWribIzy6Mo5sAZVVpyUxBTV2jFm/gOoFg4ctUgFP
+KBsQrwQ3G6nDj4e4Vx+5JYV4WJ49HHb4DZp2BmHBe/v16ZmLU4YChevXpkhzOOQK
+RMNHeUr7dsl+5UOOfkFkmuKx+MOB3KOWMqn8mvcvnmdY1hTF8/Xl3CRzb2QxOola
+MHeraVglIeQgSHv6qsOl3zm4w+3B7J1E7s8Ns0nhrBUHLHuhAQAMRhzj/4h3yRlw
+oJThAljuwpBRkRStYJdrbwSs7ccJD2d4YOSKkIdF40uWFqgUWLAvAIWPpQA+lZvl
+m/KIa0q4T3B2Q1alOquC/A1nqyt5g7yV8u2jOHoqcFV+cTI1MrAiurP9NsaydNDJ
+GrGVAY9W/B6um79OromSW9zMuQrNnvqFLQbmACHoCSzJZMliB/VFONoboO9Yoim4
+7x7+UaAiRiX5xLEiEBWpEgFyQt2rf5UulxPa4KIMTAXfJDHrBNIZh3WonRrbN0I9
+3k/CHbN0CZ081OyLZSc9+dl/JFMnwCgmxPThNy40Kq754DmwqFlk/WEFX53UHhts
+PFp50a1JgXH5a58bPCwgPvBM9PJjhDD94KfXhez0evCZqKZCdxooUXIMcawdDtET
+975MYCv0YZu/hurxwlfakw97v3g8EdB1CiCdvKaWeHlnRjdEJbBy8qcszSoUkjSw
+IxUgR5qm9OPSufnZtsyTUd2sLCaLGQhP1LZ6P7Wrs8rhsdZfWdk4eFoeSMqW8Uc6
+9wp+IH7L4broWun3lOcCsD1n9HEnaUo2QBEU46YCbM3H3LhmoHFxnNDFEFQgYV6T
+jST0aA7j4eWWqz6W/0HGyHU69A2sTmb678DVPI9lvHE1dzsgbmAmjGNH3+hfEIzZ
+3w/IuscbWXXkyAD3HoGeCwlmNm6iAK6/32oY61F9rG/L4wdgLP+9Y5WjocqVYTh6
+nJIObxc2Pyqrng8WfpbyS/Yb3a1Nrlce42JbGvwxFlP7MIPdrmuygj+khGal6DUD
+Vrn2KhLVCNnrA+T3QgrXT2ndrRRNyAXsA93/2+fuTwTKkPv/ud+0/bX/Pmuvg5CD
+mPd3mKfWwIhAytderHSR0sNB+7yViljbtnWZOe0XtFyv0T5QsCKnfhGhIJfjcBhX
+heARH5H1H6scNXY3N+2Djlxup32TeBmQrQahqi3wILTTVc05j0F0AzQpwWUhmHDl
+fXtZMNjh7T+UQCsQG0FUt3fHHsON3Zf1pI/IGcHtVwdmwZ/39a7nqzMidFU7b8K5
+jXRoFh5y4fHIwZ5BkVhdpyUpYX28jhTyu9BhVWYtI9TtUIZaL0WR5CSbxNHAtuJ0
+ch/2HrsVAIGg8EcajCsuw17+2zseIABexPnCtPuMS7kj8+28Ym5T7+SmQ1eIDao2
+aZyQj06irtr1IRRvnD+GlTMzykZD4bKh6iS0cvHmSTxF3SxL8E2ZJuLFCGzRaAVV
+KFcVn3MknZ/NezNi0X1YopRrRoK6ZqUI57XZclrYeg1zAy4QPQhVpL5ya7LSp4UV
+3LBYZtmd49zZZ1PcKnAqWVP0fZmsCXkO+SBFpfjabocbtgT2Wpbr4fbNRz0Geevw
+PxyhXoovfpmSS1fXE5G8fhQyFNu6sJscUtZuklqrge8U6TT6dBPzJJGirCY5Pdj3
+bRINzAurpGMyezyMHpRux7Wn0xagnKq573qd8Z4qrdgPviCAuhsWXs6hgOa0CVyk
+lTZEqVAawMzT6RwZICNWJ6g+buJ4zbmADKWh1/Sz6lUUjA72NJB0KgZ2THAYQ31s
+9I09+VCRHmcwqWO7i+ghhUvA6oq2WyjVbesFNIWSUPI7R0ZeLOJbgnRJSGF/n3MM
+tLMFfnFBVi8VTyew0XjE9q1QWKRVqWRIXmpEuYAOZAtQvkWn2WJDsh/dXgb9pxtC
+abZT0LK7ZYLD7bfaZrEE6XKNFSRFjLoEEuWP3CplQNjajVK6Ejr7SZPCOSAwFMTl
+ePVVtQITlw/dgNkKWTmVtBW2UNEE5aXPntLMTgD+dQIABhQZWIVNwU05De7wzFlh
+M7+PeilwIraFQ6AnPZ1r8Ft0iV2Y7IdVqXXLWJ8/jKFxp+Sp46GOEFz34JAh7xkz
+ALn08g7NkNQov/SfpDZ8Z/XFc1XGL6ymlIJPlm6esq78+U7JvoAttMkVn45QlrCd
+nmTjodKMAS/pNYsYN7fGGD8q4imK74vtBWU2O/1TRRa3tp7RNYOx910nI1jU7z3m
+Unufyfj27BOv/+ufGpTePwHjKfNTw9z/p4IpfaKQ1JtlhZl/Krwz3QgjNGfx025R
+jD9/0ZI4sWVzg1USRgompHkupjcU7mDgqoc5WpYI9c8kWIxZ/dZe/NlCj0GKE2xz
+obREkpG1Fxf+i0ZIhyOdVz3Sz3PfNyLix/yuzDgxZ76xBSHqjXstdUfqjRJ4w9SH
+8d0qe6aM/ZF6xJmVRZ2k75DqH6dl8ghSWsf1glKbtR1hHTxPX3JjDOJFChZgJSJX
+YC+O7rgMh1if3L+RU9GwAJUA0yqmgogms7jHwYY2aQp42zkkeKE7txGwVvx6CTAj
+DMDXERI3NgfoZbrrHFfwubLgzNt3QTzTzwfozqRHKkgOq1V52Vix+VU8Qjed8Y0H
+K98QkmxpTHkfboh0Y+Ap6EPwhMsZQ7LHefaIO9hcz6oufWERBF9Datpj58ydkv2/
+p2SXaD12ZsiFrqTudybukTRH+zIyjraUzWnmJWbmqKCMj5eImxXn3cRAjND/apun
+daUa+nA0VENUgBu1Z7Bv6pwR2bI2hDhBV/2vxgtz6E15eWaoAJwxuevOa3QQw8o0
+OcNRayUd/Pqp5pwfKXEpNMPzBKazHDZuWX1Lp5oeCy2sQs2+/lhP1F0V0xWLngWM
+wNM6rziQ4Id554Whu+wSR7rDU3chFtyCbB1rWDbFaKapHSQVUFAN2D4phNVwqvFS
+ecMH9qpLjAKSnJs07iEcUWzkgKBKQYVCUZNCNRKRndhIf4sQvlIBFo9tvpt08e/+
+cgk3/0wErkSMiH8mRiNFoZkIOVk6Gp8+qNmcTXCVxr2CXOxw+oANZyez/et+2RQx
+c/9ojJvyySGrfr0W8SWa5afaMV1F9J1T68r/ygosP1isgaMf5Hn+YupfB9IktAoF
+Ttm1JIwmzBpcLNVpC+1jObW4yyhgx55QyNhd2m0pNlpM6hFA/kV6FhR0AIqtbAYj
+SZhryiMukvc46+QZ+WVNvTmTBkIpk8PKNGbtxzB9PV1nNqH84BnpxhPiS0e/2322
+AVWJ/FOH8DpSRebYS24uNV3DelCBG89tpEPHM2YlTxP3qrfAIqQqjrz/HvRkvU3t
+zrxo/Du/ivY5GTReyjxKWu9Nbw6sqRnNWtAlLr5XILM4VKes2MAX1ipfQQsAIRa8
+U1ltvofANlgHojqp/V0PlD+I7cFCwB7Yquf3X6jvkhSTMY34Lq/8XYei7/6j/oUP
+fVcQ9a/JbDfnu/yV93fHU+YbPKjyyBNYk4qMg7lGNQXBtoIHKoFxHhNNHqyKbuvv
+zzjcdgvufqsC5LAr5OYVW310OhHIqDQnMzbs8vq0u1owkIlG5UhC72g+qQCcGYQz
+rKvZYqKCIdOu5l8qTireZLaoxB4iwIui5NvhfH45W74LYEkZgYKZuyiHT+9PDtrD
+IbH7Do9owS/ucmmVnaDKf2ww894Tan4ud88v/rUC18AM0D0a2Uut7hfV4NTlMsW1
+ei0MnicbLgdquHoiCWDysV5/kN2PIUaaUL7fD/RcMt0IeG4fHO8MhwqKECtB2EDw
+BxwRKkOT+OCzith+QeKz+Yx9ikHMCcWSmmoYQJoIPSFB0xJ/7vDr9gT3H3qJeMsM
+W6wnHCVLhBh+Si+/HPbS8hInED0VV2sVAerp5jsGRf14yQ7mVLk5R2h+3J8tyQgk
+viIz8s9HYRxgXIKUkz6QRqEmO44moZbAShKsLeVG4OTyUZbiCjQ2Y40TT6OlMYit
+I3vgOiDwn+++WQrkYb1Vm5Xstehs/NBA1jE2bcE/+HcIcmrvQqoxYG58HCfYTV5F
+Ao8xDEVFbjtScH/hY3NEXU4iQwSxjrrxLlq36ecWiTDeiIcYbWctafj7W2stVFtu
++WBOCuaAuwhBGdcdseqJg95HaMT6Sqyx+tlwlwgHD2hKhntzdV5HmUqf1qgBTcLB
+2VnMkv8K2DYpN5x7x4kO8HH8Gn+3VQaY2y4LnJ2tK3f5Rs4gZkNARBZ6xKQD263D
+Zv8nTRVPUDknRqqQMMQz434Q4EFbClXXPAjjegGwuNdSpA+gwGGjTf1dKTFN799U
+gUJOjiimN3uGBHIvkxu6vDeORjU/5EheYK4OegjO9dNzzouFqoPekzyLRURBaHGY
+Ir1EsZSXZww1/IRSeUcp7QvSNqSuqQ1r2K/TQqKBbmOh8YsiAPWNVfIK9DKpPPbY
+/iFq8iNiz+xj4fIHd2mpWTWlEvhhcN8fKDSU4Nu6mB4eQWzmFlqr/INa2wT0q9ih
+J3WqfmEyjCIdFypZoDT1lD4xtJ575RUU2XrGxsQADZ6I5yHtvu6ZzPg9UtZCySoz
+5OEClMr2PCG6PTaVNazVkqauIlVQko/9wFYzkeOJlRLdZ0P06AIGc1iMDgkqcJTj
+aIPRKPps94w0j4xEozNJL75RQOJQoz3Jw24aGJzN/nmIy1DGBVla2RcnrdFPOLVA
+NaW0iCmTGpmms+1whs2eAZ1CMtFggs6Kc2d2qN2q94Tly8OsraIfdO3HSepNr4n0
+udlAwAB/NzQVdTEzXR/cg6PvanNLsLizt7zJfIRnbuY7JHpdGBgT2+HkTdS/eJ4N
+UrfGpTpBe98+bypFNLt7Lqa3gCh1UaiJfQrDsjk2h3pmgSF0qqtz2g84Ar1pL4cc
+WWCc9UjhPH4NTAQzRgBbGeHDL2dMBruK5g7hgjWIYqXwfjPlYs70IPnHDL3YAR4Q
+tn7AwCQ2AOlSg/QpfSj9fYczsQMna7Fb/6iNRxRS+toJMVuuNJyP/7hkvDLjoZrE
+ERV+pKHiWASYGvs1MwM+C3OOHHNphalg3akZ5rgWlAVM20Q5bUcORUtOq5/OeKYQ
+a6i7m8xR3LZyB+T8xQcboZ1Mzu4RXCve/ER15f41i8pKdv6N7Dd3OE9J3pErVR69
+sXgpYYqbl9pM5EVgqbD5zQW91Y+qsTrB3ggQwU+u2wCFIUw/kd6CSPGANWv2KuL3
+JqAn7wL7wA2UMU+MJR+LgQa5LwbYXu6nMRc0tj5cJw7dRYxllwTrAiqshW0JHWyd
+Oit3sldy7++wr/u1L5Wns6Hsm+0DaXPi5O5zK3e2EzxvOVWkdsLapohZpVcoBqoE
+Kl9WAmkZVNcGytJD4ocjCg0qK2FmdV4RDWY40x9ZhlBvMkCie3Z6ehqmNHpOWk/E
+uoPNiNVuTpzPn18WAXBvsUqXQlPH0ZY2wUjeTI+02qooPwfbhw7UMS1JmHIKnmIp
+gLw0AIlJYn2Xq9EGu+o8UBywej1XfFDyodV1fDCuQRulNswFodmObE8Vg9gKIJu0
+9SGHWpiXcHElFsQQXBKChrLrTZbOCoHBgTx22lBV4JASLPPd2bAK4SGxmjS+LGfM
+QK0ibaNUVs6LzPgrugB+NcALDG3AqvnFsmocsZpnyC+PpfFrTFI7XVgIwREU+18S
+SckDGOFr3Q7MvQ63bEdHGVbZNoZZ+NHvgbn3gOip1fyF3RMriU5X5zXV3UZmvpSy
+DeqnSnAxE69sq4IYBg==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/es.po
@@ -34168,202 +34179,215 @@ TvnykrDevkm/g3xSd5e7mQm3aMXRWYsDezbB1QVVNxnV6rCkhRHnzqGn1z4P
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/fr.po
-Size: 42411
+Size: 45608
 
-G6qlALwMcIdLOCeWlGpsXibDtrSDHx7XRWnKstijZgj+e+dXInpPgibHVgfvn6Cg
-b438urt2bcujKu0mnEaIhef/vQHr1zha9E5Q/y6YZEuRkTa/TH+ezmZGtGJIMxBT
-Sjnr71t9/LNaC6/lIfdrPDmcSx+jbohyfL2WQ580N5LdOA+o3qtVuVk1P19Em+V7
-yhcFPcReydLqMuXdPrl7RpGXUyMW+PT35vMQdFOd/NIAqw3QUXSk599m5axTODZi
-G3MBom5LdUXQcPw/xjmmahbv31/2mS6hKXL4Q0MSMGS7sZNkfsGtM/1eq4+g1cct
-2LOiJc0Ekub7f66qe+tN66mlFQ9+kBaQxmuEyA6dOoiBMHLmJFugINzHKKu+dDrv
-ZO8uqUJFVERAM7dP/FkETt9f3BTvvn7TNpu68CZuepfqt2e7hL300oa/h+3bl6lX
-xeXq8YmSL/aOdyndPpZv1038X5e+kee/7yfuhlKvN/2kmU9vhcPbxD3vQM898Jsf
-r/3foro9O2MDef7LMZQ+e7jIAH525u/I82ZOb+ToG1betHBjT9/vy+SvhVsWUNrr
-yfzrrt+C1fNsZG/q14RNW26kh+591xTl62H26Ln8HoK6qfPebmG0xNpxWu5zj6QG
-G5arOqv+wv3FNw/fmdv8H8PI/vN1pEbWfNrp3d6Tl9smgug89Me67/49YTaFDGuf
-ZT900yzErboVyz/qbbtL3EfdlsAt//NRT262f4esr9poy+9Gu+N6fOT5n5/y4+Ta
-3z/Heg2HF1+s+6+XXY1W8RpUF8PRchUF/IO2v7DRky+mLk8oKfXQTJI+C+ivKWzc
-L4IStdjfe7992NL/dWfvZ5UcS8d9l87mtbZjP/KSpbZPz9kNUZFPHfgpeyFtHdTo
-05vzc/krtOX7I+EPpeQx3mjH1zv2FNki1Qzq95s60jWtA5MAuRDs+isW3rULN+2k
-3CP3hXd9BDzP0acvSrD6ayo4o04hYktd6rtekl1AtgisDVZYJqL/OZfoYgwSo5aR
-VFMdG0woyI0Nj0vfy772GMt0SsbNMQpRv/eVGXx2w+Ay/tLEFHZjZtKwanxFLCvK
-6F9i0o313WGfUgkdGn8R2OZC7ofm0JoHupbKXZ/YwP76nonSzF7aVJigIP3u+S6O
-hmL7G6Da6dAdb5sExcBapiZEB7NknnptKj0x41UwbuzzRMYf6OhQQ8yuyUzDwgzO
-LkQnAEkOwcRgGD0Pb+KzG+RQA42/IOUDOIZh509HBEIVkVUfAAVEj1eidv0H/afy
-GX/L0IwQdJSm/UZwSIcqjLIDtpfqc1TBLNsPWldd+VTBlvYcCYlSYap+XO46MQkA
-CPrZe6DGyDowV7Hr4PJ/YICTiIF5TjLWgkj8eZkUHThxhhas2HG2anv2mKh++Mnc
-HwKDYgzsqPL/XWHIu9jzcoye+Vrb6P6xCwq4QipCwmva5aL7yzUGzO/tcyJJ4fw+
-kIlkI4t9zY6r+W6qufYC/fWP0SYGlzEnbIxEdl03prC9lSVanFoIH+u+J907/Tox
-PEn51amgLnZkZDYkKrGyA8Xp/xEik0k5mblaCU4MBNXhXQJiIBjY4U3MCX5nY1ka
-wq8StIWnEax1sxrp33XdBX2o8RyRqQnMSpIZw4wMfeP/0ztjINcLREpooIq4UUBH
-dvLLVHT9J4jIz2u65ctvGSA/c6FSu//XVqIATHkHedwPWfMeNYVQ/ZoJGP+6p3zm
-VG2x3nFypMnSLDONqQHypDRJU/AKc/1rKOQ29QEqUODcBFD3p2vaAYyau2ahZWDj
-pbV20CXpfI3dKmCU1ZR6fuHxE/E4rgvCBr9SmIh2+E5N+Esb0TTz9mA8RTKeft4I
-TF7Stmby1cs6HqJgwK5BHbohfC7e98Aox29xKxANjbyzWoLrcEjLGrpynBk3rFQp
-Jgs1cANYzaKJyVu2nY0S+M+dXOreFDTcpkjZ2y6TxfzLXRuY9ohhCnO3VBXsbwKT
-Em9m6pRBubxNR7qDddiZBzCzTyj9Qgp5fvn7DVF1gApPGJrCEFnclmXkWvlzua92
-Nlh/WNVlYaIzqs8/4aCo69JauXxxuZFfAEtLpTF0m3kYGf9Tufgd3ebHvcsvu3tF
-C+3JFoQgtA+4n5Q/UPSvHLIxAarfcJxZan7gWBoojROglMKNPFhT1arrllZWkbjX
-usch+5XqXKY47hgaE+ZUaqlR8xG7JKjNFlR3w7IGzsEPSvhI7QAEeMdfCuioODyl
-RvKqsYTBNk/W6s+M0f8XaxbunFO5nmepvSYhuur8RfCDMKnkNyY6BAtHi5BoLBZQ
-m+O6AlSNOUpcwJMET30EuFv5izwF1kCAhTQb/htISMiXPK43GuckgjssGwF8vAtt
-Y3lJDTqadW7RV9Vl/xJYHaoggRnluUGJk2IU5/ngDvLtdv0LBFA/Oe8jmV2pNJug
-W+1sFoVjanWvEKgm9Gzv1on2WjNkuIayjw847LpEiTpwsM1nSuQI8JOTNPgmpvET
-N7Me3lZVKBou4a+wuTVRCKWdRaI20aIwdPMCmA8Aits8WoedvTcW5b+x6E+xSR36
-utNsK+0OauO5KRIv9cJ1/PtyYCv/dvGJs0MEHnpqLmCJQ/sGtI1SF2auINPYzS6j
-ehVfoSpGyYF3Z+JAoe85v6k6xXZDb8AnPXUihDySthZ2kZsqi9Gm4qCQ9JkXG+Z7
-KqEECVKLcXQW5ajw3zAsAp3eRMfWSvEC30N8PCgFeLVMJj8Z7zrmo9RvfL5acFRN
-7d+PuTXJRAQekoZDBc/qHb4wV39LweCjIyHJtpzdMSu+ERgh/W7CE4mpmYJjgPUT
-jVCUlA0qoS2nbFuulphn5wNHOEowhFmVh8dV7iocyRMNkUfnWJglN9RgJoB9M1X0
-MO6hed0VhaMnS0FHgFHU0Wa6FNAsFh2RvcbpxwtUP2Ol5Skv2w9kQjKIhrcAP1hn
-jnREJxHVfdQXUb2/a+ePfku4dS2x92uDbsvK+OWRO2ZxkcvoQAe6hZynkj2w4dtu
-P5BKP/HCOLnxyXfdgkIY/zR2HKEyLXucTnCE6O1BXMOnHUEJ9QBoFFR3Jldllzgc
-HMPeBzj7QL3J+f3ZL14rju3na9RQjpvPELLgd1Dvt4ABZqQQWFnz95WtD4qEW/k3
-EHW/ruxnqlySkYWTO4I0mK/2W6tD0b8qZYCs7P0EvMiHSnb1UP2XtBI2astzEvew
-EFXKXvJXeKwJNuGifESAZiu6lU/Ff7opuytH+Bm5Ha9d4Jb/5TNEdt+AkFfx/HAu
-TmF0hiIvdF1YEdXEGYeAXn+I7Wt51ipjVw6F/EMYTQ/1+wFXrr1bZIfKDNRMx6XO
-OERj3H5s4smKO+6wMlXCpTtr/VPUGrEFsNopQUlYDv1iu4jBJQmm0nofjP0j4J5B
-m32MqbCWDFnxM29rZSD4A53+GMqJOKaI9Zm06OEI34y5CUHMX3nPHSRNBLhsViKW
-Oy/DmQewhBv0XhMEsX158Jqm3EHu5imLS0uQVoNwsuRZvuvbPRar+m6IjfwnuRhJ
-ilWuKFdI4+1KSpCVNwAlzCG69qbrKDrxLKhcTGFYuDB0FMurkG/5vfn2YEqlVKN8
-aSawGHCF62D5sK5Z+GX5mG3hZ9vmsI9fEWFLktLyimVOJ3PlWKGLJVaf5cTwwrUq
-KzlAZZolPP8Pxzr/6efoT+44hnFAJyM+pSnobhCAx3EDxjt4583OawxGTy0HQ11/
-S/+aJoE8DqECiF55qbyke/ctTjMSI3LOuE0xQK9p2y4zlEEDxFituysClUYP3H6B
-yp8/UKNDf6KavOmgR/9FENs/OoyVHDQs01izi0uRCUrGbSRAGkCZISddjXEA15of
-MJMeGexjNDeqqpaXgcQ06CEybpdbePng3gi9AyEi83EEVUU7jo7zoqWqICm48Tsa
-6dTG4zHy128wX6DnzuV3YqPG4HEhDCk7iKSZpmZSGNeh8HecvlS1VcwFLx5187Id
-yz0ietg8eWQsFeWAPsTzUkvnY6B6V08AmHcMa+kcjJoLiWkkjrZwv/zlzt61ykWc
-5uKJnU8n04U54d6HWY7VuQq9NQtOF3ewzX0g6Hpaah+lcitPTpYMDPwtxzjGqaeG
-mOC82ooKqp8HkSLajSMcVYjkIM3GV17pcGA3L8Uumr1yBVW0kWnFqWPMciRI/Tvq
-GVcUuIL1gA7pGrHiX5+pQnLHFPoyvo3oe6djuCQpy0nDPk2mEBxGIkOjadJ6CpJn
-G5pCua2czRxBRCmQ6498H2mI89MsH+740TKs0P8u/C7EaQukctWQCyhVj6vzhaXE
-RqwM0fmup0h2R8BMeCArr+jHBC3dgRL4hP+4fsfqGJz4g/6lAW6VGJSkw8umuI5H
-6+MH+cJ35vkXKnhPpY4hbeE2Dbmuz1Vd7O85f612lXInlsTRuGiGXktaG97+qfiv
-AliPIwson84ujV9Xyq1S/izn/LMFVGvqVjIcQf+f5HScow+qQMH3qP2qqELu3PqO
-OJgnQJWYb5/QfyP14NVsZerBuptl2ci7DEegSC6Htxlpw6YTZ4TSqzxe39HZ6wGE
-JxnJMoaC7Scu/bbTbjYfvVXAtrRmrolYXcGjC1kUJjhgdbBCADElq+sMgH0SSa9A
-uqUyBFCT/VxryKOtDphGEvPV/xuTiKjX5CEUFY0539Hb62N9I801U15ONIF9Z+IN
-rkPZ4MVBvWKKxZ2GhdfOtGP1fDRV/MDRR/uW/thAwmqI2HZWs7hyEqx1UEviuU2V
-4G8rkw1tB6sdylAer2aKRQrYNMhWT1qplOsooXySdoeUM/0jiMKIfLvtNQc36ENr
-DaRPLx86kX7k7CyZDcjiCrmzcR8HzNbqmH87nJad/L17XzI6q85sJtciZEREIQ+T
-TEOlB4oszm4aXwIRvRiKIKTzwkMPRYMq9Lw3bX8+JxYYbeQRSToBTKtgpJHbLdKJ
-cV865d/uWxp3EMwmXJYhD4u1J7LtNyOyDZSvGy+nZx1Jz9WqurhoqSsOn7olekSP
-s/S6lVNyi/7PkNVVoqFlittoC7jkuYa8xuYDec+b9oz/69k/r2Byx0owxusuWVTf
-WC7nzEFUwM5tYi2MsWbfCmCqS4nvjJa5TO0KTqja8I9RBKk0TTx49NZxCvYWPh4K
-IB3o0OqdNvxyAg+1tYWi/D6SDRGu4FrxGbgobpV6YRJ4UIsMdNi7OjlVBISsCMeO
-GaCyZr4VpCafRnPqtPX3ckObnqwA4jyj4WfmNUxygZvNJaKjaY1faoK2TvjaRuXm
-C+lweHaThZ6eXGp3Hbefi2bVxtR3R4BQdl5asG07da0BjcI0CfviSFqwRwbf/Ric
-FhDPGDqySONBzEHvh+ln+Vjh7jL6jvdxPJNNQEPd5vLdfVTWssF2rYH+d+64HB58
-X66JtsBOWL1xCsWrYd55R7bSOSBnTThdmI7gqZdOM93U642RzX27zNok9q8h6D5y
-xiXhlB+WK6bfVQmcObVGPx+Uyi0wawp7Q97zZKUolJZVR7JVYSklBbUUbPSOAHN3
-J7F28qibG7MIS/lSuKZSDeDd+93Pw90/NZAUWmREESlDmO/xrY4WHaI/Qs3r+jhC
-koB6QNlJIBl/326KY62MKheMr21LXdK0LLO52UcXSS1Y46Y1UI+vBG7yZ8PuGVQo
-Dphzl3lamTgSy3rhOkvSwaRVGuYVEgSTLUvVqiIW6ftRnTtjZDH/MDRMqbdxjftg
-sWlz8xENKsju3zZtrhXDJg0htffaQ1xQyTSerVziYVRoObXm7iRCfP0JpdCwmgUl
-m3b2qyNpWnkyDRz4tqFafKVV45GIAp2jzACNOXLw8oHrhh0W1rAoDZKXS+L/asX6
-n0LmoYpcLIVGjO4tnI879+CsTKoUGi1XJCRnK4bCKxSJoZLYsn/GmUKncCMNa+M5
-CH76zk6P1GyNFn4vdZ140yewk0Xo3bKFQJKDh0f5V7ODlc2joLud24bX7K8sP9s9
-gg90Yh2DTJsGQSqHVpRMe6enjTLnBGu/HDVBZPu0OFZ5++h14tLw2uIrjtN8fXCG
-qDsx4wTz1nCQZCZeznMmr1839A/XDz3JVli0VRQkZXYxJcRBiT9xGq0gtyQFoGmz
-3ve96u87lAd1yJ1DsdxQeQiGAB8UXDod7GVgOhgvnej9WRCTj6AbJ56vRbc5rVI6
-WQeXZuhJElTG5w+IVoZ+P6LYTDFpW1esDfe11b8oJaFppa/8TGgtsLfI27Q0FCx6
-G2Y/d2J87Q1eR0N9kAfG10nlz/1pHMLeYGFoHrgbafSBHzRxpICw+Xvw8p6o00Ss
-A8Ee+qKdjkWBNABAFxt5yaAPDR3KNKZWnLDZ3HH10QcR5kdR/zRVduWCFKOsBCzT
-VOQv0V/qHPyMbeGzf8iOj8Qm6LViQ+qGz/pAkoE8hOL3b8Rek3OJNoZHj+LUCHwH
-sqiki2BdgxXRreLH4sEUKLUixIxiFlhEPu2sd2jIV86rYika1C0E77PN86xuhx0b
-BqfN7NHJGWoqoiFto80Uh6WhL2Ux+Z0+iNLUKJtEiyTQM9aqNGVoVZPK1eHVab91
-rf935EoZ4Qzze8O0fk+opu2nOo6D/eyxOtFCSODpGnxVmZ/+O/2lv2mjur7AQ2pL
-7klvGfTKdBWCCkd500j70NQjKIDHq32d4U/Nvr/peyr70pYF3KMQTMYADimNhFhm
-ujyk/eILTj9495ECYK+dolnOa4LdWDf2ENkTLYf+hga3QwV+PdPvLe5YcxaAuEwe
-5VXmz39jmMZhEj6L6qncAa5OY+QWqOeHA3jDmudTOEap9itfXy98d9/qtFW4+p/G
-v1o2mG5Sw92D+JppXsOPyQWdFgtgx3Q33vX684o/ddpImgmfZGwsDNG8dx+56KkC
-DiJCmB1zPoF0BM99hYm9yCZVXeVdQ5DOlNgbPL2qXOBL2Gr7sTYUCHnfU8DR3x8p
-CjYOUvzyabBmVtDmbuyYUB/N+OAhQMUpClRcKRqihiy/REsLwMnZ4R5mhGc7SyTY
-WQhd7MY2AjpyuD77Hldoznc3opMcI2rKw2dP1J1w0bHnV09tf2I+ednUUZLv8L7l
-JBqH5lXvdT4oG9mRtUxv0vbybkY6f3nLNOlYOb3h5T0RNOX38w3+Mh2CCIqrf2cf
-wUq0x9TzY7SUpTcgpblPfhbrx92H9LLhyzbH1ERlDBO5Z5mIHjENexo/Ats4/DS0
-yIZt87DezbaNkif5EcX3RaXDqfC5mi0CUG5IPWs6fKZ8n/kcemPTwwcvTQMrYwQV
-T7w1BermZbsXx3IIxSU89Ad8t+o0r8xdt0HhmvnGUC3DTl5N8C/6a8xEOOxtD1jr
-9VuMvmQfD9Bmw3KIndYBob/3VkMY7PhFVGJipYM7vhm+RfqBJUA2hyqH4vgvsCLO
-tJATXcjoyVUWb2WgHYW0/emIsVLS5gahdO76ALoyhaca/Ga3yGdTOkiQRPsNwqvD
-+6mxLOJnm4T1jr15S4tf7MbhC+UAf/VWj3JPPsqBnffmt0WGDpw1TZLZSM94y9rb
-BsK4jq6LnsWaVbZFjjBj7jds0BGcYv/ftVUMSRi1ss/eB9iXlBOMpzoa8Kuzaat8
-5yb/7NOjNyPLsT4NGehrqWxcgKkdIc3Xb/Wd3s6rifC++v4aXqUhDrv5x8u5luPh
-9zPvuwp33XrAmPvcfvsZhUdvoPrb3p8tvG3UvYOHylR+vvZpp15Jsl/4bKxx8PSX
-jRsNhmYre6UhRKH2eIIOYZFI9vwlzKZTGY4GmboUmsV4284/59OZu253FlH18mEc
-uzl708gAOPw20sqkC3coMInxIGjPjr4ZUCuOQhC8QihxGyB/V2v9I9qYT9XDBUxc
-2egqOAmLj4GP4DFR/ZI8mOy98gm9ZTSP5LOFURTwgzZFBUicy/hXSu+JsYUlvc8Y
-pc9EGRxBdRoK2B9POQBwhB6zLg+OzbHKqvTjXsXA80XVfFwoCggoH4+C07Pvs2vD
-63mY6i06cTvGXEnOwxYOpWhhdHUiAsnPPcghGatcsTe9WVlxiWZ50KaXhIOj9KuV
-x4A5RMvKWOIAziaZlmSKk1zn6A2t5VHiSy0Gwik9RIktv69PdNlTf4wJwqKQE6XM
-/S4OjYqknm/brQe+s8k2BnSkseNaQeZNIqLIzlcLI/XQ3mVEQZ3cM2gPJg18ai8g
-fgZWjuzKBj2DYKMZWrp/vBbR+VpUJvYTSpSVuAGNE32FYziq8eomHmn9yMYfSj4+
-rv5XM9T8TGImV1IhNnvK9Uo45Dz9Wi9JvvOYEGSpdS3+SU23ch4sYEuzzhpXg5QV
-+Ue1PMiGo+pY+4VdoUz/jeDqGPM62a1tgwWhiwpe54Zf0qpWjEE6vVQXOB4h87Di
-xSu/W7xULXds0s6cz5QpMtbglWbwhzRbOZ/oubMV5uWEjYO1xymjo5wEtbUohq0R
-dTPB/jBqUnOIhCVE56N+10K6nhVJwPmKKe19htq2hdyRTtAo+PwDGzLzIkd8eqFJ
-L6Ry31eAafNNxQhNLGDLQwpTU/neY5XG1lQ9bDeCM5GtrI77wBjd1afx/vGfxaGU
-iHznLjWccCSdqu3Ji5dH0lAc0LjXvMdzs3E2Pg/rnMN0hfGC733AzIM6G1k6xyW3
-NDxXi+oNc5c8qDoXz9CWGB4tKGAsmg8dQCl1DTEi243yza2rB32Z7m57gvk8IDbY
-8ovGbf5fKQ2kmN2Xf4qBs5lrnqCLqaTFm7HdCXNtviBD9Yd1HuV2/vqKlhRElDhE
-Mj6auIwmZzpK/YxkXLTicxIzJq/ls4Ih4bh1Gl38r/uEEOERvDE1zV70kXdaT8Jy
-N3tPePqVMz+q3AS8/POH4t41R41b+NKP+JeO5WfqpzXx9z9rb0WRUrI8lEVzowxI
-twsb49YhhBuA/L7+bRCU/RHl906IKt2cCJqsI2x33Ipqju0QEqr1Uw8Ye79URcF1
-zDiy7a8nqqHCdkux+mIIRtKv7Mv8OuTANcYBeCDjrIANMH6L7Ovf3KiF+QHmwLN8
-AV0LHxSrz3U3iWvzNwvpc/ZN0tD8TcAU37JUGG6B/BD8mw893vZc/TZd4veZeFOq
-xOWetcjSC4gU9VRpGZeF8HwfWY0qJVNf0oiDKz6JR4r8rDXcaaXJag8XQh2rirWP
-3H8uCW5BEIpdqCGj7pem+6o0z2vVx/I27byCwOesfrWP8u1sLUnYWJcQ8Yh5vGlU
-nZaLQS6TCMcDwete8lpG7aNEKZiXe1VFJifGEYl2Sxz9Rt06bLXeDkQ2IZPGRDLP
-Kxo4eyipO+XzS+YSteVDMqMcYO5rntgsrLTiNDtN9OsbcgKRZpaa6LMWkVE0wBE9
-Ftc4lGDgWOE0g6/ZSvZlw+kcx7QaRFuE6HZNLhGFMmtZtQRFYGIy3UcEqbimO+vQ
-4FEUqt2ecMeqw0diUm17CuD8Ai859BaVlBTW/suH1plp5sLzoT15lnFNJeK0J7Q7
-zfNtRJvlrEyPZh7sM9tvGsya9/QwH1yDT7QVvy5qloShA4JsyKyiKqhpMRWF5LR+
-47Mh/SqbZcYpMP/eXi4J9fgg2iY7d0o55l89cxhll1W2ugXVN7FUIgY1uP4nL16b
-ou+N4UiAKjxqreEpt9abHTMB2EyQBcVh1kHM+8Ot1nglA0vCBFPLzaPp87lFilsD
-p0AYUvla3vHM23jFlIDIC2K65puS/3HeRd7iaJ1LtnOq2amkZtT+XBklEPUVx1DT
-ptDhisAj6BEdBJMEy0oiebczO6lhmoWlu5V8rpLNVq4ncMD9Cv+ufOxZZ007sjhg
-0SG05+A9CdKuloB59X8xQfvEdBxj1PPY9CJs6aw7egvxGLjkWxfB7Y8EEYJGU4Cb
-pDTOLEzhPYtbHiGZFyp3lClep0D146Vwu4OZTs7R9XkvhGddJYr1zSLOCdtJytCE
-0mxXfvxlT99hnsRjZdH5CmP87iTZW2XPeRJlylg1E9s0WXc7kWkkeHK3gkZzZmKB
-k4f2xXe0rn7S4pJDAPId8HcHO4S70SKljvli6Vwbd0LYApgYpfmQVN8wVrBU4blN
-XTwKlNthoY0PJuQ+lChQrGSp2Eu03WH2Gps4nchnEk2DJ4bmmlscSEzEkRyACsnE
-E+Cbye7I8D2IuIJTXoGVn4nUyd+iZLbP9Cp7qymPwb2HtvqCWNHHVO8TmTI9zYzp
-a7zxWq8jfb/qtSfSffwNJ273SWs90Io3KIpjOMignC8O49uVjv+6mnsR3lB4FHjJ
-+D2NRH991xR/EUSDVD3qiZnEuJTcqV6alI4Yst+to5HnJSrygNfH2kK0eCqUV5M2
-TQ1FM0eSEHDjVXweWlFlXVxPB6DCWn/Moro17OR7QCJ7OHAQWQ5+LXPXOgvVaXVJ
-8EIYiqyk0PwgVfSRM5UopLNn2QWETafiAH7inZOWDhFEbxYFPwoRApK9/KhECJB7
-+VGI0P7O5aRRhyYa2bRHcm/V7+3eQPuOfTND8TydLmYA4uLXblQNG/p3V+tv44fm
-LjuibM37VlXbXuyq1lpBVWOpWwTsAal3RwpuLLbJ5bdaqjuy3zQxtbsOjnzQ4APa
-ncFhBJF0O9E8fvYtFMPGoK/dr3Q/L5xgE5vFWgWOXsjrSeaRRDPQhOc4Kod0z60p
-60iQ8fmzBFbrHVGPWg+SnMox6yjQRsbbt5ySi+9PFurW179rk7d65QRP/xiB61gt
-VXZBTQEq6U6W8ifOXBHqiMXdETpTB8IqAGFd05W9mRPPYwu9vAZgniQqs2mYy1ZI
-f2x++QDve6UEHuANuw9N+9l52tJshNeqsNalUnqpfUvRUaF44DDx2+USnVx3QcrU
-Rp+pYKZLkX/zIUlt5N5ILg8qj/WdrktTPU9pZTf/hxYSmO+T06qCYYk0HYPA52uC
-dJjud1+fbKu1++Xts84d9WW1yYA9ckRsQ8Nl0yYwCu5MKLUUWVXelKonlx9A5tvU
-mWHkh4hrIzW4e1cYSX0772TIXYb6UULWq6Lv8fUSzjGLXzv3U/kwQzt2UOcKxqUC
-2xfcPqZ1bt0KIXPZ7nob5honlKHx6LgVgV6bkloN7av9f613d4l4PNxC8x86xWC8
-5InVwi4F6PiHh6i0seaZ26YmmUr/nSiFBDMTXyT3i8cOV/5tLpIxrpWJrI/l/sza
-b+OHSRzWFn/EF+LjvPdm9qHaxGdasbj3SyoKQ19PcOIRl98V9E/7dVywVZt1dVgU
-K2RHipfkPn2k7j5tgDNaG/UOL1zrQKiXwtBE8vm99YJrrxb9DLVELV6llQJnx2t4
-lp03ePpo0+OlR01H6lbs5F9n+E0T1efzn8yrS2u7jglq1WovUC3kN1Ju+LgrEVAK
-SrEkYeMk7Oy4LMuuMMF2sorEye37O5YQTZ7FqcZYyNTaL5f6amfkaKKKm55agoWa
-qz0fl9FiSNGiD/T/uWLsKYRpUWRoBHSKefN0zCfEKTxKjvkN6WD7yYNpwBQcqXA3
-zOuk6XcsMGy6EdO8vL/WIkG8KlFaLfdON/PEmSVw0b9U1+P9Bo9DLL3yQsfABRQw
-knXY6dW8Kf9BhiXLl55koWEYzQv3i+uZl0/Wu73+plbeH6Kjn3iytT/oSah5gZ0T
-Mv5hMxm0VSdVBlCPi7hsymo12PYkvwRrrbFYuXZbQadPxHw5puUgdyxxdQ3N6LI4
-mngdFMQMOKnleJq/biDuFPsVfJ4/uLrT7MOp98dl1PurQCBtjVcjdi072UyNTye0
-VBbt+T/EqjjXJK8Fu/NAbH9t0FyvriPfz94krVtxBpkErEyx7XkxV2hy4rlcrvoA
-DCi8uRUObwIyAmP1NnNeFNFeAENAg1ddQ5rne19qihmgIcfKiFvDOiyMfc8T/xhL
-jKvLFaxhmHnJHdtK9yOT8Z1JTOspOUk9rBNAF573rY+SlvWM5SSRnda6vM6wd3Qy
-4BknYs0Lf/vM+unwHr4+A395FfqfSR88scJn3pfKxSOP9lMDaNZgyq83WVbOLb+L
-O37uvJjyGDTpNvosIq+gZMydV/T4wXvgDrViEgsep1nz+KRtWS0Xh+LVYGUn4r27
-j17DYMVhYk3SrNcplE/mW+iyBN3Wwy6jnXBrkJk1i7TFrX7nk8iv2IcykaYdV95d
-iSrztUxp+UsMTlqSr/wKR0v35mlv+omFS1rA7xRSjrVPEv0EC+V8Guqy0B877S2q
-6T84U+Ymwlyt4/kGxJ2NlfIirXYnAjZf7fQ47+zAHc7xc3lavluubOADZhXjK1zt
-pogRv1CWTIxXBA68RQ51Sm8bQ4lN85PQ4d+HHXgHlzWhuUjXcy8Xr3HNzmktI1Cj
-GimLBT7+2frXyvcXaa+Phtje5A27JGwlMs8vkWjLExa3M1FExutt9Vk1iMEEu1TQ
-A/C4M7V3QpnxABtdQUw5wCaX1iEHdzFKe1CC/HxtfRyj/Cy2n0NwMnLhZq1ehpZM
-z3lc52RWoTBYwu6PR9shbuCFAA==
+GyeyRFS0kgt4GeDGcBySfDhyZIpvesLyj4yJ9PPxCNHSZbGj5nJIUvmE/775mYje
+kyDvkTTB+yco6FuW3sxUrFHJXdX2LqdpxGJ989MQLXY/r+6LggLgAZa3Ho02HY9q
+5apunTycGrHj+X9vwPo1jha9E9S/CybZUmSkzczUfL3lGV4ryqUpRVdKedNY0CcH
+hGhB1Fz+32hyOJc+Rj3ggOT4ei0HeCSzcR5QzatVuaEK5xMKV/1ih8pf5nMTtGz8
+QwQsJkBbVknbTn0a3zhw/BC7mFO3pboiaBD/j3HOUzWL1/tmv0+xPiKEzCUBPsJn
+EJMkb9ucrp17Z6bQzNUU0kgUK41w0gYW90yfPqfvjq5GevLl9hkJ501GRJESUbmx
+35voZz/JMC4IeQzTvu7724iIiAhost25jYFIGDXN/CGqfGslvzfSGKFhWULwwL27
+/a/Lou/PC+VHfE+lu5rwKm56k9aXe5zCz+Supn+G7eX77MZiLGv2r5x8th4/GdPl
+u3CzXcefVr5S5s/fJ+7Qsl5s+gmz7t4LBzeJfkpA573iV78/t5+L6vWe4Rdg5k+O
+oFT8pEUC8D0jviPDq7m8k8PfUBlhO9d29st9n15bOLKBUqo78q+7/tSRehxBfs7y
+nLBpyy+NT83HLtyjCPOTzkunENJNnc12c3sk1XYTmeaKpDsDh6s6rXHBy++7m69M
+ej/MZLW7B6Aam+tprY+pn32ZDQzRusWPtT/+P2GOGhlKnwXfdPEs4FZcmuWPe/lU
+KZ6tL6vgFr547zMvltddNuZotIWP5of75fzC9uMn/TK5/friDL/fOT3vfLnDCy/N
+t+IG4mJmvjlBAZ9o++s2uuP51EXnEkoP3STFs0D8mqLGy0VQohbHe++zDbv6v+2l
+vkfLmOm46dRTdFnTlo5WyVLbh/PiDTGQT534rPiZonUwok9vTuelX0CV3xzJf0Yq
+HeMNdny+lVJsiyQZ1MPDatI1LAOTAJkQ4uOvmHvTatyMd8o9dj1z0pvA8+hjuKEC
+qz9TwQlNihCX1amx66KQBGyLoNqgwIoQ/c85RRdjkFpaG0k9tGWFBQW1sZmj1u2C
+30f6Mo2WcTQmIOq7v7OSxXQMKeNPTUxhNxYWDUXji3Oyp43+CpNuuZ3TPqQld+j8
+xWCbyrkq7UNrCIyUSr3cvoN984SJysyrtKk8IUD6XfMm9o5i+0NQJdp26G2DoRhU
+y9CF6GzOhYcum0ovzGQVjMO9D2L8QOenGp3VNJlrtzBDsgkxCUCSUzAxGRZ58y4W
+0yGnGmT8guQbcHbDrl/OGIQKYqsKAAVCT7agdvCdcVeYij82NRGCgVLPN4ZDPlR5
+5J2wv1TX4wxmXjroXbXwKba5tKdICEqZpXoar9ozCgAY+nn1QE2RZWCpZtfC8/+B
+AU4iBuExdGMjiMQfp0kxgJNmGIOVeZxrqhTX/qKPdP9a4E81MgW6HoBSW0cnOLsN
+noZkpf7H3BQk9FCSZAAsS6Px8WmJtrDrn+Ms8YrNb7wSpEaFzkxQgWM+UT9D+xv/
+M4yhThZDWsg+il9LLyZwqyUFXLMJTPWIWO5YJH25YMJ8i48BScrn+0Am4k5W+h6d
+z2wOQ/N9LOC1v/c+OWMBE2JjaDxAQ1ZRs2hXkD/I9DtsLb10hjOJD1sV1PlAInNB
+ohKTCSAm/h8hMpm2k3mql+DEQFBtPiQgJoJBnN7FhDAH26dDXvjhSNrC42LudfGU
+5ve6FvipxvfFTk0QriSZMcxJiUz+nzEYw4TYElJygxDLn5KAjmxRpwCvHuE8qmub
+Y9JyKQ+QXbkgrF/+3yhRgFR3UMdVaJbyTkMOxUslIP5lK/nSIdHqSl2xHgqQrxCI
+J1JCurgXW+q7kZB1hVfQAhauTYB0P1urCWBpXjUXXobhiWghBrmknIVrC04YeT2A
+3m/y5EV49FeDEcUWXJ3SL8L1MIbT1y0Yd5LECkZvABbPZVsSdba2jicvGJivoU5t
+lz837yswyuUhu3fz5karzsYM1/I5LWjprHFWSEcgEpO5mu/Yi9k0sXgLdrC9Bf5z
+p5SadyUNt8GWve4yWwyfH8LhbjHDFNRwWzXsh4FJiTcxdcigzD6jRZe5NdS0T8Ws
+NkALdxy83/z3OUOrA3QY2YizGTGpoDb6HBf6jqMj/lFVp4WJyRSfP2WnaOrUvWr5
+7MvSegcxL+ViaKov4PH/mVz5grD7dm3yU3KraeE8uTkhBK2AVhfqB5r+5YM7C5D6
+9cdZre4H7nMzJf8HWl9M5GGWWClZd+7OVSxuaS1+yLZQ/YIZobS7sSCydAhVw4iX
+WiitSxRXx7IEjq7nSv7I7QAEdOKvBnRpOD2loE7C5usmz9a5uLeTBiRop/sSklHD
+HE4jqaHmXFhN6vrOm12TicCO05F6UQDdJy4l3Gfw0d1n4RVj4g+hq/RFkKFFsYXJ
+ooopp1FWuble0eYYRFk3iukWotL3Bf+9dOZukzsNPs1Sq2ynlt76y/LXw6SWX4dr
+Lljol8RmHPMlxg0dKLA+bJz0sjsIPlYocMf6k1rebEppSOG4C15PQsqSaY2vvDgH
+Y+922QTg+4egrS/Pr/FStFjHaummbMLDDkplCZo+74WWdFsmcXrE1UJLulb/BAEC
+nU57N2vQHvZbaXYEF4vCOehBlwnUA3lWj75AqZYMGa1BGuMHmkHdKJGpALb5dUGO
+AN8xiQoWYep/ZMXHotuIlUKVEI0XFaAIBVfZ2fgeF1pC044p+CH6J/Gteh+wy07A
+TqM3FPMhKjkzvq4yx91oC/3jEYOlygH+fTVwKf/2wphm2wijmqk5gSWd+UFoG6fB
+8uwCLWOnxKxcipeQ0Fceh/WZOdC9cMrvqsGxbTc+s1lPPRJBbkp7hF1k5SGmaF04
+KCL9+mHDmmghFydDaoOhrqIUF/4bhhWgw7voaFHKJ/gW4f1ZKcCj0iwN3z+YT6NU
+NX66WnCUpLbfdrmHDcwFr+lcSSI9AG6wVr9wMHmfSEiyVqNvUfu4EBghV3iFM4Ka
+OQVjgPKJfGE5bYNaaMOJIp2SEkN2faAvLYdCWFVpeCxyR0iklnolvF0Hqa92rs5K
+gJn0JOjBw6ZulqnCaVugrD3ACHWcnZ8LaBorS9692unbE1SpWLvnyW/br8iEZFAd
+bwZ9UGaOBaYTRLXV4LnUsEDEeyp/c7g1Ldjb0qBtVRm7PfJQ2W8EGx/4QPeQQypJ
+gR3faruDUtqFF8XJxCc9mHaaxezHiesehGnex/4k94DeBtSL+3FNSEJaOPnb9fB+
+NHZJw8FoiU1grOWQm1zfv/kGz2xsf1fjhnza/JqwBf+u/nYP6FBGMoHldX9/3roi
+JEzhXwPqtqysMoYxJbwTtPBFCBtR6ZbqUoyvchWg2BuyB54tdjlJLVL/ilLCljby
+HAxwsuRmo4wdWbCJFvk+Gtov1nE+hX8vVN2FY/yEJZWPkuHY//INIi8UgpBHeHo6
+DoNifOYiF7TdWJHUpBm7gB48wHaz8CMqscuHQiuRRDFF6uH97lNrs8gBVeQS7MUZ
+ODuDQYTIxODJIWJmAAMJ4dzEOtIZ45PiBlgMW1BwX5w9GR8u4/UlmMSsbv8fA/ca
+3O2973TtnCFrftEvUDMQVp7tvfcw2EVmtbGc9OhuhI9ibkaQ6I8r1JaFq++qWZlY
+PvVktuYDmOHCK5YEwZGSn73mKTOcon7O4tbi5FUnnCR75id9m0fiqpEMqZG+psVs
+QgifaVwX1nibYglyjBfACX2Uc5dexjGIZ9PxfAkBCJm5o6NE5XzsV/PtYdKeCsKz
+7cugYsALvAzWkjVzH9aO1BY+t2k64mxBhD1JKMuLJxsGm6slPNo4mO+TnBhdWKpy
+OA0I0yTj2e+MdfrartFnrdm60cWWMJ/Szg4PCaDjpAnj7bzxasfpFbPsxn97l3Zt
+Gjoo+0OoAOL6z9Rewlv7Fae22LoNT1o2QKtrW00rlEEDRJ/UXReZSqcHC8xBcKnt
+OtO5315Dfr2lW/6pQ2x+HzAWWgpkgca1urgVRVmJJ00yJJdWn1J05XUCrTXtwpQR
+GaSJYoSKSsoH1664EMTGbWoLLxoygtBbMCKyHkdQFdp+cpwWI4mCEErJvztLa+08
+biV//g7zGXquWnwj5seH4F43pKTjQitN9ZTQH9ViJ+xdaIA01oKFR9lcHHvXd4iR
+N++m1FeKfEAf5Fmrp/MWOrCulwCi30035nOYPp/JTGN1HzfuFz0/2JuOpxJ3qHL7
+kMPZdG5CvFWwyjGaszBai8Hp5g6zwF8RDD0stbqtTOHJxRLHwN9yLPtCPbSLBc6S
+VtRQ7TrwNNF23cO9ypGW4ovxlUtanNnRpUyi1SvTUEUbRb04DYzZjgSlf3s9+5kL
+WsFyQA9FtXGIacUM6vmUKfRtfO0x9kHH7I6ULGEN+wyZQkgYQYa8adJ7CpGnG066
+M3u5uHKEELlADh7muien82dYa+jna509+jnzNpOmTbDK1SIukFR9XJ0bbCUxYpmE
+Tg49xbI7BmXCAln4kFJmaBkO5MAn+vsjbkZjcOYP+u8p3CgY5JTDqiZ/1E3j7Q71
+wnfk6T+wzluG2czQFm2Dy3V5zppSf0+5tlonLxhYkkZjkhlGLeEoBDcPzX8EYCOO
+JKB0Odvovy5UW6X6WajxVw+ojt5c2XCE/D/N2cJFC6pMYZXb+KZAgTx7oYR/Dgig
+ysy3SRi/USD6yL3MPSi72ZaNusvMHDTJhfQ+kRaNTsYWaL1K0/XtnX5QHfdIkcxT
+KHimzsrve2V7dlF3NrCdmzPnQoxmcBtCZuUJS/0aVCGAWJLRPAdg70CIK5BhqbgA
+SqqfpYZ8NNUZkycxLf7fkEQk4CkJIa9pzJqJ0V6FI8C01kx12Wkye2xiOdehbfBh
+aL148MaDhrnXybKjeN6ZCj9YUqZ5N6JvIDU/jdR2DrO4fOiw0UkdfNE1qgR/VVls
+6Ds4EFRceXzcXGxSwK5BtrrDTK1co4T2SUoOJWf+RxCZiDzapyUNN+gzNnKk99Za
+rvymo6tltlVxgdq5cCw7rN5ql387n4a9+j3zS9Mbi6OaaREbmq5GOc8kzilxryiy
+Mr8LPmUicTGEIJTzug/tiob4oqF3bX86J5YZbWQxSXgEmHHIlyZuu1jHp33pkj/a
+5+B3EMoGWuYRD5u1ZbKtmh3ZGtrX4YvZISPpvFKii5uWekH3qdmix7ifpdsdoyf3
+6P8MWU0lGlqWuPW+gAtrJNH6xOlM3v3ieeT/9aRPB5h8ympg7M+7ZCG+sV3OWoKp
+QJ27iHthzLoJlwKY6gyrPKHJxqZ2GSfU4f6JAkEKzhPXH71PHIL9sig3BJAJdMRZ
+94wVYEGHujRCufwxtw0RreCl4CfQorhX6tbk062abKAzfWqQU2RGqIowdqwA5XXz
+jRA1rJ41q8lVJc3a0JOcFgHx1Nda0ejVTPICN1lLxEc9rQfqgq43wFfrzMc00qF7
+9iIrPTy11Pbst5+toWhj7vuUAKHtOLUg+3YaWgMZRWkS9cWwtOCIDG6rWJoWMM8E
+6lkkfxBr0Jth8Vk2VXi4jKuUmTTuuxtoaNicn9wmZUk7bHPe2f/OE6fDg6/TNaAt
+sAtWCw65WBLmHddklA6HXDxZ2IRpGJ66uTyrWXq9Maq5b5O5hqT+1QTdJs6kaJyy
+8zLN9OtaMmdNrdbr66V2C0xJEb+i7rm3Egq5bdWwbBXMyZOGmrtNst4rEkxdjczG
+bsqONtYuLPuXKmvaq4G8eT3jw9X+qCErFM5GVlOGNN/tbe09BkV7hZojSNlBkoBa
+RBmOkmAGrrXEtVYuKpOMrW1LU9IQANzs6McUSYVGcmQNZ3w5dANyEgOBqFQMMqdO
+Nrb7MSSWqydxkJkmN63cx8soCOGwLHSrqrHI3I/63L5xiPmj0LCkFvvZr4Ptrl/P
+b2jiQggTOlVbrRyOR7NIg5O8jYsqgTCw3ZE/jQItp8banESIrztA2WlYzYKyTRvb
+3ZEcWtltCy58x6nG5RXiEyQRBcLw9IHGHEEJvf+6YKeFNSxyk+TAXOx3berwHGSy
+iFrMpUYD3aOdt2uYwcUyqVxqFBiLS86WTYVjYfEVJQ3L/hlnCp3CQRquwaM3+N5t
+4LWUX9CYfjdNnfiut2MX4Q4eGyGSBCVyC/9uVrJydy/pdgcQ8ur9neWf8RbOH4RL
+91GmuzpJKug0ymZ8pZsjZc4Oa7/sNFBki0F/qfL9vd+dCxP/uLz8ZZruD04QdWVm
+Eg/eak6SAAnyx5wBX+5Q/3S+0o1sbEP38pKkys5uCX5SglxPqxUEgJNBmu7W/bZV
++W2D/KS2uU0q8WgoP4WoAV4vpHQ4GM+iZ9ZLO7M9C2LSDJo8sWcgfcxplNLJCjg1
+U2dJUN44f0C0+7TnEdmGo0HbumBvuKmt+UVuE+opvTIkUmuAvUXapqWmZBHXmhEV
+xRzeWrz2pno9Dyw/Vjp/nk/jEvYFEqbmC08jI33g600TKaCsMK8/eUs0aSLWgWCh
+fta0K4pCAwLyJczLD8EavLIyllacDLN54moXHzDMP1nz09DZOQQpWlkJhkxTke/U
+/sLk4FO2hcX85IGPLE0o/uI8kJrpsz6QVCAvodjzG7Gg5VqiO8NPl3iqhb5BWVTS
+RbCuyYroVo3H/MlkKLUixYRiFlgl3uW0e2DIlz9Wxb1oUJcQvGKb58W6HfHaMMCD
+M3aYsdSU1Ya01Ty3ONwbKiob1sd6o5amVtmELZJA921UafKKVbkvLBM/bLMNrf+3
+l0oZGRmmtwYHkrdbTdtrccRD/NglDtZMSoCpDqho0Wv1RmT+N27U0BNg8TbkmfSc
+mrt17iwKSMYebB8aZgQZ9Diu3D5/evbNoeep7NKWZQPZwXANYJvSSkg8mM5PabPk
+Cx1dnilKgPFhRbOco8+9sC7sKWsmmk/99Q1uDxX45YxvEVfsOTNInKfsy7PMn/82
+nRE0F96K6qk8AS4O8/AGqOe7E3j91cddZYxS7Zu/Pz3zNWMr06PC1X+MkPySybST
+Gu4G+KnfPJcfA9ihFgvgxHQ93vTgceY/pW0kI9disjbmphidJZJ8aacUW1EpTI/5
+3IP0BhiRmZk9S6RY4wmsidKJJY9GT8cvDOMSttq+tQ0lQjiPijgiS5KiYO0kBQFS
+k438z9Z3YQhM/RatD24DVJy8RAW0M2rUUOUrtLQgHGA1N7AYnm4E47C2CnS+W+4j
+pD0vBz12XuFwvr7OToDg1C0Pb91T84aLELJ/OWv8VfIBz1WzJM/ubfObqJ+a1b2X
++aKsZ0PWMj2ir8t5s7T+xmWN8rF4eMevN0TUFMLs6/1t2kQRFFf/zt5CVOjsg/ze
+e87CqW7p0Sffiv3j+kN+2fBllZdUpzKG8VyTg4guDho2NF+FdgQtG7VFNmwbwvUa
+3bfW5kmItXgGXjqdAm4ruYeDyqHSo6fDW/K3GXrXKXTP3HhqGsQyRlDxxEtdpI5u
+W/FC8ylk7+GufwAlWOd5cd3rvihc8rjR1cswnHCU/HOvjZUILxvbE9Z6/XGJPn8s
+D9L6jg6KrZiA8J9HVzcMhuIRlRjf3sET38S4ReaBOUQuDr08FNt/gNhLQ8gweiej
+G0es3MhA20tp9R3ys2DS5hqptK76ALozhZtqfGYA7pMlPZogifZrpFcmzm5kWcS3
+1khLggDfavGO0zj8RT7Br97qtlzN2/LAzmv4BNwQKly3STIb6drYsvR9HWkcoItF
+z2LJKiOcFh6Y2wc2mAhOsf330iqGJIxaXEfvC+znlAONO9ub8Kuyqa3jzkv8ow2P
+ie8ygz8fb16ir6Fl4yRMxxHSfH2kkxHHWUd6f3l/D+/z4Kdd/8+LuJbH0/uv33OR
+wPB6wZjn3N7jp02PTtX7UW/7pW/a+97+v1UBCv4Wq2DCNme4oSZPf9ZebzLkF+9m
+Q4rS2v0Z2oaVRrLhT2nWnUs3G2TqkmkWY913+OSH2wB93DmIVpQPgdoztqaVAYCW
+j6SVQRduG2ASY1PQWJu2GVAjrEIQvUwqfhsge9PY+ke0Me+sGwuYpjIISh2ExbvA
+M7hLVO8kDyZ7r3RGj43hG2G2cBE5cNB6qAiZ4DLyTfndM3ZLSW8zQYliKYsjqE5D
+CdvrKVtAGSFi1vkh0bxUWZV+0ksOLCyq+nkhFpBQmo+Mj3veZdOG10KY6k46cWvG
+WgngYXMPpWhh/AYiAtlP/RAgGatcMZretKy4RP5ExPRSrF3WerHyGDCXaFkZSwDg
+4ixT8K/oTj3V3tBaHiW+dMRAOtkv/saW3tZqdKmPaowJwryQD8qZ+ZydGu2S2rO7
+2Q88tsk2BvRGa8elkkybRHgLO90tjNVLe+cRBXWnwKQtmrTwqVFA7AosdLPLW/R0
+kvVVaP72/l5E12vWPrGZUKIsBJjV3+gLvLlZ9Xc3fqb1T5J/2PPx5+o/1dOa7048
+yJVciM2egl5xp5xuvzFKkg0e46IsvW5c/qSmW7gO5rAr/8YaapCrIv1TLD9kw1GE
+of3cUCjTPxicDTkf5aAqlX7VLAqOqMTe0worxiCdXuoLDETINC3/7pXezL9XLXQ0
+aWcOM2WKjD34stAnMR9iOZ/oubMV5vmEwcXa3ZSLI78JamtRTFsX1FGC8TBKUnPw
+pCWNzi76davQtf8tIWcrpqzdV6gcWwiOtMNBwR4/sCEzh9NitxdyryKd+6aCafOR
+YqYmFrD5KeW3JvfWE5XG1tQ9rHYBJ5gtrI97/1iUGsN6/+SvechtRDa4SxsgHKxT
+1VBj8XwmdYuDNm4d3v21WfswPk3rlIN0pvGCjT4QedydjgRpsuI2LSxxUb3h0SUv
+qs76NdoSYFZ9dlKW5kMLUkpdQ4zI1tPrzeyrB22Z7q56WfJpQmywZe8a1/h/ZW8g
+xexKfs0mzmau6Qad3UoavBnbp8JSzRdoqf+IwaPMyV+laEkBozRCJOOjzmk02e/Y
+6/uScVE3+OQBR35aMCSctM6jWf7LxhAYHsMp0OnhRYWc0z8Jy83q3ePJR808RbmI
+eP7jE+NaN2+6bOFJ//ifNJe/ro+xib/92DZWFJyTxaEtDjeivb5apTFpnYL7AJDe
+1r4MHIc/ovzeCqzSxWBQVx2XdsvF7rNrVyCuXj/MgGn2C50Lfk9Yb2z7a4lqaGc7
+VlyxGJxM2p19Hq5DilxtIwCLpH8oECfov3i2tS8ma+7xAI/Ak+MC+s78Ia5+212E
+1/ovMaXf2hfJQ/0XIZN9SbZC/xFIUsi62NT9x56rf0wX/n4dL0qVOB9Ziyy9oJGi
+nioFDJpJz8bIqlUpmeeSWhxc+A9BpEj7EeJJK7lF3l5IdaKKa7tw/7kkOIKgFKtQ
+y6j5qQlfFTwKFz2X12jHmQTeFutX20W+2ltLUmksS4jGiOly00W1X46DVCVRGQ8E
+1L2AWkbHR2HJWZcbVTGTEuOIRLuh3uyDegzYGqMdiGxCnMZ4Ks/aNdBPLak7petL
+vNbG+4dURj7B1NMQaRZWWnGaQRPt/oZAIIKvr87wI0VG0UBH9FhM41CigWuFPfZx
+zeVkH7Y7wXH0UYPaFhV0s2aXGoUyaxmZQQx0pu4+PIWK0QPGgAa3oFBtdrtb7nr5
+cCw72l0An8/w7F1vVklJaW2+uD86U5s547u2ZH/2upUIaI9rc/Ior0WbjqFMl3x4
+VczjNy1mDT09zevXjNFWvITPS8LQAdFsyKyiKFrTfCoKySlS6JMhvchmeeDk8Ii4
+kUtGrXEQ3Sfbd0q65F+1sLvIzjP3ugTVR1jaIwY16H9C8booxh5MIAHq8OhoDTeZ
+vd70GJds/SALiu1skhj6za3WOGaGc8JEU8vNvfmzR4vEWw0fjjSk843Hjide+zOc
+NOJYEPM1DNX4xzhfwQO9D7+N0j9YrqT6GjxXLhJgfdHZdWhTxWGKwD3FIzoIURbi
+oSQ277X9cJJpRiHMm518qpNNdq57cMTtDv8h+TJcZk87NgGw6BSac/KWBGldS8Ic
+Z6JP0N6ZrWOM249NN9OWybqht+DnwGy+ZZHc5kgSLmrkbD7KSu2DhSk8sng8Rgjm
+hQqOMvC1D1SvVg5Xa5rhw3gzMe+l4cVQiWJ9U8E5iADePdSkpcVT+cmnLW3APOFj
+ccX5WYnxeXAyWmXXIYlyyxhZiG2aRHhPzdSTPMGtoNFc5Fhg76Ft8dzp1WsIY9pD
+ID0Bh6HvtQhXyBQO1y6fI51r8pMQtgCmgdIwmOob2gqWOzzzUOdngWrbLbSxybjg
+Q6kFipUs7fbCtrnMXsIhTmfy7kRu8MTQXI8WB1KOOAIAqDQZfwZsM9k1PX2LIsYK
+y7F+USXak/yLlKz4yK+2t/pyHqO2R32CRDnHVGckTZUePGPaGm8cVbhn7le8zETa
+t7+l42qYtDECraBBEY/uJJ1yPj+NRysN/LqSZxHWUriXeM76Pa1EP7BpCl4EtUHq
+HrVjJjEuJTjVlWlryyX79ZqN9FiiQAS8Cpshj7ErlFeRdk0NxchHkjTg2rv4NLWs
+zjq7n3ZQhaiSovDNNe2APSDMbg8AIvPJLzl3RfSoPkY7gl+4qUjMjtErtooKgal4
+KUE4vTERNp3yE3j62yQFqeEs3mQRPPkRIpL+eopEiJD59WRHaHvza69RL3UcZHkL
+8cZf9YE30Nixb2IqHgfoYoIghll3WLXb0L+r+njtPw03WRP71tCMutrmYlMV1Qd1
+jbmwCDgDUufhCjAWqzrktwoKH4ff5CrcjLgknTRgQJseHMagkK4lhm+/eSGJxBxU
+PPNK6/GM4C5xFWsVOPpFWk8yXUjkgcbt4yif0pV7qNaeJP3+s4RW422jHpcIkpzL
+CWsW6E4R2rd8BIjvTxT60vfPuclLHcvCXb946BpWS0UgqClBJd1JtvzOKRShZswP
+R2i4DoR4EyGC7sJOG8ZCbKFfLwEYkkRhNg2z7lXot853G+RtVEoYA7x+813TfnZI
+W5qN8LuirHVpL11pX0sEKhQEjoi/dS7spKYLsk9d6LMUrHyp5l9/StIbmReSy4PK
+Y3nla2WmxyGt7Pr/oZAV03Nyil8ZgvFpiQK8ryZIh+l6Zu5oo7X5ifbJ4QbcUbEC
+NsiMyIFG75txBr3kToRWz1Gsyhtydcfy/VC/DmKmkV4iLq2pwdX6hpXUt/FGBq8y
+1I8Qip4VY+fXyjhz5v9u3bny4QHtxNk6F7EcKrCVAPsYIio7DiVz2fZ2wu+6TKhC
+/eyYHYGOgkrF3fsq/1/ry1UY96ebaf5DHz4az3/kbmGXIrT7g4Oi1FyL57apQaZS
+vbFIIcM8iM+S+/m5wxjToy+pGMvKREdV83Lz8Wv/aYWHpcUe/AI/xlleM4ZqHY8h
+NnbrnVQUZnw9yAkiLp9/NobooE8lpUKXRbFDNqR4Qe5TIX33foMyoyi813getQ6E
+fslNTSSfT9hz5g6/9kMySDX4j94tnn3AYp7pq3cCjJLxUM9ESMM6D2t9h39+RvmM
+57jm7lLBeGoFLCaUfGjXiKXLYiglGZ2oSlYG8bMfp/+XdJHFwiuYLGtfMS3eep+k
+ROjGaNBKi2is5MQQYzHWDI33yR3SlYZgIs6u/Amz8Cvj6+Z2adDpNOqCS+9dDxWg
+LOoL6am6U5OWBNRzCHAZB2983w0Qi3vXWq8RuKOf8tWqzEYPSgi/W8tKqJ5c5tfx
+r2PSeLug9evubhCpT1MWLuHQTnCgoyiiqLa3rsGJr2hZLIAaIusScf8yhr2p8xt1
+UsUmmYf4pVvAKKiTt4VcQ5iPTLK0ESal5bXp1WLEfdspIY2T0gIyJMtCeUVInIGi
+nw76e24cfkv8m8Vnbf8GBpglqnpGfHnH1j2MP6tMiecswMgBzS5frfywdlJytJQC
+0WirR46TkR69FOAkErY8z5bcX9MtuSrcpTQ7KvNtnTSndXsbZwlFL41QBlVc9fVQ
+lzCF7Zkl7qhiR/XqZOITttvbYGH3j1sCderSu/aV+mfPbrFO88YsuQmREdsvwohw
+T72RLw+hoxLmb/IiSAjNsyhvNYxajrKHW2cMfEtLy6kAwcbf3vLNO7xYAFeC+knC
+5ByFKwOsynKIi3W+ZSm3FwCaboz5l/42Hg/eQWxmFVU88QNR2VXxMXKIiOoxVuFh
+x4uv9/hNpkdxD/Yi021c9zlg+IztyjOXeEMNGfKVFbOAhGSEBfANpXbD21WJgFJg
+injoI9zYuf20MaYpF439l+m+VAUwy85muGPjvzvZub7jYI58lx15aEkW9vzm+jkP
+zi7d2wqYhPJcuasUeiJmSCmux7oYZocd8ROEygR8NmzYtFi/6p0U6VrhgYKDGas6
+opvsBUuaY4ttLDLEIVHTqTqsde06OIq/kf4fPT7Z7/Q4xdxvjrISUEFBtliGjV7F
+i/IfZYiXOvNDopxENq+7np2bY7fsXn7/xmZeb2pHT3+wu9nxQ1rzHLtGGw/OZHjd
+1UlVAdSjkuCVqlr0r6jLGIwCvA9ovzQXodgczkdbTnLthVcLe4C+5nuX+AvX5gac
+rWV3mu92Ipaf3aLzePqQGn5UtqfeHs/hxA4MhhR4X4VYtWwUV6rfw+QDy+SPNH/O
+imtO8rxjt24FW81NMZczmvj72EPSvOufsEwFYfE2PUWSiFbIlheOYujkwLHM7HD4
+LmQ8rIAQ+sfZIpoLYkho8KIBbFrumKSnoENhR+1TLnT0sHDp286ZJlg4Li7C58Q0
+0yNWhs+pytl4bBK0JcpOsBhoFdG5p2OIreNplzQ7bYhzwLDDHLLpnsRcc9Shv16v
+LU4g8tfwnU+B+dfqgX1t/fo9qVrc8fDoLUoPDabyPMPLfAHqOzn0G+dNTuujJlMw
+W2rIQTVNuIsPLRHhFbhTLU58yaPnXUt0tirr5fxUzB4s64PEse2bIHkw46UzZ2na
+c1QNe/MldYl/vfFgFSH2wTrIrJp52hKzNE8BJjW/bLca1DRjVcP1LayyqJNbWvrL
+RyfEA8/v9sC2/rbXeyRqYiYsqs5H9fuIG+J2TqWLNfU11x6j7c1r6FIisc91gvv+
+yfwBxHTQT3URAiDzkE13O11fd7EuF7p9hOlS5+vIlUx8wOxibB389RQc8S/ysol8
+eejA+TnFAwnq+mQlPe2XGP8Yj+AwRgLSNAtdh+OQHWBnHOaEKCWNd+UpX+KTnwbf
+mb+9KADYxeDbmhyk5KSttCjSUbO97UcsbkTnlnCEUHx0DWJDyyhbWicTN6bjnbRM
+f4K1BJXlJ1hntEXyYsYntwFLkr+rhUw0zuv8+C78FWUWs2p1rIjisX23Lv+mrjSS
+Ehqqa9l3aZEC
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/ja.po
@@ -34698,6 +34722,23 @@ G38AgKyKd5C8E0FVRfDm85yOtnKPzqVFYmSSpkJTWoKw9WWf3FuFuUE5DtivB3n9
 GuFqNdEZzm0MBLdbR9RpVWdyH4Sf/0TnghjFaYIec6OWrNlQUCTqDgA=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/bitwarden_emergency_invite.mjml
+Size: 512
+
+G/8BYETdln1JZ/03FDGYskkQQQzCmP/e6knt9nj+jL8JEk2JEmNXLlw/CLf28qk+
+ZW7qmYWMQqiSDzGKggmStb1CNHriWuuWEwrlUDKjWyGfY4dF9l88bp5IuMPTxaye
++/4hsIURreG123vO0nbchA+gFIyuFK88jr2QMndz0vyTAeFZBtRwwDq0ipc6P1XS
+yAT4I4H30mpOAtYTAukzNskjwTiHJ2QbCyk6pmQ=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/bitwarden_emergency_invite.text
+Size: 145
+
+G5AAwJwF1Y3HVU2hE9aNvYXhh3ovrOlqKfS5ja0b8j2r0/qRUN4qLU8nB+z/t1MW
+hZG0WGPObex88WgQjYpGEweWnp1Wrfa0xisMZV/Ix0ay1G4ZQgQNMHsHX/2vH3tx
+Ze8DZdQH
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/confirm_flagship.mjml
 Size: 431
 
@@ -34895,6 +34936,22 @@ G2cAoCyOdxI085D0GnmYNHgt9xT6UiTJKfvk3sqFrkHK3O44xgH7tcg1yEKLDuZ7
 jJ2PkilC9COzX4Mf63HhcBbRuUFhALAnAg==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/notifications_digest.mjml
+Size: 362
+
+G2kBYBwHdixjeitoOntX7rW9vG3b88SvY0UraJ1MbDjKJ2XfnoLlLeT+t//fbyhI
+slB01Wa6CVlT6TZB8PWLMJWYo6Ojp8QhJhD6WmofdjU05lrQCTs5fkToKyCRbhx1
+D4ebvvzxBgAGRfXpsicYFvpnXtX0MJJYqHKWVRu+fLKD5ZLSqYV1rvpGQVP+Wv0A
+9HpprHogOgA=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/notifications_digest.text
+Size: 168
+
+G6cAAER109eoBs+MIrky38kM4sw11cn5Qe0FibYl/DxPJOLcxq5brDxCq9uRmuNo
+SC5X7bz9tAaebdqYWAIjz/Nndp09bEh7a0a3+RSlMBdbqo8RE+tgSE3K89rg
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/notifications_diskquota.mjml
 Size: 839
 
@@ -34912,6 +34969,21 @@ G7IAgJwFdiybTpaW8iurpenJ+nNWimzzjQl8UiQpqulAXETW0JNOTg/K35O2hE5S
 8wgCg9zGloJjVhGthh1JDEPZv+UvHvJKxf0DebqI+vZYZOkDsGU04WSD1TDeWqRC
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/notifications_emergency_access.mjml
+Size: 331
+
+G0oBAETdlr+Szvo1FHlLJsGkbQzEbSFnf3cQPBbpYokm4WAxNEtT9by8u6RWulgc
+BF1axzjrFtpY9xS6pJFuNaOAq/WO0B9C+slNR+0dxc6h53fC6t1nuTZRSOEjkIED
+5g33fiSwqPGwKd5GgKLW1PhUryOjERrM+jAaEmxuGw==
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/notifications_emergency_access.text
+Size: 121
+
+G3gA4C0O7MafTThKdnmP9xKhFnvdp6DTMkXm+sqFLkCccsDeitvCCE92wBPcY+y5
+zpZl8JxaQm47zaymP0F8tK3YGbjqGbn1NdMelA0cugA=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/notifications_oauthclients.mjml
 Size: 969
 
@@ -34949,6 +35021,39 @@ G68AYJwHdouHi5Zeo2XfI+WeCEgolAtdyfXp5ID971GWJZ63RZrkNnZeEJL1Qlw+
 9sdAygclCiJzpuAeRLNKiotA8DjGJLTjKPt/5k+EhXmBhj4GAtEa
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/notifications_sharing_expired.mjml
+Size: 353
+
+G2ABAIzTHbVZmoueui1b2nn/DUXe0h8UFFGDMN4KIkV91G0h958dLg4MYrMUwWIS
+jsnQTE3V8xCPw8Ws08U4JKIC5xjatnBOm0OknSrCrUYUyGXR82OjAI+8dMT7ePLc
+3/2Ef064M2mvOFiQWPPVQEM4qlMDBVhDeAOkASVdhh65hG5AYNlUGK5yeDxD3bck
+ke2CNcUYPsTyHA==
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/notifications_sharing_expired.text
+Size: 143
+
+G44AQBwF7jZ6P0Y6CLaz06Hec8Gj2wp9Oi0hF0f6N/LICrKu8XRywP7bt4O0tQUS
+hhkGuY3hOE6NiLpB9BhaGbJPH8/67U+/FpwiD+OpZGyoz6dEPqqi6KV0KEBGJA==
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/notifications_sharing_expiring.mjml
+Size: 448
+
+G78BAIzEOEbyIk5MitRt2Zc03q+hyJupaVAwaRtrFzc4PJa3kPu/+425TUAoQhMl
+m1GEpjTmrhIv5TWzmy7yUPOQyXMMXTa4ZP2e0PZo2G7VIqEX4x2hPyvkh1864gue
+3Mbtiu8JUUC3MCxQbOWaqEs8VlY3DZx31v1WiQUClihCjFRyLuER8JCcr8kTkGgm
+zDGlaDurPAAIybxJacO75ZQM
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/notifications_sharing_expiring.text
+Size: 191
+
+G74AwKwOeFOWK5eJKlxdJzp1Je4joNzDA0mFFp1yoUuRIqbuS5tbW25wbxzooAPp
+v1zjMl3zghMO68n69yBjAkGxKX/MuZD/7XH3qMKCiARoNoVsQJPPUEzUILFO5o2A
+Z3AOdk8C
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/passphrase_hint.mjml
 Size: 562
 
@@ -35752,30 +35857,32 @@ wY7iBGFI08QoUSohGys3iiNI6YV+gOwmf4uZaAI=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/login.js
-Size: 3597
+Size: 5139
 
-GwwOABwHuTnqCtcVSax4ze9ntn6a6hsHnjtgqlY5U5VyewnuMvuieMEASZYUEL9v
-vx9RNBJanruz33Qf5km0+czufkU8kWiioRI6MXFIPMTNLmrJ2N9ql4suzvenzGts
-CG1Xvt1JZhSa4QTbLc4mtFytJ9z5IGFYxLqB40n7PuTgqwM5xsiK/HGejAKGxz+B
-phRBYutFuTN+NzEYK9CgStpCFTaRK4sll8vDuhp1ZxZJSWjVbrOVa4R2JB1QJIlJ
-OHe890eD5brT0BDhjoY5sVvC0YSbcfzRxoaNQWrC25CqjZIWJQvkjfsEHy6MmZck
-3VVCCDnor/6EajqaBn9X+NACuuv+brPdJCuZQdc0wARolnaDYO1UE7BLBzGAVLs9
-ZCA2231tpNanI0yb/numCRjIeQK4y5ItOyTDZ/BToLFQhGMCR0Tn0fsmJgQxKViv
-MAGIiIJR+4q7IzSonVVBO5M18JJZFGPAhrXwRf9KRCQytS2Aim85lUQITQHtYjnj
-A6/uyWqwN6N7BkFmdVSVtbFEJAVUxM1wrOBnJpAp+2a4NH7j4eHH/FeE+8cr8Akj
-52MmQFfcAKc2iJv+kAN+/ghpGj6oMDQeqS/CjjuEcw7v+L//IfzVrp1A1ZE2p1Np
-Ki1666wBStzlaRMqufM1XGvkAKlYLWcnx9Kg3AVsDJ04NrvB3UkyWphRaoA/pQdy
-mbPbM0e1aXwqMJeU2mAm4Tb+h+t8YDCR6rTkTiInIAjZjakwID9yoTvz/fFUqJs/
-HV7KDUBkO/WKYmfXmNAwbkwWPJOMF2QUVKNFSTFMJAugxNswti50qj4IOAtbG9sR
-GpdjPSQkrHdvxiocEG3/8p21raG+sP22mdhePrO2ouMOD2HclAU2kHY+F6XxTuYC
-1SEIOkwztgVu/F0Xuwm6WBFhb6MEgnENH+oN4A3HiSzGHMr4KJBZxVjkhfrIJpzM
-JYyTurOtmRXT9RFji3HtT1JKltyqtNXiu/qK5GeG2+nlRLUYSciWOQxNqcLM2KlV
-wBjTdyrnaozjDCqlF/M3Ck7zvIVAVZCfDKvgQ2ym/UWdc91fBrHdJe/5/+mNVkzW
-EJfKSXrHYtDXNVPQdOQUTWOTQupkjPEnuMT5sU7WEcr1yB5p/mECZ0z1Z29RKTbT
-WIcpCAGWHqkM9cpxwAnTJ0PfOeRv9b1X7CUWMTDr+P9EG+ZqAmQ6ubJBjTroRU7G
-bsDfcFccQ697Xh40UfxeFsPTcKgmW0UqUVMVIw81VYTwNSmKkM1njLIB1KEv0QkE
-BlevqlZVZMdxJ5yiXqdSvb8L0gJXY05jj92sybxK9Q7ejQLR1PSChtfq9P9o7e34
-qciuyxI=
+GxIUABwHuTnqCtcVSax4zf+m6s4yvTR9uAhIm5TgtKn11Q1k44IOleCWAEmWFBAL
+9/s8CShPOL2bST5R3kGBFaDE2U0KRLaqTlTWyaqzilQfo9n2mUKriLe6xSMu93H+
+dym3AlsG+BJ8L6nSteMpdjvsJeWt3IgVd76RYVyFpoXyEn0/Z2CnA13C6Ej5mzSZ
+OAanP6FLKYrE0UR+CraHGqwVGFD5s1/7pvIuYvH2zLipy/2ZVVIK2qTfPvK9ervy
+RVH4KzScN32wD4qV+94b5HddmFP7KDiW8FKYfHZhYWORWvAuyroLsi0yC+0bb+Os
+X4DZk036kdJD2oP+1V9X+9I7e19Zbyl67v89dvpNlplprLkLJooGWtv8vnTu1g0J
+WPR5kUHrFS6AkctYQtpZUPseMn5vx6wLvoRvDxp0hAHHj94AqkWyMwZJ8Vn+IjQI
+1SpoYIjkff2hrXK+dOApESe/DnGBMRjumWEZ7zHuhJoI3qfVopoALmoNWx3USYgo
+HsSxa/DeyUUEBRkNTJGzFrhhqyPAboxwjr0shrcdEu20kkwVR+FcwDYzATrFq2G6
+xyuzrBi3YrhdvAadEgo6IQaEmVfARBzhZgvh+BPHSKv/IeNoMGS2cE/YD102+A83
+GxCy60GhgsxkHjDhWFbwYr7pKCDjnvGtq+WeaGB2gS6Q0t2Z/PREmo4LBNtAL9Tm
+o6B3k1QAB8oUsJMN6OZqP5gr9YDAl7H2NOdbnL+4i//D9AoEE63OSn6YkQtQCaFj
+8oHYiPH66M/ncz4Qbq94SQdFRIr7Aie1p0RoGbemCz6RjldkVIzAA02xTMRjV+Pf
+MrYdryYKCmqFR9R2OVij/xaFlEP2q7EqO+KBf/lZ612wfqOHXTvVhxbt6DlSzjIY
+FtBo+mTVxyhcrzLG4oygqJhlHMfq+m87YjfoakWUzdbBS/NytdA+KPyu64RHwIyy
+vgq4q4Rkvt+8IvvjXgKc9I00tSOz3p8IJZvnVzKllASek129uJi/KvmFo/x0OckE
+iAmR1GuOyiEsnLC1cUzImncKmgFlyqEsfVD8Y+BydrnUqQbyJ+PaWRdaP1w0BTXD
+pZNpP/M+8T/7xwammMNzcGJzIoZc0jN9QSeqWJrYFNIbFi39eIWLk5hsKhRT0DnO
+HHXdaMLM6fqq5qR8mP70JYFuHamAunIy8KarNrNrz75p1dBaTlaGKjhmD/2X2Jze
+rLvMwzRPkaUk8kdXnjACLhyvOoauPr8etUHaZx6Gj8Bghh0DKaOmOZVDLXMovYQV
+ybO9nFHHgR4SH9UNRBOueT6p68qu4y44TyTuNWm9P18pJb6rj4MrfcpJ2QPn6mVk
+43VE6sV7IUfYSW4Ut18IkvsHpGDPjvYHeOju0YAXndjVLb6nL7CyQ1sc3wtxynZv
+7AxQfP0fjm7vZ8u0q46MLBsRMuUE/l7z+zSxf5zZal8s+7IafoAsiy624ITyzcWA
+ORBVBwR3KIKhZmT0CXyFscIcXrAPxvoz5XSkIEcZ8HW3Ps7/r9xEPJVCGTg6DA==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/new-password.js
@@ -35913,27 +36020,54 @@ Npzu/2zUuAxSXhAirsva4bgpH1fsq6zX1BYj/7DonZix+/w1HELrLhPEezXeRwUI
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/twofactor.js
-Size: 3119
+Size: 3714
+
+G4EOAKwKeDI0V8BK43ELDbUh1DgQKvZ5aNN0+y+TEsCyjpep04OoE2ubnWIn3f4u
+GoV6pGdNqQqjCXyCKLAkTIxRpbBUENPRWrMIoRbG9t0SJsnyzNwtIl6fkAiZkGnR
+t+FMu86MECRCYELH/T53zzj/nJYC+Sv4K7ATdavYLzfw/w8TdbnV7EhwPyri5dT1
+0GtezbXvQwKhOrCKvWbO1hxH4hzkFxskoiYA1Ltim1Zs4kjcCJT2iLmQco0g+sj0
+TzkzkD4QD1zyLUwEddtvw4EISmwZlRkEr3fAYUDL8SgM4sFEu9vGMD1yOz9M72lc
+zId+k/hXY0FRjI8CQusjypq0fMYgQA8FlhONBzj88e9DmgFujdshsXhNoQgT2+Yz
+AurEht+sS0GJNh58an53MGg6OyUgOShtNNW5w5XFYQrEdt6PSwS2h8kvRnKBu+jR
+m21ZGqD8/Jz+c/v4Uw7xJbhil0WtecLF3QtBlNmM4FcsgGwCMEq+xxWSNm6ub6oL
+m53qDEaIYgYasoYABEMnorHPEXlY0UE5aySnQY7iIUQGUxEIs8uGuKubOF4lMza2
+bbAl7aMCx0VPaaBaCYZ85rtmJSuKXOmjVY6ZHADLRJLPlq3PB2Hwc425m4dW4j/w
+CBiqIAz5BGQueQRMBJGeflaQe3SYcorfi95tg9w0FF04B3UWD/QcoP1DgcTl6ODr
+fTlN/0d4RyP2xAPbpClU1jn9WjYNRrOPMFmDtrqZgG7/AouvgUoJuR95SNSYhfSL
+ABezjBRlM4ZZ0Bg6JyELxWWrm54O2nI9267vOPqEonOv+ffnUW2xzQLVVLmbTDA5
+tAL2s5SRSfGa9qQPrfwkoI1LHpftkETgJWAcYtwQIWsetIzxZdXXtAigjjgMRpj9
+WU2yJ6kge7rYbdgAY4Fg94CQrcQcL3TandDWW/lmVTW/5nhoD6PslQIXvdumiwWW
+Pc7qOjQNexYv8mj2otfMi22cXLJUexVc6rjXR5Cl6oa4wUfTwizie7XETncVrJoU
+2LxmfKLnpXgR89iuQpQFAgZRpkD+lAqGKChKCaGDR267Gb6HW0B5tneWWRXnGePI
+bS5YkZvtNfuMK3q5nfQTPgccREOWZTFJHPwgoaCC4Un0QB7BKAYXNj5urs+9e9+e
+VszJ3Pw9ospcXC4bqrWtz61Ft01VryJ7OB/lM7smZAFvcbFqYKgkv/Q8BIuBU04R
+42gtMrPgdLaOBC00cCXPnTLwwHylQ+jG5+1FnzjMukBsgBBANLCA+sUT/6axZAhR
+Ljrtob01juTGDthIqKRClc9/4msR6yiHaDnPUVFf+tIpFmxyAQ==
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /scripts/webauthn.js
+Size: 2699
 
-Gy4MAJyFsfOisL0iyUv1GXOW+f1U3Vmm7xQdvOiktMnJpU5tarMb2B1U0MM1QCqF
-pWCM7tuv3R0RQi2M7cmXM0uYJMuz83dxDwVKg0gmRhKPhzidSwuc4hPLBw8a5Cud
-cmtwUIB4P+2lYuriZI7jEVuSVq81jvudUpgE30bEFKaj6PuawN4c2KKYAou1zkzc
-QHDxDomoOQDqbdoMK97FUbgWKI2IUghcIYg+sP4Dlwyk92QDd/ywc4J6OljYPSmU
-zDJKGASvt2BhoMuZKAqCoPfoSPErhNYH9BS53zJmgggJW7mmA/b59p/OLwFum5vO
-s3PMk3BzrqwXBakw+uS3o4KSWDr9S+G9veCOrysCu0dfoXkH6GB6EvB8AqRtvJ2W
-+FubiiZxSJ5uozY48eVyM0RCN2ZZZ25Zh7/EM+iG0AddkwIM5CbIEGAg0MBYTiVy
-uRiMVE5PU0qki2aOj/E758EDyFU9WcUV0hfjgAzjSQgCF8fb6f/Pdzyn95sjVSEQ
-lycB9t9/tQjJNris2IsQbAdJCjECXHIo+UXP2aUjo4VtE9Oxrf8qW1C7LN9qYt/t
-10X7QV/0czKX38N+9xCkvJF+F4j5LkiyS9gMUJjsBxa1Ea/5iZjEwtCj3g/i2GTj
-Bo8DMQ5ivUvR3biwHseQH8v7WYblWd0vEhFQbDpHDBOe7+LHTqZJxU/arjlbklVK
-f3fklF7nbwj2LCXmzS2nlHLlMnfNukm0AmjRWyOaVKBQZCQufDCPjzWVMloqYwq2
-OKCDS/9WoDaViD3K+/CfzPpXpB/8jigUkXT9yaRx1vm4GK3bPrWjjeNaiOn1ToCh
-CVUyXcssG8gTI5plgYAWb0kMngrZwILSlCjaIfIN7fePuAeUl37HHEnkpizryTeQ
-sepeiikMBJcO/HZuMDuow4Kofal8SsKD/SchI0BbElogbwAoMJiw8byZmMdNt/y0
-cJbMD1WWVHmGX6KFSuydodFULHydnDLCzUTO3LdABLzB78Dyv78XbSxG1mqy7IN3
-xKOfT71vueYXtHBzGi40+B30uRW1wgia/Hw8jp7tUmelLkQBEoE1qCu+DWwotrEp
-DpVkk5wQVug3GzXk4jj+FWx8mQXtmsZ7wqgDTM+oIOXqc/tZiikVlGdYKC0bdfLB
-5/IJRrCuKw==
+G4oKABwFbluzhI/O6KoRFWWofl3L2b+f154CEThReiL6cpJuWiqkA9uhJ0EmnCc0
+DAI+22EDzjg6h7v/MVeHhT4qpZGpaWd/Jt7tPiKSFnm0NTKRTA88hPPGEFfrQqrd
+In/kUfhrAaXgpYdQWGf8DzxnwfJH8/+tOIx6Dg4fLhmPcHdMefdj5nXj4uUAYtY6
+IPYfNtbyQ9kCZe0CAdVHk6pCD7vIyAfycylJlV2bN4G82L7LVTfDUkby1pdDKQDE
+xuyuKA3VBQe4lwLbZbNoiPM1OeRKW+8tWINTGmLjhzBou/dqHW19V3SEiKtjHbTf
+FT5Eijt10XibqeDGD9CHb/bD7qha9oy/k1b98NzC9TUjasdUKSYLGXpb+UqyyA54
+MbWn8rI/MlNSHGd3hyjJJzLGIYA7fCUg4unuP5MHWmjgeB/k7B/GaCACGciCaiPq
+oEg3uo+9p9OdXGdvqYO+dnfTeQUTJcGUDVft7WOOhLr+zAyP8gm1fr7Gn2OPHXRk
+zdAp3onS/ELl2B+QkU04u0gQGwRb4U/oUjAOXggIEMP1/GzOZG7KwnzZlC0QbxVa
+wlyBgP7ktP3FDlh3q6qxQaBE88moLM0kOGY0Drv/Qh55eckm/2sy6kKZvs1nDTIm
+LrBDMj8hVgP98pIES0FbWbP98Co200KOzJhzJhvC7oG6DKiDYq7dW7AdaiDDLRcl
+oSGDz9xthIqPpEZpSJ+6SPxPOmf0WYEVBfi6SAy1IiQjW583PiRKfDtNhRLWGHVc
+K7b64VB8wBkzdDbLgl6M53BWZQuioKaGR9Rvwf1BAeU57UK+4B6DLHNOgK0CmAmn
+WbKltKDcITDsK9vepTTqyQ6pbrJt1aDvo2f+i2pll9IegH1AusAaQWMM3Jkmweju
+XGOrGGY7K6TyXDBHbbDwXrDrFOv8KgT8hBIYDtDI9M8QFzQG+ANfcbzf3CRAMMyG
+6NDjGsX6rCDeC9pistCxi1OPP93EO/R/zKPJk2wFxAcAOLS/wxk/W/lHLFNXnLYh
+gEJA6VQoumLckm9QcGDjzeMkiG5VeDI1zWOMecjSyCd++MVwHQEWPUGkFQq8XTZl
+1qTILGdrk1UVsTgdcyru8ikxn2GOjOKsrMZiZmk1gj2oz8gYPlgVyk9Sz5VezcsC
+a3/+5VCJw2EPIYapvIQQLfnwzb9ILUCoa5BWzYqvaFi/twtrir33qOfrSDG+MZSl
+9m91PuDjsKzZKwvRhpKMsj37d8eqFz2nE4h9FLdfn4TURtN6EV5HRQA=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /security.txt
@@ -36224,40 +36358,41 @@ XFrtR+aavtcw/0xFIYOvEPAWCTsmsKXAN+uFgcZeRx0iDQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/login.html
-Size: 6057
+Size: 6363
 
-G6gXACwLbOcPCF2jk6zBYkgOfQqC/N6lxo+geeJfSmfp6Fql+8bSbIm0Rc3unJwR
-SwFmNmAiaKoGUbDbm317IAGTUkxG/tfY/zrDdCIhUiIpkMrbc+XZfkxtd89+t4pa
-aOI5eewMJWFjTNrLFEfIAVr+qLADM2rbDwc9l+gWOVEPHzhC5B5Afg+r561P0sQD
-VPkOXd8lSfnN+CyNLWyaHbWobSW8Md39JTHsMekr6SMO0NEE2gy7PuhJUZgyNfEE
-5tMBMobANx4OnLMU0urxOmVYrZu/srXpoj5LU+BotGMfE0ZPXsHO+x1W/8k4TMQl
-ni2/93e9PbMdUqotsfMHfWq1KU/y6i791WWCdO22SbA+m87xHp3XnYrzveJ+0DVs
-z61bKU2XuEJ/GcDdRVxB9g8t6WmLoSvZlQtFCDRI3jbCi6Pm0IC8Ax/nWnqTYEnj
-fW2Tgzz0TIM8KeeF/t/bbBCX5aBSbf9QJEnSTu6/qz8GbTyJFRAP28PU13R/7JTJ
-FWygEciYhMqwCmKTGJAsoB1/hYdcpzEPVI/bnNpGfPP7NE72ek+vv3UNkb1THMRE
-LX0e23KqyAACf1Dzy1puE9eITX7NI6eVAILFfzFarzeFGqH/6J5NZL7GiIb0CrE6
-AGF9dI5ePM2Fq3kc0SNe+43XL5weE2QQkFCXNkDjFoyO2lywqMlgm3/BVRYU2/BF
-F6iYrt6ZJzPcOv6hkNLAzbEdVGGDvN3KCU9zKjT1HcqwqIptMktKwvE8tKwwBin8
-qB5mo8zEaxdckUMecULyvHTl8XUiJxX8A8QfgOMk0TY6aV6feZ33O3oNc3UZhtjG
-M16xldjXpfDbDOjPdWiDbOmLLhwD0OY60DV6sGgDrUHX6A1doxhqMb1wsuUEjTtc
-DzbIlxH6iwXFq0OPI9anKtUoVZG+0d9Qf4XR0bjCBWGHFRKILUgNzZfzbiNl+yb5
-gdHh9vH4WIu4G9kJ4Wrg0loGBaJ46XkbwRG0PWHA4iuvZ1rQQVQGNCu9ggm2e4NL
-TSj5kBflUpJcKLC7SRfv+/Z5oYUThXeeLVycgI1t62zVpe+USArp5IDWdqLZtlm0
-t8/Lz9HMObRNJmKPjaJp8G6Uzh06bx6cNW3Bl9oUpPIZLaFl4tVD3/WVPINCf7nz
-73dnJb5BIl6LbUTlNdJ32J7BPhFpBwbU8lZKhlLp3trNda3bZNTDz3q3TjJj3IFi
-lWLt/t9jQXgbm/lXw4WJHt+D44SHnFlv/VypMMlHWL8CL8zcLGlCZtKlgrDxOcI8
-asOwQeDJ0SF0TF4aCOkrSQmVD+Ba2PFkvwJegAeoUZtu5aB2wEcRftyQ9XBTz0jf
-T3Q0GE9efygKHThWgvbWxT+55XDPGctjhXve1fnooRlzTh4k1ambBUjbiklpKVUL
-QZiu6ddWM9JgDNLZ2WoqDkB6/dVSoVabqV5u4NS09LQctqWNNjv5yYbHVE5qAGzx
-LqLUc9dgRTR60cSF7WgXnlkpsqrGy/p5Q+sVtjaeeMpV6Acn4Ypdwrqsz8tYJWfv
-yoYL5w8netcB2Ql8Elz34l3ARML21yQydTuHfACRd4r03nsBOD3CxxVEkNrjNYk0
-8nZwcgu5nOq1Kgno3b3qsM4Zrxvq0bsHy5iIRxKRQR5ssaS/dRo3G5ovtX96Hs2j
-wfdmkWbme00UseD+6cBNA11qZErLlaNXjBmK7yNge+Dp359r6VUaFzfwNxdLxMUW
-SdvBNpP0ib9JZOfFU4dUJ3i2VF6xax0k5gKIZYyrjpJMhKLSoe0YpsTE33FL7MFk
-VxWU1oZqMr/VAQirB0GEaigW/yVHKi5d8V6F1KqyFkFaNPj1Y9EfGGLztnSA7R2y
-zvnQ2pzHx4LpUhOj5meY3vzCVkHEGaJ6SpmN2PG7+H7/ofaprxzYU2DQmUAoGYEe
-1uFeVMOSGO6sW/+WEYgdRJahwh+yW8r8Yw67dgjhhPj5b9lwHm3C8ovWqM8K6dgn
-a/QOk4rb9wQTIO2/d/S5GiqZxz4YoN/NhX+kebt7DcLTX4NBdF4EXylwSGNW
+G9oYAKwK7Ab9G6P1bQhEAs3hj6DIL6dv/AjaEX9SOktH1yr1yNK6xFXN/XNyH7EU
+YGYDJoKmapC+PbsBk1JMRn611utTKR8ZIWMEWntNc7D7gViFsGfe3IZRIQoHrBVL
+n4pRWY5GXXLcGucjww6MShvddzmX6Ag+JR4+sELkHoB/nxLl9Xo+xZqDb+H6LovH
+N8Mzn1rQNFq1qH0luHGp+8vC+IgJrfhHXqCjGbQbJr7LSVE8Zer8U2LeD5AhCXzo
+vusQOJJUj3uEQrUifGV705XyLE2E0dqPlRbMlLyJGbYV+X/S1X3hks623/u73p7Z
+1N6nnhj/Lk+tdtFTdRUjvQaeIKTdVBl6gyKO98gwlYIO9wL7uVyT7bF1K9AUhBX6
+ywBORraAzNYFyWmLREp2FZgiMzTmvE2EJ0fNWQMKBLyqU6xUhnVZoLUBC34YKiV+
+kq6L6b/dajgtqkG52mwd2XPSVqDfzo9B/adSkcTjeJr66o/HOPcWsOONQJaeqZxS
+iFiXgjkLl13+CqdaL6EeSl01wRcZ+8b3Pk30ek+vv3XNLHucLdhEbvk4tdWUUQEz
+/BOyX9ZyhG3GNvE1TuwbwRKs/Jei8XqTaBHTjx5Zx/M1RWlIL5GqBSFsj4bRN/ZD
+xGqcRvSI137T04v2j04yiJGoNi0LjSPQWSpCxKYmJ5r6E64yUmzpiwJS0e/eGWcz
+ubX6PZLQwM2xTaqwibxJ0UmexqXA3HeI9SgaNqDGnADjcWhaYSQu/KisByXMxANH
+XJFF6HBC8hi69Pg64JMq/EnEz8Fqz9H6O2len3kdtpVe2yHZCil1wBlr2HY67EsR
+biNBv6NF0fJCXxRwDLA214Ku0YFNG2ALCqU3FEoxVDADcBKzRt2K68EG+bKE/mJB
+5dXS4xL7U6Va5FWkb1Q3VF2hs9TluCDZYUCyxBZJzZovxt1azuZN9rnO4vZxtUuR
+7Y0cBLo6fuwtEwWieOl5GxUjQH+yActfhXZ6QE8gGLRbwRWbYJMzLjUh9yEGZU/i
+EKnV7ibdvO/b55lG7al959mooxOwFtdjki59J08ci3YLWpu+zKapork8bzxHM+fQ
+CPeEHv0sy8Bk5OcO7TTU1qgC8PXGC6l4Rgv1THH1UCGtxBUk6OXOv9+dlfgGnvTE
+phMV1wDtoCPDx0S4HSOgwpvJGVLQvbUc0iQLr8TDz3TXwwEx7kAuMViT/3sEBPex
+mX85WOj0+A6sEz7pgHq9QyoFcT6A+hnywszNlCZkJp1nhLXPJcyjJgwbCiRQ+tsS
+yVMDIdVwSlX5WLgmDjw4rixeWB5wi5p0K4vaWXwkxU8wZC08qGeA9p2OxsZTaD8r
+Ci04Vkh7K/BPblmsdMD2WHSPSR2PHpoxF4rnItWpGwVI34pJaTlVC+Ew3dOvKWak
+sTEIZ2erqXgc8uuvlspqtZnq6Q72pmXIi8W29NFgJz/ZqSFVKOo42OIFkuRj10kE
+SeqLZk7Ea7+gylKJVTXeUA0zbBfF1p5wTjLgN4Ou0CVbl/l1GarE6J3ZcXT+cKB3
+zfHK8Elg3QuTgLGE0VfPPHVUt2EBUSAKfx+ogDG9hI+LWJDa4zmZNPEotHcLBT7V
+tirJoncyL7HPGWqbtqO9B6sYsMfchMr9zRZz9NbK3Gzcfd7+6Xg0j068N4u0snDU
+RBEj908LbhrWpU5PaLmyesWQIfl+GtoeePr35xQrlSawG/Y3JyHCbAvkbSHOOL3z
+Nwnv3L0v4VMPz5byK3Stg8SYAaGK+aolOBMoUcuB/UhTYsrfaVPogXhXlijNDdVs
+4VYHIKwdQCJki2LxX0JJBbmrvmdJalVZk0JaNPjeLuoPSGLjvgwCFklW0EBidbfX
+8Ap6MaapXMUgzjN2TdYMi8VCZgMUpEoagg/8Va8LU3q1i+gvFUxoVdOs7ReWt8zW
+vtYz5fRjJWNxaf9Dze6wIaL5RZrqlgtyA+untIoNKo6SIvgge9+CYEVr4yJh94es
+Yxx+DO1a1G17qvCd34LSoTMZ0y/aosqADCJdth4+hQe38gK9fgDeEUlWUcXIzLno
+O+d925lLYQDjuU9ksn+3jvqHH5b1tWXd/7X4VKJ3Gzee1Cgf
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/magic_link_twofactor.html
@@ -36550,29 +36685,32 @@ l2g6IjlH5VNzifgYNxfTdjapnBCIJKcco1YuTQYez7JY5Gg2bXvalajX0/SRWhIA
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/twofactor.html
-Size: 3770
+Size: 4762
 
-G7kOIBwH5ZYzZdoIKWzeQf471fYs0xfjSQ+ulLrmvF3FBtspgGlpyA3St6cwYHy/
-/d5sFSlXIWxzk8k5jwrEwvBkMv8XYYGFX6GAhTVr5C7DqTHT/6RpCdEKvWWEgQbZ
-dCl8VqIjeozdTlhA5C1Qe8h1sA8H3cJaNHifHp12wbUV2CwGZi41Cy0KqZg1Hro5
-wIUKY1GJD1g+J8AzieZe+DxRmGPq9piVj6ePeQb4YCkyRp2Ie8dDTHBaFT8Iq6ar
-eSNNxNBMxvbihSD3atxui/o/MTOLm8FKQa1/C/FnbWYh5LiZfeEtq9071qtqENew
-L0UaigJ+OIq2MffHVcfIeMOYH2iDabfBLQ9MRUwhPwvgXNKqeKr9VtLaQfgLfDk5
-eRyujkrqpsypF/CcVKbGFPSeID9tOh7a33OtlORCMiFzmFevLz1EAhcrmbf4myl8
-CmFwlk9ff9TH860OesNPCmCO9fTLWa+iOduUpKk1LyWrB3rfxe0Amn4sV2v3mltU
-KatXpC13EbKFGmbkXlaGPoXCnLDdFLb8TKleQUSEYyK1LtvaVLsE0BeR3EAK7VJv
-6HkZwa2nw5cwEw3sraKKp+qDX9G0ZBzc9D0VaHgy+g1mbpWSiI0VpuySMhomsXq3
-9gOw9FygNengh8Rd3BHyK8b4JKdBh2Xd9rhACtGgbU8x/5qrwZbe0zaN9OSnfOZE
-cV8acIg5VZz0FHBHk4l5JiO8AiWDzhlt9I1+8uR2W3pauqz6x17E5UT5dc0RF44g
-pprZdLd0iJ2KL/fH1B/CKDI1jibyazeoWMQ2M9XaTd5A66DDjf6jQdsMacLU2PWY
-+sk42ZOTgXTuUOVJKYYHopQjfVvhr0Oy3MsILwzh4PYaB/vlsoV3AwCDUkaHmM+p
-MK9ddmyAyEMjXyiIu59LDh5cK3da8z6rkWZGA1L+1SP3fe3j4meP0YBOGqdSWJXN
-3VnjpDWnLKM74515b1n0B1VxAooE2r+/zFriT0ErbdNMLuNs4Wx3fdkcntlaqBG5
-0L7zwO9GYKchg7Ovwtqq6sDYgPm6w9prXC3CxWhp7/V350VbBelvlTzDVQg1uQ1O
-hAzTj27TrXtjviJAEVLy3kt42T6jOp2WyAqGJHLQk+aOhmwYKhkO9SF8XvL6zppd
-mczH51SAy3cMX1LHyoT2VTkyvxIovuKjpVw+QbhseacGZG2NctaWMxl9BtKtbmSy
-kgwMtJUlXojXU0Xh8C1tkiXaw6dQVkH/dWynySRVHIZYsNiaMlvxqBI1oqG7Jl0F
-UfBSCrFhmGhcVmOp/CxryVf1+ofn0XGor7Pah0V9a/eWDGIB
+G5kSICwOyja4zgph5wiTp78i/1WX0/qVsdKzC4Db0VY0Nec2hhAliMJe9ynsiQyY
+ePOtNV77FbfGVwj785LNzFGBWBjO7tu7ArBwgJq1rnVkTJfh1JjcjDRAHLrSW0U3
+MEO2fRzZRYnOMbNdxwYiTwHuIZdgX1+YGdZC9V49OF5CuZXELAKrlGYbLRqpVDTu
+dLWHCwxTVYl32D4nwSuJdn1kl4lqNl+7eebla1guwiwE0j1ObCAWHa9LuKR15Qdp
+13Q9m6RJBBpknBgvFHkVFYYe8j9x1uNWsJZT6/dC/F6VLYq6POx8ZDOr3XZWVBfV
+NZ1bntaVHH59yefGPL1s0oREeZUIP9Am1553brUyHQmF/SyAuyqjm+fW70DVBih/
+gy8XV48j1HFNPSrqasvhNblObdKwe4b8YuvxMP8e9VKWC82EzuGyesvpTsRxqZF5
+im+r8TlEhrN9+PpdvT2+9IXd4MkBsvt+9eWqa7OcE6XuYRl2X2QP7D7FzQBjGfs3
+7XNus0lVRYm1PImi9jDDipxlVdhTGMwtPl3BtutUKgoqYhITpnVfifrPEtAXUYVI
+Gr+Z6ej9cRShJUfcwk0M8LeOKq7Vh7xiasnYuVl+12DiyfAbzDw2jhUlo8KS3VLG
+o8kMe2E8DLDyvsFs0ikOiUvcwsVXMudTXAedtnU7JRSkUTrM7Wnu33O9s+WvZZ8m
+dvJVZgNv7kMR+5hLzSmvASeWzNyzOOED0KKwORtnsdFPHsLQ00NW6/I0irg63n7f
+c8QVI0jGZnXdo+5iUPHl7SFt9+E0OYmDlfy+DBiLvs2OTbt6A7ODrp/FjxHtrzza
+X2ILUZb/pp8VOMS0xUmcFpy8Zxydu4hQLoWAUi8bhaKo55ij1Nu3XZSIzNBFPN5i
+b7etJYbWxO1Avq01Uhd+6KEKo42vrMiUm+dehhOlM6Nea9kdsIA1SiZ/B/pcItBe
+GCqJ0+rwJzaZnmZS3QJKG1PKPpemPTZLkwoJlyWgTJTluRIlIz6lK+w/4w0q6wwg
+LW5eawcItQj2A3DC+acBhcvxBsUAmFZhPA9AWTwAIZGFC835f0Fe2z9G5Wnvuzj7
+3EmoovIrVUo7frrVqi7NDT/m+kg+3+J1crO4+sFGtOVmFQ5HrPl66/nSo+cv59k9
+vozBvhaiKELr7Ma6Xoym7ZYp9v5EvbA2Y9TZy+X04HBOnvWbYqhG57BFl3vnfbcs
+eVFJE0BzctL7bdFL5aJLFm2RlQKx0MEthotSs/XQKZNZDsGli5WukZGLHcdHGEAl
+UeFbWtjFMr+OMsqZwC2TU66eIGhb3cMAso6jXO5wr5Jrk1feDzYsmyoD+5UTL413
+lZqiwx/lkNZGS/ApzFeyiDP9dnKVcU41Nix+T6OfEeGMIdk040C8tf4M8okOabvC
+i+8HbOhrO52wMGFAeTA1RlXIqs/e9V7/V0bvbJMQ1sopkKYwqfyUtXAKdN53ZnUs
+jtEC
 -----END COZY ASSET-----
 `
 	fs.Register(data)
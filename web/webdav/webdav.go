@@ -0,0 +1,909 @@
+// Package webdav exposes the VFS of an instance as a WebDAV server, so that it
+// can be mounted from a file manager or with davfs2.
+package webdav
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/cozy-stack/web/files"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// filesPrefix is the prefix of the URLs for the WebDAV resources.
+const filesPrefix = "/dav/files"
+
+// lockTimeout is the duration announced for the locks.
+const lockTimeout = 3600
+
+// allowedMethods is the list of the methods that can be used on a resource.
+const allowedMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, PROPPATCH, MKCOL, COPY, MOVE, LOCK, UNLOCK"
+
+var (
+	errMethodNotAllowed = echo.NewHTTPError(http.StatusMethodNotAllowed)
+	errConflict         = echo.NewHTTPError(http.StatusConflict)
+	errPrecondition     = echo.NewHTTPError(http.StatusPreconditionFailed)
+	errBadDestination   = echo.NewHTTPError(http.StatusBadGateway, "invalid destination")
+)
+
+// davPath returns the path in the VFS of the resource targeted by the
+// request.
+func davPath(c echo.Context) string {
+	p := strings.TrimPrefix(c.Request().URL.Path, filesPrefix)
+	return path.Clean("/" + p)
+}
+
+// davHref returns the URL path of the resource for the given VFS path.
+func davHref(fullpath string, isDir bool) string {
+	parts := strings.Split(fullpath, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	href := filesPrefix + strings.Join(parts, "/")
+	if isDir && !strings.HasSuffix(href, "/") {
+		href += "/"
+	}
+	return href
+}
+
+// isHidden returns true for the directories of the VFS that should not be
+// reachable via WebDAV.
+func isHidden(fullpath string) bool {
+	return fullpath == vfs.TrashDirName ||
+		strings.HasPrefix(fullpath, vfs.TrashDirName+"/")
+}
+
+// NeedAuth checks that the request has a token, and asks for credentials via
+// the WWW-Authenticate header if it is not the case. The token can be sent as
+// a bearer token or as the password of a basic auth.
+func NeedAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Method == http.MethodOptions {
+			return next(c)
+		}
+		if _, err := middlewares.GetPermission(c); err != nil {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="Cozy"`)
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+		}
+		return next(c)
+	}
+}
+
+// Options handles the OPTIONS requests. It advertises the support of the
+// class 1 and 2 of WebDAV.
+func Options(c echo.Context) error {
+	h := c.Response().Header()
+	h.Set("DAV", "1, 2")
+	h.Set("MS-Author-Via", "DAV")
+	h.Set(echo.HeaderAllow, allowedMethods)
+	return c.NoContent(http.StatusOK)
+}
+
+// Propfind returns the properties of a file or directory, and for a
+// directory with a Depth: 1, the properties of its children.
+func Propfind(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) {
+		return wrapError(os.ErrNotExist)
+	}
+
+	depth := c.Request().Header.Get("Depth")
+	if depth != "0" && depth != "1" {
+		// RFC 4918 allows servers to reject the requests with an infinite
+		// depth, and we do so to avoid loading the whole VFS in memory.
+		return c.XMLBlob(http.StatusForbidden, []byte(xml.Header+
+			`<D:error xmlns:D="DAV:"><D:propfind-finite-depth/></D:error>`))
+	}
+
+	req, err := parsePropfind(c.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	dir, file, err := fs.DirOrFileByPath(fullpath)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := checkPerm(c, permission.GET, dir, file); err != nil {
+		return err
+	}
+
+	ms := multistatus{XMLNS: davNS}
+	if file != nil {
+		ms.Responses = append(ms.Responses, fileResponse(fullpath, file, req))
+		return sendMultistatus(c, &ms)
+	}
+
+	ms.Responses = append(ms.Responses, dirResponse(fs, dir, req))
+	if depth == "1" {
+		iter := fs.DirIterator(dir, nil)
+		for {
+			d, f, err := iter.Next()
+			if errors.Is(err, vfs.ErrIteratorDone) {
+				break
+			}
+			if err != nil {
+				return wrapError(err)
+			}
+			if d != nil {
+				if isHidden(d.Fullpath) {
+					continue
+				}
+				ms.Responses = append(ms.Responses, dirResponse(nil, d, req))
+			} else {
+				ms.Responses = append(ms.Responses, fileResponse(path.Join(fullpath, f.DocName), f, req))
+			}
+		}
+	}
+	return sendMultistatus(c, &ms)
+}
+
+// Proppatch is a minimal implementation that refuses to change the properties
+// of a resource, as the dead properties are not persisted.
+func Proppatch(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fullpath := davPath(c)
+	if isHidden(fullpath) {
+		return wrapError(os.ErrNotExist)
+	}
+	dir, file, err := inst.VFS().DirOrFileByPath(fullpath)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return err
+	}
+	ms := multistatus{XMLNS: davNS}
+	ms.Responses = append(ms.Responses, response{
+		Href: davHref(fullpath, dir != nil),
+		Propstats: []propstat{
+			{Status: statusLine(http.StatusForbidden)},
+		},
+	})
+	return sendMultistatus(c, &ms)
+}
+
+// Get sends the content of a file.
+func Get(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) {
+		return wrapError(os.ErrNotExist)
+	}
+
+	dir, file, err := fs.DirOrFileByPath(fullpath)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := checkPerm(c, permission.GET, dir, file); err != nil {
+		return err
+	}
+	if dir != nil {
+		c.Response().Header().Set(echo.HeaderAllow, allowedMethods)
+		return errMethodNotAllowed
+	}
+
+	err = vfs.ServeFileContent(fs, file, nil, "", "", c.Request(), c.Response())
+	if err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// Put creates a new file, or overwrites the content of an existing one.
+func Put(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) || fullpath == "/" {
+		return errMethodNotAllowed
+	}
+
+	dirpath, name := path.Split(fullpath)
+	parent, err := fs.DirByPath(path.Clean(dirpath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errConflict
+		}
+		return wrapError(err)
+	}
+
+	dir, olddoc, err := fs.DirOrFileByPath(fullpath)
+	if err != nil && !os.IsNotExist(err) {
+		return wrapError(err)
+	}
+	if dir != nil {
+		return errMethodNotAllowed
+	}
+
+	size := c.Request().ContentLength
+	mime, class := vfs.ExtractMimeAndClassFromFilename(name)
+	newdoc, err := vfs.NewFileDoc(name, parent.ID(), size, nil, mime, class,
+		time.Now(), false, false, false, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if olddoc != nil {
+		if err := checkPerm(c, permission.PUT, nil, olddoc); err != nil {
+			return err
+		}
+		newdoc.SetID(olddoc.ID())
+		newdoc.CreatedAt = olddoc.CreatedAt
+		newdoc.Tags = olddoc.Tags
+		newdoc.ReferencedBy = olddoc.ReferencedBy
+		newdoc.Executable = olddoc.Executable
+		if olddoc.CozyMetadata != nil {
+			newdoc.CozyMetadata = olddoc.CozyMetadata.Clone()
+			fcm, _ := files.CozyMetadataFromClaims(c, true)
+			newdoc.CozyMetadata.UpdatedAt = fcm.UpdatedAt
+			newdoc.CozyMetadata.UploadedAt = fcm.UploadedAt
+			newdoc.CozyMetadata.UploadedBy = fcm.UploadedBy
+			newdoc.CozyMetadata.UploadedOn = fcm.UploadedOn
+		} else {
+			newdoc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, true)
+		}
+		if err := checkPerm(c, permission.PUT, nil, newdoc); err != nil {
+			return err
+		}
+	} else {
+		newdoc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, true)
+		if err := checkPerm(c, permission.POST, nil, newdoc); err != nil {
+			return err
+		}
+		// Check the quota before reading the body, to avoid uploading a
+		// large file just to reject it at the end.
+		if size >= 0 {
+			if _, _, _, err := vfs.CheckAvailableDiskSpace(fs, newdoc); err != nil {
+				return wrapError(err)
+			}
+		}
+	}
+
+	f, err := fs.CreateFile(newdoc, olddoc)
+	if err != nil {
+		return wrapError(err)
+	}
+	_, err = io.Copy(f, c.Request().Body)
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+
+	setETag(c, newdoc.MD5Sum)
+	if olddoc != nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// Mkcol creates a new directory.
+func Mkcol(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) || fullpath == "/" {
+		return errMethodNotAllowed
+	}
+	if c.Request().ContentLength > 0 {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType)
+	}
+
+	dirpath, name := path.Split(fullpath)
+	parent, err := fs.DirByPath(path.Clean(dirpath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errConflict
+		}
+		return wrapError(err)
+	}
+	if exists, err := fs.DirChildExists(parent.ID(), name); err != nil {
+		return wrapError(err)
+	} else if exists {
+		return errMethodNotAllowed
+	}
+
+	doc, err := vfs.NewDirDocWithParent(name, parent, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	doc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, false)
+	if err := checkPerm(c, permission.POST, doc, nil); err != nil {
+		return err
+	}
+	if err := fs.CreateDir(doc); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// Delete moves a file or a directory to the trash.
+func Delete(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) || fullpath == "/" {
+		return errMethodNotAllowed
+	}
+
+	dir, file, err := fs.DirOrFileByPath(fullpath)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return err
+	}
+	if err := trash(fs, dir, file); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Move renames and/or moves a file or a directory.
+func Move(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) || fullpath == "/" {
+		return errMethodNotAllowed
+	}
+
+	dst, err := destination(c)
+	if err != nil {
+		return err
+	}
+	dir, file, err := fs.DirOrFileByPath(fullpath)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return err
+	}
+	if err := checkSourceAndDestination(dir, fullpath, dst); err != nil {
+		return err
+	}
+
+	target, err := prepareDestination(c, fs, dst)
+	if err != nil {
+		return err
+	}
+
+	name := path.Base(dst)
+	parent := target.parent
+	patch := &vfs.DocPatch{Name: &name, DirID: &parent.DocID}
+	if dir != nil {
+		err = checkPerm(c, permission.PATCH, &vfs.DirDoc{
+			DocID:    dir.DocID,
+			DocName:  name,
+			DirID:    parent.DocID,
+			Fullpath: dst,
+		}, nil)
+	} else {
+		moved := file.Clone().(*vfs.FileDoc)
+		moved.DocName = name
+		moved.DirID = parent.DocID
+		moved.ResetFullpath()
+		err = checkPerm(c, permission.PATCH, nil, moved)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The existing resource is trashed only when all the checks have passed
+	if err := target.trash(fs); err != nil {
+		return wrapError(err)
+	}
+	if dir != nil {
+		_, err = vfs.ModifyDirMetadata(fs, dir, patch)
+	} else {
+		_, err = vfs.ModifyFileMetadata(fs, file, patch)
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if target.overwritten() {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// Copy duplicates a file or a directory (with its content).
+func Copy(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) || fullpath == "/" {
+		return errMethodNotAllowed
+	}
+
+	dst, err := destination(c)
+	if err != nil {
+		return err
+	}
+	dir, file, err := fs.DirOrFileByPath(fullpath)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := checkPerm(c, permission.GET, dir, file); err != nil {
+		return err
+	}
+	if err := checkSourceAndDestination(dir, fullpath, dst); err != nil {
+		return err
+	}
+
+	target, err := prepareDestination(c, fs, dst)
+	if err != nil {
+		return err
+	}
+
+	name := path.Base(dst)
+	if file != nil {
+		newdoc := vfs.CreateFileDocCopy(file, target.parent.ID(), name)
+		newdoc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, true)
+		if err := checkPerm(c, permission.POST, nil, newdoc); err != nil {
+			return err
+		}
+		if _, _, _, err := vfs.CheckAvailableDiskSpace(fs, newdoc); err != nil {
+			return wrapError(err)
+		}
+		if err := target.trash(fs); err != nil {
+			return wrapError(err)
+		}
+		if err := fs.CopyFile(file, newdoc); err != nil {
+			return wrapError(err)
+		}
+	} else {
+		// With Depth: 0, only the directory is copied, not its content
+		recursive := c.Request().Header.Get("Depth") != "0"
+		root, err := checkCopyDir(c, fs, dir, target.parent, name, recursive)
+		if err != nil {
+			return err
+		}
+		if err := target.trash(fs); err != nil {
+			return wrapError(err)
+		}
+		if err := copyDir(c, fs, dir, root, recursive); err != nil {
+			return err
+		}
+	}
+
+	if target.overwritten() {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// checkCopyDir checks the quota and the permissions for copying the src
+// directory inside the parent directory, with the given name, and returns the
+// document for the copy.
+func checkCopyDir(c echo.Context, fs vfs.VFS, src, parent *vfs.DirDoc, name string, recursive bool) (*vfs.DirDoc, error) {
+	size := int64(0)
+	if recursive {
+		var err error
+		if size, err = fs.DirSize(src); err != nil {
+			return nil, wrapError(err)
+		}
+	}
+	if quota := fs.DiskQuota(); quota > 0 && size > 0 {
+		usage, err := fs.DiskUsage()
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if usage+size > quota {
+			return nil, wrapError(vfs.ErrFileTooBig)
+		}
+	}
+
+	root, err := vfs.NewDirDocWithParent(name, parent, src.Tags)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	root.CozyMetadata, _ = files.CozyMetadataFromClaims(c, false)
+	if err := checkPerm(c, permission.POST, root, nil); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// copyDir creates the root directory for the copy of src. If recursive is
+// true, the content of the directory is copied too, by walking the tree of
+// src.
+func copyDir(c echo.Context, fs vfs.VFS, src, root *vfs.DirDoc, recursive bool) error {
+	if err := fs.CreateDir(root); err != nil {
+		return wrapError(err)
+	}
+	if !recursive {
+		return nil
+	}
+
+	// Keep a mapping from the source directories to their copies
+	copies := map[string]*vfs.DirDoc{src.DocID: root}
+	err := vfs.WalkByID(fs, src.DocID, func(name string, dir *vfs.DirDoc, file *vfs.FileDoc, err error) error {
+		if err != nil {
+			return err
+		}
+		if dir != nil {
+			if dir.DocID == src.DocID {
+				return nil
+			}
+			target, ok := copies[dir.DirID]
+			if !ok {
+				return vfs.ErrParentDoesNotExist
+			}
+			newdir, err := vfs.NewDirDocWithParent(dir.DocName, target, dir.Tags)
+			if err != nil {
+				return err
+			}
+			newdir.CozyMetadata, _ = files.CozyMetadataFromClaims(c, false)
+			if err := fs.CreateDir(newdir); err != nil {
+				return err
+			}
+			copies[dir.DocID] = newdir
+			return nil
+		}
+		target, ok := copies[file.DirID]
+		if !ok {
+			return vfs.ErrParentDoesNotExist
+		}
+		newdoc := vfs.CreateFileDocCopy(file, target.ID(), file.DocName)
+		newdoc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, true)
+		return fs.CopyFile(file, newdoc)
+	})
+	if err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// Lock returns a lock token for the resource. The locks are advisory: they
+// are needed by some clients (macOS Finder, Windows Explorer) to write files,
+// but the stack does not enforce them.
+func Lock(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+	fullpath := davPath(c)
+	if isHidden(fullpath) {
+		return errMethodNotAllowed
+	}
+
+	var info lockInfo
+	if c.Request().ContentLength != 0 {
+		if err := xml.NewDecoder(c.Request().Body).Decode(&info); err != nil && err != io.EOF {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+	}
+
+	status := http.StatusOK
+	dir, file, err := fs.DirOrFileByPath(fullpath)
+	if os.IsNotExist(err) {
+		// A LOCK on an unmapped URL creates an empty file
+		if err := createEmptyFile(c, fs, fullpath); err != nil {
+			return err
+		}
+		status = http.StatusCreated
+	} else if err != nil {
+		return wrapError(err)
+	} else if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return err
+	}
+
+	token := "opaquelocktoken:" + utils.RandomString(32)
+	depth := "infinity"
+	if c.Request().Header.Get("Depth") == "0" {
+		depth = "0"
+	}
+	var ld lockDiscovery
+	ld.XMLNS = davNS
+	ld.ActiveLock.LockType = "<D:locktype><D:write/></D:locktype><D:lockscope><D:exclusive/></D:lockscope>"
+	ld.ActiveLock.Depth = depth
+	ld.ActiveLock.Owner = info.Owner.InnerXML
+	ld.ActiveLock.Timeout = "Second-" + strconv.Itoa(lockTimeout)
+	ld.ActiveLock.LockToken = token
+	ld.ActiveLock.LockRoot = davHref(fullpath, dir != nil)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(&ld); err != nil {
+		return err
+	}
+	c.Response().Header().Set("Lock-Token", "<"+token+">")
+	return c.Blob(status, "application/xml; charset=utf-8", buf.Bytes())
+}
+
+// Unlock releases a lock. As the locks are advisory, there is nothing to do.
+func Unlock(c echo.Context) error {
+	if c.Request().Header.Get("Lock-Token") == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing Lock-Token")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func createEmptyFile(c echo.Context, fs vfs.VFS, fullpath string) error {
+	dirpath, name := path.Split(fullpath)
+	parent, err := fs.DirByPath(path.Clean(dirpath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errConflict
+		}
+		return wrapError(err)
+	}
+	mime, class := vfs.ExtractMimeAndClassFromFilename(name)
+	doc, err := vfs.NewFileDoc(name, parent.ID(), 0, nil, mime, class,
+		time.Now(), false, false, false, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	doc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, true)
+	if err := checkPerm(c, permission.POST, nil, doc); err != nil {
+		return err
+	}
+	f, err := fs.CreateFile(doc, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := f.Close(); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// destination extracts the VFS path from the Destination header.
+func destination(c echo.Context) (string, error) {
+	header := c.Request().Header.Get("Destination")
+	if header == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "missing Destination")
+	}
+	u, err := url.Parse(header)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "invalid Destination")
+	}
+	if u.Host != "" && u.Host != c.Request().Host {
+		return "", errBadDestination
+	}
+	if !strings.HasPrefix(u.Path, filesPrefix+"/") {
+		return "", errBadDestination
+	}
+	dst := path.Clean("/" + strings.TrimPrefix(u.Path, filesPrefix))
+	if dst == "/" || isHidden(dst) {
+		return "", echo.NewHTTPError(http.StatusForbidden)
+	}
+	return dst, nil
+}
+
+// checkSourceAndDestination refuses a MOVE or a COPY where the destination is
+// the source, one of its ancestors (it would be trashed with the source), or
+// inside the source directory.
+func checkSourceAndDestination(dir *vfs.DirDoc, fullpath, dst string) error {
+	if strings.HasPrefix(fullpath+"/", dst+"/") {
+		return echo.NewHTTPError(http.StatusForbidden, "the source and the destination are the same")
+	}
+	if dir != nil && strings.HasPrefix(dst+"/", fullpath+"/") {
+		return echo.NewHTTPError(http.StatusForbidden, "cannot move or copy a directory inside itself")
+	}
+	return nil
+}
+
+// destinationTarget is the parent directory of the destination, and the
+// resource that already exists at the destination, if any.
+type destinationTarget struct {
+	parent *vfs.DirDoc
+	dir    *vfs.DirDoc
+	file   *vfs.FileDoc
+}
+
+func (t *destinationTarget) overwritten() bool {
+	return t.dir != nil || t.file != nil
+}
+
+// trash puts the resource at the destination in the trash, if any. It must be
+// called only after all the checks, just before the operation.
+func (t *destinationTarget) trash(fs vfs.VFS) error {
+	if !t.overwritten() {
+		return nil
+	}
+	return trash(fs, t.dir, t.file)
+}
+
+// prepareDestination checks the parent of the destination, and if a
+// resource already exists at the destination, that the Overwrite header and
+// the permissions allow to replace it.
+func prepareDestination(c echo.Context, fs vfs.VFS, dst string) (*destinationTarget, error) {
+	parent, err := fs.DirByPath(path.Dir(dst))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errConflict
+		}
+		return nil, wrapError(err)
+	}
+
+	dir, file, err := fs.DirOrFileByPath(dst)
+	if os.IsNotExist(err) {
+		return &destinationTarget{parent: parent}, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if c.Request().Header.Get("Overwrite") == "F" {
+		return nil, errPrecondition
+	}
+	if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return nil, err
+	}
+	return &destinationTarget{parent: parent, dir: dir, file: file}, nil
+}
+
+func trash(fs vfs.VFS, dir *vfs.DirDoc, file *vfs.FileDoc) error {
+	if dir != nil {
+		_, err := vfs.TrashDir(fs, dir)
+		return err
+	}
+	_, err := vfs.TrashFile(fs, file)
+	return err
+}
+
+func dirResponse(fs vfs.VFS, dir *vfs.DirDoc, req *propfindRequest) response {
+	props := []property{
+		davProp("resourcetype", "<D:collection xmlns:D=\"DAV:\"/>"),
+		davProp("displayname", escapeXML(dir.DocName)),
+		davProp("creationdate", dir.CreatedAt.UTC().Format(time.RFC3339)),
+		davProp("getlastmodified", httpDate(dir.UpdatedAt)),
+		davProp("getetag", `"`+dir.DocRev+`"`),
+		davProp("supportedlock", supportedLock),
+	}
+	// The quota is only computed for the directory targeted by the request, as
+	// it can be expensive.
+	if fs != nil && dir.DocID == consts.RootDirID {
+		if used, err := fs.DiskUsage(); err == nil {
+			props = append(props, davProp("quota-used-bytes", strconv.FormatInt(used, 10)))
+			if quota := fs.DiskQuota(); quota > 0 {
+				available := quota - used
+				if available < 0 {
+					available = 0
+				}
+				props = append(props, davProp("quota-available-bytes", strconv.FormatInt(available, 10)))
+			}
+		}
+	}
+	return newResponse(davHref(dir.Fullpath, true), props, req)
+}
+
+func fileResponse(fullpath string, file *vfs.FileDoc, req *propfindRequest) response {
+	props := []property{
+		davProp("resourcetype", ""),
+		davProp("displayname", escapeXML(file.DocName)),
+		davProp("creationdate", file.CreatedAt.UTC().Format(time.RFC3339)),
+		davProp("getlastmodified", httpDate(file.UpdatedAt)),
+		davProp("getcontentlength", strconv.FormatInt(file.ByteSize, 10)),
+		davProp("getcontenttype", escapeXML(file.Mime)),
+		davProp("getetag", escapeXML(etag(file.MD5Sum))),
+		davProp("supportedlock", supportedLock),
+	}
+	return newResponse(davHref(fullpath, false), props, req)
+}
+
+const supportedLock = `<D:lockentry xmlns:D="DAV:"><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype></D:lockentry>`
+
+// newResponse filters the properties to keep only the asked ones, and adds a
+// propstat with a 404 status for the unknown properties.
+func newResponse(href string, props []property, req *propfindRequest) response {
+	res := response{Href: href}
+	if req.PropName != nil {
+		for i := range props {
+			props[i].InnerXML = ""
+		}
+	}
+	if req.AllProp != nil || req.PropName != nil {
+		res.Propstats = []propstat{{Props: props, Status: statusLine(http.StatusOK)}}
+		return res
+	}
+
+	var found, missing []property
+	for _, name := range req.Prop {
+		ok := false
+		for _, p := range props {
+			if p.XMLName == name {
+				found = append(found, p)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			missing = append(missing, property{XMLName: name})
+		}
+	}
+	if len(found) > 0 {
+		res.Propstats = append(res.Propstats, propstat{Props: found, Status: statusLine(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		res.Propstats = append(res.Propstats, propstat{Props: missing, Status: statusLine(http.StatusNotFound)})
+	}
+	return res
+}
+
+func sendMultistatus(c echo.Context, ms *multistatus) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(ms); err != nil {
+		return err
+	}
+	return c.Blob(http.StatusMultiStatus, "application/xml; charset=utf-8", buf.Bytes())
+}
+
+func etag(md5sum []byte) string {
+	return fmt.Sprintf(`"%s"`, base64.StdEncoding.EncodeToString(md5sum))
+}
+
+func setETag(c echo.Context, md5sum []byte) {
+	if len(md5sum) > 0 {
+		c.Response().Header().Set("ETag", etag(md5sum))
+	}
+}
+
+func checkPerm(c echo.Context, v permission.Verb, d *vfs.DirDoc, f *vfs.FileDoc) error {
+	if d != nil {
+		return middlewares.AllowVFS(c, v, d)
+	}
+	return middlewares.AllowVFS(c, v, f)
+}
+
+// wrapError translates the errors of the VFS to HTTP errors with the status
+// codes expected by the WebDAV clients.
+func wrapError(err error) error {
+	switch {
+	case os.IsNotExist(err), errors.Is(err, vfs.ErrParentDoesNotExist):
+		return echo.NewHTTPError(http.StatusNotFound)
+	case os.IsExist(err), errors.Is(err, vfs.ErrConflict):
+		return echo.NewHTTPError(http.StatusConflict)
+	case errors.Is(err, vfs.ErrFileTooBig), errors.Is(err, vfs.ErrMaxFileSize):
+		return echo.NewHTTPError(http.StatusInsufficientStorage, err.Error())
+	case errors.Is(err, vfs.ErrIllegalFilename), errors.Is(err, vfs.ErrIllegalPath):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, vfs.ErrForbiddenDocMove), errors.Is(err, vfs.ErrParentInTrash),
+		errors.Is(err, vfs.ErrFileInTrash):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, vfs.ErrContentLengthMismatch), errors.Is(err, vfs.ErrInvalidHash):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return err
+}
+
+// Routes sets the routing for the WebDAV server.
+func Routes(router *echo.Group) {
+	group := router.Group("/files", NeedAuth)
+	for _, p := range []string{"", "/", "/*"} {
+		group.OPTIONS(p, Options)
+		group.Add("PROPFIND", p, Propfind)
+		group.Add("PROPPATCH", p, Proppatch)
+		group.HEAD(p, Get)
+		group.GET(p, Get)
+		group.PUT(p, Put)
+		group.Add("MKCOL", p, Mkcol)
+		group.DELETE(p, Delete)
+		group.Add("MOVE", p, Move)
+		group.Add("COPY", p, Copy)
+		group.Add("LOCK", p, Lock)
+		group.Add("UNLOCK", p, Unlock)
+	}
+}
@@ -0,0 +1,170 @@
+package webdav
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/tests/testutils"
+)
+
+func TestWebdav(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	_ = setup.GetTestInstance()
+	_, token := setup.GetTestClient(consts.Files)
+
+	ts := setup.GetTestServer("/dav", Routes)
+	t.Cleanup(ts.Close)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.Request("PROPFIND", "/dav/files/").
+			WithHeader("Depth", "0").
+			Expect().Status(401).
+			Header("WWW-Authenticate").Contains("Basic")
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		res := e.OPTIONS("/dav/files/").
+			Expect().Status(200)
+		res.Header("DAV").Contains("1")
+		res.Header("Allow").Contains("PROPFIND")
+	})
+
+	t.Run("MkcolAndPut", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.Request("MKCOL", "/dav/files/Documents").
+			WithBasicAuth("", token).
+			Expect().Status(201)
+
+		e.Request("MKCOL", "/dav/files/Documents").
+			WithBasicAuth("", token).
+			Expect().Status(405)
+
+		e.Request("MKCOL", "/dav/files/missing/child").
+			WithBasicAuth("", token).
+			Expect().Status(409)
+
+		e.PUT("/dav/files/Documents/hello.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("Hello world")).
+			Expect().Status(201)
+
+		e.PUT("/dav/files/Documents/hello.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("Hello world!")).
+			Expect().Status(204)
+
+		e.GET("/dav/files/Documents/hello.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Body().IsEqual("Hello world!")
+	})
+
+	t.Run("Propfind", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		body := e.Request("PROPFIND", "/dav/files/Documents/").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Depth", "1").
+			Expect().Status(207).
+			Body().Raw()
+		if !strings.Contains(body, "/dav/files/Documents/hello.txt") {
+			t.Fatalf("hello.txt not found in %s", body)
+		}
+		if !strings.Contains(body, "<getcontentlength xmlns=\"DAV:\">12</getcontentlength>") {
+			t.Fatalf("invalid content length in %s", body)
+		}
+
+		e.Request("PROPFIND", "/dav/files/Documents/").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Depth", "infinity").
+			Expect().Status(403)
+
+		root := e.Request("PROPFIND", "/dav/files/").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Depth", "1").
+			Expect().Status(207).
+			Body().Raw()
+		if strings.Contains(root, ".cozy_trash") {
+			t.Fatalf("the trash should be hidden: %s", root)
+		}
+	})
+
+	t.Run("CopyAndMove", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.Request("COPY", "/dav/files/Documents/hello.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Destination", ts.URL+"/dav/files/Documents/copy.txt").
+			Expect().Status(201)
+
+		e.Request("MOVE", "/dav/files/Documents/copy.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Destination", ts.URL+"/dav/files/Documents/hello.txt").
+			WithHeader("Overwrite", "F").
+			Expect().Status(412)
+
+		// The source can't be its own destination, and it is not trashed
+		e.Request("MOVE", "/dav/files/Documents/copy.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Destination", ts.URL+"/dav/files/Documents/copy.txt").
+			Expect().Status(403)
+		e.Request("COPY", "/dav/files/Documents/copy.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Destination", ts.URL+"/dav/files/Documents").
+			Expect().Status(403)
+
+		e.Request("MOVE", "/dav/files/Documents/copy.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Destination", ts.URL+"/dav/files/moved.txt").
+			Expect().Status(201)
+
+		e.GET("/dav/files/moved.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Body().IsEqual("Hello world!")
+
+		e.Request("COPY", "/dav/files/Documents").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Destination", ts.URL+"/dav/files/Backup").
+			Expect().Status(201)
+
+		e.GET("/dav/files/Backup/hello.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Body().IsEqual("Hello world!")
+
+		e.Request("PROPPATCH", "/dav/files/.cozy_trash").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+	})
+
+	t.Run("LockAndDelete", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.Request("LOCK", "/dav/files/moved.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Header("Lock-Token").Contains("opaquelocktoken:")
+
+		e.DELETE("/dav/files/moved.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+
+		e.GET("/dav/files/moved.txt").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+	})
+}
@@ -0,0 +1,156 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const davNS = "DAV:"
+
+// propfindRequest is the body of a PROPFIND request. An empty body is
+// equivalent to an allprop request.
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+// propNames is the list of the properties asked in a PROPFIND request. The
+// properties can be in any namespace, so we need a custom unmarshaler to keep
+// their names.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch elem := t.(type) {
+		case xml.StartElement:
+			*p = append(*p, elem.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func parsePropfind(r io.Reader) (*propfindRequest, error) {
+	var req propfindRequest
+	err := xml.NewDecoder(r).Decode(&req)
+	if err == io.EOF {
+		req.AllProp = &struct{}{}
+		return &req, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.AllProp == nil && req.PropName == nil && len(req.Prop) == 0 {
+		return nil, fmt.Errorf("invalid propfind request")
+	}
+	return &req, nil
+}
+
+// property is a WebDAV property with its raw XML value.
+type property struct {
+	XMLName  xml.Name
+	InnerXML string `xml:",innerxml"`
+}
+
+type propstat struct {
+	Props  []property
+	Status string
+}
+
+// MarshalXML is needed to serialize the properties with their own namespaces
+// inside the D:prop element.
+func (ps propstat) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "D:propstat"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	prop := xml.StartElement{Name: xml.Name{Local: "D:prop"}}
+	if err := e.EncodeToken(prop); err != nil {
+		return err
+	}
+	for _, p := range ps.Props {
+		if err := e.Encode(p); err != nil {
+			return err
+		}
+	}
+	if err := e.EncodeToken(prop.End()); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(ps.Status, xml.StartElement{Name: xml.Name{Local: "D:status"}}); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+type response struct {
+	XMLName   xml.Name   `xml:"D:response"`
+	Href      string     `xml:"D:href"`
+	Propstats []propstat `xml:"D:propstat"`
+}
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"D:multistatus"`
+	XMLNS     string     `xml:"xmlns:D,attr"`
+	Responses []response `xml:"D:response"`
+}
+
+type lockDiscovery struct {
+	XMLName    xml.Name `xml:"D:prop"`
+	XMLNS      string   `xml:"xmlns:D,attr"`
+	ActiveLock struct {
+		LockType  string `xml:",innerxml"`
+		Depth     string `xml:"D:depth"`
+		Owner     string `xml:"D:owner,omitempty"`
+		Timeout   string `xml:"D:timeout"`
+		LockToken string `xml:"D:locktoken>D:href"`
+		LockRoot  string `xml:"D:lockroot>D:href"`
+	} `xml:"D:lockdiscovery>D:activelock"`
+}
+
+// lockInfo is the body of a LOCK request.
+type lockInfo struct {
+	XMLName xml.Name `xml:"DAV: lockinfo"`
+	Owner   struct {
+		InnerXML string `xml:",innerxml"`
+	} `xml:"DAV: owner"`
+}
+
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+func davProp(local, inner string) property {
+	return property{
+		XMLName:  xml.Name{Space: davNS, Local: local},
+		InnerXML: inner,
+	}
+}
+
+func escapeXML(s string) string {
+	var b xmlBuilder
+	_ = xml.EscapeText(&b, []byte(s))
+	return string(b)
+}
+
+type xmlBuilder []byte
+
+func (b *xmlBuilder) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+func httpDate(t time.Time) string {
+	return t.UTC().Format(http.TimeFormat)
+}
@@ -0,0 +1,30 @@
+package bitwarden
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "bitwarden-emergency-access",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      30 * time.Second,
+		WorkerFunc:   WorkerEmergencyAccess,
+	})
+}
+
+// WorkerEmergencyAccess is used to approve an emergency access at the end of
+// its wait time.
+func WorkerEmergencyAccess(ctx *job.TaskContext) error {
+	var msg bitwarden.EmergencyAccessMessage
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	return bitwarden.ApproveAfterWaitTime(ctx.Instance, &msg)
+}
@@ -0,0 +1,30 @@
+// Package bitwarden is for the workers of the bitwarden vault.
+package bitwarden
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "clean-bitwarden-sends",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      30 * time.Second,
+		WorkerFunc:   WorkerCleanSend,
+	})
+}
+
+// WorkerCleanSend is used to delete a Send after its deletion date.
+func WorkerCleanSend(ctx *job.TaskContext) error {
+	var msg bitwarden.CleanSendMessage
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	return bitwarden.CleanSend(ctx.Instance, &msg)
+}
@@ -17,29 +17,34 @@ const templateTitleVar = "template_title"
 
 func initMailTemplates() {
 	mailTemplater = MailTemplater{
-		"passphrase_hint":              subjectEntry{"Mail Hint Subject", nil},
-		"passphrase_reset":             subjectEntry{"Mail Reset Passphrase Subject", nil},
-		"archiver":                     subjectEntry{"Mail Archive Subject", nil},
-		"import_success":               subjectEntry{"Mail Import Success Subject", nil},
-		"import_error":                 subjectEntry{"Mail Import Error Subject", nil},
-		"export_error":                 subjectEntry{"Mail Export Error Subject", nil},
-		"move_confirm":                 subjectEntry{"Mail Move Confirm Subject", nil},
-		"move_success":                 subjectEntry{"Mail Move Success Subject", nil},
-		"move_error":                   subjectEntry{"Mail Move Error Subject", nil},
-		"magic_link":                   subjectEntry{"Mail Magic Link Subject", nil},
-		"two_factor":                   subjectEntry{"Mail Two Factor Subject", nil},
-		"two_factor_mail_confirmation": subjectEntry{"Mail Two Factor Mail Confirmation Subject", []string{templateTitleVar}},
-		"new_connection":               subjectEntry{"Mail New Connection Subject", []string{templateTitleVar}},
-		"new_registration":             subjectEntry{"Mail New Registration Subject", []string{templateTitleVar}},
-		"confirm_flagship":             subjectEntry{"Mail Confirm Flagship Subject", nil},
-		"alert_account":                subjectEntry{"Mail Alert Account Subject", nil},
-		"support_request":              subjectEntry{"Mail Support Confirmation Subject", nil},
-		"sharing_request":              subjectEntry{"Mail Sharing Request Subject", []string{"SharerPublicName", "TitleType"}},
-		"sharing_to_confirm":           subjectEntry{"Mail Sharing Member To Confirm Subject", nil},
-		"notifications_sharing":        subjectEntry{"Notification Sharing Subject", []string{"SharerPublicName", "TitleType"}},
-		"notifications_diskquota":      subjectEntry{"Notifications Disk Quota Subject", nil},
-		"notifications_oauthclients":   subjectEntry{"Notifications OAuth Clients Subject", nil},
-		"update_email":                 subjectEntry{"Mail Update Email Subject", nil},
+		"passphrase_hint":                subjectEntry{"Mail Hint Subject", nil},
+		"passphrase_reset":               subjectEntry{"Mail Reset Passphrase Subject", nil},
+		"archiver":                       subjectEntry{"Mail Archive Subject", nil},
+		"import_success":                 subjectEntry{"Mail Import Success Subject", nil},
+		"import_error":                   subjectEntry{"Mail Import Error Subject", nil},
+		"export_error":                   subjectEntry{"Mail Export Error Subject", nil},
+		"move_confirm":                   subjectEntry{"Mail Move Confirm Subject", nil},
+		"move_success":                   subjectEntry{"Mail Move Success Subject", nil},
+		"move_error":                     subjectEntry{"Mail Move Error Subject", nil},
+		"magic_link":                     subjectEntry{"Mail Magic Link Subject", nil},
+		"two_factor":                     subjectEntry{"Mail Two Factor Subject", nil},
+		"two_factor_mail_confirmation":   subjectEntry{"Mail Two Factor Mail Confirmation Subject", []string{templateTitleVar}},
+		"new_connection":                 subjectEntry{"Mail New Connection Subject", []string{templateTitleVar}},
+		"new_registration":               subjectEntry{"Mail New Registration Subject", []string{templateTitleVar}},
+		"confirm_flagship":               subjectEntry{"Mail Confirm Flagship Subject", nil},
+		"alert_account":                  subjectEntry{"Mail Alert Account Subject", nil},
+		"support_request":                subjectEntry{"Mail Support Confirmation Subject", nil},
+		"sharing_request":                subjectEntry{"Mail Sharing Request Subject", []string{"SharerPublicName", "TitleType"}},
+		"sharing_to_confirm":             subjectEntry{"Mail Sharing Member To Confirm Subject", nil},
+		"notifications_sharing":          subjectEntry{"Notification Sharing Subject", []string{"SharerPublicName", "TitleType"}},
+		"notifications_diskquota":        subjectEntry{"Notifications Disk Quota Subject", nil},
+		"notifications_oauthclients":     subjectEntry{"Notifications OAuth Clients Subject", nil},
+		"notifications_digest":           subjectEntry{"Notifications Digest Subject", nil},
+		"notifications_sharing_expiring": subjectEntry{"Notifications Sharing Expiring Subject", []string{"SharingName"}},
+		"notifications_sharing_expired":  subjectEntry{"Notifications Sharing Expired Subject", []string{"SharingName"}},
+		"notifications_emergency_access": subjectEntry{"Notifications Emergency Access Subject", nil},
+		"bitwarden_emergency_invite":     subjectEntry{"Mail Emergency Access Subject", []string{"GrantorPublicName"}},
+		"update_email":                   subjectEntry{"Mail Update Email Subject", nil},
 	}
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/note"
+	"github.com/cozy/cozy-stack/model/search"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/model/vfs/vfsswift"
 	"github.com/cozy/cozy-stack/pkg/config/config"
@@ -40,6 +41,7 @@ const (
 	accountsToOrganization = "accounts-to-organization"
 	notesMimeType          = "notes-mime-type"
 	unwantedFolders        = "remove-unwanted-folders"
+	searchIndex            = "search-index"
 )
 
 // maxSimultaneousCalls is the maximal number of simultaneous calls to Swift
@@ -82,6 +84,8 @@ func worker(ctx *job.TaskContext) error {
 		return migrateNotesMimeType(ctx.Instance.Domain)
 	case unwantedFolders:
 		return removeUnwantedFolders(ctx.Instance.Domain)
+	case searchIndex:
+		return search.SetupTrigger(ctx.Instance)
 	default:
 		return fmt.Errorf("unknown migration type %q", msg.Type)
 	}
@@ -0,0 +1,27 @@
+// Package notifications is for the workers related to the notifications.
+package notifications
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification/center"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "notifications-digest",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      1 * time.Minute,
+		WorkerFunc:   DigestWorker,
+	})
+}
+
+// DigestWorker is the worker that sends the daily digest of the
+// notifications by mail.
+func DigestWorker(ctx *job.TaskContext) error {
+	return center.SendDigest(ctx.Instance)
+}
@@ -1,4 +1,5 @@
-// Package push is the worker that sends push notifications to mobile apps.
+// Package push is the worker that sends push notifications to mobile apps, and
+// to the browsers with Web Push.
 package push
 
 import (
@@ -22,6 +23,7 @@ import (
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/notification/center"
 	"github.com/cozy/cozy-stack/model/notification/huawei"
+	"github.com/cozy/cozy-stack/model/notification/webpush"
 	"github.com/cozy/cozy-stack/model/oauth"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/logger"
@@ -116,6 +118,13 @@ func Init() (err error) {
 		}
 	}
 
+	if conf.VAPIDPublicKey != "" {
+		webPushClient, err = webpush.NewClient(conf)
+		if err != nil {
+			return err
+		}
+	}
+
 	return
 }
 
@@ -138,7 +147,7 @@ func Worker(ctx *job.TaskContext) error {
 		if _, ok := seen[c.NotificationDeviceToken]; ok {
 			continue
 		}
-		if c.Flagship {
+		if c.Flagship || c.NotificationPlatform == oauth.PlatformWebPush {
 			continue
 		}
 		seen[c.NotificationDeviceToken] = struct{}{}
@@ -173,7 +182,7 @@ func Worker(ctx *job.TaskContext) error {
 		if _, ok := seen[c.NotificationDeviceToken]; ok {
 			continue
 		}
-		if !c.Flagship {
+		if !c.Flagship || c.NotificationPlatform == oauth.PlatformWebPush {
 			continue
 		}
 		seen[c.NotificationDeviceToken] = struct{}{}
@@ -0,0 +1,105 @@
+package push
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/model/notification/webpush"
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/pkg/logger"
+)
+
+var webPushClient *webpush.Client
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "webpush",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Timeout:      30 * time.Second,
+		WorkerFunc:   WebPushWorker,
+	})
+}
+
+// webPushPayload is the JSON sent to the service worker of the browser.
+type webPushPayload struct {
+	NotificationID string                 `json:"notification_id"`
+	Source         string                 `json:"source"`
+	Title          string                 `json:"title,omitempty"`
+	Body           string                 `json:"body,omitempty"`
+	Data           map[string]interface{} `json:"data,omitempty"`
+}
+
+// WebPushWorker is the worker that sends the push messages to the browsers.
+func WebPushWorker(ctx *job.TaskContext) error {
+	var msg center.PushMessage
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if webPushClient == nil {
+		ctx.Logger().Warn("Could not send web push notification: not configured")
+		sendFallbackMail(ctx.Instance, msg.MailFallback)
+		return nil
+	}
+	cs, err := oauth.GetNotifiables(ctx.Instance)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webPushPayload{
+		NotificationID: msg.NotificationID,
+		Source:         msg.Source,
+		Title:          msg.Title,
+		Body:           msg.Message,
+		Data:           msg.Data,
+	})
+	if err != nil {
+		return err
+	}
+	opts := &webpush.Options{}
+	switch msg.Priority {
+	case "high":
+		opts.Urgency = webpush.UrgencyHigh
+	case "normal":
+		opts.Urgency = webpush.UrgencyNormal
+	}
+	if msg.Collapsible {
+		opts.Topic = hex.EncodeToString(hashSource(msg.Source))
+	}
+
+	seen := make(map[string]struct{})
+	nbSent := 0
+	for _, c := range cs {
+		sub := c.WebPushSubscription()
+		if sub == nil {
+			continue
+		}
+		if _, ok := seen[sub.Endpoint]; ok {
+			continue
+		}
+		seen[sub.Endpoint] = struct{}{}
+		gone, err := webPushClient.Send(ctx, sub, payload, opts)
+		if gone {
+			_ = c.Delete(ctx.Instance)
+		}
+		if err != nil {
+			ctx.Logger().
+				WithFields(logger.Fields{"device_id": c.ID()}).
+				Warnf("could not send web push notification: %s", err)
+			continue
+		}
+		nbSent++
+		if nbSent >= 10 {
+			ctx.Logger().Warnf("too many web push subscriptions")
+			return nil
+		}
+	}
+	if nbSent == 0 {
+		sendFallbackMail(ctx.Instance, msg.MailFallback)
+	}
+	return nil
+}
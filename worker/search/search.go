@@ -0,0 +1,25 @@
+package search
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/search"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "search-index",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      15 * time.Minute,
+		WorkerFunc:   WorkerIndex,
+	})
+}
+
+// WorkerIndex updates the full-text search index of the files.
+func WorkerIndex(ctx *job.TaskContext) error {
+	return search.Index(ctx.Instance, ctx.Logger())
+}
@@ -51,6 +51,15 @@ func init() {
 		Timeout:      1 * time.Hour,
 		WorkerFunc:   WorkerUpload,
 	})
+
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "share-expire",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerExpire,
+	})
 }
 
 // WorkerGroup is used to update the list of members of sharings for a group
@@ -117,3 +126,15 @@ func WorkerUpload(ctx *job.TaskContext) error {
 	}
 	return s.Upload(ctx.Instance, ctx.Context, msg.Errors)
 }
+
+// WorkerExpire is used to remind the owner that a sharing will expire, and to
+// revoke it (or one of its members) on the expiration date.
+func WorkerExpire(ctx *job.TaskContext) error {
+	var msg sharing.ExpireMsg
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	ctx.Instance.Logger().WithNamespace("share").
+		Debugf("Expire %#v", msg)
+	return sharing.Expire(ctx.Instance, &msg)
+}
@@ -1,11 +1,7 @@
 package sms
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
 	"runtime"
 	"time"
 
@@ -13,10 +9,8 @@ import (
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/notification/center"
-	"github.com/cozy/cozy-stack/pkg/config/config"
-	"github.com/cozy/cozy-stack/pkg/logger"
+	smsprovider "github.com/cozy/cozy-stack/model/notification/sms"
 	"github.com/cozy/cozy-stack/pkg/mail"
-	"github.com/labstack/echo/v4"
 )
 
 func init() {
@@ -47,58 +41,47 @@ func Worker(ctx *job.TaskContext) error {
 
 func sendSMS(ctx *job.TaskContext, msg *center.SMS) error {
 	inst := ctx.Instance
-	cfg, err := getConfig(inst)
+	cfg, err := smsprovider.GetConfig(inst)
 	if err != nil {
 		return err
 	}
-	number, err := getMyselfPhoneNumber(inst)
+	provider, err := smsprovider.New(*cfg)
 	if err != nil {
 		return err
 	}
-	switch cfg.Provider {
-	case "api_sen":
-		log := ctx.Logger()
-		return sendSenAPI(cfg, msg, number, log)
-	default:
-		return errors.New("Unknown provider for sending SMS")
-	}
-}
-
-func sendSenAPI(cfg *config.SMS, msg *center.SMS, number string, log logger.Logger) error {
-	payload, err := json.Marshal(map[string]interface{}{
-		"content":  msg.Message,
-		"receiver": []interface{}{number},
-	})
-	if err != nil {
-		return err
-	}
-	req, err := http.NewRequest(http.MethodPost, cfg.URL, bytes.NewReader(payload))
+	number, err := getMyselfPhoneNumber(inst)
 	if err != nil {
 		return err
 	}
-	req.Header.Add(echo.HeaderAccept, echo.MIMEApplicationJSON)
-	req.Header.Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	req.Header.Add(echo.HeaderAuthorization, "Bearer "+cfg.Token)
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+
+	m := &smsprovider.Message{
+		NotificationID: msg.NotificationID,
+		Number:         number,
+		Text:           msg.Message,
 	}
-	defer res.Body.Close()
-	if res.StatusCode == 200 {
-		return nil
+	_, hasCallback := provider.(smsprovider.CallbackParser)
+	if hasCallback && msg.NotificationID != "" {
+		m.CallbackURL, err = smsprovider.CallbackURL(inst, msg.NotificationID)
+		if err != nil {
+			return err
+		}
 	}
 
-	var body map[string]interface{}
-	if err := json.NewDecoder(res.Body).Decode(&body); err == nil {
-		if t, ok := body["type"].(string); ok {
-			log = log.WithField("type", t)
-		}
-		if detail, ok := body["detail"].(string); ok {
-			log = log.WithField("detail", detail)
+	messageID, err := provider.Send(ctx, inst, m)
+	status := &smsprovider.Status{MessageID: messageID, Status: smsprovider.StatusSent}
+	if hasCallback {
+		status.Status = smsprovider.StatusQueued
+	}
+	if err != nil {
+		status.Status = smsprovider.StatusFailed
+		status.Error = err.Error()
+	}
+	if msg.NotificationID != "" {
+		if errr := smsprovider.RecordStatus(inst, msg.NotificationID, cfg.Provider, status); errr != nil {
+			ctx.Logger().Warnf("could not record the SMS status: %s", errr)
 		}
-		log.WithField("status_code", res.StatusCode).Warnf("Cannot send SMS")
 	}
-	return fmt.Errorf("Unexpected status code: %d", res.StatusCode)
+	return err
 }
 
 func getMyselfPhoneNumber(inst *instance.Instance) (string, error) {
@@ -113,14 +96,6 @@ func getMyselfPhoneNumber(inst *instance.Instance) (string, error) {
 	return number, nil
 }
 
-func getConfig(inst *instance.Instance) (*config.SMS, error) {
-	cfg, ok := config.GetConfig().Notifications.Contexts[inst.ContextName]
-	if !ok {
-		return nil, errors.New("SMS not configured on this context")
-	}
-	return &cfg, nil
-}
-
 func sendFallbackMail(inst *instance.Instance, email *mail.Options) {
 	if inst == nil || email == nil {
 		return
@@ -0,0 +1,30 @@
+package uploads
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/upload"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "clean-upload-session",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      30 * time.Second,
+		WorkerFunc:   WorkerCleanSession,
+	})
+}
+
+// WorkerCleanSession is used to remove an upload session that has not been
+// finalized, and the chunks that were uploaded for it, when it has expired.
+func WorkerCleanSession(ctx *job.TaskContext) error {
+	var msg upload.ExpireMessage
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	return upload.Expire(ctx.Instance, msg.SessionID)
+}